@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/audit"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+// The login methods recorded as the operation of authentication events.
+const (
+	auditOperationPassword = "password"
+	auditOperationOIDC     = "oidc"
+	auditOperationAPIKey   = "api_key"
+	auditOperationTOTP     = "totp"
+)
+
+// configuredAPIKeyName is recorded as the name of the configured API key,
+// which has no name.
+const configuredAPIKeyName = "(configured)"
+
+// clientIP returns the IP address of the client making the request.
+func clientIP(r *http.Request) string {
+	return session.ClientIP(r, config.GetInstance().GetProxyAuthTrustedProxies())
+}
+
+// newAuditEvent returns an event attributed to the user, API key and client
+// of the context.
+func newAuditEvent(ctx context.Context, action models.AuditAction, operation string) models.AuditEvent {
+	ret := models.AuditEvent{
+		Action:    action,
+		Operation: operation,
+		IPAddress: session.GetClientIP(ctx),
+	}
+
+	if userID := session.GetCurrentUserID(ctx); userID != nil {
+		ret.Username = *userID
+	}
+
+	if apiKey := session.GetAPIKey(ctx); apiKey != nil {
+		name := apiKey.Name
+		if name == "" {
+			name = configuredAPIKeyName
+		}
+		ret.APIKeyName = &name
+	}
+
+	return ret
+}
+
+// recordAuthEvent records an authentication event of the request. username
+// is the name of the user logging in or out, or the name given to a failed
+// login.
+func recordAuthEvent(r *http.Request, action models.AuditAction, operation string, username string) {
+	recordAuthError(r, action, operation, username, nil)
+}
+
+// recordAuthError records an authentication event of the request with the
+// reason it failed, if err is not nil.
+func recordAuthError(r *http.Request, action models.AuditAction, operation string, username string, err error) {
+	ctx := r.Context()
+
+	e := newAuditEvent(ctx, action, operation)
+	e.IPAddress = clientIP(r)
+	e.Username = username
+
+	if err != nil {
+		msg := err.Error()
+		e.Error = &msg
+	}
+
+	manager.GetInstance().AuditService.Record(ctx, e)
+}
+
+// auditMiddleware records mutations in the audit log, with the ids of the
+// objects they act on, a summary of their arguments and their error, if
+// any.
+func auditMiddleware(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != "Mutation" || fc.Field.Field == nil {
+		return next(ctx)
+	}
+
+	res, err := next(ctx)
+
+	var args map[string]interface{}
+	if graphql.HasOperationContext(ctx) {
+		args = fc.Field.ArgumentMap(graphql.GetOperationContext(ctx).Variables)
+	}
+
+	e := newAuditEvent(ctx, models.AuditActionMutation, fc.Field.Name)
+	e.TargetIDs = audit.TargetIDs(args)
+	e.Arguments = audit.SummariseArguments(args)
+
+	// the target of mutations creating objects is the created object
+	if len(e.TargetIDs) == 0 {
+		if id := resultID(res); id != "" {
+			e.TargetIDs = []string{id}
+		}
+	}
+
+	if err != nil {
+		msg := err.Error()
+		e.Error = &msg
+	}
+
+	manager.GetInstance().AuditService.Record(ctx, e)
+
+	return res, err
+}
+
+// resultID returns the id of the object returned by a mutation, or an empty
+// string if it did not return an object with an id.
+func resultID(res interface{}) string {
+	v := reflect.ValueOf(res)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	id := v.FieldByName("ID")
+	switch id.Kind() {
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(id.Int(), 10)
+	case reflect.String:
+		return id.String()
+	default:
+		return ""
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
@@ -11,6 +13,7 @@ import (
 	"github.com/stashapp/stash/internal/manager"
 	"github.com/stashapp/stash/internal/manager/config"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/session"
 )
 
@@ -22,6 +25,8 @@ const (
 		"This is extremely dangerous! The whole world can see your your stash page and browse your files! " +
 		"Stash is not answering any other requests to protect your privacy. " +
 		"Please read the log entry or visit https://docs.stashapp.cc/networking/authentication-required-when-accessing-stash-from-the-internet"
+
+	totpEnrollmentRequiredErrMsg = "Two-factor authentication is required for admins. Log in with a password to enroll."
 )
 
 func allowUnauthenticated(r *http.Request) bool {
@@ -40,13 +45,19 @@ func authenticateHandler() func(http.Handler) http.Handler {
 				return
 			}
 
-			userID, err := manager.GetInstance().SessionStore.Authenticate(w, r)
+			userID, apiKey, err := manager.GetInstance().SessionStore.Authenticate(w, r)
 			if err != nil {
-				if errors.Is(err, session.ErrUnauthorized) {
-					http.Error(w, err.Error(), http.StatusInternalServerError)
+				var blockedErr *session.LoginBlockedError
+				if errors.As(err, &blockedErr) {
+					writeTooManyRequests(w, blockedErr.Until)
 					return
 				}
 
+				// unknown or revoked API key
+				if errors.Is(err, session.ErrUnauthorized) {
+					recordAuthEvent(r, models.AuditActionLoginFailed, auditOperationAPIKey, "")
+				}
+
 				// unauthorized error
 				w.Header().Add("WWW-Authenticate", "FormBased")
 				w.WriteHeader(http.StatusUnauthorized)
@@ -73,9 +84,37 @@ func authenticateHandler() func(http.Handler) http.Handler {
 
 			ctx := r.Context()
 
+			user, err := manager.GetInstance().UserService.Resolve(ctx, userID)
+			if err != nil {
+				logger.Errorf("Error resolving user: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			// users which no longer exist are unauthenticated
+			if user == nil {
+				userID = ""
+			}
+
 			if c.HasCredentials() {
 				// authentication is required
 				if userID == "" && !allowUnauthenticated(r) {
+					// requests with a valid share link may access the shared
+					// content without logging in
+					if link := resolveShareLink(r); link != nil {
+						ctx, err = withShareLinkRestriction(ctx, link)
+						if err != nil {
+							logger.Errorf("Error resolving share link restriction: %v", err)
+							w.WriteHeader(http.StatusInternalServerError)
+							return
+						}
+
+						ctx = withShareLink(ctx, link)
+						ctx = session.SetClientIP(ctx, clientIP(r))
+						next.ServeHTTP(w, r.WithContext(ctx))
+						return
+					}
+
 					// if graphql or a non-webpage was requested, we just return a forbidden error
 					ext := path.Ext(r.URL.Path)
 					if r.URL.Path == gqlEndpoint || (ext != "" && ext != ".html") {
@@ -102,7 +141,20 @@ func authenticateHandler() func(http.Handler) http.Handler {
 				}
 			}
 
-			ctx = session.SetCurrentUserID(ctx, userID)
+			if totpEnrollmentRequired(c, user) && !allowUnauthenticated(r) {
+				http.Error(w, totpEnrollmentRequiredErrMsg, http.StatusForbidden)
+				return
+			}
+
+			if apiKey != nil && user != nil {
+				if scope, ok := apiKeyRouteScope(r.URL.Path); ok && !apiKey.HasScope(scope) {
+					http.Error(w, fmt.Sprintf("api key requires the %s scope", scope), http.StatusForbidden)
+					return
+				}
+			}
+
+			ctx = withCurrentUser(ctx, userID, user, apiKey, sessionRestrictionProfileID(r, apiKey))
+			ctx = session.SetClientIP(ctx, clientIP(r))
 
 			r = r.WithContext(ctx)
 
@@ -110,3 +162,128 @@ func authenticateHandler() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// currentUserHandler sets the current user of requests which are not passed
+// through authenticateHandler, such as the GraphQL requests of JS plugins.
+func currentUserHandler() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			userID, apiKey, err := manager.GetInstance().SessionStore.Authenticate(w, r)
+			if err != nil {
+				var blockedErr *session.LoginBlockedError
+				if errors.As(err, &blockedErr) {
+					writeTooManyRequests(w, blockedErr.Until)
+					return
+				}
+
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			user, err := manager.GetInstance().UserService.Resolve(ctx, userID)
+			if err != nil {
+				logger.Errorf("Error resolving user: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			if user == nil {
+				userID = ""
+			}
+
+			if totpEnrollmentRequired(config.GetInstance(), user) {
+				http.Error(w, totpEnrollmentRequiredErrMsg, http.StatusForbidden)
+				return
+			}
+
+			ctx = withCurrentUser(ctx, userID, user, apiKey, sessionRestrictionProfileID(r, apiKey))
+			ctx = session.SetClientIP(ctx, clientIP(r))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// totpEnrollmentRequired returns true if the user is an admin who must
+// enroll in two-factor authentication before making requests. They may only
+// enroll by logging in with a password, so this covers sessions created
+// before it was required, single sign-on, reverse proxy authentication and
+// API keys, including the configured API key.
+func totpEnrollmentRequired(c credentialsChecker, user *models.User) bool {
+	return c.HasCredentials() && user != nil && manager.GetInstance().UserService.TOTPEnrollmentRequired(user)
+}
+
+// withCurrentUser sets the current user, the API key they were
+// authenticated with and their content restriction on the context.
+// sessionProfileID is the restriction profile of the session or API key. The
+// scene activity of users other than the owner is stored separately from
+// the owner's.
+func withCurrentUser(ctx context.Context, userID string, user *models.User, apiKey *session.APIKey, sessionProfileID int) context.Context {
+	ctx = session.SetCurrentUserID(ctx, userID)
+	ctx = session.SetCurrentUser(ctx, user)
+
+	if user != nil && apiKey != nil {
+		ctx = session.SetAPIKey(ctx, apiKey)
+	}
+
+	if user != nil && user.ID != 0 && !manager.GetInstance().UserService.IsOwner(user.Username) {
+		ctx = models.WithActivityUserID(ctx, user.ID)
+	}
+
+	// the content is restricted by both the profile of the user and that of
+	// the session
+	ctx = session.SetRestrictionProfileID(ctx, sessionProfileID)
+
+	var restrictionProfileIDs []int
+	if user != nil && user.RestrictionProfileID != nil {
+		restrictionProfileIDs = append(restrictionProfileIDs, *user.RestrictionProfileID)
+	}
+	if sessionProfileID != 0 {
+		restrictionProfileIDs = append(restrictionProfileIDs, sessionProfileID)
+	}
+	if len(restrictionProfileIDs) > 0 {
+		ctx = models.WithRestrictionProfileIDs(ctx, restrictionProfileIDs)
+	}
+
+	return ctx
+}
+
+// sessionRestrictionProfileID returns the id of the restriction profile of
+// the session of the request, or 0 if there is none. Requests authenticated
+// by API key do not use the session, but the restriction profile of the
+// session the key was created from.
+func sessionRestrictionProfileID(r *http.Request, apiKey *session.APIKey) int {
+	if apiKey != nil {
+		return apiKey.RestrictionProfileID
+	}
+
+	return manager.GetInstance().SessionStore.GetSessionRestrictionProfileID(r)
+}
+
+// getURLAPIKey returns the API key to add to URLs returned to the client, so
+// that external players may request them. It is the key the request was
+// authenticated with, or else a short-lived key of the current user which
+// only permits streams. The configured API key is never added to URLs
+// returned to requests not authenticated with it. The key is restricted like
+// the session.
+func getURLAPIKey(ctx context.Context) string {
+	if apiKey := session.GetAPIKey(ctx); apiKey != nil {
+		return apiKey.Key
+	}
+
+	// the owner is not stored until the database is ready
+	u := session.GetCurrentUser(ctx)
+	if u == nil || u.ID == 0 || !config.GetInstance().HasCredentials() {
+		return ""
+	}
+
+	key, err := manager.GetInstance().UserService.StreamAPIKey(ctx, u.ID, session.GetRestrictionProfileID(ctx))
+	if err != nil {
+		logger.Errorf("Error getting stream api key: %v", err)
+		return ""
+	}
+
+	return key
+}
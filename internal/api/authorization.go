@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+const hasRoleDirectiveName = "hasRole"
+
+var ErrForbidden = errors.New("forbidden")
+
+// defaultRoles are the roles required by root fields without the hasRole
+// directive, by the name of the root type.
+var defaultRoles = map[string]models.UserRole{
+	"Query":        models.UserRoleViewer,
+	"Mutation":     models.UserRoleEditor,
+	"Subscription": models.UserRoleViewer,
+}
+
+// defaultAPIKeyScopes are the API key scopes which permit root fields, by
+// the name of the root type.
+var defaultAPIKeyScopes = map[string]models.APIKeyScope{
+	"Query":        models.APIKeyScopeRead,
+	"Subscription": models.APIKeyScopeRead,
+	"Mutation":     models.APIKeyScopeMutate,
+}
+
+// pluginFields are the root fields which API keys with the plugin scope may
+// access, so that plugin tasks may be listed, run and polled.
+var pluginFields = map[string]bool{
+	"plugins":       true,
+	"pluginTasks":   true,
+	"runPluginTask": true,
+	"findJob":       true,
+}
+
+type credentialsChecker interface {
+	HasCredentials() bool
+}
+
+// checkRole returns an error if the current user does not have at least the
+// role. Roles are not enforced when no credentials are configured. Restricted
+// sessions do not have the admin role, so that they cannot change the
+// configuration or lift the restriction.
+func checkRole(ctx context.Context, c credentialsChecker, role models.UserRole) error {
+	if !c.HasCredentials() {
+		return nil
+	}
+
+	u := session.GetCurrentUser(ctx)
+	if u == nil || !u.HasRole(role) {
+		return fmt.Errorf("%w: %s role required", ErrForbidden, role)
+	}
+
+	if role == models.UserRoleAdmin && models.IsRestricted(ctx) {
+		return fmt.Errorf("%w: %s role not available to restricted sessions", ErrForbidden, role)
+	}
+
+	return nil
+}
+
+// isAdmin returns true if the current user is an admin, or if no credentials
+// are configured.
+func isAdmin(ctx context.Context) bool {
+	return checkRole(ctx, config.GetInstance(), models.UserRoleAdmin) == nil
+}
+
+// checkAPIKeyScope returns an error if the request was authenticated with
+// an API key without a scope permitting the root field.
+func checkAPIKeyScope(ctx context.Context, object string, field string) error {
+	scope, isRoot := defaultAPIKeyScopes[object]
+	if !isRoot || session.HasAPIKeyScope(ctx, scope) {
+		return nil
+	}
+
+	if pluginFields[field] && session.HasAPIKeyScope(ctx, models.APIKeyScopePlugin) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: api key requires the %s scope", ErrForbidden, scope)
+}
+
+// apiKeyRouteScope returns the API key scope which permits requests for the
+// path. GraphQL requests are checked by field instead.
+func apiKeyRouteScope(path string) (models.APIKeyScope, bool) {
+	switch {
+	case path == gqlEndpoint:
+		return "", false
+	case path == "/javascript" || path == "/css":
+		// plugin javascript and css
+		return models.APIKeyScopePlugin, true
+	case strings.HasPrefix(path, playgroundEndpoint):
+		return models.APIKeyScopeRead, true
+	default:
+		return models.APIKeyScopeStream, true
+	}
+}
+
+func hasRoleDirective(ctx context.Context, obj interface{}, next graphql.Resolver, role models.UserRole) (interface{}, error) {
+	if err := checkRole(ctx, config.GetInstance(), role); err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+// defaultRoleMiddleware enforces the API key scopes of root fields, and the
+// default roles of root fields without the hasRole directive.
+func defaultRoleMiddleware(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Field.Field == nil || fc.Field.Definition == nil {
+		return next(ctx)
+	}
+
+	if err := checkAPIKeyScope(ctx, fc.Object, fc.Field.Name); err != nil {
+		return nil, err
+	}
+
+	role, isRoot := defaultRoles[fc.Object]
+	if !isRoot || fc.Field.Definition.Directives.ForName(hasRoleDirectiveName) != nil {
+		return next(ctx)
+	}
+
+	if err := checkRole(ctx, config.GetInstance(), role); err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type credentialsConfig bool
+
+func (c credentialsConfig) HasCredentials() bool {
+	return bool(c)
+}
+
+func TestCheckRole(t *testing.T) {
+	withUser := func(role models.UserRole) context.Context {
+		return session.SetCurrentUser(context.Background(), &models.User{Role: role})
+	}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		hasCredentials bool
+		role           models.UserRole
+		wantErr        bool
+	}{
+		{"no credentials", context.Background(), false, models.UserRoleAdmin, false},
+		{"no user", context.Background(), true, models.UserRoleViewer, true},
+		{"admin", withUser(models.UserRoleAdmin), true, models.UserRoleAdmin, false},
+		{"editor for viewer", withUser(models.UserRoleEditor), true, models.UserRoleViewer, false},
+		{"editor for admin", withUser(models.UserRoleEditor), true, models.UserRoleAdmin, true},
+		{"viewer for editor", withUser(models.UserRoleViewer), true, models.UserRoleEditor, true},
+		{"restricted admin", models.WithRestrictionProfileIDs(withUser(models.UserRoleAdmin), []int{1}), true, models.UserRoleAdmin, true},
+		{"restricted admin for editor", models.WithRestrictionProfileIDs(withUser(models.UserRoleAdmin), []int{1}), true, models.UserRoleEditor, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRole(tt.ctx, credentialsConfig(tt.hasCredentials), tt.role)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrForbidden)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckAPIKeyScope(t *testing.T) {
+	withKey := func(scopes ...models.APIKeyScope) context.Context {
+		return session.SetAPIKey(context.Background(), &session.APIKey{Scopes: scopes})
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		object  string
+		field   string
+		wantErr bool
+	}{
+		{"no key", context.Background(), "Mutation", "sceneUpdate", false},
+		{"unrestricted key", session.SetAPIKey(context.Background(), &session.APIKey{}), "Mutation", "sceneUpdate", false},
+		{"read query", withKey(models.APIKeyScopeRead), "Query", "findScenes", false},
+		{"read subscription", withKey(models.APIKeyScopeRead), "Subscription", "jobsSubscribe", false},
+		{"read mutation", withKey(models.APIKeyScopeRead), "Mutation", "sceneUpdate", true},
+		{"mutate mutation", withKey(models.APIKeyScopeMutate), "Mutation", "sceneUpdate", false},
+		{"mutate query", withKey(models.APIKeyScopeMutate), "Query", "findScenes", true},
+		{"stream query", withKey(models.APIKeyScopeStream), "Query", "findScenes", true},
+		{"plugin task", withKey(models.APIKeyScopePlugin), "Mutation", "runPluginTask", false},
+		{"plugin job", withKey(models.APIKeyScopePlugin), "Query", "findJob", false},
+		{"plugin mutation", withKey(models.APIKeyScopePlugin), "Mutation", "sceneUpdate", true},
+		{"non-root field", withKey(models.APIKeyScopeStream), "Scene", "title", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAPIKeyScope(tt.ctx, tt.object, tt.field)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrForbidden)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -13,4 +13,6 @@ const (
 	tagKey
 	downloadKey
 	imageKey
+	playlistKey
+	shareLinkKey
 )
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/filterquery"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// applyFilterQuery applies the criteria of a text filter query to
+// objectFilter, which must be a pointer to a filter type. It returns a copy
+// of findFilter with the free text of the query added to its search term.
+// It must be called within a transaction.
+func (r *Resolver) applyFilterQuery(ctx context.Context, query string, objectFilter interface{}, findFilter *models.FindFilterType) (*models.FindFilterType, error) {
+	text, err := filterquery.Apply(ctx, query, objectFilter, &filterquery.Resolver{
+		Performer: r.repository.Performer,
+		Studio:    r.repository.Studio,
+		Tag:       r.repository.Tag,
+		Movie:     r.repository.Movie,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if text == "" {
+		return findFilter, nil
+	}
+
+	ret := &models.FindFilterType{}
+	if findFilter != nil {
+		*ret = *findFilter
+	}
+
+	if ret.Q != nil && *ret.Q != "" {
+		text = *ret.Q + " " + text
+	}
+	ret.Q = &text
+
+	return ret, nil
+}
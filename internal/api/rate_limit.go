@@ -0,0 +1,123 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+// rateLimitLogInterval is the minimum interval between warnings that
+// requests are being rate limited.
+const rateLimitLogInterval = time.Minute
+
+type rateLimitConfig interface {
+	// GetGraphQLRateLimit returns the maximum number of requests per second,
+	// or 0 if requests are not limited.
+	GetGraphQLRateLimit() float64
+	// GetGraphQLRateLimitBurst returns the number of requests which may be
+	// made at once.
+	GetGraphQLRateLimitBurst() int
+}
+
+// rateLimiter is a token bucket shared by all clients. The rate and burst
+// size are passed on each request, so that changes to the configuration
+// apply immediately.
+type rateLimiter struct {
+	mutex   sync.Mutex
+	tokens  float64
+	last    time.Time
+	lastLog time.Time
+}
+
+// allow takes a token from the bucket, returning true if there was one. If
+// there wasn't, it returns the time until there will be.
+func (l *rateLimiter) allow(now time.Time, rate float64, burst int) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// the bucket starts full
+	if l.last.IsZero() {
+		l.tokens = float64(burst)
+	} else if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * rate
+	}
+	l.last = now
+
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - l.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// shouldLog returns true if a warning about rate limited requests should be
+// logged, so that clients exceeding the limit don't flood the log.
+func (l *rateLimiter) shouldLog(now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Sub(l.lastLog) < rateLimitLogInterval {
+		return false
+	}
+
+	l.lastLog = now
+	return true
+}
+
+// rateLimitHandler limits the rate of requests to the handler to the
+// configured rate, responding to requests over the limit with 429 Too Many
+// Requests.
+func rateLimitHandler(c rateLimitConfig) func(http.Handler) http.Handler {
+	l := &rateLimiter{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rate := c.GetGraphQLRateLimit()
+			if rate <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now()
+			allowed, wait := l.allow(now, rate, c.GetGraphQLRateLimitBurst())
+			if !allowed {
+				if l.shouldLog(now) {
+					logger.Warnf("GraphQL requests are exceeding the rate limit of %g per second. Last request from %s", rate, clientIP(r))
+				}
+
+				writeTooManyRequests(w, now.Add(wait))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeTooManyRequests responds with 429 Too Many Requests, telling the
+// client to retry at the given time.
+func writeTooManyRequests(w http.ResponseWriter, retryAt time.Time) {
+	setRetryAfter(w, retryAt)
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+// setRetryAfter sets the Retry-After header to the number of seconds until
+// the given time.
+func setRetryAfter(w http.ResponseWriter, retryAt time.Time) {
+	seconds := int(math.Ceil(time.Until(retryAt).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
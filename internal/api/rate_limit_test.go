@@ -0,0 +1,44 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := &rateLimiter{}
+
+	const (
+		rate  = 2
+		burst = 3
+	)
+
+	// the burst is allowed at once
+	for i := 0; i < burst; i++ {
+		allowed, _ := l.allow(now, rate, burst)
+		assert.True(t, allowed)
+	}
+
+	allowed, wait := l.allow(now, rate, burst)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// tokens are added at the rate
+	now = now.Add(500 * time.Millisecond)
+	allowed, _ = l.allow(now, rate, burst)
+	assert.True(t, allowed)
+	allowed, _ = l.allow(now, rate, burst)
+	assert.False(t, allowed)
+
+	// up to the burst size
+	now = now.Add(time.Hour)
+	for i := 0; i < burst; i++ {
+		allowed, _ := l.allow(now, rate, burst)
+		assert.True(t, allowed)
+	}
+	allowed, _ = l.allow(now, rate, burst)
+	assert.False(t, allowed)
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin"
 	"github.com/stashapp/stash/pkg/scraper"
+	"github.com/stashapp/stash/pkg/session"
 	"github.com/stashapp/stash/pkg/txn"
+	"github.com/stashapp/stash/pkg/user"
 )
 
 var (
@@ -45,6 +47,10 @@ func (r *Resolver) scraperCache() *scraper.Cache {
 	return manager.GetInstance().ScraperCache
 }
 
+func (r *Resolver) userService() *user.Service {
+	return manager.GetInstance().UserService
+}
+
 func (r *Resolver) Gallery() GalleryResolver {
 	return &galleryResolver{r}
 }
@@ -81,6 +87,36 @@ func (r *Resolver) Subscription() SubscriptionResolver {
 func (r *Resolver) Tag() TagResolver {
 	return &tagResolver{r}
 }
+func (r *Resolver) Playlist() PlaylistResolver {
+	return &playlistResolver{r}
+}
+func (r *Resolver) PlaylistItem() PlaylistItemResolver {
+	return &playlistItemResolver{r}
+}
+func (r *Resolver) TagImplication() TagImplicationResolver {
+	return &tagImplicationResolver{r}
+}
+func (r *Resolver) User() UserResolver {
+	return &userResolver{r}
+}
+func (r *Resolver) APIKey() APIKeyResolver {
+	return &apiKeyResolver{r}
+}
+func (r *Resolver) ShareLink() ShareLinkResolver {
+	return &shareLinkResolver{r}
+}
+func (r *Resolver) RestrictionProfile() RestrictionProfileResolver {
+	return &restrictionProfileResolver{r}
+}
+func (r *Resolver) SceneUserActivity() SceneUserActivityResolver {
+	return &sceneUserActivityResolver{r}
+}
+func (r *Resolver) SceneActivityTotals() SceneActivityTotalsResolver {
+	return &sceneActivityTotalsResolver{r}
+}
+func (r *Resolver) LibraryStatistics() LibraryStatisticsResolver {
+	return &libraryStatisticsResolver{r}
+}
 
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
@@ -95,9 +131,34 @@ type imageResolver struct{ *Resolver }
 type studioResolver struct{ *Resolver }
 type movieResolver struct{ *Resolver }
 type tagResolver struct{ *Resolver }
+type playlistResolver struct{ *Resolver }
+type playlistItemResolver struct{ *Resolver }
+type tagImplicationResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
+type apiKeyResolver struct{ *Resolver }
+type shareLinkResolver struct{ *Resolver }
+type restrictionProfileResolver struct{ *Resolver }
+type sceneUserActivityResolver struct{ *Resolver }
+type sceneActivityTotalsResolver struct{ *Resolver }
+type libraryStatisticsResolver struct{ *Resolver }
 
 func (r *Resolver) withTxn(ctx context.Context, fn func(ctx context.Context) error) error {
-	return txn.WithTxn(ctx, r.txnManager, fn)
+	return txn.WithTxn(withEditSource(ctx), r.txnManager, fn)
+}
+
+// withEditSource attributes changes made by the request to the plugin or
+// user that made it, so that they are recorded in the revision history.
+func withEditSource(ctx context.Context) context.Context {
+	if models.EditSourceFromContext(ctx) != "" {
+		return ctx
+	}
+
+	source := models.EditSourceUI
+	if session.IsPluginRequest(ctx) {
+		source = models.EditSourcePlugin
+	}
+
+	return models.WithEditSource(ctx, source)
 }
 
 func (r *Resolver) withReadTxn(ctx context.Context, fn func(ctx context.Context) error) error {
@@ -179,6 +240,10 @@ func (r *queryResolver) Stats(ctx context.Context) (*StatsResultType, error) {
 	return &ret, nil
 }
 
+func (r *queryResolver) LibraryStatistics(ctx context.Context) (*LibraryStatistics, error) {
+	return &LibraryStatistics{}, nil
+}
+
 func (r *queryResolver) Version(ctx context.Context) (*Version, error) {
 	version, hash, buildtime := GetVersion()
 
@@ -272,3 +337,36 @@ func firstError(errs []error) error {
 
 	return nil
 }
+
+// The loaders return nil for content hidden by a restriction profile. The
+// functions below remove it from lists of related content.
+
+func visibleScenes(scenes []*models.Scene) []*models.Scene {
+	ret := make([]*models.Scene, 0, len(scenes))
+	for _, s := range scenes {
+		if s != nil {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func visibleImages(images []*models.Image) []*models.Image {
+	ret := make([]*models.Image, 0, len(images))
+	for _, i := range images {
+		if i != nil {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func visibleGalleries(galleries []*models.Gallery) []*models.Gallery {
+	ret := make([]*models.Gallery, 0, len(galleries))
+	for _, g := range galleries {
+		if g != nil {
+			ret = append(ret, g)
+		}
+	}
+	return ret
+}
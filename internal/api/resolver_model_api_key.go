@@ -0,0 +1,18 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *apiKeyResolver) User(ctx context.Context, obj *models.APIKey) (ret *models.User, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.User.Find(ctx, obj.UserID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
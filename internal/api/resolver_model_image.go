@@ -22,6 +22,7 @@ func convertImageFile(f *file.ImageFile) *ImageFile {
 		Size:           f.Size,
 		Width:          f.Width,
 		Height:         f.Height,
+		Metadata:       &f.Metadata,
 		CreatedAt:      f.CreatedAt,
 		UpdatedAt:      f.UpdatedAt,
 		Fingerprints:   resolveFingerprints(f.Base()),
@@ -181,7 +182,7 @@ func (r *imageResolver) Galleries(ctx context.Context, obj *models.Image) (ret [
 
 	var errs []error
 	ret, errs = loaders.From(ctx).GalleryByID.LoadAll(obj.GalleryIDs.List())
-	return ret, firstError(errs)
+	return visibleGalleries(ret), firstError(errs)
 }
 
 func (r *imageResolver) Rating(ctx context.Context, obj *models.Image) (*int, error) {
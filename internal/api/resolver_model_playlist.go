@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/internal/api/loaders"
+	"github.com/stashapp/stash/internal/api/urlbuilders"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *playlistResolver) SavedFilter(ctx context.Context, obj *models.Playlist) (ret *models.SavedFilter, err error) {
+	if obj.SavedFilterID == nil {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.SavedFilter.Find(ctx, *obj.SavedFilterID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *playlistResolver) Items(ctx context.Context, obj *models.Playlist) (ret []*models.PlaylistItem, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.PlaylistItemResolver().Items(ctx, obj)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *playlistResolver) Paths(ctx context.Context, obj *models.Playlist) (*PlaylistPathsType, error) {
+	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+	apiKey := getURLAPIKey(ctx)
+	builder := urlbuilders.NewPlaylistURLBuilder(baseURL, obj)
+
+	return &PlaylistPathsType{
+		M3u:  builder.GetM3UURL(apiKey).String(),
+		Xspf: builder.GetXSPFURL(apiKey).String(),
+	}, nil
+}
+
+func (r *playlistItemResolver) ID(ctx context.Context, obj *models.PlaylistItem) (*string, error) {
+	// items of smart playlists are not stored
+	if obj.ID == 0 {
+		return nil, nil
+	}
+
+	ret := strconv.Itoa(obj.ID)
+	return &ret, nil
+}
+
+func (r *playlistItemResolver) Scene(ctx context.Context, obj *models.PlaylistItem) (*models.Scene, error) {
+	if obj.SceneID == nil {
+		return nil, nil
+	}
+
+	return loaders.From(ctx).SceneByID.Load(*obj.SceneID)
+}
+
+func (r *playlistItemResolver) SceneMarker(ctx context.Context, obj *models.PlaylistItem) (ret *models.SceneMarker, err error) {
+	if obj.SceneMarkerID == nil {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.SceneMarker.Find(ctx, *obj.SceneMarkerID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *playlistItemResolver) Image(ctx context.Context, obj *models.PlaylistItem) (*models.Image, error) {
+	if obj.ImageID == nil {
+		return nil, nil
+	}
+
+	return loaders.From(ctx).ImageByID.Load(*obj.ImageID)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stashapp/stash/internal/api/loaders"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *restrictionProfileResolver) Tags(ctx context.Context, obj *models.RestrictionProfile) (ret []*models.Tag, err error) {
+	var errs []error
+	ret, errs = loaders.From(ctx).TagByID.LoadAll(obj.TagIDs)
+	return ret, firstError(errs)
+}
+
+func (r *restrictionProfileResolver) Studios(ctx context.Context, obj *models.RestrictionProfile) (ret []*models.Studio, err error) {
+	var errs []error
+	ret, errs = loaders.From(ctx).StudioByID.LoadAll(obj.StudioIDs)
+	return ret, firstError(errs)
+}
+
+func (r *restrictionProfileResolver) Performers(ctx context.Context, obj *models.RestrictionProfile) (ret []*models.Performer, err error) {
+	var errs []error
+	ret, errs = loaders.From(ctx).PerformerByID.LoadAll(obj.PerformerIDs)
+	return ret, firstError(errs)
+}
+
+// filterMap returns the filter as a map, omitting the unset criteria.
+func filterMap(filter interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret map[string]interface{}
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return nil, err
+	}
+
+	omitNulls(ret)
+	return ret, nil
+}
+
+func omitNulls(m map[string]interface{}) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case nil:
+			delete(m, k)
+		case map[string]interface{}:
+			omitNulls(v)
+		}
+	}
+}
+
+func (r *restrictionProfileResolver) SceneFilter(ctx context.Context, obj *models.RestrictionProfile) (map[string]interface{}, error) {
+	if obj.SceneFilter == nil {
+		return nil, nil
+	}
+
+	return filterMap(obj.SceneFilter)
+}
+
+func (r *restrictionProfileResolver) ImageFilter(ctx context.Context, obj *models.RestrictionProfile) (map[string]interface{}, error) {
+	if obj.ImageFilter == nil {
+		return nil, nil
+	}
+
+	return filterMap(obj.ImageFilter)
+}
+
+func (r *restrictionProfileResolver) GalleryFilter(ctx context.Context, obj *models.RestrictionProfile) (map[string]interface{}, error) {
+	if obj.GalleryFilter == nil {
+		return nil, nil
+	}
+
+	return filterMap(obj.GalleryFilter)
+}
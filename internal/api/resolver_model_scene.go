@@ -159,6 +159,27 @@ func (r *sceneResolver) Rating100(ctx context.Context, obj *models.Scene) (*int,
 	return obj.Rating, nil
 }
 
+func (r *sceneResolver) UserActivity(ctx context.Context, obj *models.Scene) (ret []*models.SceneUserActivity, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Scene.GetUserActivity(ctx, obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *sceneResolver) ActivityTotals(ctx context.Context, obj *models.Scene) (*models.SceneActivityTotals, error) {
+	activity, err := r.UserActivity(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := models.TotalSceneActivity(activity)
+	return &ret, nil
+}
+
 func resolveFingerprints(f *file.BaseFile) []*Fingerprint {
 	ret := make([]*Fingerprint, len(f.Fingerprints))
 
@@ -187,7 +208,7 @@ func (r *sceneResolver) Paths(ctx context.Context, obj *models.Scene) (*ScenePat
 	builder := urlbuilders.NewSceneURLBuilder(baseURL, obj)
 	screenshotPath := builder.GetScreenshotURL()
 	previewPath := builder.GetStreamPreviewURL()
-	streamPath := builder.GetStreamURL(config.GetAPIKey()).String()
+	streamPath := builder.GetStreamURL(getURLAPIKey(ctx)).String()
 	webpPath := builder.GetStreamPreviewImageURL()
 	objHash := obj.GetHash(config.GetVideoFileNamingAlgorithm())
 	vttPath := builder.GetSpriteVTTURL(objHash)
@@ -252,7 +273,7 @@ func (r *sceneResolver) Galleries(ctx context.Context, obj *models.Scene) (ret [
 
 	var errs []error
 	ret, errs = loaders.From(ctx).GalleryByID.LoadAll(obj.GalleryIDs.List())
-	return ret, firstError(errs)
+	return visibleGalleries(ret), firstError(errs)
 }
 
 func (r *sceneResolver) Studio(ctx context.Context, obj *models.Scene) (ret *models.Studio, err error) {
@@ -377,7 +398,7 @@ func (r *sceneResolver) SceneStreams(ctx context.Context, obj *models.Scene) ([]
 
 	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
 	builder := urlbuilders.NewSceneURLBuilder(baseURL, obj)
-	apiKey := config.GetAPIKey()
+	apiKey := getURLAPIKey(ctx)
 
 	return manager.GetSceneStreamPaths(obj, builder.GetStreamURL(apiKey), config.GetMaxStreamingTranscodeSize())
 }
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *sceneUserActivityResolver) User(ctx context.Context, obj *models.SceneUserActivity) (ret *models.User, err error) {
+	if obj.UserID == nil {
+		// the activity of the owner is stored with the scene
+		return r.userService().Resolve(ctx, config.GetInstance().GetUsername())
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.User.Find(ctx, *obj.UserID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *sceneUserActivityResolver) Rating100(ctx context.Context, obj *models.SceneUserActivity) (*int, error) {
+	return obj.Rating, nil
+}
+
+func (r *sceneActivityTotalsResolver) Rating100(ctx context.Context, obj *models.SceneActivityTotals) (*int, error) {
+	return obj.Rating, nil
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *shareLinkResolver) User(ctx context.Context, obj *models.ShareLink) (ret *models.User, err error) {
+	if obj.UserID == nil {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.User.Find(ctx, *obj.UserID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *shareLinkResolver) URL(ctx context.Context, obj *models.ShareLink) (string, error) {
+	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+	return baseURL + shareEndpoint + "/" + manager.GetInstance().ShareLinkService.Token(obj), nil
+}
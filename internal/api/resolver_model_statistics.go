@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// LibraryStatistics is resolved field by field, so that only the requested
+// statistics are computed.
+type LibraryStatistics struct{}
+
+func (r *libraryStatisticsResolver) SceneGrowth(ctx context.Context, obj *LibraryStatistics, interval *models.StatisticsInterval) (ret []*models.GrowthPeriod, err error) {
+	i := models.StatisticsIntervalMonth
+	if interval != nil {
+		i = *interval
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Statistics.SceneGrowth(ctx, i)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) sceneStorage(ctx context.Context, breakdown models.StorageBreakdownType) (ret []*models.StorageBreakdown, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Statistics.SceneStorage(ctx, breakdown)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) StorageByStudio(ctx context.Context, obj *LibraryStatistics) ([]*models.StorageBreakdown, error) {
+	return r.sceneStorage(ctx, models.StorageBreakdownTypeStudio)
+}
+
+func (r *libraryStatisticsResolver) StorageByCodec(ctx context.Context, obj *LibraryStatistics) ([]*models.StorageBreakdown, error) {
+	return r.sceneStorage(ctx, models.StorageBreakdownTypeCodec)
+}
+
+func (r *libraryStatisticsResolver) StorageByResolution(ctx context.Context, obj *LibraryStatistics) ([]*models.StorageBreakdown, error) {
+	return r.sceneStorage(ctx, models.StorageBreakdownTypeResolution)
+}
+
+func (r *libraryStatisticsResolver) StorageByPath(ctx context.Context, obj *LibraryStatistics) (ret []*models.StorageBreakdown, err error) {
+	var paths []string
+	for _, s := range config.GetInstance().GetStashPaths() {
+		paths = append(paths, s.Path)
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Statistics.PathStorage(ctx, paths)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) UnorganizedSceneCount(ctx context.Context, obj *LibraryStatistics) (ret int, err error) {
+	organized := false
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Scene.QueryCount(ctx, &models.SceneFilterType{Organized: &organized}, nil)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) UnorganizedImageCount(ctx context.Context, obj *LibraryStatistics) (ret int, err error) {
+	organized := false
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Image.QueryCount(ctx, &models.ImageFilterType{Organized: &organized}, nil)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) UnorganizedGalleryCount(ctx context.Context, obj *LibraryStatistics) (ret int, err error) {
+	organized := false
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Gallery.QueryCount(ctx, &models.GalleryFilterType{Organized: &organized}, nil)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) UnidentifiedSceneCount(ctx context.Context, obj *LibraryStatistics) (ret int, err error) {
+	isMissing := "stash_id"
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Scene.QueryCount(ctx, &models.SceneFilterType{IsMissing: &isMissing}, nil)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) UnidentifiedPerformerCount(ctx context.Context, obj *LibraryStatistics) (ret int, err error) {
+	isMissing := "stash_id"
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Performer.QueryCount(ctx, &models.PerformerFilterType{IsMissing: &isMissing}, nil)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) WatchTime(ctx context.Context, obj *LibraryStatistics, interval *models.StatisticsInterval, limit *int) (ret []*models.WatchTimePeriod, err error) {
+	i := models.StatisticsIntervalWeek
+	if interval != nil {
+		i = *interval
+	}
+
+	l := 0
+	if limit != nil {
+		l = *limit
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Statistics.WatchTime(ctx, i, l)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) MostPlayedPerformers(ctx context.Context, obj *LibraryStatistics, limit *int) (ret []*models.PlayStatistic, err error) {
+	l := 0
+	if limit != nil {
+		l = *limit
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Statistics.MostPlayedPerformers(ctx, l)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *libraryStatisticsResolver) MostPlayedTags(ctx context.Context, obj *LibraryStatistics, limit *int) (ret []*models.PlayStatistic, err error) {
+	l := 0
+	if limit != nil {
+		l = *limit
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Statistics.MostPlayedTags(ctx, l)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
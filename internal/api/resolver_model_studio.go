@@ -28,6 +28,20 @@ func (r *studioResolver) ImagePath(ctx context.Context, obj *models.Studio) (*st
 	return &imagePath, nil
 }
 
+func (r *studioResolver) Images(ctx context.Context, obj *models.Studio) ([]*ProfileImage, error) {
+	var images []models.EntityImage
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		var err error
+		images, err = r.repository.Studio.GetImages(ctx, obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+	return translateProfileImages(images, urlbuilders.NewStudioURLBuilder(baseURL, obj).GetStudioImageByIDURL), nil
+}
+
 func (r *studioResolver) Aliases(ctx context.Context, obj *models.Studio) (ret []string, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
 		ret, err = r.repository.Studio.GetAliases(ctx, obj.ID)
@@ -113,3 +113,17 @@ func (r *tagResolver) ImagePath(ctx context.Context, obj *models.Tag) (*string,
 	imagePath := urlbuilders.NewTagURLBuilder(baseURL, obj).GetTagImageURL(hasImage)
 	return &imagePath, nil
 }
+
+func (r *tagResolver) Images(ctx context.Context, obj *models.Tag) ([]*ProfileImage, error) {
+	var images []models.EntityImage
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		var err error
+		images, err = r.repository.Tag.GetImages(ctx, obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
+	return translateProfileImages(images, urlbuilders.NewTagURLBuilder(baseURL, obj).GetTagImageByIDURL), nil
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/internal/api/loaders"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *tagImplicationResolver) SourceTag(ctx context.Context, obj *models.TagImplication) (*models.Tag, error) {
+	if obj.SourceTagID == nil {
+		return nil, nil
+	}
+
+	return loaders.From(ctx).TagByID.Load(*obj.SourceTagID)
+}
+
+func (r *tagImplicationResolver) SourcePerformer(ctx context.Context, obj *models.TagImplication) (*models.Performer, error) {
+	if obj.SourcePerformerID == nil {
+		return nil, nil
+	}
+
+	return loaders.From(ctx).PerformerByID.Load(*obj.SourcePerformerID)
+}
+
+func (r *tagImplicationResolver) ImpliedTag(ctx context.Context, obj *models.TagImplication) (*models.Tag, error) {
+	return loaders.From(ctx).TagByID.Load(obj.ImpliedTagID)
+}
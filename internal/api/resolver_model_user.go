@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *userResolver) Owner(ctx context.Context, obj *models.User) (bool, error) {
+	return r.userService().IsOwner(obj.Username), nil
+}
+
+func (r *userResolver) RestrictionProfile(ctx context.Context, obj *models.User) (ret *models.RestrictionProfile, err error) {
+	if obj.RestrictionProfileID == nil {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.RestrictionProfile.Find(ctx, *obj.RestrictionProfileID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
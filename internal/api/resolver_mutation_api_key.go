@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+var errScopedAPIKey = errors.New("api keys may not be managed with a scoped api key")
+
+// apiKeyUser returns the current user, who may manage API keys if they were
+// not authenticated with a scoped key.
+func apiKeyUser(ctx context.Context) (*models.User, error) {
+	if apiKey := session.GetAPIKey(ctx); apiKey != nil && apiKey.Scopes != nil {
+		return nil, fmt.Errorf("%w: %v", ErrForbidden, errScopedAPIKey)
+	}
+
+	current := session.GetCurrentUser(ctx)
+	if current == nil || current.ID == 0 {
+		return nil, fmt.Errorf("%w: no current user", ErrInput)
+	}
+
+	return current, nil
+}
+
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, input APIKeyCreateInput) (*APIKeyCreateResult, error) {
+	// keys are only restricted like the sessions of stream urls, so
+	// restricted users may not create them
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := apiKeyUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret APIKeyCreateResult
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		var err error
+		ret.APIKey, ret.Key, err = r.userService().CreateAPIKey(ctx, current.ID, input.Name, input.Scopes, input.ExpiresAt, nil)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	keyID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	current, err := apiKeyUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		apiKey, err := r.repository.APIKey.Find(ctx, keyID)
+		if err != nil {
+			return err
+		}
+
+		// don't reveal the keys of other users
+		if apiKey == nil || (apiKey.UserID != current.ID && !isAdmin(ctx)) {
+			return fmt.Errorf("api key with id %d not found", keyID)
+		}
+
+		return r.repository.APIKey.Destroy(ctx, keyID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/stashapp/stash/internal/manager"
 	"github.com/stashapp/stash/internal/manager/config"
@@ -260,6 +262,50 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input ConfigGen
 		c.Set(config.MaxSessionAge, *input.MaxSessionAge)
 	}
 
+	if input.AuditLogRetentionDays != nil {
+		if *input.AuditLogRetentionDays < 0 {
+			return makeConfigGeneralResult(), fmt.Errorf("audit log retention days must not be negative")
+		}
+		c.Set(config.AuditLogRetentionDays, *input.AuditLogRetentionDays)
+	}
+
+	if input.LoginMaxAttempts != nil {
+		if *input.LoginMaxAttempts < 0 {
+			return makeConfigGeneralResult(), fmt.Errorf("login max attempts must not be negative")
+		}
+		c.Set(config.LoginMaxAttempts, *input.LoginMaxAttempts)
+	}
+
+	if input.LoginMaxBlockMinutes != nil {
+		if *input.LoginMaxBlockMinutes < 1 {
+			return makeConfigGeneralResult(), fmt.Errorf("login max block minutes must be at least 1")
+		}
+		c.Set(config.LoginMaxBlockMinutes, *input.LoginMaxBlockMinutes)
+	}
+
+	if input.GraphqlRateLimit != nil {
+		if *input.GraphqlRateLimit < 0 {
+			return makeConfigGeneralResult(), fmt.Errorf("GraphQL rate limit must not be negative")
+		}
+		c.Set(config.GraphQLRateLimit, *input.GraphqlRateLimit)
+	}
+
+	if input.GraphqlRateLimitBurst != nil {
+		if *input.GraphqlRateLimitBurst < 0 {
+			return makeConfigGeneralResult(), fmt.Errorf("GraphQL rate limit burst must not be negative")
+		}
+		c.Set(config.GraphQLRateLimitBurst, *input.GraphqlRateLimitBurst)
+	}
+
+	if input.TotpRequiredForAdmins != nil {
+		// the sessions of admins created before it was required must log in
+		// again
+		if *input.TotpRequiredForAdmins && !c.GetTOTPRequiredForAdmins() {
+			c.Set(config.TOTPRequiredSince, time.Now().Unix())
+		}
+		c.Set(config.TOTPRequiredForAdmins, *input.TotpRequiredForAdmins)
+	}
+
 	if input.LogFile != nil {
 		c.Set(config.LogFile, input.LogFile)
 	}
@@ -314,6 +360,14 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input ConfigGen
 		c.Set(config.CreateGalleriesFromFolders, input.CreateGalleriesFromFolders)
 	}
 
+	if input.SetImageDateFromMetadata != nil {
+		c.Set(config.SetImageDateFromMetadata, *input.SetImageDateFromMetadata)
+	}
+
+	if input.SetImageTagsFromMetadata != nil {
+		c.Set(config.SetImageTagsFromMetadata, *input.SetImageTagsFromMetadata)
+	}
+
 	if input.CustomPerformerImageLocation != nil {
 		c.Set(config.CustomPerformerImageLocation, *input.CustomPerformerImageLocation)
 		initialiseCustomImages()
@@ -501,6 +555,19 @@ func (r *mutationResolver) ConfigureDlna(ctx context.Context, input ConfigDLNAIn
 		c.Set(config.DLNAVideoSortOrder, input.VideoSortOrder)
 	}
 
+	if input.RestrictionProfileID != nil {
+		profileID := 0
+		if *input.RestrictionProfileID != "" {
+			var err error
+			profileID, err = strconv.Atoi(*input.RestrictionProfileID)
+			if err != nil {
+				return makeConfigDLNAResult(), fmt.Errorf("invalid restriction profile id: %w", err)
+			}
+		}
+
+		c.Set(config.DLNARestrictionProfileID, profileID)
+	}
+
 	currentDLNAEnabled := c.GetDLNADefaultEnabled()
 	if input.Enabled != nil && *input.Enabled != currentDLNAEnabled {
 		c.Set(config.DLNADefaultEnabled, *input.Enabled)
@@ -602,6 +669,10 @@ func (r *mutationResolver) ConfigureDefaults(ctx context.Context, input ConfigDe
 }
 
 func (r *mutationResolver) GenerateAPIKey(ctx context.Context, input GenerateAPIKeyInput) (string, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return "", err
+	}
+
 	c := config.GetInstance()
 
 	var newAPIKey string
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *mutationResolver) RevertEdit(ctx context.Context, id string) (ret *models.Edit, err error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = models.WithEditOperation(ctx, models.EditOperationRevert)
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Edit
+
+		ret, err = qb.Find(ctx, idInt)
+		if err != nil {
+			return err
+		}
+
+		if ret == nil {
+			return fmt.Errorf("edit with id %d not found", idInt)
+		}
+
+		if err := qb.Revert(ctx, idInt); err != nil {
+			return err
+		}
+
+		ret, err = qb.Find(ctx, idInt)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
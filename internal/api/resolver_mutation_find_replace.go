@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/findreplace"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// findReplaceIDs returns the ids of the objects matched by the filter of the
+// input. It must be called within a transaction.
+func (r *mutationResolver) findReplaceIDs(ctx context.Context, input BulkFindReplaceInput) ([]int, error) {
+	all := models.PerPageAll
+	findFilter := &models.FindFilterType{
+		PerPage: &all,
+	}
+
+	// applyQuery applies the filter query of the input to objectFilter
+	applyQuery := func(objectFilter interface{}) error {
+		if input.Query == nil {
+			return nil
+		}
+
+		var err error
+		findFilter, err = r.applyFilterQuery(ctx, *input.Query, objectFilter, findFilter)
+		return err
+	}
+
+	var ret []int
+	switch input.EntityType {
+	case findreplace.EntityTypeScene:
+		filter := input.SceneFilter
+		if filter == nil {
+			filter = &models.SceneFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		result, err := r.repository.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: findFilter,
+			},
+			SceneFilter: filter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ret = result.IDs
+	case findreplace.EntityTypeImage:
+		filter := input.ImageFilter
+		if filter == nil {
+			filter = &models.ImageFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		result, err := r.repository.Image.Query(ctx, models.ImageQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: findFilter,
+			},
+			ImageFilter: filter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ret = result.IDs
+	case findreplace.EntityTypeGallery:
+		filter := input.GalleryFilter
+		if filter == nil {
+			filter = &models.GalleryFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		galleries, _, err := r.repository.Gallery.Query(ctx, filter, findFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range galleries {
+			ret = append(ret, g.ID)
+		}
+	case findreplace.EntityTypePerformer:
+		filter := input.PerformerFilter
+		if filter == nil {
+			filter = &models.PerformerFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		performers, _, err := r.repository.Performer.Query(ctx, filter, findFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range performers {
+			ret = append(ret, p.ID)
+		}
+	case findreplace.EntityTypeStudio:
+		filter := input.StudioFilter
+		if filter == nil {
+			filter = &models.StudioFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		studios, _, err := r.repository.Studio.Query(ctx, filter, findFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range studios {
+			ret = append(ret, s.ID)
+		}
+	case findreplace.EntityTypeMovie:
+		filter := input.MovieFilter
+		if filter == nil {
+			filter = &models.MovieFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		movies, _, err := r.repository.Movie.Query(ctx, filter, findFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range movies {
+			ret = append(ret, m.ID)
+		}
+	case findreplace.EntityTypeTag:
+		filter := input.TagFilter
+		if filter == nil {
+			filter = &models.TagFilterType{}
+		}
+		if err := applyQuery(filter); err != nil {
+			return nil, err
+		}
+
+		tags, _, err := r.repository.Tag.Query(ctx, filter, findFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			ret = append(ret, t.ID)
+		}
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) BulkFindReplace(ctx context.Context, input BulkFindReplaceInput) (*BulkFindReplaceResult, error) {
+	// changes are applied by a job, which acts on the whole library
+	if !input.Preview {
+		if err := checkUnrestricted(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	options := findreplace.Options{
+		EntityType:  input.EntityType,
+		Field:       input.Field,
+		Pattern:     input.Pattern,
+		Replacement: input.Replacement,
+	}
+
+	repo := r.repository.FindReplaceRepository()
+	f, err := findreplace.New(repo, options)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	var ids []int
+	var changes []findreplace.Change
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		var err error
+		ids, err = r.findReplaceIDs(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		if input.Preview {
+			changes, err = f.Preview(ctx, ids)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if !input.Preview {
+		jobID := strconv.Itoa(manager.GetInstance().BulkFindReplace(withEditSource(ctx), options, ids))
+		return &BulkFindReplaceResult{
+			JobID: &jobID,
+		}, nil
+	}
+
+	// count the changed objects before limiting the changes
+	count := 0
+	for i, c := range changes {
+		if i == 0 || changes[i-1].ID != c.ID {
+			count++
+		}
+	}
+
+	if input.PreviewLimit != nil && *input.PreviewLimit >= 0 && len(changes) > *input.PreviewLimit {
+		changes = changes[:*input.PreviewLimit]
+	}
+
+	ret := make([]*findreplace.Change, len(changes))
+	for i := range changes {
+		ret[i] = &changes[i]
+	}
+
+	return &BulkFindReplaceResult{
+		Count:   &count,
+		Changes: ret,
+	}, nil
+}
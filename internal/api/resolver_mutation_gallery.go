@@ -110,6 +110,8 @@ func (r *mutationResolver) GalleryUpdate(ctx context.Context, input models.Galle
 }
 
 func (r *mutationResolver) GalleriesUpdate(ctx context.Context, input []*models.GalleryUpdateInput) (ret []*models.Gallery, err error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	inputMaps := getUpdateInputMaps(ctx)
 
 	// Start the transaction and save the galleries
@@ -248,6 +250,8 @@ func (r *mutationResolver) galleryUpdate(ctx context.Context, input models.Galle
 }
 
 func (r *mutationResolver) BulkGalleryUpdate(ctx context.Context, input BulkGalleryUpdateInput) ([]*models.Gallery, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	galleryIDs, err := stringslice.StringSliceToIntSlice(input.Ids)
 	if err != nil {
 		return nil, err
@@ -478,6 +482,36 @@ func (r *mutationResolver) RemoveGalleryImages(ctx context.Context, input Galler
 	return true, nil
 }
 
+func (r *mutationResolver) GalleryReorderImages(ctx context.Context, input GalleryReorderImagesInput) (bool, error) {
+	galleryID, err := strconv.Atoi(input.GalleryID)
+	if err != nil {
+		return false, err
+	}
+
+	imageIDs, err := stringslice.StringSliceToIntSlice(input.ImageIds)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Gallery
+		gallery, err := qb.Find(ctx, galleryID)
+		if err != nil {
+			return err
+		}
+
+		if gallery == nil {
+			return fmt.Errorf("gallery with id %d not found", galleryID)
+		}
+
+		return r.galleryService.ReorderImages(ctx, gallery, imageIDs)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (r *mutationResolver) getGalleryChapter(ctx context.Context, id int) (ret *models.GalleryChapter, err error) {
 	if err := r.withTxn(ctx, func(ctx context.Context) error {
 		ret, err = r.repository.GalleryChapter.Find(ctx, id)
@@ -45,6 +45,8 @@ func (r *mutationResolver) ImageUpdate(ctx context.Context, input ImageUpdateInp
 }
 
 func (r *mutationResolver) ImagesUpdate(ctx context.Context, input []*ImageUpdateInput) (ret []*models.Image, err error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	inputMaps := getUpdateInputMaps(ctx)
 
 	// Start the transaction and save the image
@@ -191,6 +193,8 @@ func (r *mutationResolver) imageUpdate(ctx context.Context, input ImageUpdateInp
 }
 
 func (r *mutationResolver) BulkImageUpdate(ctx context.Context, input BulkImageUpdateInput) (ret []*models.Image, err error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	imageIDs, err := stringslice.StringSliceToIntSlice(input.Ids)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stashapp/stash/internal/manager"
+)
+
+func (r *mutationResolver) UnblockLogin(ctx context.Context, ip *string, username *string) (bool, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return false, err
+	}
+
+	if ip == nil && username == nil {
+		return false, errors.New("ip or username must be set")
+	}
+
+	store := manager.GetInstance().SessionStore
+
+	ret := false
+	if ip != nil && store.UnblockIP(*ip) {
+		ret = true
+	}
+	if username != nil && store.UnblockUsername(*username) {
+		ret = true
+	}
+
+	return ret, nil
+}
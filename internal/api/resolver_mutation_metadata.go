@@ -85,11 +85,19 @@ func (r *mutationResolver) MetadataGenerate(ctx context.Context, input manager.G
 }
 
 func (r *mutationResolver) MetadataAutoTag(ctx context.Context, input manager.AutoTagMetadataInput) (string, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return "", err
+	}
+
 	jobID := manager.GetInstance().AutoTag(ctx, input)
 	return strconv.Itoa(jobID), nil
 }
 
 func (r *mutationResolver) MetadataIdentify(ctx context.Context, input identify.Options) (string, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return "", err
+	}
+
 	t := manager.CreateIdentifyJob(input)
 	jobID := manager.GetInstance().JobManager.Add(ctx, "Identifying...", t)
 
@@ -101,6 +109,15 @@ func (r *mutationResolver) MetadataClean(ctx context.Context, input manager.Clea
 	return strconv.Itoa(jobID), nil
 }
 
+func (r *mutationResolver) MetadataApplyTagImplications(ctx context.Context) (string, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return "", err
+	}
+
+	jobID := manager.GetInstance().ApplyTagImplications(ctx)
+	return strconv.Itoa(jobID), nil
+}
+
 func (r *mutationResolver) MigrateHashNaming(ctx context.Context) (string, error) {
 	jobID := manager.GetInstance().MigrateHash(ctx)
 	return strconv.Itoa(jobID), nil
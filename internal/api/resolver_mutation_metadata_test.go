@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/internal/identify"
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrictedJobMutations(t *testing.T) {
+	r := newResolver()
+	ctx := models.WithRestrictionProfileIDs(context.Background(), []int{1})
+	mr := r.Mutation()
+
+	_, err := mr.MetadataAutoTag(ctx, manager.AutoTagMetadataInput{})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = mr.MetadataIdentify(ctx, identify.Options{})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = mr.MetadataApplyTagImplications(ctx)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = mr.BulkFindReplace(ctx, BulkFindReplaceInput{})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
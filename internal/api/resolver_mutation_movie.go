@@ -9,6 +9,7 @@ import (
 	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -110,17 +111,7 @@ func (r *mutationResolver) MovieCreate(ctx context.Context, input MovieCreateInp
 	return r.getMovie(ctx, newMovie.ID)
 }
 
-func (r *mutationResolver) MovieUpdate(ctx context.Context, input MovieUpdateInput) (*models.Movie, error) {
-	movieID, err := strconv.Atoi(input.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
-	}
-
-	// Populate movie from the input
+func moviePartialFromInput(input MovieUpdateInput, translator changesetTranslator) (*models.MoviePartial, error) {
 	updatedMovie := models.NewMoviePartial()
 
 	if input.Name != nil {
@@ -137,61 +128,160 @@ func (r *mutationResolver) MovieUpdate(ctx context.Context, input MovieUpdateInp
 	updatedMovie.Director = translator.optionalString(input.Director, "director")
 	updatedMovie.Synopsis = translator.optionalString(input.Synopsis, "synopsis")
 	updatedMovie.URL = translator.optionalString(input.URL, "url")
+
+	var err error
 	updatedMovie.StudioID, err = translator.optionalIntFromString(input.StudioID, "studio_id")
 	if err != nil {
 		return nil, fmt.Errorf("converting studio id: %w", err)
 	}
 
-	var frontimageData []byte
-	frontImageIncluded := translator.hasField("front_image")
+	return &updatedMovie, nil
+}
+
+// movieImagesFromInput processes the front and back images of the input.
+func movieImagesFromInput(ctx context.Context, input MovieUpdateInput) (frontImageData []byte, backImageData []byte, err error) {
 	if input.FrontImage != nil {
-		frontimageData, err = utils.ProcessImageInput(ctx, *input.FrontImage)
+		frontImageData, err = utils.ProcessImageInput(ctx, *input.FrontImage)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	var backimageData []byte
-	backImageIncluded := translator.hasField("back_image")
 	if input.BackImage != nil {
-		backimageData, err = utils.ProcessImageInput(ctx, *input.BackImage)
+		backImageData, err = utils.ProcessImageInput(ctx, *input.BackImage)
 		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return frontImageData, backImageData, nil
+}
+
+// updateMovie applies the partial movie and the images included in the input
+// to the movie. Must be called within a transaction.
+func (r *mutationResolver) updateMovie(ctx context.Context, movieID int, updatedMovie models.MoviePartial, translator changesetTranslator, frontImageData []byte, backImageData []byte) (*models.Movie, error) {
+	qb := r.repository.Movie
+	movie, err := qb.UpdatePartial(ctx, movieID, updatedMovie)
+	if err != nil {
+		return nil, err
+	}
+
+	// update image table
+	if translator.hasField("front_image") {
+		if err := qb.UpdateFrontImage(ctx, movie.ID, frontImageData); err != nil {
+			return nil, err
+		}
+	}
+
+	if translator.hasField("back_image") {
+		if err := qb.UpdateBackImage(ctx, movie.ID, backImageData); err != nil {
 			return nil, err
 		}
 	}
 
+	return movie, nil
+}
+
+func (r *mutationResolver) MovieUpdate(ctx context.Context, input MovieUpdateInput) (*models.Movie, error) {
+	movieID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	// Populate movie from the input
+	updatedMovie, err := moviePartialFromInput(input, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	frontImageData, backImageData, err := movieImagesFromInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start the transaction and save the movie
 	var movie *models.Movie
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		movie, err = r.updateMovie(ctx, movieID, *updatedMovie, translator, frontImageData, backImageData)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, movie.ID, plugin.MovieUpdatePost, input, translator.getFields())
+	return r.getMovie(ctx, movie.ID)
+}
+
+func (r *mutationResolver) MoviesMerge(ctx context.Context, input MoviesMergeInput) (*models.Movie, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationMerge)
+
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, fmt.Errorf("converting source ids: %w", err)
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("converting destination id %s: %w", input.Destination, err)
+	}
+
+	source = intslice.IntAppendUniques(nil, source)
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	values := input.Values
+	if values == nil {
+		values = &MovieUpdateInput{}
+	}
+
+	translator := changesetTranslator{
+		inputMap: getNamedUpdateInputMap(ctx, "input.values"),
+	}
+
+	updatedMovie, err := moviePartialFromInput(*values, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	frontImageData, backImageData, err := movieImagesFromInput(ctx, *values)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := r.withTxn(ctx, func(ctx context.Context) error {
 		qb := r.repository.Movie
-		movie, err = qb.UpdatePartial(ctx, movieID, updatedMovie)
+
+		m, err := qb.Find(ctx, destination)
 		if err != nil {
 			return err
 		}
 
-		// update image table
-		if frontImageIncluded {
-			if err := qb.UpdateFrontImage(ctx, movie.ID, frontimageData); err != nil {
-				return err
-			}
+		if m == nil {
+			return fmt.Errorf("movie with id %d not found", destination)
 		}
 
-		if backImageIncluded {
-			if err := qb.UpdateBackImage(ctx, movie.ID, backimageData); err != nil {
-				return err
-			}
+		if err := qb.Merge(ctx, source, destination); err != nil {
+			return err
 		}
 
-		return nil
+		_, err = r.updateMovie(ctx, destination, *updatedMovie, translator, frontImageData, backImageData)
+		return err
 	}); err != nil {
 		return nil, err
 	}
 
-	r.hookExecutor.ExecutePostHooks(ctx, movie.ID, plugin.MovieUpdatePost, input, translator.getFields())
-	return r.getMovie(ctx, movie.ID)
+	r.hookExecutor.ExecutePostHooks(ctx, destination, plugin.MovieMergePost, input, nil)
+	return r.getMovie(ctx, destination)
 }
 
 func (r *mutationResolver) BulkMovieUpdate(ctx context.Context, input BulkMovieUpdateInput) ([]*models.Movie, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	movieIDs, err := stringslice.StringSliceToIntSlice(input.Ids)
 	if err != nil {
 		return nil, err
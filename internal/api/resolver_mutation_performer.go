@@ -9,6 +9,7 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/performer"
 	"github.com/stashapp/stash/pkg/plugin"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/utils"
 )
@@ -140,69 +141,61 @@ func (r *mutationResolver) PerformerCreate(ctx context.Context, input PerformerC
 	return r.getPerformer(ctx, newPerformer.ID)
 }
 
-func (r *mutationResolver) PerformerUpdate(ctx context.Context, input PerformerUpdateInput) (*models.Performer, error) {
-	performerID, err := strconv.Atoi(input.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Populate performer from the input
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
-	}
+func performerPartialFromInput(input PerformerUpdateInput, translator changesetTranslator) (*models.PerformerPartial, error) {
+	ret := models.NewPerformerPartial()
 
-	updatedPerformer := models.NewPerformerPartial()
+	ret.Name = translator.optionalString(input.Name, "name")
+	ret.Disambiguation = translator.optionalString(input.Disambiguation, "disambiguation")
+	ret.URL = translator.optionalString(input.URL, "url")
+	ret.Gender = translator.optionalString((*string)(input.Gender), "gender")
+	ret.Birthdate = translator.optionalDate(input.Birthdate, "birthdate")
+	ret.Ethnicity = translator.optionalString(input.Ethnicity, "ethnicity")
+	ret.Country = translator.optionalString(input.Country, "country")
+	ret.EyeColor = translator.optionalString(input.EyeColor, "eye_color")
+	ret.Measurements = translator.optionalString(input.Measurements, "measurements")
 
-	updatedPerformer.Name = translator.optionalString(input.Name, "name")
-	updatedPerformer.Disambiguation = translator.optionalString(input.Disambiguation, "disambiguation")
-	updatedPerformer.URL = translator.optionalString(input.URL, "url")
-	updatedPerformer.Gender = translator.optionalString((*string)(input.Gender), "gender")
-	updatedPerformer.Birthdate = translator.optionalDate(input.Birthdate, "birthdate")
-	updatedPerformer.Ethnicity = translator.optionalString(input.Ethnicity, "ethnicity")
-	updatedPerformer.Country = translator.optionalString(input.Country, "country")
-	updatedPerformer.EyeColor = translator.optionalString(input.EyeColor, "eye_color")
-	updatedPerformer.Measurements = translator.optionalString(input.Measurements, "measurements")
+	var err error
 
 	// prefer height_cm over height
 	if translator.hasField("height_cm") {
-		updatedPerformer.Height = translator.optionalInt(input.HeightCm, "height_cm")
+		ret.Height = translator.optionalInt(input.HeightCm, "height_cm")
 	} else if translator.hasField("height") {
-		updatedPerformer.Height, err = translator.optionalIntFromString(input.Height, "height")
+		ret.Height, err = translator.optionalIntFromString(input.Height, "height")
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	updatedPerformer.FakeTits = translator.optionalString(input.FakeTits, "fake_tits")
-	updatedPerformer.PenisLength = translator.optionalFloat64(input.PenisLength, "penis_length")
-	updatedPerformer.Circumcised = translator.optionalString((*string)(input.Circumcised), "circumcised")
-	updatedPerformer.CareerLength = translator.optionalString(input.CareerLength, "career_length")
-	updatedPerformer.Tattoos = translator.optionalString(input.Tattoos, "tattoos")
-	updatedPerformer.Piercings = translator.optionalString(input.Piercings, "piercings")
-	updatedPerformer.Twitter = translator.optionalString(input.Twitter, "twitter")
-	updatedPerformer.Instagram = translator.optionalString(input.Instagram, "instagram")
-	updatedPerformer.Favorite = translator.optionalBool(input.Favorite, "favorite")
-	updatedPerformer.Rating = translator.ratingConversionOptional(input.Rating, input.Rating100)
-	updatedPerformer.Details = translator.optionalString(input.Details, "details")
-	updatedPerformer.DeathDate = translator.optionalDate(input.DeathDate, "death_date")
-	updatedPerformer.HairColor = translator.optionalString(input.HairColor, "hair_color")
-	updatedPerformer.Weight = translator.optionalInt(input.Weight, "weight")
-	updatedPerformer.IgnoreAutoTag = translator.optionalBool(input.IgnoreAutoTag, "ignore_auto_tag")
+	ret.FakeTits = translator.optionalString(input.FakeTits, "fake_tits")
+	ret.PenisLength = translator.optionalFloat64(input.PenisLength, "penis_length")
+	ret.Circumcised = translator.optionalString((*string)(input.Circumcised), "circumcised")
+	ret.CareerLength = translator.optionalString(input.CareerLength, "career_length")
+	ret.Tattoos = translator.optionalString(input.Tattoos, "tattoos")
+	ret.Piercings = translator.optionalString(input.Piercings, "piercings")
+	ret.Twitter = translator.optionalString(input.Twitter, "twitter")
+	ret.Instagram = translator.optionalString(input.Instagram, "instagram")
+	ret.Favorite = translator.optionalBool(input.Favorite, "favorite")
+	ret.Rating = translator.ratingConversionOptional(input.Rating, input.Rating100)
+	ret.Details = translator.optionalString(input.Details, "details")
+	ret.DeathDate = translator.optionalDate(input.DeathDate, "death_date")
+	ret.HairColor = translator.optionalString(input.HairColor, "hair_color")
+	ret.Weight = translator.optionalInt(input.Weight, "weight")
+	ret.IgnoreAutoTag = translator.optionalBool(input.IgnoreAutoTag, "ignore_auto_tag")
 
 	if translator.hasField("alias_list") {
-		updatedPerformer.Aliases = &models.UpdateStrings{
+		ret.Aliases = &models.UpdateStrings{
 			Values: input.AliasList,
 			Mode:   models.RelationshipUpdateModeSet,
 		}
 	} else if translator.hasField("aliases") {
-		updatedPerformer.Aliases = &models.UpdateStrings{
+		ret.Aliases = &models.UpdateStrings{
 			Values: stringslice.FromString(*input.Aliases, ","),
 			Mode:   models.RelationshipUpdateModeSet,
 		}
 	}
 
 	if translator.hasField("tag_ids") {
-		updatedPerformer.TagIDs, err = translateUpdateIDs(input.TagIds, models.RelationshipUpdateModeSet)
+		ret.TagIDs, err = translateUpdateIDs(input.TagIds, models.RelationshipUpdateModeSet)
 		if err != nil {
 			return nil, fmt.Errorf("converting tag ids: %w", err)
 		}
@@ -210,12 +203,31 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input PerformerU
 
 	// Save the stash_ids
 	if translator.hasField("stash_ids") {
-		updatedPerformer.StashIDs = &models.UpdateStashIDs{
+		ret.StashIDs = &models.UpdateStashIDs{
 			StashIDs: stashIDPtrSliceToSlice(input.StashIds),
 			Mode:     models.RelationshipUpdateModeSet,
 		}
 	}
 
+	return &ret, nil
+}
+
+func (r *mutationResolver) PerformerUpdate(ctx context.Context, input PerformerUpdateInput) (*models.Performer, error) {
+	performerID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Populate performer from the input
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	updatedPerformer, err := performerPartialFromInput(input, translator)
+	if err != nil {
+		return nil, err
+	}
+
 	var imageData []byte
 	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
@@ -245,7 +257,7 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input PerformerU
 			}
 		}
 
-		_, err = qb.UpdatePartial(ctx, performerID, updatedPerformer)
+		_, err = qb.UpdatePartial(ctx, performerID, *updatedPerformer)
 		if err != nil {
 			return err
 		}
@@ -266,7 +278,89 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input PerformerU
 	return r.getPerformer(ctx, performerID)
 }
 
+func (r *mutationResolver) PerformersMerge(ctx context.Context, input PerformersMergeInput) (*models.Performer, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationMerge)
+
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, fmt.Errorf("converting source ids: %w", err)
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("converting destination id %s: %w", input.Destination, err)
+	}
+
+	source = intslice.IntAppendUniques(nil, source)
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	values := input.Values
+	if values == nil {
+		values = &PerformerUpdateInput{}
+	}
+
+	translator := changesetTranslator{
+		inputMap: getNamedUpdateInputMap(ctx, "input.values"),
+	}
+
+	updatedPerformer, err := performerPartialFromInput(*values, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageData []byte
+	imageIncluded := translator.hasField("image")
+	if values.Image != nil {
+		imageData, err = utils.ProcessImageInput(ctx, *values.Image)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Performer
+
+		existing, err := qb.Find(ctx, destination)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return fmt.Errorf("performer with id %d not found", destination)
+		}
+
+		if err := performer.ValidateDeathDate(existing, values.Birthdate, values.DeathDate); err != nil {
+			return err
+		}
+
+		if err := qb.Merge(ctx, source, destination); err != nil {
+			return err
+		}
+
+		if _, err := qb.UpdatePartial(ctx, destination, *updatedPerformer); err != nil {
+			return err
+		}
+
+		if imageIncluded {
+			if err := qb.UpdateImage(ctx, destination, imageData); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, destination, plugin.PerformerMergePost, input, nil)
+	return r.getPerformer(ctx, destination)
+}
+
 func (r *mutationResolver) BulkPerformerUpdate(ctx context.Context, input BulkPerformerUpdateInput) ([]*models.Performer, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	performerIDs, err := stringslice.StringSliceToIntSlice(input.Ids)
 	if err != nil {
 		return nil, err
@@ -425,3 +519,73 @@ func (r *mutationResolver) PerformersDestroy(ctx context.Context, performerIDs [
 
 	return true, nil
 }
+
+// updatePerformerImages runs fn within a transaction for the performer with the given
+// id, then marks the performer as updated so that image paths are refreshed.
+func (r *mutationResolver) updatePerformerImages(ctx context.Context, id string, input interface{}, fn func(ctx context.Context, qb models.PerformerReaderWriter, performerID int) error) (*models.Performer, error) {
+	performerID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Performer
+
+		existing, err := qb.Find(ctx, performerID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return fmt.Errorf("performer with id %d not found", performerID)
+		}
+
+		if err := fn(ctx, qb, performerID); err != nil {
+			return err
+		}
+
+		_, err = qb.UpdatePartial(ctx, performerID, models.NewPerformerPartial())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, performerID, plugin.PerformerUpdatePost, input, nil)
+	return r.getPerformer(ctx, performerID)
+}
+
+func (r *mutationResolver) PerformerImageAdd(ctx context.Context, input ProfileImageAddInput) (*models.Performer, error) {
+	imageData, err := utils.ProcessImageInput(ctx, input.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := input.Primary != nil && *input.Primary
+
+	return r.updatePerformerImages(ctx, input.ID, input, func(ctx context.Context, qb models.PerformerReaderWriter, performerID int) error {
+		_, err := qb.AddImage(ctx, performerID, imageData, primary)
+		return err
+	})
+}
+
+func (r *mutationResolver) PerformerImageSetPrimary(ctx context.Context, input ProfileImageInput) (*models.Performer, error) {
+	imageID, err := strconv.Atoi(input.ImageID)
+	if err != nil {
+		return nil, fmt.Errorf("converting image id: %w", err)
+	}
+
+	return r.updatePerformerImages(ctx, input.ID, input, func(ctx context.Context, qb models.PerformerReaderWriter, performerID int) error {
+		return qb.SetPrimaryImage(ctx, performerID, imageID)
+	})
+}
+
+func (r *mutationResolver) PerformerImageDestroy(ctx context.Context, input ProfileImageInput) (*models.Performer, error) {
+	imageID, err := strconv.Atoi(input.ImageID)
+	if err != nil {
+		return nil, fmt.Errorf("converting image id: %w", err)
+	}
+
+	return r.updatePerformerImages(ctx, input.ID, input, func(ctx context.Context, qb models.PerformerReaderWriter, performerID int) error {
+		return qb.DestroyImageByID(ctx, performerID, imageID)
+	})
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/playlist"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+var errSmartPlaylistItems = errors.New("items of smart playlists cannot be modified")
+
+func playlistItemsFromInput(input []*PlaylistItemInput) ([]*models.PlaylistItem, error) {
+	ret := make([]*models.PlaylistItem, len(input))
+	for i, in := range input {
+		item := &models.PlaylistItem{}
+
+		var translator changesetTranslator
+		var err error
+		if item.SceneID, err = translator.intPtrFromString(in.SceneID, "scene_id"); err != nil {
+			return nil, err
+		}
+		if item.SceneMarkerID, err = translator.intPtrFromString(in.SceneMarkerID, "scene_marker_id"); err != nil {
+			return nil, err
+		}
+		if item.ImageID, err = translator.intPtrFromString(in.ImageID, "image_id"); err != nil {
+			return nil, err
+		}
+
+		if err := item.Validate(); err != nil {
+			return nil, err
+		}
+
+		ret[i] = item
+	}
+
+	return ret, nil
+}
+
+// validateSmartPlaylist checks that the saved filter of a smart playlist
+// exists and is of a supported mode. It must be called within a
+// transaction.
+func (r *mutationResolver) validateSmartPlaylist(ctx context.Context, p *models.Playlist) error {
+	if p.FilterLimit != nil && *p.FilterLimit <= 0 {
+		return fmt.Errorf("%w: filter limit must be positive", ErrInput)
+	}
+
+	if p.SavedFilterID == nil {
+		return nil
+	}
+
+	f, err := r.repository.SavedFilter.Find(ctx, *p.SavedFilterID)
+	if err != nil {
+		return err
+	}
+
+	if f == nil {
+		return fmt.Errorf("saved filter with id %d not found", *p.SavedFilterID)
+	}
+
+	return playlist.ValidateFilterMode(f.Mode)
+}
+
+// findPlaylist returns the playlist with the given id, or an error if it is
+// not found. It must be called within a transaction.
+func (r *mutationResolver) findPlaylist(ctx context.Context, id int) (*models.Playlist, error) {
+	ret, err := r.repository.Playlist.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret == nil {
+		return nil, fmt.Errorf("playlist with id %d not found", id)
+	}
+
+	return ret, nil
+}
+
+// findStaticPlaylist returns the playlist with the given id, or an error if
+// it is not found or is a smart playlist. It must be called within a
+// transaction.
+func (r *mutationResolver) findStaticPlaylist(ctx context.Context, id int) (*models.Playlist, error) {
+	ret, err := r.findPlaylist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.IsSmart() {
+		return nil, errSmartPlaylistItems
+	}
+
+	return ret, nil
+}
+
+// touchPlaylist sets the updated time of the playlist. It must be called
+// within a transaction.
+func (r *mutationResolver) touchPlaylist(ctx context.Context, p *models.Playlist) error {
+	p.UpdatedAt = time.Now()
+	return r.repository.Playlist.Update(ctx, p)
+}
+
+func (r *mutationResolver) PlaylistCreate(ctx context.Context, input PlaylistCreateInput) (ret *models.Playlist, err error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, errors.New("name must be non-empty")
+	}
+
+	var translator changesetTranslator
+	currentTime := time.Now()
+	newPlaylist := models.Playlist{
+		Name:        input.Name,
+		Description: translator.string(input.Description, "description"),
+		FilterLimit: input.FilterLimit,
+		CreatedAt:   currentTime,
+		UpdatedAt:   currentTime,
+	}
+
+	newPlaylist.SavedFilterID, err = translator.intPtrFromString(input.SavedFilterID, "saved_filter_id")
+	if err != nil {
+		return nil, fmt.Errorf("converting saved filter id: %w", err)
+	}
+
+	if newPlaylist.IsSmart() && len(input.Items) > 0 {
+		return nil, fmt.Errorf("%w: items cannot be given for smart playlists", ErrInput)
+	}
+
+	items, err := playlistItemsFromInput(input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		if err := r.validateSmartPlaylist(ctx, &newPlaylist); err != nil {
+			return err
+		}
+
+		qb := r.repository.Playlist
+		if err := qb.Create(ctx, &newPlaylist); err != nil {
+			return err
+		}
+
+		if len(items) > 0 {
+			return qb.AddItems(ctx, newPlaylist.ID, items)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &newPlaylist, nil
+}
+
+func (r *mutationResolver) PlaylistUpdate(ctx context.Context, input PlaylistUpdateInput) (ret *models.Playlist, err error) {
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		return nil, errors.New("name must be non-empty")
+	}
+
+	savedFilterID, err := translator.optionalIntFromString(input.SavedFilterID, "saved_filter_id")
+	if err != nil {
+		return nil, fmt.Errorf("converting saved filter id: %w", err)
+	}
+	filterLimit := translator.optionalInt(input.FilterLimit, "filter_limit")
+	description := translator.optionalString(input.Description, "description")
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.findPlaylist(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if input.Name != nil {
+			ret.Name = *input.Name
+		}
+		if description.Set {
+			ret.Description = description.Value
+		}
+		if savedFilterID.Set {
+			ret.SavedFilterID = savedFilterID.Ptr()
+		}
+		if filterLimit.Set {
+			ret.FilterLimit = filterLimit.Ptr()
+		}
+
+		if err := r.validateSmartPlaylist(ctx, ret); err != nil {
+			return err
+		}
+
+		return r.touchPlaylist(ctx, ret)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) PlaylistDestroy(ctx context.Context, id string) (bool, error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.repository.Playlist.Destroy(ctx, idInt)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) PlaylistAddItems(ctx context.Context, input PlaylistAddItemsInput) (ret *models.Playlist, err error) {
+	id, err := strconv.Atoi(input.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := playlistItemsFromInput(input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.findStaticPlaylist(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := r.repository.Playlist.AddItems(ctx, id, items); err != nil {
+			return err
+		}
+
+		return r.touchPlaylist(ctx, ret)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) PlaylistRemoveItems(ctx context.Context, input PlaylistRemoveItemsInput) (ret *models.Playlist, err error) {
+	id, err := strconv.Atoi(input.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs, err := stringslice.StringSliceToIntSlice(input.ItemIds)
+	if err != nil {
+		return nil, fmt.Errorf("converting item ids: %w", err)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.findStaticPlaylist(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := r.repository.Playlist.RemoveItems(ctx, id, itemIDs); err != nil {
+			return err
+		}
+
+		return r.touchPlaylist(ctx, ret)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) PlaylistReorderItems(ctx context.Context, input PlaylistReorderItemsInput) (ret *models.Playlist, err error) {
+	id, err := strconv.Atoi(input.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs, err := stringslice.StringSliceToIntSlice(input.ItemIds)
+	if err != nil {
+		return nil, fmt.Errorf("converting item ids: %w", err)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.findStaticPlaylist(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := r.repository.Playlist.ReorderItems(ctx, id, itemIDs); err != nil {
+			return err
+		}
+
+		return r.touchPlaylist(ctx, ret)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+var errRestricted = errors.New("not permitted for restricted users")
+
+// checkUnrestricted returns an error if the content of the request is
+// restricted, so that restricted users cannot lift their restriction.
+func checkUnrestricted(ctx context.Context) error {
+	if models.IsRestricted(ctx) {
+		return fmt.Errorf("%w: %v", ErrForbidden, errRestricted)
+	}
+
+	return nil
+}
+
+// restrictionProfileRules sets the rules of the profile from the id lists
+// which are set.
+func restrictionProfileRules(p *models.RestrictionProfile, tagIDs []string, studioIDs []string, performerIDs []string) error {
+	var err error
+	if tagIDs != nil {
+		if p.TagIDs, err = stringslice.StringSliceToIntSlice(tagIDs); err != nil {
+			return fmt.Errorf("%w: converting tag ids: %v", ErrInput, err)
+		}
+	}
+	if studioIDs != nil {
+		if p.StudioIDs, err = stringslice.StringSliceToIntSlice(studioIDs); err != nil {
+			return fmt.Errorf("%w: converting studio ids: %v", ErrInput, err)
+		}
+	}
+	if performerIDs != nil {
+		if p.PerformerIDs, err = stringslice.StringSliceToIntSlice(performerIDs); err != nil {
+			return fmt.Errorf("%w: converting performer ids: %v", ErrInput, err)
+		}
+	}
+
+	return nil
+}
+
+// restrictionProfileFilters sets the filters of the profile which are set.
+// Empty filters would hide everything, so they remove the filter instead.
+func restrictionProfileFilters(p *models.RestrictionProfile, sceneFilter *models.SceneFilterType, imageFilter *models.ImageFilterType, galleryFilter *models.GalleryFilterType) {
+	if sceneFilter != nil {
+		p.SceneFilter = sceneFilter
+		if reflect.DeepEqual(*sceneFilter, models.SceneFilterType{}) {
+			p.SceneFilter = nil
+		}
+	}
+	if imageFilter != nil {
+		p.ImageFilter = imageFilter
+		if reflect.DeepEqual(*imageFilter, models.ImageFilterType{}) {
+			p.ImageFilter = nil
+		}
+	}
+	if galleryFilter != nil {
+		p.GalleryFilter = galleryFilter
+		if reflect.DeepEqual(*galleryFilter, models.GalleryFilterType{}) {
+			p.GalleryFilter = nil
+		}
+	}
+}
+
+func (r *mutationResolver) RestrictionProfileCreate(ctx context.Context, input RestrictionProfileCreateInput) (*models.RestrictionProfile, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	newProfile := &models.RestrictionProfile{
+		Name:      input.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := restrictionProfileRules(newProfile, input.TagIds, input.StudioIds, input.PerformerIds); err != nil {
+		return nil, err
+	}
+	restrictionProfileFilters(newProfile, input.SceneFilter, input.ImageFilter, input.GalleryFilter)
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.repository.RestrictionProfile.Create(ctx, newProfile)
+	}); err != nil {
+		return nil, err
+	}
+
+	return newProfile, nil
+}
+
+func (r *mutationResolver) RestrictionProfileUpdate(ctx context.Context, input RestrictionProfileUpdateInput) (ret *models.RestrictionProfile, err error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.RestrictionProfile
+
+		ret, err = qb.Find(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if ret == nil {
+			return fmt.Errorf("restriction profile with id %d not found", id)
+		}
+
+		if input.Name != nil {
+			ret.Name = *input.Name
+		}
+
+		if err := restrictionProfileRules(ret, input.TagIds, input.StudioIds, input.PerformerIds); err != nil {
+			return err
+		}
+		restrictionProfileFilters(ret, input.SceneFilter, input.ImageFilter, input.GalleryFilter)
+
+		ret.UpdatedAt = time.Now()
+
+		return qb.Update(ctx, ret)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) RestrictionProfileDestroy(ctx context.Context, id string) (bool, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return false, err
+	}
+
+	profileID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	if config.GetInstance().GetDLNARestrictionProfileID() == profileID {
+		return false, fmt.Errorf("%w: applied to DLNA", models.ErrRestrictionProfileInUse)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.repository.RestrictionProfile.Destroy(ctx, profileID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/stashapp/stash/pkg/filterquery"
 	"github.com/stashapp/stash/pkg/models"
 )
 
@@ -20,6 +21,13 @@ func (r *mutationResolver) SaveFilter(ctx context.Context, input SaveFilterInput
 		Filter: input.Filter,
 	}
 
+	if input.Query != nil {
+		if err := filterquery.Validate(input.Mode, *input.Query); err != nil {
+			return nil, err
+		}
+		newFilter.Query = *input.Query
+	}
+
 	var id *int
 	if input.ID != nil {
 		idv, err := strconv.Atoi(*input.ID)
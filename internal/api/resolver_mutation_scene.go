@@ -120,6 +120,8 @@ func (r *mutationResolver) SceneUpdate(ctx context.Context, input models.SceneUp
 }
 
 func (r *mutationResolver) ScenesUpdate(ctx context.Context, input []*models.SceneUpdateInput) (ret []*models.Scene, err error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	inputMaps := getUpdateInputMaps(ctx)
 
 	// Start the transaction and save the scenes
@@ -323,6 +325,8 @@ func (r *mutationResolver) sceneUpdateCoverImage(ctx context.Context, s *models.
 }
 
 func (r *mutationResolver) BulkSceneUpdate(ctx context.Context, input BulkSceneUpdateInput) ([]*models.Scene, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
 	sceneIDs, err := stringslice.StringSliceToIntSlice(input.Ids)
 	if err != nil {
 		return nil, err
@@ -549,6 +553,8 @@ func (r *mutationResolver) SceneAssignFile(ctx context.Context, input AssignScen
 }
 
 func (r *mutationResolver) SceneMerge(ctx context.Context, input SceneMergeInput) (*models.Scene, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationMerge)
+
 	srcIDs, err := stringslice.StringSliceToIntSlice(input.Source)
 	if err != nil {
 		return nil, fmt.Errorf("converting source IDs: %w", err)
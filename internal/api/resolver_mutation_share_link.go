@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+var errScopedShareLink = errors.New("share links may not be created with a scoped api key")
+
+// shareLinkEntityExists returns true if the entity exists and can be seen by
+// the current user.
+func (r *mutationResolver) shareLinkEntityExists(ctx context.Context, entityType models.ShareLinkEntity, id int) (bool, error) {
+	switch entityType {
+	case models.ShareLinkEntityScene:
+		s, err := r.repository.Scene.Find(ctx, id)
+		return s != nil, err
+	case models.ShareLinkEntityGallery:
+		g, err := r.repository.Gallery.Find(ctx, id)
+		return g != nil, err
+	case models.ShareLinkEntityImage:
+		i, err := r.repository.Image.Find(ctx, id)
+		return i != nil, err
+	default:
+		return false, fmt.Errorf("%w: %q", models.ErrInvalidShareLinkEntity, entityType)
+	}
+}
+
+func (r *mutationResolver) CreateShareLink(ctx context.Context, input ShareLinkCreateInput) (*models.ShareLink, error) {
+	if apiKey := session.GetAPIKey(ctx); apiKey != nil && apiKey.Scopes != nil {
+		return nil, fmt.Errorf("%w: %v", ErrForbidden, errScopedShareLink)
+	}
+
+	entityID, err := strconv.Atoi(input.EntityID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	// the owner has no user id
+	userID := 0
+	if current := session.GetCurrentUser(ctx); current != nil {
+		userID = current.ID
+	}
+
+	allowDownload := input.AllowDownload != nil && *input.AllowDownload
+
+	var ret *models.ShareLink
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		exists, err := r.shareLinkEntityExists(ctx, input.EntityType, entityID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s with id %d not found", input.EntityType, entityID)
+		}
+
+		ret, err = manager.GetInstance().ShareLinkService.Create(ctx, input.EntityType, entityID, userID, session.GetRestrictionProfileID(ctx), input.ExpiresAt, allowDownload)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) RevokeShareLink(ctx context.Context, id string) (bool, error) {
+	linkID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	current := session.GetCurrentUser(ctx)
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		link, err := r.repository.ShareLink.Find(ctx, linkID)
+		if err != nil {
+			return err
+		}
+
+		isCreator := link != nil && link.UserID != nil && current != nil && *link.UserID == current.ID
+
+		// don't reveal the links of other users
+		if link == nil || (!isCreator && !isAdmin(ctx)) {
+			return fmt.Errorf("share link with id %d not found", linkID)
+		}
+
+		return r.repository.ShareLink.Destroy(ctx, linkID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
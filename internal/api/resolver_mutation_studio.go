@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stashapp/stash/pkg/hash/md5"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/studio"
 
@@ -107,17 +108,7 @@ func (r *mutationResolver) StudioCreate(ctx context.Context, input StudioCreateI
 	return r.getStudio(ctx, newStudio.ID)
 }
 
-func (r *mutationResolver) StudioUpdate(ctx context.Context, input StudioUpdateInput) (*models.Studio, error) {
-	studioID, err := strconv.Atoi(input.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
-	}
-
-	// Populate studio from the input
+func studioPartialFromInput(input StudioUpdateInput, translator changesetTranslator) (*models.StudioPartial, error) {
 	updatedStudio := models.NewStudioPartial()
 
 	if input.Name != nil {
@@ -131,13 +122,33 @@ func (r *mutationResolver) StudioUpdate(ctx context.Context, input StudioUpdateI
 	updatedStudio.Details = translator.optionalString(input.Details, "details")
 	updatedStudio.Rating = translator.ratingConversionOptional(input.Rating, input.Rating100)
 	updatedStudio.IgnoreAutoTag = translator.optionalBool(input.IgnoreAutoTag, "ignore_auto_tag")
+
+	var err error
 	updatedStudio.ParentID, err = translator.optionalIntFromString(input.ParentID, "parent_id")
 	if err != nil {
 		return nil, fmt.Errorf("converting parent id: %w", err)
 	}
 
+	return &updatedStudio, nil
+}
+
+func (r *mutationResolver) StudioUpdate(ctx context.Context, input StudioUpdateInput) (*models.Studio, error) {
+	studioID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	// Populate studio from the input
+	updatedStudio, err := studioPartialFromInput(input, translator)
+	if err != nil {
+		return nil, err
+	}
+
 	var imageData []byte
-	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
 		imageData, err = utils.ProcessImageInput(ctx, *input.Image)
 		if err != nil {
@@ -148,50 +159,123 @@ func (r *mutationResolver) StudioUpdate(ctx context.Context, input StudioUpdateI
 	// Start the transaction and save the studio
 	var s *models.Studio
 	if err := r.withTxn(ctx, func(ctx context.Context) error {
-		qb := r.repository.Studio
+		var err error
+		s, err = r.updateStudio(ctx, studioID, *updatedStudio, input, translator, imageData)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
-		if err := manager.ValidateModifyStudio(ctx, studioID, updatedStudio, qb); err != nil {
-			return err
+	r.hookExecutor.ExecutePostHooks(ctx, s.ID, plugin.StudioUpdatePost, input, translator.getFields())
+	return r.getStudio(ctx, s.ID)
+}
+
+// updateStudio applies the partial studio and the relationship fields of the
+// input to the studio. Must be called within a transaction.
+func (r *mutationResolver) updateStudio(ctx context.Context, studioID int, updatedStudio models.StudioPartial, input StudioUpdateInput, translator changesetTranslator, imageData []byte) (*models.Studio, error) {
+	qb := r.repository.Studio
+
+	if err := manager.ValidateModifyStudio(ctx, studioID, updatedStudio, qb); err != nil {
+		return nil, err
+	}
+
+	s, err := qb.UpdatePartial(ctx, studioID, updatedStudio)
+	if err != nil {
+		return nil, err
+	}
+
+	// update image table
+	if translator.hasField("image") {
+		if err := qb.UpdateImage(ctx, s.ID, imageData); err != nil {
+			return nil, err
 		}
+	}
 
-		var err error
-		s, err = qb.UpdatePartial(ctx, studioID, updatedStudio)
-		if err != nil {
-			return err
+	// Save the stash_ids
+	if translator.hasField("stash_ids") {
+		stashIDJoins := stashIDPtrSliceToSlice(input.StashIds)
+		if err := qb.UpdateStashIDs(ctx, studioID, stashIDJoins); err != nil {
+			return nil, err
 		}
+	}
 
-		// update image table
-		if imageIncluded {
-			if err := qb.UpdateImage(ctx, s.ID, imageData); err != nil {
-				return err
-			}
+	if translator.hasField("aliases") {
+		if err := studio.EnsureAliasesUnique(ctx, studioID, input.Aliases, qb); err != nil {
+			return nil, err
 		}
 
-		// Save the stash_ids
-		if translator.hasField("stash_ids") {
-			stashIDJoins := stashIDPtrSliceToSlice(input.StashIds)
-			if err := qb.UpdateStashIDs(ctx, studioID, stashIDJoins); err != nil {
-				return err
-			}
+		if err := qb.UpdateAliases(ctx, studioID, input.Aliases); err != nil {
+			return nil, err
 		}
+	}
 
-		if translator.hasField("aliases") {
-			if err := studio.EnsureAliasesUnique(ctx, studioID, input.Aliases, qb); err != nil {
-				return err
-			}
+	return s, nil
+}
 
-			if err := qb.UpdateAliases(ctx, studioID, input.Aliases); err != nil {
-				return err
-			}
+func (r *mutationResolver) StudiosMerge(ctx context.Context, input StudiosMergeInput) (*models.Studio, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationMerge)
+
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, fmt.Errorf("converting source ids: %w", err)
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("converting destination id %s: %w", input.Destination, err)
+	}
+
+	source = intslice.IntAppendUniques(nil, source)
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	values := input.Values
+	if values == nil {
+		values = &StudioUpdateInput{}
+	}
+
+	translator := changesetTranslator{
+		inputMap: getNamedUpdateInputMap(ctx, "input.values"),
+	}
+
+	updatedStudio, err := studioPartialFromInput(*values, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageData []byte
+	if values.Image != nil {
+		imageData, err = utils.ProcessImageInput(ctx, *values.Image)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Studio
+
+		s, err := qb.Find(ctx, destination)
+		if err != nil {
+			return err
+		}
+
+		if s == nil {
+			return fmt.Errorf("studio with id %d not found", destination)
+		}
+
+		if err := qb.Merge(ctx, source, destination); err != nil {
+			return err
+		}
+
+		_, err = r.updateStudio(ctx, destination, *updatedStudio, *values, translator, imageData)
+		return err
 	}); err != nil {
 		return nil, err
 	}
 
-	r.hookExecutor.ExecutePostHooks(ctx, s.ID, plugin.StudioUpdatePost, input, translator.getFields())
-	return r.getStudio(ctx, s.ID)
+	r.hookExecutor.ExecutePostHooks(ctx, destination, plugin.StudioMergePost, input, nil)
+	return r.getStudio(ctx, destination)
 }
 
 func (r *mutationResolver) StudioDestroy(ctx context.Context, input StudioDestroyInput) (bool, error) {
@@ -236,3 +320,73 @@ func (r *mutationResolver) StudiosDestroy(ctx context.Context, studioIDs []strin
 
 	return true, nil
 }
+
+// updateStudioImages runs fn within a transaction for the studio with the given
+// id, then marks the studio as updated so that image paths are refreshed.
+func (r *mutationResolver) updateStudioImages(ctx context.Context, id string, input interface{}, fn func(ctx context.Context, qb models.StudioReaderWriter, studioID int) error) (*models.Studio, error) {
+	studioID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Studio
+
+		existing, err := qb.Find(ctx, studioID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return fmt.Errorf("studio with id %d not found", studioID)
+		}
+
+		if err := fn(ctx, qb, studioID); err != nil {
+			return err
+		}
+
+		_, err = qb.UpdatePartial(ctx, studioID, models.NewStudioPartial())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, studioID, plugin.StudioUpdatePost, input, nil)
+	return r.getStudio(ctx, studioID)
+}
+
+func (r *mutationResolver) StudioImageAdd(ctx context.Context, input ProfileImageAddInput) (*models.Studio, error) {
+	imageData, err := utils.ProcessImageInput(ctx, input.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := input.Primary != nil && *input.Primary
+
+	return r.updateStudioImages(ctx, input.ID, input, func(ctx context.Context, qb models.StudioReaderWriter, studioID int) error {
+		_, err := qb.AddImage(ctx, studioID, imageData, primary)
+		return err
+	})
+}
+
+func (r *mutationResolver) StudioImageSetPrimary(ctx context.Context, input ProfileImageInput) (*models.Studio, error) {
+	imageID, err := strconv.Atoi(input.ImageID)
+	if err != nil {
+		return nil, fmt.Errorf("converting image id: %w", err)
+	}
+
+	return r.updateStudioImages(ctx, input.ID, input, func(ctx context.Context, qb models.StudioReaderWriter, studioID int) error {
+		return qb.SetPrimaryImage(ctx, studioID, imageID)
+	})
+}
+
+func (r *mutationResolver) StudioImageDestroy(ctx context.Context, input ProfileImageInput) (*models.Studio, error) {
+	imageID, err := strconv.Atoi(input.ImageID)
+	if err != nil {
+		return nil, fmt.Errorf("converting image id: %w", err)
+	}
+
+	return r.updateStudioImages(ctx, input.ID, input, func(ctx context.Context, qb models.StudioReaderWriter, studioID int) error {
+		return qb.DestroyImageByID(ctx, studioID, imageID)
+	})
+}
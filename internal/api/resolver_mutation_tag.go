@@ -287,6 +287,8 @@ func (r *mutationResolver) TagsDestroy(ctx context.Context, tagIDs []string) (bo
 }
 
 func (r *mutationResolver) TagsMerge(ctx context.Context, input TagsMergeInput) (*models.Tag, error) {
+	ctx = models.WithEditOperation(ctx, models.EditOperationMerge)
+
 	source, err := stringslice.StringSliceToIntSlice(input.Source)
 	if err != nil {
 		return nil, err
@@ -347,3 +349,73 @@ func (r *mutationResolver) TagsMerge(ctx context.Context, input TagsMergeInput)
 	r.hookExecutor.ExecutePostHooks(ctx, t.ID, plugin.TagMergePost, input, nil)
 	return t, nil
 }
+
+// updateTagImages runs fn within a transaction for the tag with the given
+// id, then marks the tag as updated so that image paths are refreshed.
+func (r *mutationResolver) updateTagImages(ctx context.Context, id string, input interface{}, fn func(ctx context.Context, qb models.TagReaderWriter, tagID int) error) (*models.Tag, error) {
+	tagID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.Tag
+
+		existing, err := qb.Find(ctx, tagID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return fmt.Errorf("tag with id %d not found", tagID)
+		}
+
+		if err := fn(ctx, qb, tagID); err != nil {
+			return err
+		}
+
+		_, err = qb.UpdatePartial(ctx, tagID, models.NewTagPartial())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, tagID, plugin.TagUpdatePost, input, nil)
+	return r.getTag(ctx, tagID)
+}
+
+func (r *mutationResolver) TagImageAdd(ctx context.Context, input ProfileImageAddInput) (*models.Tag, error) {
+	imageData, err := utils.ProcessImageInput(ctx, input.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := input.Primary != nil && *input.Primary
+
+	return r.updateTagImages(ctx, input.ID, input, func(ctx context.Context, qb models.TagReaderWriter, tagID int) error {
+		_, err := qb.AddImage(ctx, tagID, imageData, primary)
+		return err
+	})
+}
+
+func (r *mutationResolver) TagImageSetPrimary(ctx context.Context, input ProfileImageInput) (*models.Tag, error) {
+	imageID, err := strconv.Atoi(input.ImageID)
+	if err != nil {
+		return nil, fmt.Errorf("converting image id: %w", err)
+	}
+
+	return r.updateTagImages(ctx, input.ID, input, func(ctx context.Context, qb models.TagReaderWriter, tagID int) error {
+		return qb.SetPrimaryImage(ctx, tagID, imageID)
+	})
+}
+
+func (r *mutationResolver) TagImageDestroy(ctx context.Context, input ProfileImageInput) (*models.Tag, error) {
+	imageID, err := strconv.Atoi(input.ImageID)
+	if err != nil {
+		return nil, fmt.Errorf("converting image id: %w", err)
+	}
+
+	return r.updateTagImages(ctx, input.ID, input, func(ctx context.Context, qb models.TagReaderWriter, tagID int) error {
+		return qb.DestroyImageByID(ctx, tagID, imageID)
+	})
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+// findTagImplication returns the rule of all with the same source and
+// implied tag as i, or nil if there is none.
+func findTagImplication(all []*models.TagImplication, i models.TagImplication) *models.TagImplication {
+	sameID := func(a, b *int) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+
+	for _, existing := range all {
+		if sameID(existing.SourceTagID, i.SourceTagID) && sameID(existing.SourcePerformerID, i.SourcePerformerID) && existing.ImpliedTagID == i.ImpliedTagID {
+			return existing
+		}
+	}
+
+	return nil
+}
+
+func (r *mutationResolver) TagImplicationCreate(ctx context.Context, input TagImplicationCreateInput) (ret []*models.TagImplication, err error) {
+	var translator changesetTranslator
+	sourceTagID, err := translator.intPtrFromString(input.SourceTagID, "source_tag_id")
+	if err != nil {
+		return nil, fmt.Errorf("converting source tag id: %w", err)
+	}
+	sourcePerformerID, err := translator.intPtrFromString(input.SourcePerformerID, "source_performer_id")
+	if err != nil {
+		return nil, fmt.Errorf("converting source performer id: %w", err)
+	}
+	impliedTagIDs, err := stringslice.StringSliceToIntSlice(input.ImpliedTagIds)
+	if err != nil {
+		return nil, fmt.Errorf("converting implied tag ids: %w", err)
+	}
+
+	currentTime := time.Now()
+	implications := make([]models.TagImplication, len(impliedTagIDs))
+	for i, tagID := range impliedTagIDs {
+		implications[i] = models.TagImplication{
+			SourceTagID:       sourceTagID,
+			SourcePerformerID: sourcePerformerID,
+			ImpliedTagID:      tagID,
+			CreatedAt:         currentTime,
+		}
+
+		if err := implications[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInput, err)
+		}
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		qb := r.repository.TagImplication
+		all, err := qb.All(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, i := range implications {
+			if existing := findTagImplication(all, i); existing != nil {
+				ret = append(ret, existing)
+				continue
+			}
+
+			newImplication := i
+			if err := qb.Create(ctx, &newImplication); err != nil {
+				return err
+			}
+			all = append(all, &newImplication)
+			ret = append(ret, &newImplication)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) TagImplicationDestroy(ctx context.Context, ids []string) (bool, error) {
+	idsInt, err := stringslice.StringSliceToIntSlice(ids)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		for _, id := range idsInt {
+			if err := r.repository.TagImplication.Destroy(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+var errTOTPAPIKey = errors.New("two-factor authentication may not be managed with an api key")
+
+// totpUser returns the current user, who manages their own two-factor
+// authentication. API keys may not be used, so that a leaked key cannot be
+// used to enroll or to disable it.
+func totpUser(ctx context.Context) (*models.User, error) {
+	if session.GetAPIKey(ctx) != nil {
+		return nil, fmt.Errorf("%w: %v", ErrForbidden, errTOTPAPIKey)
+	}
+
+	current := session.GetCurrentUser(ctx)
+	if current == nil {
+		return nil, fmt.Errorf("%w: no current user", ErrInput)
+	}
+
+	return current, nil
+}
+
+func (r *mutationResolver) EnrollTotp(ctx context.Context) (ret *models.TOTPEnrollment, err error) {
+	current, err := totpUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.userService().EnrollTOTP(ctx, current.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) ConfirmTotp(ctx context.Context, code string) (ret []string, err error) {
+	current, err := totpUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.userService().ConfirmTOTP(ctx, current.ID, code)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) DisableTotp(ctx context.Context, code string) (bool, error) {
+	current, err := totpUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.userService().DisableTOTP(ctx, current.ID, code)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context, code string) (ret []string, err error) {
+	current, err := totpUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.userService().RegenerateRecoveryCodes(ctx, current.ID, code)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) ResetUserTotp(ctx context.Context, id string) (bool, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return false, err
+	}
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.userService().ResetTOTP(ctx, userID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+	"github.com/stashapp/stash/pkg/user"
+)
+
+var errDestroyCurrentUser = errors.New("the current user may not be destroyed")
+
+// validateRestrictionProfile returns an error if the restriction profile
+// being set does not exist.
+func (r *mutationResolver) validateRestrictionProfile(ctx context.Context, id models.OptionalInt) error {
+	if !id.Set || id.Null {
+		return nil
+	}
+
+	p, err := r.repository.RestrictionProfile.Find(ctx, id.Value)
+	if err != nil {
+		return err
+	}
+
+	if p == nil {
+		return fmt.Errorf("%w: restriction profile with id %d not found", ErrInput, id.Value)
+	}
+
+	return nil
+}
+
+func (r *mutationResolver) UserCreate(ctx context.Context, input UserCreateInput) (ret *models.User, err error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	restrictionProfileID, err := translator.optionalIntFromString(input.RestrictionProfileID, "restriction_profile_id")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		if err := r.validateRestrictionProfile(ctx, restrictionProfileID); err != nil {
+			return err
+		}
+
+		ret, err = r.userService().Create(ctx, input.Username, input.Password, input.Role)
+		if err != nil || !restrictionProfileID.Set {
+			return err
+		}
+
+		ret, err = r.userService().Update(ctx, ret.ID, user.UpdateInput{
+			RestrictionProfileID: restrictionProfileID,
+		})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) UserUpdate(ctx context.Context, input UserUpdateInput) (ret *models.User, err error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	restrictionProfileID, err := translator.optionalIntFromString(input.RestrictionProfileID, "restriction_profile_id")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		if err := r.validateRestrictionProfile(ctx, restrictionProfileID); err != nil {
+			return err
+		}
+
+		ret, err = r.userService().Update(ctx, id, user.UpdateInput{
+			Username:             input.Username,
+			Password:             input.Password,
+			Role:                 input.Role,
+			RestrictionProfileID: restrictionProfileID,
+			OIDCSubject:          input.OidcSubject,
+		})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) UserDestroy(ctx context.Context, id string) (bool, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return false, err
+	}
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInput, err)
+	}
+
+	if current := session.GetCurrentUser(ctx); current != nil && current.ID == userID {
+		return false, errDestroyCurrentUser
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.userService().Destroy(ctx, userID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) ChangePassword(ctx context.Context, input ChangePasswordInput) (bool, error) {
+	current := session.GetCurrentUser(ctx)
+	if current == nil {
+		return false, fmt.Errorf("%w: no current user", ErrInput)
+	}
+
+	if r.userService().IsOwner(current.Username) {
+		return false, user.ErrOwner
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		return r.userService().ChangePassword(ctx, current.ID, input.CurrentPassword, input.NewPassword)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
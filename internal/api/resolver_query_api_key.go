@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+func (r *queryResolver) APIKeys(ctx context.Context) (ret []*models.APIKey, err error) {
+	current := session.GetCurrentUser(ctx)
+	if current == nil || current.ID == 0 {
+		return []*models.APIKey{}, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if isAdmin(ctx) {
+			ret, err = r.repository.APIKey.All(ctx)
+		} else {
+			ret, err = r.repository.APIKey.FindByUserID(ctx, current.ID)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) FindAuditEvents(ctx context.Context, auditEventFilter *models.AuditEventFilterType, filter *models.FindFilterType) (ret *FindAuditEventsResultType, err error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		events, total, err := r.repository.AuditEvent.Query(ctx, auditEventFilter, filter)
+		if err != nil {
+			return err
+		}
+
+		ret = &FindAuditEventsResultType{
+			Count:       total,
+			AuditEvents: events,
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/stashapp/stash/internal/manager/config"
@@ -14,7 +15,27 @@ import (
 )
 
 func (r *queryResolver) Configuration(ctx context.Context) (*ConfigResult, error) {
-	return makeConfigResult(), nil
+	ret := makeConfigResult()
+
+	if !isAdmin(ctx) {
+		redactConfigResult(ret)
+	}
+
+	return ret, nil
+}
+
+// redactConfigResult removes the credentials from the configuration.
+func redactConfigResult(c *ConfigResult) {
+	c.General.APIKey = ""
+	c.General.Password = ""
+
+	boxes := make([]*models.StashBox, len(c.General.StashBoxes))
+	for i, b := range c.General.StashBoxes {
+		redacted := *b
+		redacted.APIKey = ""
+		boxes[i] = &redacted
+	}
+	c.General.StashBoxes = boxes
 }
 
 func (r *queryResolver) Directory(ctx context.Context, path, locale *string) (*Directory, error) {
@@ -112,6 +133,12 @@ func makeConfigGeneralResult() *ConfigGeneralResult {
 		Username:                      config.GetUsername(),
 		Password:                      config.GetPasswordHash(),
 		MaxSessionAge:                 config.GetMaxSessionAge(),
+		AuditLogRetentionDays:         config.GetAuditLogRetentionDays(),
+		LoginMaxAttempts:              config.GetLoginMaxAttempts(),
+		LoginMaxBlockMinutes:          config.GetLoginMaxBlockMinutes(),
+		GraphqlRateLimit:              config.GetGraphQLRateLimit(),
+		GraphqlRateLimitBurst:         config.GetGraphQLRateLimitBurst(),
+		TotpRequiredForAdmins:         config.GetTOTPRequiredForAdmins(),
 		LogFile:                       &logFile,
 		LogOut:                        config.GetLogOut(),
 		LogLevel:                      config.GetLogLevel(),
@@ -120,6 +147,8 @@ func makeConfigGeneralResult() *ConfigGeneralResult {
 		ImageExtensions:               config.GetImageExtensions(),
 		GalleryExtensions:             config.GetGalleryExtensions(),
 		CreateGalleriesFromFolders:    config.GetCreateGalleriesFromFolders(),
+		SetImageDateFromMetadata:      config.GetSetImageDateFromMetadata(),
+		SetImageTagsFromMetadata:      config.GetSetImageTagsFromMetadata(),
 		Excludes:                      config.GetExcludes(),
 		ImageExcludes:                 config.GetImageExcludes(),
 		CustomPerformerImageLocation:  &customPerformerImageLocation,
@@ -198,12 +227,19 @@ func makeConfigInterfaceResult() *ConfigInterfaceResult {
 func makeConfigDLNAResult() *ConfigDLNAResult {
 	config := config.GetInstance()
 
+	var restrictionProfileID *string
+	if id := config.GetDLNARestrictionProfileID(); id != 0 {
+		s := strconv.Itoa(id)
+		restrictionProfileID = &s
+	}
+
 	return &ConfigDLNAResult{
-		ServerName:     config.GetDLNAServerName(),
-		Enabled:        config.GetDLNADefaultEnabled(),
-		WhitelistedIPs: config.GetDLNADefaultIPWhitelist(),
-		Interfaces:     config.GetDLNAInterfaces(),
-		VideoSortOrder: config.GetVideoSortOrder(),
+		ServerName:           config.GetDLNAServerName(),
+		Enabled:              config.GetDLNADefaultEnabled(),
+		WhitelistedIPs:       config.GetDLNADefaultIPWhitelist(),
+		Interfaces:           config.GetDLNAInterfaces(),
+		VideoSortOrder:       config.GetVideoSortOrder(),
+		RestrictionProfileID: restrictionProfileID,
 	}
 }
 
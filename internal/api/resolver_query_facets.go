@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const defaultFacetLimit = 10
+
+func facetOptions(facets []models.FacetType, limit *int) models.FacetOptions {
+	ret := models.FacetOptions{
+		Facets: facets,
+		Limit:  defaultFacetLimit,
+	}
+	if limit != nil {
+		ret.Limit = *limit
+	}
+
+	return ret
+}
+
+func (r *queryResolver) SceneFacets(ctx context.Context, sceneFilter *models.SceneFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if sceneFilter == nil {
+				sceneFilter = &models.SceneFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, sceneFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Scene.Facets(ctx, sceneFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) SceneMarkerFacets(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if sceneMarkerFilter == nil {
+				sceneMarkerFilter = &models.SceneMarkerFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, sceneMarkerFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.SceneMarker.Facets(ctx, sceneMarkerFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) ImageFacets(ctx context.Context, imageFilter *models.ImageFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if imageFilter == nil {
+				imageFilter = &models.ImageFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, imageFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Image.Facets(ctx, imageFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) GalleryFacets(ctx context.Context, galleryFilter *models.GalleryFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if galleryFilter == nil {
+				galleryFilter = &models.GalleryFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, galleryFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Gallery.Facets(ctx, galleryFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) PerformerFacets(ctx context.Context, performerFilter *models.PerformerFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if performerFilter == nil {
+				performerFilter = &models.PerformerFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, performerFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Performer.Facets(ctx, performerFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) StudioFacets(ctx context.Context, studioFilter *models.StudioFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if studioFilter == nil {
+				studioFilter = &models.StudioFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, studioFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Studio.Facets(ctx, studioFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) MovieFacets(ctx context.Context, movieFilter *models.MovieFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if movieFilter == nil {
+				movieFilter = &models.MovieFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, movieFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Movie.Facets(ctx, movieFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) TagFacets(ctx context.Context, tagFilter *models.TagFilterType, filter *models.FindFilterType, query *string, facets []models.FacetType, limit *int) (ret []*models.Facet, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if tagFilter == nil {
+				tagFilter = &models.TagFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, tagFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
+		ret, err = r.repository.Tag.Facets(ctx, tagFilter, filter, facetOptions(facets, limit))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
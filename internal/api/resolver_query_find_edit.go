@@ -0,0 +1,23 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) FindEditHistory(ctx context.Context, entityType models.EditEntityType, id string) (ret []*models.Edit, err error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Edit.FindByEntity(ctx, entityType, idInt)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return ret, err
+}
@@ -23,8 +23,20 @@ func (r *queryResolver) FindGallery(ctx context.Context, id string) (ret *models
 	return ret, nil
 }
 
-func (r *queryResolver) FindGalleries(ctx context.Context, galleryFilter *models.GalleryFilterType, filter *models.FindFilterType) (ret *FindGalleriesResultType, err error) {
+func (r *queryResolver) FindGalleries(ctx context.Context, galleryFilter *models.GalleryFilterType, filter *models.FindFilterType, query *string) (ret *FindGalleriesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if galleryFilter == nil {
+				galleryFilter = &models.GalleryFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, galleryFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		galleries, total, err := r.repository.Gallery.Query(ctx, galleryFilter, filter)
 		if err != nil {
 			return err
@@ -34,6 +46,9 @@ func (r *queryResolver) FindGalleries(ctx context.Context, galleryFilter *models
 			Count:     total,
 			Galleries: galleries,
 		}
+		if n := len(galleries); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 		return nil
 	}); err != nil {
 		return nil, err
@@ -46,10 +46,22 @@ func (r *queryResolver) FindImage(ctx context.Context, id *string, checksum *str
 	return image, nil
 }
 
-func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.ImageFilterType, imageIds []int, filter *models.FindFilterType) (ret *FindImagesResultType, err error) {
+func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.ImageFilterType, imageIds []int, filter *models.FindFilterType, query *string) (ret *FindImagesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
 		qb := r.repository.Image
 
+		if query != nil {
+			if imageFilter == nil {
+				imageFilter = &models.ImageFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, imageFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		fields := graphql.CollectAllFields(ctx)
 
 		result, err := qb.Query(ctx, models.ImageQueryOptions{
@@ -76,6 +88,9 @@ func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.Imag
 			Megapixels: result.Megapixels,
 			Filesize:   result.TotalSize,
 		}
+		if n := len(images); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 
 		return nil
 	}); err != nil {
@@ -85,6 +100,21 @@ func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.Imag
 	return ret, nil
 }
 
+func (r *queryResolver) FindDuplicateImages(ctx context.Context, distance *int) (ret [][]*models.Image, err error) {
+	dist := 0
+	if distance != nil {
+		dist = *distance
+	}
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.Image.FindDuplicates(ctx, dist)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
 func (r *queryResolver) AllImages(ctx context.Context) (ret []*models.Image, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
 		ret, err = r.repository.Image.All(ctx)
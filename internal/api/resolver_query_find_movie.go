@@ -23,8 +23,20 @@ func (r *queryResolver) FindMovie(ctx context.Context, id string) (ret *models.M
 	return ret, nil
 }
 
-func (r *queryResolver) FindMovies(ctx context.Context, movieFilter *models.MovieFilterType, filter *models.FindFilterType) (ret *FindMoviesResultType, err error) {
+func (r *queryResolver) FindMovies(ctx context.Context, movieFilter *models.MovieFilterType, filter *models.FindFilterType, query *string) (ret *FindMoviesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if movieFilter == nil {
+				movieFilter = &models.MovieFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, movieFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		movies, total, err := r.repository.Movie.Query(ctx, movieFilter, filter)
 		if err != nil {
 			return err
@@ -34,6 +46,9 @@ func (r *queryResolver) FindMovies(ctx context.Context, movieFilter *models.Movi
 			Count:  total,
 			Movies: movies,
 		}
+		if n := len(movies); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 		return nil
 	}); err != nil {
 		return nil, err
@@ -23,8 +23,20 @@ func (r *queryResolver) FindPerformer(ctx context.Context, id string) (ret *mode
 	return ret, nil
 }
 
-func (r *queryResolver) FindPerformers(ctx context.Context, performerFilter *models.PerformerFilterType, filter *models.FindFilterType) (ret *FindPerformersResultType, err error) {
+func (r *queryResolver) FindPerformers(ctx context.Context, performerFilter *models.PerformerFilterType, filter *models.FindFilterType, query *string) (ret *FindPerformersResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if performerFilter == nil {
+				performerFilter = &models.PerformerFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, performerFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		performers, total, err := r.repository.Performer.Query(ctx, performerFilter, filter)
 		if err != nil {
 			return err
@@ -34,6 +46,9 @@ func (r *queryResolver) FindPerformers(ctx context.Context, performerFilter *mod
 			Count:      total,
 			Performers: performers,
 		}
+		if n := len(performers); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 		return nil
 	}); err != nil {
 		return nil, err
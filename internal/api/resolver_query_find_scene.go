@@ -73,7 +73,7 @@ func (r *queryResolver) FindSceneByHash(ctx context.Context, input SceneHashInpu
 	return scene, nil
 }
 
-func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.SceneFilterType, sceneIDs []int, filter *models.FindFilterType) (ret *FindScenesResultType, err error) {
+func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.SceneFilterType, sceneIDs []int, filter *models.FindFilterType, query *string) (ret *FindScenesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
 		var scenes []*models.Scene
 		var err error
@@ -84,6 +84,7 @@ func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.Scen
 		if len(sceneIDs) > 0 {
 			scenes, err = r.repository.Scene.FindMany(ctx, sceneIDs)
 			if err == nil {
+				scenes = visibleScenes(scenes)
 				result.Count = len(scenes)
 				for _, s := range scenes {
 					if err = s.LoadPrimaryFile(ctx, r.repository.File); err != nil {
@@ -101,6 +102,17 @@ func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.Scen
 				}
 			}
 		} else {
+			if query != nil {
+				if sceneFilter == nil {
+					sceneFilter = &models.SceneFilterType{}
+				}
+
+				filter, err = r.applyFilterQuery(ctx, *query, sceneFilter, filter)
+				if err != nil {
+					return err
+				}
+			}
+
 			result, err = r.repository.Scene.Query(ctx, models.SceneQueryOptions{
 				QueryOptions: models.QueryOptions{
 					FindFilter: filter,
@@ -125,6 +137,9 @@ func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.Scen
 			Duration: result.TotalDuration,
 			Filesize: result.TotalSize,
 		}
+		if n := len(scenes); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 
 		return nil
 	}); err != nil {
@@ -180,6 +195,9 @@ func (r *queryResolver) FindScenesByPathRegex(ctx context.Context, filter *model
 			Duration: result.TotalDuration,
 			Filesize: result.TotalSize,
 		}
+		if n := len(scenes); n > 0 {
+			ret.NextCursor = queryFilter.NextCursor(n)
+		}
 
 		return nil
 	}); err != nil {
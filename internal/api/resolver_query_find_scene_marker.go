@@ -6,8 +6,20 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 )
 
-func (r *queryResolver) FindSceneMarkers(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, filter *models.FindFilterType) (ret *FindSceneMarkersResultType, err error) {
+func (r *queryResolver) FindSceneMarkers(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, filter *models.FindFilterType, query *string) (ret *FindSceneMarkersResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if sceneMarkerFilter == nil {
+				sceneMarkerFilter = &models.SceneMarkerFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, sceneMarkerFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		sceneMarkers, total, err := r.repository.SceneMarker.Query(ctx, sceneMarkerFilter, filter)
 		if err != nil {
 			return err
@@ -16,6 +28,9 @@ func (r *queryResolver) FindSceneMarkers(ctx context.Context, sceneMarkerFilter
 			Count:        total,
 			SceneMarkers: sceneMarkers,
 		}
+		if n := len(sceneMarkers); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 
 		return nil
 	}); err != nil {
@@ -24,8 +24,20 @@ func (r *queryResolver) FindStudio(ctx context.Context, id string) (ret *models.
 	return ret, nil
 }
 
-func (r *queryResolver) FindStudios(ctx context.Context, studioFilter *models.StudioFilterType, filter *models.FindFilterType) (ret *FindStudiosResultType, err error) {
+func (r *queryResolver) FindStudios(ctx context.Context, studioFilter *models.StudioFilterType, filter *models.FindFilterType, query *string) (ret *FindStudiosResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if studioFilter == nil {
+				studioFilter = &models.StudioFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, studioFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		studios, total, err := r.repository.Studio.Query(ctx, studioFilter, filter)
 		if err != nil {
 			return err
@@ -35,6 +47,9 @@ func (r *queryResolver) FindStudios(ctx context.Context, studioFilter *models.St
 			Count:   total,
 			Studios: studios,
 		}
+		if n := len(studios); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 
 		return nil
 	}); err != nil {
@@ -23,8 +23,20 @@ func (r *queryResolver) FindTag(ctx context.Context, id string) (ret *models.Tag
 	return ret, nil
 }
 
-func (r *queryResolver) FindTags(ctx context.Context, tagFilter *models.TagFilterType, filter *models.FindFilterType) (ret *FindTagsResultType, err error) {
+func (r *queryResolver) FindTags(ctx context.Context, tagFilter *models.TagFilterType, filter *models.FindFilterType, query *string) (ret *FindTagsResultType, err error) {
 	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if query != nil {
+			if tagFilter == nil {
+				tagFilter = &models.TagFilterType{}
+			}
+
+			var err error
+			filter, err = r.applyFilterQuery(ctx, *query, tagFilter, filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		tags, total, err := r.repository.Tag.Query(ctx, tagFilter, filter)
 		if err != nil {
 			return err
@@ -34,6 +46,9 @@ func (r *queryResolver) FindTags(ctx context.Context, tagFilter *models.TagFilte
 			Count: total,
 			Tags:  tags,
 		}
+		if n := len(tags); n > 0 {
+			ret.NextCursor = filter.NextCursor(n)
+		}
 
 		return nil
 	}); err != nil {
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/internal/manager"
+)
+
+func (r *queryResolver) LoginBlocks(ctx context.Context) ([]*LoginBlock, error) {
+	if err := checkUnrestricted(ctx); err != nil {
+		return nil, err
+	}
+
+	blocks := manager.GetInstance().SessionStore.LoginBlocks()
+
+	ret := make([]*LoginBlock, len(blocks))
+	for i, b := range blocks {
+		ret[i] = &LoginBlock{
+			FailedAttempts: b.FailedAttempts,
+			BlockedUntil:   b.BlockedUntil,
+		}
+
+		if b.IP != "" {
+			ip := b.IP
+			ret[i].IP = &ip
+		}
+		if b.Username != "" {
+			username := b.Username
+			ret[i].Username = &username
+		}
+	}
+
+	return ret, nil
+}
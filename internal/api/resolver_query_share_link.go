@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+func (r *queryResolver) ShareLinks(ctx context.Context) (ret []*models.ShareLink, err error) {
+	current := session.GetCurrentUser(ctx)
+	isAdmin := isAdmin(ctx)
+	if !isAdmin && (current == nil || current.ID == 0) {
+		return []*models.ShareLink{}, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		if isAdmin {
+			ret, err = r.repository.ShareLink.All(ctx)
+		} else {
+			ret, err = r.repository.ShareLink.FindByUserID(ctx, current.ID)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const defaultSimilarLimit = 10
+
+func similarityOptions(limit *int, phash *bool) models.SimilarityOptions {
+	ret := models.SimilarityOptions{
+		Limit: defaultSimilarLimit,
+	}
+	if limit != nil {
+		ret.Limit = *limit
+	}
+	if phash != nil {
+		ret.PHash = *phash
+	}
+
+	return ret
+}
+
+func similarityIDs(scores []models.SimilarityScore) []int {
+	ret := make([]int, len(scores))
+	for i, s := range scores {
+		ret[i] = s.ID
+	}
+
+	return ret
+}
+
+func (r *queryResolver) SimilarScenes(ctx context.Context, id string, limit *int, phash *bool) (ret []*SimilarScene, err error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		scores, err := r.repository.Scene.FindSimilar(ctx, idInt, similarityOptions(limit, phash))
+		if err != nil {
+			return err
+		}
+
+		scenes, err := r.repository.Scene.FindMany(ctx, similarityIDs(scores))
+		if err != nil {
+			return err
+		}
+
+		ret = make([]*SimilarScene, 0, len(scores))
+		for i, s := range scores {
+			// skip content hidden by a restriction
+			if scenes[i] == nil {
+				continue
+			}
+
+			ret = append(ret, &SimilarScene{
+				Scene: scenes[i],
+				Score: s.Score,
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) SimilarGalleries(ctx context.Context, id string, limit *int) (ret []*SimilarGallery, err error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		scores, err := r.repository.Gallery.FindSimilar(ctx, idInt, similarityOptions(limit, nil))
+		if err != nil {
+			return err
+		}
+
+		galleries, err := r.repository.Gallery.FindMany(ctx, similarityIDs(scores))
+		if err != nil {
+			return err
+		}
+
+		ret = make([]*SimilarGallery, 0, len(scores))
+		for i, s := range scores {
+			// skip content hidden by a restriction
+			if galleries[i] == nil {
+				continue
+			}
+
+			ret = append(ret, &SimilarGallery{
+				Gallery: galleries[i],
+				Score:   s.Score,
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) SimilarPerformers(ctx context.Context, id string, limit *int) (ret []*SimilarPerformer, err error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		scores, err := r.repository.Performer.FindSimilar(ctx, idInt, similarityOptions(limit, nil))
+		if err != nil {
+			return err
+		}
+
+		performers, err := r.repository.Performer.FindMany(ctx, similarityIDs(scores))
+		if err != nil {
+			return err
+		}
+
+		ret = make([]*SimilarPerformer, 0, len(scores))
+		for i, s := range scores {
+			// skip content hidden by a restriction
+			if performers[i] == nil {
+				continue
+			}
+
+			ret = append(ret, &SimilarPerformer{
+				Performer: performers[i],
+				Score:     s.Score,
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
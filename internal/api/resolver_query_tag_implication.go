@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/internal/api/loaders"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const defaultTagImplicationReportLimit = 50
+
+func (r *queryResolver) AllTagImplications(ctx context.Context) (ret []*models.TagImplication, err error) {
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		ret, err = r.repository.TagImplication.All(ctx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// impliedTagsReport groups the missing implied tags by object, returning
+// the number of objects, and the ids of at most limit of the objects with
+// the ids of their missing tags.
+func impliedTagsReport(missing []*models.ImpliedTag, limit int) (int, []int, map[int][]int) {
+	objectIDs, tagIDs := models.GroupImpliedTags(missing)
+	count := len(objectIDs)
+	if len(objectIDs) > limit {
+		objectIDs = objectIDs[:limit]
+	}
+
+	return count, objectIDs, tagIDs
+}
+
+func (r *queryResolver) TagImplicationReport(ctx context.Context, limit *int) (*TagImplicationReport, error) {
+	l := defaultTagImplicationReportLimit
+	if limit != nil && *limit >= 0 {
+		l = *limit
+	}
+
+	missing := make(map[models.TagImplicationObjectType][]*models.ImpliedTag)
+	if err := r.withReadTxn(ctx, func(ctx context.Context) error {
+		for _, t := range models.AllTagImplicationObjectType {
+			m, err := r.repository.TagImplication.FindMissing(ctx, t, nil)
+			if err != nil {
+				return err
+			}
+			missing[t] = m
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := &TagImplicationReport{
+		Scenes:    []*ImpliedSceneTags{},
+		Images:    []*ImpliedImageTags{},
+		Galleries: []*ImpliedGalleryTags{},
+	}
+	dataLoaders := loaders.From(ctx)
+
+	var ids []int
+	var tagIDs map[int][]int
+
+	ret.SceneCount, ids, tagIDs = impliedTagsReport(missing[models.TagImplicationObjectTypeScene], l)
+	scenes, errs := dataLoaders.SceneByID.LoadAll(ids)
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+	for _, s := range visibleScenes(scenes) {
+		tags, errs := dataLoaders.TagByID.LoadAll(tagIDs[s.ID])
+		if err := firstError(errs); err != nil {
+			return nil, err
+		}
+		ret.Scenes = append(ret.Scenes, &ImpliedSceneTags{Scene: s, Tags: tags})
+	}
+
+	ret.ImageCount, ids, tagIDs = impliedTagsReport(missing[models.TagImplicationObjectTypeImage], l)
+	images, errs := dataLoaders.ImageByID.LoadAll(ids)
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+	for _, i := range visibleImages(images) {
+		tags, errs := dataLoaders.TagByID.LoadAll(tagIDs[i.ID])
+		if err := firstError(errs); err != nil {
+			return nil, err
+		}
+		ret.Images = append(ret.Images, &ImpliedImageTags{Image: i, Tags: tags})
+	}
+
+	ret.GalleryCount, ids, tagIDs = impliedTagsReport(missing[models.TagImplicationObjectTypeGallery], l)
+	galleries, errs := dataLoaders.GalleryByID.LoadAll(ids)
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+	for _, g := range visibleGalleries(galleries) {
+		tags, errs := dataLoaders.TagByID.LoadAll(tagIDs[g.ID])
+		if err := firstError(errs); err != nil {
+			return nil, err
+		}
+		ret.Galleries = append(ret.Galleries, &ImpliedGalleryTags{Gallery: g, Tags: tags})
+	}
+
+	return ret, nil
+}
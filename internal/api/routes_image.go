@@ -17,11 +17,13 @@ import (
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/txn"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
 type ImageFinder interface {
+	models.GalleryIDLoader
 	Find(ctx context.Context, id int) (*models.Image, error)
 	FindByChecksum(ctx context.Context, checksum string) ([]*models.Image, error)
 }
@@ -57,11 +59,9 @@ func (rs imageRoutes) Thumbnail(w http.ResponseWriter, r *http.Request) {
 	if exists {
 		utils.ServeStaticFile(w, r, filepath)
 	} else {
-		const useDefault = true
-
 		f := img.Files.Primary()
 		if f == nil {
-			rs.serveImage(w, r, img, useDefault)
+			rs.serveThumbnailFallback(w, r, img)
 			return
 		}
 
@@ -86,7 +86,7 @@ func (rs imageRoutes) Thumbnail(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// backwards compatibility - fallback to original image instead
-			rs.serveImage(w, r, img, useDefault)
+			rs.serveThumbnailFallback(w, r, img)
 			return
 		}
 
@@ -103,6 +103,19 @@ func (rs imageRoutes) Thumbnail(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveThumbnailFallback serves the original image in place of a thumbnail
+// which cannot be generated. Share links which do not allow downloads may
+// not request the original image.
+func (rs imageRoutes) serveThumbnailFallback(w http.ResponseWriter, r *http.Request, img *models.Image) {
+	if l := getShareLink(r.Context()); l != nil && !l.AllowDownload {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	const useDefault = true
+	rs.serveImage(w, r, img, useDefault)
+}
+
 func (rs imageRoutes) Preview(w http.ResponseWriter, r *http.Request) {
 	img := r.Context().Value(imageKey).(*models.Image)
 	filepath := manager.GetInstance().Paths.Generated.GetClipPreviewPath(img.Checksum, models.DefaultGthumbWidth)
@@ -177,6 +190,10 @@ func (rs imageRoutes) ImageCtx(next http.Handler) http.Handler {
 				}
 			}
 
+			if image != nil && !shareLinkAllows(ctx, models.ShareLinkEntityImage, image.ID) && !rs.inSharedGallery(ctx, image) {
+				image = nil
+			}
+
 			return nil
 		})
 		if image == nil {
@@ -188,3 +205,21 @@ func (rs imageRoutes) ImageCtx(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// inSharedGallery returns true if the image is in the gallery shared by the
+// share link the request was authenticated with.
+func (rs imageRoutes) inSharedGallery(ctx context.Context, image *models.Image) bool {
+	l := getShareLink(ctx)
+	if l == nil || l.EntityType != models.ShareLinkEntityGallery {
+		return false
+	}
+
+	if err := image.LoadGalleryIDs(ctx, rs.imageFinder); err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Errorf("error loading galleries for image %d: %v", image.ID, err)
+		}
+		return false
+	}
+
+	return intslice.IntInclude(image.GalleryIDs.List(), l.EntityID)
+}
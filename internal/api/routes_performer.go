@@ -17,6 +17,7 @@ import (
 type PerformerFinder interface {
 	Find(ctx context.Context, id int) (*models.Performer, error)
 	GetImage(ctx context.Context, performerID int) ([]byte, error)
+	GetImageByID(ctx context.Context, performerID int, imageID int) ([]byte, error)
 }
 
 type performerRoutes struct {
@@ -30,6 +31,7 @@ func (rs performerRoutes) Routes() chi.Router {
 	r.Route("/{performerId}", func(r chi.Router) {
 		r.Use(rs.PerformerCtx)
 		r.Get("/image", rs.Image)
+		r.Get("/image/{imageId}", rs.ImageByID)
 	})
 
 	return r
@@ -61,6 +63,35 @@ func (rs performerRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	utils.ServeImage(w, r, image)
 }
 
+func (rs performerRoutes) ImageByID(w http.ResponseWriter, r *http.Request) {
+	performer := r.Context().Value(performerKey).(*models.Performer)
+	imageID, err := strconv.Atoi(chi.URLParam(r, "imageId"))
+	if err != nil {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	var image []byte
+	readTxnErr := txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		var err error
+		image, err = rs.performerFinder.GetImageByID(ctx, performer.ID, imageID)
+		return err
+	})
+	if errors.Is(readTxnErr, context.Canceled) {
+		return
+	}
+	if readTxnErr != nil {
+		logger.Warnf("read transaction error on fetch performer image: %v", readTxnErr)
+	}
+
+	if len(image) == 0 {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	utils.ServeImage(w, r, image)
+}
+
 func (rs performerRoutes) PerformerCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		performerID, err := strconv.Atoi(chi.URLParam(r, "performerId"))
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/stashapp/stash/internal/api/urlbuilders"
+	"github.com/stashapp/stash/pkg/file"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/playlist"
+	"github.com/stashapp/stash/pkg/scene"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+type PlaylistFinder interface {
+	Find(ctx context.Context, id int) (*models.Playlist, error)
+}
+
+type PlaylistSceneMarkerFinder interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.SceneMarker, error)
+}
+
+type PlaylistImageFinder interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Image, error)
+}
+
+type playlistRoutes struct {
+	txnManager        txn.Manager
+	playlistFinder    PlaylistFinder
+	itemResolver      playlist.ItemResolver
+	sceneFinder       scene.IDFinder
+	sceneMarkerFinder PlaylistSceneMarkerFinder
+	imageFinder       PlaylistImageFinder
+	fileFinder        file.Finder
+}
+
+func (rs playlistRoutes) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/{playlistId}", func(r chi.Router) {
+		r.Use(rs.PlaylistCtx)
+		r.Get("/playlist.m3u8", rs.M3U)
+		r.Get("/playlist.xspf", rs.XSPF)
+	})
+
+	return r
+}
+
+func (rs playlistRoutes) M3U(w http.ResponseWriter, r *http.Request) {
+	rs.serve(w, r, playlist.M3UContentType, playlist.WriteM3U)
+}
+
+func (rs playlistRoutes) XSPF(w http.ResponseWriter, r *http.Request) {
+	rs.serve(w, r, playlist.XSPFContentType, playlist.WriteXSPF)
+}
+
+func (rs playlistRoutes) serve(w http.ResponseWriter, r *http.Request, contentType string, write func(w io.Writer, name string, entries []playlist.Entry) error) {
+	p := r.Context().Value(playlistKey).(*models.Playlist)
+
+	// the key is created outside of the read transaction
+	apiKey := getURLAPIKey(r.Context())
+
+	var entries []playlist.Entry
+	readTxnErr := txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		var err error
+		entries, err = rs.getEntries(ctx, r, p, apiKey)
+		return err
+	})
+	if errors.Is(readTxnErr, context.Canceled) {
+		return
+	}
+	if readTxnErr != nil {
+		logger.Errorf("error getting entries of playlist %d: %v", p.ID, readTxnErr)
+		http.Error(w, readTxnErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	if err := write(w, p.Name, entries); err != nil {
+		logger.Warnf("error writing playlist %d: %v", p.ID, err)
+	}
+}
+
+// withAPIKey returns rawURL with the API key added to its query, so that
+// external players are able to request it.
+func withAPIKey(rawURL string, apiKey string) string {
+	if apiKey == "" {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// shouldn't happen
+		panic(err)
+	}
+
+	v := u.Query()
+	v.Set("apikey", apiKey)
+	u.RawQuery = v.Encode()
+	return u.String()
+}
+
+func (rs playlistRoutes) getEntries(ctx context.Context, r *http.Request, p *models.Playlist, apiKey string) ([]playlist.Entry, error) {
+	items, err := rs.itemResolver.Items(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var sceneIDs, markerIDs, imageIDs []int
+	for _, item := range items {
+		switch {
+		case item.SceneID != nil:
+			sceneIDs = intslice.IntAppendUnique(sceneIDs, *item.SceneID)
+		case item.SceneMarkerID != nil:
+			markerIDs = intslice.IntAppendUnique(markerIDs, *item.SceneMarkerID)
+		case item.ImageID != nil:
+			imageIDs = intslice.IntAppendUnique(imageIDs, *item.ImageID)
+		}
+	}
+
+	markers := make(map[int]*models.SceneMarker)
+	if len(markerIDs) > 0 {
+		found, err := rs.sceneMarkerFinder.FindMany(ctx, markerIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range found {
+			// skip content hidden by a restriction
+			if m == nil {
+				continue
+			}
+
+			markers[m.ID] = m
+			sceneIDs = intslice.IntAppendUnique(sceneIDs, m.SceneID)
+		}
+	}
+
+	scenes := make(map[int]*models.Scene)
+	if len(sceneIDs) > 0 {
+		found, err := rs.sceneFinder.FindMany(ctx, sceneIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range found {
+			if s == nil {
+				continue
+			}
+
+			if err := s.LoadPrimaryFile(ctx, rs.fileFinder); err != nil {
+				return nil, err
+			}
+			scenes[s.ID] = s
+		}
+	}
+
+	images := make(map[int]*models.Image)
+	if len(imageIDs) > 0 {
+		found, err := rs.imageFinder.FindMany(ctx, imageIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range found {
+			if i == nil {
+				continue
+			}
+
+			images[i.ID] = i
+		}
+	}
+
+	baseURL, _ := r.Context().Value(BaseURLCtxKey).(string)
+
+	sceneEntry := func(s *models.Scene) playlist.Entry {
+		builder := urlbuilders.NewSceneURLBuilder(baseURL, s)
+		ret := playlist.Entry{
+			Title:    s.GetTitle(),
+			Location: builder.GetStreamURL(apiKey).String(),
+			Image:    withAPIKey(builder.GetScreenshotURL(), apiKey),
+		}
+
+		if f := s.Files.Primary(); f != nil {
+			ret.Duration = f.Duration
+		}
+
+		return ret
+	}
+
+	ret := make([]playlist.Entry, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.SceneID != nil:
+			s, found := scenes[*item.SceneID]
+			if !found {
+				continue
+			}
+
+			ret = append(ret, sceneEntry(s))
+		case item.SceneMarkerID != nil:
+			m, found := markers[*item.SceneMarkerID]
+			if !found || scenes[m.SceneID] == nil {
+				continue
+			}
+
+			e := sceneEntry(scenes[m.SceneID])
+			if m.Title != "" {
+				e.Title = m.Title
+			}
+			e.StartTime = m.Seconds
+			ret = append(ret, e)
+		case item.ImageID != nil:
+			i, found := images[*item.ImageID]
+			if !found {
+				continue
+			}
+
+			builder := urlbuilders.NewImageURLBuilder(baseURL, i)
+			ret = append(ret, playlist.Entry{
+				Title:    i.GetTitle(),
+				Location: withAPIKey(builder.GetImageURL(), apiKey),
+				Image:    withAPIKey(builder.GetThumbnailURL(), apiKey),
+			})
+		}
+	}
+
+	return ret, nil
+}
+
+func (rs playlistRoutes) PlaylistCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		playlistID, err := strconv.Atoi(chi.URLParam(r, "playlistId"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		var p *models.Playlist
+		_ = txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+			p, _ = rs.playlistFinder.Find(ctx, playlistID)
+			return nil
+		})
+		if p == nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), playlistKey, p)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -570,7 +570,7 @@ func (rs sceneRoutes) SceneCtx(next http.Handler) http.Handler {
 
 			return nil
 		})
-		if scene == nil {
+		if scene == nil || !shareLinkAllows(r.Context(), models.ShareLinkEntityScene, scene.ID) {
 			http.Error(w, http.StatusText(404), 404)
 			return
 		}
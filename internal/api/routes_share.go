@@ -0,0 +1,345 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stashapp/stash/internal/api/urlbuilders"
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/file"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+type ShareGalleryFinder interface {
+	Find(ctx context.Context, id int) (*models.Gallery, error)
+}
+
+type ShareImageFinder interface {
+	models.GalleryIDLoader
+	Find(ctx context.Context, id int) (*models.Image, error)
+	FindByGalleryID(ctx context.Context, galleryID int) ([]*models.Image, error)
+}
+
+type shareRoutes struct {
+	txnManager    txn.Manager
+	sceneFinder   SceneFinder
+	galleryFinder ShareGalleryFinder
+	imageFinder   ShareImageFinder
+	fileFinder    file.Finder
+}
+
+func (rs shareRoutes) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/{shareToken}", func(r chi.Router) {
+		r.Use(rs.ShareLinkCtx)
+
+		r.Get("/", rs.Page)
+		r.Get("/download", rs.Download)
+		r.Get("/download/{imageId}", rs.DownloadImage)
+	})
+
+	return r
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; padding: 1rem; background: #202b33; color: #f5f8fa; font-family: sans-serif; }
+a { color: #48aff0; }
+video, .image { display: block; max-width: 100%; max-height: 85vh; margin: 0 auto; }
+.images { display: grid; grid-template-columns: repeat(auto-fill, minmax(200px, 1fr)); gap: 0.5rem; }
+.images img { width: 100%; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .VideoURL}}<video controls playsinline preload="metadata" src="{{.VideoURL}}" poster="{{.PosterURL}}"></video>{{end}}
+{{if .ImageURL}}<img class="image" src="{{.ImageURL}}" alt="">{{end}}
+{{if .Images}}<div class="images">
+{{range .Images}}<div><a href="{{.ImageURL}}"><img src="{{.ThumbnailURL}}" loading="lazy" alt=""></a>{{if .DownloadURL}}<a href="{{.DownloadURL}}">Download</a>{{end}}</div>
+{{end}}</div>{{end}}
+{{if .DownloadURL}}<p><a href="{{.DownloadURL}}">Download</a></p>{{end}}
+<p>Shared until {{.ExpiresAt}}</p>
+</body>
+</html>
+`))
+
+type sharePageImage struct {
+	ImageURL     string
+	ThumbnailURL string
+	DownloadURL  string
+}
+
+type sharePageData struct {
+	Title       string
+	VideoURL    string
+	PosterURL   string
+	ImageURL    string
+	Images      []sharePageImage
+	DownloadURL string
+	ExpiresAt   string
+}
+
+// region Handlers
+
+// Page serves a minimal page showing the shared content.
+func (rs shareRoutes) Page(w http.ResponseWriter, r *http.Request) {
+	link := getShareLink(r.Context())
+	token := chi.URLParam(r, "shareToken")
+	prefix := getProxyPrefix(r)
+	downloadURL := prefix + shareEndpoint + "/" + token + "/download"
+
+	data := sharePageData{
+		ExpiresAt: link.ExpiresAt.Format(time.RFC1123),
+	}
+
+	var found bool
+	err := txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		switch link.EntityType {
+		case models.ShareLinkEntityScene:
+			scene, err := rs.sceneFinder.Find(ctx, link.EntityID)
+			if err != nil || scene == nil {
+				return err
+			}
+
+			found = true
+			builder := urlbuilders.NewSceneURLBuilder(prefix, scene)
+			data.Title = scene.GetTitle()
+			// links without downloads may only stream transcoded video
+			videoURL := builder.GetStreamURL("").String()
+			if !link.AllowDownload {
+				videoURL += ".mp4"
+			}
+			data.VideoURL = withShareToken(videoURL, token)
+			data.PosterURL = withShareToken(builder.GetScreenshotURL(), token)
+		case models.ShareLinkEntityImage:
+			image, err := rs.imageFinder.Find(ctx, link.EntityID)
+			if err != nil || image == nil {
+				return err
+			}
+
+			found = true
+			data.Title = imageTitle(image)
+			data.ImageURL = withShareToken(shareImageURL(link, urlbuilders.NewImageURLBuilder(prefix, image)), token)
+		case models.ShareLinkEntityGallery:
+			gallery, err := rs.galleryFinder.Find(ctx, link.EntityID)
+			if err != nil || gallery == nil {
+				return err
+			}
+
+			images, err := rs.imageFinder.FindByGalleryID(ctx, gallery.ID)
+			if err != nil {
+				return err
+			}
+
+			found = true
+			data.Title = gallery.GetTitle()
+			for _, image := range images {
+				builder := urlbuilders.NewImageURLBuilder(prefix, image)
+				i := sharePageImage{
+					ImageURL:     withShareToken(shareImageURL(link, builder), token),
+					ThumbnailURL: withShareToken(builder.GetThumbnailURL(), token),
+				}
+				if link.AllowDownload {
+					i.DownloadURL = downloadURL + "/" + strconv.Itoa(image.ID)
+				}
+				data.Images = append(data.Images, i)
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		logger.Warnf("read transaction error on fetch shared content: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !found {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if link.AllowDownload && link.EntityType != models.ShareLinkEntityGallery {
+		data.DownloadURL = downloadURL
+	}
+
+	var buffer bytes.Buffer
+	if err := sharePageTemplate.Execute(&buffer, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-store")
+	setPageSecurityHeaders(w, r)
+
+	_, _ = w.Write(buffer.Bytes())
+}
+
+// shareImageURL returns the url of the image shown by the share page. Links
+// without downloads show the thumbnail rather than the original image.
+func shareImageURL(link *models.ShareLink, builder urlbuilders.ImageURLBuilder) string {
+	if link.AllowDownload {
+		return builder.GetImageURL()
+	}
+
+	return builder.GetThumbnailURL()
+}
+
+// Download serves the original file of a shared scene or image.
+func (rs shareRoutes) Download(w http.ResponseWriter, r *http.Request) {
+	link := getShareLink(r.Context())
+
+	var f file.File
+	err := txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		switch link.EntityType {
+		case models.ShareLinkEntityScene:
+			scene, err := rs.sceneFinder.Find(ctx, link.EntityID)
+			if err != nil || scene == nil {
+				return err
+			}
+
+			if err := scene.LoadPrimaryFile(ctx, rs.fileFinder); err != nil {
+				return err
+			}
+			if pf := scene.Files.Primary(); pf != nil {
+				f = pf
+			}
+		case models.ShareLinkEntityImage:
+			image, err := rs.imageFinder.Find(ctx, link.EntityID)
+			if err != nil || image == nil {
+				return err
+			}
+
+			if err := image.LoadPrimaryFile(ctx, rs.fileFinder); err != nil {
+				return err
+			}
+			f = image.Files.Primary()
+		}
+
+		return nil
+	})
+
+	rs.serveDownload(w, r, f, err)
+}
+
+// DownloadImage serves the original file of an image of a shared gallery.
+func (rs shareRoutes) DownloadImage(w http.ResponseWriter, r *http.Request) {
+	link := getShareLink(r.Context())
+	imageID, err := strconv.Atoi(chi.URLParam(r, "imageId"))
+	if err != nil || link.EntityType != models.ShareLinkEntityGallery {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	var f file.File
+	err = txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		image, err := rs.imageFinder.Find(ctx, imageID)
+		if err != nil || image == nil {
+			return err
+		}
+
+		if err := image.LoadGalleryIDs(ctx, rs.imageFinder); err != nil {
+			return err
+		}
+		if !intslice.IntInclude(image.GalleryIDs.List(), link.EntityID) {
+			return nil
+		}
+
+		if err := image.LoadPrimaryFile(ctx, rs.fileFinder); err != nil {
+			return err
+		}
+		f = image.Files.Primary()
+
+		return nil
+	})
+
+	rs.serveDownload(w, r, f, err)
+}
+
+// serveDownload serves the file as an attachment, if downloads are allowed
+// by the share link.
+func (rs shareRoutes) serveDownload(w http.ResponseWriter, r *http.Request, f file.File, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		logger.Warnf("read transaction error on fetch shared file: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if f == nil || !getShareLink(r.Context()).AllowDownload {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": f.Base().Basename,
+	}))
+
+	if err := f.Base().Serve(&file.OsFS{}, w, r); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// endregion
+
+// imageTitle returns the title of the image, or its file name if it has no
+// title.
+func imageTitle(i *models.Image) string {
+	if i.Title != "" {
+		return i.Title
+	}
+
+	return filepath.Base(i.Path)
+}
+
+// ShareLinkCtx sets the share link of the token in the path in the context,
+// responding with 404 Not Found if it is invalid, has expired or has been
+// revoked.
+func (rs shareRoutes) ShareLinkCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the link has already been resolved if the request was
+		// authenticated with it
+		link := getShareLink(r.Context())
+		if link == nil {
+			var err error
+			link, err = manager.GetInstance().ShareLinkService.Resolve(r.Context(), chi.URLParam(r, "shareToken"))
+			if err != nil {
+				logger.Errorf("Error resolving share link: %v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if link == nil {
+			http.Error(w, "This link has expired or has been revoked.", http.StatusNotFound)
+			return
+		}
+
+		ctx := withShareLink(r.Context(), link)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -19,6 +19,7 @@ import (
 type StudioFinder interface {
 	studio.Finder
 	GetImage(ctx context.Context, studioID int) ([]byte, error)
+	GetImageByID(ctx context.Context, studioID int, imageID int) ([]byte, error)
 }
 
 type studioRoutes struct {
@@ -32,6 +33,7 @@ func (rs studioRoutes) Routes() chi.Router {
 	r.Route("/{studioId}", func(r chi.Router) {
 		r.Use(rs.StudioCtx)
 		r.Get("/image", rs.Image)
+		r.Get("/image/{imageId}", rs.ImageByID)
 	})
 
 	return r
@@ -70,6 +72,35 @@ func (rs studioRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	utils.ServeImage(w, r, image)
 }
 
+func (rs studioRoutes) ImageByID(w http.ResponseWriter, r *http.Request) {
+	studio := r.Context().Value(studioKey).(*models.Studio)
+	imageID, err := strconv.Atoi(chi.URLParam(r, "imageId"))
+	if err != nil {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	var image []byte
+	readTxnErr := txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		var err error
+		image, err = rs.studioFinder.GetImageByID(ctx, studio.ID, imageID)
+		return err
+	})
+	if errors.Is(readTxnErr, context.Canceled) {
+		return
+	}
+	if readTxnErr != nil {
+		logger.Warnf("read transaction error on fetch studio image: %v", readTxnErr)
+	}
+
+	if len(image) == 0 {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	utils.ServeImage(w, r, image)
+}
+
 func (rs studioRoutes) StudioCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		studioID, err := strconv.Atoi(chi.URLParam(r, "studioId"))
@@ -19,6 +19,7 @@ import (
 type TagFinder interface {
 	tag.Finder
 	GetImage(ctx context.Context, tagID int) ([]byte, error)
+	GetImageByID(ctx context.Context, tagID int, imageID int) ([]byte, error)
 }
 
 type tagRoutes struct {
@@ -32,6 +33,7 @@ func (rs tagRoutes) Routes() chi.Router {
 	r.Route("/{tagId}", func(r chi.Router) {
 		r.Use(rs.TagCtx)
 		r.Get("/image", rs.Image)
+		r.Get("/image/{imageId}", rs.ImageByID)
 	})
 
 	return r
@@ -70,6 +72,35 @@ func (rs tagRoutes) Image(w http.ResponseWriter, r *http.Request) {
 	utils.ServeImage(w, r, image)
 }
 
+func (rs tagRoutes) ImageByID(w http.ResponseWriter, r *http.Request) {
+	tag := r.Context().Value(tagKey).(*models.Tag)
+	imageID, err := strconv.Atoi(chi.URLParam(r, "imageId"))
+	if err != nil {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	var image []byte
+	readTxnErr := txn.WithReadTxn(r.Context(), rs.txnManager, func(ctx context.Context) error {
+		var err error
+		image, err = rs.tagFinder.GetImageByID(ctx, tag.ID, imageID)
+		return err
+	})
+	if errors.Is(readTxnErr, context.Canceled) {
+		return
+	}
+	if readTxnErr != nil {
+		logger.Warnf("read transaction error on fetch tag image: %v", readTxnErr)
+	}
+
+	if len(image) == 0 {
+		http.Error(w, http.StatusText(404), 404)
+		return
+	}
+
+	utils.ServeImage(w, r, image)
+}
+
 func (rs tagRoutes) TagCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tagID, err := strconv.Atoi(chi.URLParam(r, "tagId"))
@@ -44,6 +44,13 @@ const (
 	logoutEndpoint     = "/logout"
 	gqlEndpoint        = "/graphql"
 	playgroundEndpoint = "/playground"
+
+	oidcLoginEndpoint    = loginEndpoint + "/oidc"
+	oidcCallbackEndpoint = oidcLoginEndpoint + "/callback"
+
+	restrictSessionEndpoint = "/session/restrict"
+
+	shareEndpoint = "/share"
 )
 
 var version string
@@ -108,8 +115,16 @@ func Start() error {
 		hookExecutor:   pluginCache,
 	}
 
-	gqlSrv := gqlHandler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	gqlSrv := gqlHandler.New(NewExecutableSchema(Config{
+		Resolvers: resolver,
+		Directives: DirectiveRoot{
+			HasRole: hasRoleDirective,
+		},
+	}))
 	gqlSrv.SetRecoverFunc(recoverFunc)
+	// record mutations rejected by the role middleware
+	gqlSrv.AroundFields(auditMiddleware)
+	gqlSrv.AroundFields(defaultRoleMiddleware)
 	gqlSrv.AddTransport(gqlTransport.Websocket{
 		Upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
@@ -136,12 +151,12 @@ func Start() error {
 	}
 
 	// register GQL handler with plugin cache
-	// chain the visited plugin handler
+	// chain the visited plugin and current user handlers
 	// also requires the dataloader middleware
-	gqlHandler := visitedPluginHandler(dataloaders.Middleware(http.HandlerFunc(gqlHandlerFunc)))
+	gqlHandler := visitedPluginHandler(currentUserHandler()(dataloaders.Middleware(http.HandlerFunc(gqlHandlerFunc))))
 	manager.GetInstance().PluginCache.RegisterGQLHandler(gqlHandler)
 
-	r.HandleFunc(gqlEndpoint, gqlHandlerFunc)
+	r.Handle(gqlEndpoint, rateLimitHandler(c)(http.HandlerFunc(gqlHandlerFunc)))
 	r.HandleFunc(playgroundEndpoint, func(w http.ResponseWriter, r *http.Request) {
 		setPageSecurityHeaders(w, r)
 		endpoint := getProxyPrefix(r) + gqlEndpoint
@@ -177,7 +192,23 @@ func Start() error {
 		txnManager: txnManager,
 		tagFinder:  txnManager.Tag,
 	}.Routes())
+	r.Mount("/playlist", playlistRoutes{
+		txnManager:        txnManager,
+		playlistFinder:    txnManager.Playlist,
+		itemResolver:      txnManager.PlaylistItemResolver(),
+		sceneFinder:       txnManager.Scene,
+		sceneMarkerFinder: txnManager.SceneMarker,
+		imageFinder:       txnManager.Image,
+		fileFinder:        txnManager.File,
+	}.Routes())
 	r.Mount("/downloads", downloadsRoutes{}.Routes())
+	r.Mount(shareEndpoint, shareRoutes{
+		txnManager:    txnManager,
+		sceneFinder:   txnManager.Scene,
+		galleryFinder: txnManager.Gallery,
+		imageFinder:   txnManager.Image,
+		fileFinder:    txnManager.File,
+	}.Routes())
 
 	r.HandleFunc("/css", cssHandler(c, pluginCache))
 	r.HandleFunc("/javascript", javascriptHandler(c, pluginCache))
@@ -188,6 +219,9 @@ func Start() error {
 	r.Get(loginEndpoint, handleLogin(loginUIBox))
 	r.Post(loginEndpoint, handleLoginPost(loginUIBox))
 	r.Get(logoutEndpoint, handleLogout())
+	r.Get(oidcLoginEndpoint, handleOIDCLogin(loginUIBox))
+	r.Get(oidcCallbackEndpoint, handleOIDCCallback(loginUIBox))
+	r.Post(restrictSessionEndpoint, handleRestrictSession())
 	r.HandleFunc(loginEndpoint+"/*", func(w http.ResponseWriter, r *http.Request) {
 		r.URL.Path = strings.TrimPrefix(r.URL.Path, loginEndpoint)
 		w.Header().Set("Cache-Control", "no-cache")
@@ -2,21 +2,31 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/stashapp/stash/internal/manager"
 	"github.com/stashapp/stash/internal/manager/config"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/session"
+	"github.com/stashapp/stash/pkg/txn"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
-const returnURLParam = "returnURL"
+const (
+	returnURLParam = "returnURL"
+	profileIDParam = "profile_id"
+	// codeParam is the code of the authenticator app, entered after the
+	// password.
+	codeParam = "code"
+)
 
 func getLoginPage(loginUIBox fs.FS) []byte {
 	data, err := fs.ReadFile(loginUIBox, "login.html")
@@ -29,9 +39,48 @@ func getLoginPage(loginUIBox fs.FS) []byte {
 type loginTemplateData struct {
 	URL   string
 	Error string
+	// OIDC is true if OpenID Connect login is enabled.
+	OIDC bool
+
+	// SecondFactor is true if the user has entered their password, and
+	// must enter a code of their authenticator app.
+	SecondFactor bool
+	// EnrollmentSecret and EnrollmentURI are the secret to add to the
+	// authenticator app of a user who must enroll.
+	EnrollmentSecret string
+	EnrollmentURI    template.URL
+	// RecoveryCodes are the recovery codes of a user who has just enrolled.
+	RecoveryCodes []string
 }
 
 func serveLoginPage(loginUIBox fs.FS, w http.ResponseWriter, r *http.Request, returnURL string, loginError string) {
+	renderLoginPage(loginUIBox, w, r, loginTemplateData{
+		URL:   returnURL,
+		Error: loginError,
+		OIDC:  session.OIDCEnabled(config.GetInstance()),
+	})
+}
+
+// serveSecondFactorPage serves the login page asking for the code of the
+// authenticator app of the user, showing the secret to add to the app if
+// they must enroll.
+func serveSecondFactorPage(loginUIBox fs.FS, w http.ResponseWriter, r *http.Request, returnURL string, loginError string, enrollment *models.TOTPEnrollment) {
+	data := loginTemplateData{
+		URL:          returnURL,
+		Error:        loginError,
+		SecondFactor: true,
+	}
+
+	if enrollment != nil {
+		data.EnrollmentSecret = enrollment.Secret
+		// otpauth URIs are not considered safe by html/template
+		data.EnrollmentURI = template.URL(enrollment.URI)
+	}
+
+	renderLoginPage(loginUIBox, w, r, data)
+}
+
+func renderLoginPage(loginUIBox fs.FS, w http.ResponseWriter, r *http.Request, data loginTemplateData) {
 	loginPage := string(getLoginPage(loginUIBox))
 	prefix := getProxyPrefix(r)
 	loginPage = strings.ReplaceAll(loginPage, "/%BASE_URL%", prefix)
@@ -43,7 +92,7 @@ func serveLoginPage(loginUIBox fs.FS, w http.ResponseWriter, r *http.Request, re
 	}
 
 	buffer := bytes.Buffer{}
-	err = templ.Execute(&buffer, loginTemplateData{URL: returnURL, Error: loginError})
+	err = templ.Execute(&buffer, data)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("error: %s", err), http.StatusInternalServerError)
 		return
@@ -80,25 +129,151 @@ func handleLoginPost(loginUIBox fs.FS) http.HandlerFunc {
 			url = getProxyPrefix(r) + "/"
 		}
 
-		err := manager.GetInstance().SessionStore.Login(w, r)
+		store := manager.GetInstance().SessionStore
+
+		// the code of the authenticator app is entered after the password
+		_ = r.ParseForm()
+		_, secondFactor := r.PostForm[codeParam]
+
+		operation := auditOperationPassword
+		var username string
+		var recoveryCodes []string
+		var err error
+		if secondFactor {
+			operation = auditOperationTOTP
+			username, recoveryCodes, err = store.LoginSecondFactor(w, r)
+		} else {
+			username, err = store.Login(w, r)
+		}
+
+		var requiredErr *session.SecondFactorRequiredError
+		if errors.As(err, &requiredErr) {
+			serveSecondFactorPage(loginUIBox, w, r, url, "", requiredErr.Enrollment)
+			return
+		}
+
 		if err != nil {
 			// always log the error
 			logger.Errorf("Error logging in: %v", err)
 		}
 
 		var invalidCredentialsError *session.InvalidCredentialsError
+		var invalidCodeErr *session.InvalidCodeError
+		var blockedErr *session.LoginBlockedError
+
+		if errors.As(err, &invalidCodeErr) {
+			recordAuthEvent(r, models.AuditActionLoginFailed, operation, invalidCodeErr.Username)
+
+			serveSecondFactorPage(loginUIBox, w, r, url, "Code is invalid", invalidCodeErr.Enrollment)
+			return
+		}
+
+		if errors.Is(err, session.ErrSecondFactorExpired) {
+			serveLoginPage(loginUIBox, w, r, url, "Login has expired. Log in again")
+			return
+		}
 
 		if errors.As(err, &invalidCredentialsError) {
+			recordAuthEvent(r, models.AuditActionLoginFailed, auditOperationPassword, invalidCredentialsError.Username)
+
 			// serve login page with an error
 			serveLoginPage(loginUIBox, w, r, url, "Username or password is invalid")
 			return
 		}
 
+		if errors.As(err, &blockedErr) {
+			recordAuthError(r, models.AuditActionLoginFailed, operation, r.FormValue("username"), err)
+
+			setRetryAfter(w, blockedErr.Until)
+			serveLoginPage(loginUIBox, w, r, url, "Too many failed login attempts. Try again later")
+			return
+		}
+
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		recordAuthEvent(r, models.AuditActionLogin, operation, username)
+
+		// users who have just enrolled must save their recovery codes
+		if len(recoveryCodes) > 0 {
+			renderLoginPage(loginUIBox, w, r, loginTemplateData{
+				URL:           url,
+				RecoveryCodes: recoveryCodes,
+			})
+			return
+		}
+
+		http.Redirect(w, r, url, http.StatusFound)
+	}
+}
+
+// oidcRedirectURL returns the url of the OpenID Connect callback, which must
+// be registered with the provider.
+func oidcRedirectURL(r *http.Request) string {
+	baseURL, _ := r.Context().Value(BaseURLCtxKey).(string)
+	return baseURL + oidcCallbackEndpoint
+}
+
+// localReturnURL returns the url if it is a path on this server, so that
+// logging in cannot redirect to another site. Otherwise it returns the root
+// of the UI.
+func localReturnURL(r *http.Request, url string) string {
+	// browsers treat backslashes as slashes
+	if strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") && !strings.HasPrefix(url, "/\\") {
+		return url
+	}
+
+	return getProxyPrefix(r) + "/"
+}
+
+func handleOIDCLogin(loginUIBox fs.FS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		returnURL := localReturnURL(r, r.URL.Query().Get(returnURLParam))
+
+		err := manager.GetInstance().SessionStore.OIDCLogin(w, r, oidcRedirectURL(r), returnURL)
+		if errors.Is(err, session.ErrOIDCDisabled) {
+			http.NotFound(w, r)
+			return
+		}
+
+		if err != nil {
+			logger.Errorf("Error logging in with OpenID Connect: %v", err)
+			serveLoginPage(loginUIBox, w, r, returnURL, "Single sign-on is unavailable")
+		}
+	}
+}
+
+func handleOIDCCallback(loginUIBox fs.FS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		url, username, err := manager.GetInstance().SessionStore.OIDCCallback(w, r, oidcRedirectURL(r))
+		if errors.Is(err, session.ErrOIDCDisabled) {
+			http.NotFound(w, r)
+			return
+		}
+
+		// the return url is taken from the query of the login request,
+		// which may have been crafted by another site
+		url = localReturnURL(r, url)
+
+		if err != nil {
+			// always log the error
+			logger.Errorf("Error logging in with OpenID Connect: %v", err)
+
+			var invalidCredentialsError *session.InvalidCredentialsError
+			if errors.As(err, &invalidCredentialsError) {
+				recordAuthEvent(r, models.AuditActionLoginFailed, auditOperationOIDC, invalidCredentialsError.Username)
+				serveLoginPage(loginUIBox, w, r, url, "User is not permitted to log in")
+			} else {
+				recordAuthEvent(r, models.AuditActionLoginFailed, auditOperationOIDC, "")
+				serveLoginPage(loginUIBox, w, r, url, "Single sign-on failed")
+			}
+			return
+		}
+
+		recordAuthEvent(r, models.AuditActionLogin, auditOperationOIDC, username)
+
 		http.Redirect(w, r, url, http.StatusFound)
 	}
 }
@@ -110,6 +285,10 @@ func handleLogout() http.HandlerFunc {
 			return
 		}
 
+		if userID := session.GetCurrentUserID(r.Context()); userID != nil && *userID != "" {
+			recordAuthEvent(r, models.AuditActionLogout, "", *userID)
+		}
+
 		// redirect to the login page if credentials are required
 		prefix := getProxyPrefix(r)
 		if config.GetInstance().HasCredentials() {
@@ -119,3 +298,45 @@ func handleLogout() http.HandlerFunc {
 		}
 	}
 }
+
+// handleRestrictSession restricts the session to the restriction profile in
+// the profile_id form value, until the user logs out.
+func handleRestrictSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profileID, err := strconv.Atoi(r.FormValue(profileIDParam))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s: %v", profileIDParam, err), http.StatusBadRequest)
+			return
+		}
+
+		mgr := manager.GetInstance()
+
+		var profile *models.RestrictionProfile
+		if err := txn.WithReadTxn(r.Context(), mgr.Repository, func(ctx context.Context) error {
+			profile, err = mgr.Repository.RestrictionProfile.Find(ctx, profileID)
+			return err
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if profile == nil {
+			http.Error(w, fmt.Sprintf("restriction profile with id %d not found", profileID), http.StatusNotFound)
+			return
+		}
+
+		err = mgr.SessionStore.RestrictSession(w, r, profileID)
+		if errors.Is(err, session.ErrSessionRestricted) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		logger.Infof("Session restricted to restriction profile %q", profile.Name)
+
+		http.Redirect(w, r, getProxyPrefix(r)+"/", http.StatusFound)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalReturnURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		url    string
+		want   string
+	}{
+		{"empty", "", "", "/"},
+		{"path", "", "/scenes?sortby=date", "/scenes?sortby=date"},
+		{"prefixed path", "/stash", "/stash/scenes", "/stash/scenes"},
+		{"absolute", "/stash", "https://example.com/", "/stash/"},
+		{"protocol relative", "", "//example.com/", "/"},
+		{"backslash", "", "/\\example.com/", "/"},
+		{"relative", "", "scenes", "/"},
+		{"javascript", "", "javascript:alert(1)", "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/oidc/callback", nil)
+			if tt.prefix != "" {
+				r.Header.Set("X-Forwarded-Prefix", tt.prefix)
+			}
+
+			assert.Equal(t, tt.want, localReturnURL(r, tt.url))
+		})
+	}
+}
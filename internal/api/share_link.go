@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// shareLinkParam is the query parameter containing the share link token of
+// requests for shared content.
+const shareLinkParam = "share"
+
+// shareToken returns the share link token of the request, from the path of
+// the share page or the share query parameter.
+func shareToken(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, shareEndpoint+"/") {
+		token, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, shareEndpoint+"/"), "/")
+		return token
+	}
+
+	return r.URL.Query().Get(shareLinkParam)
+}
+
+// resolveShareLink returns the valid share link of the request, or nil if
+// it has none or it does not permit the requested path.
+func resolveShareLink(r *http.Request) *models.ShareLink {
+	token := shareToken(r)
+	if token == "" {
+		return nil
+	}
+
+	link, err := manager.GetInstance().ShareLinkService.Resolve(r.Context(), token)
+	if err != nil {
+		logger.Errorf("Error resolving share link: %v", err)
+		return nil
+	}
+
+	if link == nil || !shareLinkAllowsPath(link, r.URL.Path) {
+		return nil
+	}
+
+	return link
+}
+
+// shareLinkPathEntities are the entity types of the first element of the
+// paths share links may request.
+var shareLinkPathEntities = map[string]models.ShareLinkEntity{
+	"scene": models.ShareLinkEntityScene,
+	"image": models.ShareLinkEntityImage,
+}
+
+// shareLinkRoutes are the routes of scenes and images which share links may
+// request. They serve transcoded streams and thumbnails, rather than the
+// original files.
+var shareLinkRoutes = map[models.ShareLinkEntity]map[string]bool{
+	models.ShareLinkEntityScene: {
+		"stream.mp4":  true,
+		"stream.webm": true,
+		"screenshot":  true,
+	},
+	models.ShareLinkEntityImage: {
+		"thumbnail": true,
+		"preview":   true,
+	},
+}
+
+// shareLinkDownloadRoutes are the routes serving the original files, which
+// share links may only request if they allow downloads.
+var shareLinkDownloadRoutes = map[models.ShareLinkEntity]string{
+	models.ShareLinkEntityScene: "stream",
+	models.ShareLinkEntityImage: "image",
+}
+
+// shareLinkAllowsPath returns true if the link permits requests for the
+// path. The routes of the path check that the requested object is shared by
+// the link.
+func shareLinkAllowsPath(l *models.ShareLink, p string) bool {
+	if strings.HasPrefix(p, shareEndpoint+"/") {
+		return true
+	}
+
+	// the path must be /{scene|image}/{id}/{route}
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	if len(parts) != 3 {
+		return false
+	}
+
+	entityType, ok := shareLinkPathEntities[parts[0]]
+	if !ok {
+		return false
+	}
+
+	switch l.EntityType {
+	case models.ShareLinkEntityScene, models.ShareLinkEntityImage:
+		if entityType != l.EntityType || parts[1] != strconv.Itoa(l.EntityID) {
+			return false
+		}
+	case models.ShareLinkEntityGallery:
+		// the images of the gallery
+		if entityType != models.ShareLinkEntityImage {
+			return false
+		}
+	default:
+		return false
+	}
+
+	route := parts[2]
+	if shareLinkRoutes[entityType][route] {
+		return true
+	}
+
+	return l.AllowDownload && shareLinkDownloadRoutes[entityType] == route
+}
+
+// withShareLinkRestriction restricts the content of requests made with the
+// link like that of the user who created it and the session it was created
+// from.
+func withShareLinkRestriction(ctx context.Context, l *models.ShareLink) (context.Context, error) {
+	var restrictionProfileIDs []int
+	if l.UserID != nil {
+		id, err := manager.GetInstance().UserService.RestrictionProfileID(ctx, *l.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if id != 0 {
+			restrictionProfileIDs = append(restrictionProfileIDs, id)
+		}
+	}
+	if l.RestrictionProfileID != nil {
+		restrictionProfileIDs = append(restrictionProfileIDs, *l.RestrictionProfileID)
+	}
+
+	if len(restrictionProfileIDs) > 0 {
+		ctx = models.WithRestrictionProfileIDs(ctx, restrictionProfileIDs)
+	}
+
+	return ctx, nil
+}
+
+// withShareLink sets the share link the request was authenticated with in
+// the context.
+func withShareLink(ctx context.Context, l *models.ShareLink) context.Context {
+	return context.WithValue(ctx, shareLinkKey, l)
+}
+
+// getShareLink returns the share link the request was authenticated with,
+// or nil if it was not authenticated with a share link.
+func getShareLink(ctx context.Context) *models.ShareLink {
+	l, _ := ctx.Value(shareLinkKey).(*models.ShareLink)
+	return l
+}
+
+// shareLinkAllows returns true if the request was not authenticated with a
+// share link, or its link shares the object.
+func shareLinkAllows(ctx context.Context, entityType models.ShareLinkEntity, id int) bool {
+	l := getShareLink(ctx)
+	return l == nil || (l.EntityType == entityType && l.EntityID == id)
+}
+
+// withShareToken adds the share link token to the url.
+func withShareToken(u string, token string) string {
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+
+	return u + sep + shareLinkParam + "=" + url.QueryEscape(token)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareToken(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://stash/share/1.abc", "1.abc"},
+		{"http://stash/share/1.abc/download/2", "1.abc"},
+		{"http://stash/scene/1/stream?share=1.abc", "1.abc"},
+		{"http://stash/scene/1/stream", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		assert.Equal(t, tt.want, shareToken(r), tt.url)
+	}
+}
+
+func TestShareLinkAllowsPath(t *testing.T) {
+	scene := &models.ShareLink{EntityType: models.ShareLinkEntityScene, EntityID: 1}
+	image := &models.ShareLink{EntityType: models.ShareLinkEntityImage, EntityID: 1}
+	gallery := &models.ShareLink{EntityType: models.ShareLinkEntityGallery, EntityID: 1}
+	sceneDownload := &models.ShareLink{EntityType: models.ShareLinkEntityScene, EntityID: 1, AllowDownload: true}
+	imageDownload := &models.ShareLink{EntityType: models.ShareLinkEntityImage, EntityID: 1, AllowDownload: true}
+	galleryDownload := &models.ShareLink{EntityType: models.ShareLinkEntityGallery, EntityID: 1, AllowDownload: true}
+
+	tests := []struct {
+		name string
+		link *models.ShareLink
+		path string
+		want bool
+	}{
+		{"share page", scene, "/share/1.abc", true},
+		{"scene transcoded stream", scene, "/scene/1/stream.mp4", true},
+		{"scene screenshot", scene, "/scene/1/screenshot", true},
+		{"scene direct stream", scene, "/scene/1/stream", false},
+		{"scene direct stream with download", sceneDownload, "/scene/1/stream", true},
+		{"scene mkv stream", sceneDownload, "/scene/1/stream.mkv", false},
+		{"scene hls stream", scene, "/scene/1/stream.m3u8", false},
+		{"scene caption", scene, "/scene/1/caption", false},
+		{"scene marker", scene, "/scene/1/scene_marker/1/stream", false},
+		{"other scene", scene, "/scene/10/stream.mp4", false},
+		{"scene sprite", scene, "/scene/hash_sprite.jpg", false},
+		{"image of scene link", scene, "/image/1/thumbnail", false},
+		{"image thumbnail", image, "/image/1/thumbnail", true},
+		{"image original", image, "/image/1/image", false},
+		{"image original with download", imageDownload, "/image/1/image", true},
+		{"other image", image, "/image/2/thumbnail", false},
+		{"gallery image thumbnail", gallery, "/image/2/thumbnail", true},
+		{"gallery image original", gallery, "/image/2/image", false},
+		{"gallery image original with download", galleryDownload, "/image/2/image", true},
+		{"gallery scene", galleryDownload, "/scene/1/stream", false},
+		{"graphql", gallery, "/graphql", false},
+		{"performer", scene, "/performer/1/image", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shareLinkAllowsPath(tt.link, tt.path))
+		})
+	}
+}
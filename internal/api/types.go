@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
@@ -61,3 +62,16 @@ func translateSceneMovieIDs(input BulkUpdateIds) (*models.UpdateMovieIDs, error)
 
 	return ret, nil
 }
+
+func translateProfileImages(images []models.EntityImage, imageURL func(imageID int) string) []*ProfileImage {
+	ret := make([]*ProfileImage, len(images))
+	for i, image := range images {
+		ret[i] = &ProfileImage{
+			ID:        strconv.Itoa(image.ID),
+			ImagePath: imageURL(image.ID),
+			Primary:   image.Primary,
+		}
+	}
+
+	return ret
+}
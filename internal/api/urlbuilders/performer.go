@@ -27,3 +27,7 @@ func (b PerformerURLBuilder) GetPerformerImageURL(hasImage bool) string {
 	}
 	return url
 }
+
+func (b PerformerURLBuilder) GetPerformerImageByIDURL(imageID int) string {
+	return b.BaseURL + "/performer/" + b.PerformerID + "/image/" + strconv.Itoa(imageID)
+}
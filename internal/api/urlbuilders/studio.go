@@ -26,3 +26,7 @@ func (b StudioURLBuilder) GetStudioImageURL(hasImage bool) string {
 	}
 	return url
 }
+
+func (b StudioURLBuilder) GetStudioImageByIDURL(imageID int) string {
+	return b.BaseURL + "/studio/" + b.StudioID + "/image/" + strconv.Itoa(imageID)
+}
@@ -26,3 +26,7 @@ func (b TagURLBuilder) GetTagImageURL(hasImage bool) string {
 	}
 	return url
 }
+
+func (b TagURLBuilder) GetTagImageByIDURL(imageID int) string {
+	return b.BaseURL + "/tag/" + b.TagID + "/image/" + strconv.Itoa(imageID)
+}
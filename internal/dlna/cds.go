@@ -40,6 +40,7 @@ import (
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/scene"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/txn"
 )
@@ -253,43 +254,14 @@ func (me *contentDirectoryService) handleBrowseDirectChildren(obj object, host s
 		}
 	}
 
-	// Saved searches
-	// if obj.Path == "saved-searches" {
-	// 	var savedPlaylists []models.Playlist
-	// 	db, _ := models.GetDB()
-	// 	db.Where("is_deo_enabled = ?", true).Order("ordering asc").Find(&savedPlaylists)
-	// 	db.Close()
-
-	// 	for _, playlist := range savedPlaylists {
-	// 		objs = append(objs, upnpav.Container{Object: upnpav.Object{
-	// 			ID:         "saved-searches/" + strconv.Itoa(int(playlist.ID)),
-	// 			Restricted: 1,
-	// 			ParentID:   "saved-searches",
-	// 			Class:      "object.container.storageFolder",
-	// 			Title:      playlist.Name,
-	// 		}})
-	// 	}
-	// }
-
-	// if strings.HasPrefix(obj.Path, "saved-searches/") {
-	// 	id := strings.Split(obj.Path, "/")
-
-	// 	var savedPlaylist models.Playlist
-	// 	db, _ := models.GetDB()
-	// 	db.Where("id = ?", id[1]).First(&savedPlaylist)
-	// 	db.Close()
-
-	// 	var r models.RequestSceneList
-	// 	if err := json.Unmarshal([]byte(savedPlaylist.SearchParams), &r); err == nil {
-	// 		r.IsAccessible = optional.NewBool(true)
-	// 		r.IsAvailable = optional.NewBool(true)
-	// 		data := models.QueryScenesFull(r)
+	// Playlists
+	if obj.Path == "playlists" {
+		objs = me.getPlaylists()
+	}
 
-	// 		for i := range data.Scenes {
-	// 			objs = append(objs, me.sceneToContainer(data.Scenes[i], "sites/"+id[1], host))
-	// 		}
-	// 	}
-	// }
+	if strings.HasPrefix(obj.Path, "playlists/") {
+		objs = me.getPlaylistScenes(childPath(paths), host)
+	}
 
 	// Studios
 	if obj.Path == "studios" {
@@ -360,7 +332,7 @@ func (me *contentDirectoryService) handleBrowseMetadata(obj object, host string)
 	} else {
 		var scene *models.Scene
 
-		if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+		if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 			scene, err = me.repository.SceneFinder.Find(ctx, sceneID)
 			if scene != nil {
 				err = scene.LoadPrimaryFile(ctx, me.repository.FileFinder)
@@ -436,6 +408,7 @@ func getRootObjects() []interface{} {
 	objs = append(objs, makeStorageFolder("studios", "studios", rootID))
 	objs = append(objs, makeStorageFolder("movies", "movies", rootID))
 	objs = append(objs, makeStorageFolder("rating", "rating", rootID))
+	objs = append(objs, makeStorageFolder("playlists", "playlists", rootID))
 
 	return objs
 }
@@ -452,7 +425,7 @@ func getSortDirection(sceneFilter *models.SceneFilterType, sort string) models.S
 func (me *contentDirectoryService) getVideos(sceneFilter *models.SceneFilterType, parentID string, host string) []interface{} {
 	var objs []interface{}
 
-	if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 		sort := me.VideoSortOrder
 		direction := getSortDirection(sceneFilter, sort)
 		findFilter := &models.FindFilterType{
@@ -497,7 +470,7 @@ func (me *contentDirectoryService) getVideos(sceneFilter *models.SceneFilterType
 func (me *contentDirectoryService) getPageVideos(sceneFilter *models.SceneFilterType, parentID string, page int, host string) []interface{} {
 	var objs []interface{}
 
-	if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 		pager := scenePager{
 			sceneFilter: sceneFilter,
 			parentID:    parentID,
@@ -540,7 +513,7 @@ func (me *contentDirectoryService) getAllScenes(host string) []interface{} {
 func (me *contentDirectoryService) getStudios() []interface{} {
 	var objs []interface{}
 
-	if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 		studios, err := me.repository.StudioFinder.All(ctx)
 		if err != nil {
 			return err
@@ -579,7 +552,7 @@ func (me *contentDirectoryService) getStudioScenes(paths []string, host string)
 func (me *contentDirectoryService) getTags() []interface{} {
 	var objs []interface{}
 
-	if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 		tags, err := me.repository.TagFinder.All(ctx)
 		if err != nil {
 			return err
@@ -618,7 +591,7 @@ func (me *contentDirectoryService) getTagScenes(paths []string, host string) []i
 func (me *contentDirectoryService) getPerformers() []interface{} {
 	var objs []interface{}
 
-	if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 		performers, err := me.repository.PerformerFinder.All(ctx)
 		if err != nil {
 			return err
@@ -657,7 +630,7 @@ func (me *contentDirectoryService) getPerformerScenes(paths []string, host strin
 func (me *contentDirectoryService) getMovies() []interface{} {
 	var objs []interface{}
 
-	if err := txn.WithReadTxn(context.TODO(), me.txnManager, func(ctx context.Context) error {
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
 		movies, err := me.repository.MovieFinder.All(ctx)
 		if err != nil {
 			return err
@@ -759,3 +732,108 @@ func (o object) ParentID() string {
 	o.Path = path.Dir(o.Path)
 	return o.ID()
 }
+
+func (me *contentDirectoryService) getPlaylists() []interface{} {
+	var objs []interface{}
+
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
+		playlists, err := me.repository.PlaylistFinder.All(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range playlists {
+			objs = append(objs, makeStorageFolder("playlists/"+strconv.Itoa(p.ID), p.Name, "playlists"))
+		}
+
+		return nil
+	}); err != nil {
+		logger.Errorf(err.Error())
+	}
+
+	return objs
+}
+
+// getPlaylistScenes returns the scenes of a playlist in order. Scene
+// markers are listed as their scene, and images are omitted. Each scene is
+// listed once.
+func (me *contentDirectoryService) getPlaylistScenes(paths []string, host string) []interface{} {
+	id, err := strconv.Atoi(paths[0])
+	if err != nil {
+		return nil
+	}
+
+	parentID := "playlists/" + strings.Join(paths, "/")
+
+	var objs []interface{}
+
+	if err := txn.WithReadTxn(me.restrictContext(context.TODO()), me.txnManager, func(ctx context.Context) error {
+		p, err := me.repository.PlaylistFinder.Find(ctx, id)
+		if err != nil || p == nil {
+			return err
+		}
+
+		items, err := me.repository.PlaylistItemResolver.Items(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		var sceneIDs, markerIDs []int
+		for _, item := range items {
+			if item.SceneMarkerID != nil {
+				markerIDs = intslice.IntAppendUnique(markerIDs, *item.SceneMarkerID)
+			}
+		}
+
+		markerScenes := make(map[int]int)
+		if len(markerIDs) > 0 {
+			markers, err := me.repository.SceneMarkerFinder.FindMany(ctx, markerIDs)
+			if err != nil {
+				return err
+			}
+
+			for _, m := range markers {
+				// skip content hidden by a restriction
+				if m == nil {
+					continue
+				}
+
+				markerScenes[m.ID] = m.SceneID
+			}
+		}
+
+		for _, item := range items {
+			switch {
+			case item.SceneID != nil:
+				sceneIDs = intslice.IntAppendUnique(sceneIDs, *item.SceneID)
+			case item.SceneMarkerID != nil:
+				if sceneID, found := markerScenes[*item.SceneMarkerID]; found {
+					sceneIDs = intslice.IntAppendUnique(sceneIDs, sceneID)
+				}
+			}
+		}
+
+		scenes, err := me.repository.SceneFinder.FindMany(ctx, sceneIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range scenes {
+			if s == nil {
+				continue
+			}
+
+			if err := s.LoadPrimaryFile(ctx, me.repository.FileFinder); err != nil {
+				return err
+			}
+
+			objs = append(objs, sceneToContainer(s, parentID, host))
+		}
+
+		return nil
+	}); err != nil {
+		logger.Error(err.Error())
+	}
+
+	return objs
+}
@@ -73,6 +73,19 @@ type MovieFinder interface {
 	All(ctx context.Context) ([]*models.Movie, error)
 }
 
+type SceneMarkerFinder interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.SceneMarker, error)
+}
+
+type PlaylistFinder interface {
+	Find(ctx context.Context, id int) (*models.Playlist, error)
+	All(ctx context.Context) ([]*models.Playlist, error)
+}
+
+type PlaylistItemResolver interface {
+	Items(ctx context.Context, p *models.Playlist) ([]*models.PlaylistItem, error)
+}
+
 const (
 	serverField                 = "Linux/3.4 DLNADOC/1.50 UPnP/1.0 DMS/1.0"
 	rootDeviceType              = "urn:schemas-upnp-org:device:MediaServer:1"
@@ -277,6 +290,19 @@ type Server struct {
 	sceneServer        sceneServer
 	ipWhitelistManager *ipWhitelistManager
 	VideoSortOrder     string
+	// RestrictionProfileID is the id of the restriction profile applied to
+	// the content browsed and streamed, or 0 for none.
+	RestrictionProfileID int
+}
+
+// restrictContext returns ctx restricted by the restriction profile of the
+// server, if any.
+func (me *Server) restrictContext(ctx context.Context) context.Context {
+	if me.RestrictionProfileID == 0 {
+		return ctx
+	}
+
+	return models.WithRestrictionProfileIDs(ctx, []int{me.RestrictionProfileID})
 }
 
 // UPnP SOAP service.
@@ -440,7 +466,7 @@ func (me *Server) serveIcon(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var scene *models.Scene
-	err := txn.WithReadTxn(r.Context(), me.txnManager, func(ctx context.Context) error {
+	err := txn.WithReadTxn(me.restrictContext(r.Context()), me.txnManager, func(ctx context.Context) error {
 		idInt, err := strconv.Atoi(sceneId)
 		if err != nil {
 			return nil
@@ -580,7 +606,7 @@ func (me *Server) initMux(mux *http.ServeMux) {
 	mux.HandleFunc(resPath, func(w http.ResponseWriter, r *http.Request) {
 		sceneId := r.URL.Query().Get("scene")
 		var scene *models.Scene
-		err := txn.WithReadTxn(r.Context(), me.txnManager, func(ctx context.Context) error {
+		err := txn.WithReadTxn(me.restrictContext(r.Context()), me.txnManager, func(ctx context.Context) error {
 			sceneIdInt, err := strconv.Atoi(sceneId)
 			if err != nil {
 				return nil
@@ -15,12 +15,15 @@ import (
 )
 
 type Repository struct {
-	SceneFinder     SceneFinder
-	FileFinder      file.Finder
-	StudioFinder    StudioFinder
-	TagFinder       TagFinder
-	PerformerFinder PerformerFinder
-	MovieFinder     MovieFinder
+	SceneFinder          SceneFinder
+	FileFinder           file.Finder
+	StudioFinder         StudioFinder
+	TagFinder            TagFinder
+	PerformerFinder      PerformerFinder
+	MovieFinder          MovieFinder
+	SceneMarkerFinder    SceneMarkerFinder
+	PlaylistFinder       PlaylistFinder
+	PlaylistItemResolver PlaylistItemResolver
 }
 
 type Status struct {
@@ -58,6 +61,7 @@ type Config interface {
 	GetDLNAServerName() string
 	GetDLNADefaultIPWhitelist() []string
 	GetVideoSortOrder() string
+	GetDLNARestrictionProfileID() int
 }
 
 type Service struct {
@@ -165,9 +169,10 @@ func (s *Service) init() error {
 		// 		//ReadSeeker: readIcon(config.Config.Interfaces.DLNA.ServiceImage, 128),
 		// 	},
 		// },
-		StallEventSubscribe: dmsConfig.StallEventSubscribe,
-		NotifyInterval:      dmsConfig.NotifyInterval,
-		VideoSortOrder:      dmsConfig.VideoSortOrder,
+		StallEventSubscribe:  dmsConfig.StallEventSubscribe,
+		NotifyInterval:       dmsConfig.NotifyInterval,
+		VideoSortOrder:       dmsConfig.VideoSortOrder,
+		RestrictionProfileID: s.config.GetDLNARestrictionProfileID(),
 	}
 
 	return nil
@@ -2,11 +2,13 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 
 	"sync"
 	// "github.com/sasha-s/go-deadlock" // if you have deadlock issues
@@ -42,6 +44,51 @@ const (
 
 	DefaultMaxSessionAge = 60 * 60 * 1 // 1 hours
 
+	// OpenID Connect login is enabled when the issuer and client id are set.
+	OIDCIssuer        = "oidc.issuer"
+	OIDCClientID      = "oidc.client_id"
+	OIDCClientSecret  = "oidc.client_secret"
+	OIDCUsernameClaim = "oidc.username_claim"
+	OIDCGroupsClaim   = "oidc.groups_claim"
+	OIDCAdminGroups   = "oidc.admin_groups"
+	OIDCEditorGroups  = "oidc.editor_groups"
+	OIDCViewerGroups  = "oidc.viewer_groups"
+	OIDCDefaultRole   = "oidc.default_role"
+
+	DefaultOIDCUsernameClaim = "preferred_username"
+	DefaultOIDCGroupsClaim   = "groups"
+
+	// Authentication by a reverse proxy is enabled when the header is set.
+	// The header is only trusted from the trusted proxies.
+	ProxyAuthHeader         = "proxy_auth.header"
+	ProxyAuthTrustedProxies = "proxy_auth.trusted_proxies"
+
+	// Audit log events older than the retention period are deleted. They
+	// are kept forever if it is 0.
+	AuditLogRetentionDays        = "audit_log.retention_days"
+	DefaultAuditLogRetentionDays = 90
+
+	// Usernames are blocked from logging in from an IP address after the
+	// maximum number of failed attempts, for up to the maximum block time.
+	// IP addresses are blocked after more failed attempts with any username.
+	// Logins are never blocked if the maximum number of attempts is 0.
+	LoginMaxAttempts            = "login_throttle.max_attempts"
+	DefaultLoginMaxAttempts     = 5
+	LoginMaxBlockMinutes        = "login_throttle.max_block_minutes"
+	DefaultLoginMaxBlockMinutes = 60
+
+	// Requests to the GraphQL endpoint are limited to the rate, allowing
+	// bursts of up to the burst size. They are not limited if the rate is 0.
+	GraphQLRateLimit      = "graphql_rate_limit.requests_per_second"
+	GraphQLRateLimitBurst = "graphql_rate_limit.burst"
+
+	// Admins must log in with a code of an authenticator app as well as
+	// their password, and enroll when they next log in if they have not.
+	TOTPRequiredForAdmins = "totp.required_for_admins"
+	// The unix time two-factor authentication was last required for admins.
+	// The sessions of admins created before it must log in again.
+	TOTPRequiredSince = "totp.required_since"
+
 	Database = "database"
 
 	Exclude      = "exclude"
@@ -52,6 +99,12 @@ const (
 	GalleryExtensions          = "gallery_extensions"
 	CreateGalleriesFromFolders = "create_galleries_from_folders"
 
+	// SetImageDateFromMetadata and SetImageTagsFromMetadata are the config
+	// keys used to determine if the date and tags of newly scanned images are
+	// set from the embedded EXIF/XMP metadata.
+	SetImageDateFromMetadata = "set_image_date_from_metadata"
+	SetImageTagsFromMetadata = "set_image_tags_from_metadata"
+
 	// CalculateMD5 is the config key used to determine if MD5 should be calculated
 	// for video files.
 	CalculateMD5 = "calculate_md5"
@@ -213,6 +266,8 @@ const (
 	DLNADefaultIPWhitelist = "dlna.default_whitelist"
 	DLNAInterfaces         = "dlna.interfaces"
 
+	DLNARestrictionProfileID = "dlna.restriction_profile_id"
+
 	DLNAVideoSortOrder        = "dlna.video_sort_order"
 	dlnaVideoSortOrderDefault = "title"
 
@@ -635,6 +690,14 @@ func (i *Instance) GetCreateGalleriesFromFolders() bool {
 	return i.getBool(CreateGalleriesFromFolders)
 }
 
+func (i *Instance) GetSetImageDateFromMetadata() bool {
+	return i.getBool(SetImageDateFromMetadata)
+}
+
+func (i *Instance) GetSetImageTagsFromMetadata() bool {
+	return i.getBool(SetImageTagsFromMetadata)
+}
+
 func (i *Instance) GetLanguage() string {
 	ret := i.getString(Language)
 
@@ -966,6 +1029,184 @@ func (i *Instance) GetMaxSessionAge() int {
 	return ret
 }
 
+func (i *Instance) GetOIDCIssuer() string {
+	return i.getString(OIDCIssuer)
+}
+
+func (i *Instance) GetOIDCClientID() string {
+	return i.getString(OIDCClientID)
+}
+
+func (i *Instance) GetOIDCClientSecret() string {
+	return i.getString(OIDCClientSecret)
+}
+
+// GetOIDCUsernameClaim returns the claim of the ID token used as the
+// username.
+func (i *Instance) GetOIDCUsernameClaim() string {
+	if ret := i.getString(OIDCUsernameClaim); ret != "" {
+		return ret
+	}
+
+	return DefaultOIDCUsernameClaim
+}
+
+// GetOIDCGroupsClaim returns the claim of the ID token listing the groups of
+// the user.
+func (i *Instance) GetOIDCGroupsClaim() string {
+	if ret := i.getString(OIDCGroupsClaim); ret != "" {
+		return ret
+	}
+
+	return DefaultOIDCGroupsClaim
+}
+
+// GetOIDCRoleGroups returns the groups mapped to each role.
+func (i *Instance) GetOIDCRoleGroups() map[models.UserRole][]string {
+	return map[models.UserRole][]string{
+		models.UserRoleAdmin:  i.getStringSlice(OIDCAdminGroups),
+		models.UserRoleEditor: i.getStringSlice(OIDCEditorGroups),
+		models.UserRoleViewer: i.getStringSlice(OIDCViewerGroups),
+	}
+}
+
+// GetOIDCDefaultRole returns the role of users not in any mapped group. Such
+// users may only log in if they already exist when it is empty.
+func (i *Instance) GetOIDCDefaultRole() models.UserRole {
+	return models.UserRole(strings.ToUpper(i.getString(OIDCDefaultRole)))
+}
+
+// GetProxyAuthHeader returns the header containing the username of users
+// authenticated by a reverse proxy, such as Remote-User.
+func (i *Instance) GetProxyAuthHeader() string {
+	return i.getString(ProxyAuthHeader)
+}
+
+// GetProxyAuthTrustedProxies returns the IP addresses and CIDR ranges of the
+// reverse proxies trusted to set the proxy authentication and X-Forwarded-For
+// headers.
+func (i *Instance) GetProxyAuthTrustedProxies() []string {
+	return i.getStringSlice(ProxyAuthTrustedProxies)
+}
+
+// GetAuditLogRetentionDays returns the number of days audit log events are
+// kept for, or 0 if they are kept forever.
+func (i *Instance) GetAuditLogRetentionDays() int {
+	i.RLock()
+	defer i.RUnlock()
+
+	ret := DefaultAuditLogRetentionDays
+	v := i.viper(AuditLogRetentionDays)
+	if v.IsSet(AuditLogRetentionDays) {
+		ret = v.GetInt(AuditLogRetentionDays)
+	}
+
+	if ret < 0 {
+		ret = 0
+	}
+
+	return ret
+}
+
+// GetLoginMaxAttempts returns the number of failed logins after which a
+// username is blocked from an IP address, or 0 if logins are never blocked.
+func (i *Instance) GetLoginMaxAttempts() int {
+	i.RLock()
+	defer i.RUnlock()
+
+	ret := DefaultLoginMaxAttempts
+	v := i.viper(LoginMaxAttempts)
+	if v.IsSet(LoginMaxAttempts) {
+		ret = v.GetInt(LoginMaxAttempts)
+	}
+
+	if ret < 0 {
+		ret = 0
+	}
+
+	return ret
+}
+
+// GetLoginMaxBlockMinutes returns the maximum number of minutes an IP
+// address or username is blocked from logging in for.
+func (i *Instance) GetLoginMaxBlockMinutes() int {
+	i.RLock()
+	defer i.RUnlock()
+
+	ret := DefaultLoginMaxBlockMinutes
+	v := i.viper(LoginMaxBlockMinutes)
+	if v.IsSet(LoginMaxBlockMinutes) {
+		ret = v.GetInt(LoginMaxBlockMinutes)
+	}
+
+	if ret < 1 {
+		ret = 1
+	}
+
+	return ret
+}
+
+// GetLoginMaxBlockDuration returns the maximum time an IP address or
+// username is blocked from logging in for.
+func (i *Instance) GetLoginMaxBlockDuration() time.Duration {
+	return time.Duration(i.GetLoginMaxBlockMinutes()) * time.Minute
+}
+
+// GetTOTPRequiredForAdmins returns true if admins must use two-factor
+// authentication to log in with a password, and must enroll before using
+// stash however they authenticate.
+func (i *Instance) GetTOTPRequiredForAdmins() bool {
+	return i.getBool(TOTPRequiredForAdmins)
+}
+
+// GetTOTPRequiredSince returns the time two-factor authentication was last
+// required for admins. It returns the zero time if it is not required, or
+// was required by editing the config file.
+func (i *Instance) GetTOTPRequiredSince() time.Time {
+	if !i.GetTOTPRequiredForAdmins() {
+		return time.Time{}
+	}
+
+	since := i.getInt(TOTPRequiredSince)
+	if since == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(since), 0)
+}
+
+// GetGraphQLRateLimit returns the maximum number of requests per second to
+// the GraphQL endpoint, or 0 if they are not limited.
+func (i *Instance) GetGraphQLRateLimit() float64 {
+	ret := i.getFloat64(GraphQLRateLimit)
+	if ret < 0 {
+		ret = 0
+	}
+
+	return ret
+}
+
+// GetGraphQLRateLimitBurst returns the number of requests to the GraphQL
+// endpoint which may be made at once, above the rate limit. It defaults to
+// the rate limit.
+func (i *Instance) GetGraphQLRateLimitBurst() int {
+	i.RLock()
+	v := i.viper(GraphQLRateLimitBurst)
+	isSet := v.IsSet(GraphQLRateLimitBurst)
+	ret := v.GetInt(GraphQLRateLimitBurst)
+	i.RUnlock()
+
+	if !isSet || ret < 1 {
+		ret = int(math.Ceil(i.GetGraphQLRateLimit()))
+	}
+
+	if ret < 1 {
+		ret = 1
+	}
+
+	return ret
+}
+
 // GetCustomServedFolders gets the map of custom paths to their applicable
 // filesystem locations
 func (i *Instance) GetCustomServedFolders() URLMap {
@@ -1380,6 +1621,12 @@ func (i *Instance) GetDLNAInterfaces() []string {
 	return i.getStringSlice(DLNAInterfaces)
 }
 
+// GetDLNARestrictionProfileID returns the id of the restriction profile
+// applied to DLNA clients, or 0 if they are not restricted.
+func (i *Instance) GetDLNARestrictionProfileID() int {
+	return i.getInt(DLNARestrictionProfileID)
+}
+
 // GetVideoSortOrder returns the sort order to display videos. If
 // empty, videos will be sorted by titles.
 func (i *Instance) GetVideoSortOrder() string {
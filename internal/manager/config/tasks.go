@@ -21,6 +21,8 @@ type ScanMetadataOptions struct {
 	ScanGenerateThumbnails bool `json:"scanGenerateThumbnails"`
 	// Generate image thumbnails during scan
 	ScanGenerateClipPreviews bool `json:"scanGenerateClipPreviews"`
+	// Generate image phashes during scan
+	ScanGenerateImagePhashes bool `json:"scanGenerateImagePhashes"`
 }
 
 type AutoTagMetadataOptions struct {
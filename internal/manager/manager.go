@@ -17,6 +17,7 @@ import (
 	"github.com/stashapp/stash/internal/dlna"
 	"github.com/stashapp/stash/internal/log"
 	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/audit"
 	"github.com/stashapp/stash/pkg/ffmpeg"
 	"github.com/stashapp/stash/pkg/file"
 	file_image "github.com/stashapp/stash/pkg/file/image"
@@ -26,12 +27,15 @@ import (
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/job"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/models/paths"
 	"github.com/stashapp/stash/pkg/plugin"
 	"github.com/stashapp/stash/pkg/scene"
 	"github.com/stashapp/stash/pkg/scraper"
 	"github.com/stashapp/stash/pkg/session"
+	"github.com/stashapp/stash/pkg/sharelink"
 	"github.com/stashapp/stash/pkg/sqlite"
+	"github.com/stashapp/stash/pkg/user"
 	"github.com/stashapp/stash/pkg/utils"
 	"github.com/stashapp/stash/ui"
 
@@ -130,9 +134,12 @@ type Manager struct {
 	Database   *sqlite.Database
 	Repository Repository
 
-	SceneService   SceneService
-	ImageService   ImageService
-	GalleryService GalleryService
+	SceneService     SceneService
+	ImageService     ImageService
+	GalleryService   GalleryService
+	UserService      *user.Service
+	AuditService     *audit.Service
+	ShareLinkService *sharelink.Service
 
 	Scanner *file.Scanner
 	Cleaner *file.Cleaner
@@ -211,6 +218,28 @@ func initialize() error {
 		Folder:       db.Folder,
 	}
 
+	instance.UserService = &user.Service{
+		TxnManager: instance.Repository,
+		Repository: instance.Repository.User,
+		APIKeys:    instance.Repository.APIKey,
+		Config:     cfg,
+		Database:   db,
+	}
+
+	instance.AuditService = &audit.Service{
+		TxnManager: instance.Repository,
+		Repository: instance.Repository.AuditEvent,
+		Config:     cfg,
+		Database:   db,
+	}
+
+	instance.ShareLinkService = &sharelink.Service{
+		TxnManager: instance.Repository,
+		Repository: instance.Repository.ShareLink,
+		Config:     cfg,
+		Database:   db,
+	}
+
 	instance.JobManager = initJobManager()
 
 	sceneServer := SceneServer{
@@ -219,12 +248,15 @@ func initialize() error {
 	}
 
 	instance.DLNAService = dlna.NewService(instance.Repository, dlna.Repository{
-		SceneFinder:     instance.Repository.Scene,
-		FileFinder:      instance.Repository.File,
-		StudioFinder:    instance.Repository.Studio,
-		TagFinder:       instance.Repository.Tag,
-		PerformerFinder: instance.Repository.Performer,
-		MovieFinder:     instance.Repository.Movie,
+		SceneFinder:          instance.Repository.Scene,
+		FileFinder:           instance.Repository.File,
+		StudioFinder:         instance.Repository.Studio,
+		TagFinder:            instance.Repository.Tag,
+		PerformerFinder:      instance.Repository.Performer,
+		MovieFinder:          instance.Repository.Movie,
+		SceneMarkerFinder:    instance.Repository.SceneMarker,
+		PlaylistFinder:       instance.Repository.Playlist,
+		PlaylistItemResolver: instance.Repository.PlaylistItemResolver(),
 	}, instance.Config, &sceneServer)
 
 	if !cfg.IsNewSystem() {
@@ -256,7 +288,7 @@ func initialize() error {
 
 		// create temporary session store - this will be re-initialised
 		// after config is complete
-		instance.SessionStore = session.NewStore(cfg)
+		instance.SessionStore = session.NewStore(cfg, instance.UserService)
 
 		logger.Warnf("config file %snot found. Assuming new system...", cfgFile)
 	}
@@ -335,6 +367,12 @@ func makeCleaner(db *sqlite.Database, pluginCache *plugin.Cache) *file.Cleaner {
 func initJobManager() *job.Manager {
 	ret := job.NewManager()
 
+	// jobs act on the whole library, regardless of the restriction of the
+	// user who started them
+	ret.JobContext = func(ctx context.Context) context.Context {
+		return models.WithRestrictionProfileIDs(ctx, nil)
+	}
+
 	// desktop notifications
 	ctx := context.Background()
 	c := ret.Subscribe(context.Background())
@@ -448,7 +486,7 @@ func (s *Manager) PostInit(ctx context.Context) error {
 
 	*s.Paths = paths.NewPaths(s.Config.GetGeneratedPath(), s.Config.GetBlobsPath())
 	s.RefreshConfig()
-	s.SessionStore = session.NewStore(s.Config)
+	s.SessionStore = session.NewStore(s.Config, s.UserService)
 	s.PluginCache.RegisterSessionStore(s.SessionStore)
 
 	if err := s.PluginCache.LoadPlugins(); err != nil {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/findreplace"
 	"github.com/stashapp/stash/pkg/fsutil"
 	"github.com/stashapp/stash/pkg/job"
 	"github.com/stashapp/stash/pkg/logger"
@@ -313,6 +314,77 @@ func (s *Manager) MigrateHash(ctx context.Context) int {
 	return s.JobManager.Add(ctx, "Migrating scene hashes...", j)
 }
 
+// BulkFindReplace replaces the matches of the pattern in the field of the
+// objects with the given ids. The changes are made in a single transaction,
+// and are recorded in the edit history with the edit source of ctx.
+func (s *Manager) BulkFindReplace(ctx context.Context, options findreplace.Options, ids []int) int {
+	source := models.EditSourceFromContext(ctx)
+
+	j := job.MakeJobExec(func(ctx context.Context, progress *job.Progress) {
+		logger.Infof("Replacing %q in %s of %d %s objects", options.Pattern, options.Field, len(ids), options.EntityType)
+
+		if source != "" {
+			ctx = models.WithEditSource(ctx, source)
+		}
+		ctx = models.WithEditOperation(ctx, models.EditOperationBulkUpdate)
+
+		var changes []findreplace.Change
+		if err := s.Repository.WithTxn(ctx, func(ctx context.Context) error {
+			f, err := findreplace.New(s.Repository.FindReplaceRepository(), options)
+			if err != nil {
+				return err
+			}
+
+			changes, err = f.Apply(ctx, ids)
+			return err
+		}); err != nil {
+			logger.Errorf("Error replacing %q in %s of %s objects: %v", options.Pattern, options.Field, options.EntityType, err)
+			return
+		}
+
+		logger.Infof("Finished replacing: changed %d values", len(changes))
+	})
+
+	return s.JobManager.Add(ctx, "Replacing metadata...", j)
+}
+
+// ApplyTagImplications adds the tags implied by the tag implication rules
+// to all scenes, images and galleries.
+func (s *Manager) ApplyTagImplications(ctx context.Context) int {
+	j := job.MakeJobExec(func(ctx context.Context, progress *job.Progress) {
+		logger.Info("Applying tag implications")
+
+		progress.SetTotal(len(models.AllTagImplicationObjectType))
+
+		for _, t := range models.AllTagImplicationObjectType {
+			if job.IsCancelled(ctx) {
+				logger.Info("Stopping due to user request")
+				return
+			}
+
+			var added []*models.ImpliedTag
+			progress.ExecuteTask(fmt.Sprintf("Applying tag implications to %s objects", t), func() {
+				if err := s.Repository.WithTxn(ctx, func(ctx context.Context) error {
+					var err error
+					added, err = s.Repository.TagImplication.Apply(ctx, t, nil)
+					return err
+				}); err != nil {
+					logger.Errorf("Error applying tag implications to %s objects: %v", t, err)
+				}
+			})
+
+			objectIDs, _ := models.GroupImpliedTags(added)
+			logger.Infof("Added %d implied tags to %d %s objects", len(added), len(objectIDs), t)
+
+			progress.Increment()
+		}
+
+		logger.Info("Finished applying tag implications")
+	})
+
+	return s.JobManager.Add(ctx, "Applying tag implications...", j)
+}
+
 // If neither performer_ids nor performer_names are set, tag all performers
 type StashBoxBatchPerformerTagInput struct {
 	// Stash endpoint to use for the performer tagging
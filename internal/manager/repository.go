@@ -4,9 +4,12 @@ import (
 	"context"
 
 	"github.com/stashapp/stash/pkg/file"
+	"github.com/stashapp/stash/pkg/filterquery"
+	"github.com/stashapp/stash/pkg/findreplace"
 	"github.com/stashapp/stash/pkg/gallery"
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/playlist"
 	"github.com/stashapp/stash/pkg/scene"
 	"github.com/stashapp/stash/pkg/sqlite"
 	"github.com/stashapp/stash/pkg/txn"
@@ -46,19 +49,28 @@ type FolderReaderWriter interface {
 type Repository struct {
 	models.TxnManager
 
-	File           FileReaderWriter
-	Folder         FolderReaderWriter
-	Gallery        GalleryReaderWriter
-	GalleryChapter models.GalleryChapterReaderWriter
-	Image          ImageReaderWriter
-	Movie          models.MovieReaderWriter
-	Performer      models.PerformerReaderWriter
-	Scene          SceneReaderWriter
-	SceneMarker    models.SceneMarkerReaderWriter
-	ScrapedItem    models.ScrapedItemReaderWriter
-	Studio         models.StudioReaderWriter
-	Tag            models.TagReaderWriter
-	SavedFilter    models.SavedFilterReaderWriter
+	File               FileReaderWriter
+	Folder             FolderReaderWriter
+	Gallery            GalleryReaderWriter
+	GalleryChapter     models.GalleryChapterReaderWriter
+	Image              ImageReaderWriter
+	Movie              models.MovieReaderWriter
+	Performer          models.PerformerReaderWriter
+	Scene              SceneReaderWriter
+	SceneMarker        models.SceneMarkerReaderWriter
+	ScrapedItem        models.ScrapedItemReaderWriter
+	Studio             models.StudioReaderWriter
+	Tag                models.TagReaderWriter
+	SavedFilter        models.SavedFilterReaderWriter
+	Playlist           models.PlaylistReaderWriter
+	TagImplication     models.TagImplicationReaderWriter
+	User               models.UserReaderWriter
+	APIKey             models.APIKeyReaderWriter
+	RestrictionProfile models.RestrictionProfileReaderWriter
+	AuditEvent         models.AuditEventReaderWriter
+	ShareLink          models.ShareLinkReaderWriter
+	Edit               models.EditReaderWriter
+	Statistics         models.StatisticsReader
 }
 
 func (r *Repository) WithTxn(ctx context.Context, fn txn.TxnFunc) error {
@@ -73,24 +85,64 @@ func (r *Repository) WithDB(ctx context.Context, fn txn.TxnFunc) error {
 	return txn.WithDatabase(ctx, r, fn)
 }
 
+// PlaylistItemResolver returns a resolver for the items of playlists.
+func (r *Repository) PlaylistItemResolver() playlist.ItemResolver {
+	return playlist.ItemResolver{
+		Playlist:    r.Playlist,
+		SavedFilter: r.SavedFilter,
+		Scene:       r.Scene,
+		SceneMarker: r.SceneMarker,
+		Image:       r.Image,
+		Filter: &filterquery.Resolver{
+			Performer: r.Performer,
+			Studio:    r.Studio,
+			Tag:       r.Tag,
+			Movie:     r.Movie,
+		},
+	}
+}
+
+// FindReplaceRepository returns the repository used to find and replace
+// the fields of objects.
+func (r *Repository) FindReplaceRepository() findreplace.Repository {
+	return findreplace.Repository{
+		Scene:     r.Scene,
+		Image:     r.Image,
+		Gallery:   r.Gallery,
+		Performer: r.Performer,
+		Studio:    r.Studio,
+		Movie:     r.Movie,
+		Tag:       r.Tag,
+	}
+}
+
 func sqliteRepository(d *sqlite.Database) Repository {
 	txnRepo := d.TxnRepository()
 
 	return Repository{
-		TxnManager:     txnRepo,
-		File:           d.File,
-		Folder:         d.Folder,
-		Gallery:        d.Gallery,
-		GalleryChapter: txnRepo.GalleryChapter,
-		Image:          d.Image,
-		Movie:          txnRepo.Movie,
-		Performer:      txnRepo.Performer,
-		Scene:          d.Scene,
-		SceneMarker:    txnRepo.SceneMarker,
-		ScrapedItem:    txnRepo.ScrapedItem,
-		Studio:         txnRepo.Studio,
-		Tag:            txnRepo.Tag,
-		SavedFilter:    txnRepo.SavedFilter,
+		TxnManager:         txnRepo,
+		File:               d.File,
+		Folder:             d.Folder,
+		Gallery:            d.Gallery,
+		GalleryChapter:     txnRepo.GalleryChapter,
+		Image:              d.Image,
+		Movie:              txnRepo.Movie,
+		Performer:          txnRepo.Performer,
+		Scene:              d.Scene,
+		SceneMarker:        txnRepo.SceneMarker,
+		ScrapedItem:        txnRepo.ScrapedItem,
+		Studio:             txnRepo.Studio,
+		Tag:                txnRepo.Tag,
+		SavedFilter:        txnRepo.SavedFilter,
+		Playlist:           txnRepo.Playlist,
+		TagImplication:     txnRepo.TagImplication,
+		User:               txnRepo.User,
+		APIKey:             txnRepo.APIKey,
+		RestrictionProfile: txnRepo.RestrictionProfile,
+		AuditEvent:         txnRepo.AuditEvent,
+		ShareLink:          txnRepo.ShareLink,
+		Edit:               txnRepo.Edit,
+		Statistics:         txnRepo.Statistics,
 	}
 }
 
@@ -109,6 +161,7 @@ type ImageService interface {
 type GalleryService interface {
 	AddImages(ctx context.Context, g *models.Gallery, toAdd ...int) error
 	RemoveImages(ctx context.Context, g *models.Gallery, toRemove ...int) error
+	ReorderImages(ctx context.Context, g *models.Gallery, imageIDs []int) error
 
 	Destroy(ctx context.Context, i *models.Gallery, fileDeleter *image.FileDeleter, deleteGenerated, deleteFile bool) ([]*models.Image, error)
 
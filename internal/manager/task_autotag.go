@@ -27,6 +27,7 @@ type autoTagJob struct {
 
 func (j *autoTagJob) Execute(ctx context.Context, progress *job.Progress) {
 	begin := time.Now()
+	ctx = models.WithEditSource(ctx, models.EditSourceAutoTag)
 
 	input := j.input
 	if j.isFileBasedAutoTag(input) {
@@ -681,6 +681,16 @@ func exportImage(ctx context.Context, wg *sync.WaitGroup, jobChan <-chan *models
 
 		newImageJSON.Galleries = gallery.GetRefs(imageGalleries)
 
+		galleryPositions, err := repo.Image.GetGalleryPositions(ctx, s.ID)
+		if err != nil {
+			logger.Errorf("[images] <%s> error getting image gallery positions: %s", imageHash, err.Error())
+			continue
+		}
+
+		for i, g := range imageGalleries {
+			newImageJSON.Galleries[i].Position = galleryPositions[g.ID]
+		}
+
 		performers, err := performerReader.FindByImageID(ctx, s.ID)
 		if err != nil {
 			logger.Errorf("[images] <%s> error getting image performer names: %s", imageHash, err.Error())
@@ -7,6 +7,7 @@ import (
 
 	"github.com/remeh/sizedwaitgroup"
 	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/file"
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/job"
 	"github.com/stashapp/stash/pkg/logger"
@@ -31,6 +32,7 @@ type GenerateMetadataInput struct {
 	Phashes                   bool `json:"phashes"`
 	InteractiveHeatmapsSpeeds bool `json:"interactiveHeatmapsSpeeds"`
 	ClipPreviews              bool `json:"clipPreviews"`
+	ImagePhashes              bool `json:"imagePhashes"`
 	// scene ids to generate for
 	SceneIDs []string `json:"sceneIDs"`
 	// marker ids to generate for
@@ -72,6 +74,7 @@ type totalsGenerate struct {
 	phashes                  int64
 	interactiveHeatmapSpeeds int64
 	clipPreviews             int64
+	imagePhashes             int64
 
 	tasks int
 }
@@ -173,6 +176,9 @@ func (j *GenerateJob) Execute(ctx context.Context, progress *job.Progress) {
 		if j.input.ClipPreviews {
 			logMsg += fmt.Sprintf(" %d Image Clip Previews", totals.clipPreviews)
 		}
+		if j.input.ImagePhashes {
+			logMsg += fmt.Sprintf(" %d image phashes", totals.imagePhashes)
+		}
 		if logMsg == "Generating" {
 			logMsg = "Nothing selected to generate"
 		}
@@ -261,7 +267,7 @@ func (j *GenerateJob) queueTasks(ctx context.Context, g *generate.Generator, que
 	}
 
 	*findFilter.Page = 1
-	for more := j.input.ClipPreviews; more; {
+	for more := j.input.ClipPreviews || j.input.ImagePhashes; more; {
 		if job.IsCancelled(ctx) {
 			return totals
 		}
@@ -474,14 +480,49 @@ func (j *GenerateJob) queueMarkerJob(g *generate.Generator, marker *models.Scene
 }
 
 func (j *GenerateJob) queueImageJob(g *generate.Generator, image *models.Image, queue chan<- Task, totals *totalsGenerate) {
-	task := &GenerateClipPreviewTask{
-		Image:     *image,
-		Overwrite: j.overwrite,
+	if j.input.ClipPreviews {
+		task := &GenerateClipPreviewTask{
+			Image:     *image,
+			Overwrite: j.overwrite,
+		}
+
+		if task.required() {
+			totals.clipPreviews++
+			totals.tasks++
+			queue <- task
+		}
 	}
 
-	if task.required() {
-		totals.clipPreviews++
-		totals.tasks++
-		queue <- task
+	if j.input.ImagePhashes {
+		// generate for all image files; video clips are hashed like scenes
+		for _, f := range image.Files.List() {
+			var task Task
+			var required bool
+			switch f := f.(type) {
+			case *file.ImageFile:
+				t := &GenerateImagePhashTask{
+					File:        f,
+					Overwrite:   j.overwrite,
+					txnManager:  j.txnManager,
+					fileUpdater: j.txnManager.File,
+				}
+				task, required = t, t.required()
+			case *file.VideoFile:
+				t := &GeneratePhashTask{
+					File:                f,
+					fileNamingAlgorithm: j.fileNamingAlgo,
+					txnManager:          j.txnManager,
+					fileUpdater:         j.txnManager.File,
+					Overwrite:           j.overwrite,
+				}
+				task, required = t, t.required()
+			}
+
+			if required {
+				totals.imagePhashes++
+				totals.tasks++
+				queue <- task
+			}
+		}
 	}
 }
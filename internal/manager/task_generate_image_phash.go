@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/file"
+	"github.com/stashapp/stash/pkg/hash/imagephash"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+type GenerateImagePhashTask struct {
+	File        *file.ImageFile
+	Overwrite   bool
+	txnManager  txn.Manager
+	fileUpdater file.Updater
+}
+
+func (t *GenerateImagePhashTask) GetDescription() string {
+	return fmt.Sprintf("Generating phash for %s", t.File.Path)
+}
+
+func (t *GenerateImagePhashTask) Start(ctx context.Context) {
+	if !t.required() {
+		return
+	}
+
+	hash, err := imagephash.Generate(&file.OsFS{}, t.File)
+	if err != nil {
+		logger.Errorf("error generating phash: %s", err.Error())
+		return
+	}
+
+	if err := txn.WithTxn(ctx, t.txnManager, func(ctx context.Context) error {
+		qb := t.fileUpdater
+		hashValue := int64(*hash)
+		t.File.Fingerprints = t.File.Fingerprints.AppendUnique(file.Fingerprint{
+			Type:        file.FingerprintTypePhash,
+			Fingerprint: hashValue,
+		})
+
+		return qb.Update(ctx, t.File)
+	}); err != nil && ctx.Err() == nil {
+		logger.Errorf("Error setting phash: %v", err)
+	}
+}
+
+func (t *GenerateImagePhashTask) required() bool {
+	if t.Overwrite {
+		return true
+	}
+
+	return t.File.Fingerprints.Get(file.FingerprintTypePhash) == nil
+}
@@ -314,6 +314,14 @@ func (c *scanConfig) GetCreateGalleriesFromFolders() bool {
 	return instance.Config.GetCreateGalleriesFromFolders()
 }
 
+func (c *scanConfig) GetSetImageDateFromMetadata() bool {
+	return instance.Config.GetSetImageDateFromMetadata()
+}
+
+func (c *scanConfig) GetSetImageTagsFromMetadata() bool {
+	return instance.Config.GetSetImageTagsFromMetadata()
+}
+
 func getScanHandlers(options ScanMetadataInput, taskQueue *job.TaskQueue, progress *job.Progress) []file.Handler {
 	db := instance.Database
 	pluginCache := instance.PluginCache
@@ -324,6 +332,7 @@ func getScanHandlers(options ScanMetadataInput, taskQueue *job.TaskQueue, progre
 			Handler: &image.ScanHandler{
 				CreatorUpdater: db.Image,
 				GalleryFinder:  db.Gallery,
+				TagFinder:      db.Tag,
 				ScanGenerator: &imageGenerators{
 					input:     options,
 					taskQueue: taskQueue,
@@ -412,6 +421,41 @@ func (g *imageGenerators) Generate(ctx context.Context, i *models.Image, f file.
 		}
 	}
 
+	if t.ScanGenerateImagePhashes {
+		var phashTask Task
+		switch f := f.(type) {
+		case *file.ImageFile:
+			phashTask = &GenerateImagePhashTask{
+				File:        f,
+				Overwrite:   overwrite,
+				txnManager:  instance.Database,
+				fileUpdater: instance.Database.File,
+			}
+		case *file.VideoFile:
+			phashTask = &GeneratePhashTask{
+				File:                f,
+				fileNamingAlgorithm: config.GetVideoFileNamingAlgorithm(),
+				txnManager:          instance.Database,
+				fileUpdater:         instance.Database.File,
+				Overwrite:           overwrite,
+			}
+		}
+
+		if phashTask != nil {
+			progress.AddTotal(1)
+			phashFn := func(ctx context.Context) {
+				phashTask.Start(ctx)
+				progress.Increment()
+			}
+
+			if sequentialScanning {
+				phashFn(ctx)
+			} else {
+				g.taskQueue.Add(fmt.Sprintf("Generating phash for %s", path), phashFn)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -93,6 +93,10 @@ func (t *StashBoxPerformerTagTask) stashBoxPerformerTag(ctx context.Context) {
 						if err != nil {
 							return err
 						}
+
+						if err := t.addSecondaryImages(ctx, r.Performer, t.performer.ID, performer.Images[1:]); err != nil {
+							return err
+						}
 					} else {
 						logger.Warnf("Failed to read performer image: %v", err)
 					}
@@ -167,6 +171,11 @@ func (t *StashBoxPerformerTagTask) stashBoxPerformerTag(ctx context.Context) {
 						return imageErr
 					}
 					err = r.Performer.UpdateImage(ctx, newPerformer.ID, image)
+					if err != nil {
+						return err
+					}
+
+					err = t.addSecondaryImages(ctx, r.Performer, newPerformer.ID, performer.Images[1:])
 				}
 				return err
 			})
@@ -187,6 +196,24 @@ func (t *StashBoxPerformerTagTask) stashBoxPerformerTag(ctx context.Context) {
 	}
 }
 
+// addSecondaryImages adds the remaining stash-box images to the performer
+// as non-primary images. Images that cannot be read are skipped.
+func (t *StashBoxPerformerTagTask) addSecondaryImages(ctx context.Context, w models.PerformerWriter, performerID int, urls []string) error {
+	for _, url := range urls {
+		image, err := utils.ReadImageFromURL(ctx, url)
+		if err != nil {
+			logger.Warnf("Failed to read performer image %s: %v", url, err)
+			continue
+		}
+
+		if _, err := w.AddImage(ctx, performerID, image, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (t *StashBoxPerformerTagTask) getPartial(performer *models.ScrapedPerformer, excluded map[string]bool) models.PerformerPartial {
 	partial := models.NewPerformerPartial()
 
@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxStringLength is the maximum length of string arguments in the
+	// summary. Longer values, such as images, are truncated.
+	maxStringLength = 100
+	// maxListLength is the maximum number of items of list arguments in the
+	// summary.
+	maxListLength = 20
+
+	redacted = "[redacted]"
+)
+
+// secretArguments are the names, or the suffixes of the names, of arguments
+// which are redacted from the summary.
+var secretArguments = []string{
+	"password",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	// two-factor authentication and recovery codes
+	"code",
+}
+
+func isSecretArgument(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range secretArguments {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SummariseArguments returns a copy of the arguments of a mutation to record
+// in the audit log. Secrets are redacted, and long strings and lists are
+// truncated.
+func SummariseArguments(args map[string]interface{}) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+
+	ret := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if isSecretArgument(k) {
+			if v != nil {
+				ret[k] = redacted
+			} else {
+				ret[k] = nil
+			}
+			continue
+		}
+
+		ret[k] = summariseValue(v)
+	}
+
+	return ret
+}
+
+func summariseValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, int, int64, float64, json.Number:
+		return v
+	case string:
+		if len(v) <= maxStringLength {
+			return v
+		}
+
+		// don't split multi-byte characters
+		i := maxStringLength
+		for i > 0 && !utf8.RuneStart(v[i]) {
+			i--
+		}
+		return v[:i] + "..."
+	case map[string]interface{}:
+		return SummariseArguments(v)
+	case []interface{}:
+		n := len(v)
+		if n > maxListLength {
+			n = maxListLength
+		}
+
+		ret := make([]interface{}, n, n+1)
+		for i := range ret {
+			ret[i] = summariseValue(v[i])
+		}
+
+		if len(v) > n {
+			ret = append(ret, fmt.Sprintf("... %d more", len(v)-n))
+		}
+
+		return ret
+	default:
+		// values such as uploads are not recorded
+		return fmt.Sprintf("[%T]", v)
+	}
+}
+
+// TargetIDs returns the ids of the objects a mutation acts on, from its id
+// and ids arguments, and those of its input objects.
+func TargetIDs(args map[string]interface{}) []string {
+	var ret []string
+	seen := make(map[string]bool)
+	add := func(v interface{}) {
+		id := fmt.Sprint(v)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ret = append(ret, id)
+		}
+	}
+
+	addIDs := func(args map[string]interface{}) {
+		if v, ok := args["id"]; ok && v != nil {
+			add(v)
+		}
+
+		if v, ok := args["ids"].([]interface{}); ok {
+			for _, id := range v {
+				add(id)
+			}
+		}
+	}
+
+	addIDs(args)
+
+	// visit the inputs in order, so that the ids are in a stable order
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if input, ok := args[k].(map[string]interface{}); ok {
+			addIDs(input)
+		}
+	}
+
+	return ret
+}
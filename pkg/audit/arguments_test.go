@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummariseArguments(t *testing.T) {
+	longString := strings.Repeat("a", maxStringLength-1) + "é"
+	longList := make([]interface{}, maxListLength+2)
+	for i := range longList {
+		longList[i] = json.Number("1")
+	}
+
+	got := SummariseArguments(map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":              "12",
+			"title":           "title",
+			"rating100":       json.Number("80"),
+			"organized":       true,
+			"password":        "hunter2",
+			"currentPassword": "hunter2",
+			"api_key":         "key",
+			"cover_image":     longString,
+			"tag_ids":         longList,
+		},
+		"oidcClientSecret": nil,
+		"code":             "123456",
+	})
+
+	want := map[string]interface{}{
+		"input": map[string]interface{}{
+			"id":              "12",
+			"title":           "title",
+			"rating100":       json.Number("80"),
+			"organized":       true,
+			"password":        redacted,
+			"currentPassword": redacted,
+			"api_key":         redacted,
+			// multi-byte characters are not split
+			"cover_image": strings.Repeat("a", maxStringLength-1) + "...",
+			"tag_ids":     append(longList[:maxListLength:maxListLength], "... 2 more"),
+		},
+		"oidcClientSecret": nil,
+		"code":             redacted,
+	}
+
+	assert.Equal(t, want, got)
+	assert.Nil(t, SummariseArguments(nil))
+}
+
+func TestTargetIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want []string
+	}{
+		{
+			"id",
+			map[string]interface{}{"id": "1"},
+			[]string{"1"},
+		},
+		{
+			"ids",
+			map[string]interface{}{"ids": []interface{}{"1", "2"}},
+			[]string{"1", "2"},
+		},
+		{
+			"input",
+			map[string]interface{}{
+				"input": map[string]interface{}{"id": "3", "studio_id": "4"},
+			},
+			[]string{"3"},
+		},
+		{
+			"inputs in order without duplicates",
+			map[string]interface{}{
+				"b": map[string]interface{}{"ids": []interface{}{"5", "6"}},
+				"a": map[string]interface{}{"id": json.Number("5")},
+			},
+			[]string{"5", "6"},
+		},
+		{
+			"none",
+			map[string]interface{}{"input": map[string]interface{}{"title": "new"}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TargetIDs(tt.args))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Package audit records authentication events and GraphQL mutations in the
+// audit log.
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+// pruneInterval is the minimum interval between deleting the events older
+// than the retention period.
+const pruneInterval = time.Hour
+
+type Config interface {
+	// GetAuditLogRetentionDays returns the number of days events are kept
+	// for, or 0 if they are kept forever.
+	GetAuditLogRetentionDays() int
+}
+
+type Database interface {
+	Ready() error
+}
+
+// Service records events in the audit log. Events older than the configured
+// retention period are deleted as new events are recorded.
+type Service struct {
+	TxnManager txn.Manager
+	Repository models.AuditEventReaderWriter
+	Config     Config
+	Database   Database
+
+	mutex      sync.Mutex
+	lastPruned time.Time
+}
+
+// Record adds the event to the audit log, setting its creation time. It
+// opens its own transaction. Errors are logged rather than returned, so that
+// failing to record an event does not fail the request. Events are not
+// recorded while the database needs to be migrated.
+func (s *Service) Record(ctx context.Context, e models.AuditEvent) {
+	if s.Database.Ready() != nil {
+		return
+	}
+
+	e.CreatedAt = time.Now()
+	pruneBefore := s.pruneBefore(e.CreatedAt)
+
+	if err := txn.WithTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		if err := s.Repository.Create(ctx, &e); err != nil {
+			return err
+		}
+
+		if pruneBefore == nil {
+			return nil
+		}
+
+		n, err := s.Repository.DestroyBefore(ctx, *pruneBefore)
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			logger.Debugf("Deleted %d audit events older than %s", n, pruneBefore.Format(time.RFC3339))
+		}
+
+		return nil
+	}); err != nil {
+		logger.Errorf("Error recording %s audit event: %v", e.Action, err)
+	}
+}
+
+// pruneBefore returns the time before which events should be deleted, or
+// nil if they should not be deleted now.
+func (s *Service) pruneBefore(now time.Time) *time.Time {
+	days := s.Config.GetAuditLogRetentionDays()
+	if days <= 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.lastPruned) < pruneInterval {
+		return nil
+	}
+
+	s.lastPruned = now
+	ret := now.AddDate(0, 0, -days)
+	return &ret
+}
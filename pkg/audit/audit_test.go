@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+type config struct {
+	retentionDays int
+}
+
+func (c config) GetAuditLogRetentionDays() int {
+	return c.retentionDays
+}
+
+type database struct {
+	err error
+}
+
+func (d database) Ready() error {
+	return d.err
+}
+
+func newTestService(retentionDays int, db database) (*Service, *mocks.AuditEventReaderWriter) {
+	repo := mocks.NewTxnRepository()
+	auditEventReaderWriter := repo.AuditEvent.(*mocks.AuditEventReaderWriter)
+
+	return &Service{
+		TxnManager: repo,
+		Repository: auditEventReaderWriter,
+		Config:     config{retentionDays: retentionDays},
+		Database:   db,
+	}, auditEventReaderWriter
+}
+
+func TestService_Record(t *testing.T) {
+	ctx := context.Background()
+	isLogin := mock.MatchedBy(func(e *models.AuditEvent) bool {
+		return e.Action == models.AuditActionLogin && e.Username == "alice" && !e.CreatedAt.IsZero()
+	})
+	event := models.AuditEvent{Action: models.AuditActionLogin, Username: "alice"}
+
+	t.Run("prunes at most once per interval", func(t *testing.T) {
+		s, r := newTestService(30, database{})
+		r.On("Create", mock.Anything, isLogin).Return(nil).Times(2)
+		r.On("DestroyBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			age := time.Since(before)
+			return age > 29*24*time.Hour && age < 31*24*time.Hour
+		})).Return(int64(1), nil).Once()
+
+		s.Record(ctx, event)
+		s.Record(ctx, event)
+
+		r.AssertExpectations(t)
+	})
+
+	t.Run("kept forever", func(t *testing.T) {
+		s, r := newTestService(0, database{})
+		r.On("Create", mock.Anything, isLogin).Return(nil).Once()
+
+		s.Record(ctx, event)
+
+		r.AssertExpectations(t)
+		r.AssertNotCalled(t, "DestroyBefore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("database not ready", func(t *testing.T) {
+		s, r := newTestService(30, database{err: errors.New("migration required")})
+
+		s.Record(ctx, event)
+
+		r.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
@@ -14,16 +14,39 @@ type ImageThumbnailOptions struct {
 	OutputPath    string
 	MaxDimensions int
 	Quality       int
+	// Orientation is the EXIF orientation of the input image. If set, the
+	// image is rotated and flipped so that it is displayed upright.
+	Orientation int
+}
+
+// orientationFilters are the filters applied to correct each EXIF orientation.
+var orientationFilters = map[int]string{
+	2: "hflip",
+	3: "hflip,vflip",
+	4: "vflip",
+	5: "transpose=0",
+	6: "transpose=1",
+	7: "transpose=3",
+	8: "transpose=2",
 }
 
 func ImageThumbnail(input string, options ImageThumbnailOptions) ffmpeg.Args {
 	var videoFilter ffmpeg.VideoFilter
+	orientationFilter, orient := orientationFilters[options.Orientation]
+	if orient {
+		videoFilter = videoFilter.Append(orientationFilter)
+	}
 	videoFilter = videoFilter.ScaleMaxSize(options.MaxDimensions)
 
 	var args ffmpeg.Args
 	args = append(args, "-hide_banner")
 	args = args.LogLevel(ffmpeg.LogLevelError)
 
+	if orient {
+		// orientation is applied explicitly
+		args = append(args, "-noautorotate")
+	}
+
 	args = args.Overwrite().
 		ImageFormat(options.InputFormat).
 		Input(input).
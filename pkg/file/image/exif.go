@@ -0,0 +1,332 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stashapp/stash/pkg/file"
+)
+
+// EXIF tags read from the image.
+// See https://exiftool.org/TagNames/EXIF.html
+const (
+	tagMake        = 0x010f
+	tagModel       = 0x0110
+	tagOrientation = 0x0112
+	tagDateTime    = 0x0132
+	tagXPTitle     = 0x9c9b
+	tagXPKeywords  = 0x9c9e
+	tagExifIFD     = 0x8769
+	tagGPSIFD      = 0x8825
+
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagLensMake           = 0xa433
+	tagLensModel          = 0xa434
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+)
+
+// EXIF value types
+const (
+	exifTypeByte      = 1
+	exifTypeASCII     = 2
+	exifTypeShort     = 3
+	exifTypeLong      = 4
+	exifTypeRational  = 5
+	exifTypeUndefined = 7
+	exifTypeSLong     = 9
+	exifTypeSRational = 10
+)
+
+var exifTypeSizes = map[uint16]uint32{
+	exifTypeByte:      1,
+	exifTypeASCII:     1,
+	exifTypeShort:     2,
+	exifTypeLong:      4,
+	exifTypeRational:  8,
+	exifTypeUndefined: 1,
+	exifTypeSLong:     4,
+	exifTypeSRational: 8,
+}
+
+const exifDateFormat = "2006:01:02 15:04:05"
+
+var errInvalidExif = errors.New("invalid exif data")
+
+type exifEntry struct {
+	tag       uint16
+	valueType uint16
+	count     uint32
+	value     []byte
+}
+
+type exifReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// readIFD returns the entries of the IFD at the given offset, keyed by tag.
+// Entries with unsupported types or invalid offsets are ignored.
+func (r *exifReader) readIFD(offset uint32) (map[uint16]exifEntry, error) {
+	const entrySize = 12
+
+	if uint64(offset)+2 > uint64(len(r.data)) {
+		return nil, fmt.Errorf("%w: ifd offset %d out of range", errInvalidExif, offset)
+	}
+
+	n := uint32(r.order.Uint16(r.data[offset:]))
+	start := offset + 2
+	if uint64(start)+uint64(n)*entrySize > uint64(len(r.data)) {
+		return nil, fmt.Errorf("%w: ifd entries out of range", errInvalidExif)
+	}
+
+	ret := make(map[uint16]exifEntry, n)
+	for i := uint32(0); i < n; i++ {
+		e := r.data[start+i*entrySize:]
+		entry := exifEntry{
+			tag:       r.order.Uint16(e),
+			valueType: r.order.Uint16(e[2:]),
+			count:     r.order.Uint32(e[4:]),
+		}
+
+		typeSize, ok := exifTypeSizes[entry.valueType]
+		if !ok {
+			continue
+		}
+
+		size := uint64(typeSize) * uint64(entry.count)
+		if size <= 4 {
+			entry.value = e[8 : 8+size]
+		} else {
+			valueOffset := uint64(r.order.Uint32(e[8:]))
+			if valueOffset+size > uint64(len(r.data)) {
+				continue
+			}
+			entry.value = r.data[valueOffset : valueOffset+size]
+		}
+
+		ret[entry.tag] = entry
+	}
+
+	return ret, nil
+}
+
+func (r *exifReader) string(e exifEntry) string {
+	if e.valueType != exifTypeASCII && e.valueType != exifTypeUndefined {
+		return ""
+	}
+
+	v := e.value
+	if idx := bytes.IndexByte(v, 0); idx != -1 {
+		v = v[:idx]
+	}
+
+	return strings.TrimSpace(string(v))
+}
+
+// ucs2String decodes the little-endian UCS-2 strings used by the Windows XP tags.
+func (r *exifReader) ucs2String(e exifEntry) string {
+	if e.valueType != exifTypeByte || len(e.value) < 2 {
+		return ""
+	}
+
+	u := make([]uint16, 0, len(e.value)/2)
+	for i := 0; i+1 < len(e.value); i += 2 {
+		c := binary.LittleEndian.Uint16(e.value[i:])
+		if c == 0 {
+			break
+		}
+		u = append(u, c)
+	}
+
+	return strings.TrimSpace(string(utf16.Decode(u)))
+}
+
+func (r *exifReader) uint(e exifEntry) (uint32, bool) {
+	if e.count == 0 {
+		return 0, false
+	}
+
+	switch e.valueType {
+	case exifTypeByte:
+		return uint32(e.value[0]), true
+	case exifTypeShort:
+		return uint32(r.order.Uint16(e.value)), true
+	case exifTypeLong:
+		return r.order.Uint32(e.value), true
+	}
+
+	return 0, false
+}
+
+func (r *exifReader) rationals(e exifEntry) []float64 {
+	if e.valueType != exifTypeRational && e.valueType != exifTypeSRational {
+		return nil
+	}
+
+	ret := make([]float64, e.count)
+	for i := range ret {
+		v := e.value[i*8:]
+		var num, den float64
+		if e.valueType == exifTypeRational {
+			num = float64(r.order.Uint32(v))
+			den = float64(r.order.Uint32(v[4:]))
+		} else {
+			num = float64(int32(r.order.Uint32(v)))
+			den = float64(int32(r.order.Uint32(v[4:])))
+		}
+
+		if den == 0 {
+			return nil
+		}
+		ret[i] = num / den
+	}
+
+	return ret
+}
+
+// parseExif parses the TIFF-structured EXIF data in data, setting the values found in m.
+func parseExif(data []byte, m *file.ImageMetadata) error {
+	if len(data) < 8 {
+		return errInvalidExif
+	}
+
+	r := &exifReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return fmt.Errorf("%w: invalid byte order", errInvalidExif)
+	}
+
+	if r.order.Uint16(data[2:]) != 42 {
+		return fmt.Errorf("%w: invalid tiff header", errInvalidExif)
+	}
+
+	ifd0, err := r.readIFD(r.order.Uint32(data[4:]))
+	if err != nil {
+		return err
+	}
+
+	if e, ok := ifd0[tagOrientation]; ok {
+		if v, ok := r.uint(e); ok && v >= file.OrientationNormal && v <= file.OrientationMax {
+			m.Orientation = int(v)
+		}
+	}
+
+	m.CameraMake = r.string(ifd0[tagMake])
+	m.CameraModel = r.string(ifd0[tagModel])
+	m.Title = r.ucs2String(ifd0[tagXPTitle])
+	m.Keywords = appendKeywords(m.Keywords, strings.Split(r.ucs2String(ifd0[tagXPKeywords]), ";")...)
+
+	dateTime := r.string(ifd0[tagDateTime])
+	offsetTime := ""
+
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if offset, ok := r.uint(e); ok {
+			exifIFD, err := r.readIFD(offset)
+			if err != nil {
+				return err
+			}
+
+			if v := r.string(exifIFD[tagDateTimeOriginal]); v != "" {
+				dateTime = v
+				offsetTime = r.string(exifIFD[tagOffsetTimeOriginal])
+			}
+
+			lens := r.string(exifIFD[tagLensModel])
+			lensMake := r.string(exifIFD[tagLensMake])
+			if lensMake != "" && lens != "" && !strings.HasPrefix(lens, lensMake) {
+				lens = lensMake + " " + lens
+			}
+			m.Lens = lens
+		}
+	}
+
+	if t := parseExifDate(dateTime, offsetTime); t != nil {
+		m.DateTaken = t
+	}
+
+	if e, ok := ifd0[tagGPSIFD]; ok {
+		if offset, ok := r.uint(e); ok {
+			gpsIFD, err := r.readIFD(offset)
+			if err != nil {
+				return err
+			}
+
+			r.setGPS(gpsIFD, m)
+		}
+	}
+
+	return nil
+}
+
+func (r *exifReader) setGPS(ifd map[uint16]exifEntry, m *file.ImageMetadata) {
+	coordinate := func(valueTag, refTag uint16, negativeRef string) *float64 {
+		v := r.rationals(ifd[valueTag])
+		if len(v) != 3 {
+			return nil
+		}
+
+		ret := v[0] + v[1]/60 + v[2]/3600
+		if strings.EqualFold(r.string(ifd[refTag]), negativeRef) {
+			ret = -ret
+		}
+		return &ret
+	}
+
+	lat := coordinate(tagGPSLatitude, tagGPSLatitudeRef, "S")
+	lon := coordinate(tagGPSLongitude, tagGPSLongitudeRef, "W")
+
+	// only set if both are present
+	if lat != nil && lon != nil {
+		m.Latitude = lat
+		m.Longitude = lon
+	}
+
+	if v := r.rationals(ifd[tagGPSAltitude]); len(v) == 1 {
+		alt := v[0]
+		// reference of 1 indicates below sea level
+		if ref, ok := r.uint(ifd[tagGPSAltitudeRef]); ok && ref == 1 {
+			alt = -alt
+		}
+		m.Altitude = &alt
+	}
+}
+
+// parseExifDate parses an EXIF date time string with an optional offset.
+// Dates without an offset are returned in UTC so that the date portion is
+// preserved.
+func parseExifDate(v string, offset string) *time.Time {
+	if v == "" {
+		return nil
+	}
+
+	loc := time.UTC
+	if offset != "" {
+		if o, err := time.Parse("-07:00", offset); err == nil {
+			_, secs := o.Zone()
+			loc = time.FixedZone("", secs)
+		}
+	}
+
+	t, err := time.ParseInLocation(exifDateFormat, v, loc)
+	if err != nil || t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,294 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/file"
+)
+
+// maxMetadataSegmentSize is the maximum size of a metadata segment or chunk
+// that will be read into memory.
+const maxMetadataSegmentSize = 16 << 20
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngHeader  = []byte("\x89PNG\r\n\x1a\n")
+	riffHeader = []byte("RIFF")
+	webPHeader = []byte("WEBP")
+)
+
+var errMetadataTooLarge = errors.New("metadata segment too large")
+
+// ReadMetadata reads the EXIF and XMP metadata embedded in the provided
+// image data. JPEG, PNG and WebP images are supported. Images of other
+// formats, or images without metadata, return metadata with the default
+// orientation.
+func ReadMetadata(r io.Reader) (file.ImageMetadata, error) {
+	ret := file.ImageMetadata{
+		Orientation: file.OrientationNormal,
+	}
+
+	br := bufio.NewReader(r)
+	header, err := br.Peek(12)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return ret, err
+	}
+
+	var exifData, xmpData []byte
+	switch {
+	case bytes.HasPrefix(header, []byte{0xff, 0xd8}):
+		exifData, xmpData, err = readJPEGMetadata(br)
+	case bytes.HasPrefix(header, pngHeader):
+		exifData, xmpData, err = readPNGMetadata(br)
+	case bytes.HasPrefix(header, riffHeader) && len(header) >= 12 && bytes.Equal(header[8:12], webPHeader):
+		exifData, xmpData, err = readWebPMetadata(br)
+	default:
+		return ret, nil
+	}
+
+	if err != nil {
+		return ret, err
+	}
+
+	if len(exifData) > 0 {
+		if err := parseExif(exifData, &ret); err != nil {
+			return ret, fmt.Errorf("parsing exif data: %w", err)
+		}
+	}
+
+	if len(xmpData) > 0 {
+		if err := parseXMP(xmpData, &ret); err != nil {
+			return ret, fmt.Errorf("parsing xmp data: %w", err)
+		}
+	}
+
+	return ret, nil
+}
+
+func readSegment(r io.Reader, size int64) ([]byte, error) {
+	if size > maxMetadataSegmentSize {
+		return nil, errMetadataTooLarge
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func skip(r io.Reader, size int64) error {
+	_, err := io.CopyN(io.Discard, r, size)
+	return err
+}
+
+// readJPEGMetadata reads the APP1 segments of a JPEG stream until the start
+// of the image data.
+func readJPEGMetadata(r io.Reader) (exifData []byte, xmpData []byte, err error) {
+	const (
+		markerSOI  = 0xd8
+		markerEOI  = 0xd9
+		markerSOS  = 0xda
+		markerAPP1 = 0xe1
+		markerRST0 = 0xd0
+		markerRST7 = 0xd7
+	)
+
+	var marker [2]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		if _, err := io.ReadFull(r, marker[:1]); err != nil {
+			return nil, nil, err
+		}
+		if marker[0] != 0xff {
+			return nil, nil, fmt.Errorf("invalid jpeg marker %#x", marker[0])
+		}
+
+		// skip fill bytes
+		for marker[1] = 0xff; marker[1] == 0xff; {
+			if _, err := io.ReadFull(r, marker[1:]); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		m := marker[1]
+		if m == markerSOS || m == markerEOI {
+			// metadata always precedes the image data
+			return exifData, xmpData, nil
+		}
+		if m == markerSOI || (m >= markerRST0 && m <= markerRST7) {
+			// markers without a length
+			continue
+		}
+
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, nil, err
+		}
+		if length < 2 {
+			return nil, nil, fmt.Errorf("invalid jpeg segment length %d", length)
+		}
+		size := int64(length) - 2
+
+		if m != markerAPP1 {
+			if err := skip(r, size); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		data, err := readSegment(r, size)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case bytes.HasPrefix(data, exifHeader) && exifData == nil:
+			exifData = data[len(exifHeader):]
+		case bytes.HasPrefix(data, xmpHeader) && xmpData == nil:
+			xmpData = data[len(xmpHeader):]
+		}
+	}
+}
+
+// readPNGMetadata reads the eXIf and XMP iTXt chunks of a PNG stream until
+// the start of the image data.
+func readPNGMetadata(r io.Reader) (exifData []byte, xmpData []byte, err error) {
+	const xmpKeyword = "XML:com.adobe.xmp"
+
+	if err := skip(r, int64(len(pngHeader))); err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		var chunkHeader struct {
+			Length uint32
+			Type   [4]byte
+		}
+		if err := binary.Read(r, binary.BigEndian, &chunkHeader); err != nil {
+			return nil, nil, err
+		}
+
+		size := int64(chunkHeader.Length)
+		switch string(chunkHeader.Type[:]) {
+		case "IDAT", "IEND":
+			return exifData, xmpData, nil
+		case "eXIf":
+			exifData, err = readSegment(r, size)
+			if err != nil {
+				return nil, nil, err
+			}
+		case "iTXt":
+			data, err := readSegment(r, size)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// keyword, null, compression flag, compression method, language tag, null, translated keyword, null, text
+			if bytes.HasPrefix(data, []byte(xmpKeyword+"\x00\x00")) {
+				rest := data[len(xmpKeyword)+3:]
+				for i := 0; i < 2; i++ {
+					idx := bytes.IndexByte(rest, 0)
+					if idx == -1 {
+						rest = nil
+						break
+					}
+					rest = rest[idx+1:]
+				}
+				xmpData = rest
+			}
+		default:
+			if err := skip(r, size); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// skip crc
+		if err := skip(r, 4); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// readWebPMetadata reads the EXIF and XMP chunks of a WebP RIFF container.
+func readWebPMetadata(r io.Reader) (exifData []byte, xmpData []byte, err error) {
+	if err := skip(r, 12); err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		var chunkHeader struct {
+			Type   [4]byte
+			Length uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunkHeader); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return exifData, xmpData, nil
+			}
+			return nil, nil, err
+		}
+
+		size := int64(chunkHeader.Length)
+		switch string(chunkHeader.Type[:]) {
+		case "EXIF":
+			exifData, err = readSegment(r, size)
+			if err != nil {
+				return nil, nil, err
+			}
+			// some encoders include the jpeg exif header
+			exifData = bytes.TrimPrefix(exifData, exifHeader)
+		case "XMP ":
+			xmpData, err = readSegment(r, size)
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			if err := skip(r, size); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// chunks are padded to an even size
+		if size%2 == 1 {
+			if err := skip(r, 1); err != nil {
+				return exifData, xmpData, nil
+			}
+		}
+	}
+}
+
+// appendKeywords appends the keywords in v to k, ignoring empty and
+// duplicate keywords.
+func appendKeywords(k []string, v ...string) []string {
+	for _, vv := range v {
+		vv = strings.TrimSpace(vv)
+		if vv == "" {
+			continue
+		}
+
+		found := false
+		for _, kk := range k {
+			if strings.EqualFold(kk, vv) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			k = append(k, vv)
+		}
+	}
+
+	return k
+}
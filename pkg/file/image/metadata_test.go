@@ -0,0 +1,261 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/file"
+	"github.com/stretchr/testify/assert"
+)
+
+type testExifEntry struct {
+	tag       uint16
+	valueType uint16
+	count     uint32
+	value     []byte
+}
+
+// makeTestIFD appends an IFD with the provided entries to the data, which must
+// be the TIFF data up to this point. It returns the new data and the offset of the IFD.
+func makeTestIFD(data []byte, entries []testExifEntry) ([]byte, uint32) {
+	le := binary.LittleEndian
+	offset := uint32(len(data))
+	valueOffset := offset + 2 + uint32(len(entries))*12 + 4
+
+	ifd := le.AppendUint16(nil, uint16(len(entries)))
+	var values []byte
+	for _, e := range entries {
+		ifd = le.AppendUint16(ifd, e.tag)
+		ifd = le.AppendUint16(ifd, e.valueType)
+		ifd = le.AppendUint32(ifd, e.count)
+		if len(e.value) <= 4 {
+			v := make([]byte, 4)
+			copy(v, e.value)
+			ifd = append(ifd, v...)
+		} else {
+			ifd = le.AppendUint32(ifd, valueOffset+uint32(len(values)))
+			values = append(values, e.value...)
+		}
+	}
+	// next ifd
+	ifd = le.AppendUint32(ifd, 0)
+
+	return append(append(data, ifd...), values...), offset
+}
+
+func asciiEntry(tag uint16, v string) testExifEntry {
+	return testExifEntry{tag, exifTypeASCII, uint32(len(v) + 1), append([]byte(v), 0)}
+}
+
+func rationalEntry(tag uint16, v ...uint32) testExifEntry {
+	var b []byte
+	for i := 0; i < len(v); i += 2 {
+		b = binary.LittleEndian.AppendUint32(b, v[i])
+		b = binary.LittleEndian.AppendUint32(b, v[i+1])
+	}
+	return testExifEntry{tag, exifTypeRational, uint32(len(v) / 2), b}
+}
+
+func ucs2Entry(tag uint16, v string) testExifEntry {
+	var b []byte
+	for _, r := range v {
+		b = binary.LittleEndian.AppendUint16(b, uint16(r))
+	}
+	b = append(b, 0, 0)
+	return testExifEntry{tag, exifTypeByte, uint32(len(b)), b}
+}
+
+func makeTestExif() []byte {
+	data := []byte("II\x2a\x00\x08\x00\x00\x00")
+
+	data, exifOffset := makeTestIFD(data, []testExifEntry{
+		asciiEntry(tagDateTimeOriginal, "2021:07:04 10:11:12"),
+		asciiEntry(tagOffsetTimeOriginal, "+02:00"),
+		asciiEntry(tagLensMake, "Canon"),
+		asciiEntry(tagLensModel, "EF 50mm f/1.8"),
+	})
+
+	data, gpsOffset := makeTestIFD(data, []testExifEntry{
+		asciiEntry(tagGPSLatitudeRef, "S"),
+		rationalEntry(tagGPSLatitude, 33, 1, 30, 1, 0, 1),
+		asciiEntry(tagGPSLongitudeRef, "E"),
+		rationalEntry(tagGPSLongitude, 151, 1, 12, 1, 36, 1),
+		{tagGPSAltitudeRef, exifTypeByte, 1, []byte{1}},
+		rationalEntry(tagGPSAltitude, 25, 2),
+	})
+
+	// ifd0 is placed at the end and the header updated to point to it
+	data, ifd0Offset := makeTestIFD(data, []testExifEntry{
+		asciiEntry(tagMake, "Canon"),
+		asciiEntry(tagModel, "Canon EOS 5D"),
+		{tagOrientation, exifTypeShort, 1, []byte{6, 0}},
+		ucs2Entry(tagXPKeywords, "beach;sunset"),
+		{tagExifIFD, exifTypeLong, 1, binary.LittleEndian.AppendUint32(nil, exifOffset)},
+		{tagGPSIFD, exifTypeLong, 1, binary.LittleEndian.AppendUint32(nil, gpsOffset)},
+	})
+	binary.LittleEndian.PutUint32(data[4:], ifd0Offset)
+
+	return data
+}
+
+const testXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmp:CreateDate="2019-03-02T01:02:03">
+   <dc:title>
+    <rdf:Alt>
+     <rdf:li xml:lang="x-default">Sunset at the beach</rdf:li>
+    </rdf:Alt>
+   </dc:title>
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>Sunset</rdf:li>
+     <rdf:li>holiday</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func jpegSegment(marker byte, data []byte) []byte {
+	ret := []byte{0xff, marker}
+	ret = binary.BigEndian.AppendUint16(ret, uint16(len(data)+2))
+	return append(ret, data...)
+}
+
+func makeTestJPEG(exif []byte, xmp string) []byte {
+	ret := []byte{0xff, 0xd8}
+	// APP0 JFIF
+	ret = append(ret, jpegSegment(0xe0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))...)
+	if exif != nil {
+		ret = append(ret, jpegSegment(0xe1, append(append([]byte{}, exifHeader...), exif...))...)
+	}
+	if xmp != "" {
+		ret = append(ret, jpegSegment(0xe1, append(append([]byte{}, xmpHeader...), xmp...))...)
+	}
+	// start of scan - data after this should not be read
+	ret = append(ret, 0xff, 0xda, 0x00)
+	return ret
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	ret := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	ret = append(ret, chunkType...)
+	ret = append(ret, data...)
+	return binary.BigEndian.AppendUint32(ret, crc32.ChecksumIEEE(ret[4:]))
+}
+
+func makeTestPNG(exif []byte, xmp string) []byte {
+	ret := append([]byte{}, pngHeader...)
+	ret = append(ret, pngChunk("IHDR", make([]byte, 13))...)
+	if exif != nil {
+		ret = append(ret, pngChunk("eXIf", exif)...)
+	}
+	if xmp != "" {
+		ret = append(ret, pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"+xmp))...)
+	}
+	ret = append(ret, pngChunk("IDAT", nil)...)
+	return ret
+}
+
+func makeTestWebP(exif []byte, xmp string) []byte {
+	var chunks []byte
+	chunk := func(chunkType string, data []byte) {
+		chunks = append(chunks, chunkType...)
+		chunks = binary.LittleEndian.AppendUint32(chunks, uint32(len(data)))
+		chunks = append(chunks, data...)
+		if len(data)%2 == 1 {
+			chunks = append(chunks, 0)
+		}
+	}
+
+	chunk("VP8X", make([]byte, 10))
+	chunk("VP8 ", make([]byte, 5))
+	if exif != nil {
+		chunk("EXIF", exif)
+	}
+	if xmp != "" {
+		chunk("XMP ", []byte(xmp))
+	}
+
+	ret := append([]byte{}, riffHeader...)
+	ret = binary.LittleEndian.AppendUint32(ret, uint32(len(chunks)+4))
+	ret = append(ret, webPHeader...)
+	return append(ret, chunks...)
+}
+
+func TestReadMetadata(t *testing.T) {
+	floatPtr := func(v float64) *float64 { return &v }
+	timePtr := func(v time.Time) *time.Time { return &v }
+
+	exifOnly := file.ImageMetadata{
+		Orientation: 6,
+		DateTaken:   timePtr(time.Date(2021, 7, 4, 10, 11, 12, 0, time.FixedZone("", 2*60*60))),
+		CameraMake:  "Canon",
+		CameraModel: "Canon EOS 5D",
+		Lens:        "Canon EF 50mm f/1.8",
+		Latitude:    floatPtr(-33.5),
+		Longitude:   floatPtr(151.21),
+		Altitude:    floatPtr(-12.5),
+		Keywords:    []string{"beach", "sunset"},
+	}
+
+	combined := exifOnly
+	combined.Title = "Sunset at the beach"
+	combined.Keywords = []string{"beach", "sunset", "holiday"}
+
+	xmpOnly := file.ImageMetadata{
+		Orientation: file.OrientationNormal,
+		DateTaken:   timePtr(time.Date(2019, 3, 2, 1, 2, 3, 0, time.UTC)),
+		Title:       "Sunset at the beach",
+		Keywords:    []string{"Sunset", "holiday"},
+	}
+
+	exif := makeTestExif()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    file.ImageMetadata
+		wantErr bool
+	}{
+		{"jpeg exif", makeTestJPEG(exif, ""), exifOnly, false},
+		{"jpeg exif and xmp", makeTestJPEG(exif, testXMP), combined, false},
+		{"jpeg xmp", makeTestJPEG(nil, testXMP), xmpOnly, false},
+		{"jpeg no metadata", makeTestJPEG(nil, ""), file.ImageMetadata{Orientation: file.OrientationNormal}, false},
+		{"png exif and xmp", makeTestPNG(exif, testXMP), combined, false},
+		{"webp exif and xmp", makeTestWebP(exif, testXMP), combined, false},
+		{"gif", []byte("GIF89a"), file.ImageMetadata{Orientation: file.OrientationNormal}, false},
+		{"truncated jpeg", makeTestJPEG(exif, "")[:30], file.ImageMetadata{Orientation: file.OrientationNormal}, true},
+		{"invalid exif", makeTestJPEG([]byte("XX\x2a\x00"), ""), file.ImageMetadata{Orientation: file.OrientationNormal}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadMetadata(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadMetadata() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want.Longitude != nil && got.Longitude != nil {
+				assert.InDelta(t, *tt.want.Longitude, *got.Longitude, 0.0001)
+				got.Longitude = tt.want.Longitude
+			}
+
+			if tt.want.DateTaken != nil && got.DateTaken != nil {
+				assert.True(t, tt.want.DateTaken.Equal(*got.DateTaken), "DateTaken = %v, want %v", got.DateTaken, tt.want.DateTaken)
+				got.DateTaken = tt.want.DateTaken
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
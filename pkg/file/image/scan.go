@@ -40,6 +40,7 @@ func (d *Decorator) Decorate(ctx context.Context, fs file.FS, f file.File) (file
 			Format:   format,
 			Width:    c.Width,
 			Height:   c.Height,
+			Metadata: d.readMetadata(fs, base),
 		}, nil
 	}
 
@@ -73,9 +74,28 @@ func (d *Decorator) Decorate(ctx context.Context, fs file.FS, f file.File) (file
 		Format:   probe.VideoCodec,
 		Width:    probe.Width,
 		Height:   probe.Height,
+		Metadata: d.readMetadata(fs, base),
 	}, nil
 }
 
+// readMetadata reads the embedded metadata of the image file. Errors are
+// logged, and the metadata read up to the error is returned.
+func (d *Decorator) readMetadata(fs file.FS, base *file.BaseFile) file.ImageMetadata {
+	r, err := fs.Open(base.Path)
+	if err != nil {
+		logger.Warnf("Could not open image file %q to read metadata: %v", base.Path, err)
+		return file.ImageMetadata{Orientation: file.OrientationNormal}
+	}
+	defer r.Close()
+
+	ret, err := ReadMetadata(r)
+	if err != nil {
+		logger.Warnf("Error reading metadata of image file %q: %v", base.Path, err)
+	}
+
+	return ret
+}
+
 func (d *Decorator) IsMissingMetadata(ctx context.Context, fs file.FS, f file.File) bool {
 	const (
 		unsetString = "unset"
@@ -87,7 +107,7 @@ func (d *Decorator) IsMissingMetadata(ctx context.Context, fs file.FS, f file.Fi
 
 	switch {
 	case isImage:
-		return imf.Format == unsetString || imf.Width == unsetNumber || imf.Height == unsetNumber
+		return imf.Format == unsetString || imf.Width == unsetNumber || imf.Height == unsetNumber || imf.Metadata.Orientation == file.OrientationUnset
 	case isVideo:
 		videoFileDecorator := video.Decorator{FFProbe: d.FFProbe}
 		return videoFileDecorator.IsMissingMetadata(ctx, fs, vf)
@@ -0,0 +1,38 @@
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/file"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecorator_IsMissingMetadata(t *testing.T) {
+	imageFile := func(orientation int) *file.ImageFile {
+		return &file.ImageFile{
+			BaseFile: &file.BaseFile{},
+			Format:   "jpeg",
+			Width:    100,
+			Height:   100,
+			Metadata: file.ImageMetadata{Orientation: orientation},
+		}
+	}
+
+	tests := []struct {
+		name string
+		f    file.File
+		want bool
+	}{
+		{"never scanned", imageFile(file.OrientationUnset), true},
+		{"no orientation tag", imageFile(file.OrientationNormal), false},
+		{"rotated", imageFile(6), false},
+	}
+
+	d := &Decorator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, d.IsMissingMetadata(context.Background(), nil, tt.f))
+		})
+	}
+}
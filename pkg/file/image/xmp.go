@@ -0,0 +1,134 @@
+package image
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/file"
+)
+
+// XMP namespaces read from the image.
+const (
+	xmpNSRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmpNSDC        = "http://purl.org/dc/elements/1.1/"
+	xmpNSXMP       = "http://ns.adobe.com/xap/1.0/"
+	xmpNSPhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+	xmpNSExif      = "http://ns.adobe.com/exif/1.0/"
+)
+
+var xmpDateFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// xmpDateProperties are the properties used for the date taken, in order of preference.
+var xmpDateProperties = []xml.Name{
+	{Space: xmpNSExif, Local: "DateTimeOriginal"},
+	{Space: xmpNSPhotoshop, Local: "DateCreated"},
+	{Space: xmpNSXMP, Local: "CreateDate"},
+}
+
+// parseXMP parses the XMP packet in data, setting the values found in m.
+// Values from the XMP data take precedence over EXIF values, except for the
+// date taken, which is only set if not present in the EXIF data.
+func parseXMP(data []byte, m *file.ImageMetadata) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		// the current property element outside of rdf elements
+		property xml.Name
+		inLi     bool
+		title    string
+		keywords []string
+		dates    = make(map[xml.Name]string)
+	)
+
+	for {
+		t, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == xmpNSRDF && t.Name.Local == "Description":
+				// simple properties may be expressed as attributes
+				for _, a := range t.Attr {
+					dates[a.Name] = a.Value
+				}
+			case t.Name.Space == xmpNSRDF && t.Name.Local == "li":
+				inLi = true
+			case t.Name.Space != xmpNSRDF:
+				property = t.Name
+			}
+		case xml.EndElement:
+			switch {
+			case t.Name.Space == xmpNSRDF && t.Name.Local == "li":
+				inLi = false
+			case t.Name == property:
+				property = xml.Name{}
+			}
+		case xml.CharData:
+			v := strings.TrimSpace(string(t))
+			if v == "" {
+				continue
+			}
+
+			switch {
+			case property == xml.Name{Space: xmpNSDC, Local: "title"} && inLi:
+				// use the first alternative
+				if title == "" {
+					title = v
+				}
+			case property == xml.Name{Space: xmpNSDC, Local: "subject"} && inLi:
+				keywords = append(keywords, v)
+			case property.Space != "":
+				dates[property] = v
+			}
+		}
+	}
+
+	if title != "" {
+		m.Title = title
+	}
+
+	m.Keywords = appendKeywords(m.Keywords, keywords...)
+
+	if m.DateTaken == nil {
+		for _, p := range xmpDateProperties {
+			if t := parseXMPDate(dates[p]); t != nil {
+				m.DateTaken = t
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseXMPDate parses an XMP date. Dates without a time zone are returned in UTC.
+func parseXMPDate(v string) *time.Time {
+	if v == "" {
+		return nil
+	}
+
+	for _, f := range xmpDateFormats {
+		t, err := time.Parse(f, v)
+		if err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
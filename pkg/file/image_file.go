@@ -1,11 +1,15 @@
 package file
 
+import "time"
+
 // ImageFile is an extension of BaseFile to represent image files.
 type ImageFile struct {
 	*BaseFile
 	Format string `json:"format"`
 	Width  int    `json:"width"`
 	Height int    `json:"height"`
+
+	Metadata ImageMetadata `json:"metadata"`
 }
 
 func (f ImageFile) GetWidth() int {
@@ -19,3 +23,32 @@ func (f ImageFile) GetHeight() int {
 func (f ImageFile) GetFormat() string {
 	return f.Format
 }
+
+// Image orientation values, as defined by the EXIF Orientation tag.
+const (
+	// OrientationUnset indicates that the file has never been scanned for
+	// its embedded metadata.
+	OrientationUnset = 0
+	// OrientationNormal is the default orientation.
+	OrientationNormal = 1
+	// OrientationMax is the largest valid orientation value.
+	OrientationMax = 8
+)
+
+// ImageMetadata holds the metadata embedded in an image file, read from
+// its EXIF and XMP data.
+type ImageMetadata struct {
+	// Orientation is the EXIF orientation flag, between 1 and 8.
+	// It is OrientationNormal if the tag is absent, and OrientationUnset if
+	// the file has never been scanned.
+	Orientation int        `json:"orientation"`
+	DateTaken   *time.Time `json:"date_taken"`
+	CameraMake  string     `json:"camera_make"`
+	CameraModel string     `json:"camera_model"`
+	Lens        string     `json:"lens"`
+	Latitude    *float64   `json:"latitude"`
+	Longitude   *float64   `json:"longitude"`
+	Altitude    *float64   `json:"altitude"`
+	Title       string     `json:"title"`
+	Keywords    []string   `json:"keywords"`
+}
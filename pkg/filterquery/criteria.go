@@ -0,0 +1,629 @@
+package filterquery
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const dateFormat = "2006-01-02"
+
+// anyValue is the value matching any non-null value.
+const anyValue = "*"
+
+func isAny(v Value) bool {
+	return !v.Quoted && v.Text == anyValue
+}
+
+func singleValue(t Term) (Value, error) {
+	if len(t.Values) != 1 {
+		return Value{}, errorf(t.Values[1].Pos, "field %q does not accept multiple values", t.Key)
+	}
+
+	return t.Values[0], nil
+}
+
+func unsupportedOperator(t Term) error {
+	return errorf(t.Pos, "operator %q cannot be used with field %q", t.Operator, t.Key)
+}
+
+func cannotNegate(t Term) error {
+	return errorf(t.Pos, "field %q cannot be negated", t.Key)
+}
+
+// orderedOperator returns the operator of an ordered term, taking negation
+// into account.
+func orderedOperator(t Term) (Operator, error) {
+	op := t.Operator
+	if op == OperatorIncludes {
+		op = OperatorEquals
+	}
+
+	if !t.Negate {
+		if op == OperatorMatches {
+			return "", unsupportedOperator(t)
+		}
+		return op, nil
+	}
+
+	switch op {
+	case OperatorEquals:
+		return OperatorNotEquals, nil
+	case OperatorNotEquals:
+		return OperatorEquals, nil
+	case OperatorGreaterThan:
+		return OperatorLessOrEqual, nil
+	case OperatorGreaterOrEqual:
+		return OperatorLessThan, nil
+	case OperatorLessThan:
+		return OperatorGreaterOrEqual, nil
+	case OperatorLessOrEqual:
+		return OperatorGreaterThan, nil
+	}
+
+	return "", unsupportedOperator(t)
+}
+
+// nullModifier returns the modifier for a term with the * value.
+func nullModifier(t Term, op Operator) (models.CriterionModifier, error) {
+	switch op {
+	case OperatorEquals:
+		return models.CriterionModifierNotNull, nil
+	case OperatorNotEquals:
+		return models.CriterionModifierIsNull, nil
+	}
+
+	return "", unsupportedOperator(t)
+}
+
+// anyModifier returns the modifier for a term with the * value.
+func anyModifier(t Term) (models.CriterionModifier, error) {
+	op, err := orderedOperator(t)
+	if err != nil {
+		return "", err
+	}
+
+	return nullModifier(t, op)
+}
+
+// orderedRange converts a range value to an equivalent operator and bounds.
+// Open ranges are converted to comparisons.
+func orderedRange(t Term, v Value, op Operator) (Operator, string, string, bool, error) {
+	lower, upper, ok := v.Range()
+	if !ok {
+		return op, v.Text, "", false, nil
+	}
+
+	if op != OperatorEquals && op != OperatorNotEquals {
+		return "", "", "", false, errorf(v.Pos, "ranges cannot be used with operator %q", t.Operator)
+	}
+
+	switch {
+	case lower != "" && upper != "":
+		return op, lower, upper, true, nil
+	case lower != "":
+		if op == OperatorEquals {
+			return OperatorGreaterOrEqual, lower, "", false, nil
+		}
+		return OperatorLessThan, lower, "", false, nil
+	case upper != "":
+		if op == OperatorEquals {
+			return OperatorLessOrEqual, upper, "", false, nil
+		}
+		return OperatorGreaterThan, upper, "", false, nil
+	}
+
+	return "", "", "", false, errorf(v.Pos, "range requires a lower or upper bound")
+}
+
+func parseInt(v Value, s string) (int, error) {
+	ret, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errorf(v.Pos, "invalid number %q", s)
+	}
+	return ret, nil
+}
+
+// parseDuration parses a number of seconds or a duration such as 1h30m.
+func parseDuration(v Value, s string) (int, error) {
+	if ret, err := strconv.Atoi(s); err == nil {
+		return ret, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errorf(v.Pos, "invalid duration %q", s)
+	}
+	return int(d.Seconds()), nil
+}
+
+func intCriterion(t Term, parse func(v Value, s string) (int, error)) (*models.IntCriterionInput, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if isAny(v) {
+		modifier, err := anyModifier(t)
+		if err != nil {
+			return nil, err
+		}
+		return &models.IntCriterionInput{Modifier: modifier}, nil
+	}
+
+	op, err := orderedOperator(t)
+	if err != nil {
+		return nil, err
+	}
+
+	op, lower, upper, isRange, err := orderedRange(t, v, op)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := parse(v, lower)
+	if err != nil {
+		return nil, err
+	}
+
+	if isRange {
+		n2, err := parse(v, upper)
+		if err != nil {
+			return nil, err
+		}
+
+		modifier := models.CriterionModifierBetween
+		if op == OperatorNotEquals {
+			modifier = models.CriterionModifierNotBetween
+		}
+		return &models.IntCriterionInput{Value: n, Value2: &n2, Modifier: modifier}, nil
+	}
+
+	ret := &models.IntCriterionInput{Value: n}
+	switch op {
+	case OperatorEquals:
+		ret.Modifier = models.CriterionModifierEquals
+	case OperatorNotEquals:
+		ret.Modifier = models.CriterionModifierNotEquals
+	case OperatorGreaterThan:
+		ret.Modifier = models.CriterionModifierGreaterThan
+	case OperatorGreaterOrEqual:
+		ret.Modifier = models.CriterionModifierGreaterThan
+		ret.Value = n - 1
+	case OperatorLessThan:
+		ret.Modifier = models.CriterionModifierLessThan
+	case OperatorLessOrEqual:
+		ret.Modifier = models.CriterionModifierLessThan
+		ret.Value = n + 1
+	}
+
+	return ret, nil
+}
+
+type dateRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// parseDateRange parses a year, month or date into the range of days it
+// covers.
+func parseDateRange(v Value, s string) (*dateRange, error) {
+	for _, layout := range []struct {
+		format string
+		years  int
+		months int
+	}{
+		{dateFormat, 0, 0},
+		{"2006-01", 0, 1},
+		{"2006", 1, 0},
+	} {
+		start, err := time.Parse(layout.format, s)
+		if err != nil {
+			continue
+		}
+
+		end := start
+		if layout.years > 0 || layout.months > 0 {
+			end = start.AddDate(layout.years, layout.months, -1)
+		}
+
+		return &dateRange{start: start, end: end}, nil
+	}
+
+	return nil, errorf(v.Pos, "invalid date %q, expected YYYY, YYYY-MM or YYYY-MM-DD", s)
+}
+
+type dateCriterionValue struct {
+	value    string
+	value2   *string
+	modifier models.CriterionModifier
+}
+
+// dateCriterion converts a date term. Timestamps are compared as strings,
+// so the upper bound of a timestamp range is the day after its end.
+func dateCriterion(t Term, timestamp bool) (*dateCriterionValue, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if isAny(v) {
+		modifier, err := anyModifier(t)
+		if err != nil {
+			return nil, err
+		}
+		return &dateCriterionValue{modifier: modifier}, nil
+	}
+
+	op, err := orderedOperator(t)
+	if err != nil {
+		return nil, err
+	}
+
+	op, lower, upper, isRange, err := orderedRange(t, v, op)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := parseDateRange(v, lower)
+	if err != nil {
+		return nil, err
+	}
+
+	if isRange {
+		r2, err := parseDateRange(v, upper)
+		if err != nil {
+			return nil, err
+		}
+		r.end = r2.end
+	}
+
+	format := func(t time.Time) string {
+		return t.Format(dateFormat)
+	}
+
+	between := func(modifier models.CriterionModifier) *dateCriterionValue {
+		end := r.end
+		if timestamp {
+			end = end.AddDate(0, 0, 1)
+		}
+		value2 := format(end)
+		return &dateCriterionValue{value: format(r.start), value2: &value2, modifier: modifier}
+	}
+
+	single := !timestamp && r.start.Equal(r.end)
+	dayAfter := format(r.end.AddDate(0, 0, 1))
+
+	switch op {
+	case OperatorEquals:
+		if single {
+			return &dateCriterionValue{value: format(r.start), modifier: models.CriterionModifierEquals}, nil
+		}
+		return between(models.CriterionModifierBetween), nil
+	case OperatorNotEquals:
+		if single {
+			return &dateCriterionValue{value: format(r.start), modifier: models.CriterionModifierNotEquals}, nil
+		}
+		return between(models.CriterionModifierNotBetween), nil
+	case OperatorGreaterThan:
+		value := format(r.end)
+		if timestamp {
+			value = dayAfter
+		}
+		return &dateCriterionValue{value: value, modifier: models.CriterionModifierGreaterThan}, nil
+	case OperatorGreaterOrEqual:
+		value := format(r.start)
+		if !timestamp {
+			value = format(r.start.AddDate(0, 0, -1))
+		}
+		return &dateCriterionValue{value: value, modifier: models.CriterionModifierGreaterThan}, nil
+	case OperatorLessThan:
+		return &dateCriterionValue{value: format(r.start), modifier: models.CriterionModifierLessThan}, nil
+	default:
+		// OperatorLessOrEqual
+		return &dateCriterionValue{value: dayAfter, modifier: models.CriterionModifierLessThan}, nil
+	}
+}
+
+func stringCriterion(t Term) (*models.StringCriterionInput, error) {
+	ret := &models.StringCriterionInput{
+		Value: t.Raw,
+	}
+
+	if len(t.Values) == 1 && isAny(t.Values[0]) {
+		modifier, err := anyModifier(t)
+		if err != nil {
+			return nil, err
+		}
+		return &models.StringCriterionInput{Modifier: modifier}, nil
+	}
+
+	switch t.Operator {
+	case OperatorIncludes:
+		ret.Modifier = models.CriterionModifierIncludes
+		if t.Negate {
+			ret.Modifier = models.CriterionModifierExcludes
+		}
+	case OperatorEquals, OperatorNotEquals:
+		equals := (t.Operator == OperatorEquals) != t.Negate
+		ret.Modifier = models.CriterionModifierEquals
+		if !equals {
+			ret.Modifier = models.CriterionModifierNotEquals
+		}
+	case OperatorMatches:
+		ret.Modifier = models.CriterionModifierMatchesRegex
+		if t.Negate {
+			ret.Modifier = models.CriterionModifierNotMatchesRegex
+		}
+	default:
+		return nil, unsupportedOperator(t)
+	}
+
+	return ret, nil
+}
+
+func boolValue(t Term) (*bool, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret bool
+	switch strings.ToLower(v.Text) {
+	case "true", "yes", "1":
+		ret = true
+	case "false", "no", "0":
+		ret = false
+	default:
+		return nil, errorf(v.Pos, "invalid boolean %q, expected true or false", v.Text)
+	}
+
+	switch t.Operator {
+	case OperatorIncludes, OperatorEquals:
+	case OperatorNotEquals:
+		ret = !ret
+	default:
+		return nil, unsupportedOperator(t)
+	}
+
+	if t.Negate {
+		ret = !ret
+	}
+
+	return &ret, nil
+}
+
+func stringValue(t Term) (*string, error) {
+	if t.Negate {
+		return nil, cannotNegate(t)
+	}
+	if t.Operator != OperatorIncludes && t.Operator != OperatorEquals {
+		return nil, unsupportedOperator(t)
+	}
+
+	ret := t.Raw
+	return &ret, nil
+}
+
+// equalityModifier returns the modifier for a term which supports only
+// equality comparisons.
+func equalityModifier(t Term) (models.CriterionModifier, error) {
+	op, err := orderedOperator(t)
+	if err != nil {
+		return "", err
+	}
+
+	switch op {
+	case OperatorEquals:
+		return models.CriterionModifierEquals, nil
+	case OperatorNotEquals:
+		return models.CriterionModifierNotEquals, nil
+	}
+
+	return "", unsupportedOperator(t)
+}
+
+var resolutionAliases = map[string]models.ResolutionEnum{
+	"4K": models.ResolutionEnumFourK,
+	"5K": models.ResolutionEnumFiveK,
+	"6K": models.ResolutionEnumSixK,
+	"7K": models.ResolutionEnumSevenK,
+	"8K": models.ResolutionEnumEightK,
+}
+
+// parseResolution parses a resolution name such as FULL_HD, 4k, or a height
+// such as 1080p.
+func parseResolution(v Value) (models.ResolutionEnum, error) {
+	s := strings.ToUpper(v.Text)
+	if ret := models.ResolutionEnum(s); ret.IsValid() {
+		return ret, nil
+	}
+	if ret, ok := resolutionAliases[s]; ok {
+		return ret, nil
+	}
+
+	if height, err := strconv.Atoi(strings.TrimSuffix(s, "P")); err == nil {
+		for _, e := range models.AllResolutionEnum {
+			if e.GetMinResolution() <= height && height <= e.GetMaxResolution() {
+				return e, nil
+			}
+		}
+	}
+
+	return "", errorf(v.Pos, "invalid resolution %q", v.Text)
+}
+
+func resolutionCriterion(t Term) (*models.ResolutionCriterionInput, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := parseResolution(v)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &models.ResolutionCriterionInput{
+		Value: value,
+	}
+
+	op := t.Operator
+	if t.Negate {
+		if op != OperatorIncludes && op != OperatorEquals && op != OperatorNotEquals {
+			return nil, cannotNegate(t)
+		}
+	}
+
+	switch op {
+	case OperatorGreaterThan:
+		ret.Modifier = models.CriterionModifierGreaterThan
+	case OperatorLessThan:
+		ret.Modifier = models.CriterionModifierLessThan
+	default:
+		ret.Modifier, err = equalityModifier(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// enumValue normalises an enum value, such as non-binary to NON_BINARY.
+func enumValue(v Value) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(v.Text))
+}
+
+func genderCriterion(t Term) (*models.GenderCriterionInput, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if isAny(v) {
+		modifier, err := anyModifier(t)
+		if err != nil {
+			return nil, err
+		}
+		return &models.GenderCriterionInput{Modifier: modifier}, nil
+	}
+
+	modifier, err := equalityModifier(t)
+	if err != nil {
+		return nil, err
+	}
+
+	value := models.GenderEnum(enumValue(v))
+	if !value.IsValid() {
+		return nil, errorf(v.Pos, "invalid gender %q", v.Text)
+	}
+
+	return &models.GenderCriterionInput{
+		Value:    &value,
+		Modifier: modifier,
+	}, nil
+}
+
+func circumcisionCriterion(t Term) (*models.CircumcisionCriterionInput, error) {
+	ret := &models.CircumcisionCriterionInput{}
+	for _, v := range t.Values {
+		value := models.CircumisedEnum(enumValue(v))
+		if !value.IsValid() {
+			return nil, errorf(v.Pos, "invalid circumcision value %q", v.Text)
+		}
+		ret.Value = append(ret.Value, value)
+	}
+
+	switch t.Operator {
+	case OperatorIncludes, OperatorEquals, OperatorNotEquals:
+		excludes := (t.Operator == OperatorNotEquals) != t.Negate
+		ret.Modifier = models.CriterionModifierIncludes
+		if excludes {
+			ret.Modifier = models.CriterionModifierExcludes
+		}
+	default:
+		return nil, unsupportedOperator(t)
+	}
+
+	return ret, nil
+}
+
+func stashIDCriterion(t Term) (*models.StashIDCriterionInput, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if isAny(v) {
+		modifier, err := anyModifier(t)
+		if err != nil {
+			return nil, err
+		}
+		return &models.StashIDCriterionInput{Modifier: modifier}, nil
+	}
+
+	modifier, err := equalityModifier(t)
+	if err != nil {
+		return nil, err
+	}
+
+	value := v.Text
+	return &models.StashIDCriterionInput{
+		StashID:  &value,
+		Modifier: modifier,
+	}, nil
+}
+
+func phashDistanceCriterion(t Term) (*models.PhashDistanceCriterionInput, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if isAny(v) {
+		modifier, err := anyModifier(t)
+		if err != nil {
+			return nil, err
+		}
+		return &models.PhashDistanceCriterionInput{Modifier: modifier}, nil
+	}
+
+	modifier, err := equalityModifier(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := strconv.ParseUint(v.Text, 16, 64); err != nil {
+		return nil, errorf(v.Pos, "invalid phash %q", v.Text)
+	}
+
+	return &models.PhashDistanceCriterionInput{
+		Value:    v.Text,
+		Modifier: modifier,
+	}, nil
+}
+
+func phashSimilarityCriterion(t Term) (*models.PhashSimilarityCriterionInput, error) {
+	v, err := singleValue(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Negate {
+		return nil, cannotNegate(t)
+	}
+	if t.Operator != OperatorIncludes && t.Operator != OperatorEquals {
+		return nil, unsupportedOperator(t)
+	}
+
+	if _, err := strconv.Atoi(v.Text); err != nil {
+		return nil, errorf(v.Pos, "invalid id %q", v.Text)
+	}
+
+	return &models.PhashSimilarityCriterionInput{
+		ID: v.Text,
+	}, nil
+}
@@ -0,0 +1,277 @@
+package filterquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// fieldAliases maps convenient query keys to the filter field they refer to.
+// An alias is only used if the filter type has the target field.
+var fieldAliases = map[string]string{
+	"performer": "performers",
+	"tag":       "tags",
+	"studio":    "studios",
+	"movie":     "movies",
+	"gallery":   "galleries",
+	"scene":     "scenes",
+	"parent":    "parents",
+	"child":     "children",
+	"rating":    "rating100",
+	"phash":     "phash_distance",
+}
+
+var (
+	intCriterionType             = reflect.TypeOf(&models.IntCriterionInput{})
+	stringCriterionType          = reflect.TypeOf(&models.StringCriterionInput{})
+	dateCriterionType            = reflect.TypeOf(&models.DateCriterionInput{})
+	timestampCriterionType       = reflect.TypeOf(&models.TimestampCriterionInput{})
+	multiCriterionType           = reflect.TypeOf(&models.MultiCriterionInput{})
+	hierarchicalCriterionType    = reflect.TypeOf(&models.HierarchicalMultiCriterionInput{})
+	resolutionCriterionType      = reflect.TypeOf(&models.ResolutionCriterionInput{})
+	genderCriterionType          = reflect.TypeOf(&models.GenderCriterionInput{})
+	circumcisionCriterionType    = reflect.TypeOf(&models.CircumcisionCriterionInput{})
+	stashIDCriterionType         = reflect.TypeOf(&models.StashIDCriterionInput{})
+	phashDistanceCriterionType   = reflect.TypeOf(&models.PhashDistanceCriterionInput{})
+	phashDuplicatedCriterionType = reflect.TypeOf(&models.PHashDuplicationCriterionInput{})
+	phashSimilarCriterionType    = reflect.TypeOf(&models.PhashSimilarityCriterionInput{})
+	boolType                     = reflect.TypeOf((*bool)(nil))
+	stringType                   = reflect.TypeOf((*string)(nil))
+)
+
+// filterKinds maps filter types to the kind of object they filter. This is
+// used to resolve names for fields such as parents and children.
+var filterKinds = map[reflect.Type]objectKind{
+	reflect.TypeOf(models.SceneFilterType{}):       kindScene,
+	reflect.TypeOf(models.ImageFilterType{}):       kindImage,
+	reflect.TypeOf(models.GalleryFilterType{}):     kindGallery,
+	reflect.TypeOf(models.PerformerFilterType{}):   kindPerformer,
+	reflect.TypeOf(models.StudioFilterType{}):      kindStudio,
+	reflect.TypeOf(models.TagFilterType{}):         kindTag,
+	reflect.TypeOf(models.MovieFilterType{}):       kindMovie,
+	reflect.TypeOf(models.SceneMarkerFilterType{}): kindSceneMarker,
+}
+
+// NewFilter returns a pointer to a new filter of the type used by mode.
+func NewFilter(mode models.FilterMode) (interface{}, error) {
+	switch mode {
+	case models.FilterModeScenes:
+		return &models.SceneFilterType{}, nil
+	case models.FilterModeImages:
+		return &models.ImageFilterType{}, nil
+	case models.FilterModeGalleries:
+		return &models.GalleryFilterType{}, nil
+	case models.FilterModePerformers:
+		return &models.PerformerFilterType{}, nil
+	case models.FilterModeStudios:
+		return &models.StudioFilterType{}, nil
+	case models.FilterModeTags:
+		return &models.TagFilterType{}, nil
+	case models.FilterModeMovies:
+		return &models.MovieFilterType{}, nil
+	case models.FilterModeSceneMarkers:
+		return &models.SceneMarkerFilterType{}, nil
+	}
+
+	return nil, fmt.Errorf("invalid filter mode %q", mode)
+}
+
+// Validate checks that query is valid for the filter type used by mode.
+// Names of related objects are not resolved.
+func Validate(mode models.FilterMode, query string) error {
+	filter, err := NewFilter(mode)
+	if err != nil {
+		return err
+	}
+
+	_, err = Apply(context.TODO(), query, filter, nil)
+	return err
+}
+
+// Apply parses query and applies its criteria to filter, which must be a
+// pointer to one of the filter types. It returns the free text of the query.
+//
+// Criteria for fields which are already set in filter are added to its AND
+// sub-filter. If r is nil, names of related objects are not resolved.
+func Apply(ctx context.Context, query string, filter interface{}, r *Resolver) (string, error) {
+	q, err := Parse(query)
+	if err != nil {
+		return "", err
+	}
+
+	if err := q.Apply(ctx, filter, r); err != nil {
+		return "", err
+	}
+
+	return q.Text(), nil
+}
+
+// Apply applies the criteria of the query to filter. See Apply.
+func (q Query) Apply(ctx context.Context, filter interface{}, r *Resolver) error {
+	v := reflect.ValueOf(filter)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("filter must be a non-nil pointer to a filter type")
+	}
+	v = v.Elem()
+
+	fields := filterFields(v.Type())
+	kind := filterKinds[v.Type()]
+
+	// related object criteria cannot be combined using sub-filters, so
+	// terms for the same field are merged into a single criterion
+	var multiFields []string
+	multiTerms := make(map[string][]Term)
+
+	for _, t := range q.Criteria() {
+		name, index, ok := lookupField(fields, t.Key)
+		if !ok {
+			return errorf(t.Pos, "unknown field %q", t.Key)
+		}
+
+		fieldType := v.Type().Field(index).Type
+		if fieldType == multiCriterionType || fieldType == hierarchicalCriterionType {
+			if _, found := multiTerms[name]; !found {
+				multiFields = append(multiFields, name)
+			}
+			multiTerms[name] = append(multiTerms[name], t)
+			continue
+		}
+
+		c, err := convertTerm(t, name, fieldType)
+		if err != nil {
+			return err
+		}
+
+		setField(v, index, reflect.ValueOf(c))
+	}
+
+	for _, name := range multiFields {
+		index := fields[name]
+		terms := multiTerms[name]
+
+		if !v.Field(index).IsNil() {
+			return errorf(terms[0].Pos, "field %q is already set by the filter", name)
+		}
+
+		m, err := mergeMultiTerms(ctx, terms, relatedKind(name, kind), r)
+		if err != nil {
+			return err
+		}
+
+		var c interface{} = m
+		if v.Type().Field(index).Type == hierarchicalCriterionType {
+			c = &models.HierarchicalMultiCriterionInput{
+				Value:    m.Value,
+				Modifier: m.Modifier,
+				Excludes: m.Excludes,
+			}
+		}
+
+		v.Field(index).Set(reflect.ValueOf(c))
+	}
+
+	return nil
+}
+
+// filterFields returns the indexes of the fields of a filter type, keyed by
+// json name. The AND, OR and NOT sub-filters are excluded.
+func filterFields(t reflect.Type) map[string]int {
+	ret := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "AND" || name == "OR" || name == "NOT" {
+			continue
+		}
+		ret[name] = i
+	}
+
+	return ret
+}
+
+func lookupField(fields map[string]int, key string) (string, int, bool) {
+	if alias, ok := fieldAliases[key]; ok {
+		if index, ok := fields[alias]; ok {
+			return alias, index, true
+		}
+	}
+
+	index, ok := fields[key]
+	return key, index, ok
+}
+
+// setField sets the field at index, adding it to the AND sub-filter if
+// it is already set.
+func setField(v reflect.Value, index int, value reflect.Value) {
+	for !v.Field(index).IsNil() {
+		and := v.FieldByName("And")
+		if and.IsNil() {
+			and.Set(reflect.New(v.Type()))
+		}
+		v = and.Elem()
+	}
+
+	v.Field(index).Set(value)
+}
+
+func convertTerm(t Term, name string, fieldType reflect.Type) (interface{}, error) {
+	switch fieldType {
+	case intCriterionType:
+		parse := parseInt
+		if strings.Contains(name, "duration") {
+			parse = parseDuration
+		}
+		return intCriterion(t, parse)
+	case stringCriterionType:
+		return stringCriterion(t)
+	case dateCriterionType:
+		c, err := dateCriterion(t, false)
+		if err != nil {
+			return nil, err
+		}
+		return &models.DateCriterionInput{
+			Value:    c.value,
+			Value2:   c.value2,
+			Modifier: c.modifier,
+		}, nil
+	case timestampCriterionType:
+		c, err := dateCriterion(t, true)
+		if err != nil {
+			return nil, err
+		}
+		return &models.TimestampCriterionInput{
+			Value:    c.value,
+			Value2:   c.value2,
+			Modifier: c.modifier,
+		}, nil
+	case boolType:
+		return boolValue(t)
+	case stringType:
+		return stringValue(t)
+	case resolutionCriterionType:
+		return resolutionCriterion(t)
+	case genderCriterionType:
+		return genderCriterion(t)
+	case circumcisionCriterionType:
+		return circumcisionCriterion(t)
+	case stashIDCriterionType:
+		return stashIDCriterion(t)
+	case phashDistanceCriterionType:
+		return phashDistanceCriterion(t)
+	case phashDuplicatedCriterionType:
+		b, err := boolValue(t)
+		if err != nil {
+			return nil, err
+		}
+		return &models.PHashDuplicationCriterionInput{
+			Duplicated: b,
+		}, nil
+	case phashSimilarCriterionType:
+		return phashSimilarityCriterion(t)
+	}
+
+	return nil, errorf(t.Pos, "field %q cannot be used in a query", t.Key)
+}
@@ -0,0 +1,309 @@
+package filterquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func testResolver() *Resolver {
+	performerReader := &mocks.PerformerReaderWriter{}
+	performerReader.On("FindByNames", mock.Anything, []string{"Jane Doe"}, true).Return([]*models.Performer{{ID: 1}}, nil)
+	performerReader.On("FindByNames", mock.Anything, mock.Anything, true).Return(nil, nil)
+
+	tagReader := &mocks.TagReaderWriter{}
+	tagReader.On("FindByNames", mock.Anything, []string{"outdoor"}, true).Return([]*models.Tag{{ID: 2}}, nil)
+	tagReader.On("FindByNames", mock.Anything, []string{"vr"}, true).Return([]*models.Tag{{ID: 3}}, nil)
+	tagReader.On("FindByNames", mock.Anything, []string{"beach"}, true).Return([]*models.Tag{{ID: 4}}, nil)
+
+	studioReader := &mocks.StudioReaderWriter{}
+	studioReader.On("FindByName", mock.Anything, "Acme", true).Return(&models.Studio{ID: 5}, nil)
+
+	return &Resolver{
+		Performer: performerReader,
+		Tag:       tagReader,
+		Studio:    studioReader,
+		Movie:     &mocks.MovieReaderWriter{},
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	r := testResolver()
+
+	filter := &models.SceneFilterType{}
+	text, err := Apply(ctx, `performer:"Jane Doe" tag:outdoor -tag:vr rating>=80 date:2020..2022 duration>20m free`, filter, r)
+	if err != nil {
+		t.Errorf("Apply() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "free", text)
+	assert.Equal(t, &models.SceneFilterType{
+		Performers: &models.MultiCriterionInput{
+			Value:    []string{"1"},
+			Modifier: models.CriterionModifierIncludes,
+		},
+		Tags: &models.HierarchicalMultiCriterionInput{
+			Value:    []string{"2"},
+			Modifier: models.CriterionModifierIncludes,
+			Excludes: []string{"3"},
+		},
+		Rating100: &models.IntCriterionInput{
+			Value:    79,
+			Modifier: models.CriterionModifierGreaterThan,
+		},
+		Date: &models.DateCriterionInput{
+			Value:    "2020-01-01",
+			Value2:   strPtr("2022-12-31"),
+			Modifier: models.CriterionModifierBetween,
+		},
+		Duration: &models.IntCriterionInput{
+			Value:    1200,
+			Modifier: models.CriterionModifierGreaterThan,
+		},
+	}, filter)
+}
+
+func TestApplyCriteria(t *testing.T) {
+	ctx := context.Background()
+	r := testResolver()
+
+	tests := []struct {
+		name   string
+		query  string
+		filter interface{}
+		want   interface{}
+	}{
+		{
+			"repeated fields use AND sub-filter",
+			"rating>=20 rating<=40",
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Rating100: &models.IntCriterionInput{Value: 19, Modifier: models.CriterionModifierGreaterThan},
+				And: &models.SceneFilterType{
+					Rating100: &models.IntCriterionInput{Value: 41, Modifier: models.CriterionModifierLessThan},
+				},
+			},
+		},
+		{
+			"negated comparison",
+			"-o_counter>2",
+			&models.ImageFilterType{},
+			&models.ImageFilterType{
+				OCounter: &models.IntCriterionInput{Value: 3, Modifier: models.CriterionModifierLessThan},
+			},
+		},
+		{
+			"negated range",
+			"-o_counter:1..3",
+			&models.ImageFilterType{},
+			&models.ImageFilterType{
+				OCounter: &models.IntCriterionInput{Value: 1, Value2: intPtr(3), Modifier: models.CriterionModifierNotBetween},
+			},
+		},
+		{
+			"open range",
+			"o_counter:5..",
+			&models.ImageFilterType{},
+			&models.ImageFilterType{
+				OCounter: &models.IntCriterionInput{Value: 4, Modifier: models.CriterionModifierGreaterThan},
+			},
+		},
+		{
+			"strings",
+			`title:foo -details:bar path=/a/b url~^http`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Title:   &models.StringCriterionInput{Value: "foo", Modifier: models.CriterionModifierIncludes},
+				Details: &models.StringCriterionInput{Value: "bar", Modifier: models.CriterionModifierExcludes},
+				Path:    &models.StringCriterionInput{Value: "/a/b", Modifier: models.CriterionModifierEquals},
+				URL:     &models.StringCriterionInput{Value: "^http", Modifier: models.CriterionModifierMatchesRegex},
+			},
+		},
+		{
+			"null values",
+			`-details:* studio:*`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Details: &models.StringCriterionInput{Modifier: models.CriterionModifierIsNull},
+				Studios: &models.HierarchicalMultiCriterionInput{Modifier: models.CriterionModifierNotNull},
+			},
+		},
+		{
+			"booleans",
+			`organized:yes -performer_favorite:true`,
+			&models.GalleryFilterType{},
+			&models.GalleryFilterType{
+				Organized:         boolPtr(true),
+				PerformerFavorite: boolPtr(false),
+			},
+		},
+		{
+			"single date",
+			`date:2021-03-04`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Date: &models.DateCriterionInput{Value: "2021-03-04", Modifier: models.CriterionModifierEquals},
+			},
+		},
+		{
+			"timestamp month",
+			`created_at:2021-02`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				CreatedAt: &models.TimestampCriterionInput{Value: "2021-02-01", Value2: strPtr("2021-03-01"), Modifier: models.CriterionModifierBetween},
+			},
+		},
+		{
+			"timestamp after",
+			`updated_at>2021`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				UpdatedAt: &models.TimestampCriterionInput{Value: "2022-01-01", Modifier: models.CriterionModifierGreaterThan},
+			},
+		},
+		{
+			"tags any of",
+			`tag:outdoor,beach studio:Acme`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Tags:    &models.HierarchicalMultiCriterionInput{Value: []string{"2", "4"}, Modifier: models.CriterionModifierIncludes},
+				Studios: &models.HierarchicalMultiCriterionInput{Value: []string{"5"}, Modifier: models.CriterionModifierIncludes},
+			},
+		},
+		{
+			"tags all of",
+			`tag:outdoor tag:beach`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Tags: &models.HierarchicalMultiCriterionInput{Value: []string{"2", "4"}, Modifier: models.CriterionModifierIncludesAll},
+			},
+		},
+		{
+			"excluded only",
+			`-tag:vr movies!=7`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Tags:   &models.HierarchicalMultiCriterionInput{Value: []string{"3"}, Modifier: models.CriterionModifierExcludes},
+				Movies: &models.MultiCriterionInput{Value: []string{"7"}, Modifier: models.CriterionModifierExcludes},
+			},
+		},
+		{
+			"tag parents",
+			`parent:outdoor`,
+			&models.TagFilterType{},
+			&models.TagFilterType{
+				Parents: &models.HierarchicalMultiCriterionInput{Value: []string{"2"}, Modifier: models.CriterionModifierIncludes},
+			},
+		},
+		{
+			"resolution",
+			`resolution>1080p`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Resolution: &models.ResolutionCriterionInput{Value: models.ResolutionEnumFullHd, Modifier: models.CriterionModifierGreaterThan},
+			},
+		},
+		{
+			"gender",
+			`-gender:non-binary`,
+			&models.PerformerFilterType{},
+			&models.PerformerFilterType{
+				Gender: &models.GenderCriterionInput{Value: genderPtr(models.GenderEnumNonBinary), Modifier: models.CriterionModifierNotEquals},
+			},
+		},
+		{
+			"phash",
+			`duplicated:true phash_similar_to:12`,
+			&models.SceneFilterType{},
+			&models.SceneFilterType{
+				Duplicated:     &models.PHashDuplicationCriterionInput{Duplicated: boolPtr(true)},
+				PhashSimilarTo: &models.PhashSimilarityCriterionInput{ID: "12"},
+			},
+		},
+		{
+			"existing filter",
+			`rating:50`,
+			&models.SceneFilterType{
+				Rating100: &models.IntCriterionInput{Value: 10, Modifier: models.CriterionModifierGreaterThan},
+			},
+			&models.SceneFilterType{
+				Rating100: &models.IntCriterionInput{Value: 10, Modifier: models.CriterionModifierGreaterThan},
+				And: &models.SceneFilterType{
+					Rating100: &models.IntCriterionInput{Value: 50, Modifier: models.CriterionModifierEquals},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Apply(ctx, tt.query, tt.filter, r); err != nil {
+				t.Errorf("Apply() error = %v", err)
+				return
+			}
+
+			assert.Equal(t, tt.want, tt.filter)
+		})
+	}
+}
+
+func genderPtr(g models.GenderEnum) *models.GenderEnum {
+	return &g
+}
+
+func TestApplyErrors(t *testing.T) {
+	ctx := context.Background()
+	r := testResolver()
+
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"foo:bar", 1},
+		{"rating:abc", 8},
+		{"rating~5", 1},
+		{"rating:1,2", 10},
+		{"date:2020-13", 6},
+		{"performer:Nobody", 11},
+		{"phash_similar_to:foo", 18},
+		{"rating:..", 8},
+		{"tag:outdoor,beach tag:vr", 19},
+		{"is_missing:date -is_missing:url", 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Apply(ctx, tt.query, &models.SceneFilterType{}, r)
+			var qerr *Error
+			if !assert.ErrorAs(t, err, &qerr) {
+				return
+			}
+
+			assert.Equal(t, tt.pos, qerr.Pos)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(models.FilterModeScenes, `performer:"Jane Doe" rating>50`))
+	assert.Error(t, Validate(models.FilterModeTags, `performer_count>1 rating>50`))
+	assert.Error(t, Validate(models.FilterMode("invalid"), ""))
+}
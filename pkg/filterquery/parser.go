@@ -0,0 +1,305 @@
+// Package filterquery provides a compact textual query language which is
+// parsed into the filter types used to query objects.
+//
+// A query is a whitespace separated list of terms. A term is either free
+// text, which is searched for in the same way as the search box, or a
+// criterion of the form key, operator, value:
+//
+//	performer:"Jane Doe" tag:outdoor -tag:vr rating>=80 date:2020..2022 duration>20m
+//
+// Terms prefixed with - are negated. Values may be quoted, may be comma
+// separated lists, and for ordered fields may be ranges of the form a..b,
+// a.. or ..b.
+package filterquery
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Operator is the comparison operator of a criterion term.
+type Operator string
+
+const (
+	OperatorIncludes       Operator = ":"
+	OperatorEquals         Operator = "="
+	OperatorNotEquals      Operator = "!="
+	OperatorGreaterThan    Operator = ">"
+	OperatorGreaterOrEqual Operator = ">="
+	OperatorLessThan       Operator = "<"
+	OperatorLessOrEqual    Operator = "<="
+	OperatorMatches        Operator = "~"
+)
+
+// Error is returned when a query cannot be parsed or applied. Pos is the
+// 1-based position of the offending character in the query.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query error at position %d: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	}
+}
+
+// Value is a single value of a criterion term.
+type Value struct {
+	Pos  int
+	Text string
+	// Quoted is true if any part of the value was quoted
+	Quoted bool
+}
+
+// Range splits the value into its lower and upper bounds if it is of the
+// form a..b. Either bound may be empty for an open range.
+func (v Value) Range() (lower string, upper string, ok bool) {
+	if v.Quoted {
+		return "", "", false
+	}
+
+	return strings.Cut(v.Text, "..")
+}
+
+// Term is a single term of a query.
+type Term struct {
+	Pos    int
+	Negate bool
+	// Key is empty for free text terms.
+	Key      string
+	Operator Operator
+	// Raw is the unquoted value text, including any commas.
+	Raw    string
+	Values []Value
+}
+
+// Query is a parsed query.
+type Query struct {
+	Terms []Term
+}
+
+// Text returns the free text terms of the query, as they should be passed
+// to the search term of the find filter.
+func (q Query) Text() string {
+	var parts []string
+	for _, t := range q.Terms {
+		if t.Key != "" {
+			continue
+		}
+
+		s := t.Raw
+		if t.Values[0].Quoted {
+			s = `"` + s + `"`
+		}
+		if t.Negate {
+			s = "-" + s
+		}
+		parts = append(parts, s)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Criteria returns the criterion terms of the query.
+func (q Query) Criteria() []Term {
+	var ret []Term
+	for _, t := range q.Terms {
+		if t.Key != "" {
+			ret = append(ret, t)
+		}
+	}
+
+	return ret
+}
+
+// Parse parses a query string.
+func Parse(s string) (*Query, error) {
+	p := parser{input: []rune(s)}
+	return p.parse()
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func isOperatorStart(r rune) bool {
+	return strings.ContainsRune(":=!<>~", r)
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) parse() (*Query, error) {
+	ret := &Query{}
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return ret, nil
+		}
+
+		t, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		ret.Terms = append(ret.Terms, *t)
+	}
+}
+
+func (p *parser) parseTerm() (*Term, error) {
+	ret := &Term{
+		Pos: p.pos + 1,
+	}
+
+	if p.peek() == '-' {
+		ret.Negate = true
+		p.pos++
+		if p.eof() || unicode.IsSpace(p.peek()) {
+			return nil, errorf(ret.Pos, "expected term after '-'")
+		}
+	}
+
+	if p.peek() == '"' {
+		// quoted free text
+		return p.parseValues(ret, false)
+	}
+
+	keyStart := p.pos
+	for !p.eof() && !unicode.IsSpace(p.peek()) && !isOperatorStart(p.peek()) && p.peek() != '"' {
+		p.pos++
+	}
+	key := string(p.input[keyStart:p.pos])
+
+	if p.eof() || !isOperatorStart(p.peek()) {
+		// free text - reparse as a value
+		p.pos = keyStart
+		return p.parseValues(ret, false)
+	}
+
+	if key == "" {
+		return nil, errorf(p.pos+1, "expected field name before %q", string(p.peek()))
+	}
+
+	ret.Key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+
+	opPos := p.pos + 1
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	ret.Operator = op
+
+	if p.eof() || unicode.IsSpace(p.peek()) {
+		return nil, errorf(opPos, "expected value after %q", string(op))
+	}
+
+	return p.parseValues(ret, true)
+}
+
+func (p *parser) parseOperator() (Operator, error) {
+	start := p.pos
+	r := p.peek()
+	p.pos++
+
+	switch r {
+	case ':', '=', '~':
+		return Operator(r), nil
+	case '<', '>':
+		if p.peek() == '=' {
+			p.pos++
+			return Operator(string(p.input[start:p.pos])), nil
+		}
+		return Operator(r), nil
+	case '!':
+		if p.peek() == '=' {
+			p.pos++
+			return OperatorNotEquals, nil
+		}
+	}
+
+	return "", errorf(start+1, "invalid operator %q", string(p.input[start:p.pos]))
+}
+
+// parseValues parses the value text of a term up to the next whitespace.
+// Quoted sections may contain whitespace and escaped quotes. If split is
+// true, unquoted commas separate values.
+func (p *parser) parseValues(t *Term, split bool) (*Term, error) {
+	var raw strings.Builder
+	current := Value{Pos: p.pos + 1}
+	var text strings.Builder
+
+	finish := func() {
+		current.Text = text.String()
+		t.Values = append(t.Values, current)
+		text.Reset()
+	}
+
+	for !p.eof() && !unicode.IsSpace(p.peek()) {
+		r := p.peek()
+		switch {
+		case r == '"':
+			quoteStart := p.pos + 1
+			p.pos++
+			closed := false
+			for !p.eof() {
+				r = p.peek()
+				p.pos++
+				if r == '\\' && !p.eof() {
+					r = p.peek()
+					p.pos++
+				} else if r == '"' {
+					closed = true
+					break
+				}
+				text.WriteRune(r)
+				raw.WriteRune(r)
+			}
+			if !closed {
+				return nil, errorf(quoteStart, "unterminated quoted string")
+			}
+			current.Quoted = true
+		case r == ',' && split:
+			p.pos++
+			finish()
+			raw.WriteRune(r)
+			current = Value{Pos: p.pos + 1}
+		default:
+			p.pos++
+			text.WriteRune(r)
+			raw.WriteRune(r)
+		}
+	}
+
+	finish()
+	t.Raw = raw.String()
+
+	for _, v := range t.Values {
+		if v.Text == "" && !v.Quoted {
+			return nil, errorf(v.Pos, "empty value")
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,116 @@
+package filterquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []Term
+	}{
+		{
+			"empty",
+			"   ",
+			nil,
+		},
+		{
+			"free text",
+			`foo -bar "baz qux"`,
+			[]Term{
+				{Pos: 1, Raw: "foo", Values: []Value{{Pos: 1, Text: "foo"}}},
+				{Pos: 5, Negate: true, Raw: "bar", Values: []Value{{Pos: 6, Text: "bar"}}},
+				{Pos: 10, Raw: "baz qux", Values: []Value{{Pos: 10, Text: "baz qux", Quoted: true}}},
+			},
+		},
+		{
+			"criteria",
+			`performer:"Jane Doe" -tag:vr rating>=80 Date:2020..2022`,
+			[]Term{
+				{Pos: 1, Key: "performer", Operator: OperatorIncludes, Raw: "Jane Doe", Values: []Value{{Pos: 11, Text: "Jane Doe", Quoted: true}}},
+				{Pos: 22, Negate: true, Key: "tag", Operator: OperatorIncludes, Raw: "vr", Values: []Value{{Pos: 27, Text: "vr"}}},
+				{Pos: 30, Key: "rating", Operator: OperatorGreaterOrEqual, Raw: "80", Values: []Value{{Pos: 38, Text: "80"}}},
+				{Pos: 41, Key: "date", Operator: OperatorIncludes, Raw: "2020..2022", Values: []Value{{Pos: 46, Text: "2020..2022"}}},
+			},
+		},
+		{
+			"lists",
+			`tag:a,"b,c" url:http://example.com`,
+			[]Term{
+				{Pos: 1, Key: "tag", Operator: OperatorIncludes, Raw: "a,b,c", Values: []Value{{Pos: 5, Text: "a"}, {Pos: 7, Text: "b,c", Quoted: true}}},
+				{Pos: 13, Key: "url", Operator: OperatorIncludes, Raw: "http://example.com", Values: []Value{{Pos: 17, Text: "http://example.com"}}},
+			},
+		},
+		{
+			"escaped quote",
+			`title="say \"hi\""`,
+			[]Term{
+				{Pos: 1, Key: "title", Operator: OperatorEquals, Raw: `say "hi"`, Values: []Value{{Pos: 7, Text: `say "hi"`, Quoted: true}}},
+			},
+		},
+		{
+			"operators",
+			`a!=1 b<2 c<=3 d>4 e~5 play-count=6`,
+			[]Term{
+				{Pos: 1, Key: "a", Operator: OperatorNotEquals, Raw: "1", Values: []Value{{Pos: 4, Text: "1"}}},
+				{Pos: 6, Key: "b", Operator: OperatorLessThan, Raw: "2", Values: []Value{{Pos: 8, Text: "2"}}},
+				{Pos: 10, Key: "c", Operator: OperatorLessOrEqual, Raw: "3", Values: []Value{{Pos: 13, Text: "3"}}},
+				{Pos: 15, Key: "d", Operator: OperatorGreaterThan, Raw: "4", Values: []Value{{Pos: 17, Text: "4"}}},
+				{Pos: 19, Key: "e", Operator: OperatorMatches, Raw: "5", Values: []Value{{Pos: 21, Text: "5"}}},
+				{Pos: 23, Key: "play_count", Operator: OperatorEquals, Raw: "6", Values: []Value{{Pos: 34, Text: "6"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.query)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			assert.Equal(t, tt.want, got.Terms)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"foo - bar", 5},
+		{"tag:", 4},
+		{"tag: foo", 4},
+		{`title:"unterminated`, 7},
+		{":foo", 1},
+		{"a!1", 2},
+		{"tag:a,,b", 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var qerr *Error
+			if !assert.ErrorAs(t, err, &qerr) {
+				return
+			}
+
+			assert.Equal(t, tt.pos, qerr.Pos)
+		})
+	}
+}
+
+func TestQuery_Text(t *testing.T) {
+	q, err := Parse(`foo tag:bar -baz "qux quux" rating>1`)
+	if err != nil {
+		t.Errorf("Parse() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, `foo -baz "qux quux"`, q.Text())
+}
@@ -0,0 +1,203 @@
+package filterquery
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type objectKind string
+
+const (
+	kindScene       objectKind = "scene"
+	kindImage       objectKind = "image"
+	kindGallery     objectKind = "gallery"
+	kindPerformer   objectKind = "performer"
+	kindStudio      objectKind = "studio"
+	kindTag         objectKind = "tag"
+	kindMovie       objectKind = "movie"
+	kindSceneMarker objectKind = "scene marker"
+)
+
+// relatedKind returns the kind of object referenced by the named field of a
+// filter for objects of kind own.
+func relatedKind(name string, own objectKind) objectKind {
+	switch {
+	case strings.Contains(name, "tag"):
+		return kindTag
+	case strings.Contains(name, "performer"):
+		return kindPerformer
+	case strings.Contains(name, "studio"):
+		return kindStudio
+	case strings.Contains(name, "movie"):
+		return kindMovie
+	case strings.Contains(name, "galler"):
+		return kindGallery
+	case strings.Contains(name, "scene"):
+		return kindScene
+	case strings.Contains(name, "parent"), strings.Contains(name, "child"):
+		return own
+	}
+
+	return ""
+}
+
+type PerformerFinder interface {
+	FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Performer, error)
+}
+
+type StudioFinder interface {
+	FindByName(ctx context.Context, name string, nocase bool) (*models.Studio, error)
+}
+
+type TagFinder interface {
+	FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Tag, error)
+}
+
+type MovieFinder interface {
+	FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Movie, error)
+}
+
+// Resolver resolves the names of related objects in queries to ids.
+// Names are matched case-insensitively.
+type Resolver struct {
+	Performer PerformerFinder
+	Studio    StudioFinder
+	Tag       TagFinder
+	Movie     MovieFinder
+}
+
+// resolve returns the ids of the objects of the given kind named by v.
+// Numeric values are treated as ids.
+func (r *Resolver) resolve(ctx context.Context, kind objectKind, v Value) ([]string, error) {
+	if _, err := strconv.Atoi(v.Text); err == nil && !v.Quoted {
+		return []string{v.Text}, nil
+	}
+
+	if r == nil {
+		// names are not resolved when validating
+		return []string{v.Text}, nil
+	}
+
+	var ids []int
+	switch kind {
+	case kindPerformer:
+		performers, err := r.Performer.FindByNames(ctx, []string{v.Text}, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range performers {
+			ids = append(ids, p.ID)
+		}
+	case kindStudio:
+		studio, err := r.Studio.FindByName(ctx, v.Text, true)
+		if err != nil {
+			return nil, err
+		}
+		if studio != nil {
+			ids = append(ids, studio.ID)
+		}
+	case kindTag:
+		tags, err := r.Tag.FindByNames(ctx, []string{v.Text}, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			ids = append(ids, t.ID)
+		}
+	case kindMovie:
+		movies, err := r.Movie.FindByNames(ctx, []string{v.Text}, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range movies {
+			ids = append(ids, m.ID)
+		}
+	case "":
+		return nil, errorf(v.Pos, "invalid id %q", v.Text)
+	default:
+		return nil, errorf(v.Pos, "invalid %s id %q", kind, v.Text)
+	}
+
+	if len(ids) == 0 {
+		return nil, errorf(v.Pos, "no %s named %q", kind, v.Text)
+	}
+
+	var ret []string
+	for _, id := range ids {
+		ret = append(ret, strconv.Itoa(id))
+	}
+
+	return ret, nil
+}
+
+// mergeMultiTerms merges the terms for a related object field into a single
+// criterion. Separate terms must all match, while the comma separated
+// values of a single term match if any matches.
+func mergeMultiTerms(ctx context.Context, terms []Term, kind objectKind, r *Resolver) (*models.MultiCriterionInput, error) {
+	ret := &models.MultiCriterionInput{}
+	includeTerms := 0
+	anyOf := false
+
+	for _, t := range terms {
+		exclude := t.Negate
+		switch t.Operator {
+		case OperatorIncludes, OperatorEquals:
+		case OperatorNotEquals:
+			exclude = !exclude
+		default:
+			return nil, unsupportedOperator(t)
+		}
+
+		if len(t.Values) == 1 && isAny(t.Values[0]) {
+			if len(terms) > 1 {
+				return nil, errorf(t.Pos, "%q cannot be combined with other values for field %q", anyValue, t.Key)
+			}
+
+			ret.Modifier = models.CriterionModifierNotNull
+			if exclude {
+				ret.Modifier = models.CriterionModifierIsNull
+			}
+			return ret, nil
+		}
+
+		var ids []string
+		for _, v := range t.Values {
+			valueIDs, err := r.resolve(ctx, kind, v)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, valueIDs...)
+		}
+
+		if exclude {
+			ret.Excludes = append(ret.Excludes, ids...)
+			continue
+		}
+
+		includeTerms++
+		if len(t.Values) > 1 {
+			anyOf = true
+		}
+		if anyOf && includeTerms > 1 {
+			return nil, errorf(t.Pos, "a list of values cannot be combined with other values for field %q", t.Key)
+		}
+
+		ret.Value = append(ret.Value, ids...)
+	}
+
+	switch {
+	case len(ret.Value) == 0:
+		ret.Modifier = models.CriterionModifierExcludes
+		ret.Value = ret.Excludes
+		ret.Excludes = nil
+	case anyOf || includeTerms == 1:
+		ret.Modifier = models.CriterionModifierIncludes
+	default:
+		ret.Modifier = models.CriterionModifierIncludesAll
+	}
+
+	return ret, nil
+}
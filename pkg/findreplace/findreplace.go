@@ -0,0 +1,276 @@
+// Package findreplace provides functions for replacing the matches of a
+// regular expression in a metadata field of many objects.
+package findreplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+const batchSize = 1000
+
+type EntityType string
+
+const (
+	EntityTypeScene     EntityType = "SCENE"
+	EntityTypeImage     EntityType = "IMAGE"
+	EntityTypeGallery   EntityType = "GALLERY"
+	EntityTypePerformer EntityType = "PERFORMER"
+	EntityTypeStudio    EntityType = "STUDIO"
+	EntityTypeMovie     EntityType = "MOVIE"
+	EntityTypeTag       EntityType = "TAG"
+)
+
+var AllEntityType = []EntityType{
+	EntityTypeScene,
+	EntityTypeImage,
+	EntityTypeGallery,
+	EntityTypePerformer,
+	EntityTypeStudio,
+	EntityTypeMovie,
+	EntityTypeTag,
+}
+
+func (e EntityType) IsValid() bool {
+	switch e {
+	case EntityTypeScene, EntityTypeImage, EntityTypeGallery, EntityTypePerformer, EntityTypeStudio, EntityTypeMovie, EntityTypeTag:
+		return true
+	}
+	return false
+}
+
+func (e EntityType) String() string {
+	return string(e)
+}
+
+func (e *EntityType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EntityType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BulkFindReplaceEntityType", str)
+	}
+	return nil
+}
+
+func (e EntityType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Field string
+
+const (
+	FieldTitle Field = "TITLE"
+	// FieldDetails is the details of scenes, galleries, performers and
+	// studios, the synopsis of movies and the description of tags.
+	FieldDetails  Field = "DETAILS"
+	FieldCode     Field = "CODE"
+	FieldDirector Field = "DIRECTOR"
+	FieldURL      Field = "URL"
+	// FieldAliases is replaced in each alias of performers, studios and
+	// tags, and in the aliases string of movies.
+	FieldAliases Field = "ALIASES"
+)
+
+var AllField = []Field{
+	FieldTitle,
+	FieldDetails,
+	FieldCode,
+	FieldDirector,
+	FieldURL,
+	FieldAliases,
+}
+
+func (e Field) IsValid() bool {
+	switch e {
+	case FieldTitle, FieldDetails, FieldCode, FieldDirector, FieldURL, FieldAliases:
+		return true
+	}
+	return false
+}
+
+func (e Field) String() string {
+	return string(e)
+}
+
+func (e *Field) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Field(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BulkFindReplaceField", str)
+	}
+	return nil
+}
+
+func (e Field) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// entityFields are the fields which may be replaced for each entity type.
+var entityFields = map[EntityType][]Field{
+	EntityTypeScene:     {FieldTitle, FieldDetails, FieldCode, FieldDirector, FieldURL},
+	EntityTypeImage:     {FieldTitle, FieldURL},
+	EntityTypeGallery:   {FieldTitle, FieldDetails, FieldURL},
+	EntityTypePerformer: {FieldDetails, FieldURL, FieldAliases},
+	EntityTypeStudio:    {FieldDetails, FieldURL, FieldAliases},
+	EntityTypeMovie:     {FieldDetails, FieldDirector, FieldURL, FieldAliases},
+	EntityTypeTag:       {FieldDetails, FieldAliases},
+}
+
+var ErrEmptyPattern = errors.New("pattern must not be empty")
+
+// Options are the options of a find and replace.
+type Options struct {
+	EntityType EntityType
+	Field      Field
+	// Pattern is a regular expression in RE2 syntax.
+	Pattern string
+	// Replacement replaces each match of Pattern. $1 or ${name} in the
+	// replacement are replaced by the submatches of the pattern.
+	Replacement string
+}
+
+// Change is a value of a field of an object changed by a find and replace.
+type Change struct {
+	ID       int    `json:"id"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// FindReplace replaces the matches of a regular expression in a field of
+// objects.
+type FindReplace struct {
+	Repository  Repository
+	EntityType  EntityType
+	Field       Field
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// New returns a FindReplace for the options, or an error if the field is
+// not supported for the entity type or the pattern is invalid.
+func New(repo Repository, options Options) (*FindReplace, error) {
+	if !options.EntityType.IsValid() {
+		return nil, fmt.Errorf("invalid entity type %q", options.EntityType)
+	}
+
+	if !SupportsField(options.EntityType, options.Field) {
+		return nil, fmt.Errorf("field %s is not supported for %s objects", options.Field, options.EntityType)
+	}
+
+	if options.Pattern == "" {
+		return nil, ErrEmptyPattern
+	}
+
+	pattern, err := regexp.Compile(options.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	return &FindReplace{
+		Repository:  repo,
+		EntityType:  options.EntityType,
+		Field:       options.Field,
+		Pattern:     pattern,
+		Replacement: options.Replacement,
+	}, nil
+}
+
+// SupportsField returns true if the field may be replaced for objects of
+// the entity type.
+func SupportsField(entityType EntityType, field Field) bool {
+	for _, f := range entityFields[entityType] {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replace returns the values with the matches of the pattern replaced, and
+// the changed values. Empty and duplicate values are removed from lists of
+// values.
+func (f *FindReplace) replace(id int, values []string, list bool) ([]string, []Change) {
+	var ret []string
+	var changes []Change
+	for _, v := range values {
+		newValue := f.Pattern.ReplaceAllString(v, f.Replacement)
+		if newValue != v {
+			changes = append(changes, Change{
+				ID:       id,
+				OldValue: v,
+				NewValue: newValue,
+			})
+		}
+
+		if list {
+			if newValue != "" {
+				ret = stringslice.StrAppendUnique(ret, newValue)
+			}
+		} else {
+			ret = append(ret, newValue)
+		}
+	}
+
+	return ret, changes
+}
+
+func (f *FindReplace) run(ctx context.Context, ids []int, apply bool) ([]Change, error) {
+	list := f.Field == FieldAliases && f.EntityType != EntityTypeMovie
+
+	var ret []Change
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		objects, err := f.Repository.values(ctx, f.EntityType, f.Field, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range objects {
+			newValues, changes := f.replace(o.id, o.values, list)
+			if len(changes) == 0 {
+				continue
+			}
+
+			if apply {
+				if err := f.Repository.update(ctx, f.EntityType, f.Field, o.id, newValues); err != nil {
+					return nil, fmt.Errorf("updating %s %d: %w", f.EntityType, o.id, err)
+				}
+			}
+
+			ret = append(ret, changes...)
+		}
+	}
+
+	return ret, nil
+}
+
+// Preview returns the changes which would be made to the objects with the
+// given ids, in order of the ids. It must be called within a transaction.
+func (f *FindReplace) Preview(ctx context.Context, ids []int) ([]Change, error) {
+	return f.run(ctx, ids, false)
+}
+
+// Apply makes the changes to the objects with the given ids, and returns
+// the changes in order of the ids. It must be called within a transaction.
+func (f *FindReplace) Apply(ctx context.Context, ids []int) ([]Change, error) {
+	return f.run(ctx, ids, true)
+}
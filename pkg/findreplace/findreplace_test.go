@@ -0,0 +1,133 @@
+package findreplace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{
+			"valid",
+			Options{EntityType: EntityTypeScene, Field: FieldTitle, Pattern: "^Site - "},
+			false,
+		},
+		{
+			"unsupported field",
+			Options{EntityType: EntityTypeImage, Field: FieldDetails, Pattern: "a"},
+			true,
+		},
+		{
+			"invalid entity type",
+			Options{EntityType: "FILE", Field: FieldTitle, Pattern: "a"},
+			true,
+		},
+		{
+			"empty pattern",
+			Options{EntityType: EntityTypeScene, Field: FieldTitle},
+			true,
+		},
+		{
+			"invalid pattern",
+			Options{EntityType: EntityTypeScene, Field: FieldTitle, Pattern: "("},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Repository{}, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindReplace_Scenes(t *testing.T) {
+	const (
+		prefixedID = iota + 1
+		unchangedID
+	)
+
+	db := mocks.NewTxnRepository()
+	sceneReaderWriter := db.Scene.(*mocks.SceneReaderWriter)
+	sceneReaderWriter.On("FindMany", mock.Anything, []int{prefixedID, unchangedID}).Return([]*models.Scene{
+		{ID: prefixedID, Title: "Site - Title"},
+		{ID: unchangedID, Title: "Other"},
+	}, nil)
+	sceneReaderWriter.On("UpdatePartial", mock.Anything, prefixedID, mock.MatchedBy(func(p models.ScenePartial) bool {
+		return p.Title.Set && p.Title.Value == "Title"
+	})).Return(&models.Scene{}, nil).Once()
+
+	f, err := New(Repository{Scene: db.Scene}, Options{
+		EntityType:  EntityTypeScene,
+		Field:       FieldTitle,
+		Pattern:     `^(\w+) - `,
+		Replacement: "",
+	})
+	if err != nil {
+		t.Errorf("New() error = %v", err)
+		return
+	}
+
+	want := []Change{{ID: prefixedID, OldValue: "Site - Title", NewValue: "Title"}}
+
+	ctx := context.Background()
+	ids := []int{prefixedID, unchangedID}
+
+	got, err := f.Preview(ctx, ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+	sceneReaderWriter.AssertNotCalled(t, "UpdatePartial", mock.Anything, mock.Anything, mock.Anything)
+
+	got, err = f.Apply(ctx, ids)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+	sceneReaderWriter.AssertExpectations(t)
+}
+
+func TestFindReplace_Aliases(t *testing.T) {
+	const performerID = 1
+
+	db := mocks.NewTxnRepository()
+	performerReaderWriter := db.Performer.(*mocks.PerformerReaderWriter)
+	performerReaderWriter.On("FindMany", mock.Anything, []int{performerID}).Return([]*models.Performer{
+		{ID: performerID},
+	}, nil)
+	performerReaderWriter.On("GetAliases", mock.Anything, performerID).Return([]string{"Jane (Site)", "Jane", "Site"}, nil)
+	performerReaderWriter.On("UpdatePartial", mock.Anything, performerID, mock.MatchedBy(func(p models.PerformerPartial) bool {
+		// empty and duplicate aliases are removed
+		return p.Aliases != nil && assert.ObjectsAreEqual([]string{"Jane"}, p.Aliases.Values)
+	})).Return(&models.Performer{}, nil).Once()
+
+	f, err := New(Repository{Performer: db.Performer}, Options{
+		EntityType: EntityTypePerformer,
+		Field:      FieldAliases,
+		Pattern:    `\s*\(?Site\)?`,
+	})
+	if err != nil {
+		t.Errorf("New() error = %v", err)
+		return
+	}
+
+	got, err := f.Apply(context.Background(), []int{performerID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Change{
+			{ID: performerID, OldValue: "Jane (Site)", NewValue: "Jane"},
+			{ID: performerID, OldValue: "Site", NewValue: ""},
+		}, got)
+	}
+	performerReaderWriter.AssertExpectations(t)
+}
@@ -0,0 +1,329 @@
+package findreplace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type SceneFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Scene, error)
+	UpdatePartial(ctx context.Context, id int, updatedScene models.ScenePartial) (*models.Scene, error)
+}
+
+type ImageFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Image, error)
+	UpdatePartial(ctx context.Context, id int, partial models.ImagePartial) (*models.Image, error)
+}
+
+type GalleryFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Gallery, error)
+	UpdatePartial(ctx context.Context, id int, updatedGallery models.GalleryPartial) (*models.Gallery, error)
+}
+
+type PerformerFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Performer, error)
+	models.AliasLoader
+	UpdatePartial(ctx context.Context, id int, updatedPerformer models.PerformerPartial) (*models.Performer, error)
+}
+
+type StudioFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Studio, error)
+	models.AliasLoader
+	UpdatePartial(ctx context.Context, id int, updatedStudio models.StudioPartial) (*models.Studio, error)
+	UpdateAliases(ctx context.Context, studioID int, aliases []string) error
+}
+
+type MovieFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Movie, error)
+	UpdatePartial(ctx context.Context, id int, updatedMovie models.MoviePartial) (*models.Movie, error)
+}
+
+type TagFinderUpdater interface {
+	FindMany(ctx context.Context, ids []int) ([]*models.Tag, error)
+	models.AliasLoader
+	UpdatePartial(ctx context.Context, id int, updateTag models.TagPartial) (*models.Tag, error)
+	UpdateAliases(ctx context.Context, tagID int, aliases []string) error
+}
+
+// Repository reads and writes the fields of each entity type.
+type Repository struct {
+	Scene     SceneFinderUpdater
+	Image     ImageFinderUpdater
+	Gallery   GalleryFinderUpdater
+	Performer PerformerFinderUpdater
+	Studio    StudioFinderUpdater
+	Movie     MovieFinderUpdater
+	Tag       TagFinderUpdater
+}
+
+// objectValues are the values of the field of an object. Fields other than
+// lists of aliases have a single value.
+type objectValues struct {
+	id     int
+	values []string
+}
+
+func singleValue(id int, v string) objectValues {
+	return objectValues{id: id, values: []string{v}}
+}
+
+func (r Repository) values(ctx context.Context, entityType EntityType, field Field, ids []int) ([]objectValues, error) {
+	var ret []objectValues
+
+	switch entityType {
+	case EntityTypeScene:
+		scenes, err := r.Scene.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range scenes {
+			// skip content hidden by a restriction
+			if s == nil {
+				continue
+			}
+
+			var v string
+			switch field {
+			case FieldTitle:
+				v = s.Title
+			case FieldDetails:
+				v = s.Details
+			case FieldCode:
+				v = s.Code
+			case FieldDirector:
+				v = s.Director
+			case FieldURL:
+				v = s.URL
+			}
+			ret = append(ret, singleValue(s.ID, v))
+		}
+	case EntityTypeImage:
+		images, err := r.Image.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range images {
+			if i == nil {
+				continue
+			}
+
+			var v string
+			switch field {
+			case FieldTitle:
+				v = i.Title
+			case FieldURL:
+				v = i.URL
+			}
+			ret = append(ret, singleValue(i.ID, v))
+		}
+	case EntityTypeGallery:
+		galleries, err := r.Gallery.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range galleries {
+			if g == nil {
+				continue
+			}
+
+			var v string
+			switch field {
+			case FieldTitle:
+				v = g.Title
+			case FieldDetails:
+				v = g.Details
+			case FieldURL:
+				v = g.URL
+			}
+			ret = append(ret, singleValue(g.ID, v))
+		}
+	case EntityTypePerformer:
+		performers, err := r.Performer.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range performers {
+			if p == nil {
+				continue
+			}
+
+			switch field {
+			case FieldDetails:
+				ret = append(ret, singleValue(p.ID, p.Details))
+			case FieldURL:
+				ret = append(ret, singleValue(p.ID, p.URL))
+			case FieldAliases:
+				aliases, err := r.Performer.GetAliases(ctx, p.ID)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, objectValues{id: p.ID, values: aliases})
+			}
+		}
+	case EntityTypeStudio:
+		studios, err := r.Studio.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range studios {
+			if s == nil {
+				continue
+			}
+
+			switch field {
+			case FieldDetails:
+				ret = append(ret, singleValue(s.ID, s.Details))
+			case FieldURL:
+				ret = append(ret, singleValue(s.ID, s.URL))
+			case FieldAliases:
+				aliases, err := r.Studio.GetAliases(ctx, s.ID)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, objectValues{id: s.ID, values: aliases})
+			}
+		}
+	case EntityTypeMovie:
+		movies, err := r.Movie.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range movies {
+			var v string
+			switch field {
+			case FieldDetails:
+				v = m.Synopsis
+			case FieldDirector:
+				v = m.Director
+			case FieldURL:
+				v = m.URL
+			case FieldAliases:
+				v = m.Aliases
+			}
+			ret = append(ret, singleValue(m.ID, v))
+		}
+	case EntityTypeTag:
+		tags, err := r.Tag.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			switch field {
+			case FieldDetails:
+				ret = append(ret, singleValue(t.ID, t.Description))
+			case FieldAliases:
+				aliases, err := r.Tag.GetAliases(ctx, t.ID)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, objectValues{id: t.ID, values: aliases})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid entity type %q", entityType)
+	}
+
+	return ret, nil
+}
+
+// update sets the field of the object to values. values has a single
+// element for fields other than lists of aliases.
+func (r Repository) update(ctx context.Context, entityType EntityType, field Field, id int, values []string) error {
+	var v models.OptionalString
+	if len(values) == 1 {
+		v = models.NewOptionalString(values[0])
+	}
+
+	var err error
+	switch entityType {
+	case EntityTypeScene:
+		partial := models.NewScenePartial()
+		switch field {
+		case FieldTitle:
+			partial.Title = v
+		case FieldDetails:
+			partial.Details = v
+		case FieldCode:
+			partial.Code = v
+		case FieldDirector:
+			partial.Director = v
+		case FieldURL:
+			partial.URL = v
+		}
+		_, err = r.Scene.UpdatePartial(ctx, id, partial)
+	case EntityTypeImage:
+		partial := models.NewImagePartial()
+		switch field {
+		case FieldTitle:
+			partial.Title = v
+		case FieldURL:
+			partial.URL = v
+		}
+		_, err = r.Image.UpdatePartial(ctx, id, partial)
+	case EntityTypeGallery:
+		partial := models.NewGalleryPartial()
+		switch field {
+		case FieldTitle:
+			partial.Title = v
+		case FieldDetails:
+			partial.Details = v
+		case FieldURL:
+			partial.URL = v
+		}
+		_, err = r.Gallery.UpdatePartial(ctx, id, partial)
+	case EntityTypePerformer:
+		partial := models.NewPerformerPartial()
+		switch field {
+		case FieldDetails:
+			partial.Details = v
+		case FieldURL:
+			partial.URL = v
+		case FieldAliases:
+			partial.Aliases = &models.UpdateStrings{
+				Values: values,
+				Mode:   models.RelationshipUpdateModeSet,
+			}
+		}
+		_, err = r.Performer.UpdatePartial(ctx, id, partial)
+	case EntityTypeStudio:
+		if field == FieldAliases {
+			return r.Studio.UpdateAliases(ctx, id, values)
+		}
+
+		partial := models.NewStudioPartial()
+		switch field {
+		case FieldDetails:
+			partial.Details = v
+		case FieldURL:
+			partial.URL = v
+		}
+		_, err = r.Studio.UpdatePartial(ctx, id, partial)
+	case EntityTypeMovie:
+		partial := models.NewMoviePartial()
+		switch field {
+		case FieldDetails:
+			partial.Synopsis = v
+		case FieldDirector:
+			partial.Director = v
+		case FieldURL:
+			partial.URL = v
+		case FieldAliases:
+			partial.Aliases = v
+		}
+		_, err = r.Movie.UpdatePartial(ctx, id, partial)
+	case EntityTypeTag:
+		if field == FieldAliases {
+			return r.Tag.UpdateAliases(ctx, id, values)
+		}
+
+		partial := models.NewTagPartial()
+		partial.Description = v
+		_, err = r.Tag.UpdatePartial(ctx, id, partial)
+	default:
+		return fmt.Errorf("invalid entity type %q", entityType)
+	}
+
+	return err
+}
@@ -6,12 +6,14 @@ import (
 	"time"
 
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 )
 
 type ImageUpdater interface {
 	GetImageIDs(ctx context.Context, galleryID int) ([]int, error)
 	AddImages(ctx context.Context, galleryID int, imageIDs ...int) error
 	RemoveImages(ctx context.Context, galleryID int, imageIDs ...int) error
+	ReorderImages(ctx context.Context, galleryID int, imageIDs []int) error
 }
 
 func (s *Service) Updated(ctx context.Context, galleryID int) error {
@@ -54,6 +56,32 @@ func (s *Service) RemoveImages(ctx context.Context, g *models.Gallery, toRemove
 	return s.Updated(ctx, g.ID)
 }
 
+// ReorderImages moves the provided images to the start of the gallery, in the
+// provided order. The remaining images retain their existing order after the
+// provided images.
+// It returns an error if any of the images are not in the gallery.
+func (s *Service) ReorderImages(ctx context.Context, g *models.Gallery, imageIDs []int) error {
+	existing, err := s.Repository.GetImageIDs(ctx, g.ID)
+	if err != nil {
+		return fmt.Errorf("getting gallery images: %w", err)
+	}
+
+	for _, id := range imageIDs {
+		if !intslice.IntInclude(existing, id) {
+			return fmt.Errorf("image with id %d is not in gallery %d", id, g.ID)
+		}
+	}
+
+	newOrder := intslice.IntAppendUniques(nil, imageIDs)
+	newOrder = append(newOrder, intslice.IntExclude(existing, newOrder)...)
+
+	if err := s.Repository.ReorderImages(ctx, g.ID, newOrder); err != nil {
+		return fmt.Errorf("failed to reorder gallery images: %w", err)
+	}
+
+	return s.Updated(ctx, g.ID)
+}
+
 func AddPerformer(ctx context.Context, qb PartialUpdater, o *models.Gallery, performerID int) error {
 	_, err := qb.UpdatePartial(ctx, o.ID, models.GalleryPartial{
 		PerformerIDs: &models.UpdateIDs{
@@ -50,7 +50,12 @@ func (s *Service) ValidateImageGalleryChange(ctx context.Context, i *models.Imag
 		return err
 	}
 
-	for _, g := range galleries {
+	for j, g := range galleries {
+		// hidden by a restriction
+		if g == nil {
+			return fmt.Errorf("gallery with id %d not found", changedIDs[j])
+		}
+
 		if err := validateContentChange(g); err != nil {
 			return fmt.Errorf("changing galleries of image %q: %w", i.GetTitle(), err)
 		}
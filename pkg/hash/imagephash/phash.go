@@ -0,0 +1,39 @@
+package imagephash
+
+import (
+	"fmt"
+	"image"
+
+	// register image decoders
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/corona10/goimagehash"
+
+	"github.com/stashapp/stash/pkg/file"
+)
+
+// Generate returns the perceptual hash of the image file.
+func Generate(fs file.FS, f *file.ImageFile) (*uint64, error) {
+	r, err := f.Open(fs)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", f.Path, err)
+	}
+	defer r.Close()
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", f.Path, err)
+	}
+
+	hash, err := goimagehash.PerceptionHash(img)
+	if err != nil {
+		return nil, fmt.Errorf("computing phash of %s: %w", f.Path, err)
+	}
+
+	hashValue := hash.GetHash()
+	return &hashValue, nil
+}
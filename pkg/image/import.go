@@ -22,6 +22,7 @@ type GalleryFinder interface {
 type FullCreatorUpdater interface {
 	FinderCreatorUpdater
 	Update(ctx context.Context, updatedImage *models.Image) error
+	SetGalleryPosition(ctx context.Context, imageID int, galleryID int, position int) error
 }
 
 type Importer struct {
@@ -36,6 +37,10 @@ type Importer struct {
 
 	ID    int
 	image models.Image
+
+	// galleryPositions maps gallery id to the position of the image within
+	// the gallery
+	galleryPositions map[int]int
 }
 
 func (i *Importer) PreImport(ctx context.Context) error {
@@ -207,6 +212,13 @@ func (i *Importer) populateGalleries(ctx context.Context) error {
 			}
 		} else {
 			i.image.GalleryIDs.Add(gallery.ID)
+
+			if ref.Position > 0 {
+				if i.galleryPositions == nil {
+					i.galleryPositions = make(map[int]int)
+				}
+				i.galleryPositions[gallery.ID] = ref.Position
+			}
 		}
 	}
 
@@ -291,6 +303,12 @@ func (i *Importer) populateTags(ctx context.Context) error {
 }
 
 func (i *Importer) PostImport(ctx context.Context, id int) error {
+	for galleryID, position := range i.galleryPositions {
+		if err := i.ReaderWriter.SetGalleryPosition(ctx, id, galleryID, position); err != nil {
+			return fmt.Errorf("error setting gallery position: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -110,7 +110,7 @@ func FindGalleryCover(ctx context.Context, r Queryer, galleryID int, galleryCove
 		return img, nil
 	}
 
-	// return the first image in the gallery
+	// return the image in the first position of the gallery
 	return findGalleryCover(ctx, r, galleryID, !useCoverJpg, galleryCoverRegex)
 }
 
@@ -118,6 +118,9 @@ func findGalleryCover(ctx context.Context, r Queryer, galleryID int, useCoverJpg
 	// try to find cover.jpg in the gallery
 	perPage := 1
 	sortBy := "path"
+	if !useCoverJpg {
+		sortBy = "gallery_position"
+	}
 	sortDir := models.SortDirectionEnumAsc
 
 	findFilter := models.FindFilterType{
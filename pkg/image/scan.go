@@ -14,6 +14,7 @@ import (
 	"github.com/stashapp/stash/pkg/models/paths"
 	"github.com/stashapp/stash/pkg/plugin"
 	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/tag"
 	"github.com/stashapp/stash/pkg/txn"
 )
 
@@ -41,6 +42,8 @@ type GalleryFinderCreator interface {
 
 type ScanConfig interface {
 	GetCreateGalleriesFromFolders() bool
+	GetSetImageDateFromMetadata() bool
+	GetSetImageTagsFromMetadata() bool
 }
 
 type ScanGenerator interface {
@@ -50,6 +53,7 @@ type ScanGenerator interface {
 type ScanHandler struct {
 	CreatorUpdater FinderCreatorUpdater
 	GalleryFinder  GalleryFinderCreator
+	TagFinder      tag.Queryer
 
 	ScanGenerator ScanGenerator
 
@@ -70,6 +74,9 @@ func (h *ScanHandler) validate() error {
 	if h.GalleryFinder == nil {
 		return errors.New("GalleryFinder is required")
 	}
+	if h.TagFinder == nil {
+		return errors.New("TagFinder is required")
+	}
 	if h.ScanConfig == nil {
 		return errors.New("ScanConfig is required")
 	}
@@ -118,6 +125,12 @@ func (h *ScanHandler) Handle(ctx context.Context, f file.File, oldFile file.File
 
 		logger.Infof("%s doesn't exist. Creating new image...", f.Base().Path)
 
+		if imf, ok := f.(*file.ImageFile); ok {
+			if err := h.setMetadataFields(ctx, newImage, imf.Metadata); err != nil {
+				return err
+			}
+		}
+
 		g, err := h.getGalleryToAssociate(ctx, newImage, f)
 		if err != nil {
 			return err
@@ -173,6 +186,51 @@ func (h *ScanHandler) Handle(ctx context.Context, f file.File, oldFile file.File
 	return nil
 }
 
+// setMetadataFields sets the fields of a new image from the embedded metadata
+// of its file, as enabled in the scan configuration.
+func (h *ScanHandler) setMetadataFields(ctx context.Context, i *models.Image, m file.ImageMetadata) error {
+	if h.ScanConfig.GetSetImageDateFromMetadata() && m.DateTaken != nil {
+		d := models.NewDate(m.DateTaken.Format("2006-01-02"))
+		i.Date = &d
+	}
+
+	if h.ScanConfig.GetSetImageTagsFromMetadata() && len(m.Keywords) > 0 {
+		tagIDs, err := h.matchKeywordTags(ctx, m.Keywords)
+		if err != nil {
+			return err
+		}
+
+		i.TagIDs = models.NewRelatedIDs(tagIDs)
+	}
+
+	return nil
+}
+
+// matchKeywordTags returns the ids of the existing tags with a name or alias
+// matching the provided keywords. Keywords without a matching tag are ignored.
+func (h *ScanHandler) matchKeywordTags(ctx context.Context, keywords []string) ([]int, error) {
+	var ret []int
+	for _, k := range keywords {
+		t, err := tag.ByName(ctx, h.TagFinder, k)
+		if err != nil {
+			return nil, fmt.Errorf("finding tag %q: %w", k, err)
+		}
+
+		if t == nil {
+			t, err = tag.ByAlias(ctx, h.TagFinder, k)
+			if err != nil {
+				return nil, fmt.Errorf("finding tag by alias %q: %w", k, err)
+			}
+		}
+
+		if t != nil {
+			ret = intslice.IntAppendUnique(ret, t.ID)
+		}
+	}
+
+	return ret, nil
+}
+
 func (h *ScanHandler) associateExisting(ctx context.Context, existing []*models.Image, f *file.BaseFile, updateExisting bool) error {
 	for _, i := range existing {
 		if err := i.LoadFiles(ctx, h.CreatorUpdater); err != nil {
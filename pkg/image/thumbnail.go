@@ -82,7 +82,9 @@ func (e *ThumbnailEncoder) GetThumbnail(f file.File, maxSize int) ([]byte, error
 
 	data := buf.Bytes()
 
+	orientation := file.OrientationUnset
 	if imageFile, ok := f.(*file.ImageFile); ok {
+		orientation = imageFile.Metadata.Orientation
 		format := imageFile.Format
 		animated := imageFile.Format == formatGif
 
@@ -99,14 +101,14 @@ func (e *ThumbnailEncoder) GetThumbnail(f file.File, maxSize int) ([]byte, error
 
 	// Videofiles can only be thumbnailed with ffmpeg
 	if _, ok := f.(*file.VideoFile); ok {
-		return e.ffmpegImageThumbnail(buf, maxSize)
+		return e.ffmpegImageThumbnail(buf, maxSize, file.OrientationUnset)
 	}
 
 	// vips has issues loading files from stdin on Windows
 	if e.vips != nil && runtime.GOOS != "windows" {
 		return e.vips.ImageThumbnail(buf, maxSize)
 	} else {
-		return e.ffmpegImageThumbnail(buf, maxSize)
+		return e.ffmpegImageThumbnail(buf, maxSize, orientation)
 	}
 }
 
@@ -128,12 +130,13 @@ func (e *ThumbnailEncoder) GetPreview(inPath string, outPath string, maxSize int
 	return e.getClipPreview(inPath, outPath, maxSize, clipDuration, fileData.FrameRate)
 }
 
-func (e *ThumbnailEncoder) ffmpegImageThumbnail(image *bytes.Buffer, maxSize int) ([]byte, error) {
+func (e *ThumbnailEncoder) ffmpegImageThumbnail(image *bytes.Buffer, maxSize int, orientation int) ([]byte, error) {
 	args := transcoder.ImageThumbnail("-", transcoder.ImageThumbnailOptions{
 		OutputFormat:  ffmpeg.ImageFormatJpeg,
 		OutputPath:    "-",
 		MaxDimensions: maxSize,
 		Quality:       ffmpegImageQuality,
+		Orientation:   orientation,
 	})
 
 	return e.FFMpeg.GenerateOutput(context.TODO(), args, image)
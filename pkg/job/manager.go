@@ -26,6 +26,10 @@ type Manager struct {
 
 	subscriptions       []*ManagerSubscription
 	updateThrottleLimit time.Duration
+
+	// JobContext, if set, returns the context a job is executed with from
+	// the context it was added with.
+	JobContext func(ctx context.Context) context.Context
 }
 
 // NewManager initialises and returns a new Manager.
@@ -179,6 +183,10 @@ func (m *Manager) dispatch(ctx context.Context, j *Job) (done chan struct{}) {
 	j.StartTime = &t
 	j.Status = StatusRunning
 
+	if m.JobContext != nil {
+		ctx = m.JobContext(ctx)
+	}
+
 	ctx, cancelFunc := context.WithCancel(utils.ValueOnlyContext{Context: ctx})
 	j.cancelFunc = cancelFunc
 
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type APIKeyReader interface {
+	Find(ctx context.Context, id int) (*APIKey, error)
+	// FindByKeyHash returns the key with the hash, or nil if there is none.
+	FindByKeyHash(ctx context.Context, keyHash string) (*APIKey, error)
+	FindByUserID(ctx context.Context, userID int) ([]*APIKey, error)
+	All(ctx context.Context) ([]*APIKey, error)
+}
+
+type APIKeyWriter interface {
+	Create(ctx context.Context, newKey *APIKey) error
+	UpdateLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error
+	Destroy(ctx context.Context, id int) error
+}
+
+type APIKeyReaderWriter interface {
+	APIKeyReader
+	APIKeyWriter
+}
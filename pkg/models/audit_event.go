@@ -0,0 +1,41 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type AuditEventFilterType struct {
+	// Filter to only include events with these actions
+	Actions []AuditAction `json:"actions"`
+	// Filter by username
+	Username *StringCriterionInput `json:"username"`
+	// Filter by API key name
+	APIKeyName *StringCriterionInput `json:"api_key_name"`
+	// Filter by IP address
+	IPAddress *StringCriterionInput `json:"ip_address"`
+	// Filter by mutation name or login method
+	Operation *StringCriterionInput `json:"operation"`
+	// Filter to only include events acting on the object with this id
+	TargetID *string `json:"target_id"`
+	// Filter by time of the event
+	CreatedAt *TimestampCriterionInput `json:"created_at"`
+}
+
+type AuditEventReader interface {
+	// Query returns the events matching the filter, newest first unless
+	// sorted otherwise, and the total number of matching events.
+	Query(ctx context.Context, auditEventFilter *AuditEventFilterType, findFilter *FindFilterType) ([]*AuditEvent, int, error)
+}
+
+type AuditEventWriter interface {
+	Create(ctx context.Context, newEvent *AuditEvent) error
+	// DestroyBefore deletes the events created before t, returning the
+	// number of events deleted.
+	DestroyBefore(ctx context.Context, t time.Time) (int64, error)
+}
+
+type AuditEventReaderWriter interface {
+	AuditEventReader
+	AuditEventWriter
+}
@@ -0,0 +1,56 @@
+package models
+
+import "context"
+
+type editContextKey int
+
+const (
+	editSourceKey editContextKey = iota + 1
+	editOperationKey
+)
+
+// WithEditSource returns a context that records changes made to entities in
+// transactions started with it, attributing them to the provided source.
+// Changes made without a source are not recorded.
+func WithEditSource(ctx context.Context, source EditSource) context.Context {
+	return context.WithValue(ctx, editSourceKey, source)
+}
+
+// EditSourceFromContext returns the edit source set on the context, or an
+// empty string if none is set.
+func EditSourceFromContext(ctx context.Context) EditSource {
+	source, _ := ctx.Value(editSourceKey).(EditSource)
+	return source
+}
+
+// WithEditOperation returns a context that records updates made with it
+// as the provided operation. Updates are recorded as EditOperationUpdate
+// by default.
+func WithEditOperation(ctx context.Context, operation EditOperation) context.Context {
+	return context.WithValue(ctx, editOperationKey, operation)
+}
+
+// EditOperationFromContext returns the edit operation set on the context,
+// or EditOperationUpdate if none is set.
+func EditOperationFromContext(ctx context.Context) EditOperation {
+	operation, ok := ctx.Value(editOperationKey).(EditOperation)
+	if !ok {
+		return EditOperationUpdate
+	}
+	return operation
+}
+
+type EditReader interface {
+	Find(ctx context.Context, id int) (*Edit, error)
+	FindByEntity(ctx context.Context, entityType EditEntityType, entityID int) ([]*Edit, error)
+}
+
+type EditWriter interface {
+	// Revert restores the fields changed by the edit to their previous values.
+	Revert(ctx context.Context, id int) error
+}
+
+type EditReaderWriter interface {
+	EditReader
+	EditWriter
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"fmt"
+)
+
+// EntityImage is one of the images stored for a performer, studio or tag.
+// Exactly one image of an object with images is the primary image.
+type EntityImage struct {
+	ID       int    `db:"id"`
+	Checksum string `db:"image_blob"`
+	Primary  bool   `db:"is_primary"`
+}
+
+type EntityImageGetter interface {
+	GetImages(ctx context.Context, id int) ([]EntityImage, error)
+	GetImageByID(ctx context.Context, id int, imageID int) ([]byte, error)
+}
+
+// GetSecondaryImages returns the data of all images of the object other than
+// the primary image.
+func GetSecondaryImages(ctx context.Context, r EntityImageGetter, id int) ([][]byte, error) {
+	images, err := r.GetImages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret [][]byte
+	for _, image := range images {
+		if image.Primary {
+			continue
+		}
+
+		data, err := r.GetImageByID(ctx, id, image.ID)
+		if err != nil {
+			return nil, fmt.Errorf("getting image %d: %w", image.ID, err)
+		}
+
+		if len(data) > 0 {
+			ret = append(ret, data)
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type FacetType string
+
+const (
+	FacetTypeTag        FacetType = "TAG"
+	FacetTypePerformer  FacetType = "PERFORMER"
+	FacetTypeStudio     FacetType = "STUDIO"
+	FacetTypeMovie      FacetType = "MOVIE"
+	FacetTypeGallery    FacetType = "GALLERY"
+	FacetTypeParent     FacetType = "PARENT"
+	FacetTypeResolution FacetType = "RESOLUTION"
+	FacetTypeYear       FacetType = "YEAR"
+	FacetTypeCodec      FacetType = "CODEC"
+	FacetTypeGender     FacetType = "GENDER"
+	FacetTypeCountry    FacetType = "COUNTRY"
+	FacetTypeEthnicity  FacetType = "ETHNICITY"
+)
+
+var AllFacetType = []FacetType{
+	FacetTypeTag,
+	FacetTypePerformer,
+	FacetTypeStudio,
+	FacetTypeMovie,
+	FacetTypeGallery,
+	FacetTypeParent,
+	FacetTypeResolution,
+	FacetTypeYear,
+	FacetTypeCodec,
+	FacetTypeGender,
+	FacetTypeCountry,
+	FacetTypeEthnicity,
+}
+
+func (e FacetType) IsValid() bool {
+	switch e {
+	case FacetTypeTag, FacetTypePerformer, FacetTypeStudio, FacetTypeMovie, FacetTypeGallery, FacetTypeParent, FacetTypeResolution, FacetTypeYear, FacetTypeCodec, FacetTypeGender, FacetTypeCountry, FacetTypeEthnicity:
+		return true
+	}
+	return false
+}
+
+func (e FacetType) String() string {
+	return string(e)
+}
+
+func (e *FacetType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FacetType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FacetType", str)
+	}
+	return nil
+}
+
+func (e FacetType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// FacetValue is a value of a facet and the number of objects with that value.
+type FacetValue struct {
+	// ID of the related object, or the value itself for other facets
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// Facet is a breakdown of a set of objects by the values of a field.
+type Facet struct {
+	Type   FacetType     `json:"type"`
+	Values []*FacetValue `json:"values"`
+}
+
+// FacetOptions specifies the facets to aggregate over a filtered set of objects.
+type FacetOptions struct {
+	Facets []FacetType
+	// Limit is the maximum number of values returned per facet. Values
+	// are ordered by descending count. All values are returned if Limit
+	// is not positive.
+	Limit int
+}
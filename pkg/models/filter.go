@@ -169,3 +169,8 @@ type PhashDistanceCriterionInput struct {
 	Modifier CriterionModifier `json:"modifier"`
 	Distance *int              `json:"distance"`
 }
+
+type PhashSimilarityCriterionInput struct {
+	ID       string `json:"id"`
+	Distance *int   `json:"distance"`
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -58,6 +61,14 @@ type FindFilterType struct {
 	PerPage   *int               `json:"per_page"`
 	Sort      *string            `json:"sort"`
 	Direction *SortDirectionEnum `json:"direction"`
+	// cursor of the last result of the previous page, when paging with cursors
+	After *string `json:"after"`
+	// number of results to return after the cursor. Defaults to per_page.
+	First *int `json:"first"`
+
+	// LastCursor is the cursor of the last result returned by a query
+	// paging with cursors. It is set by the query, not by the client.
+	LastCursor *FindCursor `json:"-"`
 }
 
 func (ff FindFilterType) GetSort(defaultSort string) string {
@@ -118,6 +129,92 @@ func (ff FindFilterType) IsGetAll() bool {
 	return ff.PerPage != nil && *ff.PerPage < 0
 }
 
+// UsesCursor returns true if the results should be paged using cursors
+// rather than page numbers.
+func (ff FindFilterType) UsesCursor() bool {
+	return ff.After != nil || ff.First != nil
+}
+
+// GetFirst returns the number of results to return when paging with
+// cursors. Returns a negative number if all results should be returned.
+func (ff FindFilterType) GetFirst() int {
+	switch {
+	case ff.First != nil:
+		return *ff.First
+	case ff.IsGetAll():
+		return PerPageAll
+	default:
+		return ff.GetPageSize()
+	}
+}
+
+// NextCursor returns the cursor of the page following a page of n results.
+// Returns nil if the filter does not use cursors, or if there are no further
+// results.
+func (ff *FindFilterType) NextCursor(n int) *string {
+	if ff == nil || !ff.UsesCursor() || ff.LastCursor == nil {
+		return nil
+	}
+
+	first := ff.GetFirst()
+	if n == 0 || first < 0 || n < first {
+		return nil
+	}
+
+	ret := ff.LastCursor.Encode()
+	return &ret
+}
+
+// CursorDefaultSort is the sort used when paging with cursors if no sort
+// is provided.
+const CursorDefaultSort = "id"
+
+// FindCursor identifies the position of a result within a sorted set of
+// results. Cursors are encoded as opaque strings.
+type FindCursor struct {
+	Sort      string `json:"s"`
+	Direction string `json:"d"`
+	// Value is the sort value of the result, so that the position of the
+	// cursor does not depend on the result still existing. It is omitted
+	// when sorting by id.
+	Value interface{} `json:"v,omitempty"`
+	ID    int         `json:"id"`
+}
+
+func (c FindCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeFindCursor(s string) (*FindCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+
+	// numbers are decoded as integers where possible, so that they are
+	// compared exactly
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var ret FindCursor
+	if err := dec.Decode(&ret); err != nil || ret.ID == 0 {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+
+	if n, ok := ret.Value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			ret.Value = i
+		} else if f, err := n.Float64(); err == nil {
+			ret.Value = f
+		} else {
+			return nil, fmt.Errorf("invalid cursor %q", s)
+		}
+	}
+
+	return &ret, nil
+}
+
 // BatchFindFilter returns a FindFilterType suitable for batch finding
 // using the provided batch size.
 func BatchFindFilter(batchSize int) *FindFilterType {
@@ -99,6 +99,7 @@ type GalleryReader interface {
 	FindByPath(ctx context.Context, path string) ([]*Gallery, error)
 	FindBySceneID(ctx context.Context, sceneID int) ([]*Gallery, error)
 	FindByImageID(ctx context.Context, imageID int) ([]*Gallery, error)
+	FindSimilar(ctx context.Context, id int, options SimilarityOptions) ([]SimilarityScore, error)
 
 	SceneIDLoader
 	PerformerIDLoader
@@ -107,6 +108,7 @@ type GalleryReader interface {
 	Count(ctx context.Context) (int, error)
 	All(ctx context.Context) ([]*Gallery, error)
 	Query(ctx context.Context, galleryFilter *GalleryFilterType, findFilter *FindFilterType) ([]*Gallery, int, error)
+	Facets(ctx context.Context, galleryFilter *GalleryFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	QueryCount(ctx context.Context, galleryFilter *GalleryFilterType, findFilter *FindFilterType) (int, error)
 	GetImageIDs(ctx context.Context, galleryID int) ([]int, error)
 }
@@ -117,6 +119,7 @@ type GalleryWriter interface {
 	UpdatePartial(ctx context.Context, id int, updatedGallery GalleryPartial) (*Gallery, error)
 	Destroy(ctx context.Context, id int) error
 	UpdateImages(ctx context.Context, galleryID int, imageIDs []int) error
+	ReorderImages(ctx context.Context, galleryID int, imageIDs []int) error
 }
 
 type GalleryReaderWriter interface {
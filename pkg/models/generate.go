@@ -19,6 +19,7 @@ type GenerateMetadataOptions struct {
 	Phashes                   bool                    `json:"phashes"`
 	InteractiveHeatmapsSpeeds bool                    `json:"interactiveHeatmapsSpeeds"`
 	ClipPreviews              bool                    `json:"clipPreviews"`
+	ImagePhashes              bool                    `json:"imagePhashes"`
 }
 
 type GeneratePreviewOptions struct {
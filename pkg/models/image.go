@@ -26,6 +26,12 @@ type ImageFilterType struct {
 	Organized *bool `json:"organized"`
 	// Filter by o-counter
 	OCounter *IntCriterionInput `json:"o_counter"`
+	// Filter by phash distance
+	PhashDistance *PhashDistanceCriterionInput `json:"phash_distance"`
+	// Filter by phash similarity to another image
+	PhashSimilarTo *PhashSimilarityCriterionInput `json:"phash_similar_to"`
+	// Filter images that have a phash match available
+	Duplicated *PHashDuplicationCriterionInput `json:"duplicated"`
 	// Filter by resolution
 	Resolution *ResolutionCriterionInput `json:"resolution"`
 	// Filter to only include images missing this property
@@ -50,6 +56,18 @@ type ImageFilterType struct {
 	CreatedAt *TimestampCriterionInput `json:"created_at"`
 	// Filter by updated at
 	UpdatedAt *TimestampCriterionInput `json:"updated_at"`
+	// Filter by the date the image was taken, from the file metadata
+	DateTaken *TimestampCriterionInput `json:"date_taken"`
+	// Filter by camera make or model, from the file metadata
+	Camera *StringCriterionInput `json:"camera"`
+	// Filter by lens, from the file metadata
+	Lens *StringCriterionInput `json:"lens"`
+	// Filter by orientation, from the file metadata
+	Orientation *IntCriterionInput `json:"orientation"`
+	// Filter by embedded keyword, from the file metadata
+	Keywords *StringCriterionInput `json:"keywords"`
+	// Filter images that have GPS coordinates in the file metadata
+	HasGps *bool `json:"has_gps"`
 }
 
 type ImageDestroyInput struct {
@@ -107,13 +125,16 @@ type ImageReader interface {
 	Find(ctx context.Context, id int) (*Image, error)
 	FindByChecksum(ctx context.Context, checksum string) ([]*Image, error)
 	FindByGalleryID(ctx context.Context, galleryID int) ([]*Image, error)
+	FindDuplicates(ctx context.Context, distance int) ([][]*Image, error)
 	CountByGalleryID(ctx context.Context, galleryID int) (int, error)
 	OCountByPerformerID(ctx context.Context, performerID int) (int, error)
 	Count(ctx context.Context) (int, error)
 	Size(ctx context.Context) (float64, error)
 	All(ctx context.Context) ([]*Image, error)
 	Query(ctx context.Context, options ImageQueryOptions) (*ImageQueryResult, error)
+	Facets(ctx context.Context, imageFilter *ImageFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	QueryCount(ctx context.Context, imageFilter *ImageFilterType, findFilter *FindFilterType) (int, error)
+	GetGalleryPositions(ctx context.Context, imageID int) (map[int]int, error)
 
 	GalleryIDLoader
 	PerformerIDLoader
@@ -128,6 +149,7 @@ type ImageWriter interface {
 	DecrementOCounter(ctx context.Context, id int) (int, error)
 	ResetOCounter(ctx context.Context, id int) (int, error)
 	Destroy(ctx context.Context, id int) error
+	SetGalleryPosition(ctx context.Context, imageID int, galleryID int, position int) error
 }
 
 type ImageReaderWriter interface {
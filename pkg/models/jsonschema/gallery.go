@@ -75,6 +75,9 @@ type GalleryRef struct {
 	FolderPath string   `json:"folder_path,omitempty"`
 	// Title is used only if FolderPath and ZipPaths is empty
 	Title string `json:"title,omitempty"`
+	// Position is the position of the image within the gallery.
+	// Only used for image gallery references.
+	Position int `json:"position,omitempty"`
 }
 
 func (r GalleryRef) String() string {
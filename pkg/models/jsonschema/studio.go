@@ -11,10 +11,12 @@ import (
 )
 
 type Studio struct {
-	Name          string           `json:"name,omitempty"`
-	URL           string           `json:"url,omitempty"`
-	ParentStudio  string           `json:"parent_studio,omitempty"`
-	Image         string           `json:"image,omitempty"`
+	Name         string `json:"name,omitempty"`
+	URL          string `json:"url,omitempty"`
+	ParentStudio string `json:"parent_studio,omitempty"`
+	Image        string `json:"image,omitempty"`
+	// Images are the non-primary images, base64 encoded
+	Images        []string         `json:"images,omitempty"`
 	CreatedAt     json.JSONTime    `json:"created_at,omitempty"`
 	UpdatedAt     json.JSONTime    `json:"updated_at,omitempty"`
 	Rating        int              `json:"rating,omitempty"`
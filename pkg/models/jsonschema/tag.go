@@ -10,10 +10,12 @@ import (
 )
 
 type Tag struct {
-	Name          string        `json:"name,omitempty"`
-	Description   string        `json:"description,omitempty"`
-	Aliases       []string      `json:"aliases,omitempty"`
-	Image         string        `json:"image,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	// Images are the non-primary images, base64 encoded
+	Images        []string      `json:"images,omitempty"`
 	Parents       []string      `json:"parents,omitempty"`
 	IgnoreAutoTag bool          `json:"ignore_auto_tag,omitempty"`
 	CreatedAt     json.JSONTime `json:"created_at,omitempty"`
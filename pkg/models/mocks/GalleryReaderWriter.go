@@ -88,6 +88,29 @@ func (_m *GalleryReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// Facets provides a mock function with given fields: ctx, galleryFilter, findFilter, options
+func (_m *GalleryReaderWriter) Facets(ctx context.Context, galleryFilter *models.GalleryFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, galleryFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.GalleryFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, galleryFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.GalleryFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, galleryFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *GalleryReaderWriter) Find(ctx context.Context, id int) (*models.Gallery, error) {
 	ret := _m.Called(ctx, id)
@@ -249,6 +272,29 @@ func (_m *GalleryReaderWriter) FindMany(ctx context.Context, ids []int) ([]*mode
 	return r0, r1
 }
 
+// FindSimilar provides a mock function with given fields: ctx, id, options
+func (_m *GalleryReaderWriter) FindSimilar(ctx context.Context, id int, options models.SimilarityOptions) ([]models.SimilarityScore, error) {
+	ret := _m.Called(ctx, id, options)
+
+	var r0 []models.SimilarityScore
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.SimilarityOptions) []models.SimilarityScore); ok {
+		r0 = rf(ctx, id, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SimilarityScore)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.SimilarityOptions) error); ok {
+		r1 = rf(ctx, id, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImageIDs provides a mock function with given fields: ctx, galleryID
 func (_m *GalleryReaderWriter) GetImageIDs(ctx context.Context, galleryID int) ([]int, error) {
 	ret := _m.Called(ctx, galleryID)
@@ -392,6 +438,20 @@ func (_m *GalleryReaderWriter) QueryCount(ctx context.Context, galleryFilter *mo
 	return r0, r1
 }
 
+// ReorderImages provides a mock function with given fields: ctx, galleryID, imageIDs
+func (_m *GalleryReaderWriter) ReorderImages(ctx context.Context, galleryID int, imageIDs []int) error {
+	ret := _m.Called(ctx, galleryID, imageIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
+		r0 = rf(ctx, galleryID, imageIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, updatedGallery
 func (_m *GalleryReaderWriter) Update(ctx context.Context, updatedGallery *models.Gallery) error {
 	ret := _m.Called(ctx, updatedGallery)
@@ -128,6 +128,29 @@ func (_m *ImageReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// Facets provides a mock function with given fields: ctx, imageFilter, findFilter, options
+func (_m *ImageReaderWriter) Facets(ctx context.Context, imageFilter *models.ImageFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, imageFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ImageFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, imageFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ImageFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, imageFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *ImageReaderWriter) Find(ctx context.Context, id int) (*models.Image, error) {
 	ret := _m.Called(ctx, id)
@@ -197,6 +220,29 @@ func (_m *ImageReaderWriter) FindByGalleryID(ctx context.Context, galleryID int)
 	return r0, r1
 }
 
+// FindDuplicates provides a mock function with given fields: ctx, distance
+func (_m *ImageReaderWriter) FindDuplicates(ctx context.Context, distance int) ([][]*models.Image, error) {
+	ret := _m.Called(ctx, distance)
+
+	var r0 [][]*models.Image
+	if rf, ok := ret.Get(0).(func(context.Context, int) [][]*models.Image); ok {
+		r0 = rf(ctx, distance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]*models.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, distance)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindMany provides a mock function with given fields: ctx, ids
 func (_m *ImageReaderWriter) FindMany(ctx context.Context, ids []int) ([]*models.Image, error) {
 	ret := _m.Called(ctx, ids)
@@ -243,6 +289,29 @@ func (_m *ImageReaderWriter) GetGalleryIDs(ctx context.Context, relatedID int) (
 	return r0, r1
 }
 
+// GetGalleryPositions provides a mock function with given fields: ctx, imageID
+func (_m *ImageReaderWriter) GetGalleryPositions(ctx context.Context, imageID int) (map[int]int, error) {
+	ret := _m.Called(ctx, imageID)
+
+	var r0 map[int]int
+	if rf, ok := ret.Get(0).(func(context.Context, int) map[int]int); ok {
+		r0 = rf(ctx, imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPerformerIDs provides a mock function with given fields: ctx, relatedID
 func (_m *ImageReaderWriter) GetPerformerIDs(ctx context.Context, relatedID int) ([]int, error) {
 	ret := _m.Called(ctx, relatedID)
@@ -396,6 +465,20 @@ func (_m *ImageReaderWriter) ResetOCounter(ctx context.Context, id int) (int, er
 	return r0, r1
 }
 
+// SetGalleryPosition provides a mock function with given fields: ctx, imageID, galleryID, position
+func (_m *ImageReaderWriter) SetGalleryPosition(ctx context.Context, imageID int, galleryID int, position int) error {
+	ret := _m.Called(ctx, imageID, galleryID, position)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) error); ok {
+		r0 = rf(ctx, imageID, galleryID, position)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Size provides a mock function with given fields: ctx
 func (_m *ImageReaderWriter) Size(ctx context.Context) (float64, error) {
 	ret := _m.Called(ctx)
@@ -128,6 +128,29 @@ func (_m *MovieReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// Facets provides a mock function with given fields: ctx, movieFilter, findFilter, options
+func (_m *MovieReaderWriter) Facets(ctx context.Context, movieFilter *models.MovieFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, movieFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.MovieFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, movieFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.MovieFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, movieFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *MovieReaderWriter) Find(ctx context.Context, id int) (*models.Movie, error) {
 	ret := _m.Called(ctx, id)
@@ -354,6 +377,20 @@ func (_m *MovieReaderWriter) HasFrontImage(ctx context.Context, movieID int) (bo
 	return r0, r1
 }
 
+// Merge provides a mock function with given fields: ctx, source, destination
+func (_m *MovieReaderWriter) Merge(ctx context.Context, source []int, destination int) error {
+	ret := _m.Called(ctx, source, destination)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int) error); ok {
+		r0 = rf(ctx, source, destination)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: ctx, movieFilter, findFilter
 func (_m *MovieReaderWriter) Query(ctx context.Context, movieFilter *models.MovieFilterType, findFilter *models.FindFilterType) ([]*models.Movie, int, error) {
 	ret := _m.Called(ctx, movieFilter, findFilter)
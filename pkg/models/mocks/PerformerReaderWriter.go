@@ -14,6 +14,27 @@ type PerformerReaderWriter struct {
 	mock.Mock
 }
 
+// AddImage provides a mock function with given fields: ctx, performerID, image, primary
+func (_m *PerformerReaderWriter) AddImage(ctx context.Context, performerID int, image []byte, primary bool) (int, error) {
+	ret := _m.Called(ctx, performerID, image, primary)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int, []byte, bool) int); ok {
+		r0 = rf(ctx, performerID, image, primary)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, []byte, bool) error); ok {
+		r1 = rf(ctx, performerID, image, primary)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // All provides a mock function with given fields: ctx
 func (_m *PerformerReaderWriter) All(ctx context.Context) ([]*models.Performer, error) {
 	ret := _m.Called(ctx)
@@ -107,6 +128,43 @@ func (_m *PerformerReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// DestroyImageByID provides a mock function with given fields: ctx, performerID, imageID
+func (_m *PerformerReaderWriter) DestroyImageByID(ctx context.Context, performerID int, imageID int) error {
+	ret := _m.Called(ctx, performerID, imageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, performerID, imageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Facets provides a mock function with given fields: ctx, performerFilter, findFilter, options
+func (_m *PerformerReaderWriter) Facets(ctx context.Context, performerFilter *models.PerformerFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, performerFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PerformerFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, performerFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PerformerFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, performerFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *PerformerReaderWriter) Find(ctx context.Context, id int) (*models.Performer, error) {
 	ret := _m.Called(ctx, id)
@@ -291,6 +349,29 @@ func (_m *PerformerReaderWriter) FindMany(ctx context.Context, ids []int) ([]*mo
 	return r0, r1
 }
 
+// FindSimilar provides a mock function with given fields: ctx, id, options
+func (_m *PerformerReaderWriter) FindSimilar(ctx context.Context, id int, options models.SimilarityOptions) ([]models.SimilarityScore, error) {
+	ret := _m.Called(ctx, id, options)
+
+	var r0 []models.SimilarityScore
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.SimilarityOptions) []models.SimilarityScore); ok {
+		r0 = rf(ctx, id, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SimilarityScore)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.SimilarityOptions) error); ok {
+		r1 = rf(ctx, id, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAliases provides a mock function with given fields: ctx, relatedID
 func (_m *PerformerReaderWriter) GetAliases(ctx context.Context, relatedID int) ([]string, error) {
 	ret := _m.Called(ctx, relatedID)
@@ -337,6 +418,52 @@ func (_m *PerformerReaderWriter) GetImage(ctx context.Context, performerID int)
 	return r0, r1
 }
 
+// GetImageByID provides a mock function with given fields: ctx, performerID, imageID
+func (_m *PerformerReaderWriter) GetImageByID(ctx context.Context, performerID int, imageID int) ([]byte, error) {
+	ret := _m.Called(ctx, performerID, imageID)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []byte); ok {
+		r0 = rf(ctx, performerID, imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, performerID, imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImages provides a mock function with given fields: ctx, performerID
+func (_m *PerformerReaderWriter) GetImages(ctx context.Context, performerID int) ([]models.EntityImage, error) {
+	ret := _m.Called(ctx, performerID)
+
+	var r0 []models.EntityImage
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.EntityImage); ok {
+		r0 = rf(ctx, performerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EntityImage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, performerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStashIDs provides a mock function with given fields: ctx, relatedID
 func (_m *PerformerReaderWriter) GetStashIDs(ctx context.Context, relatedID int) ([]models.StashID, error) {
 	ret := _m.Called(ctx, relatedID)
@@ -404,6 +531,20 @@ func (_m *PerformerReaderWriter) HasImage(ctx context.Context, performerID int)
 	return r0, r1
 }
 
+// Merge provides a mock function with given fields: ctx, source, destination
+func (_m *PerformerReaderWriter) Merge(ctx context.Context, source []int, destination int) error {
+	ret := _m.Called(ctx, source, destination)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int) error); ok {
+		r0 = rf(ctx, source, destination)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: ctx, performerFilter, findFilter
 func (_m *PerformerReaderWriter) Query(ctx context.Context, performerFilter *models.PerformerFilterType, findFilter *models.FindFilterType) ([]*models.Performer, int, error) {
 	ret := _m.Called(ctx, performerFilter, findFilter)
@@ -478,6 +619,20 @@ func (_m *PerformerReaderWriter) QueryForAutoTag(ctx context.Context, words []st
 	return r0, r1
 }
 
+// SetPrimaryImage provides a mock function with given fields: ctx, performerID, imageID
+func (_m *PerformerReaderWriter) SetPrimaryImage(ctx context.Context, performerID int, imageID int) error {
+	ret := _m.Called(ctx, performerID, imageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, performerID, imageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, updatedPerformer
 func (_m *PerformerReaderWriter) Update(ctx context.Context, updatedPerformer *models.Performer) error {
 	ret := _m.Called(ctx, updatedPerformer)
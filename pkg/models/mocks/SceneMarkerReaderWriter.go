@@ -107,6 +107,29 @@ func (_m *SceneMarkerReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// Facets provides a mock function with given fields: ctx, sceneMarkerFilter, findFilter, options
+func (_m *SceneMarkerReaderWriter) Facets(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, sceneMarkerFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SceneMarkerFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, sceneMarkerFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SceneMarkerFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, sceneMarkerFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *SceneMarkerReaderWriter) Find(ctx context.Context, id int) (*models.SceneMarker, error) {
 	ret := _m.Called(ctx, id)
@@ -256,6 +256,29 @@ func (_m *SceneReaderWriter) Duration(ctx context.Context) (float64, error) {
 	return r0, r1
 }
 
+// Facets provides a mock function with given fields: ctx, sceneFilter, findFilter, options
+func (_m *SceneReaderWriter) Facets(ctx context.Context, sceneFilter *models.SceneFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, sceneFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SceneFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, sceneFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SceneFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, sceneFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *SceneReaderWriter) Find(ctx context.Context, id int) (*models.Scene, error) {
 	ret := _m.Called(ctx, id)
@@ -463,6 +486,29 @@ func (_m *SceneReaderWriter) FindMany(ctx context.Context, ids []int) ([]*models
 	return r0, r1
 }
 
+// FindSimilar provides a mock function with given fields: ctx, id, options
+func (_m *SceneReaderWriter) FindSimilar(ctx context.Context, id int, options models.SimilarityOptions) ([]models.SimilarityScore, error) {
+	ret := _m.Called(ctx, id, options)
+
+	var r0 []models.SimilarityScore
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.SimilarityOptions) []models.SimilarityScore); ok {
+		r0 = rf(ctx, id, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SimilarityScore)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.SimilarityOptions) error); ok {
+		r1 = rf(ctx, id, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCover provides a mock function with given fields: ctx, sceneID
 func (_m *SceneReaderWriter) GetCover(ctx context.Context, sceneID int) ([]byte, error) {
 	ret := _m.Called(ctx, sceneID)
@@ -624,6 +670,29 @@ func (_m *SceneReaderWriter) GetTagIDs(ctx context.Context, relatedID int) ([]in
 	return r0, r1
 }
 
+// GetUserActivity provides a mock function with given fields: ctx, sceneID
+func (_m *SceneReaderWriter) GetUserActivity(ctx context.Context, sceneID int) ([]*models.SceneUserActivity, error) {
+	ret := _m.Called(ctx, sceneID)
+
+	var r0 []*models.SceneUserActivity
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.SceneUserActivity); ok {
+		r0 = rf(ctx, sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SceneUserActivity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HasCover provides a mock function with given fields: ctx, sceneID
 func (_m *SceneReaderWriter) HasCover(ctx context.Context, sceneID int) (bool, error) {
 	ret := _m.Called(ctx, sceneID)
@@ -14,6 +14,27 @@ type StudioReaderWriter struct {
 	mock.Mock
 }
 
+// AddImage provides a mock function with given fields: ctx, studioID, image, primary
+func (_m *StudioReaderWriter) AddImage(ctx context.Context, studioID int, image []byte, primary bool) (int, error) {
+	ret := _m.Called(ctx, studioID, image, primary)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int, []byte, bool) int); ok {
+		r0 = rf(ctx, studioID, image, primary)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, []byte, bool) error); ok {
+		r1 = rf(ctx, studioID, image, primary)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // All provides a mock function with given fields: ctx
 func (_m *StudioReaderWriter) All(ctx context.Context) ([]*models.Studio, error) {
 	ret := _m.Called(ctx)
@@ -86,6 +107,43 @@ func (_m *StudioReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// DestroyImageByID provides a mock function with given fields: ctx, studioID, imageID
+func (_m *StudioReaderWriter) DestroyImageByID(ctx context.Context, studioID int, imageID int) error {
+	ret := _m.Called(ctx, studioID, imageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, studioID, imageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Facets provides a mock function with given fields: ctx, studioFilter, findFilter, options
+func (_m *StudioReaderWriter) Facets(ctx context.Context, studioFilter *models.StudioFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, studioFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.StudioFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, studioFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.StudioFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, studioFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *StudioReaderWriter) Find(ctx context.Context, id int) (*models.Studio, error) {
 	ret := _m.Called(ctx, id)
@@ -247,6 +305,52 @@ func (_m *StudioReaderWriter) GetImage(ctx context.Context, studioID int) ([]byt
 	return r0, r1
 }
 
+// GetImageByID provides a mock function with given fields: ctx, studioID, imageID
+func (_m *StudioReaderWriter) GetImageByID(ctx context.Context, studioID int, imageID int) ([]byte, error) {
+	ret := _m.Called(ctx, studioID, imageID)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []byte); ok {
+		r0 = rf(ctx, studioID, imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, studioID, imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImages provides a mock function with given fields: ctx, studioID
+func (_m *StudioReaderWriter) GetImages(ctx context.Context, studioID int) ([]models.EntityImage, error) {
+	ret := _m.Called(ctx, studioID)
+
+	var r0 []models.EntityImage
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.EntityImage); ok {
+		r0 = rf(ctx, studioID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EntityImage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, studioID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStashIDs provides a mock function with given fields: ctx, relatedID
 func (_m *StudioReaderWriter) GetStashIDs(ctx context.Context, relatedID int) ([]models.StashID, error) {
 	ret := _m.Called(ctx, relatedID)
@@ -291,6 +395,20 @@ func (_m *StudioReaderWriter) HasImage(ctx context.Context, studioID int) (bool,
 	return r0, r1
 }
 
+// Merge provides a mock function with given fields: ctx, source, destination
+func (_m *StudioReaderWriter) Merge(ctx context.Context, source []int, destination int) error {
+	ret := _m.Called(ctx, source, destination)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int) error); ok {
+		r0 = rf(ctx, source, destination)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: ctx, studioFilter, findFilter
 func (_m *StudioReaderWriter) Query(ctx context.Context, studioFilter *models.StudioFilterType, findFilter *models.FindFilterType) ([]*models.Studio, int, error) {
 	ret := _m.Called(ctx, studioFilter, findFilter)
@@ -344,6 +462,20 @@ func (_m *StudioReaderWriter) QueryForAutoTag(ctx context.Context, words []strin
 	return r0, r1
 }
 
+// SetPrimaryImage provides a mock function with given fields: ctx, studioID, imageID
+func (_m *StudioReaderWriter) SetPrimaryImage(ctx context.Context, studioID int, imageID int) error {
+	ret := _m.Called(ctx, studioID, imageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, studioID, imageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, updatedStudio
 func (_m *StudioReaderWriter) Update(ctx context.Context, updatedStudio *models.Studio) error {
 	ret := _m.Called(ctx, updatedStudio)
@@ -14,6 +14,27 @@ type TagReaderWriter struct {
 	mock.Mock
 }
 
+// AddImage provides a mock function with given fields: ctx, tagID, image, primary
+func (_m *TagReaderWriter) AddImage(ctx context.Context, tagID int, image []byte, primary bool) (int, error) {
+	ret := _m.Called(ctx, tagID, image, primary)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int, []byte, bool) int); ok {
+		r0 = rf(ctx, tagID, image, primary)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, []byte, bool) error); ok {
+		r1 = rf(ctx, tagID, image, primary)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // All provides a mock function with given fields: ctx
 func (_m *TagReaderWriter) All(ctx context.Context) ([]*models.Tag, error) {
 	ret := _m.Called(ctx)
@@ -86,6 +107,43 @@ func (_m *TagReaderWriter) Destroy(ctx context.Context, id int) error {
 	return r0
 }
 
+// DestroyImageByID provides a mock function with given fields: ctx, tagID, imageID
+func (_m *TagReaderWriter) DestroyImageByID(ctx context.Context, tagID int, imageID int) error {
+	ret := _m.Called(ctx, tagID, imageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, tagID, imageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Facets provides a mock function with given fields: ctx, tagFilter, findFilter, options
+func (_m *TagReaderWriter) Facets(ctx context.Context, tagFilter *models.TagFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	ret := _m.Called(ctx, tagFilter, findFilter, options)
+
+	var r0 []*models.Facet
+	if rf, ok := ret.Get(0).(func(context.Context, *models.TagFilterType, *models.FindFilterType, models.FacetOptions) []*models.Facet); ok {
+		r0 = rf(ctx, tagFilter, findFilter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Facet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.TagFilterType, *models.FindFilterType, models.FacetOptions) error); ok {
+		r1 = rf(ctx, tagFilter, findFilter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Find provides a mock function with given fields: ctx, id
 func (_m *TagReaderWriter) Find(ctx context.Context, id int) (*models.Tag, error) {
 	ret := _m.Called(ctx, id)
@@ -431,6 +489,52 @@ func (_m *TagReaderWriter) GetImage(ctx context.Context, tagID int) ([]byte, err
 	return r0, r1
 }
 
+// GetImageByID provides a mock function with given fields: ctx, tagID, imageID
+func (_m *TagReaderWriter) GetImageByID(ctx context.Context, tagID int, imageID int) ([]byte, error) {
+	ret := _m.Called(ctx, tagID, imageID)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []byte); ok {
+		r0 = rf(ctx, tagID, imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, tagID, imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImages provides a mock function with given fields: ctx, tagID
+func (_m *TagReaderWriter) GetImages(ctx context.Context, tagID int) ([]models.EntityImage, error) {
+	ret := _m.Called(ctx, tagID)
+
+	var r0 []models.EntityImage
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.EntityImage); ok {
+		r0 = rf(ctx, tagID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EntityImage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, tagID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HasImage provides a mock function with given fields: ctx, tagID
 func (_m *TagReaderWriter) HasImage(ctx context.Context, tagID int) (bool, error) {
 	ret := _m.Called(ctx, tagID)
@@ -519,6 +623,20 @@ func (_m *TagReaderWriter) QueryForAutoTag(ctx context.Context, words []string)
 	return r0, r1
 }
 
+// SetPrimaryImage provides a mock function with given fields: ctx, tagID, imageID
+func (_m *TagReaderWriter) SetPrimaryImage(ctx context.Context, tagID int, imageID int) error {
+	ret := _m.Called(ctx, tagID, imageID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, tagID, imageID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, updatedTag
 func (_m *TagReaderWriter) Update(ctx context.Context, updatedTag *models.Tag) error {
 	ret := _m.Called(ctx, updatedTag)
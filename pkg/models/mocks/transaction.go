@@ -44,17 +44,26 @@ func (*TxnManager) Reset() error {
 
 func NewTxnRepository() models.Repository {
 	return models.Repository{
-		TxnManager:     &TxnManager{},
-		Gallery:        &GalleryReaderWriter{},
-		GalleryChapter: &GalleryChapterReaderWriter{},
-		Image:          &ImageReaderWriter{},
-		Movie:          &MovieReaderWriter{},
-		Performer:      &PerformerReaderWriter{},
-		Scene:          &SceneReaderWriter{},
-		SceneMarker:    &SceneMarkerReaderWriter{},
-		ScrapedItem:    &ScrapedItemReaderWriter{},
-		Studio:         &StudioReaderWriter{},
-		Tag:            &TagReaderWriter{},
-		SavedFilter:    &SavedFilterReaderWriter{},
+		TxnManager:         &TxnManager{},
+		Gallery:            &GalleryReaderWriter{},
+		GalleryChapter:     &GalleryChapterReaderWriter{},
+		Image:              &ImageReaderWriter{},
+		Movie:              &MovieReaderWriter{},
+		Performer:          &PerformerReaderWriter{},
+		Scene:              &SceneReaderWriter{},
+		SceneMarker:        &SceneMarkerReaderWriter{},
+		ScrapedItem:        &ScrapedItemReaderWriter{},
+		Studio:             &StudioReaderWriter{},
+		Tag:                &TagReaderWriter{},
+		SavedFilter:        &SavedFilterReaderWriter{},
+		Playlist:           &PlaylistReaderWriter{},
+		TagImplication:     &TagImplicationReaderWriter{},
+		User:               &UserReaderWriter{},
+		APIKey:             &APIKeyReaderWriter{},
+		RestrictionProfile: &RestrictionProfileReaderWriter{},
+		AuditEvent:         &AuditEventReaderWriter{},
+		ShareLink:          &ShareLinkReaderWriter{},
+		Edit:               &EditReaderWriter{},
+		Statistics:         &StatisticsReader{},
 	}
 }
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type APIKeyScope string
+
+const (
+	// APIKeyScopeRead permits GraphQL queries and subscriptions.
+	APIKeyScopeRead APIKeyScope = "READ"
+	// APIKeyScopeMutate permits GraphQL mutations.
+	APIKeyScopeMutate APIKeyScope = "MUTATE"
+	// APIKeyScopeStream permits requests outside of GraphQL, such as for
+	// streams, images and playlists.
+	APIKeyScopeStream APIKeyScope = "STREAM"
+	// APIKeyScopePlugin permits running plugin tasks and requests for
+	// plugin assets.
+	APIKeyScopePlugin APIKeyScope = "PLUGIN"
+)
+
+var AllAPIKeyScope = []APIKeyScope{
+	APIKeyScopeRead,
+	APIKeyScopeMutate,
+	APIKeyScopeStream,
+	APIKeyScopePlugin,
+}
+
+func (e APIKeyScope) IsValid() bool {
+	switch e {
+	case APIKeyScopeRead, APIKeyScopeMutate, APIKeyScopeStream, APIKeyScopePlugin:
+		return true
+	}
+	return false
+}
+
+func (e APIKeyScope) String() string {
+	return string(e)
+}
+
+func (e *APIKeyScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = APIKeyScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid APIKeyScope", str)
+	}
+	return nil
+}
+
+func (e APIKeyScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+var (
+	ErrAPIKeyNameRequired   = errors.New("api key name must not be empty")
+	ErrAPIKeyScopesRequired = errors.New("api key must have at least one scope")
+	ErrInvalidAPIKeyScope   = errors.New("invalid api key scope")
+)
+
+// APIKey is a key which authenticates requests as a user, restricted to its
+// scopes.
+type APIKey struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`
+	// KeyHash is the SHA-256 hash of the key. The key itself is not stored.
+	KeyHash string        `json:"-"`
+	Scopes  []APIKeyScope `json:"scopes"`
+	// RestrictionProfileID is the restriction profile of the session the
+	// key was created from, if any, which is applied to requests made with
+	// the key as well as that of the user.
+	RestrictionProfileID *int       `json:"restriction_profile_id"`
+	ExpiresAt            *time.Time `json:"expires_at"`
+	LastUsedAt           *time.Time `json:"last_used_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+// Validate returns an error if the key has an empty name, or no or invalid
+// scopes.
+func (k APIKey) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return ErrAPIKeyNameRequired
+	}
+
+	if len(k.Scopes) == 0 {
+		return ErrAPIKeyScopesRequired
+	}
+
+	for _, s := range k.Scopes {
+		if !s.IsValid() {
+			return fmt.Errorf("%w: %q", ErrInvalidAPIKeyScope, s)
+		}
+	}
+
+	return nil
+}
+
+// HasScope returns true if the key has the scope.
+func (k APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Expired returns true if the key has expired at t.
+func (k APIKey) Expired(t time.Time) bool {
+	return k.ExpiresAt != nil && !t.Before(*k.ExpiresAt)
+}
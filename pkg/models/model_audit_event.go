@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	// AuditActionLogin is recorded when a user logs in.
+	AuditActionLogin AuditAction = "LOGIN"
+	// AuditActionLoginFailed is recorded when a login or API key is
+	// rejected.
+	AuditActionLoginFailed AuditAction = "LOGIN_FAILED"
+	// AuditActionLogout is recorded when a user logs out.
+	AuditActionLogout AuditAction = "LOGOUT"
+	// AuditActionMutation is recorded for each GraphQL mutation, whether or
+	// not it succeeded.
+	AuditActionMutation AuditAction = "MUTATION"
+)
+
+var AllAuditAction = []AuditAction{
+	AuditActionLogin,
+	AuditActionLoginFailed,
+	AuditActionLogout,
+	AuditActionMutation,
+}
+
+func (e AuditAction) IsValid() bool {
+	switch e {
+	case AuditActionLogin, AuditActionLoginFailed, AuditActionLogout, AuditActionMutation:
+		return true
+	}
+	return false
+}
+
+func (e AuditAction) String() string {
+	return string(e)
+}
+
+func (e *AuditAction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditAction", str)
+	}
+	return nil
+}
+
+func (e AuditAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// AuditEvent is an entry of the audit log.
+type AuditEvent struct {
+	ID     int         `json:"id"`
+	Action AuditAction `json:"action"`
+	// Username is the name of the user, or the name given to a failed login.
+	// It is empty if the request was not authenticated.
+	Username string `json:"username"`
+	// APIKeyName is the name of the API key the request was authenticated
+	// with, if any.
+	APIKeyName *string `json:"api_key_name"`
+	IPAddress  string  `json:"ip_address"`
+	// Operation is the name of the mutation, or the method of the login.
+	Operation string `json:"operation"`
+	// TargetIDs are the ids of the objects the mutation acted on.
+	TargetIDs []string `json:"target_ids"`
+	// Arguments is a summary of the arguments of the mutation, with secrets
+	// redacted and long values truncated.
+	Arguments map[string]interface{} `json:"arguments"`
+	// Error is the error returned by a failed mutation, or the reason a
+	// login was refused.
+	Error     *string   `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type EditEntityType string
+
+const (
+	EditEntityTypeScene     EditEntityType = "SCENE"
+	EditEntityTypeImage     EditEntityType = "IMAGE"
+	EditEntityTypeGallery   EditEntityType = "GALLERY"
+	EditEntityTypePerformer EditEntityType = "PERFORMER"
+	EditEntityTypeStudio    EditEntityType = "STUDIO"
+	EditEntityTypeTag       EditEntityType = "TAG"
+	EditEntityTypeMovie     EditEntityType = "MOVIE"
+)
+
+var AllEditEntityType = []EditEntityType{
+	EditEntityTypeScene,
+	EditEntityTypeImage,
+	EditEntityTypeGallery,
+	EditEntityTypePerformer,
+	EditEntityTypeStudio,
+	EditEntityTypeTag,
+	EditEntityTypeMovie,
+}
+
+func (e EditEntityType) IsValid() bool {
+	switch e {
+	case EditEntityTypeScene, EditEntityTypeImage, EditEntityTypeGallery, EditEntityTypePerformer, EditEntityTypeStudio, EditEntityTypeTag, EditEntityTypeMovie:
+		return true
+	}
+	return false
+}
+
+func (e EditEntityType) String() string {
+	return string(e)
+}
+
+func (e *EditEntityType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EditEntityType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EditEntityType", str)
+	}
+	return nil
+}
+
+func (e EditEntityType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type EditOperation string
+
+const (
+	EditOperationCreate     EditOperation = "CREATE"
+	EditOperationUpdate     EditOperation = "UPDATE"
+	EditOperationBulkUpdate EditOperation = "BULK_UPDATE"
+	EditOperationMerge      EditOperation = "MERGE"
+	EditOperationRevert     EditOperation = "REVERT"
+)
+
+var AllEditOperation = []EditOperation{
+	EditOperationCreate,
+	EditOperationUpdate,
+	EditOperationBulkUpdate,
+	EditOperationMerge,
+	EditOperationRevert,
+}
+
+func (e EditOperation) IsValid() bool {
+	switch e {
+	case EditOperationCreate, EditOperationUpdate, EditOperationBulkUpdate, EditOperationMerge, EditOperationRevert:
+		return true
+	}
+	return false
+}
+
+func (e EditOperation) String() string {
+	return string(e)
+}
+
+func (e *EditOperation) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EditOperation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EditOperation", str)
+	}
+	return nil
+}
+
+func (e EditOperation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type EditSource string
+
+const (
+	EditSourceUI       EditSource = "UI"
+	EditSourcePlugin   EditSource = "PLUGIN"
+	EditSourceIdentify EditSource = "IDENTIFY"
+	EditSourceAutoTag  EditSource = "AUTOTAG"
+)
+
+var AllEditSource = []EditSource{
+	EditSourceUI,
+	EditSourcePlugin,
+	EditSourceIdentify,
+	EditSourceAutoTag,
+}
+
+func (e EditSource) IsValid() bool {
+	switch e {
+	case EditSourceUI, EditSourcePlugin, EditSourceIdentify, EditSourceAutoTag:
+		return true
+	}
+	return false
+}
+
+func (e EditSource) String() string {
+	return string(e)
+}
+
+func (e *EditSource) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EditSource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EditSource", str)
+	}
+	return nil
+}
+
+func (e EditSource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// EditChange records the value of a single field before and after an edit.
+// Relationship fields hold the full list of related values.
+type EditChange struct {
+	Field    string      `json:"field"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
+
+// Edit is a revision history record of the changes made to an entity.
+type Edit struct {
+	ID         int            `json:"id"`
+	EntityType EditEntityType `json:"entity_type"`
+	EntityID   int            `json:"entity_id"`
+	Operation  EditOperation  `json:"operation"`
+	Source     EditSource     `json:"source"`
+	Changes    []EditChange   `json:"changes"`
+	CreatedAt  time.Time      `json:"created_at"`
+	RevertedAt *time.Time     `json:"reverted_at"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Playlist is an ordered list of scenes, scene markers and images. Smart
+// playlists are backed by a saved filter, and their items are the results
+// of the filter rather than stored items.
+type Playlist struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// SavedFilterID is set for smart playlists
+	SavedFilterID *int `json:"saved_filter_id"`
+	// FilterLimit is the maximum number of items of a smart playlist
+	FilterLimit *int      `json:"filter_limit"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsSmart returns true if the items of the playlist are the results of a
+// saved filter.
+func (p Playlist) IsSmart() bool {
+	return p.SavedFilterID != nil
+}
+
+type Playlists []*Playlist
+
+func (m *Playlists) Append(o interface{}) {
+	*m = append(*m, o.(*Playlist))
+}
+
+func (m *Playlists) New() interface{} {
+	return &Playlist{}
+}
+
+var ErrPlaylistItemObject = errors.New("playlist item must have exactly one of scene, scene marker or image")
+
+// PlaylistItem is an entry of a playlist. Exactly one of SceneID,
+// SceneMarkerID and ImageID is set. Items of smart playlists are not
+// stored, and have a zero ID.
+type PlaylistItem struct {
+	ID            int  `json:"id"`
+	PlaylistID    int  `json:"playlist_id"`
+	Position      int  `json:"position"`
+	SceneID       *int `json:"scene_id"`
+	SceneMarkerID *int `json:"scene_marker_id"`
+	ImageID       *int `json:"image_id"`
+}
+
+// Validate returns ErrPlaylistItemObject if the item does not refer to
+// exactly one object.
+func (i PlaylistItem) Validate() error {
+	n := 0
+	for _, id := range []*int{i.SceneID, i.SceneMarkerID, i.ImageID} {
+		if id != nil {
+			n++
+		}
+	}
+
+	if n != 1 {
+		return ErrPlaylistItemObject
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRestrictionProfileNameRequired = errors.New("restriction profile name must not be empty")
+	ErrRestrictionProfileInUse        = errors.New("restriction profile is in use")
+)
+
+// RestrictionProfile hides content from the users and sessions it is applied
+// to. Scenes, images and galleries are hidden if they have any of the tags,
+// belong to any of the studios or feature any of the performers. Performers
+// are hidden if they are listed or have any of the tags, and studios if they
+// are listed. Tags and studios include their children. Scenes, images and
+// galleries matching the filter of their type are also hidden.
+type RestrictionProfile struct {
+	ID            int                `json:"id"`
+	Name          string             `json:"name"`
+	TagIDs        []int              `json:"tag_ids"`
+	StudioIDs     []int              `json:"studio_ids"`
+	PerformerIDs  []int              `json:"performer_ids"`
+	SceneFilter   *SceneFilterType   `json:"scene_filter"`
+	ImageFilter   *ImageFilterType   `json:"image_filter"`
+	GalleryFilter *GalleryFilterType `json:"gallery_filter"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// Validate returns an error if the profile has an empty name.
+func (p RestrictionProfile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return ErrRestrictionProfileNameRequired
+	}
+
+	return nil
+}
+
+type restrictionContextKey int
+
+const restrictionProfileIDsKey restrictionContextKey = iota + 1
+
+// WithRestrictionProfileIDs returns a context in which the content hidden by
+// the restriction profiles with the ids is excluded from the results of
+// queries, as if it did not exist. An empty list removes any restriction.
+func WithRestrictionProfileIDs(ctx context.Context, ids []int) context.Context {
+	return context.WithValue(ctx, restrictionProfileIDsKey, ids)
+}
+
+// RestrictionProfileIDsFromContext returns the ids of the restriction
+// profiles set on the context.
+func RestrictionProfileIDsFromContext(ctx context.Context) []int {
+	ids, _ := ctx.Value(restrictionProfileIDsKey).([]int)
+	return ids
+}
+
+// IsRestricted returns true if the context has any restriction profiles.
+func IsRestricted(ctx context.Context) bool {
+	return len(RestrictionProfileIDsFromContext(ctx)) > 0
+}
@@ -65,6 +65,8 @@ type SavedFilter struct {
 	Name string     `json:"name"`
 	// JSON-encoded filter string
 	Filter string `json:"filter"`
+	// Text filter query
+	Query string `json:"query"`
 }
 
 type SavedFilters []*SavedFilter
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ShareLinkEntity is the type of object a share link shares.
+type ShareLinkEntity string
+
+const (
+	ShareLinkEntityScene   ShareLinkEntity = "SCENE"
+	ShareLinkEntityGallery ShareLinkEntity = "GALLERY"
+	ShareLinkEntityImage   ShareLinkEntity = "IMAGE"
+)
+
+var AllShareLinkEntity = []ShareLinkEntity{
+	ShareLinkEntityScene,
+	ShareLinkEntityGallery,
+	ShareLinkEntityImage,
+}
+
+func (e ShareLinkEntity) IsValid() bool {
+	switch e {
+	case ShareLinkEntityScene, ShareLinkEntityGallery, ShareLinkEntityImage:
+		return true
+	}
+	return false
+}
+
+func (e ShareLinkEntity) String() string {
+	return string(e)
+}
+
+func (e *ShareLinkEntity) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ShareLinkEntity(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ShareLinkEntity", str)
+	}
+	return nil
+}
+
+func (e ShareLinkEntity) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+var (
+	ErrInvalidShareLinkEntity = errors.New("invalid share link entity")
+	ErrShareLinkEntityID      = errors.New("share link must have an entity id")
+)
+
+// ShareLink grants access to a scene, gallery or image without logging in,
+// until it expires or is revoked.
+type ShareLink struct {
+	ID         int             `json:"id"`
+	EntityType ShareLinkEntity `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	// UserID is the id of the user who created the link, or nil if it was
+	// created by the owner.
+	UserID *int `json:"user_id"`
+	// RestrictionProfileID is the restriction profile of the session the
+	// link was created from, if any. It is applied to requests made with
+	// the link as well as that of the user who created it.
+	RestrictionProfileID *int `json:"restriction_profile_id"`
+	// AllowDownload permits downloading the original files.
+	AllowDownload bool      `json:"allow_download"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Validate returns an error if the link has an invalid entity type or no
+// entity id.
+func (l ShareLink) Validate() error {
+	if !l.EntityType.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidShareLinkEntity, l.EntityType)
+	}
+
+	if l.EntityID == 0 {
+		return ErrShareLinkEntityID
+	}
+
+	return nil
+}
+
+// Expired returns true if the link has expired at t.
+func (l ShareLink) Expired(t time.Time) bool {
+	return !t.Before(l.ExpiresAt)
+}
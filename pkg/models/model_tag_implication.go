@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTagImplicationSource = errors.New("tag implication must have exactly one of source tag or source performer")
+	ErrTagImplicationSelf   = errors.New("tag cannot imply itself")
+)
+
+// TagImplication is a rule adding the implied tag to scenes, images and
+// galleries which have the source tag or source performer. Exactly one of
+// SourceTagID and SourcePerformerID is set. Rules are applied transitively:
+// tags added by a rule may imply further tags.
+type TagImplication struct {
+	ID                int       `json:"id"`
+	SourceTagID       *int      `json:"source_tag_id"`
+	SourcePerformerID *int      `json:"source_performer_id"`
+	ImpliedTagID      int       `json:"implied_tag_id"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// Validate returns an error if the rule does not have exactly one source,
+// or if the source tag is the implied tag.
+func (i TagImplication) Validate() error {
+	if (i.SourceTagID == nil) == (i.SourcePerformerID == nil) {
+		return ErrTagImplicationSource
+	}
+
+	if i.SourceTagID != nil && *i.SourceTagID == i.ImpliedTagID {
+		return ErrTagImplicationSelf
+	}
+
+	return nil
+}
+
+// TagImplicationObjectType is the type of object to which tag implications
+// are applied.
+type TagImplicationObjectType string
+
+const (
+	TagImplicationObjectTypeScene   TagImplicationObjectType = "scene"
+	TagImplicationObjectTypeImage   TagImplicationObjectType = "image"
+	TagImplicationObjectTypeGallery TagImplicationObjectType = "gallery"
+)
+
+var AllTagImplicationObjectType = []TagImplicationObjectType{
+	TagImplicationObjectTypeScene,
+	TagImplicationObjectTypeImage,
+	TagImplicationObjectTypeGallery,
+}
+
+// ImpliedTag is a tag implied for an object which the object does not have.
+type ImpliedTag struct {
+	ObjectID int `db:"object_id"`
+	TagID    int `db:"tag_id"`
+}
+
+// GroupImpliedTags returns the ids of the objects of the implied tags in
+// order of first appearance, and the ids of the tags implied for each
+// object.
+func GroupImpliedTags(tags []*ImpliedTag) ([]int, map[int][]int) {
+	var objectIDs []int
+	tagIDs := make(map[int][]int)
+	for _, t := range tags {
+		if _, found := tagIDs[t.ObjectID]; !found {
+			objectIDs = append(objectIDs, t.ObjectID)
+		}
+		tagIDs[t.ObjectID] = append(tagIDs[t.ObjectID], t.TagID)
+	}
+
+	return objectIDs, tagIDs
+}
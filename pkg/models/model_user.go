@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type UserRole string
+
+const (
+	// UserRoleAdmin may access everything, including the configuration and
+	// destructive operations.
+	UserRoleAdmin UserRole = "ADMIN"
+	// UserRoleEditor may browse and edit metadata.
+	UserRoleEditor UserRole = "EDITOR"
+	// UserRoleViewer may browse, and record their own activity.
+	UserRoleViewer UserRole = "VIEWER"
+)
+
+var AllUserRole = []UserRole{
+	UserRoleAdmin,
+	UserRoleEditor,
+	UserRoleViewer,
+}
+
+func (e UserRole) IsValid() bool {
+	switch e {
+	case UserRoleAdmin, UserRoleEditor, UserRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e UserRole) String() string {
+	return string(e)
+}
+
+func (e *UserRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserRole", str)
+	}
+	return nil
+}
+
+func (e UserRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e UserRole) level() int {
+	switch e {
+	case UserRoleAdmin:
+		return 3
+	case UserRoleEditor:
+		return 2
+	case UserRoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// Includes returns true if the role has at least the permissions of other.
+func (e UserRole) Includes(other UserRole) bool {
+	return e.level() >= other.level()
+}
+
+var (
+	ErrUsernameRequired = errors.New("username must not be empty")
+	ErrInvalidUserRole  = errors.New("invalid user role")
+)
+
+// User is an account which may log in to the server. The user named by the
+// configured username is the owner, and is always an admin.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	// PasswordHash is the bcrypt hash of the password. It is empty for the
+	// owner, who logs in with the configured credentials.
+	PasswordHash string   `json:"-"`
+	Role         UserRole `json:"role"`
+	// RestrictionProfileID is the restriction profile applied to the user,
+	// if any.
+	RestrictionProfileID *int `json:"restriction_profile_id"`
+	// TOTPSecret is the base32 encoded secret of the authenticator app of
+	// the user. It is set when enrollment starts, and TOTPEnabled is set
+	// once a code of the secret has been confirmed.
+	TOTPSecret  string `json:"-"`
+	TOTPEnabled bool   `json:"totp_enabled"`
+	// TOTPLastStep is the time step of the last code used, so that codes
+	// cannot be used twice.
+	TOTPLastStep int64 `json:"-"`
+	// RecoveryCodeHashes are the hashes of the unused recovery codes, which
+	// may be used once each instead of a code of the authenticator app.
+	RecoveryCodeHashes []string `json:"-"`
+	// OIDCIssuer and OIDCSubject identify the OpenID Connect user the user
+	// logs in as with single sign-on, if any.
+	OIDCIssuer  string    `json:"-"`
+	OIDCSubject string    `json:"oidc_subject"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate returns an error if the user has an empty username or an invalid
+// role.
+func (u User) Validate() error {
+	if strings.TrimSpace(u.Username) == "" {
+		return ErrUsernameRequired
+	}
+
+	if !u.Role.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidUserRole, u.Role)
+	}
+
+	return nil
+}
+
+// HasRole returns true if the role of the user includes role.
+func (u User) HasRole(role UserRole) bool {
+	return u.Role.Includes(role)
+}
+
+// TOTPEnrollment is the secret of the authenticator app of a user enrolling
+// in two-factor authentication.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth provisioning URI of the secret, which may be
+	// opened or scanned as a QR code by authenticator apps.
+	URI string `json:"uri"`
+}
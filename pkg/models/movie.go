@@ -37,6 +37,7 @@ type MovieReader interface {
 	All(ctx context.Context) ([]*Movie, error)
 	Count(ctx context.Context) (int, error)
 	Query(ctx context.Context, movieFilter *MovieFilterType, findFilter *FindFilterType) ([]*Movie, int, error)
+	Facets(ctx context.Context, movieFilter *MovieFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	QueryCount(ctx context.Context, movieFilter *MovieFilterType, findFilter *FindFilterType) (int, error)
 	GetFrontImage(ctx context.Context, movieID int) ([]byte, error)
 	HasFrontImage(ctx context.Context, movieID int) (bool, error)
@@ -55,6 +56,7 @@ type MovieWriter interface {
 	Destroy(ctx context.Context, id int) error
 	UpdateFrontImage(ctx context.Context, movieID int, frontImage []byte) error
 	UpdateBackImage(ctx context.Context, movieID int, backImage []byte) error
+	Merge(ctx context.Context, source []int, destination int) error
 }
 
 type MovieReaderWriter interface {
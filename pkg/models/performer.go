@@ -207,6 +207,7 @@ type PerformerReader interface {
 	FindByNames(ctx context.Context, names []string, nocase bool) ([]*Performer, error)
 	FindByStashID(ctx context.Context, stashID StashID) ([]*Performer, error)
 	FindByStashIDStatus(ctx context.Context, hasStashID bool, stashboxEndpoint string) ([]*Performer, error)
+	FindSimilar(ctx context.Context, id int, options SimilarityOptions) ([]SimilarityScore, error)
 	CountByTagID(ctx context.Context, tagID int) (int, error)
 	Count(ctx context.Context) (int, error)
 	All(ctx context.Context) ([]*Performer, error)
@@ -214,10 +215,13 @@ type PerformerReader interface {
 	// support the query needed
 	QueryForAutoTag(ctx context.Context, words []string) ([]*Performer, error)
 	Query(ctx context.Context, performerFilter *PerformerFilterType, findFilter *FindFilterType) ([]*Performer, int, error)
+	Facets(ctx context.Context, performerFilter *PerformerFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	QueryCount(ctx context.Context, galleryFilter *PerformerFilterType, findFilter *FindFilterType) (int, error)
 	AliasLoader
 	GetImage(ctx context.Context, performerID int) ([]byte, error)
 	HasImage(ctx context.Context, performerID int) (bool, error)
+	GetImages(ctx context.Context, performerID int) ([]EntityImage, error)
+	GetImageByID(ctx context.Context, performerID int, imageID int) ([]byte, error)
 	StashIDLoader
 	TagIDLoader
 }
@@ -228,6 +232,10 @@ type PerformerWriter interface {
 	Update(ctx context.Context, updatedPerformer *Performer) error
 	Destroy(ctx context.Context, id int) error
 	UpdateImage(ctx context.Context, performerID int, image []byte) error
+	AddImage(ctx context.Context, performerID int, image []byte, primary bool) (int, error)
+	SetPrimaryImage(ctx context.Context, performerID int, imageID int) error
+	DestroyImageByID(ctx context.Context, performerID int, imageID int) error
+	Merge(ctx context.Context, source []int, destination int) error
 }
 
 type PerformerReaderWriter interface {
@@ -0,0 +1,30 @@
+package models
+
+import "context"
+
+type PlaylistReader interface {
+	Find(ctx context.Context, id int) (*Playlist, error)
+	FindMany(ctx context.Context, ids []int) ([]*Playlist, error)
+	All(ctx context.Context) ([]*Playlist, error)
+	Count(ctx context.Context) (int, error)
+	// GetItems returns the stored items of the playlist in order.
+	GetItems(ctx context.Context, playlistID int) ([]*PlaylistItem, error)
+}
+
+type PlaylistWriter interface {
+	Create(ctx context.Context, newPlaylist *Playlist) error
+	Update(ctx context.Context, updatedPlaylist *Playlist) error
+	Destroy(ctx context.Context, id int) error
+	// AddItems appends items to the end of the playlist.
+	AddItems(ctx context.Context, playlistID int, items []*PlaylistItem) error
+	// RemoveItems removes the items with the given ids from the playlist.
+	RemoveItems(ctx context.Context, playlistID int, itemIDs []int) error
+	// ReorderItems sets the order of the items of the playlist. itemIDs
+	// must contain the ids of all items of the playlist.
+	ReorderItems(ctx context.Context, playlistID int, itemIDs []int) error
+}
+
+type PlaylistReaderWriter interface {
+	PlaylistReader
+	PlaylistWriter
+}
@@ -14,17 +14,26 @@ type TxnManager interface {
 type Repository struct {
 	TxnManager
 
-	File           file.Store
-	Folder         file.FolderStore
-	Gallery        GalleryReaderWriter
-	GalleryChapter GalleryChapterReaderWriter
-	Image          ImageReaderWriter
-	Movie          MovieReaderWriter
-	Performer      PerformerReaderWriter
-	Scene          SceneReaderWriter
-	SceneMarker    SceneMarkerReaderWriter
-	ScrapedItem    ScrapedItemReaderWriter
-	Studio         StudioReaderWriter
-	Tag            TagReaderWriter
-	SavedFilter    SavedFilterReaderWriter
+	File               file.Store
+	Folder             file.FolderStore
+	Gallery            GalleryReaderWriter
+	GalleryChapter     GalleryChapterReaderWriter
+	Image              ImageReaderWriter
+	Movie              MovieReaderWriter
+	Performer          PerformerReaderWriter
+	Scene              SceneReaderWriter
+	SceneMarker        SceneMarkerReaderWriter
+	ScrapedItem        ScrapedItemReaderWriter
+	Studio             StudioReaderWriter
+	Tag                TagReaderWriter
+	SavedFilter        SavedFilterReaderWriter
+	Playlist           PlaylistReaderWriter
+	TagImplication     TagImplicationReaderWriter
+	User               UserReaderWriter
+	APIKey             APIKeyReaderWriter
+	RestrictionProfile RestrictionProfileReaderWriter
+	AuditEvent         AuditEventReaderWriter
+	ShareLink          ShareLinkReaderWriter
+	Edit               EditReaderWriter
+	Statistics         StatisticsReader
 }
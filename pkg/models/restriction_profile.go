@@ -0,0 +1,19 @@
+package models
+
+import "context"
+
+type RestrictionProfileReader interface {
+	Find(ctx context.Context, id int) (*RestrictionProfile, error)
+	All(ctx context.Context) ([]*RestrictionProfile, error)
+}
+
+type RestrictionProfileWriter interface {
+	Create(ctx context.Context, newProfile *RestrictionProfile) error
+	Update(ctx context.Context, updatedProfile *RestrictionProfile) error
+	Destroy(ctx context.Context, id int) error
+}
+
+type RestrictionProfileReaderWriter interface {
+	RestrictionProfileReader
+	RestrictionProfileWriter
+}
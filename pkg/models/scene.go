@@ -8,7 +8,8 @@ import (
 
 type PHashDuplicationCriterionInput struct {
 	Duplicated *bool `json:"duplicated"`
-	// Currently unimplemented
+	// Maximum hamming distance between phashes to be considered duplicates.
+	// Zero matches identical phashes only.
 	Distance *int `json:"distance"`
 }
 
@@ -29,6 +30,8 @@ type SceneFilterType struct {
 	Phash *StringCriterionInput `json:"phash"`
 	// Filter by phash distance
 	PhashDistance *PhashDistanceCriterionInput `json:"phash_distance"`
+	// Filter by phash similarity to another scene
+	PhashSimilarTo *PhashSimilarityCriterionInput `json:"phash_similar_to"`
 	// Filter by path
 	Path *StringCriterionInput `json:"path"`
 	// Filter by file count
@@ -154,6 +157,7 @@ type SceneReader interface {
 	FindByPerformerID(ctx context.Context, performerID int) ([]*Scene, error)
 	FindByGalleryID(ctx context.Context, performerID int) ([]*Scene, error)
 	FindDuplicates(ctx context.Context, distance int, durationDiff float64) ([][]*Scene, error)
+	FindSimilar(ctx context.Context, id int, options SimilarityOptions) ([]SimilarityScore, error)
 
 	GalleryIDLoader
 	PerformerIDLoader
@@ -178,9 +182,13 @@ type SceneReader interface {
 	Wall(ctx context.Context, q *string) ([]*Scene, error)
 	All(ctx context.Context) ([]*Scene, error)
 	Query(ctx context.Context, options SceneQueryOptions) (*SceneQueryResult, error)
+	Facets(ctx context.Context, sceneFilter *SceneFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	QueryCount(ctx context.Context, sceneFilter *SceneFilterType, findFilter *FindFilterType) (int, error)
 	GetCover(ctx context.Context, sceneID int) ([]byte, error)
 	HasCover(ctx context.Context, sceneID int) (bool, error)
+	// GetUserActivity returns the activity of the owner, followed by the
+	// activity of each other user who has rated or played the scene.
+	GetUserActivity(ctx context.Context, sceneID int) ([]*SceneUserActivity, error)
 }
 
 type SceneWriter interface {
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type activityContextKey int
+
+const activityUserIDKey activityContextKey = iota + 1
+
+// WithActivityUserID returns a context in which the ratings, o-counters and
+// play history of scenes are read and written for the user with the id,
+// rather than for the owner.
+func WithActivityUserID(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, activityUserIDKey, userID)
+}
+
+// ActivityUserIDFromContext returns the user id set on the context, and
+// false if none is set.
+func ActivityUserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(activityUserIDKey).(int)
+	return userID, ok
+}
+
+// SceneUserActivity is the rating, o-counter and play history of a scene
+// for a single user. UserID is nil for the owner.
+type SceneUserActivity struct {
+	SceneID int  `json:"scene_id"`
+	UserID  *int `json:"user_id"`
+	// Rating expressed in 1-100 scale
+	Rating       *int       `json:"rating"`
+	OCounter     int        `json:"o_counter"`
+	ResumeTime   float64    `json:"resume_time"`
+	PlayDuration float64    `json:"play_duration"`
+	PlayCount    int        `json:"play_count"`
+	LastPlayedAt *time.Time `json:"last_played_at"`
+}
+
+// SceneActivityTotals is the activity of all users for a scene.
+type SceneActivityTotals struct {
+	// Rating is the average rating of the users who rated the scene,
+	// expressed in 1-100 scale
+	Rating       *int       `json:"rating"`
+	OCounter     int        `json:"o_counter"`
+	PlayDuration float64    `json:"play_duration"`
+	PlayCount    int        `json:"play_count"`
+	LastPlayedAt *time.Time `json:"last_played_at"`
+}
+
+// TotalSceneActivity returns the totals of the activity of each user.
+func TotalSceneActivity(activity []*SceneUserActivity) SceneActivityTotals {
+	var ret SceneActivityTotals
+	ratingSum := 0
+	ratings := 0
+
+	for _, a := range activity {
+		ret.OCounter += a.OCounter
+		ret.PlayDuration += a.PlayDuration
+		ret.PlayCount += a.PlayCount
+
+		if a.Rating != nil {
+			ratingSum += *a.Rating
+			ratings++
+		}
+
+		if a.LastPlayedAt != nil && (ret.LastPlayedAt == nil || a.LastPlayedAt.After(*ret.LastPlayedAt)) {
+			t := *a.LastPlayedAt
+			ret.LastPlayedAt = &t
+		}
+	}
+
+	if ratings > 0 {
+		// round to the nearest whole rating
+		rating := (ratingSum + ratings/2) / ratings
+		ret.Rating = &rating
+	}
+
+	return ret
+}
@@ -0,0 +1,19 @@
+package models
+
+import "context"
+
+type ShareLinkReader interface {
+	Find(ctx context.Context, id int) (*ShareLink, error)
+	FindByUserID(ctx context.Context, userID int) ([]*ShareLink, error)
+	All(ctx context.Context) ([]*ShareLink, error)
+}
+
+type ShareLinkWriter interface {
+	Create(ctx context.Context, newLink *ShareLink) error
+	Destroy(ctx context.Context, id int) error
+}
+
+type ShareLinkReaderWriter interface {
+	ShareLinkReader
+	ShareLinkWriter
+}
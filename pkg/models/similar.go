@@ -0,0 +1,19 @@
+package models
+
+// SimilarityOptions configures the scoring of objects by their similarity
+// to another object.
+type SimilarityOptions struct {
+	// Limit is the maximum number of results. Results are not limited if
+	// Limit is less than one.
+	Limit int
+	// PHash includes the perceptual similarity of files in the score, where
+	// supported.
+	PHash bool
+}
+
+// SimilarityScore is the score of an object by its similarity to another
+// object. Higher scores are more similar.
+type SimilarityScore struct {
+	ID    int     `db:"id"`
+	Score float64 `db:"score"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type StatisticsInterval string
+
+const (
+	StatisticsIntervalDay   StatisticsInterval = "DAY"
+	StatisticsIntervalWeek  StatisticsInterval = "WEEK"
+	StatisticsIntervalMonth StatisticsInterval = "MONTH"
+	StatisticsIntervalYear  StatisticsInterval = "YEAR"
+)
+
+var AllStatisticsInterval = []StatisticsInterval{
+	StatisticsIntervalDay,
+	StatisticsIntervalWeek,
+	StatisticsIntervalMonth,
+	StatisticsIntervalYear,
+}
+
+func (e StatisticsInterval) IsValid() bool {
+	switch e {
+	case StatisticsIntervalDay, StatisticsIntervalWeek, StatisticsIntervalMonth, StatisticsIntervalYear:
+		return true
+	}
+	return false
+}
+
+func (e StatisticsInterval) String() string {
+	return string(e)
+}
+
+func (e *StatisticsInterval) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StatisticsInterval(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StatisticsInterval", str)
+	}
+	return nil
+}
+
+func (e StatisticsInterval) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StorageBreakdownType string
+
+const (
+	StorageBreakdownTypeStudio     StorageBreakdownType = "STUDIO"
+	StorageBreakdownTypeCodec      StorageBreakdownType = "CODEC"
+	StorageBreakdownTypeResolution StorageBreakdownType = "RESOLUTION"
+)
+
+// GrowthPeriod is the number of scenes added to the library in a period.
+type GrowthPeriod struct {
+	// Start date of the period, formatted as YYYY-MM-DD
+	Period string  `json:"period"`
+	Count  int     `json:"count"`
+	Size   float64 `json:"size"`
+	// Total number of scenes added up to the end of the period
+	Total int `json:"total"`
+}
+
+// StorageBreakdown is the storage used by the files with a value.
+type StorageBreakdown struct {
+	// ID of the related object, or the value itself. Empty for files
+	// without a value.
+	Value string `json:"value"`
+	Label string `json:"label"`
+	// Number of files
+	Count    int     `json:"count"`
+	Size     float64 `json:"size"`
+	Duration float64 `json:"duration"`
+}
+
+// WatchTimePeriod is the time spent playing scenes in a period.
+type WatchTimePeriod struct {
+	// Start date of the period, formatted as YYYY-MM-DD
+	Period string `json:"period"`
+	// Play duration in seconds
+	Duration float64 `json:"duration"`
+	// Number of distinct scenes played
+	SceneCount int `json:"scene_count"`
+}
+
+// PlayStatistic is the play activity of the scenes related to an object.
+type PlayStatistic struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	PlayCount    int     `json:"play_count"`
+	PlayDuration float64 `json:"play_duration"`
+}
+
+type StatisticsReader interface {
+	// SceneGrowth returns the number of scenes created in each interval,
+	// oldest first.
+	SceneGrowth(ctx context.Context, interval StatisticsInterval) ([]*GrowthPeriod, error)
+	// SceneStorage returns the storage used by scene files, broken down by
+	// the given type and ordered by descending size.
+	SceneStorage(ctx context.Context, breakdown StorageBreakdownType) ([]*StorageBreakdown, error)
+	// PathStorage returns the storage used by the files within each of the
+	// given paths.
+	PathStorage(ctx context.Context, paths []string) ([]*StorageBreakdown, error)
+	// WatchTime returns the play duration of scenes in each interval,
+	// newest first. Up to limit intervals with activity are returned.
+	WatchTime(ctx context.Context, interval StatisticsInterval, limit int) ([]*WatchTimePeriod, error)
+	// MostPlayedPerformers returns the performers whose scenes have been
+	// played the most.
+	MostPlayedPerformers(ctx context.Context, limit int) ([]*PlayStatistic, error)
+	// MostPlayedTags returns the tags whose scenes have been played the most.
+	MostPlayedTags(ctx context.Context, limit int) ([]*PlayStatistic, error)
+}
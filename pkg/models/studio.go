@@ -54,8 +54,11 @@ type StudioReader interface {
 	// support the query needed
 	QueryForAutoTag(ctx context.Context, words []string) ([]*Studio, error)
 	Query(ctx context.Context, studioFilter *StudioFilterType, findFilter *FindFilterType) ([]*Studio, int, error)
+	Facets(ctx context.Context, studioFilter *StudioFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	GetImage(ctx context.Context, studioID int) ([]byte, error)
 	HasImage(ctx context.Context, studioID int) (bool, error)
+	GetImages(ctx context.Context, studioID int) ([]EntityImage, error)
+	GetImageByID(ctx context.Context, studioID int, imageID int) ([]byte, error)
 	StashIDLoader
 	GetAliases(ctx context.Context, studioID int) ([]string, error)
 }
@@ -66,8 +69,12 @@ type StudioWriter interface {
 	Update(ctx context.Context, updatedStudio *Studio) error
 	Destroy(ctx context.Context, id int) error
 	UpdateImage(ctx context.Context, studioID int, image []byte) error
+	AddImage(ctx context.Context, studioID int, image []byte, primary bool) (int, error)
+	SetPrimaryImage(ctx context.Context, studioID int, imageID int) error
+	DestroyImageByID(ctx context.Context, studioID int, imageID int) error
 	UpdateStashIDs(ctx context.Context, studioID int, stashIDs []StashID) error
 	UpdateAliases(ctx context.Context, studioID int, aliases []string) error
+	Merge(ctx context.Context, source []int, destination int) error
 }
 
 type StudioReaderWriter interface {
@@ -62,8 +62,11 @@ type TagReader interface {
 	// support the query needed
 	QueryForAutoTag(ctx context.Context, words []string) ([]*Tag, error)
 	Query(ctx context.Context, tagFilter *TagFilterType, findFilter *FindFilterType) ([]*Tag, int, error)
+	Facets(ctx context.Context, tagFilter *TagFilterType, findFilter *FindFilterType, options FacetOptions) ([]*Facet, error)
 	GetImage(ctx context.Context, tagID int) ([]byte, error)
 	HasImage(ctx context.Context, tagID int) (bool, error)
+	GetImages(ctx context.Context, tagID int) ([]EntityImage, error)
+	GetImageByID(ctx context.Context, tagID int, imageID int) ([]byte, error)
 	GetAliases(ctx context.Context, tagID int) ([]string, error)
 	FindAllAncestors(ctx context.Context, tagID int, excludeIDs []int) ([]*TagPath, error)
 	FindAllDescendants(ctx context.Context, tagID int, excludeIDs []int) ([]*TagPath, error)
@@ -75,6 +78,9 @@ type TagWriter interface {
 	Update(ctx context.Context, updatedTag *Tag) error
 	Destroy(ctx context.Context, id int) error
 	UpdateImage(ctx context.Context, tagID int, image []byte) error
+	AddImage(ctx context.Context, tagID int, image []byte, primary bool) (int, error)
+	SetPrimaryImage(ctx context.Context, tagID int, imageID int) error
+	DestroyImageByID(ctx context.Context, tagID int, imageID int) error
 	UpdateAliases(ctx context.Context, tagID int, aliases []string) error
 	Merge(ctx context.Context, source []int, destination int) error
 	UpdateParentTags(ctx context.Context, tagID int, parentIDs []int) error
@@ -0,0 +1,27 @@
+package models
+
+import "context"
+
+type TagImplicationReader interface {
+	Find(ctx context.Context, id int) (*TagImplication, error)
+	FindMany(ctx context.Context, ids []int) ([]*TagImplication, error)
+	All(ctx context.Context) ([]*TagImplication, error)
+	Count(ctx context.Context) (int, error)
+	// FindMissing returns the implied tags which the objects of the given
+	// type do not have, ordered by object id. All objects are checked if
+	// ids is nil.
+	FindMissing(ctx context.Context, objectType TagImplicationObjectType, ids []int) ([]*ImpliedTag, error)
+}
+
+type TagImplicationWriter interface {
+	Create(ctx context.Context, newImplication *TagImplication) error
+	Destroy(ctx context.Context, id int) error
+	// Apply adds the missing implied tags to the objects of the given type,
+	// returning the added tags. All objects are updated if ids is nil.
+	Apply(ctx context.Context, objectType TagImplicationObjectType, ids []int) ([]*ImpliedTag, error)
+}
+
+type TagImplicationReaderWriter interface {
+	TagImplicationReader
+	TagImplicationWriter
+}
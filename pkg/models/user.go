@@ -0,0 +1,26 @@
+package models
+
+import "context"
+
+type UserReader interface {
+	Find(ctx context.Context, id int) (*User, error)
+	// FindByUsername returns the user with the username, ignoring case, or
+	// nil if there is none.
+	FindByUsername(ctx context.Context, username string) (*User, error)
+	// FindByOIDCSubject returns the user linked to the OpenID Connect user
+	// with the subject at the issuer, or nil if there is none.
+	FindByOIDCSubject(ctx context.Context, issuer string, subject string) (*User, error)
+	All(ctx context.Context) ([]*User, error)
+	Count(ctx context.Context) (int, error)
+}
+
+type UserWriter interface {
+	Create(ctx context.Context, newUser *User) error
+	Update(ctx context.Context, updatedUser *User) error
+	Destroy(ctx context.Context, id int) error
+}
+
+type UserReaderWriter interface {
+	UserReader
+	UserWriter
+}
@@ -14,6 +14,7 @@ import (
 
 type ImageAliasStashIDGetter interface {
 	GetImage(ctx context.Context, performerID int) ([]byte, error)
+	models.EntityImageGetter
 	models.AliasLoader
 	models.StashIDLoader
 }
@@ -93,6 +94,15 @@ func ToJSON(ctx context.Context, reader ImageAliasStashIDGetter, performer *mode
 		newPerformerJSON.Image = utils.GetBase64StringFromData(image)
 	}
 
+	images, err := models.GetSecondaryImages(ctx, reader, performer.ID)
+	if err != nil {
+		logger.Errorf("Error getting performer images: %v", err)
+	}
+
+	for _, image := range images {
+		newPerformerJSON.Images = append(newPerformerJSON.Images, utils.GetBase64StringFromData(image))
+	}
+
 	return &newPerformerJSON, nil
 }
 
@@ -52,7 +52,10 @@ var (
 	circumcised     = circumcisedEnum.String()
 )
 
-var imageBytes = []byte("imageBytes")
+var (
+	imageBytes          = []byte("imageBytes")
+	secondaryImageBytes = []byte("secondaryImageBytes")
+)
 
 var stashID = models.StashID{
 	StashID:  "StashID",
@@ -62,7 +65,10 @@ var stashIDs = []models.StashID{
 	stashID,
 }
 
-const image = "aW1hZ2VCeXRlcw=="
+const (
+	image          = "aW1hZ2VCeXRlcw=="
+	secondaryImage = "c2Vjb25kYXJ5SW1hZ2VCeXRlcw=="
+)
 
 var birthDate = models.NewDate("2001-01-01")
 var deathDate = models.NewDate("2021-02-02")
@@ -158,6 +164,12 @@ func createFullJSONPerformer(name string, image string) *jsonschema.Performer {
 	}
 }
 
+func createFullJSONPerformerWithImages(name string, image string, images []string) *jsonschema.Performer {
+	ret := createFullJSONPerformer(name, image)
+	ret.Images = images
+	return ret
+}
+
 func createEmptyJSONPerformer() *jsonschema.Performer {
 	return &jsonschema.Performer{
 		Aliases:  []string{},
@@ -183,7 +195,7 @@ func initTestTable() {
 	scenarios = []testScenario{
 		{
 			*createFullPerformer(performerID, performerName),
-			createFullJSONPerformer(performerName, image),
+			createFullJSONPerformerWithImages(performerName, image, []string{secondaryImage}),
 			false,
 		},
 		{
@@ -211,6 +223,14 @@ func TestToJSON(t *testing.T) {
 	mockPerformerReader.On("GetImage", testCtx, noImageID).Return(nil, nil).Once()
 	mockPerformerReader.On("GetImage", testCtx, errImageID).Return(nil, imageErr).Once()
 
+	mockPerformerReader.On("GetImages", testCtx, performerID).Return([]models.EntityImage{
+		{ID: 1, Primary: true},
+		{ID: 2},
+	}, nil).Once()
+	mockPerformerReader.On("GetImageByID", testCtx, performerID, 2).Return(secondaryImageBytes, nil).Once()
+	mockPerformerReader.On("GetImages", testCtx, noImageID).Return(nil, nil).Once()
+	mockPerformerReader.On("GetImages", testCtx, errImageID).Return(nil, imageErr).Once()
+
 	for i, s := range scenarios {
 		tag := s.input
 		json, err := ToJSON(testCtx, mockPerformerReader, &tag)
@@ -18,6 +18,7 @@ type NameFinderCreatorUpdater interface {
 	NameFinderCreator
 	Update(ctx context.Context, updatedPerformer *models.Performer) error
 	UpdateImage(ctx context.Context, performerID int, image []byte) error
+	AddImage(ctx context.Context, performerID int, image []byte, primary bool) (int, error)
 }
 
 type Importer struct {
@@ -26,9 +27,10 @@ type Importer struct {
 	Input               jsonschema.Performer
 	MissingRefBehaviour models.ImportMissingRefEnum
 
-	ID        int
-	performer models.Performer
-	imageData []byte
+	ID         int
+	performer  models.Performer
+	imageData  []byte
+	imagesData [][]byte
 }
 
 func (i *Importer) PreImport(ctx context.Context) error {
@@ -46,6 +48,15 @@ func (i *Importer) PreImport(ctx context.Context) error {
 		}
 	}
 
+	for _, image := range i.Input.Images {
+		data, err := utils.ProcessBase64Image(image)
+		if err != nil {
+			return fmt.Errorf("invalid image: %v", err)
+		}
+
+		i.imagesData = append(i.imagesData, data)
+	}
+
 	return nil
 }
 
@@ -123,6 +134,12 @@ func (i *Importer) PostImport(ctx context.Context, id int) error {
 		}
 	}
 
+	for _, data := range i.imagesData {
+		if _, err := i.ReaderWriter.AddImage(ctx, id, data, false); err != nil {
+			return fmt.Errorf("error adding performer image: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,127 @@
+package playlist
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	M3UContentType  = "audio/x-mpegurl"
+	XSPFContentType = "application/xspf+xml"
+)
+
+// Entry is an entry of an exported playlist.
+type Entry struct {
+	Title    string
+	Location string
+	// Duration is the duration of the entry in seconds, or zero if unknown.
+	Duration float64
+	// StartTime is the time in seconds at which to start playing the entry.
+	// Start times are applied by VLC, and ignored by other players.
+	StartTime float64
+	Image     string
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// m3uTitle returns the title with line breaks replaced, since each
+// directive of an M3U playlist is a single line.
+func m3uTitle(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// WriteM3U writes the entries as an extended M3U playlist, encoded in UTF-8.
+func WriteM3U(w io.Writer, name string, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "#EXTM3U")
+	fmt.Fprintf(bw, "#PLAYLIST:%s\n", m3uTitle(name))
+
+	for _, e := range entries {
+		duration := -1
+		if e.Duration > 0 {
+			duration = int(math.Round(e.Duration))
+		}
+
+		fmt.Fprintf(bw, "#EXTINF:%d,%s\n", duration, m3uTitle(e.Title))
+		if e.StartTime > 0 {
+			fmt.Fprintf(bw, "#EXTVLCOPT:start-time=%s\n", formatSeconds(e.StartTime))
+		}
+		fmt.Fprintln(bw, e.Location)
+	}
+
+	return bw.Flush()
+}
+
+const (
+	xspfNamespace = "http://xspf.org/ns/0/"
+	vlcNamespace  = "http://www.videolan.org/vlc/playlist/ns/0/"
+	vlcExtension  = "http://www.videolan.org/vlc/playlist/0"
+)
+
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"playlist"`
+	Version   string      `xml:"version,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	VLC       string      `xml:"xmlns:vlc,attr"`
+	Title     string      `xml:"title"`
+	Tracks    []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location  string         `xml:"location"`
+	Title     string         `xml:"title,omitempty"`
+	Image     string         `xml:"image,omitempty"`
+	Duration  int64          `xml:"duration,omitempty"`
+	Extension *xspfExtension `xml:"extension,omitempty"`
+}
+
+type xspfExtension struct {
+	Application string   `xml:"application,attr"`
+	Options     []string `xml:"vlc:option"`
+}
+
+// WriteXSPF writes the entries as an XSPF playlist.
+func WriteXSPF(w io.Writer, name string, entries []Entry) error {
+	p := xspfPlaylist{
+		Version:   "1",
+		Namespace: xspfNamespace,
+		VLC:       vlcNamespace,
+		Title:     name,
+		Tracks:    make([]xspfTrack, len(entries)),
+	}
+
+	for i, e := range entries {
+		t := xspfTrack{
+			Location: e.Location,
+			Title:    e.Title,
+			Image:    e.Image,
+			// durations are in milliseconds
+			Duration: int64(math.Round(e.Duration * 1000)),
+		}
+
+		if e.StartTime > 0 {
+			t.Extension = &xspfExtension{
+				Application: vlcExtension,
+				Options:     []string{"start-time=" + formatSeconds(e.StartTime)},
+			}
+		}
+
+		p.Tracks[i] = t
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(p)
+}
@@ -0,0 +1,67 @@
+package playlist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testEntries = []Entry{
+	{
+		Title:    "Scene\ntitle",
+		Location: "http://localhost:9999/scene/1/stream?apikey=key",
+		Duration: 61.6,
+		Image:    "http://localhost:9999/scene/1/screenshot",
+	},
+	{
+		Title:     "Marker & <title>",
+		Location:  "http://localhost:9999/scene/2/stream",
+		StartTime: 12.5,
+	},
+}
+
+func TestWriteM3U(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteM3U(&sb, "Watch list", testEntries); err != nil {
+		t.Errorf("WriteM3U() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, `#EXTM3U
+#PLAYLIST:Watch list
+#EXTINF:62,Scene title
+http://localhost:9999/scene/1/stream?apikey=key
+#EXTINF:-1,Marker & <title>
+#EXTVLCOPT:start-time=12.5
+http://localhost:9999/scene/2/stream
+`, sb.String())
+}
+
+func TestWriteXSPF(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteXSPF(&sb, "Watch list", testEntries); err != nil {
+		t.Errorf("WriteXSPF() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/" xmlns:vlc="http://www.videolan.org/vlc/playlist/ns/0/">
+  <title>Watch list</title>
+  <trackList>
+    <track>
+      <location>http://localhost:9999/scene/1/stream?apikey=key</location>
+      <title>Scene&#xA;title</title>
+      <image>http://localhost:9999/scene/1/screenshot</image>
+      <duration>61600</duration>
+    </track>
+    <track>
+      <location>http://localhost:9999/scene/2/stream</location>
+      <title>Marker &amp; &lt;title&gt;</title>
+      <extension application="http://www.videolan.org/vlc/playlist/0">
+        <vlc:option>start-time=12.5</vlc:option>
+      </extension>
+    </track>
+  </trackList>
+</playlist>`, sb.String())
+}
@@ -0,0 +1,200 @@
+// Package playlist provides functions for resolving the items of playlists.
+package playlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/filterquery"
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/scene"
+)
+
+type ItemGetter interface {
+	GetItems(ctx context.Context, playlistID int) ([]*models.PlaylistItem, error)
+}
+
+type SavedFilterFinder interface {
+	Find(ctx context.Context, id int) (*models.SavedFilter, error)
+}
+
+type SceneMarkerQueryer interface {
+	Query(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, findFilter *models.FindFilterType) ([]*models.SceneMarker, int, error)
+}
+
+// ItemResolver resolves the items of static and smart playlists.
+type ItemResolver struct {
+	Playlist    ItemGetter
+	SavedFilter SavedFilterFinder
+	Scene       scene.Queryer
+	SceneMarker SceneMarkerQueryer
+	Image       image.Queryer
+	// Filter resolves the names of related objects in the filter query of
+	// saved filters.
+	Filter *filterquery.Resolver
+}
+
+// savedFilterParams are the parameters of the JSON filter of a saved filter
+// which are applied to smart playlists. The criteria of the filter are
+// encoded by the UI, and are not applied.
+type savedFilterParams struct {
+	SortBy  string `json:"sortby"`
+	SortDir string `json:"sortdir"`
+	Q       string `json:"q"`
+}
+
+// FindFilter returns the find filter of the saved filter f, returning at
+// most limit results. All results are returned if limit is nil.
+func FindFilter(f *models.SavedFilter, limit *int) (*models.FindFilterType, error) {
+	var params savedFilterParams
+	if f.Filter != "" {
+		if err := json.Unmarshal([]byte(f.Filter), &params); err != nil {
+			return nil, fmt.Errorf("parsing saved filter %d: %w", f.ID, err)
+		}
+	}
+
+	perPage := models.PerPageAll
+	if limit != nil {
+		perPage = *limit
+	}
+
+	ret := &models.FindFilterType{
+		PerPage: &perPage,
+	}
+
+	if params.Q != "" {
+		ret.Q = &params.Q
+	}
+
+	if params.SortBy != "" {
+		ret.Sort = &params.SortBy
+
+		// the UI omits the default direction, which is descending for dates
+		direction := models.SortDirectionEnumAsc
+		if params.SortDir == "" && params.SortBy == "date" {
+			direction = models.SortDirectionEnumDesc
+		} else if params.SortDir != "" {
+			direction = models.SortDirectionEnum(strings.ToUpper(params.SortDir))
+		}
+
+		if !direction.IsValid() {
+			return nil, fmt.Errorf("invalid sort direction %q in saved filter %d", params.SortDir, f.ID)
+		}
+		ret.Direction = &direction
+	}
+
+	return ret, nil
+}
+
+// Items returns the items of the playlist in order. The items of smart
+// playlists are the results of their saved filter.
+func (r ItemResolver) Items(ctx context.Context, p *models.Playlist) ([]*models.PlaylistItem, error) {
+	if !p.IsSmart() {
+		return r.Playlist.GetItems(ctx, p.ID)
+	}
+
+	f, err := r.SavedFilter.Find(ctx, *p.SavedFilterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if f == nil {
+		return nil, fmt.Errorf("saved filter %d of playlist %d not found", *p.SavedFilterID, p.ID)
+	}
+
+	ids, err := r.filterIDs(ctx, f, p.FilterLimit)
+	if err != nil {
+		return nil, fmt.Errorf("resolving items of playlist %d: %w", p.ID, err)
+	}
+
+	ret := make([]*models.PlaylistItem, len(ids))
+	for i := range ids {
+		item := &models.PlaylistItem{
+			PlaylistID: p.ID,
+			Position:   i,
+		}
+
+		switch f.Mode {
+		case models.FilterModeScenes:
+			item.SceneID = &ids[i]
+		case models.FilterModeSceneMarkers:
+			item.SceneMarkerID = &ids[i]
+		case models.FilterModeImages:
+			item.ImageID = &ids[i]
+		}
+
+		ret[i] = item
+	}
+
+	return ret, nil
+}
+
+// ValidateFilterMode returns an error if saved filters of the given mode
+// cannot be used for smart playlists.
+func ValidateFilterMode(mode models.FilterMode) error {
+	switch mode {
+	case models.FilterModeScenes, models.FilterModeSceneMarkers, models.FilterModeImages:
+		return nil
+	}
+
+	return fmt.Errorf("saved filters of mode %s cannot be used for playlists", mode)
+}
+
+func (r ItemResolver) filterIDs(ctx context.Context, f *models.SavedFilter, limit *int) ([]int, error) {
+	if err := ValidateFilterMode(f.Mode); err != nil {
+		return nil, err
+	}
+
+	findFilter, err := FindFilter(f, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	objectFilter, err := filterquery.NewFilter(f.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Query != "" {
+		text, err := filterquery.Apply(ctx, f.Query, objectFilter, r.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if text != "" {
+			if findFilter.Q != nil {
+				text = *findFilter.Q + " " + text
+			}
+			findFilter.Q = &text
+		}
+	}
+
+	switch f.Mode {
+	case models.FilterModeScenes:
+		result, err := r.Scene.Query(ctx, scene.QueryOptions(objectFilter.(*models.SceneFilterType), findFilter, false))
+		if err != nil {
+			return nil, err
+		}
+		return result.IDs, nil
+	case models.FilterModeImages:
+		result, err := r.Image.Query(ctx, image.QueryOptions(objectFilter.(*models.ImageFilterType), findFilter, false))
+		if err != nil {
+			return nil, err
+		}
+		return result.IDs, nil
+	default:
+		markers, _, err := r.SceneMarker.Query(ctx, objectFilter.(*models.SceneMarkerFilterType), findFilter)
+		if err != nil {
+			return nil, err
+		}
+
+		ret := make([]int, len(markers))
+		for i, m := range markers {
+			ret[i] = m.ID
+		}
+		return ret, nil
+	}
+}
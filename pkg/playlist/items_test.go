@@ -0,0 +1,167 @@
+package playlist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func sortDirectionPtr(v models.SortDirectionEnum) *models.SortDirectionEnum {
+	return &v
+}
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestFindFilter(t *testing.T) {
+	all := models.PerPageAll
+
+	tests := []struct {
+		name    string
+		filter  string
+		limit   *int
+		want    *models.FindFilterType
+		wantErr bool
+	}{
+		{
+			"empty",
+			"",
+			nil,
+			&models.FindFilterType{PerPage: &all},
+			false,
+		},
+		{
+			"limit",
+			`{"perPage":40}`,
+			intPtr(10),
+			&models.FindFilterType{PerPage: intPtr(10)},
+			false,
+		},
+		{
+			"query",
+			`{"q":"foo"}`,
+			nil,
+			&models.FindFilterType{PerPage: &all, Q: strPtr("foo")},
+			false,
+		},
+		{
+			"default direction",
+			`{"sortby":"title"}`,
+			nil,
+			&models.FindFilterType{PerPage: &all, Sort: strPtr("title"), Direction: sortDirectionPtr(models.SortDirectionEnumAsc)},
+			false,
+		},
+		{
+			"default date direction",
+			`{"sortby":"date"}`,
+			nil,
+			&models.FindFilterType{PerPage: &all, Sort: strPtr("date"), Direction: sortDirectionPtr(models.SortDirectionEnumDesc)},
+			false,
+		},
+		{
+			"direction",
+			`{"sortby":"title","sortdir":"desc"}`,
+			nil,
+			&models.FindFilterType{PerPage: &all, Sort: strPtr("title"), Direction: sortDirectionPtr(models.SortDirectionEnumDesc)},
+			false,
+		},
+		{
+			"invalid direction",
+			`{"sortby":"title","sortdir":"up"}`,
+			nil,
+			nil,
+			true,
+		},
+		{
+			"invalid json",
+			`{`,
+			nil,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindFilter(&models.SavedFilter{Filter: tt.filter}, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestItemResolver_Items(t *testing.T) {
+	const (
+		staticID = iota + 1
+		smartID
+		savedFilterID
+		sceneID1
+		sceneID2
+	)
+
+	staticItems := []*models.PlaylistItem{
+		{ID: 1, PlaylistID: staticID, SceneID: intPtr(sceneID1)},
+	}
+
+	db := mocks.NewTxnRepository()
+	db.Playlist.(*mocks.PlaylistReaderWriter).On("GetItems", mock.Anything, staticID).Return(staticItems, nil)
+	db.SavedFilter.(*mocks.SavedFilterReaderWriter).On("Find", mock.Anything, savedFilterID).Return(&models.SavedFilter{
+		ID:     savedFilterID,
+		Mode:   models.FilterModeScenes,
+		Filter: `{"sortby":"title"}`,
+	}, nil)
+
+	sceneResult := &models.SceneQueryResult{}
+	sceneResult.IDs = []int{sceneID1, sceneID2}
+	db.Scene.(*mocks.SceneReaderWriter).On("Query", mock.Anything, mock.MatchedBy(func(o models.SceneQueryOptions) bool {
+		return o.FindFilter != nil && o.FindFilter.PerPage != nil && *o.FindFilter.PerPage == 2 &&
+			o.FindFilter.Sort != nil && *o.FindFilter.Sort == "title"
+	})).Return(sceneResult, nil)
+
+	r := ItemResolver{
+		Playlist:    db.Playlist,
+		SavedFilter: db.SavedFilter,
+		Scene:       db.Scene,
+		SceneMarker: db.SceneMarker,
+		Image:       db.Image,
+	}
+
+	ctx := context.Background()
+
+	got, err := r.Items(ctx, &models.Playlist{ID: staticID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, staticItems, got)
+	}
+
+	got, err = r.Items(ctx, &models.Playlist{
+		ID:            smartID,
+		SavedFilterID: intPtr(savedFilterID),
+		FilterLimit:   intPtr(2),
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*models.PlaylistItem{
+			{PlaylistID: smartID, Position: 0, SceneID: intPtr(sceneID1)},
+			{PlaylistID: smartID, Position: 1, SceneID: intPtr(sceneID2)},
+		}, got)
+	}
+}
+
+func TestValidateFilterMode(t *testing.T) {
+	assert.NoError(t, ValidateFilterMode(models.FilterModeScenes))
+	assert.NoError(t, ValidateFilterMode(models.FilterModeSceneMarkers))
+	assert.NoError(t, ValidateFilterMode(models.FilterModeImages))
+	assert.Error(t, ValidateFilterMode(models.FilterModePerformers))
+}
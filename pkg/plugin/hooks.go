@@ -40,14 +40,17 @@ const (
 
 	MovieCreatePost  HookTriggerEnum = "Movie.Create.Post"
 	MovieUpdatePost  HookTriggerEnum = "Movie.Update.Post"
+	MovieMergePost   HookTriggerEnum = "Movie.Merge.Post"
 	MovieDestroyPost HookTriggerEnum = "Movie.Destroy.Post"
 
 	PerformerCreatePost  HookTriggerEnum = "Performer.Create.Post"
 	PerformerUpdatePost  HookTriggerEnum = "Performer.Update.Post"
+	PerformerMergePost   HookTriggerEnum = "Performer.Merge.Post"
 	PerformerDestroyPost HookTriggerEnum = "Performer.Destroy.Post"
 
 	StudioCreatePost  HookTriggerEnum = "Studio.Create.Post"
 	StudioUpdatePost  HookTriggerEnum = "Studio.Update.Post"
+	StudioMergePost   HookTriggerEnum = "Studio.Merge.Post"
 	StudioDestroyPost HookTriggerEnum = "Studio.Destroy.Post"
 
 	TagCreatePost  HookTriggerEnum = "Tag.Create.Post"
@@ -79,14 +82,17 @@ var AllHookTriggerEnum = []HookTriggerEnum{
 
 	MovieCreatePost,
 	MovieUpdatePost,
+	MovieMergePost,
 	MovieDestroyPost,
 
 	PerformerCreatePost,
 	PerformerUpdatePost,
+	PerformerMergePost,
 	PerformerDestroyPost,
 
 	StudioCreatePost,
 	StudioUpdatePost,
+	StudioMergePost,
 	StudioDestroyPost,
 
 	TagCreatePost,
@@ -120,18 +126,22 @@ func (e HookTriggerEnum) IsValid() bool {
 
 		MovieCreatePost,
 		MovieUpdatePost,
+		MovieMergePost,
 		MovieDestroyPost,
 
 		PerformerCreatePost,
 		PerformerUpdatePost,
+		PerformerMergePost,
 		PerformerDestroyPost,
 
 		StudioCreatePost,
 		StudioUpdatePost,
+		StudioMergePost,
 		StudioDestroyPost,
 
 		TagCreatePost,
 		TagUpdatePost,
+		TagMergePost,
 		TagDestroyPost:
 		return true
 	}
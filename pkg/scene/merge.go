@@ -35,7 +35,12 @@ func (s *Service) Merge(ctx context.Context, sourceIDs []int, destinationID int,
 
 	var fileIDs []file.ID
 
-	for _, src := range sources {
+	for i, src := range sources {
+		// hidden by a restriction
+		if src == nil {
+			return fmt.Errorf("source scene with id %d not found", sourceIDs[i])
+		}
+
 		// TODO - delete generated files as needed
 
 		if err := src.LoadRelationships(ctx, s.Repository); err != nil {
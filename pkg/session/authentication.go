@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/stashapp/stash/pkg/logger"
 )
@@ -15,22 +16,62 @@ func (e ExternalAccessError) Error() string {
 	return fmt.Sprintf("stash accessed from external IP %s", net.IP(e).String())
 }
 
-func CheckAllowPublicWithoutAuth(c ExternalAccessConfig, r *http.Request) error {
-	if !c.HasCredentials() && !c.GetDangerousAllowPublicWithoutAuth() && !c.IsNewSystem() {
-		requestIPString, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			return fmt.Errorf("error parsing remote host (%s): %w", r.RemoteAddr, err)
-		}
+// remoteIP returns the IP address the request was received from.
+func remoteIP(r *http.Request) (net.IP, error) {
+	requestIPString, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing remote host (%s): %w", r.RemoteAddr, err)
+	}
+
+	// presence of scope ID in IPv6 addresses prevents parsing. Remove if present
+	scopeIDIndex := strings.Index(requestIPString, "%")
+	if scopeIDIndex != -1 {
+		requestIPString = requestIPString[0:scopeIDIndex]
+	}
+
+	requestIP := net.ParseIP(requestIPString)
+	if requestIP == nil {
+		return nil, fmt.Errorf("unable to parse remote host (%s)", requestIPString)
+	}
+
+	return requestIP, nil
+}
+
+// ClientIP returns the IP address of the client making the request. If the
+// request was received from a trusted proxy, the X-Forwarded-For header is
+// walked from the right, skipping the addresses of trusted proxies, and the
+// first untrusted address is returned. The addresses to the left of it may be
+// set by the client, so are ignored. It returns the remote address if it
+// cannot be parsed.
+func ClientIP(r *http.Request, trustedProxies []string) string {
+	ip, err := remoteIP(r)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	// the header may be sent more than once
+	var forwardedFor []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		forwardedFor = append(forwardedFor, strings.Split(h, ",")...)
+	}
 
-		// presence of scope ID in IPv6 addresses prevents parsing. Remove if present
-		scopeIDIndex := strings.Index(requestIPString, "%")
-		if scopeIDIndex != -1 {
-			requestIPString = requestIPString[0:scopeIDIndex]
+	for i := len(forwardedFor) - 1; i >= 0 && isTrustedProxy(trustedProxies, ip); i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwardedFor[i]))
+		if forwardedIP == nil {
+			break
 		}
 
-		requestIP := net.ParseIP(requestIPString)
-		if requestIP == nil {
-			return fmt.Errorf("unable to parse remote host (%s)", requestIPString)
+		ip = forwardedIP
+	}
+
+	return ip.String()
+}
+
+func CheckAllowPublicWithoutAuth(c ExternalAccessConfig, r *http.Request) error {
+	if !c.HasCredentials() && !c.GetDangerousAllowPublicWithoutAuth() && !c.IsNewSystem() {
+		requestIP, err := remoteIP(r)
+		if err != nil {
+			return err
 		}
 
 		if r.Header.Get("X-FORWARDED-FOR") != "" {
@@ -75,6 +116,29 @@ func isLocalIP(requestIP net.IP) bool {
 	return requestIP.IsPrivate() || requestIP.IsLoopback() || requestIP.IsLinkLocalUnicast() || cgNatAddrSpace.Contains(requestIP)
 }
 
+// LogLoginBlocked logs that an IP address or username has been blocked after
+// too many failed logins. Public IP addresses are logged as errors, as they
+// mean that stash is reachable from the internet and someone is attempting
+// to guess credentials.
+func LogLoginBlocked(b LoginBlock) {
+	until := b.BlockedUntil.Format(time.RFC3339)
+
+	if b.Username != "" {
+		// don't leak the name
+		logger.Warnf("Logins with a username from %s have been blocked until %s after %d failed attempts", b.IP, until, b.FailedAttempts)
+		return
+	}
+
+	if ip := net.ParseIP(b.IP); ip != nil && !isLocalIP(ip) {
+		logger.Errorf("Stash has been accessed from the internet (public IP %s), and logins from it have been blocked until %s after %d failed attempts. \n"+
+			"Someone may be attempting to guess your credentials. Make sure that your password is strong, and consider not exposing stash to the internet. \n"+
+			"Blocked IP addresses are listed by the loginBlocks query, and may be unblocked with the unblockLogin mutation.", b.IP, until, b.FailedAttempts)
+		return
+	}
+
+	logger.Warnf("Logins from %s have been blocked until %s after %d failed attempts", b.IP, until, b.FailedAttempts)
+}
+
 func LogExternalAccessError(err ExternalAccessError) {
 	logger.Errorf("Stash has been accessed from the internet (public IP %s), without authentication. \n"+
 		"This is extremely dangerous! The whole world can see your stash page and browse your files! \n"+
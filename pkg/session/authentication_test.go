@@ -186,3 +186,37 @@ func TestCheckExternalAccessTripwire(t *testing.T) {
 		t.Errorf("unexpected error %v", err)
 	}
 }
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []string{"10.0.0.1", "172.16.0.0/12"}
+
+	testCases := []struct {
+		name         string
+		address      string
+		forwardedFor string
+		expectedIP   string
+	}{
+		{"direct", "192.168.1.1:8080", "", "192.168.1.1"},
+		{"untrusted proxy", "192.168.1.1:8080", "1.1.1.1", "192.168.1.1"},
+		{"trusted proxy", "10.0.0.1:8080", "1.1.1.1", "1.1.1.1"},
+		{"trusted proxy chain", "10.0.0.1:8080", "1.1.1.1, 172.16.0.2", "1.1.1.1"},
+		{"untrusted proxy in chain", "10.0.0.1:8080", "1.1.1.1, 10.0.0.2", "10.0.0.2"},
+		{"forged by client", "10.0.0.1:8080", "6.6.6.6, 1.1.1.1", "1.1.1.1"},
+		{"forged trusted proxy", "10.0.0.1:8080", "172.16.0.2, 1.1.1.1", "1.1.1.1"},
+		{"trusted proxy without header", "10.0.0.1:8080", "", "10.0.0.1"},
+		{"invalid header", "10.0.0.1:8080", "unknown", "10.0.0.1"},
+		{"invalid address", "invalid", "", "invalid"},
+	}
+
+	for _, tc := range testCases {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tc.address
+		if tc.forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", tc.forwardedFor)
+		}
+
+		if got := ClientIP(r, trustedProxies); got != tc.expectedIP {
+			t.Errorf("[%s]: expected %s, got %s", tc.name, tc.expectedIP, got)
+		}
+	}
+}
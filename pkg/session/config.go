@@ -1,5 +1,12 @@
 package session
 
+import (
+	"context"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
 type ExternalAccessConfig interface {
 	HasCredentials() bool
 	GetDangerousAllowPublicWithoutAuth() bool
@@ -7,11 +14,82 @@ type ExternalAccessConfig interface {
 	IsNewSystem() bool
 }
 
+// UserAuthenticator validates the credentials of users other than the
+// configured user, and API keys other than the configured API key.
+type UserAuthenticator interface {
+	ValidateCredentials(ctx context.Context, username string, password string) (bool, error)
+	// AuthenticateAPIKey returns the username of the user of the key and the
+	// key, or an empty username if the key is invalid.
+	AuthenticateAPIKey(ctx context.Context, key string) (string, *models.APIKey, error)
+	// LoginOIDCUser returns the username of the user linked to the user
+	// with the subject at the OpenID Connect issuer, or an empty string if
+	// they may not log in. username is the username of new users. role is
+	// the role mapped from the groups of the user, and defaultRole the role
+	// of new users without a mapped role. Either may be empty.
+	LoginOIDCUser(ctx context.Context, issuer string, subject string, username string, role models.UserRole, defaultRole models.UserRole) (string, error)
+
+	// LoginUserID returns the id of the user with the username, which is
+	// stored in their session.
+	LoginUserID(ctx context.Context, username string) (int, error)
+	// SessionUsername returns the username of the user with the id stored
+	// in a session, or an empty string if there is no such user.
+	SessionUsername(ctx context.Context, id int) (string, error)
+
+	// SecondFactorStatus returns true if the user must enter a code of
+	// their authenticator app after their password, and whether they must
+	// enroll in two-factor authentication first.
+	SecondFactorStatus(ctx context.Context, username string) (enabled bool, enrollmentRequired bool, err error)
+	// EnrollLoginTOTP returns the secret of the authenticator app of the
+	// user being enrolled.
+	EnrollLoginTOTP(ctx context.Context, username string) (*models.TOTPEnrollment, error)
+	// ConfirmLoginTOTP enables two-factor authentication for the user if
+	// code is a code of the secret being enrolled, returning their recovery
+	// codes. It returns nil if the code is invalid.
+	ConfirmLoginTOTP(ctx context.Context, username string, code string) ([]string, error)
+	// VerifySecondFactor returns true if code is a code of the
+	// authenticator app or an unused recovery code of the user.
+	VerifySecondFactor(ctx context.Context, username string, code string) (bool, error)
+	// TOTPRequired returns true if the user must use two-factor
+	// authentication.
+	TOTPRequired(ctx context.Context, username string) (bool, error)
+}
+
+// OIDCConfig is the configuration of OpenID Connect login.
+type OIDCConfig interface {
+	GetOIDCIssuer() string
+	GetOIDCClientID() string
+	GetOIDCClientSecret() string
+	GetOIDCUsernameClaim() string
+	GetOIDCGroupsClaim() string
+	// GetOIDCRoleGroups returns the groups mapped to each role.
+	GetOIDCRoleGroups() map[models.UserRole][]string
+	GetOIDCDefaultRole() models.UserRole
+}
+
+// ProxyAuthConfig is the configuration of authentication by a trusted
+// reverse proxy.
+type ProxyAuthConfig interface {
+	// GetProxyAuthHeader returns the header containing the username.
+	GetProxyAuthHeader() string
+	// GetProxyAuthTrustedProxies returns the IP addresses and CIDR ranges of
+	// the proxies trusted to set the header.
+	GetProxyAuthTrustedProxies() []string
+}
+
 type SessionConfig interface {
+	OIDCConfig
+	ProxyAuthConfig
+	LoginThrottleConfig
+
 	GetUsername() string
 	GetAPIKey() string
 
 	GetSessionStoreKey() []byte
 	GetMaxSessionAge() int
 	ValidateCredentials(username string, password string) bool
+
+	// GetTOTPRequiredSince returns the time two-factor authentication was
+	// required for admins, or the zero time if it is not. Sessions of users
+	// who must use it created before then are logged out.
+	GetTOTPRequiredSince() time.Time
 }
@@ -0,0 +1,532 @@
+package session
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	oidcCookieName   = "oidc"
+	oidcStateKey     = "state"
+	oidcNonceKey     = "nonce"
+	oidcReturnURLKey = "returnURL"
+
+	// oidcMaxAge is the time in seconds to complete the login with the
+	// provider.
+	oidcMaxAge = 10 * 60
+
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+	oidcTimeout       = 30 * time.Second
+)
+
+var (
+	ErrOIDCDisabled = errors.New("OpenID Connect login is not configured")
+	ErrOIDCState    = errors.New("OpenID Connect login state is invalid or expired")
+)
+
+// oidcSigningMethods are the accepted algorithms of ID tokens.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// OIDCEnabled returns true if OpenID Connect login is configured.
+func OIDCEnabled(c OIDCConfig) bool {
+	return c.GetOIDCIssuer() != "" && c.GetOIDCClientID() != ""
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeJWKInt(v string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(v, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey returns the public key, or nil if the key type is not supported.
+func (k oidcJWK) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding modulus: %w", err)
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding exponent: %w", err)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y: %w", err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, nil
+}
+
+// oidcProvider is an OpenID Connect provider. The discovery document and
+// signing keys are fetched on first use and cached.
+type oidcProvider struct {
+	issuer string
+	client *http.Client
+
+	mutex     sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+}
+
+func newOIDCProvider(issuer string) *oidcProvider {
+	return &oidcProvider{
+		issuer: strings.TrimRight(issuer, "/"),
+		client: &http.Client{Timeout: oidcTimeout},
+	}
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, u string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, u)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var ret oidcDiscovery
+	if err := p.getJSON(ctx, p.issuer+oidcDiscoveryPath, &ret); err != nil {
+		return nil, fmt.Errorf("fetching OpenID Connect discovery document: %w", err)
+	}
+
+	if strings.TrimRight(ret.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("discovered issuer %q does not match configured issuer %q", ret.Issuer, p.issuer)
+	}
+
+	if ret.AuthorizationEndpoint == "" || ret.TokenEndpoint == "" || ret.JWKSURI == "" {
+		return nil, errors.New("OpenID Connect discovery document is incomplete")
+	}
+
+	p.discovery = &ret
+	return p.discovery, nil
+}
+
+func (p *oidcProvider) fetchKeys(ctx context.Context, jwksURI string) (map[string]interface{}, error) {
+	var jwks struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("fetching OpenID Connect signing keys: %w", err)
+	}
+
+	ret := make(map[string]interface{})
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			logger.Warnf("Ignoring invalid OpenID Connect signing key %q: %v", k.Kid, err)
+			continue
+		}
+
+		if key != nil {
+			ret[k.Kid] = key
+		}
+	}
+
+	return ret, nil
+}
+
+// getKey returns the signing key with the id. The keys are refetched if the
+// key is not found, to handle key rotation. An empty id matches the only key.
+func (p *oidcProvider) getKey(ctx context.Context, d *oidcDiscovery, kid string) (interface{}, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	find := func() interface{} {
+		if kid == "" && len(p.keys) == 1 {
+			for _, k := range p.keys {
+				return k
+			}
+		}
+		return p.keys[kid]
+	}
+
+	if key := find(); key != nil {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx, d.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if key := find(); key != nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("signing key %q not found", kid)
+}
+
+func (p *oidcProvider) authCodeURL(d *oidcDiscovery, clientID string, redirectURL string, state string, nonce string) string {
+	v := url.Values{
+		"response_type": {"code"},
+		"client_id":     {clientID},
+		"redirect_uri":  {redirectURL},
+		"scope":         {"openid profile email"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return d.AuthorizationEndpoint + sep + v.Encode()
+}
+
+// exchange exchanges the authorization code for the raw ID token.
+func (p *oidcProvider) exchange(ctx context.Context, d *oidcDiscovery, c OIDCConfig, code string, redirectURL string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURL},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// client_secret_basic requires the credentials to be form encoded
+	req.SetBasicAuth(url.QueryEscape(c.GetOIDCClientID()), url.QueryEscape(c.GetOIDCClientSecret()))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("exchanging authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decoding token response: %w", err)
+	}
+
+	if token.Error != "" {
+		return "", fmt.Errorf("exchanging authorization code: %s: %s", token.Error, token.ErrorDescription)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("exchanging authorization code: unexpected status %s", resp.Status)
+	}
+
+	if token.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+
+	return token.IDToken, nil
+}
+
+// verify verifies the signature and claims of the ID token, returning its
+// claims.
+func (p *oidcProvider) verify(ctx context.Context, d *oidcDiscovery, clientID string, rawToken string, nonce string) (jwt.MapClaims, error) {
+	parser := jwt.Parser{ValidMethods: oidcSigningMethods}
+	claims := jwt.MapClaims{}
+
+	if _, err := parser.ParseWithClaims(rawToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.getKey(ctx, d, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("verifying id token: %w", err)
+	}
+
+	if !claims.VerifyIssuer(d.Issuer, true) {
+		return nil, errors.New("id token has an invalid issuer")
+	}
+
+	if !claims.VerifyAudience(clientID, true) {
+		return nil, errors.New("id token has an invalid audience")
+	}
+
+	// expiry is only checked by the parser if present
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("id token is expired")
+	}
+
+	if n, _ := claims["nonce"].(string); n == "" || n != nonce {
+		return nil, errors.New("id token has an invalid nonce")
+	}
+
+	return claims, nil
+}
+
+// oidcGroups returns the groups in the claim, which may be a list or a
+// single string.
+func oidcGroups(claims jwt.MapClaims, claim string) []string {
+	switch v := claims[claim].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var ret []string
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+
+	return nil
+}
+
+// oidcRole returns the highest role mapped from the groups, or an empty
+// role if none of the groups are mapped.
+func oidcRole(c OIDCConfig, groups []string) models.UserRole {
+	roleGroups := c.GetOIDCRoleGroups()
+
+	// AllUserRole is ordered from the highest role
+	for _, role := range models.AllUserRole {
+		for _, g := range roleGroups[role] {
+			for _, gg := range groups {
+				if g == gg {
+					return role
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func randomOIDCValue() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *Store) getOIDCProvider() *oidcProvider {
+	s.oidcMutex.Lock()
+	defer s.oidcMutex.Unlock()
+
+	// recreate the provider if the issuer is changed
+	issuer := strings.TrimRight(s.config.GetOIDCIssuer(), "/")
+	if s.oidc == nil || s.oidc.issuer != issuer {
+		s.oidc = newOIDCProvider(issuer)
+	}
+
+	return s.oidc
+}
+
+// OIDCLogin redirects to the OpenID Connect provider to log in. The provider
+// redirects back to redirectURL, which must call OIDCCallback. returnURL is
+// returned by OIDCCallback once logged in.
+func (s *Store) OIDCLogin(w http.ResponseWriter, r *http.Request, redirectURL string, returnURL string) error {
+	if !OIDCEnabled(s.config) {
+		return ErrOIDCDisabled
+	}
+
+	p := s.getOIDCProvider()
+	d, err := p.discover(r.Context())
+	if err != nil {
+		return err
+	}
+
+	state, err := randomOIDCValue()
+	if err != nil {
+		return err
+	}
+	nonce, err := randomOIDCValue()
+	if err != nil {
+		return err
+	}
+
+	// ignore error - we want a new session regardless
+	session, _ := s.sessionStore.Get(r, oidcCookieName)
+	session.Values[oidcStateKey] = state
+	session.Values[oidcNonceKey] = nonce
+	session.Values[oidcReturnURLKey] = returnURL
+	session.Options.MaxAge = oidcMaxAge
+
+	if err := session.Save(r, w); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, p.authCodeURL(d, s.config.GetOIDCClientID(), redirectURL, state, nonce), http.StatusFound)
+	return nil
+}
+
+// OIDCCallback completes the login with the OpenID Connect provider,
+// returning the returnURL passed to OIDCLogin and the username of the user.
+// redirectURL must be the same as that passed to OIDCLogin. It returns an
+// InvalidCredentialsError if the user may not log in.
+func (s *Store) OIDCCallback(w http.ResponseWriter, r *http.Request, redirectURL string) (returnURL string, username string, err error) {
+	if !OIDCEnabled(s.config) {
+		return "", "", ErrOIDCDisabled
+	}
+
+	session, err := s.sessionStore.Get(r, oidcCookieName)
+	if err != nil || session.IsNew {
+		return "", "", ErrOIDCState
+	}
+
+	state, _ := session.Values[oidcStateKey].(string)
+	nonce, _ := session.Values[oidcNonceKey].(string)
+	returnURL, _ = session.Values[oidcReturnURLKey].(string)
+
+	// the state may only be used once
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		return "", "", err
+	}
+
+	if state == "" || r.FormValue("state") != state {
+		return "", "", ErrOIDCState
+	}
+
+	if e := r.FormValue("error"); e != "" {
+		return "", "", fmt.Errorf("OpenID Connect provider returned error: %s: %s", e, r.FormValue("error_description"))
+	}
+
+	ctx := r.Context()
+	p := s.getOIDCProvider()
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	rawToken, err := p.exchange(ctx, d, s.config, r.FormValue("code"), redirectURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	claims, err := p.verify(ctx, d, s.config.GetOIDCClientID(), rawToken, nonce)
+	if err != nil {
+		return "", "", err
+	}
+
+	// users are identified by the issuer and subject, which unlike the
+	// username claim cannot be chosen by the user
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return "", "", errors.New("id token has no sub claim")
+	}
+
+	usernameClaim := s.config.GetOIDCUsernameClaim()
+	claimedUsername, _ := claims[usernameClaim].(string)
+
+	role := oidcRole(s.config, oidcGroups(claims, s.config.GetOIDCGroupsClaim()))
+	defaultRole := s.config.GetOIDCDefaultRole()
+	if !defaultRole.IsValid() {
+		defaultRole = ""
+	}
+
+	if s.users != nil {
+		username, err = s.users.LoginOIDCUser(ctx, s.config.GetOIDCIssuer(), subject, claimedUsername, role, defaultRole)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	if username == "" {
+		return "", "", &InvalidCredentialsError{Username: claimedUsername}
+	}
+
+	// ignore error - we want a new session regardless
+	newSession, _ := s.sessionStore.Get(r, cookieName)
+	if err := s.setSessionUser(ctx, newSession, username); err != nil {
+		return "", "", err
+	}
+
+	if err := newSession.Save(r, w); err != nil {
+		return "", "", err
+	}
+
+	// don't leak the name
+	logger.Info("User logged in with OpenID Connect")
+
+	return returnURL, username, nil
+}
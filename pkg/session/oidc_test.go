@@ -0,0 +1,283 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testClientID     = "stash"
+	testClientSecret = "secret"
+	testCode         = "code"
+	testRedirectURL  = "http://stash/login/oidc/callback"
+	testKeyID        = "key"
+)
+
+// mockOIDCProvider is a minimal OpenID Connect provider which issues ID
+// tokens with the claims for the nonce of the last authorization request.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+	nonce  string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+
+	p := &mockOIDCProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []oidcJWK{{
+				Kty: "RSA",
+				Kid: testKeyID,
+				Use: "sig",
+				N:   enc.EncodeToString(key.N.Bytes()),
+				E:   enc.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != testClientID || secret != testClientSecret || r.FormValue("code") != testCode || r.FormValue("redirect_uri") != testRedirectURL {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   p.server.URL,
+			"aud":   testClientID,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": p.nonce,
+		}
+		for k, v := range p.claims {
+			claims[k] = v
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = testKeyID
+		signed, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// login starts the login, returning the callback request the provider
+// would redirect to.
+func (p *mockOIDCProvider) login(t *testing.T, s *Store) *http.Request {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://stash/login/oidc", nil)
+	if err := s.OIDCLogin(w, r, testRedirectURL, "/scenes"); err != nil {
+		t.Fatalf("OIDCLogin() error = %v", err)
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parsing location: %v", err)
+	}
+
+	q := location.Query()
+	assert.Equal(t, p.server.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, testClientID, q.Get("client_id"))
+	assert.Equal(t, testRedirectURL, q.Get("redirect_uri"))
+	p.nonce = q.Get("nonce")
+
+	callback := httptest.NewRequest(http.MethodGet, testRedirectURL+"?"+url.Values{
+		"code":  {testCode},
+		"state": {q.Get("state")},
+	}.Encode(), nil)
+	for _, c := range w.Result().Cookies() {
+		callback.AddCookie(c)
+	}
+
+	return callback
+}
+
+func TestStore_OIDC(t *testing.T) {
+	p := newMockOIDCProvider(t)
+	c := &sessionConfig{
+		issuer: p.server.URL,
+		roleGroups: map[models.UserRole][]string{
+			models.UserRoleAdmin:  {"admins"},
+			models.UserRoleViewer: {"family"},
+		},
+	}
+	users := &testUsers{allowed: true}
+	s := NewStore(c, users)
+
+	t.Run("valid", func(t *testing.T) {
+		p.claims = jwt.MapClaims{
+			"sub":                "alice-subject",
+			"preferred_username": "alice",
+			"groups":             []string{"family", "admins"},
+		}
+
+		w := httptest.NewRecorder()
+		returnURL, username, err := s.OIDCCallback(w, p.login(t, s), testRedirectURL)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, "/scenes", returnURL)
+		assert.Equal(t, "alice", username)
+		assert.Equal(t, models.UserRoleAdmin, users.role)
+
+		// the session cookie logs in the user
+		r := httptest.NewRequest(http.MethodGet, "http://stash/", nil)
+		for _, c := range w.Result().Cookies() {
+			r.AddCookie(c)
+		}
+		userID, err := s.GetSessionUserID(httptest.NewRecorder(), r)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "alice", userID)
+		}
+	})
+
+	t.Run("linked subject", func(t *testing.T) {
+		// the user is identified by the subject rather than the username
+		p.claims = jwt.MapClaims{
+			"sub":                "alice-subject",
+			"preferred_username": "mallory",
+		}
+
+		_, username, err := s.OIDCCallback(httptest.NewRecorder(), p.login(t, s), testRedirectURL)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "alice", username)
+		}
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		p.claims = jwt.MapClaims{
+			"sub":                "",
+			"preferred_username": "alice",
+		}
+
+		_, _, err := s.OIDCCallback(httptest.NewRecorder(), p.login(t, s), testRedirectURL)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		r := p.login(t, s)
+		q := r.URL.Query()
+		q.Set("state", "invalid")
+		r.URL.RawQuery = q.Encode()
+
+		_, _, err := s.OIDCCallback(httptest.NewRecorder(), r, testRedirectURL)
+		assert.ErrorIs(t, err, ErrOIDCState)
+	})
+
+	t.Run("invalid nonce", func(t *testing.T) {
+		r := p.login(t, s)
+		p.nonce = "invalid"
+
+		_, _, err := s.OIDCCallback(httptest.NewRecorder(), r, testRedirectURL)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid audience", func(t *testing.T) {
+		p.claims = jwt.MapClaims{
+			"preferred_username": "alice",
+			"aud":                "other",
+		}
+
+		_, _, err := s.OIDCCallback(httptest.NewRecorder(), p.login(t, s), testRedirectURL)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing username", func(t *testing.T) {
+		// new users are created with the username
+		p.claims = jwt.MapClaims{
+			"sub": "new-subject",
+		}
+
+		_, _, err := s.OIDCCallback(httptest.NewRecorder(), p.login(t, s), testRedirectURL)
+		assert.Error(t, err)
+	})
+
+	t.Run("not permitted", func(t *testing.T) {
+		p.claims = jwt.MapClaims{
+			"sub":                "bob-subject",
+			"preferred_username": "bob",
+		}
+		users.allowed = false
+		defer func() { users.allowed = true }()
+
+		_, _, err := s.OIDCCallback(httptest.NewRecorder(), p.login(t, s), testRedirectURL)
+
+		var invalidCredentialsError *InvalidCredentialsError
+		assert.True(t, errors.As(err, &invalidCredentialsError))
+		assert.Equal(t, models.UserRole(""), users.role)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := NewStore(&sessionConfig{}, users)
+		err := s.OIDCLogin(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://stash/login/oidc", nil), testRedirectURL, "")
+		assert.ErrorIs(t, err, ErrOIDCDisabled)
+	})
+}
+
+func TestOIDCRole(t *testing.T) {
+	c := &sessionConfig{
+		roleGroups: map[models.UserRole][]string{
+			models.UserRoleAdmin:  {"admins"},
+			models.UserRoleEditor: {"editors"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   models.UserRole
+	}{
+		{"none", jwt.MapClaims{}, ""},
+		{"unmapped", jwt.MapClaims{"groups": []interface{}{"users"}}, ""},
+		{"single", jwt.MapClaims{"groups": "editors"}, models.UserRoleEditor},
+		{"highest", jwt.MapClaims{"groups": []interface{}{"editors", "admins"}}, models.UserRoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, oidcRole(c, oidcGroups(tt.claims, "groups")))
+		})
+	}
+}
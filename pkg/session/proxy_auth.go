@@ -0,0 +1,66 @@
+package session
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+// isTrustedProxy returns true if ip is one of the trusted IP addresses or
+// within one of the trusted CIDR ranges.
+func isTrustedProxy(trusted []string, ip net.IP) bool {
+	for _, t := range trusted {
+		t = strings.TrimSpace(t)
+
+		if !strings.Contains(t, "/") {
+			if trustedIP := net.ParseIP(t); trustedIP != nil && trustedIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(t)
+		if err != nil {
+			logger.Warnf("Ignoring invalid trusted proxy %q: %v", t, err)
+			continue
+		}
+
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getProxyAuthUserID returns the username set by a trusted reverse proxy in
+// the configured header, or an empty string if there is none. The header is
+// ignored if the request was not received from a trusted proxy.
+func (s *Store) getProxyAuthUserID(r *http.Request) string {
+	header := s.config.GetProxyAuthHeader()
+	if header == "" {
+		return ""
+	}
+
+	username := strings.TrimSpace(r.Header.Get(header))
+	if username == "" {
+		return ""
+	}
+
+	ip, err := remoteIP(r)
+	if err != nil {
+		logger.Warnf("Ignoring %s header: %v", header, err)
+		return ""
+	}
+
+	if !isTrustedProxy(s.config.GetProxyAuthTrustedProxies(), ip) {
+		logger.Warnf("%v with a %s header, but it is not a trusted proxy. The header was ignored. "+
+			"Stash may be reachable without going through the reverse proxy. "+
+			"If the address is that of the reverse proxy, add it to proxy_auth.trusted_proxies in config.yml.", ExternalAccessError(ip), header)
+		return ""
+	}
+
+	return username
+}
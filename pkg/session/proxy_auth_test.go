@@ -0,0 +1,60 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_AuthenticateProxyHeader(t *testing.T) {
+	const header = "Remote-User"
+
+	c := &sessionConfig{
+		proxyHeader:    header,
+		trustedProxies: []string{"10.0.0.0/8", "192.168.1.2", "invalid/cidr"},
+	}
+	s := NewStore(c, &testUsers{})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		username   string
+		want       string
+	}{
+		{"trusted range", "10.1.2.3:1234", "alice", "alice"},
+		{"trusted address", "192.168.1.2:1234", "alice", "alice"},
+		{"untrusted", "192.168.1.3:1234", "alice", ""},
+		{"public", "8.8.8.8:1234", "alice", ""},
+		{"no header", "10.1.2.3:1234", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://stash/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.username != "" {
+				r.Header.Set(header, tt.username)
+			}
+
+			got, apiKey, err := s.Authenticate(httptest.NewRecorder(), r)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+				assert.Nil(t, apiKey)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		s := NewStore(&sessionConfig{}, &testUsers{})
+
+		r := httptest.NewRequest(http.MethodGet, "http://stash/", nil)
+		r.RemoteAddr = "10.1.2.3:1234"
+		r.Header.Set(header, "alice")
+
+		got, _, err := s.Authenticate(httptest.NewRecorder(), r)
+		if assert.NoError(t, err) {
+			assert.Empty(t, got)
+		}
+	})
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 )
 
@@ -15,12 +18,20 @@ type key int
 
 const (
 	contextUser key = iota
+	contextUserRecord
 	contextVisitedPlugins
+	contextPluginRequest
+	contextAPIKey
+	contextClientIP
+	contextRestrictionProfileID
 )
 
 const (
-	userIDKey         = "userID"
-	visitedPluginsKey = "visitedPlugins"
+	userIDKey               = "userID"
+	restrictionProfileIDKey = "restrictionProfileID"
+	loginTimeKey            = "loginTime"
+	visitedPluginsKey       = "visitedPlugins"
+	pluginRequestKey        = "pluginRequest"
 )
 
 const (
@@ -45,15 +56,26 @@ func (e InvalidCredentialsError) Error() string {
 
 var ErrUnauthorized = errors.New("unauthorized")
 
+var ErrSessionRestricted = errors.New("session is already restricted")
+
 type Store struct {
 	sessionStore *sessions.CookieStore
 	config       SessionConfig
+	users        UserAuthenticator
+	throttle     *loginThrottle
+
+	oidcMutex sync.Mutex
+	oidc      *oidcProvider
 }
 
-func NewStore(c SessionConfig) *Store {
+// NewStore returns a new session store. Users other than the configured
+// user are authenticated with users, which may be nil.
+func NewStore(c SessionConfig, users UserAuthenticator) *Store {
 	ret := &Store{
 		sessionStore: sessions.NewCookieStore(c.GetSessionStoreKey()),
 		config:       c,
+		users:        users,
+		throttle:     newLoginThrottle(c),
 	}
 
 	ret.sessionStore.MaxAge(c.GetMaxSessionAge())
@@ -62,51 +84,141 @@ func NewStore(c SessionConfig) *Store {
 	return ret
 }
 
-func (s *Store) Login(w http.ResponseWriter, r *http.Request) error {
+// Login logs in the user with the credentials of the login form, returning
+// their username. It returns an InvalidCredentialsError if the credentials
+// are invalid, and a LoginBlockedError if the IP address of the client, or
+// the username from it, are blocked after too many failed attempts. If the user uses
+// two-factor authentication, it returns a SecondFactorRequiredError, and
+// the user is logged in by LoginSecondFactor.
+func (s *Store) Login(w http.ResponseWriter, r *http.Request) (string, error) {
 	// ignore error - we want a new session regardless
 	newSession, _ := s.sessionStore.Get(r, cookieName)
 
 	username := r.FormValue(usernameFormKey)
 	password := r.FormValue(passwordFormKey)
 
+	throttleKeys := s.loginThrottleKeys(r, username)
+	if err := s.throttle.check(throttleKeys...); err != nil {
+		return "", err
+	}
+	s.throttle.slowDown(throttleKeys...)
+
 	// authenticate the user
-	if !s.config.ValidateCredentials(username, password) {
-		return &InvalidCredentialsError{Username: username}
+	valid := s.config.ValidateCredentials(username, password)
+	if !valid && s.users != nil {
+		var err error
+		valid, err = s.users.ValidateCredentials(r.Context(), username, password)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	// since we only have one user, don't leak the name
-	logger.Info("User logged in")
+	if !valid {
+		s.loginFailed(throttleKeys...)
+		return "", &InvalidCredentialsError{Username: username}
+	}
 
-	newSession.Values[userIDKey] = username
+	if err := s.requireSecondFactor(w, r, newSession, username); err != nil {
+		return "", err
+	}
 
-	err := newSession.Save(r, w)
-	if err != nil {
+	if err := s.completeLogin(w, r, newSession, username, throttleKeys); err != nil {
+		return "", err
+	}
+
+	return username, nil
+}
+
+// loginThrottleKeys returns the keys failed logins of the username from the
+// client are counted against.
+func (s *Store) loginThrottleKeys(r *http.Request, username string) []loginThrottleKey {
+	ip := ClientIP(r, s.config.GetProxyAuthTrustedProxies())
+	ret := []loginThrottleKey{ipThrottleKey(ip)}
+	if username != "" {
+		ret = append(ret, pairThrottleKey(ip, username), usernameThrottleKey(username))
+	}
+
+	return ret
+}
+
+// completeLogin logs in the authenticated user, and forgets their failed
+// attempts.
+func (s *Store) completeLogin(w http.ResponseWriter, r *http.Request, newSession *sessions.Session, username string, throttleKeys []loginThrottleKey) error {
+	s.throttle.reset(throttleKeys...)
+
+	// don't leak the name
+	logger.Info("User logged in")
+
+	if err := s.setSessionUser(r.Context(), newSession, username); err != nil {
 		return err
 	}
 
+	return newSession.Save(r, w)
+}
+
+// setSessionUser stores the id of the user in the session, rather than
+// their username, so that the session does not log in a different user
+// given the username after the user is renamed or deleted.
+func (s *Store) setSessionUser(ctx context.Context, session *sessions.Session, username string) error {
+	id := 0
+	if s.users != nil {
+		var err error
+		id, err = s.users.LoginUserID(ctx, username)
+		if err != nil {
+			return err
+		}
+	}
+
+	session.Values[userIDKey] = id
+	session.Values[loginTimeKey] = time.Now().Unix()
 	return nil
 }
 
+// sessionUsername returns the username of the user with the id stored in
+// a session. Without users, only the configured user may log in, with id 0.
+func (s *Store) sessionUsername(ctx context.Context, id int) (string, error) {
+	if s.users == nil {
+		if id != 0 {
+			return "", nil
+		}
+		return s.config.GetUsername(), nil
+	}
+
+	return s.users.SessionUsername(ctx, id)
+}
+
 func (s *Store) Logout(w http.ResponseWriter, r *http.Request) error {
 	session, err := s.sessionStore.Get(r, cookieName)
 	if err != nil {
 		return err
 	}
 
-	delete(session.Values, userIDKey)
-	session.Options.MaxAge = -1
+	clearSession(session)
 
 	err = session.Save(r, w)
 	if err != nil {
 		return err
 	}
 
-	// since we only have one user, don't leak the name
+	// don't leak the name
 	logger.Infof("User logged out")
 
 	return nil
 }
 
+// clearSession logs out the session, which must then be saved.
+func clearSession(session *sessions.Session) {
+	delete(session.Values, userIDKey)
+	delete(session.Values, loginTimeKey)
+	delete(session.Values, restrictionProfileIDKey)
+	clearSecondFactor(session)
+	session.Options.MaxAge = -1
+}
+
+// GetSessionUserID returns the username of the user logged in with the
+// session of the request, or an empty string if there is none. Sessions
+// created before two-factor authentication was required of their user are
+// logged out.
 func (s *Store) GetSessionUserID(w http.ResponseWriter, r *http.Request) (string, error) {
 	session, err := s.sessionStore.Get(r, cookieName)
 	// ignore errors and treat as an empty user id, so that we handle expired
@@ -115,21 +227,82 @@ func (s *Store) GetSessionUserID(w http.ResponseWriter, r *http.Request) (string
 		return "", nil
 	}
 
-	if !session.IsNew {
-		val := session.Values[userIDKey]
+	if session.IsNew {
+		return "", nil
+	}
+
+	var username string
+	if id, ok := session.Values[userIDKey].(int); ok {
+		username, err = s.sessionUsername(r.Context(), id)
+		if err != nil {
+			return "", err
+		}
+	}
 
-		// refresh the cookie
-		err = session.Save(r, w)
+	if username != "" {
+		expired, err := s.loginExpired(r.Context(), session, username)
 		if err != nil {
 			return "", err
 		}
 
-		ret, _ := val.(string)
+		if expired {
+			// don't leak the name
+			logger.Info("Logging out session created before two-factor authentication was required")
+			clearSession(session)
+			username = ""
+		}
+	}
+
+	// refresh the cookie
+	if err := session.Save(r, w); err != nil {
+		return "", err
+	}
+
+	return username, nil
+}
+
+// loginExpired returns true if the session of the user was created before
+// two-factor authentication was required of them.
+func (s *Store) loginExpired(ctx context.Context, session *sessions.Session, username string) (bool, error) {
+	since := s.config.GetTOTPRequiredSince()
+	if since.IsZero() || s.users == nil {
+		return false, nil
+	}
+
+	// sessions created before login times were stored have none
+	loginTime, _ := session.Values[loginTimeKey].(int64)
+	if loginTime > since.Unix() {
+		return false, nil
+	}
+
+	return s.users.TOTPRequired(ctx, username)
+}
+
+// RestrictSession restricts the session of the request to the content
+// allowed by the restriction profile. The restriction can only be lifted by
+// logging out, so a restricted session cannot be restricted again.
+func (s *Store) RestrictSession(w http.ResponseWriter, r *http.Request, profileID int) error {
+	session, _ := s.sessionStore.Get(r, cookieName)
+
+	if _, restricted := session.Values[restrictionProfileIDKey]; restricted {
+		return ErrSessionRestricted
+	}
+
+	session.Values[restrictionProfileIDKey] = profileID
 
-		return ret, nil
+	return session.Save(r, w)
+}
+
+// GetSessionRestrictionProfileID returns the id of the restriction profile
+// of the session of the request, or 0 if the session is not restricted.
+func (s *Store) GetSessionRestrictionProfileID(r *http.Request) int {
+	session, err := s.sessionStore.Get(r, cookieName)
+	if err != nil {
+		return 0
 	}
 
-	return "", nil
+	ret, _ := session.Values[restrictionProfileIDKey].(int)
+	return ret
 }
 
 func SetCurrentUserID(ctx context.Context, userID string) context.Context {
@@ -147,6 +320,95 @@ func GetCurrentUserID(ctx context.Context) *string {
 	return nil
 }
 
+// SetClientIP sets the IP address of the client making the request in the
+// context.
+func SetClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextClientIP, ip)
+}
+
+// GetClientIP gets the IP address of the client making the request from the
+// context. It returns an empty string if it is not set.
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(contextClientIP).(string)
+	return ip
+}
+
+// SetRestrictionProfileID sets the id of the restriction profile of the
+// session or API key the request was authenticated with in the context.
+func SetRestrictionProfileID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, contextRestrictionProfileID, id)
+}
+
+// GetRestrictionProfileID gets the id of the restriction profile of the
+// session or API key the request was authenticated with from the context.
+// It returns 0 if there is none. The restriction profile of the user is not
+// included.
+func GetRestrictionProfileID(ctx context.Context) int {
+	id, _ := ctx.Value(contextRestrictionProfileID).(int)
+	return id
+}
+
+// SetCurrentUser sets the user record of the current user in the context.
+func SetCurrentUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, contextUserRecord, user)
+}
+
+// GetCurrentUser gets the user record of the current user from the provided
+// context. It returns nil if the request is not authenticated.
+func GetCurrentUser(ctx context.Context) *models.User {
+	user, _ := ctx.Value(contextUserRecord).(*models.User)
+	return user
+}
+
+// APIKey is the API key a request was authenticated with.
+type APIKey struct {
+	Key string
+	// Name is the name of the key. It is empty for the configured API key.
+	Name string
+	// Scopes are the scopes of the key. Keys without scopes, such as the
+	// configured API key, are unrestricted.
+	Scopes []models.APIKeyScope
+	// RestrictionProfileID is the restriction profile of the session the
+	// key was created from, or 0 if there is none.
+	RestrictionProfileID int
+}
+
+// HasScope returns true if the key is unrestricted or has the scope.
+func (k APIKey) HasScope(scope models.APIKeyScope) bool {
+	if k.Scopes == nil {
+		return true
+	}
+
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetAPIKey sets the API key the request was authenticated with in the
+// context.
+func SetAPIKey(ctx context.Context, apiKey *APIKey) context.Context {
+	return context.WithValue(ctx, contextAPIKey, apiKey)
+}
+
+// GetAPIKey gets the API key the request was authenticated with from the
+// context. It returns nil if the request was not authenticated with an API
+// key.
+func GetAPIKey(ctx context.Context) *APIKey {
+	apiKey, _ := ctx.Value(contextAPIKey).(*APIKey)
+	return apiKey
+}
+
+// HasAPIKeyScope returns true if the request was not authenticated with an
+// API key, or if the key has the scope.
+func HasAPIKeyScope(ctx context.Context, scope models.APIKeyScope) bool {
+	apiKey := GetAPIKey(ctx)
+	return apiKey == nil || apiKey.HasScope(scope)
+}
+
 func (s *Store) VisitedPluginHandler() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -160,6 +422,11 @@ func (s *Store) VisitedPluginHandler() func(http.Handler) http.Handler {
 				visitedPlugins, _ := val.([]string)
 
 				ctx := setVisitedPlugins(r.Context(), visitedPlugins)
+
+				if pluginRequest, _ := session.Values[pluginRequestKey].(bool); pluginRequest {
+					ctx = context.WithValue(ctx, contextPluginRequest, true)
+				}
+
 				r = r.WithContext(ctx)
 			}
 
@@ -177,6 +444,12 @@ func GetVisitedPlugins(ctx context.Context) []string {
 	return nil
 }
 
+// IsPluginRequest returns true if the request was made by a plugin.
+func IsPluginRequest(ctx context.Context) bool {
+	pluginRequest, _ := ctx.Value(contextPluginRequest).(bool)
+	return pluginRequest
+}
+
 func AddVisitedPlugin(ctx context.Context, pluginID string) context.Context {
 	curVal := GetVisitedPlugins(ctx)
 	curVal = stringslice.StrAppendUnique(curVal, pluginID)
@@ -188,15 +461,23 @@ func setVisitedPlugins(ctx context.Context, visitedPlugins []string) context.Con
 }
 
 func (s *Store) MakePluginCookie(ctx context.Context) *http.Cookie {
-	currentUser := GetCurrentUserID(ctx)
+	currentUser := GetCurrentUser(ctx)
 	visitedPlugins := GetVisitedPlugins(ctx)
 
 	session := sessions.NewSession(s.sessionStore, cookieName)
 	if currentUser != nil {
-		session.Values[userIDKey] = *currentUser
+		session.Values[userIDKey] = currentUser.ID
+		// the request starting the plugin was authenticated now
+		session.Values[loginTimeKey] = time.Now().Unix()
+	}
+
+	// plugins are restricted like the session which started them
+	if profileID := GetRestrictionProfileID(ctx); profileID != 0 {
+		session.Values[restrictionProfileIDKey] = profileID
 	}
 
 	session.Values[visitedPluginsKey] = visitedPlugins
+	session.Values[pluginRequestKey] = true
 
 	encoded, err := securecookie.EncodeMulti(session.Name(), session.Values,
 		s.sessionStore.Codecs...)
@@ -208,34 +489,99 @@ func (s *Store) MakePluginCookie(ctx context.Context) *http.Cookie {
 	return sessions.NewCookie(session.Name(), encoded, session.Options)
 }
 
-func (s *Store) Authenticate(w http.ResponseWriter, r *http.Request) (userID string, err error) {
-	c := s.config
-
+// Authenticate returns the id of the user making the request, and the API
+// key the request was authenticated with, if any. Requests are authenticated
+// by API key, then by the header set by a trusted reverse proxy, then by
+// session cookie.
+func (s *Store) Authenticate(w http.ResponseWriter, r *http.Request) (userID string, apiKey *APIKey, err error) {
 	// translate api key into current user, if present
-	apiKey := r.Header.Get(ApiKeyHeader)
+	key := r.Header.Get(ApiKeyHeader)
 
 	// try getting the api key as a query parameter
-	if apiKey == "" {
-		apiKey = r.URL.Query().Get(ApiKeyParameter)
+	if key == "" {
+		key = r.URL.Query().Get(ApiKeyParameter)
 	}
 
-	if apiKey != "" {
-		// match against configured API and set userID to the
-		// configured username. In future, we'll want to
-		// get the username from the key.
-		if c.GetAPIKey() != apiKey {
-			return "", ErrUnauthorized
+	if key != "" {
+		// API keys may be guessed like passwords
+		ipKey := ipThrottleKey(ClientIP(r, s.config.GetProxyAuthTrustedProxies()))
+		if err := s.throttle.check(ipKey); err != nil {
+			return "", nil, err
 		}
 
-		userID = c.GetUsername()
+		userID, apiKey, err = s.authenticateAPIKey(r.Context(), key)
+		if errors.Is(err, ErrUnauthorized) {
+			s.loginFailed(ipKey)
+		}
+	} else if proxyUserID := s.getProxyAuthUserID(r); proxyUserID != "" {
+		// the user was authenticated by a trusted reverse proxy
+		userID = proxyUserID
 	} else {
 		// handle session
 		userID, err = s.GetSessionUserID(w, r)
 	}
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	return
 }
+
+func (s *Store) authenticateAPIKey(ctx context.Context, key string) (string, *APIKey, error) {
+	// the configured API key acts as the configured user
+	if configured := s.config.GetAPIKey(); configured != "" && configured == key {
+		return s.config.GetUsername(), &APIKey{Key: key}, nil
+	}
+
+	if s.users == nil {
+		return "", nil, ErrUnauthorized
+	}
+
+	username, apiKey, err := s.users.AuthenticateAPIKey(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if username == "" || apiKey == nil {
+		return "", nil, ErrUnauthorized
+	}
+
+	ret := &APIKey{Key: key, Name: apiKey.Name, Scopes: apiKey.Scopes}
+	if apiKey.RestrictionProfileID != nil {
+		ret.RestrictionProfileID = *apiKey.RestrictionProfileID
+	}
+
+	return username, ret, nil
+}
+
+// loginFailed records a failed login for the throttle keys, logging those
+// which are blocked as a result.
+func (s *Store) loginFailed(keys ...loginThrottleKey) {
+	for _, b := range s.throttle.fail(keys...) {
+		LogLoginBlocked(b)
+	}
+}
+
+// LoginBlocks returns the IP addresses and usernames which are blocked from
+// logging in after too many failed attempts.
+func (s *Store) LoginBlocks() []LoginBlock {
+	return s.throttle.blocks()
+}
+
+// UnblockIP forgets the failed logins from the IP address, returning true if
+// it or any username was blocked from it.
+func (s *Store) UnblockIP(ip string) bool {
+	return s.throttle.resetMatching(func(k loginThrottleKey) bool {
+		return k.ip == ip
+	})
+}
+
+// UnblockUsername forgets the failed logins with the username, returning
+// true if it was blocked from any IP address.
+func (s *Store) UnblockUsername(username string) bool {
+	username = usernameThrottleKey(username).username
+	return s.throttle.resetMatching(func(k loginThrottleKey) bool {
+		return k.username == username
+	})
+}
@@ -0,0 +1,300 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type sessionConfig struct {
+	issuer         string
+	roleGroups     map[models.UserRole][]string
+	defaultRole    models.UserRole
+	proxyHeader    string
+	trustedProxies []string
+	maxAttempts    int
+	maxBlock       time.Duration
+	totpSince      time.Time
+}
+
+func (c *sessionConfig) GetUsername() string {
+	return "owner"
+}
+
+func (c *sessionConfig) GetAPIKey() string {
+	return ""
+}
+
+func (c *sessionConfig) GetSessionStoreKey() []byte {
+	return []byte("0123456789abcdef0123456789abcdef")
+}
+
+func (c *sessionConfig) GetMaxSessionAge() int {
+	return 60
+}
+
+func (c *sessionConfig) ValidateCredentials(username string, password string) bool {
+	return false
+}
+
+func (c *sessionConfig) GetTOTPRequiredSince() time.Time {
+	return c.totpSince
+}
+
+func (c *sessionConfig) GetOIDCIssuer() string {
+	return c.issuer
+}
+
+func (c *sessionConfig) GetOIDCClientID() string {
+	return testClientID
+}
+
+func (c *sessionConfig) GetOIDCClientSecret() string {
+	return testClientSecret
+}
+
+func (c *sessionConfig) GetOIDCUsernameClaim() string {
+	return "preferred_username"
+}
+
+func (c *sessionConfig) GetOIDCGroupsClaim() string {
+	return "groups"
+}
+
+func (c *sessionConfig) GetOIDCRoleGroups() map[models.UserRole][]string {
+	return c.roleGroups
+}
+
+func (c *sessionConfig) GetOIDCDefaultRole() models.UserRole {
+	return c.defaultRole
+}
+
+func (c *sessionConfig) GetProxyAuthHeader() string {
+	return c.proxyHeader
+}
+
+func (c *sessionConfig) GetProxyAuthTrustedProxies() []string {
+	return c.trustedProxies
+}
+
+func (c *sessionConfig) GetLoginMaxAttempts() int {
+	return c.maxAttempts
+}
+
+func (c *sessionConfig) GetLoginMaxBlockDuration() time.Duration {
+	return c.maxBlock
+}
+
+type testUsers struct {
+	allowed bool
+	role    models.UserRole
+
+	// password is the password of all users
+	password           string
+	totpEnabled        bool
+	enrollmentRequired bool
+	// code is the current code of the authenticator app
+	code string
+
+	// usernames are the usernames of the users who logged in, by id
+	usernames map[int]string
+	// oidcUsers are the usernames of the users linked to OpenID Connect
+	// subjects
+	oidcUsers map[string]string
+	// apiKeys are the API keys of the user "user", by key
+	apiKeys map[string]*models.APIKey
+	// totpRequired are the usernames of the users who must use two-factor
+	// authentication
+	totpRequired map[string]bool
+}
+
+func (u *testUsers) ValidateCredentials(ctx context.Context, username string, password string) (bool, error) {
+	return u.password != "" && password == u.password, nil
+}
+
+func (u *testUsers) AuthenticateAPIKey(ctx context.Context, key string) (string, *models.APIKey, error) {
+	if k, ok := u.apiKeys[key]; ok {
+		return "user", k, nil
+	}
+	return "", nil, nil
+}
+
+func (u *testUsers) LoginOIDCUser(ctx context.Context, issuer string, subject string, username string, role models.UserRole, defaultRole models.UserRole) (string, error) {
+	u.role = role
+	if linked, ok := u.oidcUsers[subject]; ok {
+		return linked, nil
+	}
+
+	if !u.allowed || username == "" {
+		return "", nil
+	}
+
+	if u.oidcUsers == nil {
+		u.oidcUsers = make(map[string]string)
+	}
+	u.oidcUsers[subject] = username
+	return username, nil
+}
+
+func (u *testUsers) LoginUserID(ctx context.Context, username string) (int, error) {
+	for id, n := range u.usernames {
+		if n == username {
+			return id, nil
+		}
+	}
+
+	if u.usernames == nil {
+		u.usernames = make(map[int]string)
+	}
+
+	id := len(u.usernames) + 1
+	u.usernames[id] = username
+	return id, nil
+}
+
+func (u *testUsers) SessionUsername(ctx context.Context, id int) (string, error) {
+	return u.usernames[id], nil
+}
+
+func (u *testUsers) SecondFactorStatus(ctx context.Context, username string) (bool, bool, error) {
+	return u.totpEnabled, u.enrollmentRequired, nil
+}
+
+func (u *testUsers) EnrollLoginTOTP(ctx context.Context, username string) (*models.TOTPEnrollment, error) {
+	return &models.TOTPEnrollment{Secret: "SECRET"}, nil
+}
+
+func (u *testUsers) ConfirmLoginTOTP(ctx context.Context, username string, code string) ([]string, error) {
+	if code != u.code {
+		return nil, nil
+	}
+
+	u.totpEnabled = true
+	u.enrollmentRequired = false
+	return []string{"recovery"}, nil
+}
+
+func (u *testUsers) VerifySecondFactor(ctx context.Context, username string, code string) (bool, error) {
+	return code == u.code, nil
+}
+
+func (u *testUsers) TOTPRequired(ctx context.Context, username string) (bool, error) {
+	return u.totpRequired[username], nil
+}
+
+func TestStore_RestrictSession(t *testing.T) {
+	s := NewStore(&sessionConfig{}, &testUsers{})
+
+	withCookies := func(w *httptest.ResponseRecorder) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://stash/", nil)
+		for _, c := range w.Result().Cookies() {
+			r.AddCookie(c)
+		}
+		return r
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "http://stash/session/restrict", nil)
+	assert.Equal(t, 0, s.GetSessionRestrictionProfileID(r))
+
+	w := httptest.NewRecorder()
+	if err := s.RestrictSession(w, r, 2); err != nil {
+		t.Fatalf("RestrictSession() error = %v", err)
+	}
+
+	restricted := withCookies(w)
+	assert.Equal(t, 2, s.GetSessionRestrictionProfileID(restricted))
+
+	// the restriction cannot be replaced
+	err := s.RestrictSession(httptest.NewRecorder(), restricted, 3)
+	assert.ErrorIs(t, err, ErrSessionRestricted)
+
+	// logging out lifts the restriction
+	w = httptest.NewRecorder()
+	if err := s.Logout(w, restricted); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	assert.Equal(t, 0, s.GetSessionRestrictionProfileID(withCookies(w)))
+}
+
+func TestStore_AuthenticateAPIKeyRestriction(t *testing.T) {
+	profileID := 2
+	users := &testUsers{apiKeys: map[string]*models.APIKey{
+		"unrestricted": {Name: "dlna"},
+		"restricted":   {Name: "external player", RestrictionProfileID: &profileID},
+	}}
+	s := NewStore(&sessionConfig{maxAttempts: 5}, users)
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"unrestricted", 0},
+		{"restricted", profileID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://stash/scene/1/stream?apikey="+tt.key, nil)
+			userID, apiKey, err := s.Authenticate(httptest.NewRecorder(), r)
+			if !assert.NoError(t, err) || !assert.NotNil(t, apiKey) {
+				return
+			}
+			assert.Equal(t, "user", userID)
+			assert.Equal(t, tt.want, apiKey.RestrictionProfileID)
+		})
+	}
+}
+
+func TestStore_SessionUser(t *testing.T) {
+	users := &testUsers{password: "password"}
+	s := NewStore(&sessionConfig{maxAttempts: 5}, users)
+
+	w := httptest.NewRecorder()
+	if _, err := s.Login(w, postForm(url.Values{
+		usernameFormKey: {"alice"},
+		passwordFormKey: {"password"},
+	}, nil)); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	cookies := w.Result().Cookies()
+	userID, err := s.GetSessionUserID(httptest.NewRecorder(), postForm(nil, cookies))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "alice", userID)
+	}
+
+	// the session follows the user when they are renamed
+	users.usernames[1] = "bob"
+	userID, err = s.GetSessionUserID(httptest.NewRecorder(), postForm(nil, cookies))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bob", userID)
+	}
+
+	// and does not log in another user given the username once they are
+	// deleted
+	delete(users.usernames, 1)
+	userID, err = s.GetSessionUserID(httptest.NewRecorder(), postForm(nil, cookies))
+	if assert.NoError(t, err) {
+		assert.Empty(t, userID)
+	}
+}
+
+func TestStore_PluginCookieRestriction(t *testing.T) {
+	s := NewStore(&sessionConfig{}, &testUsers{usernames: map[int]string{1: "alice"}})
+
+	ctx := SetCurrentUser(context.Background(), &models.User{ID: 1, Username: "alice"})
+
+	r := httptest.NewRequest(http.MethodGet, "http://stash/plugin", nil)
+	r.AddCookie(s.MakePluginCookie(ctx))
+	assert.Equal(t, 0, s.GetSessionRestrictionProfileID(r))
+
+	r = httptest.NewRequest(http.MethodGet, "http://stash/plugin", nil)
+	r.AddCookie(s.MakePluginCookie(SetRestrictionProfileID(ctx, 2)))
+	assert.Equal(t, 2, s.GetSessionRestrictionProfileID(r))
+}
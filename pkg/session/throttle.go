@@ -0,0 +1,316 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// loginBackoffBase is the time an IP address or username is blocked
+	// for when it reaches the maximum number of failed attempts. It doubles
+	// with each further failed attempt.
+	loginBackoffBase = time.Minute
+	// loginAttemptsExpiry is the time after which the failed attempts of an
+	// IP address or username are forgotten, if there are no further
+	// failures.
+	loginAttemptsExpiry = 24 * time.Hour
+	// loginIPAttemptsFactor is the multiple of the maximum number of
+	// attempts after which an IP address is blocked. Addresses may be shared
+	// by several users, so usernames are blocked from the address first.
+	loginIPAttemptsFactor = 4
+	// loginDelayBase is the time logins with a username are delayed by when
+	// it reaches the maximum number of failed attempts from any address. It
+	// doubles with each further failed attempt, up to loginMaxDelay.
+	loginDelayBase = time.Second
+	loginMaxDelay  = 8 * time.Second
+)
+
+// LoginThrottleConfig is the configuration of the blocking of IP addresses
+// and usernames after repeated failed logins.
+type LoginThrottleConfig interface {
+	// GetLoginMaxAttempts returns the number of failed attempts after which
+	// a username is blocked from an IP address, or 0 if logins are never
+	// blocked.
+	GetLoginMaxAttempts() int
+	// GetLoginMaxBlockDuration returns the maximum time an IP address or
+	// username is blocked for.
+	GetLoginMaxBlockDuration() time.Duration
+}
+
+// LoginBlockedError is returned when logging in from a blocked IP address or
+// with a blocked username.
+type LoginBlockedError struct {
+	Until time.Time
+}
+
+func (e LoginBlockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, blocked until %s", e.Until.Format(time.RFC3339))
+}
+
+// LoginBlock is an IP address blocked from logging in, or a username blocked
+// from logging in from an IP address if Username is set.
+type LoginBlock struct {
+	IP             string
+	Username       string
+	FailedAttempts int
+	BlockedUntil   time.Time
+}
+
+type loginThrottleKey struct {
+	ip       string
+	username string
+}
+
+func ipThrottleKey(ip string) loginThrottleKey {
+	return loginThrottleKey{ip: ip}
+}
+
+// pairThrottleKey counts the failed logins with the username from the IP
+// address.
+func pairThrottleKey(ip string, username string) loginThrottleKey {
+	return loginThrottleKey{ip: ip, username: strings.ToLower(username)}
+}
+
+// usernameThrottleKey counts the failed logins with the username from any
+// address. Logins with the username are slowed down rather than blocked, so
+// that others cannot lock out its user.
+func usernameThrottleKey(username string) loginThrottleKey {
+	return loginThrottleKey{username: strings.ToLower(username)}
+}
+
+// maxAttempts returns the number of failed attempts after which the key is
+// blocked, or 0 if it is never blocked.
+func (k loginThrottleKey) maxAttempts(maxAttempts int) int {
+	switch {
+	case k.ip == "":
+		return 0
+	case k.username == "":
+		return maxAttempts * loginIPAttemptsFactor
+	default:
+		return maxAttempts
+	}
+}
+
+type failedLogins struct {
+	count        int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// loginThrottle counts the failed logins of IP addresses and usernames, and
+// blocks them with exponential backoff once they reach the maximum number of
+// attempts. It is kept in memory, so blocks are lifted on restart.
+type loginThrottle struct {
+	config LoginThrottleConfig
+	now    func() time.Time
+	sleep  func(time.Duration)
+
+	mutex  sync.Mutex
+	failed map[loginThrottleKey]*failedLogins
+}
+
+func newLoginThrottle(c LoginThrottleConfig) *loginThrottle {
+	return &loginThrottle{
+		config: c,
+		now:    time.Now,
+		sleep:  time.Sleep,
+		failed: make(map[loginThrottleKey]*failedLogins),
+	}
+}
+
+// check returns a LoginBlockedError if any of the keys are blocked.
+func (t *loginThrottle) check(keys ...loginThrottleKey) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	var until time.Time
+	for _, k := range keys {
+		if f := t.failed[k]; f != nil && f.blockedUntil.After(now) && f.blockedUntil.After(until) {
+			until = f.blockedUntil
+		}
+	}
+
+	if until.IsZero() {
+		return nil
+	}
+
+	return &LoginBlockedError{Until: until}
+}
+
+// fail records a failed attempt for each of the keys. It returns the keys
+// which are blocked as a result.
+func (t *loginThrottle) fail(keys ...loginThrottleKey) []LoginBlock {
+	maxAttempts := t.config.GetLoginMaxAttempts()
+	if maxAttempts <= 0 {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	t.expire(now)
+
+	var ret []LoginBlock
+	for _, k := range keys {
+		f := t.failed[k]
+		if f == nil {
+			f = &failedLogins{}
+			t.failed[k] = f
+		}
+
+		f.count++
+		f.lastFailure = now
+
+		keyMaxAttempts := k.maxAttempts(maxAttempts)
+		if keyMaxAttempts == 0 || f.count < keyMaxAttempts {
+			continue
+		}
+
+		f.blockedUntil = now.Add(t.backoff(f.count - keyMaxAttempts))
+		ret = append(ret, f.block(k))
+	}
+
+	return ret
+}
+
+// backoff returns the time to block for after n failed attempts beyond the
+// maximum.
+func (t *loginThrottle) backoff(n int) time.Duration {
+	maxBlock := t.config.GetLoginMaxBlockDuration()
+	if maxBlock < loginBackoffBase {
+		maxBlock = loginBackoffBase
+	}
+
+	ret := loginBackoffBase
+	for i := 0; i < n && ret < maxBlock; i++ {
+		ret *= 2
+	}
+
+	if ret > maxBlock {
+		ret = maxBlock
+	}
+
+	return ret
+}
+
+// delay returns the time to wait before checking the credentials of the
+// keys, for usernames which have reached the maximum number of attempts.
+func (t *loginThrottle) delay(keys ...loginThrottleKey) time.Duration {
+	maxAttempts := t.config.GetLoginMaxAttempts()
+	if maxAttempts <= 0 {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var ret time.Duration
+	for _, k := range keys {
+		f := t.failed[k]
+		if k.ip != "" || f == nil || f.count < maxAttempts {
+			continue
+		}
+
+		d := loginDelayBase
+		for i := maxAttempts; i < f.count && d < loginMaxDelay; i++ {
+			d *= 2
+		}
+
+		if d > loginMaxDelay {
+			d = loginMaxDelay
+		}
+
+		if d > ret {
+			ret = d
+		}
+	}
+
+	return ret
+}
+
+// slowDown waits for the delay of the keys.
+func (t *loginThrottle) slowDown(keys ...loginThrottleKey) {
+	if d := t.delay(keys...); d > 0 {
+		t.sleep(d)
+	}
+}
+
+// reset forgets the failed attempts of the keys. It returns true if any of
+// them were blocked.
+func (t *loginThrottle) reset(keys ...loginThrottleKey) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	ret := false
+	for _, k := range keys {
+		if f := t.failed[k]; f != nil {
+			if f.blockedUntil.After(now) {
+				ret = true
+			}
+			delete(t.failed, k)
+		}
+	}
+
+	return ret
+}
+
+// resetMatching forgets the failed attempts of the keys matching f. It
+// returns true if any of them were blocked.
+func (t *loginThrottle) resetMatching(f func(k loginThrottleKey) bool) bool {
+	t.mutex.Lock()
+	var keys []loginThrottleKey
+	for k := range t.failed {
+		if f(k) {
+			keys = append(keys, k)
+		}
+	}
+	t.mutex.Unlock()
+
+	return t.reset(keys...)
+}
+
+// expire forgets the failed attempts which have expired. The mutex must be
+// held.
+func (t *loginThrottle) expire(now time.Time) {
+	for k, f := range t.failed {
+		if now.Sub(f.lastFailure) > loginAttemptsExpiry && !f.blockedUntil.After(now) {
+			delete(t.failed, k)
+		}
+	}
+}
+
+// blocks returns the blocked IP addresses and usernames, ordered by the time
+// they are blocked until.
+func (t *loginThrottle) blocks() []LoginBlock {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	var ret []LoginBlock
+	for k, f := range t.failed {
+		if f.blockedUntil.After(now) {
+			ret = append(ret, f.block(k))
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].BlockedUntil.Before(ret[j].BlockedUntil)
+	})
+
+	return ret
+}
+
+func (f *failedLogins) block(k loginThrottleKey) LoginBlock {
+	return LoginBlock{
+		IP:             k.ip,
+		Username:       k.username,
+		FailedAttempts: f.count,
+		BlockedUntil:   f.blockedUntil,
+	}
+}
@@ -0,0 +1,210 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := &sessionConfig{
+		maxAttempts: 3,
+		maxBlock:    5 * time.Minute,
+	}
+	th := newLoginThrottle(c)
+	th.now = func() time.Time { return now }
+
+	ip := ipThrottleKey("1.2.3.4")
+	pair := pairThrottleKey("1.2.3.4", "Alice")
+	user := usernameThrottleKey("Alice")
+
+	// not blocked before the maximum number of attempts
+	assert.Empty(t, th.fail(ip, pair, user))
+	assert.Empty(t, th.fail(ip, pair, user))
+	assert.NoError(t, th.check(ip, pair, user))
+	assert.Zero(t, th.delay(ip, pair, user))
+
+	// the username is blocked from the address, but the address and the
+	// username are not blocked themselves
+	blocks := th.fail(ip, pair, user)
+	assert.Equal(t, []LoginBlock{{IP: "1.2.3.4", Username: "alice", FailedAttempts: 3, BlockedUntil: now.Add(time.Minute)}}, blocks)
+
+	var blockedErr *LoginBlockedError
+	if assert.True(t, errors.As(th.check(ip, pair, user), &blockedErr)) {
+		assert.Equal(t, now.Add(time.Minute), blockedErr.Until)
+	}
+	assert.NoError(t, th.check(ip, pairThrottleKey("5.6.7.8", "alice"), user))
+
+	// the username is slowed down instead
+	assert.Equal(t, loginDelayBase, th.delay(ip, pair, user))
+
+	// the block doubles with each further failure, up to the maximum
+	for _, want := range []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		blocks = th.fail(pair)
+		if assert.Len(t, blocks, 1) {
+			assert.Equal(t, now.Add(want), blocks[0].BlockedUntil)
+		}
+	}
+
+	// the block is lifted after it expires
+	now = now.Add(5 * time.Minute)
+	assert.NoError(t, th.check(pair))
+	assert.Empty(t, th.blocks())
+
+	// the address is blocked after more failures, with any usernames
+	for i := 3; i < c.maxAttempts*loginIPAttemptsFactor-1; i++ {
+		assert.Empty(t, th.fail(ip))
+	}
+	blocks = th.fail(ip)
+	assert.Equal(t, []LoginBlock{{IP: "1.2.3.4", FailedAttempts: 12, BlockedUntil: now.Add(time.Minute)}}, blocks)
+
+	assert.True(t, th.reset(ip))
+	assert.False(t, th.reset(ip))
+	assert.NoError(t, th.check(ip))
+
+	// the delay of usernames doubles, up to the maximum, and usernames are
+	// case insensitive
+	for _, want := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		th.fail(usernameThrottleKey("ALICE"))
+		assert.Equal(t, want, th.delay(user))
+	}
+	assert.NoError(t, th.check(user))
+
+	// failed attempts are forgotten after they expire
+	now = now.Add(loginAttemptsExpiry + time.Minute)
+	th.fail(pair)
+	assert.Empty(t, th.fail(pair))
+	assert.Zero(t, th.delay(user))
+}
+
+func TestLoginThrottle_Disabled(t *testing.T) {
+	th := newLoginThrottle(&sessionConfig{})
+
+	ip := ipThrottleKey("1.2.3.4")
+	for i := 0; i < 10; i++ {
+		assert.Empty(t, th.fail(ip))
+	}
+	assert.NoError(t, th.check(ip))
+}
+
+func TestStore_LoginThrottle(t *testing.T) {
+	s := NewStore(&sessionConfig{maxAttempts: 2, maxBlock: time.Hour}, &testUsers{})
+
+	var delays []time.Duration
+	s.throttle.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+
+	login := func(remoteAddr string, username string) error {
+		form := url.Values{
+			usernameFormKey: {username},
+			passwordFormKey: {"wrong"},
+		}
+		r := httptest.NewRequest(http.MethodPost, "http://stash/login", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.RemoteAddr = remoteAddr
+
+		_, err := s.Login(httptest.NewRecorder(), r)
+		return err
+	}
+
+	var invalidErr *InvalidCredentialsError
+	var blockedErr *LoginBlockedError
+
+	assert.True(t, errors.As(login("8.8.8.8:1234", "alice"), &invalidErr))
+	assert.True(t, errors.As(login("8.8.8.8:1234", "alice"), &invalidErr))
+
+	// the username is blocked from the address
+	assert.True(t, errors.As(login("8.8.8.8:1234", "alice"), &blockedErr))
+
+	// but not from other addresses, where it is slowed down
+	assert.True(t, errors.As(login("8.8.4.4:1234", "alice"), &invalidErr))
+	assert.Equal(t, []time.Duration{loginDelayBase}, delays)
+
+	// the address is blocked with any username after more failures
+	for i := 0; i < 5; i++ {
+		assert.True(t, errors.As(login("8.8.8.8:1234", fmt.Sprintf("user%d", i)), &invalidErr))
+	}
+	assert.True(t, errors.As(login("8.8.8.8:1234", "carol"), &invalidErr))
+	assert.True(t, errors.As(login("8.8.8.8:1234", "dave"), &blockedErr))
+
+	var blocked []string
+	for _, b := range s.LoginBlocks() {
+		blocked = append(blocked, b.IP+"/"+b.Username)
+	}
+	assert.ElementsMatch(t, []string{"8.8.8.8/", "8.8.8.8/alice"}, blocked)
+
+	// unblocking the username leaves the address blocked
+	assert.True(t, s.UnblockUsername("Alice"))
+	if blocks := s.LoginBlocks(); assert.Len(t, blocks, 1) {
+		assert.Empty(t, blocks[0].Username)
+	}
+	assert.True(t, s.UnblockIP("8.8.8.8"))
+	assert.Empty(t, s.LoginBlocks())
+	assert.True(t, errors.As(login("8.8.8.8:1234", "carol"), &invalidErr))
+}
+
+func TestStore_LoginThrottleForwardedFor(t *testing.T) {
+	s := NewStore(&sessionConfig{maxAttempts: 2, maxBlock: time.Hour, trustedProxies: []string{"10.0.0.1"}}, &testUsers{})
+
+	login := func(forged string, username string) error {
+		form := url.Values{
+			usernameFormKey: {username},
+			passwordFormKey: {"wrong"},
+		}
+		r := httptest.NewRequest(http.MethodPost, "http://stash/login", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		// the proxy appends the address of the client to the header sent by
+		// the client, which the client may forge
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", forged+", 8.8.8.8")
+
+		_, err := s.Login(httptest.NewRecorder(), r)
+		return err
+	}
+
+	var invalidErr *InvalidCredentialsError
+	var blockedErr *LoginBlockedError
+
+	for i := 0; i < 8; i++ {
+		assert.True(t, errors.As(login(fmt.Sprintf("1.1.1.%d", i), fmt.Sprintf("user%d", i)), &invalidErr))
+	}
+
+	// the address of the client is blocked, whatever it forges
+	assert.True(t, errors.As(login("1.1.1.9", "carol"), &blockedErr))
+
+	var blocked []string
+	for _, b := range s.LoginBlocks() {
+		blocked = append(blocked, b.IP)
+	}
+	assert.Equal(t, []string{"8.8.8.8"}, blocked)
+}
+
+func TestStore_APIKeyThrottleForwardedFor(t *testing.T) {
+	s := NewStore(&sessionConfig{maxAttempts: 2, maxBlock: time.Hour, trustedProxies: []string{"10.0.0.1"}}, &testUsers{})
+
+	authenticate := func(forged string) error {
+		r := httptest.NewRequest(http.MethodGet, "http://stash/graphql?apikey=guess", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Forwarded-For", forged+", 8.8.8.8")
+
+		_, _, err := s.Authenticate(httptest.NewRecorder(), r)
+		return err
+	}
+
+	var blockedErr *LoginBlockedError
+
+	for i := 0; i < 8; i++ {
+		assert.ErrorIs(t, authenticate(fmt.Sprintf("1.1.1.%d", i)), ErrUnauthorized)
+	}
+	assert.True(t, errors.As(authenticate("1.1.1.9"), &blockedErr))
+}
@@ -0,0 +1,155 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	pendingUserIDKey  = "pendingUserID"
+	pendingExpiresKey = "pendingExpires"
+	codeFormKey       = "code"
+
+	// secondFactorTimeout is how long users have to enter their code after
+	// their password.
+	secondFactorTimeout = 5 * time.Minute
+)
+
+// ErrSecondFactorExpired is returned by LoginSecondFactor when a code is
+// entered without a valid password first, or too long after it.
+var ErrSecondFactorExpired = errors.New("login has expired")
+
+// SecondFactorRequiredError is returned by Login when the password is
+// valid, but the user must also enter a code of their authenticator app.
+type SecondFactorRequiredError struct {
+	Username string
+	// Enrollment is the secret to add to the authenticator app, if the
+	// user must enroll in two-factor authentication first.
+	Enrollment *models.TOTPEnrollment
+}
+
+func (e SecondFactorRequiredError) Error() string {
+	return "two-factor authentication code required"
+}
+
+// InvalidCodeError is returned by LoginSecondFactor when the code is
+// invalid.
+type InvalidCodeError struct {
+	Username string
+	// Enrollment is the secret being enrolled, if the user is enrolling.
+	Enrollment *models.TOTPEnrollment
+}
+
+func (e InvalidCodeError) Error() string {
+	return "invalid two-factor authentication code"
+}
+
+func clearSecondFactor(session *sessions.Session) {
+	delete(session.Values, pendingUserIDKey)
+	delete(session.Values, pendingExpiresKey)
+}
+
+// requireSecondFactor returns a SecondFactorRequiredError if the user must
+// enter a code after their password, saving them in the session until they
+// do.
+func (s *Store) requireSecondFactor(w http.ResponseWriter, r *http.Request, newSession *sessions.Session, username string) error {
+	if s.users == nil {
+		return nil
+	}
+
+	enabled, enrollmentRequired, err := s.users.SecondFactorStatus(r.Context(), username)
+	if err != nil || (!enabled && !enrollmentRequired) {
+		return err
+	}
+
+	ret := &SecondFactorRequiredError{Username: username}
+	if enrollmentRequired {
+		ret.Enrollment, err = s.users.EnrollLoginTOTP(r.Context(), username)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the user is not logged in until they enter their code
+	delete(newSession.Values, userIDKey)
+	newSession.Values[pendingUserIDKey] = username
+	newSession.Values[pendingExpiresKey] = time.Now().Add(secondFactorTimeout).Unix()
+
+	if err := newSession.Save(r, w); err != nil {
+		return err
+	}
+
+	return ret
+}
+
+// LoginSecondFactor logs in the user who entered their password with the
+// code of the login form, returning their username. The code of users
+// enrolling in two-factor authentication confirms their enrollment, and
+// their recovery codes are returned. It returns an InvalidCodeError if the
+// code is invalid, a LoginBlockedError if the IP address of the client or
+// the username are blocked after too many failed attempts, and
+// ErrSecondFactorExpired if the password must be entered again.
+func (s *Store) LoginSecondFactor(w http.ResponseWriter, r *http.Request) (username string, recoveryCodes []string, err error) {
+	// ignore error - an invalid session has no pending user
+	newSession, _ := s.sessionStore.Get(r, cookieName)
+
+	username, _ = newSession.Values[pendingUserIDKey].(string)
+	expires, _ := newSession.Values[pendingExpiresKey].(int64)
+	if username == "" || s.users == nil || time.Now().Unix() > expires {
+		return "", nil, ErrSecondFactorExpired
+	}
+
+	throttleKeys := s.loginThrottleKeys(r, username)
+	if err := s.throttle.check(throttleKeys...); err != nil {
+		return "", nil, err
+	}
+	s.throttle.slowDown(throttleKeys...)
+
+	ctx := r.Context()
+	code := r.FormValue(codeFormKey)
+
+	enabled, enrollmentRequired, err := s.users.SecondFactorStatus(ctx, username)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// two-factor authentication may have been reset since the password was
+	// entered, in which case the password is enough
+	valid := true
+	switch {
+	case enabled:
+		valid, err = s.users.VerifySecondFactor(ctx, username, code)
+	case enrollmentRequired:
+		recoveryCodes, err = s.users.ConfirmLoginTOTP(ctx, username, code)
+		valid = recoveryCodes != nil
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !valid {
+		s.loginFailed(throttleKeys...)
+
+		ret := &InvalidCodeError{Username: username}
+		if enrollmentRequired {
+			ret.Enrollment, err = s.users.EnrollLoginTOTP(ctx, username)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		return "", nil, ret
+	}
+
+	clearSecondFactor(newSession)
+
+	if err := s.completeLogin(w, r, newSession, username, throttleKeys); err != nil {
+		return "", nil, err
+	}
+
+	return username, recoveryCodes, nil
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func postForm(form url.Values, cookies []*http.Cookie) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "http://stash/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.RemoteAddr = "8.8.8.8:1234"
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestStore_LoginSecondFactor(t *testing.T) {
+	users := &testUsers{password: "password", totpEnabled: true, code: "123456"}
+	s := NewStore(&sessionConfig{maxAttempts: 5}, users)
+
+	login := func() []*http.Cookie {
+		w := httptest.NewRecorder()
+		_, err := s.Login(w, postForm(url.Values{
+			usernameFormKey: {"alice"},
+			passwordFormKey: {"password"},
+		}, nil))
+
+		var requiredErr *SecondFactorRequiredError
+		if assert.True(t, errors.As(err, &requiredErr)) {
+			assert.Equal(t, "alice", requiredErr.Username)
+			assert.Equal(t, users.enrollmentRequired, requiredErr.Enrollment != nil)
+		}
+		return w.Result().Cookies()
+	}
+
+	sessionUserID := func(cookies []*http.Cookie) string {
+		userID, _ := s.GetSessionUserID(httptest.NewRecorder(), postForm(nil, cookies))
+		return userID
+	}
+
+	enterCode := func(cookies []*http.Cookie, code string) (*httptest.ResponseRecorder, []string, error) {
+		w := httptest.NewRecorder()
+		_, recoveryCodes, err := s.LoginSecondFactor(w, postForm(url.Values{
+			codeFormKey: {code},
+		}, cookies))
+		return w, recoveryCodes, err
+	}
+
+	// the password alone does not log in
+	cookies := login()
+	assert.Empty(t, sessionUserID(cookies))
+
+	var invalidErr *InvalidCodeError
+	_, _, err := enterCode(cookies, "000000")
+	assert.True(t, errors.As(err, &invalidErr))
+
+	w, recoveryCodes, err := enterCode(cookies, "123456")
+	if assert.NoError(t, err) {
+		assert.Nil(t, recoveryCodes)
+		assert.Equal(t, "alice", sessionUserID(w.Result().Cookies()))
+	}
+
+	// the pending login is completed once
+	_, _, err = enterCode(w.Result().Cookies(), "123456")
+	assert.ErrorIs(t, err, ErrSecondFactorExpired)
+
+	// a code without a password does not log in
+	_, _, err = enterCode(nil, "123456")
+	assert.ErrorIs(t, err, ErrSecondFactorExpired)
+
+	// users who must enroll confirm their enrollment with their code
+	users.totpEnabled = false
+	users.enrollmentRequired = true
+
+	cookies = login()
+	_, _, err = enterCode(cookies, "000000")
+	if assert.True(t, errors.As(err, &invalidErr)) {
+		assert.NotNil(t, invalidErr.Enrollment)
+	}
+
+	w, recoveryCodes, err = enterCode(cookies, "123456")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"recovery"}, recoveryCodes)
+		assert.Equal(t, "alice", sessionUserID(w.Result().Cookies()))
+	}
+}
+
+func TestStore_LoginSecondFactorThrottle(t *testing.T) {
+	users := &testUsers{password: "password", totpEnabled: true, code: "123456"}
+	s := NewStore(&sessionConfig{maxAttempts: 2}, users)
+
+	w := httptest.NewRecorder()
+	_, err := s.Login(w, postForm(url.Values{
+		usernameFormKey: {"alice"},
+		passwordFormKey: {"password"},
+	}, nil))
+
+	var requiredErr *SecondFactorRequiredError
+	assert.True(t, errors.As(err, &requiredErr))
+
+	var invalidErr *InvalidCodeError
+	var blockedErr *LoginBlockedError
+	for i := 0; i < 2; i++ {
+		_, _, err = s.LoginSecondFactor(httptest.NewRecorder(), postForm(url.Values{codeFormKey: {"000000"}}, w.Result().Cookies()))
+		assert.True(t, errors.As(err, &invalidErr))
+	}
+
+	// failed codes count as failed logins
+	_, _, err = s.LoginSecondFactor(httptest.NewRecorder(), postForm(url.Values{codeFormKey: {"123456"}}, w.Result().Cookies()))
+	assert.True(t, errors.As(err, &blockedErr))
+}
+
+func TestStore_TOTPRequiredSince(t *testing.T) {
+	users := &testUsers{password: "password", totpRequired: map[string]bool{"admin": true}}
+	c := &sessionConfig{maxAttempts: 5}
+	s := NewStore(c, users)
+
+	login := func(username string) []*http.Cookie {
+		w := httptest.NewRecorder()
+		if _, err := s.Login(w, postForm(url.Values{
+			usernameFormKey: {username},
+			passwordFormKey: {"password"},
+		}, nil)); err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		return w.Result().Cookies()
+	}
+
+	sessionUserID := func(cookies []*http.Cookie) string {
+		userID, err := s.GetSessionUserID(httptest.NewRecorder(), postForm(nil, cookies))
+		assert.NoError(t, err)
+		return userID
+	}
+
+	admin := login("admin")
+	editor := login("editor")
+
+	// requiring two-factor authentication logs out the sessions created
+	// before, of the users who must use it
+	c.totpSince = time.Now()
+	assert.Empty(t, sessionUserID(admin))
+	assert.Equal(t, "editor", sessionUserID(editor))
+
+	// sessions created after are not logged out
+	c.totpSince = time.Now().Add(-time.Hour)
+	assert.Equal(t, "admin", sessionUserID(login("admin")))
+}
+
+func TestStore_PluginCookieTOTPRequiredSince(t *testing.T) {
+	users := &testUsers{
+		usernames:    map[int]string{1: "admin"},
+		totpRequired: map[string]bool{"admin": true},
+	}
+	c := &sessionConfig{maxAttempts: 5, totpSince: time.Now().Add(-time.Minute)}
+	s := NewStore(c, users)
+
+	ctx := SetCurrentUser(context.Background(), &models.User{ID: 1, Username: "admin"})
+	cookie := s.MakePluginCookie(ctx)
+	if !assert.NotNil(t, cookie) {
+		return
+	}
+
+	// plugin requests made after two-factor authentication was required are
+	// not logged out
+	userID, err := s.GetSessionUserID(httptest.NewRecorder(), postForm(nil, []*http.Cookie{cookie}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "admin", userID)
+	}
+}
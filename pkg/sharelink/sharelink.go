@@ -0,0 +1,129 @@
+// Package sharelink creates share links and verifies their signed tokens.
+package sharelink
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+var (
+	ErrExpiry = errors.New("share link expiry must be in the future")
+	// ErrCredentialsRequired is returned when creating a link without
+	// credentials, as stash may not be accessed from the internet then.
+	ErrCredentialsRequired = errors.New("share links require credentials to be configured")
+)
+
+type Config interface {
+	HasCredentials() bool
+	// GetJWTSignKey returns the secret the tokens are signed with. Changing
+	// it invalidates all share links.
+	GetJWTSignKey() []byte
+}
+
+type Database interface {
+	Ready() error
+}
+
+// Service creates share links and resolves their tokens. Tokens are the id
+// of the link and a signature of the link, so they are not stored and
+// cannot be guessed.
+type Service struct {
+	TxnManager txn.Manager
+	Repository models.ShareLinkReaderWriter
+	Config     Config
+	Database   Database
+}
+
+// Create creates a link sharing the entity until expiresAt. userID is the id
+// of the user creating the link, or 0 for the owner, and
+// restrictionProfileID the restriction profile of their session, or 0 if
+// there is none. It must be called within a transaction.
+func (s *Service) Create(ctx context.Context, entityType models.ShareLinkEntity, entityID int, userID int, restrictionProfileID int, expiresAt time.Time, allowDownload bool) (*models.ShareLink, error) {
+	if !s.Config.HasCredentials() {
+		return nil, ErrCredentialsRequired
+	}
+
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil, ErrExpiry
+	}
+
+	ret := &models.ShareLink{
+		EntityType:    entityType,
+		EntityID:      entityID,
+		AllowDownload: allowDownload,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}
+
+	if userID != 0 {
+		ret.UserID = &userID
+	}
+
+	if restrictionProfileID != 0 {
+		ret.RestrictionProfileID = &restrictionProfileID
+	}
+
+	if err := s.Repository.Create(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Token returns the token of the link.
+func (s *Service) Token(l *models.ShareLink) string {
+	return strconv.Itoa(l.ID) + "." + s.signature(l)
+}
+
+// signature signs the id, entity and creation time of the link. The creation
+// time is truncated to seconds, as it is stored.
+func (s *Service) signature(l *models.ShareLink) string {
+	mac := hmac.New(sha256.New, s.Config.GetJWTSignKey())
+	fmt.Fprintf(mac, "share_link:%d:%s:%d:%d", l.ID, l.EntityType, l.EntityID, l.CreatedAt.Unix())
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Resolve returns the link of the token. It returns nil if the token is
+// invalid, or the link has been revoked or has expired. It opens its own
+// transaction.
+func (s *Service) Resolve(ctx context.Context, token string) (*models.ShareLink, error) {
+	idStr, sig, found := strings.Cut(token, ".")
+	if !found || s.Database.Ready() != nil {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return nil, nil
+	}
+
+	var ret *models.ShareLink
+	if err := txn.WithReadTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		var err error
+		ret, err = s.Repository.Find(ctx, id)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("finding share link: %w", err)
+	}
+
+	if ret == nil || ret.Expired(time.Now()) {
+		return nil, nil
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(s.signature(ret))) {
+		return nil, nil
+	}
+
+	return ret, nil
+}
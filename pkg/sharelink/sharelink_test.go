@@ -0,0 +1,129 @@
+package sharelink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type config struct {
+	key           string
+	noCredentials bool
+}
+
+func (c config) HasCredentials() bool {
+	return !c.noCredentials
+}
+
+func (c config) GetJWTSignKey() []byte {
+	return []byte(c.key)
+}
+
+type database struct{}
+
+func (d database) Ready() error {
+	return nil
+}
+
+func newTestService() (*Service, *mocks.ShareLinkReaderWriter) {
+	repo := mocks.NewTxnRepository()
+	shareLinkReaderWriter := repo.ShareLink.(*mocks.ShareLinkReaderWriter)
+
+	return &Service{
+		TxnManager: repo,
+		Repository: shareLinkReaderWriter,
+		Config:     config{key: "secret"},
+		Database:   database{},
+	}, shareLinkReaderWriter
+}
+
+func TestService_Create(t *testing.T) {
+	s, r := newTestService()
+	ctx := context.Background()
+
+	r.On("Create", mock.Anything, mock.AnythingOfType("*models.ShareLink")).Return(nil).Once()
+
+	expiresAt := time.Now().Add(time.Hour)
+	got, err := s.Create(ctx, models.ShareLinkEntityScene, 1, 2, 3, expiresAt, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.ShareLinkEntityScene, got.EntityType)
+		assert.Equal(t, 1, got.EntityID)
+		assert.Equal(t, 2, *got.UserID)
+		assert.Equal(t, 3, *got.RestrictionProfileID)
+		assert.True(t, got.AllowDownload)
+		assert.False(t, got.CreatedAt.IsZero())
+	}
+
+	_, err = s.Create(ctx, models.ShareLinkEntityScene, 1, 0, 0, time.Now().Add(-time.Hour), false)
+	assert.ErrorIs(t, err, ErrExpiry)
+
+	s.Config = config{key: "secret", noCredentials: true}
+	_, err = s.Create(ctx, models.ShareLinkEntityScene, 1, 0, 0, expiresAt, false)
+	assert.ErrorIs(t, err, ErrCredentialsRequired)
+
+	r.AssertExpectations(t)
+}
+
+func TestService_Resolve(t *testing.T) {
+	s, r := newTestService()
+	ctx := context.Background()
+
+	createdAt := time.Now().Add(-time.Hour)
+	valid := &models.ShareLink{
+		ID:         1,
+		EntityType: models.ShareLinkEntityGallery,
+		EntityID:   3,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		CreatedAt:  createdAt,
+	}
+	expired := &models.ShareLink{
+		ID:         2,
+		EntityType: models.ShareLinkEntityScene,
+		EntityID:   3,
+		ExpiresAt:  time.Now().Add(-time.Minute),
+		CreatedAt:  createdAt,
+	}
+
+	r.On("Find", mock.Anything, 1).Return(valid, nil)
+	r.On("Find", mock.Anything, 2).Return(expired, nil)
+	r.On("Find", mock.Anything, 3).Return(nil, nil)
+
+	// the link as stored, without the sub-second part of the creation time
+	stored := *valid
+	stored.CreatedAt = createdAt.Truncate(time.Second)
+	token := s.Token(&stored)
+
+	other := *valid
+	other.EntityID = 4
+
+	otherKey, _ := newTestService()
+	otherKey.Config = config{key: "other"}
+
+	tests := []struct {
+		name  string
+		token string
+		want  *models.ShareLink
+	}{
+		{"valid", token, valid},
+		{"expired", s.Token(expired), nil},
+		{"revoked", "3." + s.signature(&stored), nil},
+		{"other entity", s.Token(&other), nil},
+		{"other key", otherKey.Token(valid), nil},
+		{"no signature", "1", nil},
+		{"invalid id", "x." + s.signature(&stored), nil},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Resolve(ctx, tt.token)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -47,6 +47,14 @@ func (db *Anonymiser) Anonymise(ctx context.Context) error {
 		return utils.Do([]func() error{
 			func() error { return db.deleteBlobs() },
 			func() error { return db.deleteStashIDs() },
+			func() error { return db.truncateTable(editTable) },
+			func() error { return db.truncateTable(scenesUsersTable) },
+			func() error { return db.truncateTable(apiKeyTable) },
+			func() error { return db.truncateTable(userTable) },
+			func() error { return db.truncateTable(restrictionProfileTable) },
+			func() error { return db.truncateTable(auditEventTable) },
+			func() error { return db.truncateTable(shareLinkTable) },
+			func() error { return db.dropFullTextSearch() },
 			func() error { return db.anonymiseFolders(ctx) },
 			func() error { return db.anonymiseFiles(ctx) },
 			func() error { return db.anonymiseFingerprints(ctx) },
@@ -88,6 +96,9 @@ func (db *Anonymiser) deleteBlobs() error {
 		func() error { return db.truncateColumn("scenes", "cover_blob") },
 		func() error { return db.truncateColumn("movies", "front_image_blob") },
 		func() error { return db.truncateColumn("movies", "back_image_blob") },
+		func() error { return db.truncateTable("performer_image_blobs") },
+		func() error { return db.truncateTable("studio_image_blobs") },
+		func() error { return db.truncateTable("tag_image_blobs") },
 
 		func() error { return db.truncateTable("blobs") },
 	})
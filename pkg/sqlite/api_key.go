@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	apiKeyTable = "api_keys"
+)
+
+type apiKeyRow struct {
+	ID     int    `db:"id" goqu:"skipinsert"`
+	UserID int    `db:"user_id"`
+	Name   string `db:"name"`
+	// hex encoded SHA-256 hash of the key
+	KeyHash string `db:"key_hash"`
+	// comma separated
+	Scopes               string        `db:"scopes"`
+	RestrictionProfileID null.Int      `db:"restriction_profile_id"`
+	ExpiresAt            NullTimestamp `db:"expires_at"`
+	LastUsedAt           NullTimestamp `db:"last_used_at"`
+	CreatedAt            Timestamp     `db:"created_at"`
+}
+
+func (r *apiKeyRow) fromAPIKey(o models.APIKey) {
+	scopes := make([]string, len(o.Scopes))
+	for i, s := range o.Scopes {
+		scopes[i] = s.String()
+	}
+
+	r.ID = o.ID
+	r.UserID = o.UserID
+	r.Name = o.Name
+	r.KeyHash = o.KeyHash
+	r.Scopes = strings.Join(scopes, ",")
+	r.RestrictionProfileID = intFromPtr(o.RestrictionProfileID)
+	r.ExpiresAt = NullTimestampFromTimePtr(o.ExpiresAt)
+	r.LastUsedAt = NullTimestampFromTimePtr(o.LastUsedAt)
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+}
+
+func (r *apiKeyRow) resolve() *models.APIKey {
+	var scopes []models.APIKeyScope
+	for _, s := range strings.Split(r.Scopes, ",") {
+		if s != "" {
+			scopes = append(scopes, models.APIKeyScope(s))
+		}
+	}
+
+	return &models.APIKey{
+		ID:         r.ID,
+		UserID:     r.UserID,
+		Name:       r.Name,
+		KeyHash:    r.KeyHash,
+		Scopes:     scopes,
+		ExpiresAt:  r.ExpiresAt.TimePtr(),
+		LastUsedAt: r.LastUsedAt.TimePtr(),
+		CreatedAt:  r.CreatedAt.Timestamp,
+
+		RestrictionProfileID: nullIntPtr(r.RestrictionProfileID),
+	}
+}
+
+type APIKeyStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewAPIKeyStore() *APIKeyStore {
+	return &APIKeyStore{
+		repository: repository{
+			tableName: apiKeyTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: apiKeyTableMgr,
+	}
+}
+
+func (qb *APIKeyStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *APIKeyStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *APIKeyStore) Create(ctx context.Context, newObject *models.APIKey) error {
+	if err := newObject.Validate(); err != nil {
+		return err
+	}
+
+	var r apiKeyRow
+	r.fromAPIKey(*newObject)
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	updated, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding after create: %w", err)
+	}
+
+	*newObject = *updated
+
+	return nil
+}
+
+func (qb *APIKeyStore) UpdateLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error {
+	return qb.tableMgr.updateByID(ctx, id, goqu.Record{
+		"last_used_at": Timestamp{Timestamp: lastUsedAt},
+	})
+}
+
+func (qb *APIKeyStore) Destroy(ctx context.Context, id int) error {
+	return qb.destroyExisting(ctx, []int{id})
+}
+
+// returns nil, nil if not found
+func (qb *APIKeyStore) Find(ctx context.Context, id int) (*models.APIKey, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *APIKeyStore) find(ctx context.Context, id int) (*models.APIKey, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	return qb.get(ctx, q)
+}
+
+// returns nil, nil if not found
+func (qb *APIKeyStore) FindByKeyHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	q := qb.selectDataset().Where(qb.table().Col("key_hash").Eq(keyHash))
+
+	ret, err := qb.get(ctx, q)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+func (qb *APIKeyStore) FindByUserID(ctx context.Context, userID int) ([]*models.APIKey, error) {
+	table := qb.table()
+	return qb.getMany(ctx, qb.selectDataset().Where(
+		table.Col("user_id").Eq(userID),
+	).Order(
+		table.Col("name").Asc(),
+		table.Col(idColumn).Asc(),
+	))
+}
+
+func (qb *APIKeyStore) All(ctx context.Context) ([]*models.APIKey, error) {
+	table := qb.table()
+	return qb.getMany(ctx, qb.selectDataset().Order(
+		table.Col("user_id").Asc(),
+		table.Col("name").Asc(),
+		table.Col(idColumn).Asc(),
+	))
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *APIKeyStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.APIKey, error) {
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *APIKeyStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.APIKey, error) {
+	const single = false
+	var ret []*models.APIKey
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f apiKeyRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		ret = append(ret, f.resolve())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,125 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyCreateDestroy(t *testing.T) {
+	runWithRollbackTxn(t, "create destroy", func(t *testing.T, ctx context.Context) {
+		now := time.Now()
+		u := &models.User{
+			Username:  "backup",
+			Role:      models.UserRoleViewer,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := db.User.Create(ctx, u); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		expiresAt := now.Add(time.Hour).Truncate(time.Second)
+		k := &models.APIKey{
+			UserID:    u.ID,
+			Name:      "backup script",
+			KeyHash:   "hash",
+			Scopes:    []models.APIKeyScope{models.APIKeyScopeRead, models.APIKeyScopeStream},
+			ExpiresAt: &expiresAt,
+			CreatedAt: now,
+		}
+		if err := db.APIKey.Create(ctx, k); err != nil {
+			t.Errorf("APIKeyStore.Create() error = %v", err)
+			return
+		}
+
+		assert.ErrorIs(t, db.APIKey.Create(ctx, &models.APIKey{
+			UserID:  u.ID,
+			Name:    "no scopes",
+			KeyHash: "other",
+		}), models.ErrAPIKeyScopesRequired)
+
+		found, err := db.APIKey.FindByKeyHash(ctx, "hash")
+		if err != nil {
+			t.Errorf("APIKeyStore.FindByKeyHash() error = %v", err)
+			return
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, k.ID, found.ID)
+			assert.Equal(t, k.Scopes, found.Scopes)
+			assert.True(t, expiresAt.Equal(*found.ExpiresAt))
+			assert.Nil(t, found.LastUsedAt)
+		}
+
+		if err := db.APIKey.UpdateLastUsed(ctx, k.ID, now); err != nil {
+			t.Errorf("APIKeyStore.UpdateLastUsed() error = %v", err)
+			return
+		}
+
+		keys, err := db.APIKey.FindByUserID(ctx, u.ID)
+		if assert.NoError(t, err) && assert.Len(t, keys, 1) {
+			assert.NotNil(t, keys[0].LastUsedAt)
+		}
+
+		// destroying the user destroys their keys
+		if err := db.User.Destroy(ctx, u.ID); err != nil {
+			t.Errorf("UserStore.Destroy() error = %v", err)
+			return
+		}
+
+		found, err = db.APIKey.FindByKeyHash(ctx, "hash")
+		if assert.NoError(t, err) {
+			assert.Nil(t, found)
+		}
+	})
+}
+
+func TestAPIKeyRestrictionProfile(t *testing.T) {
+	runWithRollbackTxn(t, "restriction profile", func(t *testing.T, ctx context.Context) {
+		now := time.Now()
+		u := &models.User{
+			Username:  "player",
+			Role:      models.UserRoleViewer,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := db.User.Create(ctx, u); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		p := &models.RestrictionProfile{Name: "kids", TagIDs: []int{tagIDs[tagIdxWithScene]}}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		k := &models.APIKey{
+			UserID:               u.ID,
+			Name:                 "external player",
+			KeyHash:              "restricted",
+			Scopes:               []models.APIKeyScope{models.APIKeyScopeStream},
+			RestrictionProfileID: &p.ID,
+			CreatedAt:            now,
+		}
+		if err := db.APIKey.Create(ctx, k); err != nil {
+			t.Errorf("APIKeyStore.Create() error = %v", err)
+			return
+		}
+
+		found, err := db.APIKey.FindByKeyHash(ctx, "restricted")
+		if assert.NoError(t, err) && assert.NotNil(t, found) && assert.NotNil(t, found.RestrictionProfileID) {
+			assert.Equal(t, p.ID, *found.RestrictionProfileID)
+		}
+
+		// the profile cannot be deleted while the key uses it
+		err = db.RestrictionProfile.Destroy(ctx, p.ID)
+		assert.ErrorIs(t, err, models.ErrRestrictionProfileInUse)
+	})
+}
@@ -0,0 +1,269 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	auditEventTable = "audit_events"
+)
+
+type auditEventRow struct {
+	ID         int         `db:"id" goqu:"skipinsert"`
+	Action     string      `db:"action"`
+	Username   string      `db:"username"`
+	APIKeyName null.String `db:"api_key_name"`
+	IPAddress  string      `db:"ip_address"`
+	Operation  string      `db:"operation"`
+	// comma separated
+	TargetIDs string `db:"target_ids"`
+	// JSON encoded
+	Arguments null.String `db:"arguments"`
+	Error     null.String `db:"error"`
+	CreatedAt Timestamp   `db:"created_at"`
+}
+
+func (r *auditEventRow) fromAuditEvent(o models.AuditEvent) error {
+	r.ID = o.ID
+	r.Action = o.Action.String()
+	r.Username = o.Username
+	r.APIKeyName = null.StringFromPtr(o.APIKeyName)
+	r.IPAddress = o.IPAddress
+	r.Operation = o.Operation
+	r.TargetIDs = strings.Join(o.TargetIDs, ",")
+	r.Error = null.StringFromPtr(o.Error)
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+
+	if len(o.Arguments) > 0 {
+		arguments, err := json.Marshal(o.Arguments)
+		if err != nil {
+			return fmt.Errorf("marshalling arguments: %w", err)
+		}
+		r.Arguments = null.StringFrom(string(arguments))
+	}
+
+	return nil
+}
+
+func (r *auditEventRow) resolve() (*models.AuditEvent, error) {
+	ret := &models.AuditEvent{
+		ID:         r.ID,
+		Action:     models.AuditAction(r.Action),
+		Username:   r.Username,
+		APIKeyName: r.APIKeyName.Ptr(),
+		IPAddress:  r.IPAddress,
+		Operation:  r.Operation,
+		Error:      r.Error.Ptr(),
+		CreatedAt:  r.CreatedAt.Timestamp,
+	}
+
+	for _, id := range strings.Split(r.TargetIDs, ",") {
+		if id != "" {
+			ret.TargetIDs = append(ret.TargetIDs, id)
+		}
+	}
+
+	if r.Arguments.Valid {
+		if err := json.Unmarshal([]byte(r.Arguments.String), &ret.Arguments); err != nil {
+			return nil, fmt.Errorf("unmarshalling arguments of audit event %d: %w", r.ID, err)
+		}
+	}
+
+	return ret, nil
+}
+
+type AuditEventStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewAuditEventStore() *AuditEventStore {
+	return &AuditEventStore{
+		repository: repository{
+			tableName: auditEventTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: auditEventTableMgr,
+	}
+}
+
+func (qb *AuditEventStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *AuditEventStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *AuditEventStore) Create(ctx context.Context, newObject *models.AuditEvent) error {
+	var r auditEventRow
+	if err := r.fromAuditEvent(*newObject); err != nil {
+		return err
+	}
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	newObject.ID = id
+
+	return nil
+}
+
+func (qb *AuditEventStore) DestroyBefore(ctx context.Context, t time.Time) (int64, error) {
+	q := dialect.Delete(qb.table()).Where(qb.table().Col("created_at").Lt(Timestamp{Timestamp: t}))
+
+	ret, err := exec(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("destroying audit events: %w", err)
+	}
+
+	return ret.RowsAffected()
+}
+
+func (qb *AuditEventStore) findMany(ctx context.Context, ids []int) ([]*models.AuditEvent, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	events, err := qb.getMany(ctx, qb.selectDataset().Where(qb.table().Col(idColumn).In(ids)))
+	if err != nil {
+		return nil, err
+	}
+
+	// return the events in the order of the ids
+	byID := make(map[int]*models.AuditEvent, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+
+	ret := make([]*models.AuditEvent, 0, len(ids))
+	for _, id := range ids {
+		if e := byID[id]; e != nil {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret, nil
+}
+
+func (qb *AuditEventStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.AuditEvent, error) {
+	const single = false
+	var ret []*models.AuditEvent
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f auditEventRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		e, err := f.resolve()
+		if err != nil {
+			return err
+		}
+
+		ret = append(ret, e)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (qb *AuditEventStore) makeFilter(ctx context.Context, auditEventFilter *models.AuditEventFilterType) *filterBuilder {
+	query := &filterBuilder{}
+
+	query.handleCriterion(ctx, auditEventActionsCriterionHandler(auditEventFilter.Actions))
+	query.handleCriterion(ctx, stringCriterionHandler(auditEventFilter.Username, "audit_events.username"))
+	query.handleCriterion(ctx, stringCriterionHandler(auditEventFilter.APIKeyName, "audit_events.api_key_name"))
+	query.handleCriterion(ctx, stringCriterionHandler(auditEventFilter.IPAddress, "audit_events.ip_address"))
+	query.handleCriterion(ctx, stringCriterionHandler(auditEventFilter.Operation, "audit_events.operation"))
+	query.handleCriterion(ctx, auditEventTargetIDCriterionHandler(auditEventFilter.TargetID))
+	query.handleCriterion(ctx, timestampCriterionHandler(auditEventFilter.CreatedAt, "audit_events.created_at"))
+
+	return query
+}
+
+func (qb *AuditEventStore) Query(ctx context.Context, auditEventFilter *models.AuditEventFilterType, findFilter *models.FindFilterType) ([]*models.AuditEvent, int, error) {
+	if findFilter == nil {
+		findFilter = &models.FindFilterType{}
+	}
+	if auditEventFilter == nil {
+		auditEventFilter = &models.AuditEventFilterType{}
+	}
+
+	query := qb.newQuery()
+	distinctIDs(&query, auditEventTable)
+
+	if q := findFilter.Q; q != nil && *q != "" {
+		searchColumns := []string{"audit_events.username", "audit_events.operation", "audit_events.ip_address"}
+		query.parseQueryString(searchColumns, *q)
+	}
+
+	if err := query.addFilter(qb.makeFilter(ctx, auditEventFilter)); err != nil {
+		return nil, 0, err
+	}
+
+	query.sortAndPagination = qb.getAuditEventSort(findFilter) + getPagination(findFilter)
+
+	idsResult, countResult, err := query.executeFind(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events, err := qb.findMany(ctx, idsResult)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, countResult, nil
+}
+
+func auditEventActionsCriterionHandler(actions []models.AuditAction) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if len(actions) == 0 {
+			return
+		}
+
+		args := make([]interface{}, len(actions))
+		for i, a := range actions {
+			args[i] = a.String()
+		}
+
+		f.addWhere("audit_events.action IN "+getInBinding(len(actions)), args...)
+	}
+}
+
+func auditEventTargetIDCriterionHandler(targetID *string) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if targetID == nil || *targetID == "" {
+			return
+		}
+
+		f.addWhere("(',' || audit_events.target_ids || ',') LIKE ?", "%,"+*targetID+",%")
+	}
+}
+
+// getAuditEventSort sorts events newest first by default.
+func (qb *AuditEventStore) getAuditEventSort(findFilter *models.FindFilterType) string {
+	sort := findFilter.GetSort("created_at")
+	direction := "DESC"
+	if findFilter.Direction != nil {
+		direction = findFilter.GetDirection()
+	}
+
+	return getSort(sort, direction, auditEventTable) + ", audit_events.id " + getSortDirection(direction)
+}
@@ -0,0 +1,123 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEventCreateQuery(t *testing.T) {
+	runWithRollbackTxn(t, "create query", func(t *testing.T, ctx context.Context) {
+		qb := db.AuditEvent
+
+		now := time.Now().Truncate(time.Second)
+		keyName := "backup script"
+		errMsg := "forbidden"
+
+		events := []*models.AuditEvent{
+			{
+				Action:    models.AuditActionLogin,
+				Username:  "alice",
+				IPAddress: "192.168.1.2",
+				Operation: "password",
+				CreatedAt: now.Add(-2 * time.Hour),
+			},
+			{
+				Action:     models.AuditActionMutation,
+				Username:   "alice",
+				APIKeyName: &keyName,
+				IPAddress:  "192.168.1.2",
+				Operation:  "sceneUpdate",
+				TargetIDs:  []string{"12", "3"},
+				Arguments: map[string]interface{}{
+					"input": map[string]interface{}{"id": "12", "title": "new title"},
+				},
+				CreatedAt: now.Add(-time.Hour),
+			},
+			{
+				Action:    models.AuditActionMutation,
+				Username:  "bob",
+				IPAddress: "192.168.1.3",
+				Operation: "sceneDestroy",
+				TargetIDs: []string{"123"},
+				Error:     &errMsg,
+				CreatedAt: now,
+			},
+		}
+
+		for _, e := range events {
+			if err := qb.Create(ctx, e); err != nil {
+				t.Errorf("AuditEventStore.Create() error = %v", err)
+				return
+			}
+		}
+
+		query := func(filter *models.AuditEventFilterType) []*models.AuditEvent {
+			t.Helper()
+			ret, count, err := qb.Query(ctx, filter, nil)
+			if err != nil {
+				t.Errorf("AuditEventStore.Query() error = %v", err)
+				return nil
+			}
+			assert.Len(t, ret, count)
+			return ret
+		}
+
+		// newest first
+		got := query(nil)
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, events[2].ID, got[0].ID)
+			assert.Equal(t, events[0].ID, got[2].ID)
+
+			assert.Equal(t, &keyName, got[1].APIKeyName)
+			assert.Equal(t, []string{"12", "3"}, got[1].TargetIDs)
+			assert.Equal(t, events[1].Arguments, got[1].Arguments)
+			assert.Equal(t, &errMsg, got[0].Error)
+			assert.Nil(t, got[0].Arguments)
+		}
+
+		got = query(&models.AuditEventFilterType{
+			Actions: []models.AuditAction{models.AuditActionMutation},
+			Username: &models.StringCriterionInput{
+				Value:    "alice",
+				Modifier: models.CriterionModifierEquals,
+			},
+		})
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, events[1].ID, got[0].ID)
+		}
+
+		// target ids match whole ids
+		targetID := "12"
+		got = query(&models.AuditEventFilterType{TargetID: &targetID})
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, events[1].ID, got[0].ID)
+		}
+
+		got = query(&models.AuditEventFilterType{
+			CreatedAt: &models.TimestampCriterionInput{
+				Value:    now.Add(-90 * time.Minute).Format(time.RFC3339),
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+		})
+		assert.Len(t, got, 2)
+
+		n, err := qb.DestroyBefore(ctx, now.Add(-30*time.Minute))
+		if err != nil {
+			t.Errorf("AuditEventStore.DestroyBefore() error = %v", err)
+			return
+		}
+		assert.Equal(t, int64(2), n)
+
+		got = query(nil)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, events[2].ID, got[0].ID)
+		}
+	})
+}
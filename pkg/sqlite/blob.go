@@ -14,6 +14,7 @@ import (
 	"github.com/stashapp/stash/pkg/file"
 	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sqlite/blob"
 	"github.com/stashapp/stash/pkg/utils"
 	"gopkg.in/guregu/null.v4"
@@ -412,3 +413,232 @@ func (qb *blobJoinQueryBuilder) HasImage(ctx context.Context, id int, blobCol st
 
 	return c == 1, nil
 }
+
+const imageCollectionBlobColumn = "image_blob"
+
+// imageCollectionQueryBuilder manages a collection of images for an object.
+// The primary image of the collection is stored in the blob column of the
+// object table, so that it can be read by the blobJoinQueryBuilder methods.
+type imageCollectionQueryBuilder struct {
+	blobJoinQueryBuilder
+
+	blobCol         string
+	collectionTable string
+	fkColumn        string
+}
+
+func (qb *imageCollectionQueryBuilder) formatSQL(format string) string {
+	return utils.StrFormat(format, utils.StrFormatMap{
+		"joinTable":       qb.joinTable,
+		"joinCol":         qb.blobCol,
+		"collectionTable": qb.collectionTable,
+		"fkCol":           qb.fkColumn,
+		"blobCol":         imageCollectionBlobColumn,
+	})
+}
+
+func (qb *imageCollectionQueryBuilder) getImages(ctx context.Context, id int) ([]models.EntityImage, error) {
+	sqlQuery := qb.formatSQL(`
+SELECT {collectionTable}.id, {collectionTable}.{blobCol}, {collectionTable}.{blobCol} IS {joinTable}.{joinCol} AS is_primary
+FROM {collectionTable} INNER JOIN {joinTable} ON {joinTable}.id = {collectionTable}.{fkCol}
+WHERE {collectionTable}.{fkCol} = ?
+ORDER BY is_primary DESC, {collectionTable}.id
+`)
+
+	var ret []models.EntityImage
+	const single = false
+	if err := qb.queryFunc(ctx, sqlQuery, []interface{}{id}, single, func(rows *sqlx.Rows) error {
+		var image models.EntityImage
+		if err := rows.StructScan(&image); err != nil {
+			return err
+		}
+
+		ret = append(ret, image)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("getting images from %s: %w", qb.collectionTable, err)
+	}
+
+	return ret, nil
+}
+
+func (qb *imageCollectionQueryBuilder) getImageByID(ctx context.Context, id int, imageID int) ([]byte, error) {
+	sqlQuery := qb.formatSQL(`
+SELECT blobs.checksum, blobs.blob FROM {collectionTable} INNER JOIN blobs ON {collectionTable}.{blobCol} = blobs.checksum
+WHERE {collectionTable}.id = ? AND {collectionTable}.{fkCol} = ?
+`)
+
+	ret, _, err := qb.blobStore.readSQL(ctx, sqlQuery, imageID, id)
+	return ret, err
+}
+
+// getImageChecksum returns the checksum of the image with the given id.
+// Returns an error if the image does not belong to the object.
+func (qb *imageCollectionQueryBuilder) getImageChecksum(ctx context.Context, id int, imageID int) (string, error) {
+	sqlQuery := qb.formatSQL(`SELECT {blobCol} FROM {collectionTable} WHERE id = ? AND {fkCol} = ?`)
+
+	var checksum null.String
+	if err := qb.repository.querySimple(ctx, sqlQuery, []interface{}{imageID, id}, &checksum); err != nil {
+		return "", err
+	}
+
+	if !checksum.Valid {
+		return "", fmt.Errorf("image %d not found for %s %d", imageID, qb.joinTable, id)
+	}
+
+	return checksum.String, nil
+}
+
+// addChecksum adds the checksum to the collection if not already present,
+// returning the id of the collection entry.
+func (qb *imageCollectionQueryBuilder) addChecksum(ctx context.Context, id int, checksum string) (int, error) {
+	insertSQL := qb.formatSQL(`INSERT OR IGNORE INTO {collectionTable} ({fkCol}, {blobCol}) VALUES (?, ?)`)
+	if _, err := qb.tx.Exec(ctx, insertSQL, id, checksum); err != nil {
+		return 0, err
+	}
+
+	var imageID int
+	sqlQuery := qb.formatSQL(`SELECT id FROM {collectionTable} WHERE {fkCol} = ? AND {blobCol} = ?`)
+	if err := qb.repository.querySimple(ctx, sqlQuery, []interface{}{id, checksum}, &imageID); err != nil {
+		return 0, err
+	}
+
+	return imageID, nil
+}
+
+// removeChecksum removes the checksum from the collection and deletes the
+// blob if it is no longer referenced. If the checksum was the primary image,
+// then the next image in the collection becomes the primary image.
+func (qb *imageCollectionQueryBuilder) removeChecksum(ctx context.Context, id int, checksum string) error {
+	deleteSQL := qb.formatSQL(`DELETE FROM {collectionTable} WHERE {fkCol} = ? AND {blobCol} = ?`)
+	if _, err := qb.tx.Exec(ctx, deleteSQL, id, checksum); err != nil {
+		return err
+	}
+
+	updateSQL := qb.formatSQL(`UPDATE {joinTable} SET {joinCol} = (
+SELECT {blobCol} FROM {collectionTable} WHERE {fkCol} = {joinTable}.id ORDER BY id LIMIT 1
+) WHERE id = ? AND {joinCol} = ?`)
+	if _, err := qb.tx.Exec(ctx, updateSQL, id, checksum); err != nil {
+		return err
+	}
+
+	return qb.blobStore.Delete(ctx, checksum)
+}
+
+func (qb *imageCollectionQueryBuilder) setPrimaryChecksum(ctx context.Context, id int, checksum string) error {
+	updateSQL := qb.formatSQL(`UPDATE {joinTable} SET {joinCol} = ? WHERE id = ?`)
+	_, err := qb.tx.Exec(ctx, updateSQL, checksum, id)
+	return err
+}
+
+// addImage adds the image to the collection, returning the id of the new
+// image. The image is set as the primary image if primary is true or if
+// the object does not have a primary image.
+func (qb *imageCollectionQueryBuilder) addImage(ctx context.Context, id int, image []byte, primary bool) (int, error) {
+	checksum, err := qb.blobStore.Write(ctx, image)
+	if err != nil {
+		return 0, err
+	}
+
+	imageID, err := qb.addChecksum(ctx, id, checksum)
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := qb.getChecksum(ctx, id, qb.blobCol)
+	if err != nil {
+		return 0, err
+	}
+
+	if primary || current == nil {
+		if err := qb.setPrimaryChecksum(ctx, id, checksum); err != nil {
+			return 0, err
+		}
+	}
+
+	return imageID, nil
+}
+
+func (qb *imageCollectionQueryBuilder) setPrimaryImage(ctx context.Context, id int, imageID int) error {
+	checksum, err := qb.getImageChecksum(ctx, id, imageID)
+	if err != nil {
+		return err
+	}
+
+	return qb.setPrimaryChecksum(ctx, id, checksum)
+}
+
+func (qb *imageCollectionQueryBuilder) destroyImageByID(ctx context.Context, id int, imageID int) error {
+	checksum, err := qb.getImageChecksum(ctx, id, imageID)
+	if err != nil {
+		return err
+	}
+
+	return qb.removeChecksum(ctx, id, checksum)
+}
+
+// updatePrimaryImage replaces the primary image with the provided image.
+// If image is empty, then the primary image is removed.
+func (qb *imageCollectionQueryBuilder) updatePrimaryImage(ctx context.Context, id int, image []byte) error {
+	oldChecksum, err := qb.getChecksum(ctx, id, qb.blobCol)
+	if err != nil {
+		return err
+	}
+
+	if len(image) == 0 {
+		if oldChecksum == nil {
+			return nil
+		}
+
+		return qb.removeChecksum(ctx, id, *oldChecksum)
+	}
+
+	if _, err := qb.addImage(ctx, id, image, true); err != nil {
+		return err
+	}
+
+	// remove the replaced image
+	checksum := md5.FromBytes(image)
+	if oldChecksum != nil && *oldChecksum != checksum {
+		return qb.removeChecksum(ctx, id, *oldChecksum)
+	}
+
+	return nil
+}
+
+// destroyImages removes all images of the object.
+func (qb *imageCollectionQueryBuilder) destroyImages(ctx context.Context, id int) error {
+	images, err := qb.getImages(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// the primary image should always be in the collection, but handle
+	// the case where it is not
+	if err := qb.DestroyImage(ctx, id, qb.blobCol); err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if err := qb.removeChecksum(ctx, id, image.Checksum); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeImages moves the images of the source objects to the destination
+// object. If the destination has no primary image, then the first of the
+// merged images becomes the primary image.
+func (qb *imageCollectionQueryBuilder) mergeImages(ctx context.Context, source []int, destination int) error {
+	if err := mergeJoins(ctx, qb.collectionTable, qb.fkColumn, []string{imageCollectionBlobColumn}, nil, source, destination); err != nil {
+		return err
+	}
+
+	updateSQL := qb.formatSQL(`UPDATE {joinTable} SET {joinCol} = (
+SELECT {blobCol} FROM {collectionTable} WHERE {fkCol} = {joinTable}.id ORDER BY id LIMIT 1
+) WHERE id = ? AND {joinCol} IS NULL`)
+	_, err := qb.tx.Exec(ctx, updateSQL, destination)
+	return err
+}
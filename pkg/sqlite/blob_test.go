@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,3 +44,95 @@ func testUpdateImage(t *testing.T, ctx context.Context, id int, updateFn updateI
 
 	return nil
 }
+
+type imageCollectionStore interface {
+	GetImage(ctx context.Context, id int) ([]byte, error)
+	UpdateImage(ctx context.Context, id int, image []byte) error
+	GetImages(ctx context.Context, id int) ([]models.EntityImage, error)
+	GetImageByID(ctx context.Context, id int, imageID int) ([]byte, error)
+	AddImage(ctx context.Context, id int, image []byte, primary bool) (int, error)
+	SetPrimaryImage(ctx context.Context, id int, imageID int) error
+	DestroyImageByID(ctx context.Context, id int, imageID int) error
+}
+
+func testImageCollection(t *testing.T, ctx context.Context, id int, qb imageCollectionStore) error {
+	first := []byte("first")
+	second := []byte("second")
+
+	// the first image added becomes the primary image
+	firstID, err := qb.AddImage(ctx, id, first, false)
+	if err != nil {
+		return fmt.Errorf("error adding image: %w", err)
+	}
+
+	secondID, err := qb.AddImage(ctx, id, second, false)
+	if err != nil {
+		return fmt.Errorf("error adding image: %w", err)
+	}
+
+	images, err := qb.GetImages(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting images: %w", err)
+	}
+	if assert.Len(t, images, 2) {
+		assert.Equal(t, firstID, images[0].ID)
+		assert.True(t, images[0].Primary)
+		assert.Equal(t, secondID, images[1].ID)
+		assert.False(t, images[1].Primary)
+	}
+
+	storedImage, err := qb.GetImageByID(ctx, id, secondID)
+	if err != nil {
+		return fmt.Errorf("error getting image by id: %w", err)
+	}
+	assert.Equal(t, second, storedImage)
+
+	if err := qb.SetPrimaryImage(ctx, id, secondID); err != nil {
+		return fmt.Errorf("error setting primary image: %w", err)
+	}
+
+	storedImage, err = qb.GetImage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting image: %w", err)
+	}
+	assert.Equal(t, second, storedImage)
+
+	// destroying the primary image promotes the remaining image
+	if err := qb.DestroyImageByID(ctx, id, secondID); err != nil {
+		return fmt.Errorf("error destroying image: %w", err)
+	}
+
+	images, err = qb.GetImages(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting images: %w", err)
+	}
+	if assert.Len(t, images, 1) {
+		assert.Equal(t, firstID, images[0].ID)
+		assert.True(t, images[0].Primary)
+	}
+
+	storedImage, err = qb.GetImage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting image: %w", err)
+	}
+	assert.Equal(t, first, storedImage)
+
+	// updating the image replaces the primary image
+	if err := qb.UpdateImage(ctx, id, second); err != nil {
+		return fmt.Errorf("error updating image: %w", err)
+	}
+
+	images, err = qb.GetImages(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting images: %w", err)
+	}
+	assert.Len(t, images, 1)
+
+	storedImage, err = qb.GetImage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error getting image: %w", err)
+	}
+	assert.Equal(t, second, storedImage)
+
+	return nil
+}
@@ -2,7 +2,9 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/jmoiron/sqlx"
@@ -73,3 +75,70 @@ func (qb *oCounterManager) ResetOCounter(ctx context.Context, id int) (int, erro
 
 	return qb.getOCounter(ctx, id)
 }
+
+// mergeJoins reassigns the rows of a join table from the source ids to the
+// destination id. keyColumns are the columns that, together with idColumn,
+// uniquely identify a row. Rows which would duplicate an existing destination
+// row are dropped.
+func mergeJoins(ctx context.Context, table string, idColumn string, keyColumns []string, copyColumns []string, source []int, destination int) error {
+	inBinding := getInBinding(len(source))
+
+	args := []interface{}{destination}
+	srcArgs := make([]interface{}, len(source))
+	for i, id := range source {
+		srcArgs[i] = id
+	}
+	args = append(args, srcArgs...)
+	args = append(args, destination)
+
+	columns := append(append([]string{}, keyColumns...), copyColumns...)
+	columnList := strings.Join(columns, ", ")
+
+	var matches []string
+	for _, c := range keyColumns {
+		matches = append(matches, "o."+c+" = "+table+"."+c)
+	}
+
+	var tx dbWrapper
+	if _, err := tx.Exec(ctx, `INSERT OR IGNORE INTO `+table+` (`+idColumn+`, `+columnList+`)
+SELECT ?, `+columnList+` FROM `+table+`
+WHERE `+idColumn+` IN `+inBinding+`
+AND NOT EXISTS(SELECT 1 FROM `+table+` o WHERE o.`+idColumn+` = ? AND `+strings.Join(matches, " AND ")+`)
+GROUP BY `+strings.Join(keyColumns, ", "),
+		args...,
+	); err != nil {
+		return fmt.Errorf("merging %s: %w", table, err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE `+idColumn+` IN `+inBinding, srcArgs...); err != nil {
+		return fmt.Errorf("merging %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// mergeForeignKey points the foreign key column of a table at the destination
+// id where it currently references any of the source ids.
+func mergeForeignKey(ctx context.Context, table string, column string, source []int, destination int) error {
+	args := []interface{}{destination}
+	for _, id := range source {
+		args = append(args, id)
+	}
+
+	var tx dbWrapper
+	if _, err := tx.Exec(ctx, "UPDATE "+table+" SET "+column+" = ? WHERE "+column+" IN "+getInBinding(len(source)), args...); err != nil {
+		return fmt.Errorf("merging %s: %w", table, err)
+	}
+
+	return nil
+}
+
+func validateMergeIDs(source []int, destination int) error {
+	for _, id := range source {
+		if id == destination {
+			return errors.New("cannot merge where source == destination")
+		}
+	}
+
+	return nil
+}
@@ -32,7 +32,7 @@ const (
 	dbConnTimeout = 30
 )
 
-var appSchemaVersion uint = 46
+var appSchemaVersion uint = 66
 
 //go:embed migrations/*.sql
 var migrationsBox embed.FS
@@ -64,19 +64,28 @@ func (e *MismatchedSchemaVersionError) Error() string {
 }
 
 type Database struct {
-	Blobs          *BlobStore
-	File           *FileStore
-	Folder         *FolderStore
-	Image          *ImageStore
-	Gallery        *GalleryStore
-	GalleryChapter *GalleryChapterStore
-	Scene          *SceneStore
-	SceneMarker    *SceneMarkerStore
-	Performer      *PerformerStore
-	Studio         *StudioStore
-	Tag            *TagStore
-	Movie          *MovieStore
-	SavedFilter    *SavedFilterStore
+	Blobs              *BlobStore
+	File               *FileStore
+	Folder             *FolderStore
+	Image              *ImageStore
+	Gallery            *GalleryStore
+	GalleryChapter     *GalleryChapterStore
+	Scene              *SceneStore
+	SceneMarker        *SceneMarkerStore
+	Performer          *PerformerStore
+	Studio             *StudioStore
+	Tag                *TagStore
+	Movie              *MovieStore
+	SavedFilter        *SavedFilterStore
+	Playlist           *PlaylistStore
+	TagImplication     *TagImplicationStore
+	User               *UserStore
+	APIKey             *APIKeyStore
+	RestrictionProfile *RestrictionProfileStore
+	AuditEvent         *AuditEventStore
+	ShareLink          *ShareLinkStore
+	Edit               *EditStore
+	Statistics         *StatisticsStore
 
 	db     *sqlx.DB
 	dbPath string
@@ -92,20 +101,29 @@ func NewDatabase() *Database {
 	blobStore := NewBlobStore(BlobStoreOptions{})
 
 	ret := &Database{
-		Blobs:          blobStore,
-		File:           fileStore,
-		Folder:         folderStore,
-		Scene:          NewSceneStore(fileStore, blobStore),
-		SceneMarker:    NewSceneMarkerStore(),
-		Image:          NewImageStore(fileStore),
-		Gallery:        NewGalleryStore(fileStore, folderStore),
-		GalleryChapter: NewGalleryChapterStore(),
-		Performer:      NewPerformerStore(blobStore),
-		Studio:         NewStudioStore(blobStore),
-		Tag:            NewTagStore(blobStore),
-		Movie:          NewMovieStore(blobStore),
-		SavedFilter:    NewSavedFilterStore(),
-		lockChan:       make(chan struct{}, 1),
+		Blobs:              blobStore,
+		File:               fileStore,
+		Folder:             folderStore,
+		Scene:              NewSceneStore(fileStore, blobStore),
+		SceneMarker:        NewSceneMarkerStore(),
+		Image:              NewImageStore(fileStore),
+		Gallery:            NewGalleryStore(fileStore, folderStore),
+		GalleryChapter:     NewGalleryChapterStore(),
+		Performer:          NewPerformerStore(blobStore),
+		Studio:             NewStudioStore(blobStore),
+		Tag:                NewTagStore(blobStore),
+		Movie:              NewMovieStore(blobStore),
+		SavedFilter:        NewSavedFilterStore(),
+		Playlist:           NewPlaylistStore(),
+		TagImplication:     NewTagImplicationStore(),
+		User:               NewUserStore(),
+		APIKey:             NewAPIKeyStore(),
+		RestrictionProfile: NewRestrictionProfileStore(),
+		AuditEvent:         NewAuditEventStore(),
+		ShareLink:          NewShareLinkStore(),
+		Edit:               NewEditStore(),
+		Statistics:         NewStatisticsStore(),
+		lockChan:           make(chan struct{}, 1),
 	}
 
 	return ret
@@ -170,6 +188,10 @@ func (db *Database) Open(dbPath string) error {
 		if err != nil {
 			return err
 		}
+
+		if err := db.initFullTextSearch(); err != nil {
+			return fmt.Errorf("initializing full-text search: %w", err)
+		}
 	}
 
 	return nil
@@ -401,6 +423,14 @@ func (db *Database) RunMigrations() error {
 	if stepNumber != 0 {
 		logger.Infof("Migrating database from version %d to %d", databaseSchemaVersion, appSchemaVersion)
 
+		// full-text search triggers reference tables that migrations may
+		// replace, so drop them until the migrations are complete
+		if databaseSchemaVersion != 0 {
+			if err := db.dropFullTextTriggers(); err != nil {
+				return fmt.Errorf("dropping full-text search triggers: %w", err)
+			}
+		}
+
 		// run each migration individually, and run custom migrations as needed
 		var i uint = 1
 		for ; i <= stepNumber; i++ {
@@ -434,6 +464,10 @@ func (db *Database) RunMigrations() error {
 		return fmt.Errorf("re-initializing the database: %w", err)
 	}
 
+	if err := db.initFullTextSearch(); err != nil {
+		return fmt.Errorf("initializing full-text search: %w", err)
+	}
+
 	// optimize database after migration
 	db.optimise()
 
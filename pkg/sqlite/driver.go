@@ -40,10 +40,15 @@ func (d *CustomSQLiteDriver) Open(dsn string) (driver.Conn, error) {
 
 			// COLLATE NATURAL_CI - Case insensitive natural sort
 			err := conn.RegisterCollation("NATURAL_CI", func(s string, s2 string) int {
-				if casefolded.NaturalLess(s, s2) {
+				// equal values must compare as equal for comparisons
+				// using the collation, such as those paging with cursors
+				switch {
+				case casefolded.NaturalLess(s, s2):
 					return -1
-				} else {
+				case casefolded.NaturalLess(s2, s):
 					return 1
+				default:
+					return 0
 				}
 			})
 
@@ -0,0 +1,630 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stashapp/stash/pkg/hash/md5"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+const (
+	editTable = "edits"
+
+	editEntityTypeColumn = "entity_type"
+	editEntityIDColumn   = "entity_id"
+)
+
+var ErrEditNotRevertable = errors.New("edit cannot be reverted")
+
+type editRow struct {
+	ID         int           `db:"id" goqu:"skipinsert"`
+	EntityType string        `db:"entity_type"`
+	EntityID   int           `db:"entity_id"`
+	Operation  string        `db:"operation"`
+	Source     string        `db:"source"`
+	Changes    string        `db:"changes"`
+	CreatedAt  Timestamp     `db:"created_at"`
+	RevertedAt NullTimestamp `db:"reverted_at"`
+}
+
+func (r *editRow) resolve() (*models.Edit, error) {
+	ret := &models.Edit{
+		ID:         r.ID,
+		EntityType: models.EditEntityType(r.EntityType),
+		EntityID:   r.EntityID,
+		Operation:  models.EditOperation(r.Operation),
+		Source:     models.EditSource(r.Source),
+		CreatedAt:  r.CreatedAt.Timestamp,
+		RevertedAt: r.RevertedAt.TimePtr(),
+	}
+
+	if err := json.Unmarshal([]byte(r.Changes), &ret.Changes); err != nil {
+		return nil, fmt.Errorf("unmarshalling changes of edit %d: %w", r.ID, err)
+	}
+
+	return ret, nil
+}
+
+// editJoin describes a relationship recorded in the revision history. The
+// value of a join with a single column is the list of column values. The
+// value of a join with multiple columns is a list of column value maps.
+type editJoin struct {
+	field    string
+	table    string
+	fkColumn string
+	columns  []string
+}
+
+func (j editJoin) get(ctx context.Context, id int) ([]interface{}, error) {
+	t := goqu.T(j.table)
+
+	var cols []interface{}
+	var order []exp.OrderedExpression
+	for _, c := range j.columns {
+		cols = append(cols, t.Col(c))
+		order = append(order, t.Col(c).Asc())
+	}
+
+	q := dialect.From(t).Select(cols...).Where(t.Col(j.fkColumn).Eq(id)).Order(order...)
+
+	ret := []interface{}{}
+	const single = false
+	if err := queryFunc(ctx, q, single, func(rows *sqlx.Rows) error {
+		if len(j.columns) == 1 {
+			var v interface{}
+			if err := rows.Scan(&v); err != nil {
+				return err
+			}
+			ret = append(ret, editValue(v))
+			return nil
+		}
+
+		m := make(map[string]interface{})
+		if err := rows.MapScan(m); err != nil {
+			return err
+		}
+		for k, v := range m {
+			m[k] = editValue(v)
+		}
+		ret = append(ret, m)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("getting %s: %w", j.field, err)
+	}
+
+	return ret, nil
+}
+
+func (j editJoin) replace(ctx context.Context, id int, v interface{}) error {
+	t := goqu.T(j.table)
+
+	if _, err := exec(ctx, dialect.Delete(t).Where(t.Col(j.fkColumn).Eq(id))); err != nil {
+		return fmt.Errorf("reverting %s: %w", j.field, err)
+	}
+
+	values, _ := v.([]interface{})
+	if len(values) == 0 {
+		return nil
+	}
+
+	var rows []interface{}
+	for _, vv := range values {
+		r := goqu.Record{j.fkColumn: id}
+		if m, ok := vv.(map[string]interface{}); ok {
+			for _, c := range j.columns {
+				r[c] = m[c]
+			}
+		} else {
+			r[j.columns[0]] = vv
+		}
+		rows = append(rows, r)
+	}
+
+	if _, err := exec(ctx, dialect.Insert(t).Prepared(true).Rows(rows...)); err != nil {
+		return fmt.Errorf("reverting %s: %w", j.field, err)
+	}
+
+	return nil
+}
+
+// editTracker describes the fields of an entity that are recorded in the
+// revision history.
+type editTracker struct {
+	entityType models.EditEntityType
+	tableMgr   *table
+	// columns of the entity table. Date columns are recorded in the
+	// sqlite date format.
+	columns []string
+	joins   []editJoin
+	// derive sets columns that are derived from reverted columns
+	derive func(r goqu.Record)
+}
+
+type editValues map[string]interface{}
+
+func editValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case []byte:
+		return string(vv)
+	case time.Time:
+		// only date columns are tracked
+		return vv.Format(sqliteDateLayout)
+	}
+
+	return v
+}
+
+// snapshot returns the current values of the tracked fields of the entity.
+// Returns nil if the entity does not exist.
+func (t *editTracker) snapshot(ctx context.Context, id int) (editValues, error) {
+	table := t.tableMgr.table
+
+	var cols []interface{}
+	for _, c := range t.columns {
+		cols = append(cols, table.Col(c))
+	}
+
+	q := dialect.From(table).Select(cols...).Where(t.tableMgr.byID(id))
+
+	var ret editValues
+	const single = true
+	if err := queryFunc(ctx, q, single, func(rows *sqlx.Rows) error {
+		ret = make(editValues)
+		return rows.MapScan(ret)
+	}); err != nil {
+		return nil, fmt.Errorf("getting %s %d: %w", t.entityType, id, err)
+	}
+
+	if ret == nil {
+		return nil, nil
+	}
+
+	for k, v := range ret {
+		ret[k] = editValue(v)
+	}
+
+	for _, j := range t.joins {
+		v, err := j.get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ret[j.field] = v
+	}
+
+	return ret, nil
+}
+
+func isEmptyEditValue(v interface{}) bool {
+	switch vv := v.(type) {
+	case nil:
+		return true
+	case string:
+		return vv == ""
+	case bool:
+		return !vv
+	case []interface{}:
+		return len(vv) == 0
+	}
+
+	return false
+}
+
+// diff returns the changes between the before and after values. before is
+// nil for newly created entities, in which case only non-empty values are
+// returned.
+func (t *editTracker) diff(before, after editValues) []models.EditChange {
+	fields := append([]string{}, t.columns...)
+	for _, j := range t.joins {
+		fields = append(fields, j.field)
+	}
+
+	var ret []models.EditChange
+	for _, f := range fields {
+		newValue := after[f]
+		if before == nil {
+			if !isEmptyEditValue(newValue) {
+				ret = append(ret, models.EditChange{
+					Field:    f,
+					NewValue: newValue,
+				})
+			}
+			continue
+		}
+
+		oldValue := before[f]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			ret = append(ret, models.EditChange{
+				Field:    f,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+
+	return ret
+}
+
+// apply sets the tracked fields of the entity to the provided values.
+func (t *editTracker) apply(ctx context.Context, id int, values editValues) error {
+	r := goqu.Record{
+		"updated_at": Timestamp{Timestamp: time.Now()},
+	}
+	for _, c := range t.columns {
+		if v, ok := values[c]; ok {
+			r[c] = v
+		}
+	}
+
+	if t.derive != nil {
+		t.derive(r)
+	}
+
+	if err := t.tableMgr.updateByID(ctx, id, r); err != nil {
+		return err
+	}
+
+	for _, j := range t.joins {
+		if v, ok := values[j.field]; ok {
+			if err := j.replace(ctx, id, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// track records the current values of the entity, so that changes made to
+// it in the current transaction are recorded when the transaction is
+// committed. Does nothing if the transaction has no edit source.
+func (t *editTracker) track(ctx context.Context, id int) error {
+	s := getEditSession(ctx)
+	if s == nil || s.tracking(t, id) {
+		return nil
+	}
+
+	before, err := t.snapshot(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.add(ctx, &pendingEdit{
+		tracker:   t,
+		id:        id,
+		operation: models.EditOperationFromContext(ctx),
+		before:    before,
+	})
+
+	return nil
+}
+
+// trackCreate records the creation of the entity in the current
+// transaction. Does nothing if the transaction has no edit source.
+func (t *editTracker) trackCreate(ctx context.Context, id int) {
+	s := getEditSession(ctx)
+	if s == nil {
+		return
+	}
+
+	s.add(ctx, &pendingEdit{
+		tracker:   t,
+		id:        id,
+		operation: models.EditOperationCreate,
+	})
+}
+
+var (
+	sceneEditTracker = &editTracker{
+		entityType: models.EditEntityTypeScene,
+		tableMgr:   sceneTableMgr,
+		columns:    []string{"title", "code", "details", "director", "url", "date", "rating", "organized", studioIDColumn},
+		joins: []editJoin{
+			{field: "tag_ids", table: scenesTagsTable, fkColumn: sceneIDColumn, columns: []string{tagIDColumn}},
+			{field: "performer_ids", table: performersScenesTable, fkColumn: sceneIDColumn, columns: []string{performerIDColumn}},
+			{field: "gallery_ids", table: scenesGalleriesTable, fkColumn: sceneIDColumn, columns: []string{galleryIDColumn}},
+			{field: "movies", table: moviesScenesTable, fkColumn: sceneIDColumn, columns: []string{movieIDColumn, "scene_index"}},
+			{field: "stash_ids", table: "scene_stash_ids", fkColumn: sceneIDColumn, columns: []string{"endpoint", "stash_id"}},
+		},
+	}
+
+	imageEditTracker = &editTracker{
+		entityType: models.EditEntityTypeImage,
+		tableMgr:   imageTableMgr,
+		columns:    []string{"title", "url", "date", "rating", "organized", studioIDColumn},
+		joins: []editJoin{
+			{field: "tag_ids", table: imagesTagsTable, fkColumn: imageIDColumn, columns: []string{tagIDColumn}},
+			{field: "performer_ids", table: performersImagesTable, fkColumn: imageIDColumn, columns: []string{performerIDColumn}},
+			{field: "gallery_ids", table: galleriesImagesTable, fkColumn: imageIDColumn, columns: []string{galleryIDColumn, "position"}},
+		},
+	}
+
+	galleryEditTracker = &editTracker{
+		entityType: models.EditEntityTypeGallery,
+		tableMgr:   galleryTableMgr,
+		columns:    []string{"title", "url", "date", "details", "rating", "organized", studioIDColumn},
+		joins: []editJoin{
+			{field: "tag_ids", table: galleriesTagsTable, fkColumn: galleryIDColumn, columns: []string{tagIDColumn}},
+			{field: "performer_ids", table: performersGalleriesTable, fkColumn: galleryIDColumn, columns: []string{performerIDColumn}},
+			{field: "scene_ids", table: galleriesScenesTable, fkColumn: galleryIDColumn, columns: []string{sceneIDColumn}},
+		},
+	}
+
+	performerEditTracker = &editTracker{
+		entityType: models.EditEntityTypePerformer,
+		tableMgr:   performerTableMgr,
+		columns: []string{
+			"name", "disambiguation", "gender", "url", "twitter", "instagram", "birthdate", "ethnicity", "country",
+			"eye_color", "height", "measurements", "fake_tits", "penis_length", "circumcised", "career_length",
+			"tattoos", "piercings", "favorite", "details", "death_date", "hair_color", "weight", "rating", "ignore_auto_tag",
+		},
+		joins: []editJoin{
+			{field: "alias_list", table: performersAliasesTable, fkColumn: performerIDColumn, columns: []string{performerAliasColumn}},
+			{field: "tag_ids", table: performersTagsTable, fkColumn: performerIDColumn, columns: []string{tagIDColumn}},
+			{field: "stash_ids", table: "performer_stash_ids", fkColumn: performerIDColumn, columns: []string{"endpoint", "stash_id"}},
+		},
+	}
+
+	studioEditTracker = &editTracker{
+		entityType: models.EditEntityTypeStudio,
+		tableMgr:   studioTableMgr,
+		columns:    []string{"name", "url", "parent_id", "details", "rating", "ignore_auto_tag"},
+		joins: []editJoin{
+			{field: "aliases", table: studioAliasesTable, fkColumn: studioIDColumn, columns: []string{"alias"}},
+			{field: "stash_ids", table: "studio_stash_ids", fkColumn: studioIDColumn, columns: []string{"endpoint", "stash_id"}},
+		},
+		derive: deriveNameChecksum,
+	}
+
+	tagEditTracker = &editTracker{
+		entityType: models.EditEntityTypeTag,
+		tableMgr:   tagTableMgr,
+		columns:    []string{"name", "description", "ignore_auto_tag"},
+		joins: []editJoin{
+			{field: "aliases", table: tagAliasesTable, fkColumn: tagIDColumn, columns: []string{"alias"}},
+			{field: "parent_ids", table: "tags_relations", fkColumn: "child_id", columns: []string{"parent_id"}},
+			{field: "child_ids", table: "tags_relations", fkColumn: "parent_id", columns: []string{"child_id"}},
+		},
+	}
+
+	movieEditTracker = &editTracker{
+		entityType: models.EditEntityTypeMovie,
+		tableMgr:   movieTableMgr,
+		columns:    []string{"name", "aliases", "duration", "date", "rating", studioIDColumn, "director", "synopsis", "url"},
+		derive:     deriveNameChecksum,
+	}
+
+	editTrackers = map[models.EditEntityType]*editTracker{
+		models.EditEntityTypeScene:     sceneEditTracker,
+		models.EditEntityTypeImage:     imageEditTracker,
+		models.EditEntityTypeGallery:   galleryEditTracker,
+		models.EditEntityTypePerformer: performerEditTracker,
+		models.EditEntityTypeStudio:    studioEditTracker,
+		models.EditEntityTypeTag:       tagEditTracker,
+		models.EditEntityTypeMovie:     movieEditTracker,
+	}
+)
+
+// deriveNameChecksum sets the checksum of studios and movies, which is
+// generated from the name.
+func deriveNameChecksum(r goqu.Record) {
+	if name, ok := r["name"].(string); ok {
+		r["checksum"] = md5.FromString(name)
+	}
+}
+
+type pendingEdit struct {
+	tracker   *editTracker
+	id        int
+	operation models.EditOperation
+	before    editValues
+}
+
+// editSession holds the entities changed in a transaction with an edit
+// source. The changes are written to the revision history before the
+// transaction is committed.
+type editSession struct {
+	source  models.EditSource
+	pending []*pendingEdit
+}
+
+func getEditSession(ctx context.Context) *editSession {
+	s, _ := ctx.Value(editSessionKey).(*editSession)
+	return s
+}
+
+func (s *editSession) tracking(t *editTracker, id int) bool {
+	for _, p := range s.pending {
+		if p.tracker == t && p.id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *editSession) add(ctx context.Context, p *pendingEdit) {
+	if len(s.pending) == 0 {
+		txn.AddPreCommitHook(ctx, s.flush)
+	}
+
+	s.pending = append(s.pending, p)
+}
+
+func (s *editSession) flush(ctx context.Context) error {
+	now := time.Now()
+
+	for _, p := range s.pending {
+		after, err := p.tracker.snapshot(ctx, p.id)
+		if err != nil {
+			return err
+		}
+
+		// entity was destroyed
+		if after == nil {
+			continue
+		}
+
+		changes := p.tracker.diff(p.before, after)
+		if len(changes) == 0 {
+			continue
+		}
+
+		changesJSON, err := json.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("marshalling changes: %w", err)
+		}
+
+		r := editRow{
+			EntityType: p.tracker.entityType.String(),
+			EntityID:   p.id,
+			Operation:  p.operation.String(),
+			Source:     s.source.String(),
+			Changes:    string(changesJSON),
+			CreatedAt:  Timestamp{Timestamp: now},
+		}
+
+		if _, err := editTableMgr.insertID(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	s.pending = nil
+
+	return nil
+}
+
+type EditStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewEditStore() *EditStore {
+	return &EditStore{
+		repository: repository{
+			tableName: editTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: editTableMgr,
+	}
+}
+
+func (qb *EditStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *EditStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+// returns nil, nil if not found
+func (qb *EditStore) Find(ctx context.Context, id int) (*models.Edit, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// FindByEntity returns the edits of the provided entity, newest first.
+func (qb *EditStore) FindByEntity(ctx context.Context, entityType models.EditEntityType, entityID int) ([]*models.Edit, error) {
+	table := qb.table()
+	q := qb.selectDataset().Where(
+		table.Col(editEntityTypeColumn).Eq(entityType.String()),
+		table.Col(editEntityIDColumn).Eq(entityID),
+	).Order(table.Col(idColumn).Desc())
+
+	return qb.getMany(ctx, q)
+}
+
+// Revert restores the fields changed by the edit to their values before the
+// edit. The edit is marked as reverted. Edits that created an entity, and
+// edits that have already been reverted, cannot be reverted.
+func (qb *EditStore) Revert(ctx context.Context, id int) error {
+	e, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding edit %d: %w", id, err)
+	}
+
+	if e.RevertedAt != nil || e.Operation == models.EditOperationCreate {
+		return ErrEditNotRevertable
+	}
+
+	tracker := editTrackers[e.EntityType]
+	if tracker == nil {
+		return fmt.Errorf("%w: unsupported entity type %s", ErrEditNotRevertable, e.EntityType)
+	}
+
+	if err := tracker.tableMgr.checkIDExists(ctx, e.EntityID); err != nil {
+		return err
+	}
+
+	if err := tracker.track(ctx, e.EntityID); err != nil {
+		return err
+	}
+
+	values := make(editValues)
+	for _, c := range e.Changes {
+		values[c.Field] = c.OldValue
+	}
+
+	if err := tracker.apply(ctx, e.EntityID, values); err != nil {
+		return err
+	}
+
+	r := goqu.Record{
+		"reverted_at": Timestamp{Timestamp: time.Now()},
+	}
+
+	return qb.tableMgr.updateByID(ctx, id, r)
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *EditStore) find(ctx context.Context, id int) (*models.Edit, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *EditStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Edit, error) {
+	const single = false
+	var ret []*models.Edit
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f editRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		e, err := f.resolve()
+		if err != nil {
+			return err
+		}
+
+		ret = append(ret, e)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,154 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sqlite"
+	"github.com/stashapp/stash/pkg/txn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditStore_History(t *testing.T) {
+	ctx := models.WithEditSource(context.Background(), models.EditSourceUI)
+
+	const (
+		name    = "TestEditStore_History"
+		newName = "TestEditStore_History updated"
+		details = "details"
+	)
+	birthdate := models.NewDate("2003-02-01")
+
+	performer := models.Performer{
+		Name:      name,
+		Birthdate: &birthdate,
+		TagIDs:    models.NewRelatedIDs([]int{tagIDs[tagIdx1WithPerformer]}),
+	}
+
+	if err := txn.WithTxn(ctx, db, func(ctx context.Context) error {
+		return db.Performer.Create(ctx, &performer)
+	}); err != nil {
+		t.Errorf("creating performer: %v", err)
+		return
+	}
+
+	defer func() {
+		if err := withTxn(func(ctx context.Context) error {
+			return db.Performer.Destroy(ctx, performer.ID)
+		}); err != nil {
+			t.Errorf("destroying performer: %v", err)
+		}
+	}()
+
+	// update performer
+	if err := txn.WithTxn(ctx, db, func(ctx context.Context) error {
+		partial := models.NewPerformerPartial()
+		partial.Name = models.NewOptionalString(newName)
+		partial.Details = models.NewOptionalString(details)
+		partial.Birthdate = models.NewOptionalDate(models.NewDate("2004-03-02"))
+		partial.TagIDs = &models.UpdateIDs{
+			IDs:  []int{tagIDs[tagIdx2WithPerformer]},
+			Mode: models.RelationshipUpdateModeAdd,
+		}
+		_, err := db.Performer.UpdatePartial(ctx, performer.ID, partial)
+		return err
+	}); err != nil {
+		t.Errorf("updating performer: %v", err)
+		return
+	}
+
+	// updates without an edit source are not recorded
+	if err := withTxn(func(ctx context.Context) error {
+		partial := models.NewPerformerPartial()
+		partial.Details = models.NewOptionalString(details)
+		_, err := db.Performer.UpdatePartial(ctx, performer.ID, partial)
+		return err
+	}); err != nil {
+		t.Errorf("updating performer: %v", err)
+		return
+	}
+
+	var history []*models.Edit
+	if err := withTxn(func(ctx context.Context) error {
+		var err error
+		history, err = db.Edit.FindByEntity(ctx, models.EditEntityTypePerformer, performer.ID)
+		return err
+	}); err != nil {
+		t.Errorf("finding edit history: %v", err)
+		return
+	}
+
+	if !assert.Len(t, history, 2) {
+		return
+	}
+
+	update := history[0]
+	assert.Equal(t, models.EditOperationUpdate, update.Operation)
+	assert.Equal(t, models.EditSourceUI, update.Source)
+	assert.Equal(t, []models.EditChange{
+		{Field: "name", OldValue: name, NewValue: newName},
+		{Field: "birthdate", OldValue: "2003-02-01", NewValue: "2004-03-02"},
+		{Field: "details", OldValue: nil, NewValue: details},
+		{
+			Field:    "tag_ids",
+			OldValue: []interface{}{float64(tagIDs[tagIdx1WithPerformer])},
+			NewValue: []interface{}{float64(tagIDs[tagIdx1WithPerformer]), float64(tagIDs[tagIdx2WithPerformer])},
+		},
+	}, update.Changes)
+
+	create := history[1]
+	assert.Equal(t, models.EditOperationCreate, create.Operation)
+	assert.Equal(t, []models.EditChange{
+		{Field: "name", NewValue: name},
+		{Field: "birthdate", NewValue: "2003-02-01"},
+		{Field: "tag_ids", NewValue: []interface{}{float64(tagIDs[tagIdx1WithPerformer])}},
+	}, create.Changes)
+
+	// revert the update
+	revertCtx := models.WithEditOperation(ctx, models.EditOperationRevert)
+	if err := txn.WithTxn(revertCtx, db, func(ctx context.Context) error {
+		return db.Edit.Revert(ctx, update.ID)
+	}); err != nil {
+		t.Errorf("reverting edit: %v", err)
+		return
+	}
+
+	if err := withTxn(func(ctx context.Context) error {
+		reverted, err := db.Performer.Find(ctx, performer.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := reverted.LoadTagIDs(ctx, db.Performer); err != nil {
+			return err
+		}
+
+		assert.Equal(t, name, reverted.Name)
+		assert.Equal(t, "", reverted.Details)
+		assert.Equal(t, &birthdate, reverted.Birthdate)
+		assert.Equal(t, []int{tagIDs[tagIdx1WithPerformer]}, reverted.TagIDs.List())
+
+		history, err = db.Edit.FindByEntity(ctx, models.EditEntityTypePerformer, performer.ID)
+		return err
+	}); err != nil {
+		t.Errorf("finding reverted performer: %v", err)
+		return
+	}
+
+	if assert.Len(t, history, 3) {
+		assert.Equal(t, models.EditOperationRevert, history[0].Operation)
+		assert.NotNil(t, history[1].RevertedAt)
+	}
+
+	// edits cannot be reverted twice, and creation cannot be reverted
+	for _, id := range []int{update.ID, create.ID} {
+		err := txn.WithTxn(revertCtx, db, func(ctx context.Context) error {
+			return db.Edit.Revert(ctx, id)
+		})
+		assert.ErrorIs(t, err, sqlite.ErrEditNotRevertable)
+	}
+}
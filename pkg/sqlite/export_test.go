@@ -0,0 +1,4 @@
+package sqlite
+
+// FullTextSearchEnabled exposes fullTextSearchEnabled to tests.
+const FullTextSearchEnabled = fullTextSearchEnabled
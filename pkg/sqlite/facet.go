@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// facetDefinition defines how the values of a facet are aggregated. The
+// joins and expressions refer to the ids of the filtered objects as temp.id.
+type facetDefinition struct {
+	joins string
+	// value is grouped on. Rows with a null value are ignored.
+	value string
+	label string
+}
+
+type facetDefinitions map[models.FacetType]facetDefinition
+
+// relatedFacet returns the definition of a facet over the objects related
+// through joinTable.
+func relatedFacet(joinTable, fkColumn, relatedTable, relatedFK, labelColumn string) facetDefinition {
+	return facetDefinition{
+		joins: fmt.Sprintf("INNER JOIN %[1]s ON %[1]s.%[2]s = temp.id INNER JOIN %[3]s ON %[3]s.id = %[1]s.%[4]s", joinTable, fkColumn, relatedTable, relatedFK),
+		value: relatedTable + ".id",
+		label: relatedTable + "." + labelColumn,
+	}
+}
+
+// columnFacet returns the definition of a facet over the values of a column
+// of table. Empty values are ignored.
+func columnFacet(table, column string) facetDefinition {
+	value := fmt.Sprintf("NULLIF(%s, '')", getColumn(table, column))
+	return facetDefinition{
+		joins: fmt.Sprintf("INNER JOIN %[1]s ON %[1]s.id = temp.id", table),
+		value: value,
+		label: value,
+	}
+}
+
+// yearFacet returns the definition of a facet over the year of a date
+// column of table.
+func yearFacet(table, column string) facetDefinition {
+	year := fmt.Sprintf("strftime('%%Y', %s)", getColumn(table, column))
+	return facetDefinition{
+		joins: fmt.Sprintf("INNER JOIN %[1]s ON %[1]s.id = temp.id", table),
+		value: year,
+		label: year,
+	}
+}
+
+// studioFacet returns the definition of a facet over the studio_id column
+// of table.
+func studioFacet(table string) facetDefinition {
+	return facetDefinition{
+		joins: fmt.Sprintf("INNER JOIN %[1]s ON %[1]s.id = temp.id INNER JOIN studios ON studios.id = %[1]s.studio_id", table),
+		value: "studios.id",
+		label: "studios.name",
+	}
+}
+
+// resolutionExpression returns an expression bucketing the given dimensions
+// into resolutions, using the same ranges as the resolution criterion.
+func resolutionExpression(heightColumn, widthColumn string) string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	// check the highest resolutions first
+	for i := len(models.AllResolutionEnum) - 1; i >= 0; i-- {
+		r := models.AllResolutionEnum[i]
+		fmt.Fprintf(&sb, " WHEN MIN(%s, %s) >= %d THEN '%s'", heightColumn, widthColumn, r.GetMinResolution(), r)
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+// queryFacets aggregates the values of the requested facets over the
+// objects returned by query.
+func (r *repository) queryFacets(ctx context.Context, query queryBuilder, defs facetDefinitions, options models.FacetOptions) ([]*models.Facet, error) {
+	const includeSortPagination = false
+	filtered := query.toSQL(includeSortPagination)
+
+	var ret []*models.Facet
+	for _, t := range options.Facets {
+		def, ok := defs[t]
+		if !ok {
+			return nil, fmt.Errorf("facet %s is not supported for %s", t, r.tableName)
+		}
+
+		sql := fmt.Sprintf("SELECT %s AS value, COALESCE(%s, '') AS label, COUNT(DISTINCT temp.id) AS count FROM (%s) AS temp %s WHERE %s IS NOT NULL GROUP BY 1 ORDER BY count DESC, label ASC",
+			def.value, def.label, filtered, def.joins, def.value)
+
+		args := query.args
+		if options.Limit > 0 {
+			sql += " LIMIT ?"
+			args = append(args[:len(args):len(args)], options.Limit)
+		}
+
+		facet := &models.Facet{
+			Type:   t,
+			Values: []*models.FacetValue{},
+		}
+		if err := r.queryFunc(ctx, sql, args, false, func(rows *sqlx.Rows) error {
+			var v models.FacetValue
+			if err := rows.Scan(&v.Value, &v.Label, &v.Count); err != nil {
+				return err
+			}
+			facet.Values = append(facet.Values, &v)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("querying %s facet: %w", t, err)
+		}
+
+		ret = append(ret, facet)
+	}
+
+	return ret, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stashapp/stash/pkg/file"
 	"github.com/stashapp/stash/pkg/models"
 	"gopkg.in/guregu/null.v4"
+	"gopkg.in/guregu/null.v4/zero"
 )
 
 const (
@@ -22,6 +23,9 @@ const (
 	imageFileTable = "image_files"
 	fileIDColumn   = "file_id"
 
+	imageFileKeywordsTable = "image_file_keywords"
+	imageFileKeywordColumn = "keyword"
+
 	videoCaptionsTable    = "video_captions"
 	captionCodeColumn     = "language_code"
 	captionFilenameColumn = "filename"
@@ -79,17 +83,37 @@ func (f *videoFileRow) fromVideoFile(ff file.VideoFile) {
 }
 
 type imageFileRow struct {
-	FileID file.ID `db:"file_id"`
-	Format string  `db:"format"`
-	Width  int     `db:"width"`
-	Height int     `db:"height"`
+	FileID      file.ID       `db:"file_id"`
+	Format      string        `db:"format"`
+	Width       int           `db:"width"`
+	Height      int           `db:"height"`
+	Orientation int           `db:"orientation"`
+	DateTaken   NullTimestamp `db:"date_taken"`
+	CameraMake  zero.String   `db:"camera_make"`
+	CameraModel zero.String   `db:"camera_model"`
+	Lens        zero.String   `db:"lens"`
+	Latitude    null.Float    `db:"latitude"`
+	Longitude   null.Float    `db:"longitude"`
+	Altitude    null.Float    `db:"altitude"`
+	Title       zero.String   `db:"title"`
 }
 
 func (f *imageFileRow) fromImageFile(ff file.ImageFile) {
+	m := ff.Metadata
+
 	f.FileID = ff.ID
 	f.Format = ff.Format
 	f.Width = ff.Width
 	f.Height = ff.Height
+	f.Orientation = m.Orientation
+	f.DateTaken = NullTimestampFromTimePtr(m.DateTaken)
+	f.CameraMake = zero.StringFrom(m.CameraMake)
+	f.CameraModel = zero.StringFrom(m.CameraModel)
+	f.Lens = zero.StringFrom(m.Lens)
+	f.Latitude = null.FloatFromPtr(m.Latitude)
+	f.Longitude = null.FloatFromPtr(m.Longitude)
+	f.Altitude = null.FloatFromPtr(m.Altitude)
+	f.Title = zero.StringFrom(m.Title)
 }
 
 // we redefine this to change the columns around
@@ -143,9 +167,18 @@ func videoFileQueryColumns() []interface{} {
 // we redefine this to change the columns around
 // otherwise, we collide with the video file columns
 type imageFileQueryRow struct {
-	Format null.String `db:"image_format"`
-	Width  null.Int    `db:"image_width"`
-	Height null.Int    `db:"image_height"`
+	Format      null.String   `db:"image_format"`
+	Width       null.Int      `db:"image_width"`
+	Height      null.Int      `db:"image_height"`
+	Orientation null.Int      `db:"image_orientation"`
+	DateTaken   NullTimestamp `db:"image_date_taken"`
+	CameraMake  null.String   `db:"image_camera_make"`
+	CameraModel null.String   `db:"image_camera_model"`
+	Lens        null.String   `db:"image_lens"`
+	Latitude    null.Float    `db:"image_latitude"`
+	Longitude   null.Float    `db:"image_longitude"`
+	Altitude    null.Float    `db:"image_altitude"`
+	Title       null.String   `db:"image_title"`
 }
 
 func (imageFileQueryRow) columns(table *table) []interface{} {
@@ -154,6 +187,15 @@ func (imageFileQueryRow) columns(table *table) []interface{} {
 		ex.Col("format").As("image_format"),
 		ex.Col("width").As("image_width"),
 		ex.Col("height").As("image_height"),
+		ex.Col("orientation").As("image_orientation"),
+		ex.Col("date_taken").As("image_date_taken"),
+		ex.Col("camera_make").As("image_camera_make"),
+		ex.Col("camera_model").As("image_camera_model"),
+		ex.Col("lens").As("image_lens"),
+		ex.Col("latitude").As("image_latitude"),
+		ex.Col("longitude").As("image_longitude"),
+		ex.Col("altitude").As("image_altitude"),
+		ex.Col("title").As("image_title"),
 	}
 }
 
@@ -162,6 +204,17 @@ func (f *imageFileQueryRow) resolve() *file.ImageFile {
 		Format: f.Format.String,
 		Width:  int(f.Width.Int64),
 		Height: int(f.Height.Int64),
+		Metadata: file.ImageMetadata{
+			Orientation: int(f.Orientation.Int64),
+			DateTaken:   f.DateTaken.TimePtr(),
+			CameraMake:  f.CameraMake.String,
+			CameraModel: f.CameraModel.String,
+			Lens:        f.Lens.String,
+			Latitude:    nullFloatPtr(f.Latitude),
+			Longitude:   nullFloatPtr(f.Longitude),
+			Altitude:    nullFloatPtr(f.Altitude),
+			Title:       f.Title.String,
+		},
 	}
 }
 
@@ -403,6 +456,10 @@ func (qb *FileStore) createImageFile(ctx context.Context, id file.ID, f file.Ima
 		return err
 	}
 
+	if err := imageFileKeywordsTableMgr.insertJoins(ctx, int(id), f.Metadata.Keywords); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -423,6 +480,10 @@ func (qb *FileStore) updateOrCreateImageFile(ctx context.Context, id file.ID, f
 		return err
 	}
 
+	if err := imageFileKeywordsTableMgr.replaceJoins(ctx, int(id), f.Metadata.Keywords); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -541,7 +602,52 @@ func (qb *FileStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]file
 		return nil, err
 	}
 
-	return rows.resolve(), nil
+	ret := rows.resolve()
+	if err := qb.loadImageFileKeywords(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// loadImageFileKeywords sets the keywords of the image files in files.
+func (qb *FileStore) loadImageFileKeywords(ctx context.Context, files []file.File) error {
+	imageFiles := make(map[file.ID]*file.ImageFile)
+	var ids []int
+	for _, f := range files {
+		if imf, ok := f.(*file.ImageFile); ok {
+			imageFiles[imf.ID] = imf
+			ids = append(ids, int(imf.ID))
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	table := imageFileKeywordsTableMgr.table.table
+
+	return batchExec(ids, defaultBatchSize, func(batch []int) error {
+		q := dialect.Select(table.Col(fileIDColumn), table.Col(imageFileKeywordColumn)).From(table).Where(
+			table.Col(fileIDColumn).In(batch),
+		).Order(table.Col(fileIDColumn).Asc(), table.Col(imageFileKeywordColumn).Asc())
+
+		const single = false
+		return queryFunc(ctx, q, single, func(rows *sqlx.Rows) error {
+			var (
+				id      file.ID
+				keyword string
+			)
+			if err := rows.Scan(&id, &keyword); err != nil {
+				return err
+			}
+
+			imf := imageFiles[id]
+			imf.Metadata.Keywords = append(imf.Metadata.Keywords, keyword)
+
+			return nil
+		})
+	})
 }
 
 func (qb *FileStore) Find(ctx context.Context, ids ...file.ID) ([]file.File, error) {
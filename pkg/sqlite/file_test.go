@@ -45,6 +45,14 @@ func Test_fileFileStore_Create(t *testing.T) {
 		videoCodec       = "videoCodec"
 		audioCodec       = "audioCodec"
 		format           = "format"
+
+		orientation = 6
+		dateTaken   = time.Date(2002, 1, 1, 10, 0, 0, 0, time.UTC)
+		cameraMake  = "cameraMake"
+		cameraModel = "cameraModel"
+		latitude    = 1.5
+		longitude   = -2.5
+		keywords    = []string{"keyword1", "keyword2"}
 	)
 
 	tests := []struct {
@@ -133,6 +141,15 @@ func Test_fileFileStore_Create(t *testing.T) {
 				Format: format,
 				Width:  width,
 				Height: height,
+				Metadata: file.ImageMetadata{
+					Orientation: orientation,
+					DateTaken:   &dateTaken,
+					CameraMake:  cameraMake,
+					CameraModel: cameraModel,
+					Latitude:    &latitude,
+					Longitude:   &longitude,
+					Keywords:    keywords,
+				},
 			},
 			false,
 		},
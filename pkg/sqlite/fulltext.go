@@ -0,0 +1,585 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+// relevanceSort is the sort value used to sort full-text search results
+// by how well they match the search string.
+const relevanceSort = "relevance"
+
+const fullTextTokenizer = "unicode61 remove_diacritics 2"
+
+// fullTextColumn is a column of a full-text index.
+type fullTextColumn struct {
+	name string
+	// value is the expression selecting the column value from the row of
+	// the indexed table.
+	value string
+	// weight is the bm25 weight of the column when ranking results.
+	weight float64
+}
+
+// fullTextDependency is a table whose rows contribute to the indexed
+// text of the rows of an indexed table. Triggers are created on the table
+// to refresh the affected index rows when its rows change.
+type fullTextDependency struct {
+	table string
+	// columns are the columns that trigger a refresh when updated.
+	// Updates are ignored if empty.
+	columns []string
+	// ids selects the ids of the indexed rows affected by a change to a row
+	// of the table. The changed row is referred to as {row}.
+	ids string
+}
+
+// fullTextIndex is an FTS5 table indexing the text of the rows of a table.
+// The rowid of the index is the id of the indexed row.
+type fullTextIndex struct {
+	table        string
+	columns      []fullTextColumn
+	dependencies []fullTextDependency
+}
+
+func (i fullTextIndex) name() string {
+	return i.table + "_fts"
+}
+
+func (i fullTextIndex) columnNames() []string {
+	var ret []string
+	for _, c := range i.columns {
+		ret = append(ret, c.name)
+	}
+	return ret
+}
+
+func (i fullTextIndex) createSQL() string {
+	return fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, tokenize = '%s')", i.name(), strings.Join(i.columnNames(), ", "), fullTextTokenizer)
+}
+
+func (i fullTextIndex) rankSQL() string {
+	var weights []string
+	for _, c := range i.columns {
+		weights = append(weights, fmt.Sprintf("%.1f", c.weight))
+	}
+
+	return fmt.Sprintf("bm25(%s)", strings.Join(weights, ", "))
+}
+
+// populateSQL returns the statement indexing the rows of the table that
+// match the where clause.
+func (i fullTextIndex) populateSQL(where string) string {
+	var values []string
+	for _, c := range i.columns {
+		values = append(values, c.value)
+	}
+
+	ret := fmt.Sprintf("INSERT INTO %s(rowid, %s) SELECT %s.id, %s FROM %s", i.name(), strings.Join(i.columnNames(), ", "), i.table, strings.Join(values, ", "), i.table)
+	if where != "" {
+		ret += " WHERE " + where
+	}
+
+	return ret
+}
+
+type fullTextTrigger struct {
+	name string
+	sql  string
+}
+
+// mergedDependencies returns the dependencies of the index, combining
+// those on the same table.
+func (i fullTextIndex) mergedDependencies() []fullTextDependency {
+	var ret []fullTextDependency
+	indexes := make(map[string]int)
+
+	for _, d := range i.dependencies {
+		idx, found := indexes[d.table]
+		if !found {
+			indexes[d.table] = len(ret)
+			ret = append(ret, fullTextDependency{
+				table:   d.table,
+				columns: append([]string{}, d.columns...),
+				ids:     d.ids,
+			})
+			continue
+		}
+
+		merged := &ret[idx]
+		merged.ids += " UNION " + d.ids
+		for _, c := range d.columns {
+			if !stringslice.StrInclude(merged.columns, c) {
+				merged.columns = append(merged.columns, c)
+			}
+		}
+	}
+
+	return ret
+}
+
+func (i fullTextIndex) triggers() []fullTextTrigger {
+	var ret []fullTextTrigger
+
+	for _, d := range i.mergedDependencies() {
+		events := []struct {
+			name string
+			on   string
+			rows []string
+		}{
+			{"insert", "INSERT", []string{"NEW"}},
+			{"delete", "DELETE", []string{"OLD"}},
+		}
+
+		if len(d.columns) > 0 {
+			events = append(events, struct {
+				name string
+				on   string
+				rows []string
+			}{"update", "UPDATE OF " + strings.Join(d.columns, ", "), []string{"OLD", "NEW"}})
+		}
+
+		for _, e := range events {
+			var ids []string
+			for _, row := range e.rows {
+				ids = append(ids, strings.ReplaceAll(d.ids, "{row}", row))
+			}
+			idsSQL := strings.Join(ids, " UNION ")
+
+			name := fmt.Sprintf("%s_%s_%s", i.name(), d.table, e.name)
+			ret = append(ret, fullTextTrigger{
+				name: name,
+				sql: fmt.Sprintf("CREATE TRIGGER %s AFTER %s ON %s BEGIN\n  DELETE FROM %s WHERE rowid IN (%s);\n  %s;\nEND",
+					name, e.on, d.table, i.name(), idsSQL, i.populateSQL(fmt.Sprintf("%s.id IN (%s)", i.table, idsSQL))),
+			})
+		}
+	}
+
+	return ret
+}
+
+// groupConcat returns an expression concatenating the values selected
+// by the query.
+func groupConcat(value string, from string) string {
+	return fmt.Sprintf("(SELECT group_concat(%s, ' ') FROM %s)", value, from)
+}
+
+// fileFullTextColumns returns the columns indexing the paths and
+// fingerprints of the files of the rows of table.
+func fileFullTextColumns(table string, joinTable string, idColumn string) []fullTextColumn {
+	return []fullTextColumn{
+		{
+			name:   "paths",
+			value:  groupConcat("folders.path || ' ' || files.basename", fmt.Sprintf("%[1]s INNER JOIN files ON files.id = %[1]s.file_id INNER JOIN folders ON folders.id = files.parent_folder_id WHERE %[1]s.%[2]s = %[3]s.id", joinTable, idColumn, table)),
+			weight: 2,
+		},
+		{
+			name:   "fingerprints",
+			value:  groupConcat("files_fingerprints.fingerprint", fmt.Sprintf("%[1]s INNER JOIN files_fingerprints ON files_fingerprints.file_id = %[1]s.file_id WHERE %[1]s.%[2]s = %[3]s.id AND files_fingerprints.type != 'phash'", joinTable, idColumn, table)),
+			weight: 1,
+		},
+	}
+}
+
+func fileFullTextDependencies(joinTable string, idColumn string) []fullTextDependency {
+	return []fullTextDependency{
+		{
+			table:   joinTable,
+			columns: []string{idColumn, "file_id"},
+			ids:     fmt.Sprintf("SELECT {row}.%s", idColumn),
+		},
+		{
+			table:   fileTable,
+			columns: []string{"basename", "parent_folder_id"},
+			ids:     fmt.Sprintf("SELECT %[2]s FROM %[1]s WHERE file_id = {row}.id", joinTable, idColumn),
+		},
+		{
+			table:   folderTable,
+			columns: []string{"path"},
+			ids:     fmt.Sprintf("SELECT %[2]s FROM %[1]s INNER JOIN files ON files.id = %[1]s.file_id WHERE files.parent_folder_id = {row}.id", joinTable, idColumn),
+		},
+		{
+			table:   fingerprintTable,
+			columns: []string{"fingerprint"},
+			ids:     fmt.Sprintf("SELECT %[2]s FROM %[1]s WHERE file_id = {row}.file_id", joinTable, idColumn),
+		},
+	}
+}
+
+// relatedFullTextColumns returns the columns indexing the names of the
+// performers, tags and studio of the rows of table.
+func relatedFullTextColumns(table string, performersTable string, tagsTable string, idColumn string) []fullTextColumn {
+	return []fullTextColumn{
+		{
+			name: "performers",
+			value: groupConcat("performers.name || ' ' || COALESCE((SELECT group_concat(alias, ' ') FROM performer_aliases WHERE performer_id = performers.id), '')",
+				fmt.Sprintf("%[1]s INNER JOIN performers ON performers.id = %[1]s.performer_id WHERE %[1]s.%[2]s = %[3]s.id", performersTable, idColumn, table)),
+			weight: 4,
+		},
+		{
+			name:   "tags",
+			value:  groupConcat("tags.name", fmt.Sprintf("%[1]s INNER JOIN tags ON tags.id = %[1]s.tag_id WHERE %[1]s.%[2]s = %[3]s.id", tagsTable, idColumn, table)),
+			weight: 2,
+		},
+		{
+			name:   "studio",
+			value:  fmt.Sprintf("(SELECT name FROM studios WHERE studios.id = %s.studio_id)", table),
+			weight: 2,
+		},
+	}
+}
+
+func relatedFullTextDependencies(table string, performersTable string, tagsTable string, idColumn string) []fullTextDependency {
+	return []fullTextDependency{
+		{
+			table:   performersTable,
+			columns: []string{idColumn, "performer_id"},
+			ids:     fmt.Sprintf("SELECT {row}.%s", idColumn),
+		},
+		{
+			table:   performerTable,
+			columns: []string{"name"},
+			ids:     fmt.Sprintf("SELECT %s FROM %s WHERE performer_id = {row}.id", idColumn, performersTable),
+		},
+		{
+			table:   performersAliasesTable,
+			columns: []string{"alias"},
+			ids:     fmt.Sprintf("SELECT %s FROM %s WHERE performer_id = {row}.performer_id", idColumn, performersTable),
+		},
+		{
+			table:   tagsTable,
+			columns: []string{idColumn, "tag_id"},
+			ids:     fmt.Sprintf("SELECT {row}.%s", idColumn),
+		},
+		{
+			table:   tagTable,
+			columns: []string{"name"},
+			ids:     fmt.Sprintf("SELECT %s FROM %s WHERE tag_id = {row}.id", idColumn, tagsTable),
+		},
+		{
+			table:   studioTable,
+			columns: []string{"name"},
+			ids:     fmt.Sprintf("SELECT id FROM %s WHERE studio_id = {row}.id", table),
+		},
+	}
+}
+
+// aliasFullTextIndex returns the index of a table with a name and
+// aliases held in a separate table.
+func aliasFullTextIndex(table string, aliasesTable string, idColumn string, extraColumns ...fullTextColumn) fullTextIndex {
+	columns := append([]fullTextColumn{
+		{name: "name", value: table + ".name", weight: 10},
+		{name: "aliases", value: groupConcat("alias", fmt.Sprintf("%s WHERE %s = %s.id", aliasesTable, idColumn, table)), weight: 5},
+	}, extraColumns...)
+
+	baseColumns := []string{"name"}
+	for _, c := range extraColumns {
+		baseColumns = append(baseColumns, c.name)
+	}
+
+	return fullTextIndex{
+		table:   table,
+		columns: columns,
+		dependencies: []fullTextDependency{
+			{table: table, columns: baseColumns, ids: "SELECT {row}.id"},
+			{table: aliasesTable, columns: []string{"alias"}, ids: fmt.Sprintf("SELECT {row}.%s", idColumn)},
+		},
+	}
+}
+
+var fullTextIndexes = []fullTextIndex{
+	{
+		table: sceneTable,
+		columns: append(append([]fullTextColumn{
+			{name: "title", value: "scenes.title", weight: 10},
+			{name: "code", value: "scenes.code", weight: 5},
+			{name: "details", value: "scenes.details", weight: 1},
+			{name: "markers", value: groupConcat("title", "scene_markers WHERE scene_id = scenes.id"), weight: 2},
+			{name: "captions", value: groupConcat("video_captions.filename", "scenes_files INNER JOIN video_captions ON video_captions.file_id = scenes_files.file_id WHERE scenes_files.scene_id = scenes.id"), weight: 1},
+		}, fileFullTextColumns(sceneTable, scenesFilesTable, sceneIDColumn)...), relatedFullTextColumns(sceneTable, performersScenesTable, scenesTagsTable, sceneIDColumn)...),
+		dependencies: append(append([]fullTextDependency{
+			{table: sceneTable, columns: []string{"title", "code", "details", "studio_id"}, ids: "SELECT {row}.id"},
+			{table: sceneMarkerTable, columns: []string{"title", "scene_id"}, ids: "SELECT {row}.scene_id"},
+			{table: videoCaptionsTable, columns: []string{"filename"}, ids: "SELECT scene_id FROM scenes_files WHERE file_id = {row}.file_id"},
+		}, fileFullTextDependencies(scenesFilesTable, sceneIDColumn)...), relatedFullTextDependencies(sceneTable, performersScenesTable, scenesTagsTable, sceneIDColumn)...),
+	},
+	{
+		table: imageTable,
+		columns: append(append([]fullTextColumn{
+			{name: "title", value: "images.title", weight: 10},
+		}, fileFullTextColumns(imageTable, imagesFilesTable, imageIDColumn)...), relatedFullTextColumns(imageTable, performersImagesTable, imagesTagsTable, imageIDColumn)...),
+		dependencies: append(append([]fullTextDependency{
+			{table: imageTable, columns: []string{"title", "studio_id"}, ids: "SELECT {row}.id"},
+		}, fileFullTextDependencies(imagesFilesTable, imageIDColumn)...), relatedFullTextDependencies(imageTable, performersImagesTable, imagesTagsTable, imageIDColumn)...),
+	},
+	{
+		table: galleryTable,
+		columns: append(append([]fullTextColumn{
+			{name: "title", value: "galleries.title", weight: 10},
+			{name: "details", value: "galleries.details", weight: 1},
+			{name: "chapters", value: groupConcat("title", "galleries_chapters WHERE gallery_id = galleries.id"), weight: 2},
+			{name: "folder", value: "(SELECT path FROM folders WHERE folders.id = galleries.folder_id)", weight: 2},
+		}, fileFullTextColumns(galleryTable, galleriesFilesTable, galleryIDColumn)...), relatedFullTextColumns(galleryTable, performersGalleriesTable, galleriesTagsTable, galleryIDColumn)...),
+		dependencies: append(append([]fullTextDependency{
+			{table: galleryTable, columns: []string{"title", "details", "folder_id", "studio_id"}, ids: "SELECT {row}.id"},
+			{table: galleriesChaptersTable, columns: []string{"title", "gallery_id"}, ids: "SELECT {row}.gallery_id"},
+			{table: folderTable, columns: []string{"path"}, ids: "SELECT id FROM galleries WHERE folder_id = {row}.id"},
+		}, fileFullTextDependencies(galleriesFilesTable, galleryIDColumn)...), relatedFullTextDependencies(galleryTable, performersGalleriesTable, galleriesTagsTable, galleryIDColumn)...),
+	},
+	{
+		table: sceneMarkerTable,
+		columns: []fullTextColumn{
+			{name: "title", value: "scene_markers.title", weight: 10},
+			{name: "scene", value: "(SELECT title FROM scenes WHERE scenes.id = scene_markers.scene_id)", weight: 2},
+		},
+		dependencies: []fullTextDependency{
+			{table: sceneMarkerTable, columns: []string{"title", "scene_id"}, ids: "SELECT {row}.id"},
+			{table: sceneTable, columns: []string{"title"}, ids: "SELECT id FROM scene_markers WHERE scene_id = {row}.id"},
+		},
+	},
+	aliasFullTextIndex(performerTable, performersAliasesTable, performerIDColumn, fullTextColumn{name: "disambiguation", value: "performers.disambiguation", weight: 2}),
+	aliasFullTextIndex(studioTable, studioAliasesTable, studioIDColumn),
+	aliasFullTextIndex(tagTable, tagAliasesTable, tagIDColumn),
+	{
+		table: movieTable,
+		columns: []fullTextColumn{
+			{name: "name", value: "movies.name", weight: 10},
+			{name: "aliases", value: "movies.aliases", weight: 5},
+		},
+		dependencies: []fullTextDependency{
+			{table: movieTable, columns: []string{"name", "aliases"}, ids: "SELECT {row}.id"},
+		},
+	},
+}
+
+type sqlQueryer interface {
+	sqlx.Execer
+	sqlx.Queryer
+}
+
+// initFullTextSearch creates the full-text indexes and the triggers
+// keeping them up to date. Indexes are rebuilt if their triggers are
+// missing or out of date. If full-text search is not available, the
+// triggers are dropped so that the database can still be written to.
+func (db *Database) initFullTextSearch() error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := initFullTextIndexes(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func initFullTextIndexes(tx sqlQueryer) error {
+	if !fullTextSearchEnabled {
+		return dropFullTextTriggers(tx)
+	}
+
+	for _, index := range fullTextIndexes {
+		if err := initFullTextIndex(tx, index); err != nil {
+			return fmt.Errorf("initializing full-text index %s: %w", index.name(), err)
+		}
+	}
+
+	return nil
+}
+
+func initFullTextIndex(tx sqlQueryer, index fullTextIndex) error {
+	rebuild := false
+
+	var existingSQL []string
+	if err := sqlx.Select(tx, &existingSQL, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", index.name()); err != nil {
+		return err
+	}
+
+	if len(existingSQL) == 0 || existingSQL[0] != index.createSQL() {
+		if len(existingSQL) > 0 {
+			if _, err := tx.Exec("DROP TABLE " + index.name()); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(index.createSQL()); err != nil {
+			return err
+		}
+
+		rebuild = true
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %[1]s(%[1]s, rank) VALUES('rank', ?)", index.name()), index.rankSQL()); err != nil {
+		return err
+	}
+
+	existing, err := getFullTextTriggers(tx, index.name())
+	if err != nil {
+		return err
+	}
+
+	triggers := index.triggers()
+	if !rebuild && len(existing) == len(triggers) {
+		for _, t := range triggers {
+			if existing[t.name] != t.sql {
+				rebuild = true
+				break
+			}
+		}
+	} else {
+		rebuild = true
+	}
+
+	if !rebuild {
+		return nil
+	}
+
+	logger.Infof("Building full-text index %s", index.name())
+
+	for name := range existing {
+		if _, err := tx.Exec("DROP TRIGGER " + name); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range triggers {
+		if _, err := tx.Exec(t.sql); err != nil {
+			return fmt.Errorf("creating trigger %s: %w", t.name, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM " + index.name()); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(index.populateSQL("")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getFullTextTriggers returns the sql of the triggers of the index, keyed by name.
+func getFullTextTriggers(tx sqlQueryer, indexName string) (map[string]string, error) {
+	var rows []struct {
+		Name string `db:"name"`
+		SQL  string `db:"sql"`
+	}
+	if err := sqlx.Select(tx, &rows, `SELECT name, sql FROM sqlite_master WHERE type = 'trigger' AND name LIKE ? ESCAPE '\'`, strings.ReplaceAll(indexName, "_", `\_`)+`\_%`); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]string)
+	for _, r := range rows {
+		ret[r.Name] = r.SQL
+	}
+
+	return ret, nil
+}
+
+// dropFullTextTriggers drops the triggers maintaining the full-text
+// indexes. The indexes are rebuilt when the triggers are next created.
+func dropFullTextTriggers(tx sqlQueryer) error {
+	for _, index := range fullTextIndexes {
+		existing, err := getFullTextTriggers(tx, index.name())
+		if err != nil {
+			return err
+		}
+
+		for name := range existing {
+			if _, err := tx.Exec("DROP TRIGGER " + name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// dropFullTextTriggers drops the full-text search triggers from the
+// database before the connection is opened.
+func (db *Database) dropFullTextTriggers() error {
+	const disableForeignKeys = true
+	conn, err := db.open(disableForeignKeys)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return dropFullTextTriggers(conn)
+}
+
+// dropFullTextSearch drops the triggers maintaining the full-text
+// indexes and clears their contents.
+func (db *Database) dropFullTextSearch() error {
+	if err := dropFullTextTriggers(db.db); err != nil {
+		return err
+	}
+
+	if !fullTextSearchEnabled {
+		return nil
+	}
+
+	for _, index := range fullTextIndexes {
+		if _, err := db.db.Exec("DELETE FROM " + index.name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fullTextTerm returns the FTS5 phrase matching the search term. The last
+// token of the term is matched as a prefix. Returns an empty string if the
+// term contains no searchable tokens.
+func fullTextTerm(term string) string {
+	if strings.IndexFunc(term, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	}) == -1 {
+		return ""
+	}
+
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+}
+
+func fullTextTerms(terms []string) []string {
+	var ret []string
+	for _, t := range terms {
+		if ft := fullTextTerm(t); ft != "" {
+			ret = append(ret, ft)
+		}
+	}
+	return ret
+}
+
+// getFullTextMatch returns the FTS5 query matching the search specs, and
+// the query matching excluded terms if there are no other terms to
+// match.
+func getFullTextMatch(specs models.SearchSpecs) (match string, exclude string) {
+	clauses := fullTextTerms(specs.MustHave)
+
+	for _, set := range specs.AnySets {
+		if terms := fullTextTerms(set); len(terms) > 0 {
+			clauses = append(clauses, "("+strings.Join(terms, " OR ")+")")
+		}
+	}
+
+	match = strings.Join(clauses, " AND ")
+	exclude = strings.Join(fullTextTerms(specs.MustNot), " OR ")
+
+	if match != "" && exclude != "" {
+		match = "(" + match + ") NOT (" + exclude + ")"
+		exclude = ""
+	}
+
+	return match, exclude
+}
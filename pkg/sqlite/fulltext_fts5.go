@@ -0,0 +1,7 @@
+//go:build sqlite_fts5 || fts5
+// +build sqlite_fts5 fts5
+
+package sqlite
+
+// fullTextSearchEnabled is true if sqlite is built with FTS5 support.
+const fullTextSearchEnabled = true
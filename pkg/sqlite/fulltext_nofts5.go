@@ -0,0 +1,8 @@
+//go:build !sqlite_fts5 && !fts5
+// +build !sqlite_fts5,!fts5
+
+package sqlite
+
+// fullTextSearchEnabled is true if sqlite is built with FTS5 support.
+// Searches fall back to matching with LIKE when it is not.
+const fullTextSearchEnabled = false
@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	if !sqlite.FullTextSearchEnabled {
+		t.Skip("full-text search is not enabled")
+	}
+
+	runWithRollbackTxn(t, "full-text search", func(t *testing.T, ctx context.Context) {
+		qb := db.Scene
+
+		titleMatch := models.Scene{
+			Title:   "Quasar Fluxcapacitor Voyage",
+			Details: "the first",
+		}
+		detailsMatch := models.Scene{
+			Title:   "Voyage",
+			Details: "a story about a quasar",
+		}
+
+		for _, s := range []*models.Scene{&titleMatch, &detailsMatch} {
+			if err := qb.Create(ctx, s, nil); err != nil {
+				t.Errorf("creating scene: %v", err)
+				return
+			}
+		}
+
+		search := func(q string, sort string) []int {
+			direction := models.SortDirectionEnumDesc
+			findFilter := &models.FindFilterType{
+				Q:         &q,
+				Sort:      &sort,
+				Direction: &direction,
+			}
+			return scenesToIDs(queryScene(ctx, t, qb, nil, findFilter))
+		}
+
+		// prefixes of words are matched
+		assert.Equal(t, []int{titleMatch.ID}, search("fluxcap", "title"))
+		// excluded words
+		assert.Equal(t, []int{detailsMatch.ID}, search("voyage -fluxcapacitor", "title"))
+		// phrases
+		assert.Equal(t, []int{titleMatch.ID}, search(`"quasar fluxcapacitor"`, "title"))
+		// diacritics are ignored
+		assert.Equal(t, []int{titleMatch.ID}, search("quäsar fluxcapacitor", "title"))
+
+		// titles are weighted over details
+		assert.Equal(t, []int{titleMatch.ID, detailsMatch.ID}, search("quasar", "relevance"))
+
+		// the index is updated with the scene
+		title := "Nebula"
+		if _, err := qb.UpdatePartial(ctx, detailsMatch.ID, models.ScenePartial{
+			Title: models.NewOptionalString(title),
+		}); err != nil {
+			t.Errorf("updating scene: %v", err)
+			return
+		}
+
+		assert.Equal(t, []int{detailsMatch.ID}, search("nebula", "relevance"))
+		assert.Len(t, search("voyage", "relevance"), 1)
+
+		if err := qb.Destroy(ctx, titleMatch.ID); err != nil {
+			t.Errorf("destroying scene: %v", err)
+			return
+		}
+
+		assert.Len(t, search("fluxcapacitor", "relevance"), 0)
+
+		// related performer names are indexed
+		performer := models.Performer{
+			Name:    "Zyxwvut",
+			Aliases: models.NewRelatedStrings([]string{"Qwertzuiop"}),
+		}
+		if err := db.Performer.Create(ctx, &performer); err != nil {
+			t.Errorf("creating performer: %v", err)
+			return
+		}
+
+		if _, err := qb.UpdatePartial(ctx, detailsMatch.ID, models.ScenePartial{
+			PerformerIDs: &models.UpdateIDs{
+				IDs:  []int{performer.ID},
+				Mode: models.RelationshipUpdateModeSet,
+			},
+		}); err != nil {
+			t.Errorf("updating scene: %v", err)
+			return
+		}
+
+		assert.Equal(t, []int{detailsMatch.ID}, search("qwertz", "relevance"))
+
+		q := "qwertz"
+		assert.Equal(t, []int{performer.ID}, performersToIDs(queryPerformers(ctx, t, nil, &models.FindFilterType{Q: &q})))
+	})
+}
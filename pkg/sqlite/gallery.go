@@ -172,6 +172,8 @@ func (qb *GalleryStore) Create(ctx context.Context, newObject *models.Gallery, f
 		return err
 	}
 
+	galleryEditTracker.trackCreate(ctx, id)
+
 	if len(fileIDs) > 0 {
 		const firstPrimary = true
 		if err := galleriesFilesTableMgr.insertJoins(ctx, id, firstPrimary, fileIDs); err != nil {
@@ -189,6 +191,11 @@ func (qb *GalleryStore) Create(ctx context.Context, newObject *models.Gallery, f
 			return err
 		}
 	}
+	if newObject.PerformerIDs.Loaded() || newObject.TagIDs.Loaded() {
+		if err := applyTagImplications(ctx, galleryTagImplications, id); err != nil {
+			return err
+		}
+	}
 	if newObject.SceneIDs.Loaded() {
 		if err := galleriesScenesTableMgr.insertJoins(ctx, id, newObject.SceneIDs.List()); err != nil {
 			return err
@@ -206,6 +213,10 @@ func (qb *GalleryStore) Create(ctx context.Context, newObject *models.Gallery, f
 }
 
 func (qb *GalleryStore) Update(ctx context.Context, updatedObject *models.Gallery) error {
+	if err := galleryEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r galleryRow
 	r.fromGallery(*updatedObject)
 
@@ -223,6 +234,12 @@ func (qb *GalleryStore) Update(ctx context.Context, updatedObject *models.Galler
 			return err
 		}
 	}
+
+	if updatedObject.PerformerIDs.Loaded() || updatedObject.TagIDs.Loaded() {
+		if err := applyTagImplications(ctx, galleryTagImplications, updatedObject.ID); err != nil {
+			return err
+		}
+	}
 	if updatedObject.SceneIDs.Loaded() {
 		if err := galleriesScenesTableMgr.replaceJoins(ctx, updatedObject.ID, updatedObject.SceneIDs.List()); err != nil {
 			return err
@@ -244,6 +261,10 @@ func (qb *GalleryStore) Update(ctx context.Context, updatedObject *models.Galler
 }
 
 func (qb *GalleryStore) UpdatePartial(ctx context.Context, id int, partial models.GalleryPartial) (*models.Gallery, error) {
+	if err := galleryEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := galleryRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -268,6 +289,11 @@ func (qb *GalleryStore) UpdatePartial(ctx context.Context, id int, partial model
 			return nil, err
 		}
 	}
+	if partial.PerformerIDs != nil || partial.TagIDs != nil {
+		if err := applyTagImplications(ctx, galleryTagImplications, id); err != nil {
+			return nil, err
+		}
+	}
 	if partial.SceneIDs != nil {
 		if err := galleriesScenesTableMgr.modifyJoins(ctx, id, partial.SceneIDs.IDs, partial.SceneIDs.Mode); err != nil {
 			return nil, err
@@ -339,9 +365,12 @@ func (qb *GalleryStore) FindMany(ctx context.Context, ids []int) ([]*models.Gall
 		return nil, err
 	}
 
-	for i := range galleries {
-		if galleries[i] == nil {
-			return nil, fmt.Errorf("gallery with id %d not found", ids[i])
+	// content hidden by a restriction is left nil, as if it did not exist
+	if !models.IsRestricted(ctx) {
+		for i := range galleries {
+			if galleries[i] == nil {
+				return nil, fmt.Errorf("gallery with id %d not found", ids[i])
+			}
 		}
 	}
 
@@ -387,6 +416,8 @@ func (qb *GalleryStore) get(ctx context.Context, q *goqu.SelectDataset) (*models
 }
 
 func (qb *GalleryStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Gallery, error) {
+	q = restrictDataset(q, galleryRestrictionCondition(ctx))
+
 	const single = false
 	var ret []*models.Gallery
 	var lastID int
@@ -564,6 +595,7 @@ func (qb *GalleryStore) CountByImageID(ctx context.Context, imageID int) (int, e
 	joinTable := galleriesImagesJoinTable
 
 	q := dialect.Select(goqu.COUNT("*")).From(joinTable).Where(joinTable.Col(imageIDColumn).Eq(imageID))
+	q = restrictDataset(q, relatedRestrictionCondition(galleryTable, "galleries_images.gallery_id", galleryRestrictionCondition(ctx)))
 	return count(ctx, q)
 }
 
@@ -589,6 +621,7 @@ func (qb *GalleryStore) FindUserGalleryByTitle(ctx context.Context, title string
 
 func (qb *GalleryStore) Count(ctx context.Context) (int, error) {
 	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	q = restrictDataset(q, galleryRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -715,8 +748,11 @@ func (qb *GalleryStore) makeQuery(ctx context.Context, galleryFilter *models.Gal
 
 	query := qb.newQuery()
 	distinctIDs(&query, galleryTable)
+	query.addWhere(galleryRestrictionCondition(ctx))
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		query.addJoins(
 			join{
 				table:    galleriesFilesTable,
@@ -760,8 +796,14 @@ func (qb *GalleryStore) makeQuery(ctx context.Context, galleryFilter *models.Gal
 		return nil, err
 	}
 
-	qb.setGallerySort(&query, findFilter)
-	query.sortAndPagination += getPagination(findFilter)
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, galleryKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		qb.setGallerySort(&query, findFilter)
+		query.sortAndPagination += getPagination(findFilter)
+	}
 
 	return &query, nil
 }
@@ -785,6 +827,22 @@ func (qb *GalleryStore) Query(ctx context.Context, galleryFilter *models.Gallery
 	return galleries, countResult, nil
 }
 
+var galleryFacets = facetDefinitions{
+	models.FacetTypeTag:       relatedFacet(galleriesTagsTable, galleryIDColumn, tagTable, tagIDColumn, "name"),
+	models.FacetTypePerformer: relatedFacet(performersGalleriesTable, galleryIDColumn, performerTable, performerIDColumn, "name"),
+	models.FacetTypeStudio:    studioFacet(galleryTable),
+	models.FacetTypeYear:      yearFacet(galleryTable, "date"),
+}
+
+func (qb *GalleryStore) Facets(ctx context.Context, galleryFilter *models.GalleryFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, galleryFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, galleryFacets, options)
+}
+
 func (qb *GalleryStore) QueryCount(ctx context.Context, galleryFilter *models.GalleryFilterType, findFilter *models.FindFilterType) (int, error) {
 	query, err := qb.makeQuery(ctx, galleryFilter, findFilter)
 	if err != nil {
@@ -1044,6 +1102,17 @@ func galleryAverageResolutionCriterionHandler(qb *GalleryStore, resolution *mode
 	}
 }
 
+var galleryKeysetSorts = keysetSorts{
+	"title":           "COALESCE(galleries.title, '') COLLATE NATURAL_CI",
+	"date":            "COALESCE(galleries.date, '')",
+	"rating":          "COALESCE(galleries.rating, 0)",
+	"created_at":      "galleries.created_at",
+	"updated_at":      "galleries.updated_at",
+	"images_count":    getCountKeyset(galleryTable, galleriesImagesTable, galleryIDColumn),
+	"tag_count":       getCountKeyset(galleryTable, galleriesTagsTable, galleryIDColumn),
+	"performer_count": getCountKeyset(galleryTable, performersGalleriesTable, galleryIDColumn),
+}
+
 func (qb *GalleryStore) setGallerySort(query *queryBuilder, findFilter *models.FindFilterType) {
 	if findFilter == nil || findFilter.Sort == nil || *findFilter.Sort == "" {
 		return
@@ -1051,6 +1120,9 @@ func (qb *GalleryStore) setGallerySort(query *queryBuilder, findFilter *models.F
 
 	sort := findFilter.GetSort("path")
 	direction := findFilter.GetDirection()
+	if sort == relevanceSort && query.fullTextSearch == "" {
+		sort = "path"
+	}
 
 	addFileTable := func() {
 		query.addJoins(
@@ -1101,6 +1173,8 @@ func (qb *GalleryStore) setGallerySort(query *queryBuilder, findFilter *models.F
 		addFileTable()
 		addFolderTable()
 		query.sortAndPagination += " ORDER BY COALESCE(galleries.title, files.basename, basename(COALESCE(folders.path, ''))) COLLATE NATURAL_CI " + direction + ", file_folder.path COLLATE NATURAL_CI " + direction
+	case relevanceSort:
+		query.sortAndPagination += query.getRelevanceSort(direction)
 	default:
 		query.sortAndPagination += getSort(sort, direction, "galleries")
 	}
@@ -1167,12 +1241,14 @@ func (qb *GalleryStore) imagesRepository() *joinRepository {
 	}
 }
 
+// GetImageIDs returns the ids of the images in the gallery, in position order.
 func (qb *GalleryStore) GetImageIDs(ctx context.Context, galleryID int) ([]int, error) {
-	return qb.imagesRepository().getIDs(ctx, galleryID)
+	return galleriesImagesTableMgr.get(ctx, galleryID)
 }
 
+// AddImages adds the images to the end of the gallery.
 func (qb *GalleryStore) AddImages(ctx context.Context, galleryID int, imageIDs ...int) error {
-	return qb.imagesRepository().insertOrIgnore(ctx, galleryID, imageIDs...)
+	return galleriesImagesTableMgr.addJoins(ctx, galleryID, imageIDs)
 }
 
 func (qb *GalleryStore) RemoveImages(ctx context.Context, galleryID int, imageIDs ...int) error {
@@ -1180,8 +1256,19 @@ func (qb *GalleryStore) RemoveImages(ctx context.Context, galleryID int, imageID
 }
 
 func (qb *GalleryStore) UpdateImages(ctx context.Context, galleryID int, imageIDs []int) error {
-	// Delete the existing joins and then create new ones
-	return qb.imagesRepository().replace(ctx, galleryID, imageIDs)
+	return galleriesImagesTableMgr.replaceJoins(ctx, galleryID, imageIDs)
+}
+
+// ReorderImages sets the positions of the provided images in the gallery to
+// their order in imageIDs, starting at 1. Images not in the gallery are ignored.
+func (qb *GalleryStore) ReorderImages(ctx context.Context, galleryID int, imageIDs []int) error {
+	for i, imageID := range imageIDs {
+		if err := galleriesImagesTableMgr.setPosition(ctx, galleryID, imageID, i+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (qb *GalleryStore) scenesRepository() *joinRepository {
@@ -2948,6 +2948,126 @@ func TestGalleryStore_RemoveImages(t *testing.T) {
 	}
 }
 
+func TestGalleryStore_ReorderImages(t *testing.T) {
+	var (
+		galleryID = galleryIDs[galleryIdxWithTwoImages]
+		image1ID  = imageIDs[imageIdx1WithGallery]
+		image2ID  = imageIDs[imageIdx2WithGallery]
+		newID     = imageIDs[imageIdx1WithPerformer]
+	)
+
+	queryImageIDs := func(ctx context.Context, direction models.SortDirectionEnum) ([]int, error) {
+		sort := "gallery_position"
+		result, err := db.Image.Query(ctx, models.ImageQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &models.FindFilterType{
+					Sort:      &sort,
+					Direction: &direction,
+				},
+			},
+			ImageFilter: &models.ImageFilterType{
+				Galleries: &models.MultiCriterionInput{
+					Value:    []string{strconv.Itoa(galleryID)},
+					Modifier: models.CriterionModifierIncludes,
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return result.IDs, nil
+	}
+
+	tests := []struct {
+		name      string
+		addBefore []int
+		imageIDs  []int
+		addAfter  []int
+		want      []int
+	}{
+		{
+			"reverse",
+			nil,
+			[]int{image2ID, image1ID},
+			nil,
+			[]int{image2ID, image1ID},
+		},
+		{
+			"same",
+			nil,
+			[]int{image1ID, image2ID},
+			nil,
+			[]int{image1ID, image2ID},
+		},
+		{
+			"add after reorder",
+			nil,
+			[]int{image2ID, image1ID},
+			[]int{newID},
+			[]int{image2ID, image1ID, newID},
+		},
+		{
+			"reorder added",
+			[]int{newID},
+			[]int{newID, image2ID, image1ID},
+			nil,
+			[]int{newID, image2ID, image1ID},
+		},
+	}
+
+	qb := db.Gallery
+
+	for _, tt := range tests {
+		runWithRollbackTxn(t, tt.name, func(t *testing.T, ctx context.Context) {
+			assert := assert.New(t)
+
+			if err := qb.AddImages(ctx, galleryID, tt.addBefore...); err != nil {
+				t.Errorf("GalleryStore.AddImages() error = %v", err)
+				return
+			}
+
+			if err := qb.ReorderImages(ctx, galleryID, tt.imageIDs); err != nil {
+				t.Errorf("GalleryStore.ReorderImages() error = %v", err)
+				return
+			}
+
+			if err := qb.AddImages(ctx, galleryID, tt.addAfter...); err != nil {
+				t.Errorf("GalleryStore.AddImages() error = %v", err)
+				return
+			}
+
+			got, err := qb.GetImageIDs(ctx, galleryID)
+			if err != nil {
+				t.Errorf("GalleryStore.GetImageIDs() error = %v", err)
+				return
+			}
+
+			assert.Equal(tt.want, got)
+
+			got, err = queryImageIDs(ctx, models.SortDirectionEnumAsc)
+			if err != nil {
+				t.Errorf("ImageStore.Query() error = %v", err)
+				return
+			}
+
+			assert.Equal(tt.want, got)
+
+			got, err = queryImageIDs(ctx, models.SortDirectionEnumDesc)
+			if err != nil {
+				t.Errorf("ImageStore.Query() error = %v", err)
+				return
+			}
+
+			want := make([]int, len(tt.want))
+			for i, id := range tt.want {
+				want[len(want)-1-i] = id
+			}
+			assert.Equal(want, got)
+		})
+	}
+}
+
 func TestGalleryQueryHasChapters(t *testing.T) {
 	withTxn(func(ctx context.Context) error {
 		sqb := db.Gallery
@@ -6,11 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stashapp/stash/pkg/file"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/utils"
 	"gopkg.in/guregu/null.v4"
 	"gopkg.in/guregu/null.v4/zero"
 
@@ -170,6 +174,8 @@ func (qb *ImageStore) Create(ctx context.Context, newObject *models.ImageCreateI
 		return err
 	}
 
+	imageEditTracker.trackCreate(ctx, id)
+
 	if len(newObject.FileIDs) > 0 {
 		const firstPrimary = true
 		if err := imagesFilesTableMgr.insertJoins(ctx, id, firstPrimary, newObject.FileIDs); err != nil {
@@ -187,6 +193,11 @@ func (qb *ImageStore) Create(ctx context.Context, newObject *models.ImageCreateI
 			return err
 		}
 	}
+	if newObject.PerformerIDs.Loaded() || newObject.TagIDs.Loaded() {
+		if err := applyTagImplications(ctx, imageTagImplications, id); err != nil {
+			return err
+		}
+	}
 
 	if newObject.GalleryIDs.Loaded() {
 		if err := imageGalleriesTableMgr.insertJoins(ctx, id, newObject.GalleryIDs.List()); err != nil {
@@ -205,6 +216,10 @@ func (qb *ImageStore) Create(ctx context.Context, newObject *models.ImageCreateI
 }
 
 func (qb *ImageStore) UpdatePartial(ctx context.Context, id int, partial models.ImagePartial) (*models.Image, error) {
+	if err := imageEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := imageRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -234,6 +249,11 @@ func (qb *ImageStore) UpdatePartial(ctx context.Context, id int, partial models.
 			return nil, err
 		}
 	}
+	if partial.PerformerIDs != nil || partial.TagIDs != nil {
+		if err := applyTagImplications(ctx, imageTagImplications, id); err != nil {
+			return nil, err
+		}
+	}
 
 	if partial.PrimaryFileID != nil {
 		if err := imagesFilesTableMgr.setPrimary(ctx, id, *partial.PrimaryFileID); err != nil {
@@ -245,6 +265,10 @@ func (qb *ImageStore) UpdatePartial(ctx context.Context, id int, partial models.
 }
 
 func (qb *ImageStore) Update(ctx context.Context, updatedObject *models.Image) error {
+	if err := imageEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r imageRow
 	r.fromImage(*updatedObject)
 
@@ -264,6 +288,12 @@ func (qb *ImageStore) Update(ctx context.Context, updatedObject *models.Image) e
 		}
 	}
 
+	if updatedObject.PerformerIDs.Loaded() || updatedObject.TagIDs.Loaded() {
+		if err := applyTagImplications(ctx, imageTagImplications, updatedObject.ID); err != nil {
+			return err
+		}
+	}
+
 	if updatedObject.GalleryIDs.Loaded() {
 		if err := imageGalleriesTableMgr.replaceJoins(ctx, updatedObject.ID, updatedObject.GalleryIDs.List()); err != nil {
 			return err
@@ -316,9 +346,12 @@ func (qb *ImageStore) FindMany(ctx context.Context, ids []int) ([]*models.Image,
 		return nil, err
 	}
 
-	for i := range images {
-		if images[i] == nil {
-			return nil, fmt.Errorf("image with id %d not found", ids[i])
+	// content hidden by a restriction is left nil, as if it did not exist
+	if !models.IsRestricted(ctx) {
+		for i := range images {
+			if images[i] == nil {
+				return nil, fmt.Errorf("image with id %d not found", ids[i])
+			}
 		}
 	}
 
@@ -364,6 +397,8 @@ func (qb *ImageStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.I
 }
 
 func (qb *ImageStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Image, error) {
+	q = restrictDataset(q, imageRestrictionCondition(ctx))
+
 	const single = false
 	var ret []*models.Image
 	var lastID int
@@ -503,10 +538,87 @@ func (qb *ImageStore) FindByGalleryID(ctx context.Context, galleryID int) ([]*mo
 	return ret, nil
 }
 
+// FindDuplicates returns groups of images with files having phashes within
+// distance of each other. A distance of zero matches identical phashes only.
+func (qb *ImageStore) FindDuplicates(ctx context.Context, distance int) ([][]*models.Image, error) {
+	var dupeIds [][]int
+	if distance == 0 {
+		query := fmt.Sprintf(`SELECT GROUP_CONCAT(DISTINCT phashes.id) as ids FROM (%s) phashes
+GROUP BY phashes.phash
+HAVING COUNT(DISTINCT phashes.id) > 1`, imagePhashFiles.phashesQuery())
+
+		var ids []string
+		if err := qb.tx.Select(ctx, &ids, query); err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			var imageIds []int
+			for _, strId := range strings.Split(id, ",") {
+				if intId, err := strconv.Atoi(strId); err == nil {
+					imageIds = intslice.IntAppendUnique(imageIds, intId)
+				}
+			}
+			if len(imageIds) > 1 {
+				dupeIds = append(dupeIds, imageIds)
+			}
+		}
+	} else {
+		var hashes []*utils.Phash
+
+		if err := qb.queryFunc(ctx, imagePhashFiles.phashesQuery(), nil, false, func(rows *sqlx.Rows) error {
+			phash := utils.Phash{
+				Bucket:   -1,
+				Duration: -1,
+			}
+			if err := rows.StructScan(&phash); err != nil {
+				return err
+			}
+
+			hashes = append(hashes, &phash)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		dupeIds = utils.FindDuplicates(hashes, distance, -1)
+	}
+
+	var duplicates [][]*models.Image
+	for _, imageIds := range dupeIds {
+		images, err := qb.FindMany(ctx, imageIds)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, images)
+	}
+
+	sortImagesByPath(duplicates)
+
+	return duplicates, nil
+}
+
+func sortImagesByPath(images [][]*models.Image) {
+	firstPath := func(group []*models.Image) string {
+		var ret string
+		for i, image := range group {
+			if i == 0 || image.Path < ret {
+				ret = image.Path
+			}
+		}
+		return ret
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return firstPath(images[i]) < firstPath(images[j])
+	})
+}
+
 func (qb *ImageStore) CountByGalleryID(ctx context.Context, galleryID int) (int, error) {
 	joinTable := goqu.T(galleriesImagesTable)
 
 	q := dialect.Select(goqu.COUNT("*")).From(joinTable).Where(joinTable.Col("gallery_id").Eq(galleryID))
+	q = restrictDataset(q, relatedRestrictionCondition(imageTable, "galleries_images.image_id", imageRestrictionCondition(ctx)))
 	return count(ctx, q)
 }
 
@@ -514,6 +626,7 @@ func (qb *ImageStore) OCountByPerformerID(ctx context.Context, performerID int)
 	table := qb.table()
 	joinTable := performersImagesJoinTable
 	q := dialect.Select(goqu.COALESCE(goqu.SUM("o_counter"), 0)).From(table).InnerJoin(joinTable, goqu.On(table.Col(idColumn).Eq(joinTable.Col(imageIDColumn)))).Where(joinTable.Col(performerIDColumn).Eq(performerID))
+	q = restrictDataset(q, imageRestrictionCondition(ctx))
 
 	var ret int
 	if err := querySimple(ctx, q, &ret); err != nil {
@@ -575,6 +688,7 @@ func (qb *ImageStore) FindByZipFileID(ctx context.Context, zipFileID file.ID) ([
 
 func (qb *ImageStore) Count(ctx context.Context) (int, error) {
 	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	q = restrictDataset(q, imageRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -590,6 +704,7 @@ func (qb *ImageStore) Size(ctx context.Context) (float64, error) {
 		fileTable,
 		goqu.On(imagesFilesJoinTable.Col(fileIDColumn).Eq(fileTable.Col(idColumn))),
 	)
+	q = restrictDataset(q, imageRestrictionCondition(ctx))
 	var ret float64
 	if err := querySimple(ctx, q, &ret); err != nil {
 		return 0, err
@@ -663,6 +778,9 @@ func (qb *ImageStore) makeFilter(ctx context.Context, imageFilter *models.ImageF
 	// legacy rating handler
 	query.handleCriterion(ctx, rating5CriterionHandler(imageFilter.Rating, "images.rating", nil))
 	query.handleCriterion(ctx, intCriterionHandler(imageFilter.OCounter, "images.o_counter", nil))
+	query.handleCriterion(ctx, phashDistanceCriterionHandler(imageFilter.PhashDistance, imagePhashFiles))
+	query.handleCriterion(ctx, phashSimilarToCriterionHandler(imageFilter.PhashSimilarTo, imagePhashFiles))
+	query.handleCriterion(ctx, phashDuplicatedCriterionHandler(imageFilter.Duplicated, imagePhashFiles))
 	query.handleCriterion(ctx, boolCriterionHandler(imageFilter.Organized, "images.organized", nil))
 	query.handleCriterion(ctx, dateCriterionHandler(imageFilter.Date, "images.date"))
 	query.handleCriterion(ctx, stringCriterionHandler(imageFilter.URL, "images.url"))
@@ -681,6 +799,13 @@ func (qb *ImageStore) makeFilter(ctx context.Context, imageFilter *models.ImageF
 	query.handleCriterion(ctx, timestampCriterionHandler(imageFilter.CreatedAt, "images.created_at"))
 	query.handleCriterion(ctx, timestampCriterionHandler(imageFilter.UpdatedAt, "images.updated_at"))
 
+	query.handleCriterion(ctx, imageFileMetadataCriterionHandler(qb, imageFilter.DateTaken != nil, timestampCriterionHandler(imageFilter.DateTaken, "image_files.date_taken")))
+	query.handleCriterion(ctx, imageFileMetadataCriterionHandler(qb, imageFilter.Camera != nil, stringCriterionHandler(imageFilter.Camera, "TRIM(COALESCE(image_files.camera_make, '') || ' ' || COALESCE(image_files.camera_model, ''))")))
+	query.handleCriterion(ctx, imageFileMetadataCriterionHandler(qb, imageFilter.Lens != nil, stringCriterionHandler(imageFilter.Lens, "image_files.lens")))
+	query.handleCriterion(ctx, intCriterionHandler(imageFilter.Orientation, "image_files.orientation", qb.addImageFilesTable))
+	query.handleCriterion(ctx, imageKeywordsCriterionHandler(qb, imageFilter.Keywords))
+	query.handleCriterion(ctx, imageHasGPSCriterionHandler(qb, imageFilter.HasGps))
+
 	return query
 }
 
@@ -713,8 +838,11 @@ func (qb *ImageStore) makeQuery(ctx context.Context, imageFilter *models.ImageFi
 
 	query := qb.newQuery()
 	distinctIDs(&query, imageTable)
+	query.addWhere(imageRestrictionCondition(ctx))
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		query.addJoins(
 			join{
 				table:    imagesFilesTable,
@@ -748,7 +876,13 @@ func (qb *ImageStore) makeQuery(ctx context.Context, imageFilter *models.ImageFi
 		return nil, err
 	}
 
-	qb.setImageSortAndPagination(&query, findFilter)
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, imageKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		qb.setImageSortAndPagination(&query, imageFilter, findFilter)
+	}
 
 	return &query, nil
 }
@@ -815,6 +949,28 @@ func (qb *ImageStore) queryGroupedFields(ctx context.Context, options models.Ima
 	return ret, nil
 }
 
+var imageFacets = facetDefinitions{
+	models.FacetTypeTag:       relatedFacet(imagesTagsTable, imageIDColumn, tagTable, tagIDColumn, "name"),
+	models.FacetTypePerformer: relatedFacet(performersImagesTable, imageIDColumn, performerTable, performerIDColumn, "name"),
+	models.FacetTypeGallery:   relatedFacet(galleriesImagesTable, imageIDColumn, galleryTable, galleryIDColumn, "title"),
+	models.FacetTypeStudio:    studioFacet(imageTable),
+	models.FacetTypeYear:      yearFacet(imageTable, "date"),
+	models.FacetTypeResolution: {
+		joins: "INNER JOIN images_files ON images_files.image_id = temp.id AND images_files.`primary` = 1 INNER JOIN image_files ON image_files.file_id = images_files.file_id",
+		value: resolutionExpression("image_files.height", "image_files.width"),
+		label: resolutionExpression("image_files.height", "image_files.width"),
+	},
+}
+
+func (qb *ImageStore) Facets(ctx context.Context, imageFilter *models.ImageFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, imageFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, imageFacets, options)
+}
+
 func (qb *ImageStore) QueryCount(ctx context.Context, imageFilter *models.ImageFilterType, findFilter *models.FindFilterType) (int, error) {
 	query, err := qb.makeQuery(ctx, imageFilter, findFilter)
 	if err != nil {
@@ -856,6 +1012,45 @@ func imageIsMissingCriterionHandler(qb *ImageStore, isMissing *string) criterion
 	}
 }
 
+// imageFileMetadataCriterionHandler joins the image_files table before
+// running h, if set is true.
+func imageFileMetadataCriterionHandler(qb *ImageStore, set bool, h criterionHandlerFunc) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if set {
+			qb.addImageFilesTable(f)
+		}
+
+		h(ctx, f)
+	}
+}
+
+func imageKeywordsCriterionHandler(qb *ImageStore, keywords *models.StringCriterionInput) criterionHandlerFunc {
+	h := stringListCriterionHandlerBuilder{
+		joinTable:    imageFileKeywordsTable,
+		stringColumn: imageFileKeywordColumn,
+		addJoinTable: func(f *filterBuilder) {
+			qb.addImagesFilesTable(f)
+			f.addLeftJoin(imageFileKeywordsTable, "", "image_file_keywords.file_id = images_files.file_id")
+		},
+	}
+
+	return h.handler(keywords)
+}
+
+func imageHasGPSCriterionHandler(qb *ImageStore, hasGPS *bool) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if hasGPS != nil {
+			qb.addImageFilesTable(f)
+
+			if *hasGPS {
+				f.addWhere("image_files.latitude IS NOT NULL AND image_files.longitude IS NOT NULL")
+			} else {
+				f.addWhere("(image_files.latitude IS NULL OR image_files.longitude IS NULL)")
+			}
+		}
+	}
+}
+
 func (qb *ImageStore) getMultiCriterionHandlerBuilder(foreignTable, joinTable, foreignFK string, addJoinsFunc func(f *filterBuilder)) multiCriterionHandlerBuilder {
 	return multiCriterionHandlerBuilder{
 		primaryTable: imageTable,
@@ -961,13 +1156,28 @@ func imagePerformerTagsCriterionHandler(qb *ImageStore, tags *models.Hierarchica
 	}
 }
 
-func (qb *ImageStore) setImageSortAndPagination(q *queryBuilder, findFilter *models.FindFilterType) {
+var imageKeysetSorts = keysetSorts{
+	"title":           "COALESCE(images.title, (SELECT files.basename FROM images_files INNER JOIN files ON files.id = images_files.file_id WHERE images_files.image_id = images.id AND images_files.`primary` = 1), '') COLLATE NATURAL_CI",
+	"date":            "COALESCE(images.date, '')",
+	"rating":          "COALESCE(images.rating, 0)",
+	"o_counter":       "images.o_counter",
+	"created_at":      "images.created_at",
+	"updated_at":      "images.updated_at",
+	"tag_count":       getCountKeyset(imageTable, imagesTagsTable, imageIDColumn),
+	"performer_count": getCountKeyset(imageTable, performersImagesTable, imageIDColumn),
+}
+
+func (qb *ImageStore) setImageSortAndPagination(q *queryBuilder, imageFilter *models.ImageFilterType, findFilter *models.FindFilterType) {
 	sortClause := ""
 
 	if findFilter != nil && findFilter.Sort != nil && *findFilter.Sort != "" {
 		sort := findFilter.GetSort("title")
 		direction := findFilter.GetDirection()
 
+		if sort == relevanceSort && q.fullTextSearch == "" {
+			sort = "title"
+		}
+
 		// translate sort field
 		if sort == "file_mod_time" {
 			sort = "mod_time"
@@ -1011,6 +1221,10 @@ func (qb *ImageStore) setImageSortAndPagination(q *queryBuilder, findFilter *mod
 			addFilesJoin()
 			addFolderJoin()
 			sortClause = " ORDER BY COALESCE(images.title, files.basename) COLLATE NATURAL_CI " + direction + ", folders.path COLLATE NATURAL_CI " + direction
+		case "gallery_position":
+			sortClause = " ORDER BY " + galleryPositionSortColumn(imageFilter) + " " + direction
+		case relevanceSort:
+			sortClause = q.getRelevanceSort(direction)
 		default:
 			sortClause = getSort(sort, direction, "images")
 		}
@@ -1022,6 +1236,23 @@ func (qb *ImageStore) setImageSortAndPagination(q *queryBuilder, findFilter *mod
 	q.sortAndPagination = sortClause + getPagination(findFilter)
 }
 
+// galleryPositionSortColumn returns the expression used to sort by gallery
+// position. If the filter includes a single gallery, then images are sorted
+// by their position in that gallery. Otherwise, the lowest position across
+// all galleries of the image is used.
+func galleryPositionSortColumn(imageFilter *models.ImageFilterType) string {
+	if imageFilter != nil && imageFilter.Galleries != nil && len(imageFilter.Galleries.Value) == 1 {
+		modifier := imageFilter.Galleries.Modifier
+		if modifier == models.CriterionModifierIncludes || modifier == models.CriterionModifierIncludesAll {
+			if galleryID, err := strconv.Atoi(imageFilter.Galleries.Value[0]); err == nil {
+				return fmt.Sprintf("(SELECT %s FROM %s WHERE %s = images.id AND %s = %d)", galleriesImagesPositionColumn, galleriesImagesTable, imageIDColumn, galleryIDColumn, galleryID)
+			}
+		}
+	}
+
+	return fmt.Sprintf("(SELECT MIN(%s) FROM %s WHERE %s = images.id)", galleriesImagesPositionColumn, galleriesImagesTable, imageIDColumn)
+}
+
 func (qb *ImageStore) galleriesRepository() *joinRepository {
 	return &joinRepository{
 		repository: repository{
@@ -1052,6 +1283,17 @@ func (qb *ImageStore) GetGalleryIDs(ctx context.Context, imageID int) ([]int, er
 	return qb.galleriesRepository().getIDs(ctx, imageID)
 }
 
+// GetGalleryPositions returns a map of gallery id to the position of the
+// image within that gallery.
+func (qb *ImageStore) GetGalleryPositions(ctx context.Context, imageID int) (map[int]int, error) {
+	return imageGalleriesTableMgr.getPositions(ctx, imageID)
+}
+
+// SetGalleryPosition sets the position of the image within the gallery.
+func (qb *ImageStore) SetGalleryPosition(ctx context.Context, imageID int, galleryID int, position int) error {
+	return imageGalleriesTableMgr.setPosition(ctx, imageID, galleryID, position)
+}
+
 // func (qb *imageQueryBuilder) UpdateGalleries(ctx context.Context, imageID int, galleryIDs []int) error {
 // 	// Delete the existing joins and then create new ones
 // 	return qb.galleriesRepository().replace(ctx, imageID, galleryIDs)
@@ -1073,8 +1315,16 @@ func (qb *ImageStore) GetPerformerIDs(ctx context.Context, imageID int) ([]int,
 }
 
 func (qb *ImageStore) UpdatePerformers(ctx context.Context, imageID int, performerIDs []int) error {
+	if err := imageEditTracker.track(ctx, imageID); err != nil {
+		return err
+	}
+
 	// Delete the existing joins and then create new ones
-	return qb.performersRepository().replace(ctx, imageID, performerIDs)
+	if err := qb.performersRepository().replace(ctx, imageID, performerIDs); err != nil {
+		return err
+	}
+
+	return applyTagImplications(ctx, imageTagImplications, imageID)
 }
 
 func (qb *ImageStore) tagsRepository() *joinRepository {
@@ -1095,6 +1345,14 @@ func (qb *ImageStore) GetTagIDs(ctx context.Context, imageID int) ([]int, error)
 }
 
 func (qb *ImageStore) UpdateTags(ctx context.Context, imageID int, tagIDs []int) error {
+	if err := imageEditTracker.track(ctx, imageID); err != nil {
+		return err
+	}
+
 	// Delete the existing joins and then create new ones
-	return qb.tagsRepository().replace(ctx, imageID, tagIDs)
+	if err := qb.tagsRepository().replace(ctx, imageID, tagIDs); err != nil {
+		return err
+	}
+
+	return applyTagImplications(ctx, imageTagImplications, imageID)
 }
@@ -5,6 +5,7 @@ package sqlite_test
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"strconv"
 	"testing"
@@ -1843,6 +1844,113 @@ func verifyImagesOCounter(t *testing.T, oCounterCriterion models.IntCriterionInp
 	})
 }
 
+func createPhashImages(ctx context.Context, t *testing.T, phashes []int64) []int {
+	var ids []int
+	for i, phash := range phashes {
+		basename := fmt.Sprintf("phash_image_%d.jpg", i)
+		f := &file.ImageFile{
+			BaseFile: &file.BaseFile{
+				Path:           getFilePath(folderIdxWithImageFiles, basename),
+				Basename:       basename,
+				ParentFolderID: folderIDs[folderIdxWithImageFiles],
+				Fingerprints: []file.Fingerprint{
+					{
+						Type:        file.FingerprintTypePhash,
+						Fingerprint: phash,
+					},
+				},
+			},
+		}
+		if err := db.File.Create(ctx, f); err != nil {
+			t.Fatalf("creating image file: %v", err)
+		}
+
+		image := &models.Image{}
+		if err := db.Image.Create(ctx, &models.ImageCreateInput{
+			Image:   image,
+			FileIDs: []file.ID{f.ID},
+		}); err != nil {
+			t.Fatalf("creating image: %v", err)
+		}
+
+		ids = append(ids, image.ID)
+	}
+
+	return ids
+}
+
+func TestImageQueryPhash(t *testing.T) {
+	runWithRollbackTxn(t, "phash", func(t *testing.T, ctx context.Context) {
+		sqb := db.Image
+		// exact, exact, distance 1, distance 8
+		ids := createPhashImages(ctx, t, []int64{0x100, 0x100, 0x101, 0xff})
+
+		query := func(imageFilter models.ImageFilterType) []int {
+			return imagesToIDs(queryImages(ctx, t, sqb, &imageFilter, nil))
+		}
+
+		duplicated := true
+		dupeCriterion := models.PHashDuplicationCriterionInput{
+			Duplicated: &duplicated,
+		}
+		assert.ElementsMatch(t, ids[:2], query(models.ImageFilterType{Duplicated: &dupeCriterion}))
+
+		distance := 1
+		dupeCriterion.Distance = &distance
+		assert.ElementsMatch(t, ids[:3], query(models.ImageFilterType{Duplicated: &dupeCriterion}))
+
+		duplicated = false
+		assert.ElementsMatch(t, ids[3:], query(models.ImageFilterType{Duplicated: &dupeCriterion}))
+
+		similarTo := models.PhashSimilarityCriterionInput{
+			ID: strconv.Itoa(ids[0]),
+		}
+		assert.ElementsMatch(t, ids[1:2], query(models.ImageFilterType{PhashSimilarTo: &similarTo}))
+
+		distance = 9
+		similarTo.Distance = &distance
+		assert.ElementsMatch(t, ids[1:], query(models.ImageFilterType{PhashSimilarTo: &similarTo}))
+
+		assert.ElementsMatch(t, ids[:2], query(models.ImageFilterType{PhashDistance: &models.PhashDistanceCriterionInput{
+			Value:    "100",
+			Modifier: models.CriterionModifierEquals,
+		}}))
+	})
+}
+
+func TestImageStore_FindDuplicates(t *testing.T) {
+	runWithRollbackTxn(t, "find duplicates", func(t *testing.T, ctx context.Context) {
+		qb := db.Image
+		ids := createPhashImages(ctx, t, []int64{0x100, 0x100, 0x101, 0xff})
+
+		toIDs := func(groups [][]*models.Image) [][]int {
+			var ret [][]int
+			for _, g := range groups {
+				ret = append(ret, imagesToIDs(g))
+			}
+			return ret
+		}
+
+		got, err := qb.FindDuplicates(ctx, 0)
+		if err != nil {
+			t.Errorf("ImageStore.FindDuplicates() error = %v", err)
+			return
+		}
+
+		assert.Len(t, got, 1)
+		assert.ElementsMatch(t, ids[:2], toIDs(got)[0])
+
+		got, err = qb.FindDuplicates(ctx, 1)
+		if err != nil {
+			t.Errorf("ImageStore.FindDuplicates() error = %v", err)
+			return
+		}
+
+		assert.Len(t, got, 1)
+		assert.ElementsMatch(t, ids[:3], toIDs(got)[0])
+	})
+}
+
 func TestImageQueryResolution(t *testing.T) {
 	verifyImagesResolution(t, models.ResolutionEnumLow)
 	verifyImagesResolution(t, models.ResolutionEnumStandard)
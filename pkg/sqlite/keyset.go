@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// keysetSorts maps the sorts supported when paging with cursors to the
+// expressions the results are ordered by. Expressions must not evaluate to
+// null, and may only reference columns of the primary table. Ties are
+// broken using the id column.
+type keysetSorts map[string]string
+
+// setKeysetPagination sorts and limits the query using the cursor of the
+// provided find filter. Results are ordered by the sort expression and id,
+// and only the results following the cursor are returned.
+//
+// Unlike page numbers, the position of the cursor does not change when
+// results are inserted before it, and the query does not need to skip
+// the preceding results.
+func (qb *queryBuilder) setKeysetPagination(ctx context.Context, sorts keysetSorts, findFilter *models.FindFilterType) error {
+	table := qb.repository.tableName
+	idCol := getColumn(table, idColumn)
+
+	sort := findFilter.GetSort(models.CursorDefaultSort)
+	direction := getSortDirection(findFilter.GetDirection())
+
+	expr := idCol
+	if sort != models.CursorDefaultSort {
+		var ok bool
+		expr, ok = sorts[sort]
+		if !ok {
+			return fmt.Errorf("sort %q is not supported when paging with cursors", sort)
+		}
+	}
+
+	if findFilter.After != nil {
+		cursor, err := models.DecodeFindCursor(*findFilter.After)
+		if err != nil {
+			return err
+		}
+
+		if cursor.Sort != sort || cursor.Direction != direction {
+			return fmt.Errorf("cursor was created for sort %s %s", cursor.Sort, cursor.Direction)
+		}
+
+		op := ">"
+		if direction == "DESC" {
+			op = "<"
+		}
+
+		id := strconv.Itoa(cursor.ID)
+		if expr == idCol {
+			qb.keyset = fmt.Sprintf("%s %s %s", idCol, op, id)
+		} else {
+			// the sort value is inlined rather than bound, as the keyset
+			// clause is not included when counting the results
+			value, err := sqlLiteral(cursor.Value)
+			if err != nil {
+				return fmt.Errorf("invalid cursor: %w", err)
+			}
+			qb.keyset = fmt.Sprintf("(%s, %s) %s (%s, %s)", expr, idCol, op, value, id)
+		}
+	}
+
+	qb.cursor = &keysetCursor{
+		filter:    findFilter,
+		sort:      sort,
+		direction: direction,
+	}
+	if expr != idCol {
+		qb.cursor.expr = expr
+	}
+
+	qb.sortAndPagination = " ORDER BY " + expr + " " + direction
+	if expr != idCol {
+		qb.sortAndPagination += ", " + idCol + " " + direction
+	}
+
+	if first := findFilter.GetFirst(); first >= 0 {
+		qb.sortAndPagination += " LIMIT " + strconv.Itoa(first) + " "
+	}
+
+	return nil
+}
+
+// keysetCursor is used to set the cursor of the last result of a query
+// paging with cursors.
+type keysetCursor struct {
+	filter    *models.FindFilterType
+	sort      string
+	direction string
+	// expr is the sort expression, if not sorting by id
+	expr string
+}
+
+// setLastCursor sets the cursor of the last of the provided results on the
+// find filter. The sort value of the last result is included in the
+// cursor, so that the following page may be found even if the result is
+// deleted.
+func (qb queryBuilder) setLastCursor(ctx context.Context, ids []int) error {
+	c := qb.cursor
+	c.filter.LastCursor = nil
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ret := &models.FindCursor{
+		Sort:      c.sort,
+		Direction: c.direction,
+		ID:        ids[len(ids)-1],
+	}
+
+	if c.expr != "" {
+		// the unary plus prevents the driver from converting the value
+		// using the declared type of the column
+		query := fmt.Sprintf("SELECT +(%s) FROM %s WHERE %s = ?", c.expr, qb.repository.tableName, getColumn(qb.repository.tableName, idColumn))
+		var value interface{}
+		if err := qb.repository.tx.Get(ctx, &value, query, ret.ID); err != nil {
+			return fmt.Errorf("getting sort value: %w", err)
+		}
+
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		ret.Value = value
+	}
+
+	c.filter.LastCursor = ret
+	return nil
+}
+
+// sqlLiteral returns the SQL literal of a sort value of a cursor.
+func sqlLiteral(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	default:
+		return "", fmt.Errorf("unsupported sort value %v", v)
+	}
+}
+
+// getCountKeyset returns the keyset expression sorting by the number of
+// rows of joinTable related to the primary table.
+func getCountKeyset(primaryTable, joinTable, primaryFK string) string {
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %s WHERE %s = %s.id)", joinTable, primaryFK, primaryTable)
+}
@@ -0,0 +1,250 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// pageScenes pages through all scenes using cursors, returning the ids of
+// the scenes in the order they were returned.
+func pageScenes(ctx context.Context, t *testing.T, sceneFilter *models.SceneFilterType, findFilter models.FindFilterType) []int {
+	var ret []int
+	for i := 0; ; i++ {
+		if i > totalScenes {
+			t.Fatal("too many pages")
+		}
+
+		result, err := db.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &findFilter,
+				Count:      true,
+			},
+			SceneFilter: sceneFilter,
+		})
+		if err != nil {
+			t.Fatalf("SceneStore.Query() error = %v", err)
+		}
+
+		ret = append(ret, result.IDs...)
+
+		next := findFilter.NextCursor(len(result.IDs))
+		if next == nil {
+			return ret
+		}
+		findFilter.After = next
+	}
+}
+
+func TestSceneQueryCursor(t *testing.T) {
+	first := 7
+	all := -1
+	desc := models.SortDirectionEnumDesc
+
+	tests := []struct {
+		name      string
+		sort      string
+		direction *models.SortDirectionEnum
+	}{
+		{"id", "", nil},
+		{"title", "title", nil},
+		{"date desc", "date", &desc},
+		{"rating desc", "rating", &desc},
+		{"tag count", "tag_count", nil},
+		{"created at", "created_at", nil},
+	}
+
+	for _, tt := range tests {
+		runWithRollbackTxn(t, tt.name, func(t *testing.T, ctx context.Context) {
+			findFilter := models.FindFilterType{
+				First:     &first,
+				Direction: tt.direction,
+			}
+			if tt.sort != "" {
+				findFilter.Sort = &tt.sort
+			}
+
+			got := pageScenes(ctx, t, nil, findFilter)
+
+			findFilter.First = &all
+			want := pageScenes(ctx, t, nil, findFilter)
+
+			assert.Len(t, want, totalScenes)
+			assert.Equal(t, want, got)
+
+			if tt.sort == "" {
+				assert.True(t, sort.IntsAreSorted(got))
+			}
+		})
+	}
+}
+
+func TestSceneQueryCursorDeleted(t *testing.T) {
+	runWithRollbackTxn(t, "deleted", func(t *testing.T, ctx context.Context) {
+		first := 3
+		titleSort := "title"
+		findFilter := models.FindFilterType{
+			First: &first,
+			Sort:  &titleSort,
+		}
+
+		want := pageScenes(ctx, t, nil, findFilter)
+
+		result, err := db.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &findFilter,
+			},
+		})
+		if err != nil {
+			t.Fatalf("SceneStore.Query() error = %v", err)
+		}
+
+		findFilter.After = findFilter.NextCursor(len(result.IDs))
+
+		// the following page is found after the last result is deleted
+		if err := db.Scene.Destroy(ctx, result.IDs[first-1]); err != nil {
+			t.Fatalf("SceneStore.Destroy() error = %v", err)
+		}
+
+		result, err = db.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &findFilter,
+			},
+		})
+		if err != nil {
+			t.Fatalf("SceneStore.Query() error = %v", err)
+		}
+
+		assert.Equal(t, want[first:2*first], result.IDs)
+	})
+}
+
+func TestSceneQueryCursorFiltered(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		first := 1
+		sceneFilter := &models.SceneFilterType{
+			Studios: &models.HierarchicalMultiCriterionInput{
+				Value:    []string{strconv.Itoa(studioIDs[studioIdxWithTwoScenes])},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		}
+
+		findFilter := models.FindFilterType{
+			First: &first,
+		}
+
+		result, err := db.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &findFilter,
+				Count:      true,
+			},
+			SceneFilter: sceneFilter,
+		})
+		if err != nil {
+			t.Errorf("SceneStore.Query() error = %v", err)
+			return nil
+		}
+
+		// the count includes the results before the cursor
+		assert.Equal(t, 2, result.Count)
+		assert.Len(t, result.IDs, 1)
+
+		findFilter.After = findFilter.NextCursor(len(result.IDs))
+		result, err = db.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &findFilter,
+				Count:      true,
+			},
+			SceneFilter: sceneFilter,
+		})
+		if err != nil {
+			t.Errorf("SceneStore.Query() error = %v", err)
+			return nil
+		}
+
+		assert.Equal(t, 2, result.Count)
+		assert.Len(t, result.IDs, 1)
+
+		return nil
+	})
+}
+
+func TestQueryCursorErrors(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		first := 1
+		titleSort := "title"
+		randomSort := "random"
+		cursor := models.FindCursor{Sort: "id", Direction: "ASC", ID: sceneIDs[0]}.Encode()
+		noValue := models.FindCursor{Sort: "title", Direction: "ASC", ID: sceneIDs[0]}.Encode()
+		invalid := "invalid"
+
+		tests := []struct {
+			name   string
+			filter models.FindFilterType
+		}{
+			{"unsupported sort", models.FindFilterType{First: &first, Sort: &randomSort}},
+			{"invalid cursor", models.FindFilterType{First: &first, After: &invalid}},
+			{"different sort", models.FindFilterType{First: &first, Sort: &titleSort, After: &cursor}},
+			{"missing sort value", models.FindFilterType{First: &first, Sort: &titleSort, After: &noValue}},
+		}
+
+		for _, tt := range tests {
+			filter := tt.filter
+			_, err := db.Scene.Query(ctx, models.SceneQueryOptions{
+				QueryOptions: models.QueryOptions{
+					FindFilter: &filter,
+				},
+			})
+			assert.Error(t, err, tt.name)
+		}
+
+		return nil
+	})
+}
+
+func TestPerformerQueryCursor(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		first := 5
+		nameSort := "name"
+		findFilter := models.FindFilterType{
+			First: &first,
+			Sort:  &nameSort,
+		}
+
+		var got []int
+		for {
+			performers, count, err := db.Performer.Query(ctx, nil, &findFilter)
+			if err != nil {
+				t.Errorf("PerformerStore.Query() error = %v", err)
+				return nil
+			}
+
+			assert.Equal(t, totalPerformers, count)
+
+			for _, p := range performers {
+				got = append(got, p.ID)
+			}
+
+			if len(performers) == 0 {
+				break
+			}
+
+			findFilter.After = findFilter.NextCursor(len(performers))
+			if findFilter.After == nil {
+				break
+			}
+		}
+
+		assert.Len(t, got, totalPerformers)
+		assert.ElementsMatch(t, performerIDs, got)
+
+		return nil
+	})
+}
@@ -0,0 +1,19 @@
+-- an orientation of 0 indicates that the metadata has not been read yet
+ALTER TABLE `image_files` ADD COLUMN `orientation` tinyint NOT NULL DEFAULT 0;
+ALTER TABLE `image_files` ADD COLUMN `date_taken` datetime;
+ALTER TABLE `image_files` ADD COLUMN `camera_make` varchar(255);
+ALTER TABLE `image_files` ADD COLUMN `camera_model` varchar(255);
+ALTER TABLE `image_files` ADD COLUMN `lens` varchar(255);
+ALTER TABLE `image_files` ADD COLUMN `latitude` float;
+ALTER TABLE `image_files` ADD COLUMN `longitude` float;
+ALTER TABLE `image_files` ADD COLUMN `altitude` float;
+ALTER TABLE `image_files` ADD COLUMN `title` varchar(255);
+
+CREATE TABLE `image_file_keywords` (
+  `file_id` integer NOT NULL,
+  `keyword` varchar(255) NOT NULL,
+  foreign key(`file_id`) references `files`(`id`) on delete CASCADE,
+  PRIMARY KEY(`file_id`, `keyword`)
+);
+
+CREATE INDEX `image_file_keywords_keyword` on `image_file_keywords` (`keyword`);
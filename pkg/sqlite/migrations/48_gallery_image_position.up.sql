@@ -0,0 +1,37 @@
+PRAGMA foreign_keys=OFF;
+
+CREATE TABLE `galleries_images_new` (
+  `gallery_id` integer NOT NULL,
+  `image_id` integer NOT NULL,
+  `position` integer NOT NULL DEFAULT 0,
+  foreign key(`gallery_id`) references `galleries`(`id`) on delete CASCADE,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE,
+  PRIMARY KEY(`gallery_id`, `image_id`)
+);
+
+-- initialise the position of existing images using the default path sort order
+INSERT INTO `galleries_images_new`
+  (
+    `gallery_id`,
+    `image_id`,
+    `position`
+  )
+  SELECT
+    `galleries_images`.`gallery_id`,
+    `galleries_images`.`image_id`,
+    ROW_NUMBER() OVER (
+      PARTITION BY `galleries_images`.`gallery_id`
+      ORDER BY COALESCE(`folders`.`path`, '') || COALESCE(`files`.`basename`, '') COLLATE NATURAL_CI, `galleries_images`.`image_id`
+    )
+  FROM `galleries_images`
+  LEFT JOIN `images_files` ON `images_files`.`image_id` = `galleries_images`.`image_id` AND `images_files`.`primary` = 1
+  LEFT JOIN `files` ON `files`.`id` = `images_files`.`file_id`
+  LEFT JOIN `folders` ON `folders`.`id` = `files`.`parent_folder_id`;
+
+DROP TABLE `galleries_images`;
+ALTER TABLE `galleries_images_new` rename to `galleries_images`;
+
+CREATE INDEX `index_galleries_images_on_image_id` on `galleries_images` (`image_id`);
+CREATE INDEX `index_galleries_images_on_gallery_id_position` on `galleries_images` (`gallery_id`, `position`);
+
+PRAGMA foreign_keys=ON;
@@ -0,0 +1,42 @@
+CREATE TABLE `performer_image_blobs` (
+  `id` integer not null primary key autoincrement,
+  `performer_id` integer not null,
+  `image_blob` varchar(255) not null,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE,
+  foreign key(`image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `performer_image_blobs_unique` on `performer_image_blobs` (`performer_id`, `image_blob`);
+CREATE INDEX `index_performer_image_blobs_on_image_blob` on `performer_image_blobs` (`image_blob`);
+
+CREATE TABLE `studio_image_blobs` (
+  `id` integer not null primary key autoincrement,
+  `studio_id` integer not null,
+  `image_blob` varchar(255) not null,
+  foreign key(`studio_id`) references `studios`(`id`) on delete CASCADE,
+  foreign key(`image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `studio_image_blobs_unique` on `studio_image_blobs` (`studio_id`, `image_blob`);
+CREATE INDEX `index_studio_image_blobs_on_image_blob` on `studio_image_blobs` (`image_blob`);
+
+CREATE TABLE `tag_image_blobs` (
+  `id` integer not null primary key autoincrement,
+  `tag_id` integer not null,
+  `image_blob` varchar(255) not null,
+  foreign key(`tag_id`) references `tags`(`id`) on delete CASCADE,
+  foreign key(`image_blob`) references `blobs`(`checksum`)
+);
+
+CREATE UNIQUE INDEX `tag_image_blobs_unique` on `tag_image_blobs` (`tag_id`, `image_blob`);
+CREATE INDEX `index_tag_image_blobs_on_image_blob` on `tag_image_blobs` (`image_blob`);
+
+-- existing images become the primary image of each collection
+INSERT INTO `performer_image_blobs` (`performer_id`, `image_blob`)
+  SELECT `id`, `image_blob` FROM `performers` WHERE `image_blob` IS NOT NULL;
+
+INSERT INTO `studio_image_blobs` (`studio_id`, `image_blob`)
+  SELECT `id`, `image_blob` FROM `studios` WHERE `image_blob` IS NOT NULL;
+
+INSERT INTO `tag_image_blobs` (`tag_id`, `image_blob`)
+  SELECT `id`, `image_blob` FROM `tags` WHERE `image_blob` IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE `edits` (
+  `id` integer not null primary key autoincrement,
+  `entity_type` varchar(255) not null,
+  `entity_id` integer not null,
+  `operation` varchar(255) not null,
+  `source` varchar(255) not null,
+  `changes` text not null,
+  `created_at` datetime not null,
+  `reverted_at` datetime
+);
+
+CREATE INDEX `index_edits_on_entity_type_entity_id` on `edits` (`entity_type`, `entity_id`);
@@ -0,0 +1 @@
+ALTER TABLE `saved_filters` ADD COLUMN `query` text not null default '';
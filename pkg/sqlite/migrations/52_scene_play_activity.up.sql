@@ -0,0 +1,15 @@
+CREATE TABLE `scenes_play_activity` (
+  `id` integer not null primary key autoincrement,
+  `scene_id` integer not null,
+  `played_at` datetime not null,
+  `duration` float not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scenes_play_activity_on_scene_id` on `scenes_play_activity` (`scene_id`);
+CREATE INDEX `index_scenes_play_activity_on_played_at` on `scenes_play_activity` (`played_at`);
+
+-- existing play durations are attributed to the last time the scene was played
+INSERT INTO `scenes_play_activity` (`scene_id`, `played_at`, `duration`)
+  SELECT `id`, `last_played_at`, `play_duration` FROM `scenes`
+  WHERE `play_duration` > 0 AND `last_played_at` IS NOT NULL;
@@ -0,0 +1,31 @@
+CREATE TABLE `playlists` (
+  `id` integer not null primary key autoincrement,
+  `name` varchar(255) not null,
+  `description` text,
+  `saved_filter_id` integer,
+  `filter_limit` integer,
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  foreign key(`saved_filter_id`) references `saved_filters`(`id`) on delete SET NULL
+);
+
+CREATE INDEX `index_playlists_on_name` on `playlists` (`name`);
+
+CREATE TABLE `playlists_items` (
+  `id` integer not null primary key autoincrement,
+  `playlist_id` integer not null,
+  `position` integer not null,
+  `scene_id` integer,
+  `scene_marker_id` integer,
+  `image_id` integer,
+  foreign key(`playlist_id`) references `playlists`(`id`) on delete CASCADE,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE,
+  foreign key(`scene_marker_id`) references `scene_markers`(`id`) on delete CASCADE,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE,
+  CHECK ((`scene_id` IS NOT NULL) + (`scene_marker_id` IS NOT NULL) + (`image_id` IS NOT NULL) = 1)
+);
+
+CREATE INDEX `index_playlists_items_on_playlist_id_position` on `playlists_items` (`playlist_id`, `position`);
+CREATE INDEX `index_playlists_items_on_scene_id` on `playlists_items` (`scene_id`);
+CREATE INDEX `index_playlists_items_on_scene_marker_id` on `playlists_items` (`scene_marker_id`);
+CREATE INDEX `index_playlists_items_on_image_id` on `playlists_items` (`image_id`);
@@ -0,0 +1,16 @@
+CREATE TABLE `tag_implications` (
+  `id` integer not null primary key autoincrement,
+  `source_tag_id` integer,
+  `source_performer_id` integer,
+  `implied_tag_id` integer not null,
+  `created_at` datetime not null,
+  foreign key(`source_tag_id`) references `tags`(`id`) on delete CASCADE,
+  foreign key(`source_performer_id`) references `performers`(`id`) on delete CASCADE,
+  foreign key(`implied_tag_id`) references `tags`(`id`) on delete CASCADE,
+  CHECK ((`source_tag_id` IS NOT NULL) + (`source_performer_id` IS NOT NULL) = 1),
+  CHECK (`source_tag_id` IS NULL OR `source_tag_id` != `implied_tag_id`)
+);
+
+CREATE UNIQUE INDEX `index_tag_implications_on_source_tag_id_implied_tag_id` on `tag_implications` (`source_tag_id`, `implied_tag_id`) WHERE `source_tag_id` IS NOT NULL;
+CREATE UNIQUE INDEX `index_tag_implications_on_source_performer_id_implied_tag_id` on `tag_implications` (`source_performer_id`, `implied_tag_id`) WHERE `source_performer_id` IS NOT NULL;
+CREATE INDEX `index_tag_implications_on_implied_tag_id` on `tag_implications` (`implied_tag_id`);
@@ -0,0 +1,15 @@
+CREATE TABLE `scenes_users` (
+  `scene_id` integer not null,
+  `user_id` integer not null,
+  `rating` tinyint,
+  `o_counter` tinyint not null default 0,
+  `resume_time` float not null default 0,
+  `play_duration` float not null default 0,
+  `play_count` tinyint not null default 0,
+  `last_played_at` datetime default null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE,
+  foreign key(`user_id`) references `users`(`id`) on delete CASCADE,
+  PRIMARY KEY(`scene_id`, `user_id`)
+);
+
+CREATE INDEX `index_scenes_users_on_user_id` on `scenes_users` (`user_id`);
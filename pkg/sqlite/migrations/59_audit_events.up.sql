@@ -0,0 +1,15 @@
+CREATE TABLE `audit_events` (
+  `id` integer not null primary key autoincrement,
+  `action` varchar(255) not null,
+  `username` varchar(255) not null,
+  `api_key_name` varchar(255),
+  `ip_address` varchar(255) not null,
+  `operation` varchar(255) not null,
+  `target_ids` text not null,
+  `arguments` text,
+  `error` text,
+  `created_at` datetime not null
+);
+
+CREATE INDEX `index_audit_events_on_created_at` on `audit_events` (`created_at`);
+CREATE INDEX `index_audit_events_on_username` on `audit_events` (`username`);
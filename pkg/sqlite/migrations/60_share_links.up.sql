@@ -0,0 +1,19 @@
+CREATE TABLE `share_links` (
+  `id` integer not null primary key autoincrement,
+  `user_id` integer,
+  `scene_id` integer,
+  `gallery_id` integer,
+  `image_id` integer,
+  `allow_download` boolean not null default '0',
+  `expires_at` datetime not null,
+  `created_at` datetime not null,
+  foreign key(`user_id`) references `users`(`id`) on delete CASCADE,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE,
+  foreign key(`gallery_id`) references `galleries`(`id`) on delete CASCADE,
+  foreign key(`image_id`) references `images`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_share_links_on_user_id` on `share_links` (`user_id`);
+CREATE INDEX `index_share_links_on_scene_id` on `share_links` (`scene_id`);
+CREATE INDEX `index_share_links_on_gallery_id` on `share_links` (`gallery_id`);
+CREATE INDEX `index_share_links_on_image_id` on `share_links` (`image_id`);
@@ -0,0 +1,4 @@
+ALTER TABLE `users` ADD COLUMN `totp_secret` varchar(255) not null default '';
+ALTER TABLE `users` ADD COLUMN `totp_enabled` boolean not null default '0';
+ALTER TABLE `users` ADD COLUMN `totp_last_step` integer not null default 0;
+ALTER TABLE `users` ADD COLUMN `recovery_code_hashes` text not null default '';
@@ -0,0 +1,3 @@
+ALTER TABLE `users` ADD COLUMN `oidc_issuer` varchar(255);
+ALTER TABLE `users` ADD COLUMN `oidc_subject` varchar(255);
+CREATE UNIQUE INDEX `index_users_on_oidc_issuer_oidc_subject` on `users` (`oidc_issuer`, `oidc_subject`);
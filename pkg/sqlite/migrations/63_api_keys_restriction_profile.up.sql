@@ -0,0 +1 @@
+ALTER TABLE `api_keys` ADD COLUMN `restriction_profile_id` integer REFERENCES `restriction_profiles`(`id`) ON DELETE SET NULL;
@@ -0,0 +1 @@
+ALTER TABLE `share_links` ADD COLUMN `restriction_profile_id` integer REFERENCES `restriction_profiles`(`id`) ON DELETE RESTRICT;
@@ -0,0 +1,3 @@
+ALTER TABLE `restriction_profiles` ADD COLUMN `scene_filter` text;
+ALTER TABLE `restriction_profiles` ADD COLUMN `image_filter` text;
+ALTER TABLE `restriction_profiles` ADD COLUMN `gallery_filter` text;
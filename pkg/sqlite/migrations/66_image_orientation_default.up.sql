@@ -0,0 +1,4 @@
+-- images scanned before metadata extraction was added are treated as having
+-- the normal orientation, so that they are not all re-read on the next scan.
+-- An orientation of 0 now only indicates a file that has never been scanned.
+UPDATE `image_files` SET `orientation` = 1 WHERE `orientation` = 0;
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 )
 
 const (
@@ -139,6 +141,8 @@ func (qb *MovieStore) Create(ctx context.Context, newObject *models.Movie) error
 		return err
 	}
 
+	movieEditTracker.trackCreate(ctx, id)
+
 	updated, err := qb.find(ctx, id)
 	if err != nil {
 		return fmt.Errorf("finding after create: %w", err)
@@ -150,6 +154,10 @@ func (qb *MovieStore) Create(ctx context.Context, newObject *models.Movie) error
 }
 
 func (qb *MovieStore) UpdatePartial(ctx context.Context, id int, partial models.MoviePartial) (*models.Movie, error) {
+	if err := movieEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := movieRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -168,6 +176,10 @@ func (qb *MovieStore) UpdatePartial(ctx context.Context, id int, partial models.
 }
 
 func (qb *MovieStore) Update(ctx context.Context, updatedObject *models.Movie) error {
+	if err := movieEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r movieRow
 	r.fromMovie(*updatedObject)
 
@@ -187,6 +199,76 @@ func (qb *MovieStore) Destroy(ctx context.Context, id int) error {
 	return qb.destroyExisting(ctx, []int{id})
 }
 
+// Merge reassigns the scenes of the source movies to the destination movie,
+// adds the source names and aliases to the destination aliases and then
+// destroys the source movies.
+func (qb *MovieStore) Merge(ctx context.Context, source []int, destination int) error {
+	if err := movieEditTracker.track(ctx, destination); err != nil {
+		return err
+	}
+
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := validateMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	dest, err := qb.find(ctx, destination)
+	if err != nil {
+		return fmt.Errorf("finding destination movie: %w", err)
+	}
+
+	sources, err := qb.FindMany(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	aliases := splitMovieAliases(dest.Aliases)
+	for _, m := range sources {
+		aliases = append(aliases, m.Name)
+		aliases = append(aliases, splitMovieAliases(m.Aliases)...)
+	}
+
+	var merged []string
+	for _, a := range aliases {
+		if a != dest.Name && !stringslice.StrInclude(merged, a) {
+			merged = append(merged, a)
+		}
+	}
+
+	if err := qb.tableMgr.updateByID(ctx, destination, goqu.Record{
+		"aliases": zero.StringFrom(strings.Join(merged, ", ")),
+	}); err != nil {
+		return err
+	}
+
+	if err := mergeJoins(ctx, moviesScenesTable, movieIDColumn, []string{sceneIDColumn}, []string{"scene_index"}, source, destination); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		if err := qb.Destroy(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitMovieAliases(aliases string) []string {
+	var ret []string
+	for _, a := range strings.Split(aliases, ",") {
+		a = strings.TrimSpace(a)
+		if a != "" {
+			ret = append(ret, a)
+		}
+	}
+
+	return ret
+}
+
 // returns nil, nil if not found
 func (qb *MovieStore) Find(ctx context.Context, id int) (*models.Movie, error) {
 	ret, err := qb.find(ctx, id)
@@ -363,7 +445,9 @@ func (qb *MovieStore) makeQuery(ctx context.Context, movieFilter *models.MovieFi
 	query := qb.newQuery()
 	distinctIDs(&query, movieTable)
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		searchColumns := []string{"movies.name"}
 		query.parseQueryString(searchColumns, *q)
 	}
@@ -374,7 +458,13 @@ func (qb *MovieStore) makeQuery(ctx context.Context, movieFilter *models.MovieFi
 		return nil, err
 	}
 
-	query.sortAndPagination = qb.getMovieSort(findFilter) + getPagination(findFilter)
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, movieKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		query.sortAndPagination = qb.getMovieSort(&query, findFilter) + getPagination(findFilter)
+	}
 
 	return &query, nil
 }
@@ -398,6 +488,20 @@ func (qb *MovieStore) Query(ctx context.Context, movieFilter *models.MovieFilter
 	return movies, countResult, nil
 }
 
+var movieFacets = facetDefinitions{
+	models.FacetTypeStudio: studioFacet(movieTable),
+	models.FacetTypeYear:   yearFacet(movieTable, "date"),
+}
+
+func (qb *MovieStore) Facets(ctx context.Context, movieFilter *models.MovieFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, movieFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, movieFacets, options)
+}
+
 func (qb *MovieStore) QueryCount(ctx context.Context, movieFilter *models.MovieFilterType, findFilter *models.FindFilterType) (int, error) {
 	query, err := qb.makeQuery(ctx, movieFilter, findFilter)
 	if err != nil {
@@ -472,7 +576,17 @@ func moviePerformersCriterionHandler(qb *MovieStore, performers *models.MultiCri
 	}
 }
 
-func (qb *MovieStore) getMovieSort(findFilter *models.FindFilterType) string {
+var movieKeysetSorts = keysetSorts{
+	"name":         "COALESCE(movies.name, '') COLLATE NATURAL_CI",
+	"date":         "COALESCE(movies.date, '')",
+	"rating":       "COALESCE(movies.rating, 0)",
+	"duration":     "COALESCE(movies.duration, 0)",
+	"created_at":   "movies.created_at",
+	"updated_at":   "movies.updated_at",
+	"scenes_count": getCountKeyset(movieTable, moviesScenesTable, movieIDColumn),
+}
+
+func (qb *MovieStore) getMovieSort(query *queryBuilder, findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
 	if findFilter == nil {
@@ -483,10 +597,16 @@ func (qb *MovieStore) getMovieSort(findFilter *models.FindFilterType) string {
 		direction = findFilter.GetDirection()
 	}
 
+	if sort == relevanceSort && query.fullTextSearch == "" {
+		sort = "name"
+	}
+
 	sortQuery := ""
 	switch sort {
 	case "scenes_count": // generic getSort won't work for this
 		sortQuery += getCountSort(movieTable, moviesScenesTable, movieIDColumn, direction)
+	case relevanceSort:
+		sortQuery += query.getRelevanceSort(direction)
 	default:
 		sortQuery += getSort(sort, direction, "movies")
 	}
@@ -551,12 +671,32 @@ func (qb *MovieStore) HasBackImage(ctx context.Context, movieID int) (bool, erro
 	return qb.HasImage(ctx, movieID, movieBackImageBlobColumn)
 }
 
+// moviesByPerformerRestriction returns the condition counting only the visible
+// scenes of performers, so that hidden scenes do not reveal their movies.
+func moviesByPerformerRestriction(ctx context.Context) string {
+	if condition := relatedRestrictionCondition(sceneTable, "movies_scenes.scene_id", sceneRestrictionCondition(ctx)); condition != "" {
+		return " AND " + condition
+	}
+
+	return ""
+}
+
+// moviesByStudioRestriction returns the condition excluding the movies of
+// hidden studios.
+func moviesByStudioRestriction(ctx context.Context) string {
+	if condition := relatedRestrictionCondition(studioTable, "movies.studio_id", studioRestrictionCondition(ctx)); condition != "" {
+		return " AND " + condition
+	}
+
+	return ""
+}
+
 func (qb *MovieStore) FindByPerformerID(ctx context.Context, performerID int) ([]*models.Movie, error) {
 	query := `SELECT DISTINCT movies.*
 FROM movies
 INNER JOIN movies_scenes ON movies.id = movies_scenes.movie_id
 INNER JOIN performers_scenes ON performers_scenes.scene_id = movies_scenes.scene_id
-WHERE performers_scenes.performer_id = ?
+WHERE performers_scenes.performer_id = ?` + moviesByPerformerRestriction(ctx) + `
 `
 	args := []interface{}{performerID}
 	return qb.queryMovies(ctx, query, args)
@@ -566,7 +706,7 @@ func (qb *MovieStore) CountByPerformerID(ctx context.Context, performerID int) (
 	query := `SELECT COUNT(DISTINCT movies_scenes.movie_id) AS count
 FROM movies_scenes
 INNER JOIN performers_scenes ON performers_scenes.scene_id = movies_scenes.scene_id
-WHERE performers_scenes.performer_id = ?
+WHERE performers_scenes.performer_id = ?` + moviesByPerformerRestriction(ctx) + `
 `
 	args := []interface{}{performerID}
 	return qb.runCountQuery(ctx, query, args)
@@ -575,7 +715,7 @@ WHERE performers_scenes.performer_id = ?
 func (qb *MovieStore) FindByStudioID(ctx context.Context, studioID int) ([]*models.Movie, error) {
 	query := `SELECT movies.*
 FROM movies
-WHERE movies.studio_id = ?
+WHERE movies.studio_id = ?` + moviesByStudioRestriction(ctx) + `
 `
 	args := []interface{}{studioID}
 	return qb.queryMovies(ctx, query, args)
@@ -584,7 +724,7 @@ WHERE movies.studio_id = ?
 func (qb *MovieStore) CountByStudioID(ctx context.Context, studioID int) (int, error) {
 	query := `SELECT COUNT(1) AS count
 FROM movies
-WHERE movies.studio_id = ?
+WHERE movies.studio_id = ?` + moviesByStudioRestriction(ctx) + `
 `
 	args := []interface{}{studioID}
 	return qb.runCountQuery(ctx, query, args)
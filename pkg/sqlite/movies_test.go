@@ -333,3 +333,58 @@ func TestMovieUpdateBackImage(t *testing.T) {
 // TODO Count
 // TODO All
 // TODO Query
+
+func TestMovieStore_Merge(t *testing.T) {
+	assert := assert.New(t)
+
+	// perform in a transaction that we'll rollback
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Movie
+
+		destID := movieIDs[movieIdxWithStudio]
+		srcID := movieIDs[movieIdxWithScene]
+
+		// try merging into same movie
+		err := qb.Merge(ctx, []int{destID}, destID)
+		assert.NotNil(err)
+
+		src, err := qb.Find(ctx, srcID)
+		if err != nil {
+			return err
+		}
+
+		if err := qb.Merge(ctx, []int{srcID}, destID); err != nil {
+			return err
+		}
+
+		deleted, err := qb.Find(ctx, srcID)
+		if err != nil {
+			return err
+		}
+		assert.Nil(deleted)
+
+		dest, err := qb.Find(ctx, destID)
+		if err != nil {
+			return err
+		}
+		assert.Contains(dest.Aliases, src.Name)
+
+		scene, err := db.Scene.Find(ctx, sceneIDs[sceneIdxWithMovie])
+		if err != nil {
+			return err
+		}
+		if err := scene.LoadMovies(ctx, db.Scene); err != nil {
+			return err
+		}
+
+		var sceneMovieIDs []int
+		for _, m := range scene.Movies.List() {
+			sceneMovieIDs = append(sceneMovieIDs, m.MovieID)
+		}
+		assert.Equal([]int{destID}, sceneMovieIDs)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -26,6 +26,7 @@ const (
 	performersTagsTable    = "performers_tags"
 
 	performerImageBlobColumn = "image_blob"
+	performerImageBlobsTable = "performer_image_blobs"
 )
 
 type performerRow struct {
@@ -178,7 +179,7 @@ func (r *performerRowRecord) fromPartial(o models.PerformerPartial) {
 
 type PerformerStore struct {
 	repository
-	blobJoinQueryBuilder
+	imageCollectionQueryBuilder
 
 	tableMgr *table
 }
@@ -189,9 +190,14 @@ func NewPerformerStore(blobStore *BlobStore) *PerformerStore {
 			tableName: performerTable,
 			idColumn:  idColumn,
 		},
-		blobJoinQueryBuilder: blobJoinQueryBuilder{
-			blobStore: blobStore,
-			joinTable: performerTable,
+		imageCollectionQueryBuilder: imageCollectionQueryBuilder{
+			blobJoinQueryBuilder: blobJoinQueryBuilder{
+				blobStore: blobStore,
+				joinTable: performerTable,
+			},
+			blobCol:         performerImageBlobColumn,
+			collectionTable: performerImageBlobsTable,
+			fkColumn:        performerIDColumn,
 		},
 		tableMgr: performerTableMgr,
 	}
@@ -214,6 +220,8 @@ func (qb *PerformerStore) Create(ctx context.Context, newObject *models.Performe
 		return err
 	}
 
+	performerEditTracker.trackCreate(ctx, id)
+
 	if newObject.Aliases.Loaded() {
 		if err := performersAliasesTableMgr.insertJoins(ctx, id, newObject.Aliases.List()); err != nil {
 			return err
@@ -243,6 +251,10 @@ func (qb *PerformerStore) Create(ctx context.Context, newObject *models.Performe
 }
 
 func (qb *PerformerStore) UpdatePartial(ctx context.Context, id int, partial models.PerformerPartial) (*models.Performer, error) {
+	if err := performerEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := performerRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -278,6 +290,10 @@ func (qb *PerformerStore) UpdatePartial(ctx context.Context, id int, partial mod
 }
 
 func (qb *PerformerStore) Update(ctx context.Context, updatedObject *models.Performer) error {
+	if err := performerEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r performerRow
 	r.fromPerformer(*updatedObject)
 
@@ -315,6 +331,69 @@ func (qb *PerformerStore) Destroy(ctx context.Context, id int) error {
 	return qb.destroyExisting(ctx, []int{id})
 }
 
+// Merge reassigns the scenes, images, galleries, tags, aliases and stash ids
+// of the source performers to the destination performer, adds the source
+// names as aliases and then destroys the source performers.
+func (qb *PerformerStore) Merge(ctx context.Context, source []int, destination int) error {
+	if err := performerEditTracker.track(ctx, destination); err != nil {
+		return err
+	}
+
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := validateMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	joins := map[string]string{
+		performersScenesTable:    sceneIDColumn,
+		performersImagesTable:    imageIDColumn,
+		performersGalleriesTable: galleryIDColumn,
+		performersTagsTable:      tagIDColumn,
+	}
+
+	for table, keyColumn := range joins {
+		if err := mergeJoins(ctx, table, performerIDColumn, []string{keyColumn}, nil, source, destination); err != nil {
+			return err
+		}
+	}
+
+	// add the source names as aliases, ignoring any that match the destination name
+	args := []interface{}{destination}
+	for _, id := range source {
+		args = append(args, id)
+	}
+	args = append(args, destination)
+
+	if _, err := qb.tx.Exec(ctx, `INSERT OR IGNORE INTO `+performersAliasesTable+` (performer_id, alias)
+SELECT ?, name FROM `+performerTable+` WHERE id IN `+getInBinding(len(source))+`
+AND name != (SELECT name FROM `+performerTable+` WHERE id = ?)`, args...); err != nil {
+		return err
+	}
+
+	if err := mergeJoins(ctx, performersAliasesTable, performerIDColumn, []string{performerAliasColumn}, nil, source, destination); err != nil {
+		return err
+	}
+
+	if err := mergeJoins(ctx, "performer_stash_ids", performerIDColumn, []string{"endpoint", "stash_id"}, nil, source, destination); err != nil {
+		return err
+	}
+
+	if err := qb.mergeImages(ctx, source, destination); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		if err := qb.Destroy(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // returns nil, nil if not found
 func (qb *PerformerStore) Find(ctx context.Context, id int) (*models.Performer, error) {
 	ret, err := qb.find(ctx, id)
@@ -345,9 +424,12 @@ func (qb *PerformerStore) FindMany(ctx context.Context, ids []int) ([]*models.Pe
 		return nil, err
 	}
 
-	for i := range ret {
-		if ret[i] == nil {
-			return nil, fmt.Errorf("performer with id %d not found", ids[i])
+	// content hidden by a restriction is left nil, as if it did not exist
+	if !models.IsRestricted(ctx) {
+		for i := range ret {
+			if ret[i] == nil {
+				return nil, fmt.Errorf("performer with id %d not found", ids[i])
+			}
 		}
 	}
 
@@ -393,6 +475,8 @@ func (qb *PerformerStore) get(ctx context.Context, q *goqu.SelectDataset) (*mode
 }
 
 func (qb *PerformerStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Performer, error) {
+	q = restrictDataset(q, performerRestrictionCondition(ctx))
+
 	const single = false
 	var ret []*models.Performer
 	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
@@ -479,11 +563,13 @@ func (qb *PerformerStore) CountByTagID(ctx context.Context, tagID int) (int, err
 	joinTable := performersTagsJoinTable
 
 	q := dialect.Select(goqu.COUNT("*")).From(joinTable).Where(joinTable.Col(tagIDColumn).Eq(tagID))
+	q = restrictDataset(q, relatedRestrictionCondition(performerTable, "performers_tags.performer_id", performerRestrictionCondition(ctx)))
 	return count(ctx, q)
 }
 
 func (qb *PerformerStore) Count(ctx context.Context) (int, error) {
 	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	q = restrictDataset(q, performerRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -685,8 +771,11 @@ func (qb *PerformerStore) makeQuery(ctx context.Context, performerFilter *models
 
 	query := qb.newQuery()
 	distinctIDs(&query, performerTable)
+	query.addWhere(performerRestrictionCondition(ctx))
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		query.join(performersAliasesTable, "", "performer_aliases.performer_id = performers.id")
 		searchColumns := []string{"performers.name", "performer_aliases.alias"}
 		query.parseQueryString(searchColumns, *q)
@@ -701,7 +790,13 @@ func (qb *PerformerStore) makeQuery(ctx context.Context, performerFilter *models
 		return nil, err
 	}
 
-	query.sortAndPagination = qb.getPerformerSort(findFilter) + getPagination(findFilter)
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, performerKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		query.sortAndPagination = qb.getPerformerSort(&query, findFilter) + getPagination(findFilter)
+	}
 
 	return &query, nil
 }
@@ -725,6 +820,23 @@ func (qb *PerformerStore) Query(ctx context.Context, performerFilter *models.Per
 	return performers, countResult, nil
 }
 
+var performerFacets = facetDefinitions{
+	models.FacetTypeTag:       relatedFacet(performersTagsTable, performerIDColumn, tagTable, tagIDColumn, "name"),
+	models.FacetTypeGender:    columnFacet(performerTable, "gender"),
+	models.FacetTypeCountry:   columnFacet(performerTable, "country"),
+	models.FacetTypeEthnicity: columnFacet(performerTable, "ethnicity"),
+	models.FacetTypeYear:      yearFacet(performerTable, "birthdate"),
+}
+
+func (qb *PerformerStore) Facets(ctx context.Context, performerFilter *models.PerformerFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, performerFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, performerFacets, options)
+}
+
 func (qb *PerformerStore) QueryCount(ctx context.Context, performerFilter *models.PerformerFilterType, findFilter *models.FindFilterType) (int, error) {
 	query, err := qb.makeQuery(ctx, performerFilter, findFilter)
 	if err != nil {
@@ -994,7 +1106,19 @@ func performerAppearsWithCriterionHandler(qb *PerformerStore, performers *models
 	}
 }
 
-func (qb *PerformerStore) getPerformerSort(findFilter *models.FindFilterType) string {
+var performerKeysetSorts = keysetSorts{
+	"name":            "COALESCE(performers.name, '') COLLATE NATURAL_CI",
+	"birthdate":       "COALESCE(performers.birthdate, '')",
+	"rating":          "COALESCE(performers.rating, 0)",
+	"created_at":      "performers.created_at",
+	"updated_at":      "performers.updated_at",
+	"tag_count":       getCountKeyset(performerTable, performersTagsTable, performerIDColumn),
+	"scenes_count":    getCountKeyset(performerTable, performersScenesTable, performerIDColumn),
+	"images_count":    getCountKeyset(performerTable, performersImagesTable, performerIDColumn),
+	"galleries_count": getCountKeyset(performerTable, performersGalleriesTable, performerIDColumn),
+}
+
+func (qb *PerformerStore) getPerformerSort(query *queryBuilder, findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
 	if findFilter == nil {
@@ -1005,6 +1129,10 @@ func (qb *PerformerStore) getPerformerSort(findFilter *models.FindFilterType) st
 		direction = findFilter.GetDirection()
 	}
 
+	if sort == relevanceSort && query.fullTextSearch == "" {
+		sort = "name"
+	}
+
 	sortQuery := ""
 	switch sort {
 	case "tag_count":
@@ -1015,6 +1143,8 @@ func (qb *PerformerStore) getPerformerSort(findFilter *models.FindFilterType) st
 		sortQuery += getCountSort(performerTable, performersImagesTable, performerIDColumn, direction)
 	case "galleries_count":
 		sortQuery += getCountSort(performerTable, performersGalleriesTable, performerIDColumn, direction)
+	case relevanceSort:
+		sortQuery += query.getRelevanceSort(direction)
 	default:
 		sortQuery += getSort(sort, direction, "performers")
 	}
@@ -1051,11 +1181,31 @@ func (qb *PerformerStore) HasImage(ctx context.Context, performerID int) (bool,
 }
 
 func (qb *PerformerStore) UpdateImage(ctx context.Context, performerID int, image []byte) error {
-	return qb.blobJoinQueryBuilder.UpdateImage(ctx, performerID, performerImageBlobColumn, image)
+	return qb.updatePrimaryImage(ctx, performerID, image)
 }
 
 func (qb *PerformerStore) destroyImage(ctx context.Context, performerID int) error {
-	return qb.blobJoinQueryBuilder.DestroyImage(ctx, performerID, performerImageBlobColumn)
+	return qb.destroyImages(ctx, performerID)
+}
+
+func (qb *PerformerStore) GetImages(ctx context.Context, performerID int) ([]models.EntityImage, error) {
+	return qb.getImages(ctx, performerID)
+}
+
+func (qb *PerformerStore) GetImageByID(ctx context.Context, performerID int, imageID int) ([]byte, error) {
+	return qb.getImageByID(ctx, performerID, imageID)
+}
+
+func (qb *PerformerStore) AddImage(ctx context.Context, performerID int, image []byte, primary bool) (int, error) {
+	return qb.addImage(ctx, performerID, image, primary)
+}
+
+func (qb *PerformerStore) SetPrimaryImage(ctx context.Context, performerID int, imageID int) error {
+	return qb.setPrimaryImage(ctx, performerID, imageID)
+}
+
+func (qb *PerformerStore) DestroyImageByID(ctx context.Context, performerID int, imageID int) error {
+	return qb.destroyImageByID(ctx, performerID, imageID)
 }
 
 func (qb *PerformerStore) stashIDRepository() *stashIDRepository {
@@ -1128,6 +1128,85 @@ func verifyFloat(t *testing.T, value *float64, criterion models.FloatCriterionIn
 	return false
 }
 
+func TestPerformerStore_Merge(t *testing.T) {
+	assert := assert.New(t)
+
+	// perform in a transaction that we'll rollback
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Performer
+
+		destID := performerIDs[performerIdx1WithScene]
+
+		// try merging into same performer
+		err := qb.Merge(ctx, []int{destID}, destID)
+		assert.NotNil(err)
+
+		srcIdxs := []int{
+			performerIdx2WithScene,
+			performerIdxWithTwoImages,
+			performerIdxWithTwoGalleries,
+		}
+
+		var srcIDs []int
+		var srcNames []string
+		for _, idx := range srcIdxs {
+			p, err := qb.Find(ctx, performerIDs[idx])
+			if err != nil {
+				return err
+			}
+
+			srcIDs = append(srcIDs, p.ID)
+			srcNames = append(srcNames, p.Name)
+		}
+
+		if err := qb.Merge(ctx, srcIDs, destID); err != nil {
+			return err
+		}
+
+		// ensure source performers are deleted
+		for _, id := range srcIDs {
+			p, err := qb.Find(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			assert.Nil(p)
+		}
+
+		aliases, err := qb.GetAliases(ctx, destID)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range srcNames {
+			assert.Contains(aliases, name)
+		}
+
+		// scene shared by the source and destination should only reference the destination once
+		scenePerformerIDs, err := db.Scene.GetPerformerIDs(ctx, sceneIDs[sceneIdxWithTwoPerformers])
+		if err != nil {
+			return err
+		}
+		assert.Equal([]int{destID}, scenePerformerIDs)
+
+		imagePerformerIDs, err := db.Image.GetPerformerIDs(ctx, imageIDs[imageIdx1WithPerformer])
+		if err != nil {
+			return err
+		}
+		assert.Contains(imagePerformerIDs, destID)
+
+		galleryPerformerIDs, err := db.Gallery.GetPerformerIDs(ctx, galleryIDs[galleryIdx1WithPerformer])
+		if err != nil {
+			return err
+		}
+		assert.Contains(galleryPerformerIDs, destID)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestPerformerQueryForAutoTag(t *testing.T) {
 	withTxn(func(ctx context.Context) error {
 		tqb := db.Performer
@@ -1168,6 +1247,23 @@ func TestPerformerUpdatePerformerImage(t *testing.T) {
 	}
 }
 
+func TestPerformerImages(t *testing.T) {
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Performer
+
+		performer := models.Performer{
+			Name: "TestPerformerImages",
+		}
+		if err := qb.Create(ctx, &performer); err != nil {
+			return fmt.Errorf("Error creating performer: %s", err.Error())
+		}
+
+		return testImageCollection(t, ctx, performer.ID, qb)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestPerformerQueryAge(t *testing.T) {
 	const age = 19
 	ageCriterion := models.IntCriterionInput{
@@ -1,6 +1,16 @@
 package sqlite
 
-import "github.com/corona10/goimagehash"
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/corona10/goimagehash"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/utils"
+)
 
 func phashDistanceFn(phash1 int64, phash2 int64) (int64, error) {
 	hash1 := goimagehash.NewImageHash(uint64(phash1), goimagehash.PHash)
@@ -8,3 +18,180 @@ func phashDistanceFn(phash1 int64, phash2 int64) (int64, error) {
 	distance, _ := hash1.Distance(hash2)
 	return int64(distance), nil
 }
+
+// phashFilesTable describes the join table between an object table and the
+// files that carry its phash fingerprints.
+type phashFilesTable struct {
+	// primaryTable is the table being filtered, eg scenes
+	primaryTable string
+	// joinTable is the table joining primaryTable to files, eg scenes_files
+	joinTable string
+	// fkColumn is the column in joinTable referencing primaryTable
+	fkColumn string
+}
+
+var (
+	scenePhashFiles = phashFilesTable{
+		primaryTable: sceneTable,
+		joinTable:    scenesFilesTable,
+		fkColumn:     sceneIDColumn,
+	}
+	imagePhashFiles = phashFilesTable{
+		primaryTable: imageTable,
+		joinTable:    imagesFilesTable,
+		fkColumn:     imageIDColumn,
+	}
+)
+
+// phashesQuery returns a query selecting the object id and phash
+// fingerprint of every file of the object with a phash.
+func (t phashFilesTable) phashesQuery() string {
+	return fmt.Sprintf(`SELECT %[1]s.%[2]s AS id, files_fingerprints.fingerprint AS phash
+FROM %[1]s
+INNER JOIN files_fingerprints ON %[1]s.file_id = files_fingerprints.file_id AND files_fingerprints.type = 'phash'
+WHERE typeof(files_fingerprints.fingerprint) = 'integer'`, t.joinTable, t.fkColumn)
+}
+
+// duplicatedQuery returns a query selecting the ids of objects which have a
+// file with the same phash as a file of another object.
+func (t phashFilesTable) duplicatedQuery() string {
+	return fmt.Sprintf(`SELECT phashes.id FROM (%[1]s) phashes
+WHERE phashes.phash IN (
+	SELECT others.phash FROM (%[1]s) others GROUP BY others.phash HAVING COUNT(DISTINCT others.id) > 1
+)`, t.phashesQuery())
+}
+
+// duplicatedIDs returns the ids of objects which have a file with a phash
+// within distance of a file of another object. Comparing every pair of
+// phashes in SQL calls phash_distance once per pair, so the phashes are
+// loaded once and grouped in Go, as FindDuplicates does.
+func (t phashFilesTable) duplicatedIDs(ctx context.Context, distance int) ([]int, error) {
+	var tx dbWrapper
+	var hashes []*utils.Phash
+	if err := tx.Select(ctx, &hashes, t.phashesQuery()); err != nil {
+		return nil, err
+	}
+
+	for _, h := range hashes {
+		h.Bucket = -1
+		h.Duration = -1
+	}
+
+	var ret []int
+	// a negative duration difference ignores the duration
+	for _, group := range utils.FindDuplicates(hashes, distance, -1) {
+		ret = append(ret, group...)
+	}
+
+	return ret, nil
+}
+
+func phashDistanceCriterionHandler(phashDistance *models.PhashDistanceCriterionInput, t phashFilesTable) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if phashDistance != nil {
+			f.addLeftJoin(t.joinTable, "", fmt.Sprintf("%s.%s = %s.id", t.joinTable, t.fkColumn, t.primaryTable))
+			f.addLeftJoin(fingerprintTable, "fingerprints_phash", t.joinTable+".file_id = fingerprints_phash.file_id AND fingerprints_phash.type = 'phash'")
+
+			value, _ := utils.StringToPhash(phashDistance.Value)
+			distance := 0
+			if phashDistance.Distance != nil {
+				distance = *phashDistance.Distance
+			}
+
+			switch {
+			case phashDistance.Modifier == models.CriterionModifierEquals && distance > 0:
+				// needed to avoid a type mismatch
+				f.addWhere("typeof(fingerprints_phash.fingerprint) = 'integer'")
+				f.addWhere("phash_distance(fingerprints_phash.fingerprint, ?) < ?", value, distance)
+			case phashDistance.Modifier == models.CriterionModifierNotEquals && distance > 0:
+				// needed to avoid a type mismatch
+				f.addWhere("typeof(fingerprints_phash.fingerprint) = 'integer'")
+				f.addWhere("phash_distance(fingerprints_phash.fingerprint, ?) > ?", value, distance)
+			default:
+				intCriterionHandler(&models.IntCriterionInput{
+					Value:    int(value),
+					Modifier: phashDistance.Modifier,
+				}, "fingerprints_phash.fingerprint", nil)(ctx, f)
+			}
+		}
+	}
+}
+
+// phashDuplicatedCriterionHandler filters objects by whether another object
+// has a file with a phash within the given distance. A distance of zero or
+// less matches identical phashes only.
+func phashDuplicatedCriterionHandler(duplicatedFilter *models.PHashDuplicationCriterionInput, t phashFilesTable) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if duplicatedFilter == nil {
+			return
+		}
+
+		duplicated := true
+		if duplicatedFilter.Duplicated != nil {
+			duplicated = *duplicatedFilter.Duplicated
+		}
+
+		distance := 0
+		if duplicatedFilter.Distance != nil {
+			distance = *duplicatedFilter.Distance
+		}
+
+		idColumn := t.primaryTable + ".id"
+		dupesQuery := t.duplicatedQuery()
+
+		if distance > 0 {
+			ids, err := t.duplicatedIDs(ctx, distance)
+			if err != nil {
+				f.setError(fmt.Errorf("finding duplicated phashes: %w", err))
+				return
+			}
+
+			// ids are inlined, as there may be more than the bound
+			// parameter limit. 0 is not an id.
+			dupesQuery = "0"
+			if len(ids) > 0 {
+				dupesQuery = strings.Join(intslice.IntSliceToStringSlice(ids), ",")
+			}
+		}
+
+		if duplicated {
+			f.addWhere(fmt.Sprintf("%s IN (%s)", idColumn, dupesQuery))
+		} else {
+			// only objects with a phash can be considered not duplicated
+			f.addWhere(fmt.Sprintf("%s IN (SELECT id FROM (%s))", idColumn, t.phashesQuery()))
+			f.addWhere(fmt.Sprintf("%s NOT IN (%s)", idColumn, dupesQuery))
+		}
+	}
+}
+
+// phashSimilarToCriterionHandler filters objects having a file with a phash
+// within the given distance of a phash of the object with the given id.
+// The object itself is excluded.
+func phashSimilarToCriterionHandler(similarTo *models.PhashSimilarityCriterionInput, t phashFilesTable) criterionHandlerFunc {
+	return func(ctx context.Context, f *filterBuilder) {
+		if similarTo == nil {
+			return
+		}
+
+		id, err := strconv.Atoi(similarTo.ID)
+		if err != nil {
+			f.setError(fmt.Errorf("invalid id %q: %w", similarTo.ID, err))
+			return
+		}
+
+		distance := 0
+		if similarTo.Distance != nil {
+			distance = *similarTo.Distance
+		}
+
+		idColumn := t.primaryTable + ".id"
+		phashes := t.phashesQuery()
+
+		f.addWhere(idColumn+" != ?", id)
+		f.addWhere(fmt.Sprintf(`%s IN (
+	SELECT phashes.id FROM (%[2]s) phashes
+	INNER JOIN (%[2]s) target ON target.id = ?
+	WHERE phash_distance(phashes.phash, target.phash) <= ?
+)`, idColumn, phashes), id, distance)
+	}
+}
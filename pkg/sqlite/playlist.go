@@ -0,0 +1,348 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+	"gopkg.in/guregu/null.v4/zero"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+)
+
+const (
+	playlistTable       = "playlists"
+	playlistsItemsTable = "playlists_items"
+	playlistIDColumn    = "playlist_id"
+)
+
+type playlistRow struct {
+	ID            int         `db:"id" goqu:"skipinsert"`
+	Name          string      `db:"name"`
+	Description   zero.String `db:"description"`
+	SavedFilterID null.Int    `db:"saved_filter_id,omitempty"`
+	FilterLimit   null.Int    `db:"filter_limit"`
+	CreatedAt     Timestamp   `db:"created_at"`
+	UpdatedAt     Timestamp   `db:"updated_at"`
+}
+
+func (r *playlistRow) fromPlaylist(o models.Playlist) {
+	r.ID = o.ID
+	r.Name = o.Name
+	r.Description = zero.StringFrom(o.Description)
+	r.SavedFilterID = intFromPtr(o.SavedFilterID)
+	r.FilterLimit = intFromPtr(o.FilterLimit)
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+	r.UpdatedAt = Timestamp{Timestamp: o.UpdatedAt}
+}
+
+func (r *playlistRow) resolve() *models.Playlist {
+	ret := &models.Playlist{
+		ID:            r.ID,
+		Name:          r.Name,
+		Description:   r.Description.String,
+		SavedFilterID: nullIntPtr(r.SavedFilterID),
+		FilterLimit:   nullIntPtr(r.FilterLimit),
+		CreatedAt:     r.CreatedAt.Timestamp,
+		UpdatedAt:     r.UpdatedAt.Timestamp,
+	}
+
+	return ret
+}
+
+type playlistItemRow struct {
+	ID            int      `db:"id" goqu:"skipinsert"`
+	PlaylistID    int      `db:"playlist_id"`
+	Position      int      `db:"position"`
+	SceneID       null.Int `db:"scene_id"`
+	SceneMarkerID null.Int `db:"scene_marker_id"`
+	ImageID       null.Int `db:"image_id"`
+}
+
+func (r *playlistItemRow) fromPlaylistItem(o models.PlaylistItem) {
+	r.ID = o.ID
+	r.PlaylistID = o.PlaylistID
+	r.Position = o.Position
+	r.SceneID = intFromPtr(o.SceneID)
+	r.SceneMarkerID = intFromPtr(o.SceneMarkerID)
+	r.ImageID = intFromPtr(o.ImageID)
+}
+
+func (r *playlistItemRow) resolve() *models.PlaylistItem {
+	return &models.PlaylistItem{
+		ID:            r.ID,
+		PlaylistID:    r.PlaylistID,
+		Position:      r.Position,
+		SceneID:       nullIntPtr(r.SceneID),
+		SceneMarkerID: nullIntPtr(r.SceneMarkerID),
+		ImageID:       nullIntPtr(r.ImageID),
+	}
+}
+
+type PlaylistStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewPlaylistStore() *PlaylistStore {
+	return &PlaylistStore{
+		repository: repository{
+			tableName: playlistTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: playlistTableMgr,
+	}
+}
+
+func (qb *PlaylistStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *PlaylistStore) itemsTable() exp.IdentifierExpression {
+	return playlistsItemsTableMgr.table
+}
+
+func (qb *PlaylistStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *PlaylistStore) Create(ctx context.Context, newObject *models.Playlist) error {
+	var r playlistRow
+	r.fromPlaylist(*newObject)
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	updated, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding after create: %w", err)
+	}
+
+	*newObject = *updated
+
+	return nil
+}
+
+func (qb *PlaylistStore) Update(ctx context.Context, updatedObject *models.Playlist) error {
+	var r playlistRow
+	r.fromPlaylist(*updatedObject)
+
+	if err := qb.tableMgr.updateByID(ctx, updatedObject.ID, r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (qb *PlaylistStore) Destroy(ctx context.Context, id int) error {
+	return qb.destroyExisting(ctx, []int{id})
+}
+
+// returns nil, nil if not found
+func (qb *PlaylistStore) Find(ctx context.Context, id int) (*models.Playlist, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+func (qb *PlaylistStore) FindMany(ctx context.Context, ids []int) ([]*models.Playlist, error) {
+	ret := make([]*models.Playlist, len(ids))
+
+	table := qb.table()
+	q := qb.selectDataset().Prepared(true).Where(table.Col(idColumn).In(ids))
+	unsorted, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range unsorted {
+		i := intslice.IntIndex(ids, s.ID)
+		ret[i] = s
+	}
+
+	for i := range ret {
+		if ret[i] == nil {
+			return nil, fmt.Errorf("playlist with id %d not found", ids[i])
+		}
+	}
+
+	return ret, nil
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *PlaylistStore) find(ctx context.Context, id int) (*models.Playlist, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	ret, err := qb.get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *PlaylistStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.Playlist, error) {
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *PlaylistStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Playlist, error) {
+	const single = false
+	var ret []*models.Playlist
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f playlistRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		s := f.resolve()
+
+		ret = append(ret, s)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (qb *PlaylistStore) All(ctx context.Context) ([]*models.Playlist, error) {
+	table := qb.table()
+	return qb.getMany(ctx, qb.selectDataset().Order(
+		table.Col("name").Asc(),
+		table.Col(idColumn).Asc(),
+	))
+}
+
+func (qb *PlaylistStore) Count(ctx context.Context) (int, error) {
+	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	return count(ctx, q)
+}
+
+func (qb *PlaylistStore) GetItems(ctx context.Context, playlistID int) ([]*models.PlaylistItem, error) {
+	table := qb.itemsTable()
+	q := dialect.From(table).Select(table.All()).Where(
+		table.Col(playlistIDColumn).Eq(playlistID),
+	).Order(table.Col("position").Asc(), table.Col(idColumn).Asc())
+
+	const single = false
+	var ret []*models.PlaylistItem
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f playlistItemRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		ret = append(ret, f.resolve())
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("getting items of playlist %d: %w", playlistID, err)
+	}
+
+	return ret, nil
+}
+
+func (qb *PlaylistStore) AddItems(ctx context.Context, playlistID int, items []*models.PlaylistItem) error {
+	if err := qb.tableMgr.checkIDExists(ctx, playlistID); err != nil {
+		return err
+	}
+
+	table := qb.itemsTable()
+	q := dialect.Select(goqu.COALESCE(goqu.MAX(table.Col("position")), -1)).From(table).Where(
+		table.Col(playlistIDColumn).Eq(playlistID),
+	)
+
+	var last int
+	if err := querySimple(ctx, q, &last); err != nil {
+		return fmt.Errorf("getting last position of playlist %d: %w", playlistID, err)
+	}
+
+	for i, item := range items {
+		if err := item.Validate(); err != nil {
+			return err
+		}
+
+		item.PlaylistID = playlistID
+		item.Position = last + 1 + i
+
+		var r playlistItemRow
+		r.fromPlaylistItem(*item)
+
+		id, err := playlistsItemsTableMgr.insertID(ctx, r)
+		if err != nil {
+			return err
+		}
+		item.ID = id
+	}
+
+	return nil
+}
+
+func (qb *PlaylistStore) RemoveItems(ctx context.Context, playlistID int, itemIDs []int) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	table := qb.itemsTable()
+	q := dialect.Delete(table).Where(
+		table.Col(playlistIDColumn).Eq(playlistID),
+		table.Col(idColumn).In(itemIDs),
+	)
+
+	if _, err := exec(ctx, q); err != nil {
+		return fmt.Errorf("removing items from playlist %d: %w", playlistID, err)
+	}
+
+	return nil
+}
+
+func (qb *PlaylistStore) ReorderItems(ctx context.Context, playlistID int, itemIDs []int) error {
+	existing, err := qb.GetItems(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	if len(itemIDs) != len(existing) || len(intslice.IntAppendUniques(nil, itemIDs)) != len(itemIDs) {
+		return fmt.Errorf("reordering playlist %d: all %d items must be given once", playlistID, len(existing))
+	}
+
+	for _, item := range existing {
+		if !intslice.IntInclude(itemIDs, item.ID) {
+			return fmt.Errorf("reordering playlist %d: item %d not given", playlistID, item.ID)
+		}
+	}
+
+	table := qb.itemsTable()
+	for i, id := range itemIDs {
+		q := dialect.Update(table).Prepared(true).Set(goqu.Record{"position": i}).Where(
+			table.Col(playlistIDColumn).Eq(playlistID),
+			table.Col(idColumn).Eq(id),
+		)
+
+		if _, err := exec(ctx, q); err != nil {
+			return fmt.Errorf("reordering playlist %d: %w", playlistID, err)
+		}
+	}
+
+	return nil
+}
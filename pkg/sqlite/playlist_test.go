@@ -0,0 +1,192 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestPlaylist(ctx context.Context, t *testing.T, name string) *models.Playlist {
+	t.Helper()
+
+	now := time.Now()
+	p := &models.Playlist{
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := db.Playlist.Create(ctx, p); err != nil {
+		t.Fatalf("PlaylistStore.Create() error = %v", err)
+	}
+
+	return p
+}
+
+func playlistItemIDs(items []*models.PlaylistItem) []int {
+	ret := make([]int, len(items))
+	for i, item := range items {
+		ret[i] = item.ID
+	}
+
+	return ret
+}
+
+func TestPlaylistCreateUpdate(t *testing.T) {
+	runWithRollbackTxn(t, "create and update", func(t *testing.T, ctx context.Context) {
+		p := createTestPlaylist(ctx, t, "playlist")
+
+		found, err := db.Playlist.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, "playlist", found.Name)
+		assert.False(t, found.IsSmart())
+
+		savedFilterID := savedFilterIDs[savedFilterIdxScene]
+		limit := 10
+		found.Description = "description"
+		found.SavedFilterID = &savedFilterID
+		found.FilterLimit = &limit
+
+		if err := db.Playlist.Update(ctx, found); err != nil {
+			t.Errorf("PlaylistStore.Update() error = %v", err)
+			return
+		}
+
+		updated, err := db.Playlist.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, "description", updated.Description)
+		assert.Equal(t, &savedFilterID, updated.SavedFilterID)
+		assert.Equal(t, &limit, updated.FilterLimit)
+	})
+}
+
+func TestPlaylistItems(t *testing.T) {
+	runWithRollbackTxn(t, "items", func(t *testing.T, ctx context.Context) {
+		p := createTestPlaylist(ctx, t, "items")
+
+		sceneID := sceneIDs[0]
+		markerID := markerIDs[0]
+		imageID := imageIDs[0]
+
+		if err := db.Playlist.AddItems(ctx, p.ID, []*models.PlaylistItem{
+			{SceneID: &sceneID},
+			{SceneMarkerID: &markerID},
+		}); err != nil {
+			t.Errorf("PlaylistStore.AddItems() error = %v", err)
+			return
+		}
+
+		// items are appended
+		if err := db.Playlist.AddItems(ctx, p.ID, []*models.PlaylistItem{
+			{ImageID: &imageID},
+		}); err != nil {
+			t.Errorf("PlaylistStore.AddItems() error = %v", err)
+			return
+		}
+
+		items, err := db.Playlist.GetItems(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.GetItems() error = %v", err)
+			return
+		}
+
+		if !assert.Len(t, items, 3) {
+			return
+		}
+		assert.Equal(t, &sceneID, items[0].SceneID)
+		assert.Equal(t, &markerID, items[1].SceneMarkerID)
+		assert.Equal(t, &imageID, items[2].ImageID)
+
+		ids := playlistItemIDs(items)
+
+		// reverse the order
+		reordered := []int{ids[2], ids[1], ids[0]}
+		if err := db.Playlist.ReorderItems(ctx, p.ID, reordered); err != nil {
+			t.Errorf("PlaylistStore.ReorderItems() error = %v", err)
+			return
+		}
+
+		items, err = db.Playlist.GetItems(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.GetItems() error = %v", err)
+			return
+		}
+		assert.Equal(t, reordered, playlistItemIDs(items))
+
+		// all items must be given
+		assert.Error(t, db.Playlist.ReorderItems(ctx, p.ID, []int{ids[0], ids[1]}))
+		assert.Error(t, db.Playlist.ReorderItems(ctx, p.ID, []int{ids[0], ids[0], ids[1]}))
+
+		if err := db.Playlist.RemoveItems(ctx, p.ID, []int{ids[1]}); err != nil {
+			t.Errorf("PlaylistStore.RemoveItems() error = %v", err)
+			return
+		}
+
+		items, err = db.Playlist.GetItems(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.GetItems() error = %v", err)
+			return
+		}
+		assert.Equal(t, []int{ids[2], ids[0]}, playlistItemIDs(items))
+	})
+}
+
+func TestPlaylistAddInvalidItem(t *testing.T) {
+	runWithRollbackTxn(t, "invalid item", func(t *testing.T, ctx context.Context) {
+		p := createTestPlaylist(ctx, t, "invalid")
+
+		sceneID := sceneIDs[0]
+		imageID := imageIDs[0]
+
+		err := db.Playlist.AddItems(ctx, p.ID, []*models.PlaylistItem{
+			{SceneID: &sceneID, ImageID: &imageID},
+		})
+		assert.ErrorIs(t, err, models.ErrPlaylistItemObject)
+
+		err = db.Playlist.AddItems(ctx, p.ID, []*models.PlaylistItem{{}})
+		assert.ErrorIs(t, err, models.ErrPlaylistItemObject)
+	})
+}
+
+func TestPlaylistDestroy(t *testing.T) {
+	runWithRollbackTxn(t, "destroy", func(t *testing.T, ctx context.Context) {
+		p := createTestPlaylist(ctx, t, "destroy")
+
+		sceneID := sceneIDs[0]
+		if err := db.Playlist.AddItems(ctx, p.ID, []*models.PlaylistItem{{SceneID: &sceneID}}); err != nil {
+			t.Errorf("PlaylistStore.AddItems() error = %v", err)
+			return
+		}
+
+		if err := db.Playlist.Destroy(ctx, p.ID); err != nil {
+			t.Errorf("PlaylistStore.Destroy() error = %v", err)
+			return
+		}
+
+		found, err := db.Playlist.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, found)
+
+		items, err := db.Playlist.GetItems(ctx, p.ID)
+		if err != nil {
+			t.Errorf("PlaylistStore.GetItems() error = %v", err)
+			return
+		}
+		assert.Empty(t, items)
+	})
+}
@@ -22,6 +22,19 @@ type queryBuilder struct {
 	recursiveWith bool
 
 	sortAndPagination string
+
+	// keyset is the where clause selecting the results following the
+	// cursor, when paging with cursors. Like the sort and pagination, it is
+	// not applied when counting results.
+	keyset string
+
+	// cursor is used to set the cursor of the last result on the find
+	// filter, when paging with cursors.
+	cursor *keysetCursor
+
+	// fullTextSearch is the alias of the full-text search results joined
+	// to the query, if any.
+	fullTextSearch string
 }
 
 func (qb queryBuilder) body() string {
@@ -44,7 +57,12 @@ func (qb queryBuilder) toSQL(includeSortPagination bool) string {
 		withClause = "WITH " + recursive + strings.Join(qb.withClauses, ", ") + " "
 	}
 
-	body = withClause + qb.repository.buildQueryBody(body, qb.whereClauses, qb.havingClauses)
+	whereClauses := qb.whereClauses
+	if includeSortPagination {
+		whereClauses = qb.pageWhereClauses()
+	}
+
+	body = withClause + qb.repository.buildQueryBody(body, whereClauses, qb.havingClauses)
 	if includeSortPagination {
 		body += qb.sortAndPagination
 	}
@@ -55,10 +73,48 @@ func (qb queryBuilder) toSQL(includeSortPagination bool) string {
 func (qb queryBuilder) findIDs(ctx context.Context) ([]int, error) {
 	const includeSortPagination = true
 	sql := qb.toSQL(includeSortPagination)
-	return qb.repository.runIdsQuery(ctx, sql, qb.args)
+	ids, err := qb.repository.runIdsQuery(ctx, sql, qb.args)
+	if err != nil {
+		return nil, err
+	}
+
+	if qb.cursor != nil {
+		if err := qb.setLastCursor(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// pageWhereClauses returns the where clauses of the query, including the
+// keyset clause if paging with cursors.
+func (qb queryBuilder) pageWhereClauses() []string {
+	if qb.keyset == "" {
+		return qb.whereClauses
+	}
+
+	ret := append([]string{}, qb.whereClauses...)
+	return append(ret, qb.keyset)
 }
 
 func (qb queryBuilder) executeFind(ctx context.Context) ([]int, int, error) {
+	if qb.cursor != nil {
+		// the count excludes the keyset clause, so cannot share the body
+		// of the find query
+		count, err := qb.executeCount(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		ids, err := qb.findIDs(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return ids, count, nil
+	}
+
 	body := qb.body()
 	return qb.repository.executeFindQuery(ctx, body, qb.args, qb.sortAndPagination, qb.whereClauses, qb.havingClauses, qb.withClauses, qb.recursiveWith)
 }
@@ -164,6 +220,53 @@ func (qb *queryBuilder) addFilter(f *filterBuilder) error {
 	return nil
 }
 
+// parseFullTextQuery filters the query using the full-text index of its
+// table. Results matching the positive terms of the search string are
+// joined to the query, so that they can be sorted by relevance.
+func (qb *queryBuilder) parseFullTextQuery(q string) {
+	table := qb.repository.tableName
+	index := table + "_fts"
+
+	match, exclude := getFullTextMatch(models.ParseSearchString(q))
+
+	if match != "" {
+		// the match argument must precede all but WITH clause arguments
+		qb.fullTextSearch = table + "_search"
+		qb.addJoins(join{
+			table:    fmt.Sprintf("(SELECT rowid, rank FROM %[1]s WHERE %[1]s MATCH ?)", index),
+			as:       qb.fullTextSearch,
+			onClause: fmt.Sprintf("%s.rowid = %s.id", qb.fullTextSearch, table),
+			joinType: "INNER",
+		})
+		qb.addArg(match)
+	}
+
+	if exclude != "" {
+		qb.addWhere(fmt.Sprintf("%[1]s.id NOT IN (SELECT rowid FROM %[2]s WHERE %[2]s MATCH ?)", table, index))
+		qb.addArg(exclude)
+	}
+}
+
+// getRelevanceSort returns the clause sorting the full-text search
+// results of the query by relevance, with the most relevant results first
+// when sorting in descending order. Returns an empty string if the query
+// has no full-text search results.
+func (qb *queryBuilder) getRelevanceSort(direction string) string {
+	if qb.fullTextSearch == "" {
+		return ""
+	}
+
+	// rank is lower for more relevant results
+	direction = getSortDirection(direction)
+	if direction == "DESC" {
+		direction = "ASC"
+	} else {
+		direction = "DESC"
+	}
+
+	return " ORDER BY " + qb.fullTextSearch + ".rank " + direction
+}
+
 func (qb *queryBuilder) parseQueryString(columns []string, q string) {
 	specs := models.ParseSearchString(q)
 
@@ -0,0 +1,346 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// The conditions below exclude the content hidden by the restriction profiles
+// of the context from queries. They return an empty string if the context is
+// not restricted. The profile ids are integers, so are embedded directly in
+// the SQL to allow the conditions to be added to both goqu datasets and
+// query builders.
+
+// restrictionCondition returns the condition built by f from the profile ids
+// of the context. A profile that does not exist, such as one still held by a
+// session after being deleted, hides everything rather than nothing.
+func restrictionCondition(ctx context.Context, f func(profileIDs string) string) string {
+	ids := models.RestrictionProfileIDsFromContext(ctx)
+	if len(ids) == 0 {
+		return ""
+	}
+
+	seen := make(map[int]bool)
+	var s []string
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			s = append(s, strconv.Itoa(id))
+		}
+	}
+
+	profileIDs := strings.Join(s, ", ")
+	return fmt.Sprintf("((SELECT COUNT(*) FROM restriction_profiles WHERE id IN (%s)) = %d AND %s)", profileIDs, len(s), f(profileIDs))
+}
+
+// restrictedTagsSQL returns a query selecting the ids of the restricted tags
+// and their descendants.
+func restrictedTagsSQL(profileIDs string) string {
+	return fmt.Sprintf(`WITH RECURSIVE restricted_tags(id) AS (
+SELECT tag_id FROM restriction_profiles_tags WHERE restriction_profile_id IN (%s)
+UNION
+SELECT tags_relations.child_id FROM tags_relations INNER JOIN restricted_tags ON tags_relations.parent_id = restricted_tags.id
+) SELECT id FROM restricted_tags`, profileIDs)
+}
+
+// restrictedStudiosSQL returns a query selecting the ids of the restricted
+// studios and their descendants.
+func restrictedStudiosSQL(profileIDs string) string {
+	return fmt.Sprintf(`WITH RECURSIVE restricted_studios(id) AS (
+SELECT studio_id FROM restriction_profiles_studios WHERE restriction_profile_id IN (%s)
+UNION
+SELECT studios.id FROM studios INNER JOIN restricted_studios ON studios.parent_id = restricted_studios.id
+) SELECT id FROM restricted_studios`, profileIDs)
+}
+
+// restrictedPerformersSQL returns a query selecting the ids of the restricted
+// performers and the performers with restricted tags.
+func restrictedPerformersSQL(profileIDs string) string {
+	return fmt.Sprintf(`SELECT performer_id FROM restriction_profiles_performers WHERE restriction_profile_id IN (%s)
+UNION
+SELECT performer_id FROM performers_tags WHERE tag_id IN (%s)`, profileIDs, restrictedTagsSQL(profileIDs))
+}
+
+// contentRestrictionCondition returns the condition for scenes, images and
+// galleries, which are hidden if they have a restricted tag, studio or
+// performer, or match the filter of their type.
+func contentRestrictionCondition(ctx context.Context, table string, tagsTable string, performersTable string, idColumn string, filterColumn string, filterSQL restrictionFilterFunc) string {
+	return restrictionCondition(ctx, func(profileIDs string) string {
+		ret := fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM %[2]s WHERE %[2]s.%[4]s = %[1]s.id AND %[2]s.tag_id IN (%[5]s))
+AND (%[1]s.studio_id IS NULL OR %[1]s.studio_id NOT IN (%[6]s))
+AND NOT EXISTS (SELECT 1 FROM %[3]s WHERE %[3]s.%[4]s = %[1]s.id AND %[3]s.performer_id IN (%[7]s))`,
+			table, tagsTable, performersTable, idColumn,
+			restrictedTagsSQL(profileIDs), restrictedStudiosSQL(profileIDs), restrictedPerformersSQL(profileIDs))
+
+		if filters := filterRestrictionSQL(ctx, profileIDs, table, filterColumn, filterSQL); filters != "" {
+			ret += "\nAND " + filters
+		}
+
+		return ret
+	})
+}
+
+func sceneRestrictionCondition(ctx context.Context) string {
+	return contentRestrictionCondition(ctx, sceneTable, scenesTagsTable, performersScenesTable, sceneIDColumn, "scene_filter", sceneFilterSQL)
+}
+
+func imageRestrictionCondition(ctx context.Context) string {
+	return contentRestrictionCondition(ctx, imageTable, imagesTagsTable, performersImagesTable, imageIDColumn, "image_filter", imageFilterSQL)
+}
+
+func galleryRestrictionCondition(ctx context.Context) string {
+	return contentRestrictionCondition(ctx, galleryTable, galleriesTagsTable, performersGalleriesTable, galleryIDColumn, "gallery_filter", galleryFilterSQL)
+}
+
+// sceneMarkerRestrictionCondition hides the markers of hidden scenes, and
+// the markers with a restricted primary tag or tag.
+func sceneMarkerRestrictionCondition(ctx context.Context) string {
+	return restrictionCondition(ctx, func(profileIDs string) string {
+		tags := restrictedTagsSQL(profileIDs)
+		return fmt.Sprintf(`EXISTS (SELECT 1 FROM scenes WHERE scenes.id = scene_markers.scene_id AND %s)
+AND scene_markers.primary_tag_id NOT IN (%s)
+AND NOT EXISTS (SELECT 1 FROM scene_markers_tags WHERE scene_markers_tags.scene_marker_id = scene_markers.id AND scene_markers_tags.tag_id IN (%s))`,
+			sceneRestrictionCondition(ctx), tags, tags)
+	})
+}
+
+func performerRestrictionCondition(ctx context.Context) string {
+	return restrictionCondition(ctx, func(profileIDs string) string {
+		return fmt.Sprintf("performers.id NOT IN (%s)", restrictedPerformersSQL(profileIDs))
+	})
+}
+
+func studioRestrictionCondition(ctx context.Context) string {
+	return restrictionCondition(ctx, func(profileIDs string) string {
+		return fmt.Sprintf("studios.id NOT IN (%s)", restrictedStudiosSQL(profileIDs))
+	})
+}
+
+// relatedRestrictionCondition returns a condition requiring the object of
+// table with the id in idColumn to satisfy the restriction condition, so that
+// the rows of join tables can be restricted. It returns an empty string if
+// the condition is empty.
+func relatedRestrictionCondition(table string, idColumn string, condition string) string {
+	if condition == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.id = %[2]s AND %[3]s)", table, idColumn, condition)
+}
+
+// restrictDataset returns q with the restriction condition, if any.
+func restrictDataset(q *goqu.SelectDataset, condition string) *goqu.SelectDataset {
+	if condition == "" {
+		return q
+	}
+
+	return q.Where(goqu.L(condition))
+}
+
+// restrictionFilterFunc returns a query selecting the ids of the objects
+// matching the JSON encoded filter.
+type restrictionFilterFunc func(ctx context.Context, filter string) (string, error)
+
+// filterRestrictionSQL returns the condition hiding the objects of table
+// matching the filters stored in column by the profiles. A filter which
+// cannot be applied hides everything.
+func filterRestrictionSQL(ctx context.Context, profileIDs string, table string, column string, filterSQL restrictionFilterFunc) string {
+	var filters []string
+	query := fmt.Sprintf("SELECT %[1]s FROM restriction_profiles WHERE id IN (%[2]s) AND %[1]s IS NOT NULL", column, profileIDs)
+	wrapper := dbWrapper{}
+	if err := wrapper.Select(ctx, &filters, query); err != nil {
+		logger.Errorf("reading restriction filters: %v", err)
+		return "0"
+	}
+
+	// the filters themselves see the whole library
+	ctx = models.WithRestrictionProfileIDs(ctx, nil)
+
+	var conditions []string
+	for _, f := range filters {
+		q, err := filterSQL(ctx, f)
+		if err != nil {
+			logger.Errorf("applying restriction %s: %v", column, err)
+			return "0"
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s.id NOT IN (%s)", table, q))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+func sceneFilterSQL(ctx context.Context, filter string) (string, error) {
+	var sceneFilter models.SceneFilterType
+	if err := json.Unmarshal([]byte(filter), &sceneFilter); err != nil {
+		return "", err
+	}
+
+	qb := NewSceneStore(nil, nil)
+	if err := qb.validateFilter(&sceneFilter); err != nil {
+		return "", err
+	}
+
+	return filterIDsSQL(qb.newQuery(), sceneTable, qb.makeFilter(ctx, &sceneFilter))
+}
+
+func imageFilterSQL(ctx context.Context, filter string) (string, error) {
+	var imageFilter models.ImageFilterType
+	if err := json.Unmarshal([]byte(filter), &imageFilter); err != nil {
+		return "", err
+	}
+
+	qb := NewImageStore(nil)
+	if err := qb.validateFilter(&imageFilter); err != nil {
+		return "", err
+	}
+
+	return filterIDsSQL(qb.newQuery(), imageTable, qb.makeFilter(ctx, &imageFilter))
+}
+
+func galleryFilterSQL(ctx context.Context, filter string) (string, error) {
+	var galleryFilter models.GalleryFilterType
+	if err := json.Unmarshal([]byte(filter), &galleryFilter); err != nil {
+		return "", err
+	}
+
+	qb := NewGalleryStore(nil, nil)
+	if err := qb.validateFilter(&galleryFilter); err != nil {
+		return "", err
+	}
+
+	return filterIDsSQL(qb.newQuery(), galleryTable, qb.makeFilter(ctx, &galleryFilter))
+}
+
+// filterIDsSQL returns the query selecting the ids of table matching the
+// filter, with its arguments embedded.
+func filterIDsSQL(query queryBuilder, table string, f *filterBuilder) (string, error) {
+	distinctIDs(&query, table)
+	if err := query.addFilter(f); err != nil {
+		return "", err
+	}
+
+	const includeSortPagination = false
+	return embedArgs(query.toSQL(includeSortPagination), query.args)
+}
+
+// validateRestrictionFilters returns an error if any filter of the profile
+// cannot be applied.
+func validateRestrictionFilters(ctx context.Context, p models.RestrictionProfile) error {
+	ctx = models.WithRestrictionProfileIDs(ctx, nil)
+
+	filters := []struct {
+		name   string
+		filter interface{}
+		sql    restrictionFilterFunc
+	}{
+		{"scene", p.SceneFilter, sceneFilterSQL},
+		{"image", p.ImageFilter, imageFilterSQL},
+		{"gallery", p.GalleryFilter, galleryFilterSQL},
+	}
+
+	for _, f := range filters {
+		s, err := marshalFilter(f.filter)
+		if err != nil {
+			return err
+		}
+
+		if !s.Valid {
+			continue
+		}
+
+		if _, err := f.sql(ctx, s.String); err != nil {
+			return fmt.Errorf("invalid %s filter: %w", f.name, err)
+		}
+	}
+
+	return nil
+}
+
+// embedArgs replaces the placeholders of the query with the literal values
+// of the arguments, so that it can be embedded in a restriction condition.
+func embedArgs(query string, args []interface{}) (string, error) {
+	var b strings.Builder
+	var quote rune
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			if len(args) == 0 {
+				return "", errors.New("too few arguments for query")
+			}
+
+			literal, err := argLiteral(args[0])
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(literal)
+			args = args[1:]
+			continue
+		}
+
+		b.WriteRune(c)
+	}
+
+	if len(args) > 0 {
+		return "", errors.New("too many arguments for query")
+	}
+
+	return b.String(), nil
+}
+
+// argLiteral returns the SQL literal of a query argument.
+func argLiteral(v interface{}) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		return argLiteral(value)
+	}
+
+	if v == nil {
+		return "NULL", nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return "'" + strings.ReplaceAll(rv.String(), "'", "''") + "'", nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return argLiteral(rv.Elem().Interface())
+	}
+
+	return "", fmt.Errorf("unsupported argument type %T", v)
+}
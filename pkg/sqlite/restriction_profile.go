@@ -0,0 +1,272 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	restrictionProfileTable            = "restriction_profiles"
+	restrictionProfilesTagsTable       = "restriction_profiles_tags"
+	restrictionProfilesStudiosTable    = "restriction_profiles_studios"
+	restrictionProfilesPerformersTable = "restriction_profiles_performers"
+	restrictionProfileIDColumn         = "restriction_profile_id"
+)
+
+type restrictionProfileRow struct {
+	ID   int    `db:"id" goqu:"skipinsert"`
+	Name string `db:"name"`
+	// JSON encoded
+	SceneFilter   null.String `db:"scene_filter"`
+	ImageFilter   null.String `db:"image_filter"`
+	GalleryFilter null.String `db:"gallery_filter"`
+	CreatedAt     Timestamp   `db:"created_at"`
+	UpdatedAt     Timestamp   `db:"updated_at"`
+}
+
+// marshalFilter returns the JSON encoding of the filter pointer, or null if
+// it is nil.
+func marshalFilter(filter interface{}) (null.String, error) {
+	if reflect.ValueOf(filter).IsNil() {
+		return null.String{}, nil
+	}
+
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return null.String{}, err
+	}
+
+	return null.StringFrom(string(b)), nil
+}
+
+func (r *restrictionProfileRow) fromRestrictionProfile(o models.RestrictionProfile) error {
+	r.ID = o.ID
+	r.Name = o.Name
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+	r.UpdatedAt = Timestamp{Timestamp: o.UpdatedAt}
+
+	var err error
+	if r.SceneFilter, err = marshalFilter(o.SceneFilter); err != nil {
+		return fmt.Errorf("marshalling scene filter: %w", err)
+	}
+	if r.ImageFilter, err = marshalFilter(o.ImageFilter); err != nil {
+		return fmt.Errorf("marshalling image filter: %w", err)
+	}
+	if r.GalleryFilter, err = marshalFilter(o.GalleryFilter); err != nil {
+		return fmt.Errorf("marshalling gallery filter: %w", err)
+	}
+
+	return nil
+}
+
+func (r *restrictionProfileRow) resolve() (*models.RestrictionProfile, error) {
+	ret := &models.RestrictionProfile{
+		ID:        r.ID,
+		Name:      r.Name,
+		CreatedAt: r.CreatedAt.Timestamp,
+		UpdatedAt: r.UpdatedAt.Timestamp,
+	}
+
+	if r.SceneFilter.Valid {
+		if err := json.Unmarshal([]byte(r.SceneFilter.String), &ret.SceneFilter); err != nil {
+			return nil, fmt.Errorf("unmarshalling scene filter of restriction profile %d: %w", r.ID, err)
+		}
+	}
+	if r.ImageFilter.Valid {
+		if err := json.Unmarshal([]byte(r.ImageFilter.String), &ret.ImageFilter); err != nil {
+			return nil, fmt.Errorf("unmarshalling image filter of restriction profile %d: %w", r.ID, err)
+		}
+	}
+	if r.GalleryFilter.Valid {
+		if err := json.Unmarshal([]byte(r.GalleryFilter.String), &ret.GalleryFilter); err != nil {
+			return nil, fmt.Errorf("unmarshalling gallery filter of restriction profile %d: %w", r.ID, err)
+		}
+	}
+
+	return ret, nil
+}
+
+type RestrictionProfileStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewRestrictionProfileStore() *RestrictionProfileStore {
+	return &RestrictionProfileStore{
+		repository: repository{
+			tableName: restrictionProfileTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: restrictionProfileTableMgr,
+	}
+}
+
+func (qb *RestrictionProfileStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *RestrictionProfileStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *RestrictionProfileStore) Create(ctx context.Context, newObject *models.RestrictionProfile) error {
+	if err := newObject.Validate(); err != nil {
+		return err
+	}
+
+	if err := validateRestrictionFilters(ctx, *newObject); err != nil {
+		return err
+	}
+
+	var r restrictionProfileRow
+	if err := r.fromRestrictionProfile(*newObject); err != nil {
+		return err
+	}
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := qb.replaceRules(ctx, id, *newObject); err != nil {
+		return err
+	}
+
+	updated, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding after create: %w", err)
+	}
+
+	*newObject = *updated
+
+	return nil
+}
+
+func (qb *RestrictionProfileStore) Update(ctx context.Context, updatedObject *models.RestrictionProfile) error {
+	if err := updatedObject.Validate(); err != nil {
+		return err
+	}
+
+	if err := validateRestrictionFilters(ctx, *updatedObject); err != nil {
+		return err
+	}
+
+	var r restrictionProfileRow
+	if err := r.fromRestrictionProfile(*updatedObject); err != nil {
+		return err
+	}
+
+	if err := qb.tableMgr.updateByID(ctx, updatedObject.ID, r); err != nil {
+		return err
+	}
+
+	return qb.replaceRules(ctx, updatedObject.ID, *updatedObject)
+}
+
+func (qb *RestrictionProfileStore) replaceRules(ctx context.Context, id int, o models.RestrictionProfile) error {
+	if err := restrictionProfilesTagsTableMgr.replaceJoins(ctx, id, o.TagIDs); err != nil {
+		return err
+	}
+
+	if err := restrictionProfilesStudiosTableMgr.replaceJoins(ctx, id, o.StudioIDs); err != nil {
+		return err
+	}
+
+	return restrictionProfilesPerformersTableMgr.replaceJoins(ctx, id, o.PerformerIDs)
+}
+
+// Destroy deletes the profile. It returns ErrRestrictionProfileInUse if the
+// profile is applied to any user, API key or share link, since deleting it
+// would lift their restriction.
+func (qb *RestrictionProfileStore) Destroy(ctx context.Context, id int) error {
+	for _, t := range []string{userTable, apiKeyTable, shareLinkTable} {
+		n, err := count(ctx, dialect.From(t).Select(goqu.COUNT("*")).Where(goqu.C(restrictionProfileIDColumn).Eq(id)))
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			return fmt.Errorf("%w: referenced by %s", models.ErrRestrictionProfileInUse, t)
+		}
+	}
+
+	return qb.destroyExisting(ctx, []int{id})
+}
+
+// returns nil, nil if not found
+func (qb *RestrictionProfileStore) Find(ctx context.Context, id int) (*models.RestrictionProfile, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *RestrictionProfileStore) find(ctx context.Context, id int) (*models.RestrictionProfile, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *RestrictionProfileStore) All(ctx context.Context) ([]*models.RestrictionProfile, error) {
+	return qb.getMany(ctx, qb.selectDataset().Order(qb.table().Col("name").Asc()))
+}
+
+func (qb *RestrictionProfileStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.RestrictionProfile, error) {
+	const single = false
+	var ret []*models.RestrictionProfile
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f restrictionProfileRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		p, err := f.resolve()
+		if err != nil {
+			return err
+		}
+
+		ret = append(ret, p)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// the rules of profiles are few, so they are loaded with the profile
+	for _, p := range ret {
+		var err error
+		if p.TagIDs, err = restrictionProfilesTagsTableMgr.get(ctx, p.ID); err != nil {
+			return nil, err
+		}
+		if p.StudioIDs, err = restrictionProfilesStudiosTableMgr.get(ctx, p.ID); err != nil {
+			return nil, err
+		}
+		if p.PerformerIDs, err = restrictionProfilesPerformersTableMgr.get(ctx, p.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,462 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func createRestrictionProfile(ctx context.Context, t *testing.T, p *models.RestrictionProfile) bool {
+	t.Helper()
+
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	if err := db.RestrictionProfile.Create(ctx, p); err != nil {
+		t.Errorf("RestrictionProfileStore.Create() error = %v", err)
+		return false
+	}
+
+	return true
+}
+
+func TestRestrictionProfileCreateUpdateDestroy(t *testing.T) {
+	runWithRollbackTxn(t, "create update destroy", func(t *testing.T, ctx context.Context) {
+		qb := db.RestrictionProfile
+
+		p := &models.RestrictionProfile{
+			Name:      "family",
+			TagIDs:    []int{tagIDs[tagIdxWithScene]},
+			StudioIDs: []int{studioIDs[studioIdxWithScene]},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		found, err := qb.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("RestrictionProfileStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, "family", found.Name)
+		assert.Equal(t, []int{tagIDs[tagIdxWithScene]}, found.TagIDs)
+		assert.Equal(t, []int{studioIDs[studioIdxWithScene]}, found.StudioIDs)
+		assert.Empty(t, found.PerformerIDs)
+
+		found.Name = "demo"
+		found.TagIDs = nil
+		found.PerformerIDs = []int{performerIDs[performerIdxWithScene]}
+		if err := qb.Update(ctx, found); err != nil {
+			t.Errorf("RestrictionProfileStore.Update() error = %v", err)
+			return
+		}
+
+		found, err = qb.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("RestrictionProfileStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, "demo", found.Name)
+		assert.Empty(t, found.TagIDs)
+		assert.Equal(t, []int{performerIDs[performerIdxWithScene]}, found.PerformerIDs)
+
+		// profiles applied to users cannot be deleted
+		u := &models.User{
+			Username:             "family",
+			Role:                 models.UserRoleViewer,
+			RestrictionProfileID: &p.ID,
+		}
+		if err := db.User.Create(ctx, u); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		err = qb.Destroy(ctx, p.ID)
+		assert.ErrorIs(t, err, models.ErrRestrictionProfileInUse)
+
+		u.RestrictionProfileID = nil
+		if err := db.User.Update(ctx, u); err != nil {
+			t.Errorf("UserStore.Update() error = %v", err)
+			return
+		}
+
+		if err := qb.Destroy(ctx, p.ID); err != nil {
+			t.Errorf("RestrictionProfileStore.Destroy() error = %v", err)
+			return
+		}
+
+		found, err = qb.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("RestrictionProfileStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, found)
+	})
+
+	runWithRollbackTxn(t, "name required", func(t *testing.T, ctx context.Context) {
+		err := db.RestrictionProfile.Create(ctx, &models.RestrictionProfile{Name: " "})
+		assert.ErrorIs(t, err, models.ErrRestrictionProfileNameRequired)
+	})
+}
+
+func TestRestrictionProfileQueries(t *testing.T) {
+	runWithRollbackTxn(t, "restricted", func(t *testing.T, ctx context.Context) {
+		p := &models.RestrictionProfile{
+			Name:         "family",
+			TagIDs:       []int{tagIDs[tagIdxWithScene], tagIDs[tagIdxWithPerformer]},
+			StudioIDs:    []int{studioIDs[studioIdxWithGrandChild]},
+			PerformerIDs: []int{performerIDs[performerIdxWithScene]},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		unrestrictedCount, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return
+		}
+
+		ctx = models.WithRestrictionProfileIDs(ctx, []int{p.ID})
+
+		hiddenScenes := []int{
+			sceneIdxWithTag,
+			sceneIdxWithPerformer,
+			sceneIdxWithPerformerTag,
+			sceneIdxWithTwoPerformerTag,
+			sceneIdxWithGrandChildStudio,
+		}
+		for _, idx := range hiddenScenes {
+			s, err := db.Scene.Find(ctx, sceneIDs[idx])
+			if err != nil {
+				t.Errorf("SceneStore.Find() error = %v", err)
+				return
+			}
+			assert.Nil(t, s, "scene %d", idx)
+		}
+
+		s, err := db.Scene.Find(ctx, sceneIDs[sceneIdxWithGallery])
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+		assert.NotNil(t, s)
+
+		// hidden scenes are left nil rather than returning an error
+		scenes, err := db.Scene.FindMany(ctx, []int{sceneIDs[sceneIdxWithTag], sceneIDs[sceneIdxWithGallery]})
+		if err != nil {
+			t.Errorf("SceneStore.FindMany() error = %v", err)
+			return
+		}
+		if assert.Len(t, scenes, 2) {
+			assert.Nil(t, scenes[0])
+			assert.NotNil(t, scenes[1])
+		}
+
+		count, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return
+		}
+		assert.Equal(t, unrestrictedCount-len(hiddenScenes), count)
+
+		perPage := -1
+		result, err := db.Scene.Query(ctx, models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &models.FindFilterType{PerPage: &perPage},
+				Count:      true,
+			},
+		})
+		if err != nil {
+			t.Errorf("SceneStore.Query() error = %v", err)
+			return
+		}
+		assert.Equal(t, count, result.Count)
+		for _, idx := range hiddenScenes {
+			assert.NotContains(t, result.IDs, sceneIDs[idx])
+		}
+
+		// performers with a restricted tag are hidden, including its children
+		for _, idx := range []int{performerIdxWithScene, performerIdxWithTag} {
+			p, err := db.Performer.Find(ctx, performerIDs[idx])
+			if err != nil {
+				t.Errorf("PerformerStore.Find() error = %v", err)
+				return
+			}
+			assert.Nil(t, p, "performer %d", idx)
+		}
+
+		// child studios are hidden
+		studio, err := db.Studio.Find(ctx, studioIDs[studioIdxWithGrandParent])
+		if err != nil {
+			t.Errorf("StudioStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, studio)
+
+		studio, err = db.Studio.Find(ctx, studioIDs[studioIdxWithScene])
+		if err != nil {
+			t.Errorf("StudioStore.Find() error = %v", err)
+			return
+		}
+		assert.NotNil(t, studio)
+	})
+
+	runWithRollbackTxn(t, "images galleries and markers", func(t *testing.T, ctx context.Context) {
+		p := &models.RestrictionProfile{
+			Name:      "family",
+			TagIDs:    []int{tagIDs[tagIdxWithPrimaryMarkers]},
+			StudioIDs: []int{studioIDs[studioIdxWithImage], studioIDs[studioIdxWithGallery]},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		ctx = models.WithRestrictionProfileIDs(ctx, []int{p.ID})
+
+		image, err := db.Image.Find(ctx, imageIDs[imageIdxWithStudio])
+		if err != nil {
+			t.Errorf("ImageStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, image)
+
+		gallery, err := db.Gallery.Find(ctx, galleryIDs[galleryIdxWithStudio])
+		if err != nil {
+			t.Errorf("GalleryStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, gallery)
+
+		markers, err := db.SceneMarker.FindBySceneID(ctx, sceneIDs[sceneIdxWithMarkers])
+		if err != nil {
+			t.Errorf("SceneMarkerStore.FindBySceneID() error = %v", err)
+			return
+		}
+		assert.Empty(t, markers)
+	})
+
+	runWithRollbackTxn(t, "child tags", func(t *testing.T, ctx context.Context) {
+		p := &models.RestrictionProfile{
+			Name:   "family",
+			TagIDs: []int{tagIDs[tagIdxWithGrandChild]},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		ctx = models.WithRestrictionProfileIDs(ctx, []int{p.ID})
+
+		performer, err := db.Performer.Find(ctx, performerIDs[performerIdxWithParentTag])
+		if err != nil {
+			t.Errorf("PerformerStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, performer)
+	})
+
+	runWithRollbackTxn(t, "filters", func(t *testing.T, ctx context.Context) {
+		p := &models.RestrictionProfile{
+			Name: "family",
+			SceneFilter: &models.SceneFilterType{
+				Title: &models.StringCriterionInput{
+					Value:    getSceneTitle(sceneIdxWithGallery),
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+			ImageFilter: &models.ImageFilterType{
+				ID: &models.IntCriterionInput{
+					Value:    imageIDs[imageIdxWithGallery],
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+			GalleryFilter: &models.GalleryFilterType{
+				Title: &models.StringCriterionInput{
+					Value:    "it's " + getGalleryStringValue(galleryIdxWithImage, titleField),
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		found, err := db.RestrictionProfile.Find(ctx, p.ID)
+		if err != nil {
+			t.Errorf("RestrictionProfileStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, p.SceneFilter, found.SceneFilter)
+		assert.Equal(t, p.ImageFilter, found.ImageFilter)
+		assert.Equal(t, p.GalleryFilter, found.GalleryFilter)
+
+		unrestrictedCount, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return
+		}
+
+		ctx = models.WithRestrictionProfileIDs(ctx, []int{p.ID})
+
+		s, err := db.Scene.Find(ctx, sceneIDs[sceneIdxWithGallery])
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, s)
+
+		count, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return
+		}
+		assert.Equal(t, unrestrictedCount-1, count)
+
+		image, err := db.Image.Find(ctx, imageIDs[imageIdxWithGallery])
+		if err != nil {
+			t.Errorf("ImageStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, image)
+
+		// the quote in the title is embedded in the condition correctly
+		gallery, err := db.Gallery.Find(ctx, galleryIDs[galleryIdxWithImage])
+		if err != nil {
+			t.Errorf("GalleryStore.Find() error = %v", err)
+			return
+		}
+		assert.NotNil(t, gallery)
+	})
+
+	runWithRollbackTxn(t, "invalid filter", func(t *testing.T, ctx context.Context) {
+		p := &models.RestrictionProfile{
+			Name: "family",
+			SceneFilter: &models.SceneFilterType{
+				And: &models.SceneFilterType{},
+				Or:  &models.SceneFilterType{},
+			},
+		}
+		err := db.RestrictionProfile.Create(ctx, p)
+		assert.Error(t, err)
+	})
+
+	runWithRollbackTxn(t, "counts", func(t *testing.T, ctx context.Context) {
+		// hide the scenes by id, so that the related objects remain visible
+		var sceneFilter *models.SceneFilterType
+		for _, idx := range []int{sceneIdxWithPerformer, sceneIdxWithStudio, sceneIdxWithTag, sceneIdxWithMarkers, sceneIdxWithMovie} {
+			sceneFilter = &models.SceneFilterType{
+				ID: &models.IntCriterionInput{
+					Value:    sceneIDs[idx],
+					Modifier: models.CriterionModifierEquals,
+				},
+				Or: sceneFilter,
+			}
+		}
+
+		p := &models.RestrictionProfile{
+			Name:         "family",
+			StudioIDs:    []int{studioIDs[studioIdxWithMovie]},
+			PerformerIDs: []int{performerIDs[performerIdxWithTag]},
+			SceneFilter:  sceneFilter,
+			ImageFilter: &models.ImageFilterType{
+				ID: &models.IntCriterionInput{
+					Value:    imageIDs[imageIdxWithGallery],
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+			GalleryFilter: &models.GalleryFilterType{
+				ID: &models.IntCriterionInput{
+					Value:    galleryIDs[galleryIdx1WithImage],
+					Modifier: models.CriterionModifierEquals,
+				},
+			},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		counts := []struct {
+			name       string
+			count      func(ctx context.Context) (int, error)
+			restricted int
+		}{
+			{"SceneStore.CountByPerformerID", func(ctx context.Context) (int, error) {
+				return db.Scene.CountByPerformerID(ctx, performerIDs[performerIdxWithScene])
+			}, 0},
+			{"SceneStore.CountByStudioID", func(ctx context.Context) (int, error) {
+				return db.Scene.CountByStudioID(ctx, studioIDs[studioIdxWithScene])
+			}, 0},
+			{"SceneStore.CountByTagID", func(ctx context.Context) (int, error) {
+				return db.Scene.CountByTagID(ctx, tagIDs[tagIdxWithScene])
+			}, 0},
+			{"SceneStore.CountByMovieID", func(ctx context.Context) (int, error) {
+				return db.Scene.CountByMovieID(ctx, movieIDs[movieIdxWithScene])
+			}, 0},
+			{"SceneMarkerStore.CountByTagID", func(ctx context.Context) (int, error) {
+				return db.SceneMarker.CountByTagID(ctx, tagIDs[tagIdxWithPrimaryMarkers])
+			}, 2},
+			{"PerformerStore.CountByTagID", func(ctx context.Context) (int, error) {
+				return db.Performer.CountByTagID(ctx, tagIDs[tagIdxWithPerformer])
+			}, 0},
+			{"MovieStore.CountByStudioID", func(ctx context.Context) (int, error) {
+				return db.Movie.CountByStudioID(ctx, studioIDs[studioIdxWithMovie])
+			}, 0},
+			{"ImageStore.CountByGalleryID", func(ctx context.Context) (int, error) {
+				return db.Image.CountByGalleryID(ctx, galleryIDs[galleryIdxWithImage])
+			}, 0},
+			{"GalleryStore.CountByImageID", func(ctx context.Context) (int, error) {
+				return db.Gallery.CountByImageID(ctx, imageIDs[imageIdxWithTwoGalleries])
+			}, 1},
+		}
+
+		restrictedCtx := models.WithRestrictionProfileIDs(ctx, []int{p.ID})
+		for _, c := range counts {
+			unrestricted, err := c.count(ctx)
+			if err != nil {
+				t.Errorf("%s() error = %v", c.name, err)
+				continue
+			}
+
+			restricted, err := c.count(restrictedCtx)
+			if err != nil {
+				t.Errorf("%s() error = %v", c.name, err)
+				continue
+			}
+
+			assert.Greater(t, unrestricted, restricted, c.name)
+			assert.Equal(t, c.restricted, restricted, c.name)
+		}
+	})
+
+	runWithRollbackTxn(t, "unknown profile", func(t *testing.T, ctx context.Context) {
+		// a deleted profile still held by a session hides everything
+		ctx = models.WithRestrictionProfileIDs(ctx, []int{-1})
+
+		count, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return
+		}
+		assert.Zero(t, count)
+
+		performer, err := db.Performer.Find(ctx, performerIDs[performerIdxWithScene])
+		if err != nil {
+			t.Errorf("PerformerStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, performer)
+
+		studio, err := db.Studio.Find(ctx, studioIDs[studioIdxWithScene])
+		if err != nil {
+			t.Errorf("StudioStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, studio)
+	})
+}
@@ -24,6 +24,7 @@ type savedFilterRow struct {
 	Mode   string `db:"mode"`
 	Name   string `db:"name"`
 	Filter string `db:"filter"`
+	Query  string `db:"query"`
 }
 
 func (r *savedFilterRow) fromSavedFilter(o models.SavedFilter) {
@@ -31,6 +32,7 @@ func (r *savedFilterRow) fromSavedFilter(o models.SavedFilter) {
 	r.Mode = string(o.Mode)
 	r.Name = o.Name
 	r.Filter = o.Filter
+	r.Query = o.Query
 }
 
 func (r *savedFilterRow) resolve() *models.SavedFilter {
@@ -39,6 +41,7 @@ func (r *savedFilterRow) resolve() *models.SavedFilter {
 		Name:   r.Name,
 		Mode:   models.FilterMode(r.Mode),
 		Filter: r.Filter,
+		Query:  r.Query,
 	}
 
 	return ret
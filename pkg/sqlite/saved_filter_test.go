@@ -89,11 +89,13 @@ func TestSavedFilterFindDefault(t *testing.T) {
 
 func TestSavedFilterSetDefault(t *testing.T) {
 	const newFilter = "foo"
+	const newQuery = "rating>50"
 
 	withTxn(func(ctx context.Context) error {
 		err := db.SavedFilter.SetDefault(ctx, &models.SavedFilter{
 			Mode:   models.FilterModeMovies,
 			Filter: newFilter,
+			Query:  newQuery,
 		})
 
 		return err
@@ -105,6 +107,7 @@ func TestSavedFilterSetDefault(t *testing.T) {
 		if err == nil {
 			defID = def.ID
 			assert.Equal(t, newFilter, def.Filter)
+			assert.Equal(t, newQuery, def.Query)
 		}
 
 		return err
@@ -32,6 +32,8 @@ const (
 	scenesGalleriesTable  = "scenes_galleries"
 	moviesScenesTable     = "movies_scenes"
 
+	scenesPlayActivityTable = "scenes_play_activity"
+
 	sceneCoverBlobColumn = "cover_blob"
 )
 
@@ -261,6 +263,8 @@ func (qb *SceneStore) Create(ctx context.Context, newObject *models.Scene, fileI
 		return err
 	}
 
+	sceneEditTracker.trackCreate(ctx, id)
+
 	if len(fileIDs) > 0 {
 		const firstPrimary = true
 		if err := scenesFilesTableMgr.insertJoins(ctx, id, firstPrimary, fileIDs); err != nil {
@@ -278,6 +282,11 @@ func (qb *SceneStore) Create(ctx context.Context, newObject *models.Scene, fileI
 			return err
 		}
 	}
+	if newObject.PerformerIDs.Loaded() || newObject.TagIDs.Loaded() {
+		if err := applyTagImplications(ctx, sceneTagImplications, id); err != nil {
+			return err
+		}
+	}
 
 	if newObject.GalleryIDs.Loaded() {
 		if err := scenesGalleriesTableMgr.insertJoins(ctx, id, newObject.GalleryIDs.List()); err != nil {
@@ -308,6 +317,10 @@ func (qb *SceneStore) Create(ctx context.Context, newObject *models.Scene, fileI
 }
 
 func (qb *SceneStore) UpdatePartial(ctx context.Context, id int, partial models.ScenePartial) (*models.Scene, error) {
+	if err := sceneEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := sceneRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -316,6 +329,14 @@ func (qb *SceneStore) UpdatePartial(ctx context.Context, id int, partial models.
 
 	r.fromPartial(partial)
 
+	if userID, ok := models.ActivityUserIDFromContext(ctx); ok {
+		if activity := splitActivityRecord(r.Record); len(activity) > 0 {
+			if err := qb.updateUserActivity(ctx, id, userID, activity); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if len(r.Record) > 0 {
 		if err := qb.tableMgr.updateByID(ctx, id, r.Record); err != nil {
 			return nil, err
@@ -332,6 +353,11 @@ func (qb *SceneStore) UpdatePartial(ctx context.Context, id int, partial models.
 			return nil, err
 		}
 	}
+	if partial.PerformerIDs != nil || partial.TagIDs != nil {
+		if err := applyTagImplications(ctx, sceneTagImplications, id); err != nil {
+			return nil, err
+		}
+	}
 	if partial.GalleryIDs != nil {
 		if err := scenesGalleriesTableMgr.modifyJoins(ctx, id, partial.GalleryIDs.IDs, partial.GalleryIDs.Mode); err != nil {
 			return nil, err
@@ -357,9 +383,29 @@ func (qb *SceneStore) UpdatePartial(ctx context.Context, id int, partial models.
 }
 
 func (qb *SceneStore) Update(ctx context.Context, updatedObject *models.Scene) error {
+	if err := sceneEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r sceneRow
 	r.fromScene(*updatedObject)
 
+	if userID, ok := models.ActivityUserIDFromContext(ctx); ok {
+		if err := qb.updateUserActivity(ctx, updatedObject.ID, userID, userActivityRecord(*updatedObject)); err != nil {
+			return err
+		}
+
+		// preserve the activity of the owner
+		owner, err := qb.getOwnerActivity(ctx, updatedObject.ID)
+		if err != nil {
+			return err
+		}
+
+		s := *updatedObject
+		setSceneActivity(&s, owner)
+		r.fromScene(s)
+	}
+
 	if err := qb.tableMgr.updateByID(ctx, updatedObject.ID, r); err != nil {
 		return err
 	}
@@ -376,6 +422,12 @@ func (qb *SceneStore) Update(ctx context.Context, updatedObject *models.Scene) e
 		}
 	}
 
+	if updatedObject.PerformerIDs.Loaded() || updatedObject.TagIDs.Loaded() {
+		if err := applyTagImplications(ctx, sceneTagImplications, updatedObject.ID); err != nil {
+			return err
+		}
+	}
+
 	if updatedObject.GalleryIDs.Loaded() {
 		if err := scenesGalleriesTableMgr.replaceJoins(ctx, updatedObject.ID, updatedObject.GalleryIDs.List()); err != nil {
 			return err
@@ -450,9 +502,12 @@ func (qb *SceneStore) FindMany(ctx context.Context, ids []int) ([]*models.Scene,
 		return nil, err
 	}
 
-	for i := range scenes {
-		if scenes[i] == nil {
-			return nil, fmt.Errorf("scene with id %d not found", ids[i])
+	// content hidden by a restriction is left nil, as if it did not exist
+	if !models.IsRestricted(ctx) {
+		for i := range scenes {
+			if scenes[i] == nil {
+				return nil, fmt.Errorf("scene with id %d not found", ids[i])
+			}
 		}
 	}
 
@@ -498,6 +553,8 @@ func (qb *SceneStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.S
 }
 
 func (qb *SceneStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Scene, error) {
+	q = restrictDataset(q, sceneRestrictionCondition(ctx))
+
 	const single = false
 	var ret []*models.Scene
 	var lastID int
@@ -519,6 +576,12 @@ func (qb *SceneStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*mo
 		return nil, err
 	}
 
+	if userID, ok := models.ActivityUserIDFromContext(ctx); ok && len(ret) > 0 {
+		if err := qb.loadUserActivity(ctx, userID, ret); err != nil {
+			return nil, err
+		}
+	}
+
 	return ret, nil
 }
 
@@ -689,6 +752,7 @@ func (qb *SceneStore) CountByPerformerID(ctx context.Context, performerID int) (
 	joinTable := scenesPerformersJoinTable
 
 	q := dialect.Select(goqu.COUNT("*")).From(joinTable).Where(joinTable.Col(performerIDColumn).Eq(performerID))
+	q = restrictDataset(q, relatedRestrictionCondition(sceneTable, "performers_scenes.scene_id", sceneRestrictionCondition(ctx)))
 	return count(ctx, q)
 }
 
@@ -697,6 +761,7 @@ func (qb *SceneStore) OCountByPerformerID(ctx context.Context, performerID int)
 	joinTable := scenesPerformersJoinTable
 
 	q := dialect.Select(goqu.COALESCE(goqu.SUM("o_counter"), 0)).From(table).InnerJoin(joinTable, goqu.On(table.Col(idColumn).Eq(joinTable.Col(sceneIDColumn)))).Where(joinTable.Col(performerIDColumn).Eq(performerID))
+	q = restrictDataset(q, sceneRestrictionCondition(ctx))
 	var ret int
 	if err := querySimple(ctx, q, &ret); err != nil {
 		return 0, err
@@ -722,11 +787,13 @@ func (qb *SceneStore) CountByMovieID(ctx context.Context, movieID int) (int, err
 	joinTable := scenesMoviesJoinTable
 
 	q := dialect.Select(goqu.COUNT("*")).From(joinTable).Where(joinTable.Col(movieIDColumn).Eq(movieID))
+	q = restrictDataset(q, relatedRestrictionCondition(sceneTable, "movies_scenes.scene_id", sceneRestrictionCondition(ctx)))
 	return count(ctx, q)
 }
 
 func (qb *SceneStore) Count(ctx context.Context) (int, error) {
 	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	q = restrictDataset(q, sceneRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -742,6 +809,7 @@ func (qb *SceneStore) Size(ctx context.Context) (float64, error) {
 		fileTable,
 		goqu.On(scenesFilesJoinTable.Col(fileIDColumn).Eq(fileTable.Col(idColumn))),
 	)
+	q = restrictDataset(q, sceneRestrictionCondition(ctx))
 	var ret float64
 	if err := querySimple(ctx, q, &ret); err != nil {
 		return 0, err
@@ -762,6 +830,7 @@ func (qb *SceneStore) Duration(ctx context.Context) (float64, error) {
 		videoFileTable,
 		goqu.On(videoFileTable.Col("file_id").Eq(scenesFilesJoinTable.Col("file_id"))),
 	)
+	q = restrictDataset(q, sceneRestrictionCondition(ctx))
 
 	var ret float64
 	if err := querySimple(ctx, q, &ret); err != nil {
@@ -775,6 +844,7 @@ func (qb *SceneStore) CountByStudioID(ctx context.Context, studioID int) (int, e
 	table := qb.table()
 
 	q := dialect.Select(goqu.COUNT("*")).From(table).Where(table.Col(studioIDColumn).Eq(studioID))
+	q = restrictDataset(q, sceneRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -782,6 +852,7 @@ func (qb *SceneStore) CountByTagID(ctx context.Context, tagID int) (int, error)
 	joinTable := scenesTagsJoinTable
 
 	q := dialect.Select(goqu.COUNT("*")).From(joinTable).Where(joinTable.Col(tagIDColumn).Eq(tagID))
+	q = restrictDataset(q, relatedRestrictionCondition(sceneTable, "scenes_tags.scene_id", sceneRestrictionCondition(ctx)))
 	return count(ctx, q)
 }
 
@@ -908,19 +979,20 @@ func (qb *SceneStore) makeFilter(ctx context.Context, sceneFilter *models.SceneF
 	query.handleCriterion(ctx, criterionHandlerFunc(func(ctx context.Context, f *filterBuilder) {
 		if sceneFilter.Phash != nil {
 			// backwards compatibility
-			scenePhashDistanceCriterionHandler(qb, &models.PhashDistanceCriterionInput{
+			phashDistanceCriterionHandler(&models.PhashDistanceCriterionInput{
 				Value:    sceneFilter.Phash.Value,
 				Modifier: sceneFilter.Phash.Modifier,
-			})(ctx, f)
+			}, scenePhashFiles)(ctx, f)
 		}
 	}))
 
-	query.handleCriterion(ctx, scenePhashDistanceCriterionHandler(qb, sceneFilter.PhashDistance))
+	query.handleCriterion(ctx, phashDistanceCriterionHandler(sceneFilter.PhashDistance, scenePhashFiles))
+	query.handleCriterion(ctx, phashSimilarToCriterionHandler(sceneFilter.PhashSimilarTo, scenePhashFiles))
 
-	query.handleCriterion(ctx, intCriterionHandler(sceneFilter.Rating100, "scenes.rating", nil))
+	query.handleCriterion(ctx, intCriterionHandler(sceneFilter.Rating100, sceneActivityColumn(ctx, "rating"), nil))
 	// legacy rating handler
-	query.handleCriterion(ctx, rating5CriterionHandler(sceneFilter.Rating, "scenes.rating", nil))
-	query.handleCriterion(ctx, intCriterionHandler(sceneFilter.OCounter, "scenes.o_counter", nil))
+	query.handleCriterion(ctx, rating5CriterionHandler(sceneFilter.Rating, sceneActivityColumn(ctx, "rating"), nil))
+	query.handleCriterion(ctx, intCriterionHandler(sceneFilter.OCounter, sceneActivityColumn(ctx, "o_counter"), nil))
 	query.handleCriterion(ctx, boolCriterionHandler(sceneFilter.Organized, "scenes.organized", nil))
 
 	query.handleCriterion(ctx, floatIntCriterionHandler(sceneFilter.Duration, "video_files.duration", qb.addVideoFilesTable))
@@ -948,9 +1020,9 @@ func (qb *SceneStore) makeFilter(ctx context.Context, sceneFilter *models.SceneF
 
 	query.handleCriterion(ctx, sceneCaptionCriterionHandler(qb, sceneFilter.Captions))
 
-	query.handleCriterion(ctx, floatIntCriterionHandler(sceneFilter.ResumeTime, "scenes.resume_time", nil))
-	query.handleCriterion(ctx, floatIntCriterionHandler(sceneFilter.PlayDuration, "scenes.play_duration", nil))
-	query.handleCriterion(ctx, intCriterionHandler(sceneFilter.PlayCount, "scenes.play_count", nil))
+	query.handleCriterion(ctx, floatIntCriterionHandler(sceneFilter.ResumeTime, sceneActivityColumn(ctx, "resume_time"), nil))
+	query.handleCriterion(ctx, floatIntCriterionHandler(sceneFilter.PlayDuration, sceneActivityColumn(ctx, "play_duration"), nil))
+	query.handleCriterion(ctx, intCriterionHandler(sceneFilter.PlayCount, sceneActivityColumn(ctx, "play_count"), nil))
 
 	query.handleCriterion(ctx, sceneTagsCriterionHandler(qb, sceneFilter.Tags))
 	query.handleCriterion(ctx, sceneTagCountCriterionHandler(qb, sceneFilter.TagCount))
@@ -961,7 +1033,7 @@ func (qb *SceneStore) makeFilter(ctx context.Context, sceneFilter *models.SceneF
 	query.handleCriterion(ctx, scenePerformerTagsCriterionHandler(qb, sceneFilter.PerformerTags))
 	query.handleCriterion(ctx, scenePerformerFavoriteCriterionHandler(sceneFilter.PerformerFavorite))
 	query.handleCriterion(ctx, scenePerformerAgeCriterionHandler(sceneFilter.PerformerAge))
-	query.handleCriterion(ctx, scenePhashDuplicatedCriterionHandler(sceneFilter.Duplicated, qb.addSceneFilesTable))
+	query.handleCriterion(ctx, phashDuplicatedCriterionHandler(sceneFilter.Duplicated, scenePhashFiles))
 	query.handleCriterion(ctx, dateCriterionHandler(sceneFilter.Date, "scenes.date"))
 	query.handleCriterion(ctx, timestampCriterionHandler(sceneFilter.CreatedAt, "scenes.created_at"))
 	query.handleCriterion(ctx, timestampCriterionHandler(sceneFilter.UpdatedAt, "scenes.updated_at"))
@@ -998,8 +1070,11 @@ func (qb *SceneStore) makeQuery(ctx context.Context, sceneFilter *models.SceneFi
 
 	query := qb.newQuery()
 	distinctIDs(&query, sceneTable)
+	query.addWhere(sceneRestrictionCondition(ctx))
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		query.addJoins(
 			join{
 				table:    scenesFilesTable,
@@ -1037,8 +1112,14 @@ func (qb *SceneStore) makeQuery(ctx context.Context, sceneFilter *models.SceneFi
 		return nil, err
 	}
 
-	qb.setSceneSort(&query, findFilter)
-	query.sortAndPagination += getPagination(findFilter)
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, getSceneKeysetSorts(ctx), findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		qb.setSceneSort(ctx, &query, findFilter)
+		query.sortAndPagination += getPagination(findFilter)
+	}
 
 	return &query, nil
 }
@@ -1124,6 +1205,34 @@ func (qb *SceneStore) queryGroupedFields(ctx context.Context, options models.Sce
 	return ret, nil
 }
 
+var sceneFacets = facetDefinitions{
+	models.FacetTypeTag:       relatedFacet(scenesTagsTable, sceneIDColumn, tagTable, tagIDColumn, "name"),
+	models.FacetTypePerformer: relatedFacet(performersScenesTable, sceneIDColumn, performerTable, performerIDColumn, "name"),
+	models.FacetTypeMovie:     relatedFacet(moviesScenesTable, sceneIDColumn, movieTable, movieIDColumn, "name"),
+	models.FacetTypeGallery:   relatedFacet(scenesGalleriesTable, sceneIDColumn, galleryTable, galleryIDColumn, "title"),
+	models.FacetTypeStudio:    studioFacet(sceneTable),
+	models.FacetTypeYear:      yearFacet(sceneTable, "date"),
+	models.FacetTypeResolution: {
+		joins: "INNER JOIN scenes_files ON scenes_files.scene_id = temp.id AND scenes_files.`primary` = 1 INNER JOIN video_files ON video_files.file_id = scenes_files.file_id",
+		value: resolutionExpression("video_files.height", "video_files.width"),
+		label: resolutionExpression("video_files.height", "video_files.width"),
+	},
+	models.FacetTypeCodec: {
+		joins: "INNER JOIN scenes_files ON scenes_files.scene_id = temp.id AND scenes_files.`primary` = 1 INNER JOIN video_files ON video_files.file_id = scenes_files.file_id",
+		value: "NULLIF(video_files.video_codec, '')",
+		label: "NULLIF(video_files.video_codec, '')",
+	},
+}
+
+func (qb *SceneStore) Facets(ctx context.Context, sceneFilter *models.SceneFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, sceneFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, sceneFacets, options)
+}
+
 func (qb *SceneStore) QueryCount(ctx context.Context, sceneFilter *models.SceneFilterType, findFilter *models.FindFilterType) (int, error) {
 	query, err := qb.makeQuery(ctx, sceneFilter, findFilter)
 	if err != nil {
@@ -1143,26 +1252,6 @@ func sceneFileCountCriterionHandler(qb *SceneStore, fileCount *models.IntCriteri
 	return h.handler(fileCount)
 }
 
-func scenePhashDuplicatedCriterionHandler(duplicatedFilter *models.PHashDuplicationCriterionInput, addJoinFn func(f *filterBuilder)) criterionHandlerFunc {
-	return func(ctx context.Context, f *filterBuilder) {
-		// TODO: Wishlist item: Implement Distance matching
-		if duplicatedFilter != nil {
-			if addJoinFn != nil {
-				addJoinFn(f)
-			}
-
-			var v string
-			if *duplicatedFilter.Duplicated {
-				v = ">"
-			} else {
-				v = "="
-			}
-
-			f.addInnerJoin("(SELECT file_id FROM files_fingerprints INNER JOIN (SELECT fingerprint FROM files_fingerprints WHERE type = 'phash' GROUP BY fingerprint HAVING COUNT (fingerprint) "+v+" 1) dupes on files_fingerprints.fingerprint = dupes.fingerprint)", "scph", "scenes_files.file_id = scph.file_id")
-		}
-	}
-}
-
 func floatIntCriterionHandler(durationFilter *models.IntCriterionInput, column string, addJoinFn func(f *filterBuilder)) criterionHandlerFunc {
 	return func(ctx context.Context, f *filterBuilder) {
 		if durationFilter != nil {
@@ -1382,50 +1471,47 @@ func scenePerformerTagsCriterionHandler(qb *SceneStore, tags *models.Hierarchica
 	}
 }
 
-func scenePhashDistanceCriterionHandler(qb *SceneStore, phashDistance *models.PhashDistanceCriterionInput) criterionHandlerFunc {
-	return func(ctx context.Context, f *filterBuilder) {
-		if phashDistance != nil {
-			qb.addSceneFilesTable(f)
-			f.addLeftJoin(fingerprintTable, "fingerprints_phash", "scenes_files.file_id = fingerprints_phash.file_id AND fingerprints_phash.type = 'phash'")
-
-			value, _ := utils.StringToPhash(phashDistance.Value)
-			distance := 0
-			if phashDistance.Distance != nil {
-				distance = *phashDistance.Distance
-			}
+var sceneKeysetSorts = keysetSorts{
+	"title":           "COALESCE(scenes.title, (SELECT files.basename FROM scenes_files INNER JOIN files ON files.id = scenes_files.file_id WHERE scenes_files.scene_id = scenes.id AND scenes_files.`primary` = 1), '') COLLATE NATURAL_CI",
+	"date":            "COALESCE(scenes.date, '')",
+	"rating":          "COALESCE(scenes.rating, 0)",
+	"o_counter":       "scenes.o_counter",
+	"play_count":      "scenes.play_count",
+	"last_played_at":  "COALESCE(scenes.last_played_at, '')",
+	"created_at":      "scenes.created_at",
+	"updated_at":      "scenes.updated_at",
+	"tag_count":       getCountKeyset(sceneTable, scenesTagsTable, sceneIDColumn),
+	"performer_count": getCountKeyset(sceneTable, performersScenesTable, sceneIDColumn),
+}
 
-			if distance == 0 {
-				// use the default handler
-				intCriterionHandler(&models.IntCriterionInput{
-					Value:    int(value),
-					Modifier: phashDistance.Modifier,
-				}, "fingerprints_phash.fingerprint", nil)(ctx, f)
-			}
+// getSceneKeysetSorts returns the keyset sorts of scenes, sorting by the
+// activity of the user set in the context.
+func getSceneKeysetSorts(ctx context.Context) keysetSorts {
+	if _, ok := models.ActivityUserIDFromContext(ctx); !ok {
+		return sceneKeysetSorts
+	}
 
-			switch {
-			case phashDistance.Modifier == models.CriterionModifierEquals && distance > 0:
-				// needed to avoid a type mismatch
-				f.addWhere("typeof(fingerprints_phash.fingerprint) = 'integer'")
-				f.addWhere("phash_distance(fingerprints_phash.fingerprint, ?) < ?", value, distance)
-			case phashDistance.Modifier == models.CriterionModifierNotEquals && distance > 0:
-				// needed to avoid a type mismatch
-				f.addWhere("typeof(fingerprints_phash.fingerprint) = 'integer'")
-				f.addWhere("phash_distance(fingerprints_phash.fingerprint, ?) > ?", value, distance)
-			default:
-				intCriterionHandler(&models.IntCriterionInput{
-					Value:    int(value),
-					Modifier: phashDistance.Modifier,
-				}, "fingerprints_phash.fingerprint", nil)(ctx, f)
-			}
-		}
+	ret := make(keysetSorts, len(sceneKeysetSorts))
+	for k, v := range sceneKeysetSorts {
+		ret[k] = v
 	}
+
+	ret["rating"] = "COALESCE(" + sceneActivityColumn(ctx, "rating") + ", 0)"
+	ret["o_counter"] = sceneActivityColumn(ctx, "o_counter")
+	ret["play_count"] = sceneActivityColumn(ctx, "play_count")
+	ret["last_played_at"] = "COALESCE(" + sceneActivityColumn(ctx, "last_played_at") + ", '')"
+
+	return ret
 }
 
-func (qb *SceneStore) setSceneSort(query *queryBuilder, findFilter *models.FindFilterType) {
+func (qb *SceneStore) setSceneSort(ctx context.Context, query *queryBuilder, findFilter *models.FindFilterType) {
 	if findFilter == nil || findFilter.Sort == nil || *findFilter.Sort == "" {
 		return
 	}
 	sort := findFilter.GetSort("title")
+	if sort == relevanceSort && query.fullTextSearch == "" {
+		sort = "title"
+	}
 
 	addFileTable := func() {
 		query.addJoins(
@@ -1514,7 +1600,11 @@ func (qb *SceneStore) setSceneSort(query *queryBuilder, findFilter *models.FindF
 		query.sortAndPagination += " ORDER BY COALESCE(scenes.title, files.basename) COLLATE NATURAL_CI " + direction + ", folders.path COLLATE NATURAL_CI " + direction
 	case "play_count":
 		// handle here since getSort has special handling for _count suffix
-		query.sortAndPagination += " ORDER BY scenes.play_count " + direction
+		query.sortAndPagination += " ORDER BY " + sceneActivityColumn(ctx, "play_count") + " " + direction
+	case "rating", "o_counter", "last_played_at", "resume_time", "play_duration":
+		query.sortAndPagination += " ORDER BY " + sceneActivityColumn(ctx, sort) + " " + getSortDirection(direction)
+	case relevanceSort:
+		query.sortAndPagination += query.getRelevanceSort(direction)
 	default:
 		query.sortAndPagination += getSort(sort, direction, "scenes")
 	}
@@ -1545,6 +1635,8 @@ func (qb *SceneStore) SaveActivity(ctx context.Context, id int, resumeTime *floa
 		return false, err
 	}
 
+	userID, hasUser := models.ActivityUserIDFromContext(ctx)
+
 	record := goqu.Record{}
 
 	if resumeTime != nil {
@@ -1555,12 +1647,28 @@ func (qb *SceneStore) SaveActivity(ctx context.Context, id int, resumeTime *floa
 		record["play_duration"] = goqu.L("play_duration + ?", playDuration)
 	}
 
-	if len(record) > 0 {
+	if hasUser {
+		if err := qb.updateUserActivity(ctx, id, userID, exp.Record(record)); err != nil {
+			return false, err
+		}
+	} else if len(record) > 0 {
 		if err := qb.tableMgr.updateByID(ctx, id, record); err != nil {
 			return false, err
 		}
 	}
 
+	if playDuration != nil && *playDuration > 0 {
+		// record when the scene was played for watch time statistics
+		q := dialect.Insert(scenesPlayActivityJoinTable).Prepared(true).Rows(goqu.Record{
+			sceneIDColumn: id,
+			"played_at":   time.Now(),
+			"duration":    *playDuration,
+		})
+		if _, err := exec(ctx, q); err != nil {
+			return false, fmt.Errorf("inserting play activity: %w", err)
+		}
+	}
+
 	return true, nil
 }
 
@@ -1569,10 +1677,20 @@ func (qb *SceneStore) IncrementWatchCount(ctx context.Context, id int) (int, err
 		return 0, err
 	}
 
-	if err := qb.tableMgr.updateByID(ctx, id, goqu.Record{
+	record := goqu.Record{
 		"play_count":     goqu.L("play_count + 1"),
 		"last_played_at": time.Now(),
-	}); err != nil {
+	}
+
+	if userID, ok := models.ActivityUserIDFromContext(ctx); ok {
+		if err := qb.updateUserActivity(ctx, id, userID, exp.Record(record)); err != nil {
+			return 0, err
+		}
+
+		return qb.getUserActivityInt(ctx, id, userID, "play_count")
+	}
+
+	if err := qb.tableMgr.updateByID(ctx, id, record); err != nil {
 		return 0, err
 	}
 
@@ -1700,6 +1818,10 @@ func (qb *SceneStore) GetGalleryIDs(ctx context.Context, id int) ([]int, error)
 }
 
 func (qb *SceneStore) AddGalleryIDs(ctx context.Context, sceneID int, galleryIDs []int) error {
+	if err := sceneEditTracker.track(ctx, sceneID); err != nil {
+		return err
+	}
+
 	return scenesGalleriesTableMgr.addJoins(ctx, sceneID, galleryIDs)
 }
 
@@ -20,7 +20,7 @@ const sceneMarkerTable = "scene_markers"
 const countSceneMarkersForTagQuery = `
 SELECT scene_markers.id FROM scene_markers
 LEFT JOIN scene_markers_tags as tags_join on tags_join.scene_marker_id = scene_markers.id
-WHERE tags_join.tag_id = ? OR scene_markers.primary_tag_id = ?
+WHERE (tags_join.tag_id = ? OR scene_markers.primary_tag_id = ?)%s
 GROUP BY scene_markers.id
 `
 
@@ -140,9 +140,12 @@ func (qb *SceneMarkerStore) FindMany(ctx context.Context, ids []int) ([]*models.
 		ret[i] = s
 	}
 
-	for i := range ret {
-		if ret[i] == nil {
-			return nil, fmt.Errorf("scene marker with id %d not found", ids[i])
+	// content hidden by a restriction is left nil, as if it did not exist
+	if !models.IsRestricted(ctx) {
+		for i := range ret {
+			if ret[i] == nil {
+				return nil, fmt.Errorf("scene marker with id %d not found", ids[i])
+			}
 		}
 	}
 
@@ -176,6 +179,8 @@ func (qb *SceneMarkerStore) get(ctx context.Context, q *goqu.SelectDataset) (*mo
 }
 
 func (qb *SceneMarkerStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.SceneMarker, error) {
+	q = restrictDataset(q, sceneMarkerRestrictionCondition(ctx))
+
 	const single = false
 	var ret []*models.SceneMarker
 	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
@@ -196,9 +201,14 @@ func (qb *SceneMarkerStore) getMany(ctx context.Context, q *goqu.SelectDataset)
 }
 
 func (qb *SceneMarkerStore) FindBySceneID(ctx context.Context, sceneID int) ([]*models.SceneMarker, error) {
+	where := "scene_markers.scene_id = ?"
+	if restriction := sceneMarkerRestrictionCondition(ctx); restriction != "" {
+		where += " AND " + restriction
+	}
+
 	query := `
 		SELECT scene_markers.* FROM scene_markers
-		WHERE scene_markers.scene_id = ?
+		WHERE ` + where + `
 		GROUP BY scene_markers.id
 		ORDER BY scene_markers.seconds ASC
 	`
@@ -207,8 +217,13 @@ func (qb *SceneMarkerStore) FindBySceneID(ctx context.Context, sceneID int) ([]*
 }
 
 func (qb *SceneMarkerStore) CountByTagID(ctx context.Context, tagID int) (int, error) {
+	var restriction string
+	if condition := sceneMarkerRestrictionCondition(ctx); condition != "" {
+		restriction = " AND " + condition
+	}
+
 	args := []interface{}{tagID, tagID}
-	return qb.runCountQuery(ctx, qb.buildCountQuery(countSceneMarkersForTagQuery), args)
+	return qb.runCountQuery(ctx, qb.buildCountQuery(fmt.Sprintf(countSceneMarkersForTagQuery, restriction)), args)
 }
 
 func (qb *SceneMarkerStore) GetMarkerStrings(ctx context.Context, q *string, sort *string) ([]*models.MarkerStringsResultType, error) {
@@ -262,8 +277,11 @@ func (qb *SceneMarkerStore) makeQuery(ctx context.Context, sceneMarkerFilter *mo
 
 	query := qb.newQuery()
 	distinctIDs(&query, sceneMarkerTable)
+	query.addWhere(sceneMarkerRestrictionCondition(ctx))
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		searchColumns := []string{"scene_markers.title", "scenes.title"}
 		query.parseQueryString(searchColumns, *q)
 	}
@@ -274,7 +292,13 @@ func (qb *SceneMarkerStore) makeQuery(ctx context.Context, sceneMarkerFilter *mo
 		return nil, err
 	}
 
-	query.sortAndPagination = qb.getSceneMarkerSort(&query, findFilter) + getPagination(findFilter)
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, sceneMarkerKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		query.sortAndPagination = qb.getSceneMarkerSort(&query, findFilter) + getPagination(findFilter)
+	}
 
 	return &query, nil
 }
@@ -298,6 +322,28 @@ func (qb *SceneMarkerStore) Query(ctx context.Context, sceneMarkerFilter *models
 	return sceneMarkers, countResult, nil
 }
 
+var sceneMarkerFacets = facetDefinitions{
+	models.FacetTypeTag: {
+		joins: "INNER JOIN scene_markers ON scene_markers.id = temp.id LEFT JOIN scene_markers_tags ON scene_markers_tags.scene_marker_id = scene_markers.id INNER JOIN tags ON tags.id = scene_markers.primary_tag_id OR tags.id = scene_markers_tags.tag_id",
+		value: "tags.id",
+		label: "tags.name",
+	},
+	models.FacetTypePerformer: {
+		joins: "INNER JOIN scene_markers ON scene_markers.id = temp.id INNER JOIN performers_scenes ON performers_scenes.scene_id = scene_markers.scene_id INNER JOIN performers ON performers.id = performers_scenes.performer_id",
+		value: "performers.id",
+		label: "performers.name",
+	},
+}
+
+func (qb *SceneMarkerStore) Facets(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, sceneMarkerFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, sceneMarkerFacets, options)
+}
+
 func (qb *SceneMarkerStore) QueryCount(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, findFilter *models.FindFilterType) (int, error) {
 	query, err := qb.makeQuery(ctx, sceneMarkerFilter, findFilter)
 	if err != nil {
@@ -436,10 +482,26 @@ func sceneMarkerPerformersCriterionHandler(qb *SceneMarkerStore, performers *mod
 	}
 }
 
+var sceneMarkerKeysetSorts = keysetSorts{
+	"title":      "scene_markers.title COLLATE NATURAL_CI",
+	"seconds":    "scene_markers.seconds",
+	"created_at": "scene_markers.created_at",
+	"updated_at": "scene_markers.updated_at",
+}
+
 func (qb *SceneMarkerStore) getSceneMarkerSort(query *queryBuilder, findFilter *models.FindFilterType) string {
 	sort := findFilter.GetSort("title")
 	direction := findFilter.GetDirection()
 	tableName := "scene_markers"
+	additional := ", scene_markers.scene_id ASC, scene_markers.seconds ASC"
+
+	if sort == relevanceSort {
+		if query.fullTextSearch != "" {
+			return query.getRelevanceSort(direction) + additional
+		}
+		sort = "title"
+	}
+
 	if sort == "scenes_updated_at" {
 		// ensure scene table is joined
 		query.join(sceneTable, "", "scenes.id = scene_markers.scene_id")
@@ -447,7 +509,6 @@ func (qb *SceneMarkerStore) getSceneMarkerSort(query *queryBuilder, findFilter *
 		tableName = "scenes"
 	}
 
-	additional := ", scene_markers.scene_id ASC, scene_markers.seconds ASC"
 	return getSort(sort, direction, tableName) + additional
 }
 
@@ -516,6 +577,7 @@ func (qb *SceneMarkerStore) UpdateTags(ctx context.Context, id int, tagIDs []int
 
 func (qb *SceneMarkerStore) Count(ctx context.Context) (int, error) {
 	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	q = restrictDataset(q, sceneMarkerRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/stashapp/stash/pkg/file"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/sqlite"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -3986,6 +3987,39 @@ func TestSceneQueryPhashDuplicated(t *testing.T) {
 		// -1 for missing phash
 		assert.Len(t, scenes, totalScenes-(dupeScenePhashes*2)-1)
 
+		duplicated = true
+		distance := 1
+		phashCriterion.Distance = &distance
+
+		scenes = queryScene(ctx, t, sqb, &sceneFilter, nil)
+		assert.Len(t, scenes, dupeScenePhashes*2)
+
+		duplicated = false
+
+		scenes = queryScene(ctx, t, sqb, &sceneFilter, nil)
+		assert.Len(t, scenes, totalScenes-(dupeScenePhashes*2)-1)
+
+		return nil
+	})
+}
+
+func TestSceneQueryPhashSimilarTo(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		sqb := db.Scene
+		sceneFilter := models.SceneFilterType{
+			PhashSimilarTo: &models.PhashSimilarityCriterionInput{
+				ID: strconv.Itoa(sceneIDs[0]),
+			},
+		}
+
+		// scenes at the end share phashes with those at the start
+		scenes := queryScene(ctx, t, sqb, &sceneFilter, nil)
+		assert.Equal(t, []int{sceneIDs[totalScenes-dupeScenePhashes]}, scenesToIDs(scenes))
+
+		sceneFilter.PhashSimilarTo.ID = strconv.Itoa(sceneIDs[sceneIdxMissingPhash])
+		scenes = queryScene(ctx, t, sqb, &sceneFilter, nil)
+		assert.Len(t, scenes, 0)
+
 		return nil
 	})
 }
@@ -4464,10 +4498,15 @@ func TestSceneQueryQTrim(t *testing.T) {
 			{query: " zzz    yyy    ", id: expectedID, count: 1},
 			{query: "   \"zzz yyy xxx\" ", id: expectedID, count: 1},
 			{query: "zzz", id: expectedID, count: 1},
-			{query: "\" zzz    yyy    \"", count: 0},
-			{query: "\"zzz    yyy\"", count: 0},
-			{query: "\" zzz yyy\"", count: 0},
-			{query: "\"zzz yyy  \"", count: 0},
+		}
+
+		// phrases are matched by word when using full-text search
+		phraseCount := 0
+		if sqlite.FullTextSearchEnabled {
+			phraseCount = 1
+		}
+		for _, q := range []string{"\" zzz    yyy    \"", "\"zzz    yyy\"", "\" zzz yyy\"", "\"zzz yyy  \""} {
+			tests = append(tests, test{query: q, id: expectedID, count: phraseCount})
 		}
 
 		for _, tst := range tests {
@@ -4730,3 +4769,81 @@ func TestSceneStore_SaveActivity(t *testing.T) {
 
 // TODO Count
 // TODO SizeCount
+
+func TestSceneFacets(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		sqb := db.Scene
+
+		facets, err := sqb.Facets(ctx, nil, nil, models.FacetOptions{
+			Facets: []models.FacetType{models.FacetTypeTag, models.FacetTypeStudio},
+		})
+		if err != nil {
+			t.Errorf("SceneStore.Facets() error = %v", err)
+			return nil
+		}
+
+		if !assert.Len(t, facets, 2) {
+			return nil
+		}
+		assert.Equal(t, models.FacetTypeTag, facets[0].Type)
+		assert.Equal(t, models.FacetTypeStudio, facets[1].Type)
+
+		for _, facet := range facets {
+			assert.NotEmpty(t, facet.Values)
+
+			for i, v := range facet.Values {
+				if i > 0 {
+					assert.GreaterOrEqual(t, facet.Values[i-1].Count, v.Count)
+				}
+
+				criterion := &models.HierarchicalMultiCriterionInput{
+					Value:    []string{v.Value},
+					Modifier: models.CriterionModifierIncludes,
+				}
+				sceneFilter := &models.SceneFilterType{}
+				if facet.Type == models.FacetTypeTag {
+					sceneFilter.Tags = criterion
+				} else {
+					sceneFilter.Studios = criterion
+				}
+
+				count, err := sqb.QueryCount(ctx, sceneFilter, nil)
+				if err != nil {
+					t.Errorf("SceneStore.QueryCount() error = %v", err)
+					return nil
+				}
+				assert.Equal(t, count, v.Count, "%s %s", facet.Type, v.Label)
+			}
+		}
+
+		// facets are limited to the filtered scenes
+		sceneFilter := &models.SceneFilterType{
+			Studios: &models.HierarchicalMultiCriterionInput{
+				Value:    []string{strconv.Itoa(studioIDs[studioIdxWithTwoScenes])},
+				Modifier: models.CriterionModifierIncludes,
+			},
+		}
+		facets, err = sqb.Facets(ctx, sceneFilter, nil, models.FacetOptions{
+			Facets: []models.FacetType{models.FacetTypeStudio, models.FacetTypeResolution},
+			Limit:  1,
+		})
+		if err != nil {
+			t.Errorf("SceneStore.Facets() error = %v", err)
+			return nil
+		}
+
+		assert.Equal(t, []*models.FacetValue{{
+			Value: strconv.Itoa(studioIDs[studioIdxWithTwoScenes]),
+			Label: getStudioStringValue(studioIdxWithTwoScenes, "Name"),
+			Count: 2,
+		}}, facets[0].Values)
+		assert.Len(t, facets[1].Values, 1)
+
+		_, err = sqb.Facets(ctx, nil, nil, models.FacetOptions{
+			Facets: []models.FacetType{models.FacetTypeGender},
+		})
+		assert.Error(t, err)
+
+		return nil
+	})
+}
@@ -0,0 +1,305 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	scenesUsersTable = "scenes_users"
+	userIDColumn     = "user_id"
+)
+
+// sceneActivityColumns are the columns of scenes which are stored in
+// scenes_users for users other than the owner.
+var sceneActivityColumns = []string{
+	"rating",
+	"o_counter",
+	"resume_time",
+	"play_duration",
+	"play_count",
+	"last_played_at",
+}
+
+type sceneUserRow struct {
+	SceneID int      `db:"scene_id"`
+	UserID  null.Int `db:"user_id"`
+	// expressed as 1-100
+	Rating       null.Int      `db:"rating"`
+	OCounter     int           `db:"o_counter"`
+	ResumeTime   float64       `db:"resume_time"`
+	PlayDuration float64       `db:"play_duration"`
+	PlayCount    int           `db:"play_count"`
+	LastPlayedAt NullTimestamp `db:"last_played_at"`
+}
+
+func (r *sceneUserRow) resolve() *models.SceneUserActivity {
+	return &models.SceneUserActivity{
+		SceneID:      r.SceneID,
+		UserID:       nullIntPtr(r.UserID),
+		Rating:       nullIntPtr(r.Rating),
+		OCounter:     r.OCounter,
+		ResumeTime:   r.ResumeTime,
+		PlayDuration: r.PlayDuration,
+		PlayCount:    r.PlayCount,
+		LastPlayedAt: r.LastPlayedAt.TimePtr(),
+	}
+}
+
+// setSceneActivity sets the activity fields of the scene to those of a.
+// The fields are reset if a is nil.
+func setSceneActivity(s *models.Scene, a *models.SceneUserActivity) {
+	if a == nil {
+		a = &models.SceneUserActivity{}
+	}
+
+	s.Rating = a.Rating
+	s.OCounter = a.OCounter
+	s.ResumeTime = a.ResumeTime
+	s.PlayDuration = a.PlayDuration
+	s.PlayCount = a.PlayCount
+	s.LastPlayedAt = a.LastPlayedAt
+}
+
+// sceneActivityColumn returns the expression for the activity column of
+// scenes for the user set in the context. The expression refers to the
+// scenes table.
+func sceneActivityColumn(ctx context.Context, column string) string {
+	userID, ok := models.ActivityUserIDFromContext(ctx)
+	if !ok {
+		return "scenes." + column
+	}
+
+	expr := fmt.Sprintf("(SELECT %[1]s.%[2]s FROM %[1]s WHERE %[1]s.scene_id = scenes.id AND %[1]s.user_id = %[3]d)", scenesUsersTable, column, userID)
+
+	switch column {
+	case "rating", "last_played_at":
+		return expr
+	default:
+		return "COALESCE(" + expr + ", 0)"
+	}
+}
+
+// splitActivityRecord removes the activity columns from the record,
+// returning them in a new record.
+func splitActivityRecord(record exp.Record) exp.Record {
+	ret := make(exp.Record)
+	for _, c := range sceneActivityColumns {
+		if v, ok := record[c]; ok {
+			ret[c] = v
+			delete(record, c)
+		}
+	}
+
+	return ret
+}
+
+func (qb *SceneStore) userActivitySelect() *goqu.SelectDataset {
+	return dialect.From(scenesUsersJoinTable).Select(
+		scenesUsersJoinTable.Col(sceneIDColumn),
+		scenesUsersJoinTable.Col(userIDColumn),
+		scenesUsersJoinTable.Col("rating"),
+		scenesUsersJoinTable.Col("o_counter"),
+		scenesUsersJoinTable.Col("resume_time"),
+		scenesUsersJoinTable.Col("play_duration"),
+		scenesUsersJoinTable.Col("play_count"),
+		scenesUsersJoinTable.Col("last_played_at"),
+	)
+}
+
+func (qb *SceneStore) getUserActivity(ctx context.Context, q *goqu.SelectDataset) ([]*models.SceneUserActivity, error) {
+	const single = false
+	var ret []*models.SceneUserActivity
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f sceneUserRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		ret = append(ret, f.resolve())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// getOwnerActivity returns the activity stored in the scenes table.
+func (qb *SceneStore) getOwnerActivity(ctx context.Context, sceneID int) (*models.SceneUserActivity, error) {
+	table := qb.table()
+	q := dialect.From(table).Select(
+		table.Col(idColumn).As(sceneIDColumn),
+		goqu.L("NULL").As(userIDColumn),
+		table.Col("rating"),
+		table.Col("o_counter"),
+		table.Col("resume_time"),
+		table.Col("play_duration"),
+		table.Col("play_count"),
+		table.Col("last_played_at"),
+	).Where(qb.tableMgr.byID(sceneID))
+
+	ret, err := qb.getUserActivity(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("scene with id %d not found", sceneID)
+	}
+
+	return ret[0], nil
+}
+
+func (qb *SceneStore) GetUserActivity(ctx context.Context, sceneID int) ([]*models.SceneUserActivity, error) {
+	owner, err := qb.getOwnerActivity(ctx, sceneID)
+	if err != nil {
+		return nil, err
+	}
+
+	q := qb.userActivitySelect().Where(
+		scenesUsersJoinTable.Col(sceneIDColumn).Eq(sceneID),
+	).Order(scenesUsersJoinTable.Col(userIDColumn).Asc())
+
+	users, err := qb.getUserActivity(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*models.SceneUserActivity{owner}, users...), nil
+}
+
+// loadUserActivity replaces the activity of the scenes with that of the
+// user.
+func (qb *SceneStore) loadUserActivity(ctx context.Context, userID int, scenes []*models.Scene) error {
+	ids := make([]int, len(scenes))
+	for i, s := range scenes {
+		ids[i] = s.ID
+	}
+
+	activity := make(map[int]*models.SceneUserActivity)
+	if err := batchExec(ids, defaultBatchSize, func(batch []int) error {
+		q := qb.userActivitySelect().Prepared(true).Where(
+			scenesUsersJoinTable.Col(userIDColumn).Eq(userID),
+			scenesUsersJoinTable.Col(sceneIDColumn).In(batch),
+		)
+
+		found, err := qb.getUserActivity(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range found {
+			activity[a.SceneID] = a
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("loading user activity: %w", err)
+	}
+
+	for _, s := range scenes {
+		setSceneActivity(s, activity[s.ID])
+	}
+
+	return nil
+}
+
+// updateUserActivity updates the activity of the scene for the user,
+// creating the row if necessary.
+func (qb *SceneStore) updateUserActivity(ctx context.Context, sceneID int, userID int, record exp.Record) error {
+	key := goqu.Ex{
+		sceneIDColumn: sceneID,
+		userIDColumn:  userID,
+	}
+
+	insert := dialect.Insert(scenesUsersJoinTable).Prepared(true).Rows(goqu.Record(key)).OnConflict(goqu.DoNothing())
+	if _, err := exec(ctx, insert); err != nil {
+		return fmt.Errorf("inserting into %s: %w", scenesUsersTable, err)
+	}
+
+	if len(record) == 0 {
+		return nil
+	}
+
+	update := dialect.Update(scenesUsersJoinTable).Prepared(true).Set(record).Where(key)
+	if _, err := exec(ctx, update); err != nil {
+		return fmt.Errorf("updating %s: %w", scenesUsersTable, err)
+	}
+
+	return nil
+}
+
+func (qb *SceneStore) getUserActivityInt(ctx context.Context, sceneID int, userID int, column string) (int, error) {
+	q := dialect.From(scenesUsersJoinTable).Select(column).Where(goqu.Ex{
+		sceneIDColumn: sceneID,
+		userIDColumn:  userID,
+	})
+
+	var ret int
+	if err := querySimple(ctx, q, &ret); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+// userActivityRecord returns the record of the activity of the scene.
+func userActivityRecord(s models.Scene) exp.Record {
+	return exp.Record{
+		"rating":         intFromPtr(s.Rating),
+		"o_counter":      s.OCounter,
+		"resume_time":    s.ResumeTime,
+		"play_duration":  s.PlayDuration,
+		"play_count":     s.PlayCount,
+		"last_played_at": NullTimestampFromTimePtr(s.LastPlayedAt),
+	}
+}
+
+func (qb *SceneStore) IncrementOCounter(ctx context.Context, id int) (int, error) {
+	userID, ok := models.ActivityUserIDFromContext(ctx)
+	if !ok {
+		return qb.oCounterManager.IncrementOCounter(ctx, id)
+	}
+
+	return qb.updateUserOCounter(ctx, id, userID, goqu.L("o_counter + 1"))
+}
+
+func (qb *SceneStore) DecrementOCounter(ctx context.Context, id int) (int, error) {
+	userID, ok := models.ActivityUserIDFromContext(ctx)
+	if !ok {
+		return qb.oCounterManager.DecrementOCounter(ctx, id)
+	}
+
+	return qb.updateUserOCounter(ctx, id, userID, goqu.L("MAX(o_counter - 1, 0)"))
+}
+
+func (qb *SceneStore) ResetOCounter(ctx context.Context, id int) (int, error) {
+	userID, ok := models.ActivityUserIDFromContext(ctx)
+	if !ok {
+		return qb.oCounterManager.ResetOCounter(ctx, id)
+	}
+
+	return qb.updateUserOCounter(ctx, id, userID, 0)
+}
+
+func (qb *SceneStore) updateUserOCounter(ctx context.Context, id int, userID int, value interface{}) (int, error) {
+	if err := qb.tableMgr.checkIDExists(ctx, id); err != nil {
+		return 0, err
+	}
+
+	if err := qb.updateUserActivity(ctx, id, userID, exp.Record{
+		"o_counter": value,
+	}); err != nil {
+		return 0, err
+	}
+
+	return qb.getUserActivityInt(ctx, id, userID, "o_counter")
+}
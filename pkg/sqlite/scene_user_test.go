@@ -0,0 +1,161 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSceneUserActivity(t *testing.T) {
+	runWithRollbackTxn(t, "user activity", func(t *testing.T, ctx context.Context) {
+		now := time.Now()
+		u := &models.User{
+			Username:  "viewer",
+			Role:      models.UserRoleViewer,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := db.User.Create(ctx, u); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		owner, err := db.Scene.Find(ctx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+
+		userCtx := models.WithActivityUserID(ctx, u.ID)
+
+		// the user starts without any activity
+		s, err := db.Scene.Find(userCtx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, s.Rating)
+		assert.Zero(t, s.OCounter)
+		assert.Zero(t, s.PlayCount)
+
+		rating := 80
+		if _, err := db.Scene.UpdatePartial(userCtx, sceneID, models.ScenePartial{
+			Rating:     models.NewOptionalInt(rating),
+			ResumeTime: models.NewOptionalFloat64(12),
+		}); err != nil {
+			t.Errorf("SceneStore.UpdatePartial() error = %v", err)
+			return
+		}
+
+		oCounter, err := db.Scene.IncrementOCounter(userCtx, sceneID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, oCounter)
+		}
+		oCounter, err = db.Scene.DecrementOCounter(userCtx, sceneID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, oCounter)
+		}
+		oCounter, err = db.Scene.DecrementOCounter(userCtx, sceneID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, oCounter)
+		}
+
+		playCount, err := db.Scene.IncrementWatchCount(userCtx, sceneID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, playCount)
+		}
+
+		s, err = db.Scene.Find(userCtx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, &rating, s.Rating)
+		assert.Equal(t, 12.0, s.ResumeTime)
+		assert.Equal(t, 1, s.PlayCount)
+		assert.NotNil(t, s.LastPlayedAt)
+
+		// the activity of the owner is unchanged
+		got, err := db.Scene.Find(ctx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, owner.Rating, got.Rating)
+		assert.Equal(t, owner.OCounter, got.OCounter)
+		assert.Equal(t, owner.ResumeTime, got.ResumeTime)
+		assert.Equal(t, owner.PlayCount, got.PlayCount)
+
+		// full updates preserve the activity of the owner
+		s.Title = "user activity"
+		if err := db.Scene.Update(userCtx, s); err != nil {
+			t.Errorf("SceneStore.Update() error = %v", err)
+			return
+		}
+		got, err = db.Scene.Find(ctx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, "user activity", got.Title)
+		assert.Equal(t, owner.Rating, got.Rating)
+		assert.Equal(t, owner.PlayCount, got.PlayCount)
+
+		// filters use the activity of the user
+		rating100 := models.IntCriterionInput{
+			Value:    rating,
+			Modifier: models.CriterionModifierEquals,
+		}
+		scenes := queryScene(userCtx, t, db.Scene, &models.SceneFilterType{
+			Rating100: &rating100,
+		}, nil)
+		if assert.Len(t, scenes, 1) {
+			assert.Equal(t, sceneID, scenes[0].ID)
+		}
+
+		// as do sorts
+		sort := "rating"
+		direction := models.SortDirectionEnumDesc
+		first := 1
+		scenes = queryScene(userCtx, t, db.Scene, nil, &models.FindFilterType{
+			Sort:      &sort,
+			Direction: &direction,
+			First:     &first,
+		})
+		if assert.Len(t, scenes, 1) {
+			assert.Equal(t, sceneID, scenes[0].ID)
+		}
+
+		activity, err := db.Scene.GetUserActivity(ctx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.GetUserActivity() error = %v", err)
+			return
+		}
+		if assert.Len(t, activity, 2) {
+			assert.Nil(t, activity[0].UserID)
+			assert.Equal(t, owner.PlayCount, activity[0].PlayCount)
+			assert.Equal(t, &u.ID, activity[1].UserID)
+			assert.Equal(t, &rating, activity[1].Rating)
+		}
+
+		totals := models.TotalSceneActivity(activity)
+		assert.Equal(t, owner.PlayCount+1, totals.PlayCount)
+
+		// destroying the user destroys their activity
+		if err := db.User.Destroy(ctx, u.ID); err != nil {
+			t.Errorf("UserStore.Destroy() error = %v", err)
+			return
+		}
+		activity, err = db.Scene.GetUserActivity(ctx, sceneID)
+		if assert.NoError(t, err) {
+			assert.Len(t, activity, 1)
+		}
+	})
+}
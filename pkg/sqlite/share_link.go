@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	shareLinkTable = "share_links"
+)
+
+// shareLinkRow has a column for each type of entity, so that links are
+// deleted with the entity they share. Only one of them is set.
+type shareLinkRow struct {
+	ID        int      `db:"id" goqu:"skipinsert"`
+	UserID    null.Int `db:"user_id"`
+	SceneID   null.Int `db:"scene_id"`
+	GalleryID null.Int `db:"gallery_id"`
+	ImageID   null.Int `db:"image_id"`
+	// RestrictionProfileID references restriction_profiles, which may not
+	// be deleted while links use them.
+	RestrictionProfileID null.Int  `db:"restriction_profile_id"`
+	AllowDownload        bool      `db:"allow_download"`
+	ExpiresAt            Timestamp `db:"expires_at"`
+	CreatedAt            Timestamp `db:"created_at"`
+}
+
+func (r *shareLinkRow) fromShareLink(o models.ShareLink) {
+	r.ID = o.ID
+	r.UserID = intFromPtr(o.UserID)
+	r.RestrictionProfileID = intFromPtr(o.RestrictionProfileID)
+	r.AllowDownload = o.AllowDownload
+	r.ExpiresAt = Timestamp{Timestamp: o.ExpiresAt}
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+
+	entityID := null.IntFrom(int64(o.EntityID))
+	switch o.EntityType {
+	case models.ShareLinkEntityScene:
+		r.SceneID = entityID
+	case models.ShareLinkEntityGallery:
+		r.GalleryID = entityID
+	case models.ShareLinkEntityImage:
+		r.ImageID = entityID
+	}
+}
+
+func (r *shareLinkRow) resolve() *models.ShareLink {
+	ret := &models.ShareLink{
+		ID:            r.ID,
+		UserID:        nullIntPtr(r.UserID),
+		AllowDownload: r.AllowDownload,
+
+		RestrictionProfileID: nullIntPtr(r.RestrictionProfileID),
+		ExpiresAt:            r.ExpiresAt.Timestamp,
+		CreatedAt:            r.CreatedAt.Timestamp,
+	}
+
+	switch {
+	case r.SceneID.Valid:
+		ret.EntityType = models.ShareLinkEntityScene
+		ret.EntityID = int(r.SceneID.Int64)
+	case r.GalleryID.Valid:
+		ret.EntityType = models.ShareLinkEntityGallery
+		ret.EntityID = int(r.GalleryID.Int64)
+	case r.ImageID.Valid:
+		ret.EntityType = models.ShareLinkEntityImage
+		ret.EntityID = int(r.ImageID.Int64)
+	}
+
+	return ret
+}
+
+type ShareLinkStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewShareLinkStore() *ShareLinkStore {
+	return &ShareLinkStore{
+		repository: repository{
+			tableName: shareLinkTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: shareLinkTableMgr,
+	}
+}
+
+func (qb *ShareLinkStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *ShareLinkStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *ShareLinkStore) Create(ctx context.Context, newObject *models.ShareLink) error {
+	if err := newObject.Validate(); err != nil {
+		return err
+	}
+
+	var r shareLinkRow
+	r.fromShareLink(*newObject)
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	updated, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding after create: %w", err)
+	}
+
+	*newObject = *updated
+
+	return nil
+}
+
+func (qb *ShareLinkStore) Destroy(ctx context.Context, id int) error {
+	return qb.destroyExisting(ctx, []int{id})
+}
+
+// returns nil, nil if not found
+func (qb *ShareLinkStore) Find(ctx context.Context, id int) (*models.ShareLink, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *ShareLinkStore) find(ctx context.Context, id int) (*models.ShareLink, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	return qb.get(ctx, q)
+}
+
+func (qb *ShareLinkStore) FindByUserID(ctx context.Context, userID int) ([]*models.ShareLink, error) {
+	table := qb.table()
+	return qb.getMany(ctx, qb.selectDataset().Where(
+		table.Col("user_id").Eq(userID),
+	).Order(
+		table.Col("created_at").Desc(),
+		table.Col(idColumn).Desc(),
+	))
+}
+
+func (qb *ShareLinkStore) All(ctx context.Context) ([]*models.ShareLink, error) {
+	table := qb.table()
+	return qb.getMany(ctx, qb.selectDataset().Order(
+		table.Col("created_at").Desc(),
+		table.Col(idColumn).Desc(),
+	))
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *ShareLinkStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.ShareLink, error) {
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *ShareLinkStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.ShareLink, error) {
+	const single = false
+	var ret []*models.ShareLink
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f shareLinkRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		ret = append(ret, f.resolve())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,108 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareLinkCreateDestroy(t *testing.T) {
+	runWithRollbackTxn(t, "create destroy", func(t *testing.T, ctx context.Context) {
+		now := time.Now().Truncate(time.Second)
+		u := &models.User{
+			Username:  "sharer",
+			Role:      models.UserRoleViewer,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := db.User.Create(ctx, u); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		p := &models.RestrictionProfile{Name: "kids", TagIDs: []int{tagIDs[tagIdxWithScene]}}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		expiresAt := now.Add(time.Hour)
+		sceneLink := &models.ShareLink{
+			EntityType:           models.ShareLinkEntityScene,
+			EntityID:             sceneIDs[sceneIdxWithGallery],
+			UserID:               &u.ID,
+			RestrictionProfileID: &p.ID,
+			AllowDownload:        true,
+			ExpiresAt:            expiresAt,
+			CreatedAt:            now,
+		}
+		galleryLink := &models.ShareLink{
+			EntityType: models.ShareLinkEntityGallery,
+			EntityID:   galleryIDs[galleryIdxWithImage],
+			ExpiresAt:  expiresAt,
+			CreatedAt:  now,
+		}
+		for _, l := range []*models.ShareLink{sceneLink, galleryLink} {
+			if err := db.ShareLink.Create(ctx, l); err != nil {
+				t.Errorf("ShareLinkStore.Create() error = %v", err)
+				return
+			}
+		}
+
+		assert.ErrorIs(t, db.ShareLink.Create(ctx, &models.ShareLink{
+			EntityType: models.ShareLinkEntityImage,
+			ExpiresAt:  expiresAt,
+		}), models.ErrShareLinkEntityID)
+
+		found, err := db.ShareLink.Find(ctx, galleryLink.ID)
+		if err != nil {
+			t.Errorf("ShareLinkStore.Find() error = %v", err)
+			return
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, models.ShareLinkEntityGallery, found.EntityType)
+			assert.Equal(t, galleryIDs[galleryIdxWithImage], found.EntityID)
+			assert.Nil(t, found.UserID)
+			assert.Nil(t, found.RestrictionProfileID)
+			assert.False(t, found.AllowDownload)
+			assert.True(t, expiresAt.Equal(found.ExpiresAt))
+			assert.True(t, now.Equal(found.CreatedAt))
+		}
+
+		links, err := db.ShareLink.FindByUserID(ctx, u.ID)
+		if assert.NoError(t, err) && assert.Len(t, links, 1) {
+			assert.Equal(t, sceneLink.ID, links[0].ID)
+			assert.Equal(t, models.ShareLinkEntityScene, links[0].EntityType)
+			assert.True(t, links[0].AllowDownload)
+			if assert.NotNil(t, links[0].RestrictionProfileID) {
+				assert.Equal(t, p.ID, *links[0].RestrictionProfileID)
+			}
+		}
+
+		links, err = db.ShareLink.All(ctx)
+		if assert.NoError(t, err) {
+			assert.Len(t, links, 2)
+		}
+
+		if err := db.ShareLink.Destroy(ctx, galleryLink.ID); err != nil {
+			t.Errorf("ShareLinkStore.Destroy() error = %v", err)
+			return
+		}
+
+		// destroying the user destroys their links
+		if err := db.User.Destroy(ctx, u.ID); err != nil {
+			t.Errorf("UserStore.Destroy() error = %v", err)
+			return
+		}
+
+		links, err = db.ShareLink.All(ctx)
+		if assert.NoError(t, err) {
+			assert.Empty(t, links)
+		}
+	})
+}
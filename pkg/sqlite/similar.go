@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// Weights of the features shared between objects when scoring their
+// similarity.
+const (
+	similarTagWeight           = 3.0
+	similarRelatedTagWeight    = 1.0
+	similarPerformerWeight     = 5.0
+	similarStudioWeight        = 4.0
+	similarRelatedStudioWeight = 2.0
+	similarMovieWeight         = 4.0
+	similarSceneWeight         = 5.0
+	similarGalleryWeight       = 2.0
+	// similarDateWeight is scaled by the proximity of the dates, down to
+	// zero for dates similarDateDays or more apart
+	similarDateWeight = 2.0
+	similarDateDays   = 365.0
+	// similarPhashWeight is scaled by the proximity of the phashes, down to
+	// zero for phashes more than similarPhashDistance apart
+	similarPhashWeight   = 5.0
+	similarPhashDistance = 10
+
+	// weights when scoring performers
+	similarCoSceneWeight      = 3.0
+	similarCoGalleryWeight    = 1.0
+	similarPerformerTagWeight = 2.0
+)
+
+// similarQuery builds a query scoring objects by the features they share
+// with a source object. The source CTE must select the source object, and
+// each score query must select the id of a candidate object and the score
+// of a shared feature.
+type similarQuery struct {
+	table   string
+	with    []string
+	scores  []string
+	args    []interface{}
+	dateCol string
+}
+
+func newSimilarQuery(table string, id int, sourceColumns ...string) *similarQuery {
+	columns := append([]string{"id"}, sourceColumns...)
+	return &similarQuery{
+		table: table,
+		with:  []string{fmt.Sprintf("source AS (SELECT %s FROM %s WHERE id = ?)", strings.Join(columns, ", "), table)},
+		args:  []interface{}{id},
+	}
+}
+
+func (q *similarQuery) addScore(query string, weight float64) {
+	q.scores = append(q.scores, fmt.Sprintf(query, weight))
+}
+
+// addTags scores the candidates by the tags they share with the source
+// object. Tags which are a parent or child of a tag of the source object
+// contribute a lesser score.
+func (q *similarQuery) addTags(joinTable, fkColumn string, weight float64, relatedWeight float64) {
+	q.with = append(q.with,
+		fmt.Sprintf("source_tags AS (SELECT tag_id FROM %s WHERE %s = (SELECT id FROM source))", joinTable, fkColumn),
+		`related_tags AS (
+	SELECT child_id AS tag_id FROM tags_relations WHERE parent_id IN source_tags
+	UNION SELECT parent_id FROM tags_relations WHERE child_id IN source_tags
+	EXCEPT SELECT tag_id FROM source_tags
+)`)
+
+	q.addScore(fmt.Sprintf("SELECT %s, %%f FROM %s WHERE tag_id IN source_tags", fkColumn, joinTable), weight)
+	q.addScore(fmt.Sprintf("SELECT %s, %%f FROM %s WHERE tag_id IN related_tags", fkColumn, joinTable), relatedWeight)
+}
+
+// addShared scores the candidates by the objects related to both the
+// candidate and the source object through joinTable.
+func (q *similarQuery) addShared(joinTable, fkColumn, relatedColumn string, weight float64) {
+	q.addScore(fmt.Sprintf("SELECT %[2]s, %%f FROM %[1]s WHERE %[3]s IN (SELECT %[3]s FROM %[1]s WHERE %[2]s = (SELECT id FROM source))", joinTable, fkColumn, relatedColumn), weight)
+}
+
+// addStudio scores the candidates with the same studio as the source
+// object, and those with the parent, a child or a sibling of the studio.
+// The source CTE must select the studio_id column.
+func (q *similarQuery) addStudio() {
+	q.with = append(q.with, `related_studios AS (
+	SELECT id FROM studios WHERE parent_id = (SELECT studio_id FROM source)
+	UNION SELECT parent_id FROM studios WHERE id = (SELECT studio_id FROM source) AND parent_id IS NOT NULL
+	UNION SELECT siblings.id FROM studios siblings INNER JOIN studios ON studios.parent_id = siblings.parent_id WHERE studios.id = (SELECT studio_id FROM source)
+	EXCEPT SELECT studio_id FROM source
+)`)
+
+	q.addScore(fmt.Sprintf("SELECT id, %%f FROM %s WHERE studio_id = (SELECT studio_id FROM source)", q.table), similarStudioWeight)
+	q.addScore(fmt.Sprintf("SELECT id, %%f FROM %s WHERE studio_id IN related_studios", q.table), similarRelatedStudioWeight)
+}
+
+// addPhash scores the candidates by the distance between their phashes and
+// those of the source object.
+func (q *similarQuery) addPhash(t phashFilesTable) {
+	phashes := t.phashesQuery()
+	q.with = append(q.with, fmt.Sprintf("source_phashes AS (SELECT phash FROM (%s) WHERE id = (SELECT id FROM source))", phashes))
+	q.addScore(fmt.Sprintf(`SELECT phashes.id, MAX(%%f * (1.0 - phash_distance(phashes.phash, source_phashes.phash) / %d.0)) FROM (%s) phashes, source_phashes
+WHERE phash_distance(phashes.phash, source_phashes.phash) <= %d
+GROUP BY phashes.id`, similarPhashDistance+1, phashes, similarPhashDistance), similarPhashWeight)
+}
+
+// addDate adds to the score of each candidate according to the proximity
+// of its date to that of the source object. Only candidates sharing other
+// features are scored. The source CTE must select the date column.
+func (q *similarQuery) addDate() {
+	q.dateCol = "date"
+}
+
+func (q *similarQuery) toSQL(limit int) (string, []interface{}) {
+	score := "SUM(scores.score)"
+	if q.dateCol != "" {
+		score += fmt.Sprintf(" + %f * COALESCE(MAX(0.0, 1.0 - ABS(julianday(%[2]s.%[3]s) - julianday((SELECT %[3]s FROM source))) / %[4]f), 0.0)", similarDateWeight, q.table, q.dateCol, similarDateDays)
+	}
+
+	sql := fmt.Sprintf(`WITH %s,
+scores(id, score) AS (
+	%s
+)
+SELECT scores.id AS id, %s AS score FROM scores
+INNER JOIN %[4]s ON %[4]s.id = scores.id
+WHERE scores.id != (SELECT id FROM source)
+GROUP BY scores.id
+ORDER BY score DESC, scores.id ASC`, strings.Join(q.with, ",\n"), strings.Join(q.scores, "\n\tUNION ALL "), score, q.table)
+
+	args := q.args
+	if limit > 0 {
+		sql += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	return sql, args
+}
+
+func (r *repository) findSimilar(ctx context.Context, q *similarQuery, limit int) ([]models.SimilarityScore, error) {
+	query, args := q.toSQL(limit)
+
+	var ret []models.SimilarityScore
+	if err := r.queryFunc(ctx, query, args, false, func(rows *sqlx.Rows) error {
+		var s models.SimilarityScore
+		if err := rows.StructScan(&s); err != nil {
+			return err
+		}
+		ret = append(ret, s)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("finding objects similar to %s: %w", q.table, err)
+	}
+
+	return ret, nil
+}
+
+// FindSimilar returns the scenes most similar to the scene with the given
+// id, scored by the weighted overlap of their tags, performers, studio,
+// movies and date, and optionally the perceptual similarity of their files.
+func (qb *SceneStore) FindSimilar(ctx context.Context, id int, options models.SimilarityOptions) ([]models.SimilarityScore, error) {
+	q := newSimilarQuery(sceneTable, id, "studio_id", "date")
+	q.addTags(scenesTagsTable, sceneIDColumn, similarTagWeight, similarRelatedTagWeight)
+	q.addShared(performersScenesTable, sceneIDColumn, performerIDColumn, similarPerformerWeight)
+	q.addShared(moviesScenesTable, sceneIDColumn, movieIDColumn, similarMovieWeight)
+	q.addShared(galleriesScenesTable, sceneIDColumn, galleryIDColumn, similarGalleryWeight)
+	q.addStudio()
+	q.addDate()
+	if options.PHash {
+		q.addPhash(scenePhashFiles)
+	}
+
+	return qb.repository.findSimilar(ctx, q, options.Limit)
+}
+
+// FindSimilar returns the galleries most similar to the gallery with the
+// given id, scored by the weighted overlap of their tags, performers,
+// studio, scenes and date.
+func (qb *GalleryStore) FindSimilar(ctx context.Context, id int, options models.SimilarityOptions) ([]models.SimilarityScore, error) {
+	q := newSimilarQuery(galleryTable, id, "studio_id", "date")
+	q.addTags(galleriesTagsTable, galleryIDColumn, similarTagWeight, similarRelatedTagWeight)
+	q.addShared(performersGalleriesTable, galleryIDColumn, performerIDColumn, similarPerformerWeight)
+	q.addShared(galleriesScenesTable, galleryIDColumn, sceneIDColumn, similarSceneWeight)
+	q.addStudio()
+	q.addDate()
+
+	return qb.repository.findSimilar(ctx, q, options.Limit)
+}
+
+// FindSimilar returns the performers most similar to the performer with the
+// given id, scored by the number of scenes and galleries in which they
+// appear together, and the tags they share.
+func (qb *PerformerStore) FindSimilar(ctx context.Context, id int, options models.SimilarityOptions) ([]models.SimilarityScore, error) {
+	q := newSimilarQuery(performerTable, id)
+	q.addTags(performersTagsTable, performerIDColumn, similarPerformerTagWeight, similarRelatedTagWeight)
+	q.addShared(performersScenesTable, performerIDColumn, sceneIDColumn, similarCoSceneWeight)
+	q.addShared(performersGalleriesTable, performerIDColumn, galleryIDColumn, similarCoGalleryWeight)
+
+	return qb.repository.findSimilar(ctx, q, options.Limit)
+}
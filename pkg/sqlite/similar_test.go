@@ -0,0 +1,115 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// findScore returns the score of id in scores, or zero if not found.
+func findScore(scores []models.SimilarityScore, id int) float64 {
+	for _, s := range scores {
+		if s.ID == id {
+			return s.Score
+		}
+	}
+
+	return 0
+}
+
+func assertSimilarityScores(t *testing.T, scores []models.SimilarityScore, sourceID int) {
+	t.Helper()
+
+	assert.Zero(t, findScore(scores, sourceID), "source object should not be scored")
+	assert.True(t, sort.SliceIsSorted(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	}), "scores should be in descending order")
+}
+
+func TestSceneFindSimilar(t *testing.T) {
+	tests := []struct {
+		name     string
+		sceneIdx int
+		otherIdx int
+		minScore float64
+		phash    bool
+	}{
+		{"shared performers", sceneIdxWithTwoPerformers, sceneIdxWithThreePerformers, 10, false},
+		{"shared studio", sceneIdx1WithStudio, sceneIdx2WithStudio, 4, false},
+		{"shared tags", sceneIdxWithTwoTags, sceneIdxWithMarkerTwoTags, 3, true},
+	}
+
+	for _, tt := range tests {
+		runWithRollbackTxn(t, tt.name, func(t *testing.T, ctx context.Context) {
+			id := sceneIDs[tt.sceneIdx]
+			scores, err := db.Scene.FindSimilar(ctx, id, models.SimilarityOptions{
+				PHash: tt.phash,
+			})
+			if err != nil {
+				t.Errorf("SceneStore.FindSimilar() error = %v", err)
+				return
+			}
+
+			assertSimilarityScores(t, scores, id)
+			assert.GreaterOrEqual(t, findScore(scores, sceneIDs[tt.otherIdx]), tt.minScore)
+		})
+	}
+}
+
+func TestSceneFindSimilarLimit(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		scores, err := db.Scene.FindSimilar(ctx, sceneIDs[sceneIdxWithTwoPerformers], models.SimilarityOptions{
+			Limit: 1,
+		})
+		if err != nil {
+			t.Errorf("SceneStore.FindSimilar() error = %v", err)
+			return nil
+		}
+
+		if assert.Len(t, scores, 1) {
+			assert.Equal(t, sceneIDs[sceneIdxWithThreePerformers], scores[0].ID)
+		}
+
+		return nil
+	})
+}
+
+func TestGalleryFindSimilar(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		id := galleryIDs[galleryIdxWithTwoPerformers]
+		scores, err := db.Gallery.FindSimilar(ctx, id, models.SimilarityOptions{})
+		if err != nil {
+			t.Errorf("GalleryStore.FindSimilar() error = %v", err)
+			return nil
+		}
+
+		assertSimilarityScores(t, scores, id)
+		assert.GreaterOrEqual(t, findScore(scores, galleryIDs[galleryIdxWithThreePerformers]), 10.0)
+
+		return nil
+	})
+}
+
+func TestPerformerFindSimilar(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		id := performerIDs[performerIdx1WithScene]
+		scores, err := db.Performer.FindSimilar(ctx, id, models.SimilarityOptions{})
+		if err != nil {
+			t.Errorf("PerformerStore.FindSimilar() error = %v", err)
+			return nil
+		}
+
+		assertSimilarityScores(t, scores, id)
+
+		// appear together in two scenes
+		assert.GreaterOrEqual(t, findScore(scores, performerIDs[performerIdx2WithScene]), 6.0)
+
+		return nil
+	})
+}
@@ -0,0 +1,251 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// StatisticsStore aggregates statistics over the library.
+type StatisticsStore struct {
+	repository
+}
+
+func NewStatisticsStore() *StatisticsStore {
+	return &StatisticsStore{
+		repository: repository{
+			tableName: sceneTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+// intervalStart returns an expression for the start date of the interval
+// containing the time in column. Weeks start on Monday.
+func intervalStart(interval models.StatisticsInterval, column string) (string, error) {
+	switch interval {
+	case models.StatisticsIntervalDay:
+		return fmt.Sprintf("date(%s)", column), nil
+	case models.StatisticsIntervalWeek:
+		return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", column), nil
+	case models.StatisticsIntervalMonth:
+		return fmt.Sprintf("date(%s, 'start of month')", column), nil
+	case models.StatisticsIntervalYear:
+		return fmt.Sprintf("date(%s, 'start of year')", column), nil
+	}
+
+	return "", fmt.Errorf("invalid interval %q", interval)
+}
+
+// whereClause returns a WHERE clause with the condition, or an empty string
+// if there is none.
+func whereClause(condition string) string {
+	if condition == "" {
+		return ""
+	}
+
+	return " WHERE " + condition
+}
+
+// fileRestrictionCondition hides the files of the scenes, images and
+// galleries hidden by the restriction profiles of the context.
+func fileRestrictionCondition(ctx context.Context) string {
+	return restrictionCondition(ctx, func(string) string {
+		return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM scenes_files INNER JOIN scenes ON scenes.id = scenes_files.scene_id WHERE scenes_files.file_id = files.id AND NOT %s)
+AND NOT EXISTS (SELECT 1 FROM images_files INNER JOIN images ON images.id = images_files.image_id WHERE images_files.file_id = files.id AND NOT %s)
+AND NOT EXISTS (SELECT 1 FROM galleries_files INNER JOIN galleries ON galleries.id = galleries_files.gallery_id WHERE galleries_files.file_id = files.id AND NOT %s)`,
+			sceneRestrictionCondition(ctx), imageRestrictionCondition(ctx), galleryRestrictionCondition(ctx))
+	})
+}
+
+func limitClause(limit int, args []interface{}) (string, []interface{}) {
+	if limit <= 0 {
+		return "", args
+	}
+
+	return " LIMIT ?", append(args, limit)
+}
+
+func (qb *StatisticsStore) SceneGrowth(ctx context.Context, interval models.StatisticsInterval) ([]*models.GrowthPeriod, error) {
+	period, err := intervalStart(interval, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s AS period, COUNT(*), COALESCE(SUM(size), 0) FROM (
+	SELECT scenes.created_at, (
+		SELECT SUM(files.size) FROM scenes_files
+		INNER JOIN files ON files.id = scenes_files.file_id
+		WHERE scenes_files.scene_id = scenes.id
+	) AS size FROM scenes%s
+) GROUP BY period ORDER BY period`, period, whereClause(sceneRestrictionCondition(ctx)))
+
+	var ret []*models.GrowthPeriod
+	total := 0
+	if err := qb.queryFunc(ctx, query, nil, false, func(rows *sqlx.Rows) error {
+		var p models.GrowthPeriod
+		if err := rows.Scan(&p.Period, &p.Count, &p.Size); err != nil {
+			return err
+		}
+
+		total += p.Count
+		p.Total = total
+		ret = append(ret, &p)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("querying scene growth: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (qb *StatisticsStore) SceneStorage(ctx context.Context, breakdown models.StorageBreakdownType) ([]*models.StorageBreakdown, error) {
+	var value, label, joins string
+	switch breakdown {
+	case models.StorageBreakdownTypeStudio:
+		value = "studios.id"
+		label = "studios.name"
+		joins = "LEFT JOIN studios ON studios.id = scenes.studio_id"
+	case models.StorageBreakdownTypeCodec:
+		value = "video_files.video_codec"
+		label = value
+	case models.StorageBreakdownTypeResolution:
+		value = resolutionExpression("video_files.height", "video_files.width")
+		label = value
+	default:
+		return nil, fmt.Errorf("invalid storage breakdown %q", breakdown)
+	}
+
+	query := fmt.Sprintf(`SELECT COALESCE(%s, '') AS value, COALESCE(%s, ''), COUNT(files.id), COALESCE(SUM(files.size), 0) AS size, COALESCE(SUM(video_files.duration), 0)
+FROM scenes
+INNER JOIN scenes_files ON scenes_files.scene_id = scenes.id
+INNER JOIN files ON files.id = scenes_files.file_id
+LEFT JOIN video_files ON video_files.file_id = files.id
+%s%s
+GROUP BY 1 ORDER BY size DESC, value`, value, label, joins, whereClause(sceneRestrictionCondition(ctx)))
+
+	ret, err := qb.queryStorage(ctx, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("querying scene storage by %s: %w", breakdown, err)
+	}
+
+	return ret, nil
+}
+
+func (qb *StatisticsStore) queryStorage(ctx context.Context, query string, args []interface{}) ([]*models.StorageBreakdown, error) {
+	var ret []*models.StorageBreakdown
+	if err := qb.queryFunc(ctx, query, args, false, func(rows *sqlx.Rows) error {
+		var s models.StorageBreakdown
+		if err := rows.Scan(&s.Value, &s.Label, &s.Count, &s.Size, &s.Duration); err != nil {
+			return err
+		}
+
+		ret = append(ret, &s)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (qb *StatisticsStore) PathStorage(ctx context.Context, paths []string) ([]*models.StorageBreakdown, error) {
+	// files within zip files are excluded, since the zip file is counted
+	query := `SELECT ?, ?, COUNT(files.id), COALESCE(SUM(files.size), 0), COALESCE(SUM(video_files.duration), 0)
+FROM files
+INNER JOIN folders ON folders.id = files.parent_folder_id
+LEFT JOIN video_files ON video_files.file_id = files.id
+WHERE files.zip_file_id IS NULL AND (folders.path = ? OR substr(folders.path, 1, ?) = ?)`
+	if condition := fileRestrictionCondition(ctx); condition != "" {
+		query += " AND " + condition
+	}
+
+	var ret []*models.StorageBreakdown
+	for _, p := range paths {
+		prefix := p + string(filepath.Separator)
+		s, err := qb.queryStorage(ctx, query, []interface{}{p, p, p, utf8.RuneCountInString(prefix), prefix})
+		if err != nil {
+			return nil, fmt.Errorf("querying storage of %s: %w", p, err)
+		}
+
+		ret = append(ret, s...)
+	}
+
+	return ret, nil
+}
+
+func (qb *StatisticsStore) WatchTime(ctx context.Context, interval models.StatisticsInterval, limit int) ([]*models.WatchTimePeriod, error) {
+	period, err := intervalStart(interval, "played_at")
+	if err != nil {
+		return nil, err
+	}
+
+	from := scenesPlayActivityTable
+	if condition := sceneRestrictionCondition(ctx); condition != "" {
+		from += fmt.Sprintf(" INNER JOIN scenes ON scenes.id = %s.scene_id WHERE %s", scenesPlayActivityTable, condition)
+	}
+
+	query := fmt.Sprintf("SELECT %s AS period, SUM(duration), COUNT(DISTINCT scene_id) FROM %s GROUP BY period ORDER BY period DESC", period, from)
+	limitSQL, args := limitClause(limit, nil)
+	query += limitSQL
+
+	var ret []*models.WatchTimePeriod
+	if err := qb.queryFunc(ctx, query, args, false, func(rows *sqlx.Rows) error {
+		var p models.WatchTimePeriod
+		if err := rows.Scan(&p.Period, &p.Duration, &p.SceneCount); err != nil {
+			return err
+		}
+
+		ret = append(ret, &p)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("querying watch time: %w", err)
+	}
+
+	return ret, nil
+}
+
+func (qb *StatisticsStore) MostPlayedPerformers(ctx context.Context, limit int) ([]*models.PlayStatistic, error) {
+	return qb.mostPlayed(ctx, performerTable, performersScenesTable, performerIDColumn, limit)
+}
+
+func (qb *StatisticsStore) MostPlayedTags(ctx context.Context, limit int) ([]*models.PlayStatistic, error) {
+	return qb.mostPlayed(ctx, tagTable, scenesTagsTable, tagIDColumn, limit)
+}
+
+func (qb *StatisticsStore) mostPlayed(ctx context.Context, table, joinTable, fkColumn string, limit int) ([]*models.PlayStatistic, error) {
+	// restricted performers and tags only appear in hidden scenes, so only
+	// the scenes need to be restricted
+	restriction := ""
+	if condition := sceneRestrictionCondition(ctx); condition != "" {
+		restriction = " AND " + condition
+	}
+
+	query := fmt.Sprintf(`SELECT %[1]s.id, %[1]s.name, SUM(scenes.play_count) AS play_count, SUM(scenes.play_duration) AS play_duration
+FROM %[1]s
+INNER JOIN %[2]s ON %[2]s.%[3]s = %[1]s.id
+INNER JOIN scenes ON scenes.id = %[2]s.scene_id
+WHERE (scenes.play_count > 0 OR scenes.play_duration > 0)%[4]s
+GROUP BY %[1]s.id ORDER BY play_count DESC, play_duration DESC, %[1]s.name`, table, joinTable, fkColumn, restriction)
+	limitSQL, args := limitClause(limit, nil)
+	query += limitSQL
+
+	var ret []*models.PlayStatistic
+	if err := qb.queryFunc(ctx, query, args, false, func(rows *sqlx.Rows) error {
+		var s models.PlayStatistic
+		if err := rows.Scan(&s.ID, &s.Name, &s.PlayCount, &s.PlayDuration); err != nil {
+			return err
+		}
+
+		ret = append(ret, &s)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("querying most played %s: %w", table, err)
+	}
+
+	return ret, nil
+}
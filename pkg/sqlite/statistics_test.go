@@ -0,0 +1,283 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatisticsSceneGrowth(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		growth, err := db.Statistics.SceneGrowth(ctx, models.StatisticsIntervalMonth)
+		if err != nil {
+			t.Errorf("StatisticsStore.SceneGrowth() error = %v", err)
+			return nil
+		}
+
+		count, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return nil
+		}
+
+		if !assert.NotEmpty(t, growth) {
+			return nil
+		}
+		assert.Equal(t, count, growth[len(growth)-1].Total)
+
+		for _, p := range growth {
+			_, err := time.Parse("2006-01-02", p.Period)
+			assert.NoError(t, err)
+			assert.Equal(t, "01", p.Period[8:], "period %s should start on the first of the month", p.Period)
+		}
+
+		_, err = db.Statistics.SceneGrowth(ctx, models.StatisticsInterval("invalid"))
+		assert.Error(t, err)
+
+		return nil
+	})
+}
+
+func TestStatisticsSceneStorage(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		size, err := db.Scene.Size(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Size() error = %v", err)
+			return nil
+		}
+
+		for _, breakdown := range []models.StorageBreakdownType{
+			models.StorageBreakdownTypeStudio,
+			models.StorageBreakdownTypeCodec,
+			models.StorageBreakdownTypeResolution,
+		} {
+			storage, err := db.Statistics.SceneStorage(ctx, breakdown)
+			if err != nil {
+				t.Errorf("StatisticsStore.SceneStorage(%s) error = %v", breakdown, err)
+				continue
+			}
+
+			total := 0.0
+			for i, s := range storage {
+				total += s.Size
+				if i > 0 {
+					assert.GreaterOrEqual(t, storage[i-1].Size, s.Size)
+				}
+			}
+			assert.Equal(t, size, total, breakdown)
+		}
+
+		storage, err := db.Statistics.SceneStorage(ctx, models.StorageBreakdownTypeStudio)
+		if err != nil {
+			t.Errorf("StatisticsStore.SceneStorage() error = %v", err)
+			return nil
+		}
+
+		studioID := strconv.Itoa(studioIDs[studioIdxWithTwoScenes])
+		for _, s := range storage {
+			if s.Value == studioID {
+				assert.Equal(t, getStudioStringValue(studioIdxWithTwoScenes, "Name"), s.Label)
+				assert.Equal(t, 2, s.Count)
+			}
+		}
+
+		return nil
+	})
+}
+
+func TestStatisticsPathStorage(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		path := folderPaths[folderIdxWithSceneFiles]
+		storage, err := db.Statistics.PathStorage(ctx, []string{path, "/nonexistent"})
+		if err != nil {
+			t.Errorf("StatisticsStore.PathStorage() error = %v", err)
+			return nil
+		}
+
+		if !assert.Len(t, storage, 2) {
+			return nil
+		}
+
+		assert.Equal(t, path, storage[0].Value)
+		assert.Greater(t, storage[0].Count, 0)
+		assert.Greater(t, storage[0].Duration, 0.0)
+		assert.Equal(t, &models.StorageBreakdown{Value: "/nonexistent", Label: "/nonexistent"}, storage[1])
+
+		return nil
+	})
+}
+
+func TestStatisticsWatchTime(t *testing.T) {
+	withRollbackTxn(func(ctx context.Context) error {
+		const playDuration = 12.5
+
+		before, err := db.Statistics.WatchTime(ctx, models.StatisticsIntervalDay, 1)
+		if err != nil {
+			t.Errorf("StatisticsStore.WatchTime() error = %v", err)
+			return nil
+		}
+
+		duration := playDuration
+		for _, idx := range []int{sceneIdxWithGallery, sceneIdxWithMovie} {
+			if _, err := db.Scene.SaveActivity(ctx, sceneIDs[idx], nil, &duration); err != nil {
+				t.Errorf("SceneStore.SaveActivity() error = %v", err)
+				return nil
+			}
+		}
+
+		after, err := db.Statistics.WatchTime(ctx, models.StatisticsIntervalDay, 1)
+		if err != nil {
+			t.Errorf("StatisticsStore.WatchTime() error = %v", err)
+			return nil
+		}
+
+		if !assert.Len(t, after, 1) {
+			return nil
+		}
+
+		want := &models.WatchTimePeriod{
+			Period:     time.Now().UTC().Format("2006-01-02"),
+			Duration:   2 * playDuration,
+			SceneCount: 2,
+		}
+		if len(before) > 0 && before[0].Period == want.Period {
+			want.Duration += before[0].Duration
+			want.SceneCount = after[0].SceneCount
+		}
+		assert.Equal(t, want, after[0])
+
+		return nil
+	})
+}
+
+func TestStatisticsMostPlayed(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		performers, err := db.Statistics.MostPlayedPerformers(ctx, 3)
+		if err != nil {
+			t.Errorf("StatisticsStore.MostPlayedPerformers() error = %v", err)
+			return nil
+		}
+
+		assert.LessOrEqual(t, len(performers), 3)
+		for i, p := range performers {
+			assert.Greater(t, p.PlayCount+int(p.PlayDuration), 0)
+			if i > 0 {
+				assert.GreaterOrEqual(t, performers[i-1].PlayCount, p.PlayCount)
+			}
+		}
+
+		tags, err := db.Statistics.MostPlayedTags(ctx, 0)
+		if err != nil {
+			t.Errorf("StatisticsStore.MostPlayedTags() error = %v", err)
+			return nil
+		}
+
+		assert.NotEmpty(t, tags)
+
+		return nil
+	})
+}
+
+func TestStatisticsRestricted(t *testing.T) {
+	runWithRollbackTxn(t, "restricted", func(t *testing.T, ctx context.Context) {
+		p := &models.RestrictionProfile{
+			Name:   "family",
+			TagIDs: []int{tagIDs[tagIdxWithScene]},
+		}
+		if !createRestrictionProfile(ctx, t, p) {
+			return
+		}
+
+		// play the hidden scene so that its tag is played
+		duration := 12.5
+		if _, err := db.Scene.SaveActivity(ctx, sceneIDs[sceneIdxWithTag], nil, &duration); err != nil {
+			t.Errorf("SceneStore.SaveActivity() error = %v", err)
+			return
+		}
+
+		path := folderPaths[folderIdxWithSceneFiles]
+		unrestrictedPaths, err := db.Statistics.PathStorage(ctx, []string{path})
+		if err != nil {
+			t.Errorf("StatisticsStore.PathStorage() error = %v", err)
+			return
+		}
+
+		unrestrictedWatchTime, err := db.Statistics.WatchTime(ctx, models.StatisticsIntervalDay, 1)
+		if err != nil {
+			t.Errorf("StatisticsStore.WatchTime() error = %v", err)
+			return
+		}
+
+		ctx = models.WithRestrictionProfileIDs(ctx, []int{p.ID})
+
+		count, err := db.Scene.Count(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Count() error = %v", err)
+			return
+		}
+
+		growth, err := db.Statistics.SceneGrowth(ctx, models.StatisticsIntervalMonth)
+		if err != nil {
+			t.Errorf("StatisticsStore.SceneGrowth() error = %v", err)
+			return
+		}
+		if assert.NotEmpty(t, growth) {
+			assert.Equal(t, count, growth[len(growth)-1].Total)
+		}
+
+		size, err := db.Scene.Size(ctx)
+		if err != nil {
+			t.Errorf("SceneStore.Size() error = %v", err)
+			return
+		}
+
+		storage, err := db.Statistics.SceneStorage(ctx, models.StorageBreakdownTypeCodec)
+		if err != nil {
+			t.Errorf("StatisticsStore.SceneStorage() error = %v", err)
+			return
+		}
+		total := 0.0
+		for _, s := range storage {
+			total += s.Size
+		}
+		assert.Equal(t, size, total)
+
+		paths, err := db.Statistics.PathStorage(ctx, []string{path})
+		if err != nil {
+			t.Errorf("StatisticsStore.PathStorage() error = %v", err)
+			return
+		}
+		if assert.Len(t, paths, 1) && assert.Len(t, unrestrictedPaths, 1) {
+			assert.Less(t, paths[0].Count, unrestrictedPaths[0].Count)
+		}
+
+		watchTime, err := db.Statistics.WatchTime(ctx, models.StatisticsIntervalDay, 1)
+		if err != nil {
+			t.Errorf("StatisticsStore.WatchTime() error = %v", err)
+			return
+		}
+		if assert.Len(t, unrestrictedWatchTime, 1) {
+			var restrictedDuration float64
+			if len(watchTime) > 0 && watchTime[0].Period == unrestrictedWatchTime[0].Period {
+				restrictedDuration = watchTime[0].Duration
+			}
+			assert.Equal(t, unrestrictedWatchTime[0].Duration-duration, restrictedDuration)
+		}
+
+		tags, err := db.Statistics.MostPlayedTags(ctx, 0)
+		if err != nil {
+			t.Errorf("StatisticsStore.MostPlayedTags() error = %v", err)
+			return
+		}
+		for _, tag := range tags {
+			assert.NotEqual(t, strconv.Itoa(tagIDs[tagIdxWithScene]), tag.ID)
+		}
+	})
+}
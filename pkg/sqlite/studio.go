@@ -24,6 +24,7 @@ const (
 	studioAliasColumn  = "alias"
 
 	studioImageBlobColumn = "image_blob"
+	studioImageBlobsTable = "studio_image_blobs"
 )
 
 type studioRow struct {
@@ -91,7 +92,7 @@ func (r *studioRowRecord) fromPartial(o models.StudioPartial) {
 
 type StudioStore struct {
 	repository
-	blobJoinQueryBuilder
+	imageCollectionQueryBuilder
 
 	tableMgr *table
 }
@@ -102,9 +103,14 @@ func NewStudioStore(blobStore *BlobStore) *StudioStore {
 			tableName: studioTable,
 			idColumn:  idColumn,
 		},
-		blobJoinQueryBuilder: blobJoinQueryBuilder{
-			blobStore: blobStore,
-			joinTable: studioTable,
+		imageCollectionQueryBuilder: imageCollectionQueryBuilder{
+			blobJoinQueryBuilder: blobJoinQueryBuilder{
+				blobStore: blobStore,
+				joinTable: studioTable,
+			},
+			blobCol:         studioImageBlobColumn,
+			collectionTable: studioImageBlobsTable,
+			fkColumn:        studioIDColumn,
 		},
 
 		tableMgr: studioTableMgr,
@@ -128,6 +134,8 @@ func (qb *StudioStore) Create(ctx context.Context, newObject *models.Studio) err
 		return err
 	}
 
+	studioEditTracker.trackCreate(ctx, id)
+
 	updated, err := qb.find(ctx, id)
 	if err != nil {
 		return fmt.Errorf("finding after create: %w", err)
@@ -139,6 +147,10 @@ func (qb *StudioStore) Create(ctx context.Context, newObject *models.Studio) err
 }
 
 func (qb *StudioStore) UpdatePartial(ctx context.Context, id int, partial models.StudioPartial) (*models.Studio, error) {
+	if err := studioEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := studioRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -157,6 +169,10 @@ func (qb *StudioStore) UpdatePartial(ctx context.Context, id int, partial models
 }
 
 func (qb *StudioStore) Update(ctx context.Context, updatedObject *models.Studio) error {
+	if err := studioEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r studioRow
 	r.fromStudio(*updatedObject)
 
@@ -183,6 +199,104 @@ func (qb *StudioStore) Destroy(ctx context.Context, id int) error {
 	return qb.destroyExisting(ctx, []int{id})
 }
 
+// Merge reassigns the scenes, images, galleries, movies, child studios,
+// aliases and stash ids of the source studios to the destination studio, adds
+// the source names as aliases and then destroys the source studios.
+func (qb *StudioStore) Merge(ctx context.Context, source []int, destination int) error {
+	if err := studioEditTracker.track(ctx, destination); err != nil {
+		return err
+	}
+
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := validateMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	// if the destination is a descendant of a source studio, move it up to
+	// the first ancestor that is not being merged
+	if err := qb.mergeParent(ctx, source, destination); err != nil {
+		return err
+	}
+
+	for _, table := range []string{sceneTable, imageTable, galleryTable, movieTable, "scraped_items"} {
+		if err := mergeForeignKey(ctx, table, studioIDColumn, source, destination); err != nil {
+			return err
+		}
+	}
+
+	args := []interface{}{destination}
+	for _, id := range source {
+		args = append(args, id)
+	}
+	args = append(args, destination)
+	inBinding := getInBinding(len(source))
+
+	if _, err := qb.tx.Exec(ctx, "UPDATE "+studioTable+" SET parent_id = ? WHERE parent_id IN "+inBinding+" AND id != ?", args...); err != nil {
+		return err
+	}
+
+	// aliases are unique across all studios, so they can be moved directly
+	if err := mergeForeignKey(ctx, studioAliasesTable, studioIDColumn, source, destination); err != nil {
+		return err
+	}
+
+	if _, err := qb.tx.Exec(ctx, `INSERT OR IGNORE INTO `+studioAliasesTable+` (studio_id, alias)
+SELECT ?, name FROM `+studioTable+` WHERE id IN `+inBinding+`
+AND name != (SELECT name FROM `+studioTable+` WHERE id = ?)`, args...); err != nil {
+		return err
+	}
+
+	if err := mergeJoins(ctx, "studio_stash_ids", studioIDColumn, []string{"endpoint", "stash_id"}, nil, source, destination); err != nil {
+		return err
+	}
+
+	if err := qb.mergeImages(ctx, source, destination); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		if err := qb.Destroy(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qb *StudioStore) mergeParent(ctx context.Context, source []int, destination int) error {
+	dest, err := qb.find(ctx, destination)
+	if err != nil {
+		return fmt.Errorf("finding destination studio: %w", err)
+	}
+
+	newParent := dest.ParentID
+	changed := false
+	for parentID := dest.ParentID; parentID != nil; {
+		parent, err := qb.find(ctx, *parentID)
+		if err != nil {
+			return fmt.Errorf("finding parent studio %d: %w", *parentID, err)
+		}
+
+		if intslice.IntInclude(source, parent.ID) {
+			newParent = parent.ParentID
+			changed = true
+		}
+
+		parentID = parent.ParentID
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return qb.tableMgr.updateByID(ctx, destination, goqu.Record{
+		"parent_id": intFromPtr(newParent),
+	})
+}
+
 // returns nil, nil if not found
 func (qb *StudioStore) Find(ctx context.Context, id int) (*models.Studio, error) {
 	ret, err := qb.find(ctx, id)
@@ -213,9 +327,12 @@ func (qb *StudioStore) FindMany(ctx context.Context, ids []int) ([]*models.Studi
 		return nil, err
 	}
 
-	for i := range ret {
-		if ret[i] == nil {
-			return nil, fmt.Errorf("studio with id %d not found", ids[i])
+	// content hidden by a restriction is left nil, as if it did not exist
+	if !models.IsRestricted(ctx) {
+		for i := range ret {
+			if ret[i] == nil {
+				return nil, fmt.Errorf("studio with id %d not found", ids[i])
+			}
 		}
 	}
 
@@ -249,6 +366,8 @@ func (qb *StudioStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.
 }
 
 func (qb *StudioStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.Studio, error) {
+	q = restrictDataset(q, studioRestrictionCondition(ctx))
+
 	const single = false
 	var ret []*models.Studio
 	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
@@ -331,6 +450,7 @@ func (qb *StudioStore) FindByStashID(ctx context.Context, stashID models.StashID
 
 func (qb *StudioStore) Count(ctx context.Context) (int, error) {
 	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	q = restrictDataset(q, studioRestrictionCondition(ctx))
 	return count(ctx, q)
 }
 
@@ -447,7 +567,7 @@ func (qb *StudioStore) makeFilter(ctx context.Context, studioFilter *models.Stud
 	return query
 }
 
-func (qb *StudioStore) Query(ctx context.Context, studioFilter *models.StudioFilterType, findFilter *models.FindFilterType) ([]*models.Studio, int, error) {
+func (qb *StudioStore) makeQuery(ctx context.Context, studioFilter *models.StudioFilterType, findFilter *models.FindFilterType) (*queryBuilder, error) {
 	if studioFilter == nil {
 		studioFilter = &models.StudioFilterType{}
 	}
@@ -457,8 +577,11 @@ func (qb *StudioStore) Query(ctx context.Context, studioFilter *models.StudioFil
 
 	query := qb.newQuery()
 	distinctIDs(&query, studioTable)
+	query.addWhere(studioRestrictionCondition(ctx))
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		query.join(studioAliasesTable, "", "studio_aliases.studio_id = studios.id")
 		searchColumns := []string{"studios.name", "studio_aliases.alias"}
 
@@ -466,15 +589,31 @@ func (qb *StudioStore) Query(ctx context.Context, studioFilter *models.StudioFil
 	}
 
 	if err := qb.validateFilter(studioFilter); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	filter := qb.makeFilter(ctx, studioFilter)
 
 	if err := query.addFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, studioKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		query.sortAndPagination = qb.getStudioSort(&query, findFilter) + getPagination(findFilter)
+	}
+
+	return &query, nil
+}
+
+func (qb *StudioStore) Query(ctx context.Context, studioFilter *models.StudioFilterType, findFilter *models.FindFilterType) ([]*models.Studio, int, error) {
+	query, err := qb.makeQuery(ctx, studioFilter, findFilter)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	query.sortAndPagination = qb.getStudioSort(findFilter) + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind(ctx)
 	if err != nil {
 		return nil, 0, err
@@ -488,6 +627,23 @@ func (qb *StudioStore) Query(ctx context.Context, studioFilter *models.StudioFil
 	return studios, countResult, nil
 }
 
+var studioFacets = facetDefinitions{
+	models.FacetTypeParent: {
+		joins: "INNER JOIN studios ON studios.id = temp.id INNER JOIN studios AS parent_studios ON parent_studios.id = studios.parent_id",
+		value: "parent_studios.id",
+		label: "parent_studios.name",
+	},
+}
+
+func (qb *StudioStore) Facets(ctx context.Context, studioFilter *models.StudioFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, studioFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, studioFacets, options)
+}
+
 func studioIsMissingCriterionHandler(qb *StudioStore, isMissing *string) criterionHandlerFunc {
 	return func(ctx context.Context, f *filterBuilder) {
 		if isMissing != nil && *isMissing != "" {
@@ -564,7 +720,17 @@ func studioAliasCriterionHandler(qb *StudioStore, alias *models.StringCriterionI
 	return h.handler(alias)
 }
 
-func (qb *StudioStore) getStudioSort(findFilter *models.FindFilterType) string {
+var studioKeysetSorts = keysetSorts{
+	"name":            "COALESCE(studios.name, '') COLLATE NATURAL_CI",
+	"rating":          "COALESCE(studios.rating, 0)",
+	"created_at":      "studios.created_at",
+	"updated_at":      "studios.updated_at",
+	"scenes_count":    getCountKeyset(studioTable, sceneTable, studioIDColumn),
+	"images_count":    getCountKeyset(studioTable, imageTable, studioIDColumn),
+	"galleries_count": getCountKeyset(studioTable, galleryTable, studioIDColumn),
+}
+
+func (qb *StudioStore) getStudioSort(query *queryBuilder, findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
 	if findFilter == nil {
@@ -575,6 +741,10 @@ func (qb *StudioStore) getStudioSort(findFilter *models.FindFilterType) string {
 		direction = findFilter.GetDirection()
 	}
 
+	if sort == relevanceSort && query.fullTextSearch == "" {
+		sort = "name"
+	}
+
 	sortQuery := ""
 	switch sort {
 	case "scenes_count":
@@ -583,6 +753,8 @@ func (qb *StudioStore) getStudioSort(findFilter *models.FindFilterType) string {
 		sortQuery += getCountSort(studioTable, imageTable, studioIDColumn, direction)
 	case "galleries_count":
 		sortQuery += getCountSort(studioTable, galleryTable, studioIDColumn, direction)
+	case relevanceSort:
+		sortQuery += query.getRelevanceSort(direction)
 	default:
 		sortQuery += getSort(sort, direction, "studios")
 	}
@@ -621,11 +793,31 @@ func (qb *StudioStore) HasImage(ctx context.Context, studioID int) (bool, error)
 }
 
 func (qb *StudioStore) UpdateImage(ctx context.Context, studioID int, image []byte) error {
-	return qb.blobJoinQueryBuilder.UpdateImage(ctx, studioID, studioImageBlobColumn, image)
+	return qb.updatePrimaryImage(ctx, studioID, image)
 }
 
 func (qb *StudioStore) destroyImage(ctx context.Context, studioID int) error {
-	return qb.blobJoinQueryBuilder.DestroyImage(ctx, studioID, studioImageBlobColumn)
+	return qb.destroyImages(ctx, studioID)
+}
+
+func (qb *StudioStore) GetImages(ctx context.Context, studioID int) ([]models.EntityImage, error) {
+	return qb.getImages(ctx, studioID)
+}
+
+func (qb *StudioStore) GetImageByID(ctx context.Context, studioID int, imageID int) ([]byte, error) {
+	return qb.getImageByID(ctx, studioID, imageID)
+}
+
+func (qb *StudioStore) AddImage(ctx context.Context, studioID int, image []byte, primary bool) (int, error) {
+	return qb.addImage(ctx, studioID, image, primary)
+}
+
+func (qb *StudioStore) SetPrimaryImage(ctx context.Context, studioID int, imageID int) error {
+	return qb.setPrimaryImage(ctx, studioID, imageID)
+}
+
+func (qb *StudioStore) DestroyImageByID(ctx context.Context, studioID int, imageID int) error {
+	return qb.destroyImageByID(ctx, studioID, imageID)
 }
 
 func (qb *StudioStore) stashIDRepository() *stashIDRepository {
@@ -643,6 +835,10 @@ func (qb *StudioStore) GetStashIDs(ctx context.Context, studioID int) ([]models.
 }
 
 func (qb *StudioStore) UpdateStashIDs(ctx context.Context, studioID int, stashIDs []models.StashID) error {
+	if err := studioEditTracker.track(ctx, studioID); err != nil {
+		return err
+	}
+
 	return qb.stashIDRepository().replace(ctx, studioID, stashIDs)
 }
 
@@ -662,5 +858,9 @@ func (qb *StudioStore) GetAliases(ctx context.Context, studioID int) ([]string,
 }
 
 func (qb *StudioStore) UpdateAliases(ctx context.Context, studioID int, aliases []string) error {
+	if err := studioEditTracker.track(ctx, studioID); err != nil {
+		return err
+	}
+
 	return qb.aliasRepository().replace(ctx, studioID, aliases)
 }
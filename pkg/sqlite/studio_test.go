@@ -400,6 +400,95 @@ func TestStudioUpdateStudioImage(t *testing.T) {
 	}
 }
 
+func TestStudioStore_Merge(t *testing.T) {
+	assert := assert.New(t)
+
+	// perform in a transaction that we'll rollback
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Studio
+
+		// create a hierarchy of root -> source -> middle -> destination
+		root, err := createStudio(ctx, qb, "mergeRoot", nil)
+		if err != nil {
+			return err
+		}
+		source, err := createStudio(ctx, qb, "mergeSource", &root.ID)
+		if err != nil {
+			return err
+		}
+		middle, err := createStudio(ctx, qb, "mergeMiddle", &source.ID)
+		if err != nil {
+			return err
+		}
+		dest, err := createStudio(ctx, qb, "mergeDestination", &middle.ID)
+		if err != nil {
+			return err
+		}
+
+		// try merging into same studio
+		err = qb.Merge(ctx, []int{dest.ID}, dest.ID)
+		assert.NotNil(err)
+
+		srcIDs := []int{source.ID, studioIDs[studioIdxWithTwoScenes], studioIDs[studioIdxWithMovie]}
+		if err := qb.Merge(ctx, srcIDs, dest.ID); err != nil {
+			return err
+		}
+
+		for _, id := range srcIDs {
+			s, err := qb.Find(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			assert.Nil(s)
+		}
+
+		// destination is moved above the merged studio
+		merged, err := qb.Find(ctx, dest.ID)
+		if err != nil {
+			return err
+		}
+		if assert.NotNil(merged.ParentID) {
+			assert.Equal(root.ID, *merged.ParentID)
+		}
+
+		// children of the source are moved to the destination
+		m, err := qb.Find(ctx, middle.ID)
+		if err != nil {
+			return err
+		}
+		if assert.NotNil(m.ParentID) {
+			assert.Equal(dest.ID, *m.ParentID)
+		}
+
+		aliases, err := qb.GetAliases(ctx, dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Contains(aliases, source.Name)
+
+		scene, err := db.Scene.Find(ctx, sceneIDs[sceneIdx1WithStudio])
+		if err != nil {
+			return err
+		}
+		if assert.NotNil(scene.StudioID) {
+			assert.Equal(dest.ID, *scene.StudioID)
+		}
+
+		movie, err := db.Movie.Find(ctx, movieIDs[movieIdxWithStudio])
+		if err != nil {
+			return err
+		}
+		if assert.NotNil(movie.StudioID) {
+			assert.Equal(dest.ID, *movie.StudioID)
+		}
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestStudioQuerySceneCount(t *testing.T) {
 	const sceneCount = 1
 	sceneCountCriterion := models.IntCriterionInput{
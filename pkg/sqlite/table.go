@@ -594,6 +594,165 @@ func (t *scenesMoviesTable) modifyJoins(ctx context.Context, id int, v []models.
 	return nil
 }
 
+// galleryImagesJoinTable is a joinTable for the galleries_images table, which
+// maintains the position of each image within its galleries. It may be
+// keyed by either the gallery or the image id. New joins are added to the end
+// of the gallery.
+type galleryImagesJoinTable struct {
+	joinTable
+}
+
+const galleriesImagesPositionColumn = "position"
+
+func (t *galleryImagesJoinTable) galleryIDColumn() exp.IdentifierExpression {
+	return t.table.table.Col(galleryIDColumn)
+}
+
+func (t *galleryImagesJoinTable) invert() *galleryImagesJoinTable {
+	return &galleryImagesJoinTable{
+		joinTable: *t.joinTable.invert(),
+	}
+}
+
+func (t *galleryImagesJoinTable) isGalleryKeyed() bool {
+	return t.idColumn.GetCol() == galleryIDColumn
+}
+
+// get returns the foreign keys of the joins for the provided id. If keyed by
+// gallery, then the image ids are returned in position order.
+func (t *galleryImagesJoinTable) get(ctx context.Context, id int) ([]int, error) {
+	q := dialect.Select(t.fkColumn).From(t.table.table).Where(t.idColumn.Eq(id))
+	if t.isGalleryKeyed() {
+		q = q.Order(t.table.table.Col(galleriesImagesPositionColumn).Asc(), t.fkColumn.Asc())
+	}
+
+	const single = false
+	var ret []int
+	if err := queryFunc(ctx, q, single, func(rows *sqlx.Rows) error {
+		var fk int
+		if err := rows.Scan(&fk); err != nil {
+			return err
+		}
+
+		ret = append(ret, fk)
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("getting foreign keys from %s: %w", t.table.table.GetTable(), err)
+	}
+
+	return ret, nil
+}
+
+func (t *galleryImagesJoinTable) insertJoins(ctx context.Context, id int, foreignIDs []int) error {
+	// manually create SQL so that we can prepare once
+	// ignore duplicates
+	// position is set to the end of the gallery
+	q := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (?, ?, (SELECT COALESCE(MAX(%[4]s), 0) + 1 FROM %[1]s WHERE %s = ?)) ON CONFLICT (%[2]s, %[3]s) DO NOTHING",
+		t.table.table.GetTable(), t.idColumn.GetCol(), t.fkColumn.GetCol(), galleriesImagesPositionColumn, galleryIDColumn)
+
+	tx := dbWrapper{}
+	stmt, err := tx.Prepare(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	// eliminate duplicates
+	foreignIDs = intslice.IntAppendUniques(nil, foreignIDs)
+
+	for _, fk := range foreignIDs {
+		galleryID := fk
+		if t.isGalleryKeyed() {
+			galleryID = id
+		}
+
+		if _, err := tx.ExecStmt(ctx, stmt, id, fk, galleryID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceJoins sets the joins for the provided id. Unlike joinTable, existing
+// joins are retained so that their positions are not lost.
+func (t *galleryImagesJoinTable) replaceJoins(ctx context.Context, id int, foreignIDs []int) error {
+	fks, err := t.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if toRemove := intslice.IntExclude(fks, foreignIDs); len(toRemove) > 0 {
+		if err := t.destroyJoins(ctx, id, toRemove); err != nil {
+			return err
+		}
+	}
+
+	return t.insertJoins(ctx, id, intslice.IntExclude(foreignIDs, fks))
+}
+
+func (t *galleryImagesJoinTable) addJoins(ctx context.Context, id int, foreignIDs []int) error {
+	// get existing foreign keys
+	fks, err := t.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// only add foreign keys that are not already present
+	foreignIDs = intslice.IntExclude(foreignIDs, fks)
+	return t.insertJoins(ctx, id, foreignIDs)
+}
+
+func (t *galleryImagesJoinTable) modifyJoins(ctx context.Context, id int, foreignIDs []int, mode models.RelationshipUpdateMode) error {
+	switch mode {
+	case models.RelationshipUpdateModeSet:
+		return t.replaceJoins(ctx, id, foreignIDs)
+	case models.RelationshipUpdateModeAdd:
+		return t.addJoins(ctx, id, foreignIDs)
+	case models.RelationshipUpdateModeRemove:
+		return t.destroyJoins(ctx, id, foreignIDs)
+	}
+
+	return nil
+}
+
+// getPositions returns a map of foreign key to position for the provided id.
+func (t *galleryImagesJoinTable) getPositions(ctx context.Context, id int) (map[int]int, error) {
+	q := dialect.Select(t.fkColumn, t.table.table.Col(galleriesImagesPositionColumn)).From(t.table.table).Where(t.idColumn.Eq(id))
+
+	const single = false
+	ret := make(map[int]int)
+	if err := queryFunc(ctx, q, single, func(rows *sqlx.Rows) error {
+		var fk, position int
+		if err := rows.Scan(&fk, &position); err != nil {
+			return err
+		}
+
+		ret[fk] = position
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("getting positions from %s: %w", t.table.table.GetTable(), err)
+	}
+
+	return ret, nil
+}
+
+// setPosition sets the position of the join between the provided id and
+// foreign key.
+func (t *galleryImagesJoinTable) setPosition(ctx context.Context, id int, fk int, position int) error {
+	q := dialect.Update(t.table.table).Prepared(true).Set(goqu.Record{
+		galleriesImagesPositionColumn: position,
+	}).Where(t.idColumn.Eq(id), t.fkColumn.Eq(fk))
+
+	if _, err := exec(ctx, q); err != nil {
+		return fmt.Errorf("updating %s: %w", t.table.table.GetTable(), err)
+	}
+
+	return nil
+}
+
 type relatedFilesTable struct {
 	table
 }
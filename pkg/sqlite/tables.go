@@ -14,6 +14,8 @@ var (
 	performersImagesJoinTable = goqu.T(performersImagesTable)
 	imagesFilesJoinTable      = goqu.T(imagesFilesTable)
 
+	imageFileKeywordsJoinTable = goqu.T(imageFileKeywordsTable)
+
 	galleriesFilesJoinTable      = goqu.T(galleriesFilesTable)
 	galleriesTagsJoinTable       = goqu.T(galleriesTagsTable)
 	performersGalleriesJoinTable = goqu.T(performersGalleriesTable)
@@ -25,9 +27,16 @@ var (
 	scenesStashIDsJoinTable   = goqu.T("scene_stash_ids")
 	scenesMoviesJoinTable     = goqu.T(moviesScenesTable)
 
+	scenesPlayActivityJoinTable = goqu.T(scenesPlayActivityTable)
+	scenesUsersJoinTable        = goqu.T(scenesUsersTable)
+
 	performersAliasesJoinTable  = goqu.T(performersAliasesTable)
 	performersTagsJoinTable     = goqu.T(performersTagsTable)
 	performersStashIDsJoinTable = goqu.T("performer_stash_ids")
+
+	restrictionProfilesTagsJoinTable       = goqu.T(restrictionProfilesTagsTable)
+	restrictionProfilesStudiosJoinTable    = goqu.T(restrictionProfilesStudiosTable)
+	restrictionProfilesPerformersJoinTable = goqu.T(restrictionProfilesPerformersTable)
 )
 
 var (
@@ -43,13 +52,7 @@ var (
 		},
 	}
 
-	imageGalleriesTableMgr = &joinTable{
-		table: table{
-			table:    galleriesImagesJoinTable,
-			idColumn: galleriesImagesJoinTable.Col(imageIDColumn),
-		},
-		fkColumn: galleriesImagesJoinTable.Col(galleryIDColumn),
-	}
+	imageGalleriesTableMgr = galleriesImagesTableMgr.invert()
 
 	imagesTagsTableMgr = &joinTable{
 		table: table{
@@ -97,6 +100,16 @@ var (
 		fkColumn: performersGalleriesJoinTable.Col(performerIDColumn),
 	}
 
+	galleriesImagesTableMgr = &galleryImagesJoinTable{
+		joinTable: joinTable{
+			table: table{
+				table:    galleriesImagesJoinTable,
+				idColumn: galleriesImagesJoinTable.Col(galleryIDColumn),
+			},
+			fkColumn: galleriesImagesJoinTable.Col(imageIDColumn),
+		},
+	}
+
 	galleriesScenesTableMgr = &joinTable{
 		table: table{
 			table:    galleriesScenesJoinTable,
@@ -178,6 +191,14 @@ var (
 		idColumn: goqu.T(imageFileTable).Col(fileIDColumn),
 	}
 
+	imageFileKeywordsTableMgr = &stringTable{
+		table: table{
+			table:    imageFileKeywordsJoinTable,
+			idColumn: imageFileKeywordsJoinTable.Col(fileIDColumn),
+		},
+		stringColumn: imageFileKeywordsJoinTable.Col(imageFileKeywordColumn),
+	}
+
 	folderTableMgr = &table{
 		table:    goqu.T(folderTable),
 		idColumn: goqu.T(folderTable).Col(idColumn),
@@ -252,4 +273,73 @@ var (
 		table:    goqu.T(savedFilterTable),
 		idColumn: goqu.T(savedFilterTable).Col(idColumn),
 	}
+
+	playlistTableMgr = &table{
+		table:    goqu.T(playlistTable),
+		idColumn: goqu.T(playlistTable).Col(idColumn),
+	}
+
+	playlistsItemsTableMgr = &table{
+		table:    goqu.T(playlistsItemsTable),
+		idColumn: goqu.T(playlistsItemsTable).Col(idColumn),
+	}
+
+	tagImplicationTableMgr = &table{
+		table:    goqu.T(tagImplicationTable),
+		idColumn: goqu.T(tagImplicationTable).Col(idColumn),
+	}
+
+	userTableMgr = &table{
+		table:    goqu.T(userTable),
+		idColumn: goqu.T(userTable).Col(idColumn),
+	}
+
+	apiKeyTableMgr = &table{
+		table:    goqu.T(apiKeyTable),
+		idColumn: goqu.T(apiKeyTable).Col(idColumn),
+	}
+
+	auditEventTableMgr = &table{
+		table:    goqu.T(auditEventTable),
+		idColumn: goqu.T(auditEventTable).Col(idColumn),
+	}
+
+	shareLinkTableMgr = &table{
+		table:    goqu.T(shareLinkTable),
+		idColumn: goqu.T(shareLinkTable).Col(idColumn),
+	}
+
+	restrictionProfileTableMgr = &table{
+		table:    goqu.T(restrictionProfileTable),
+		idColumn: goqu.T(restrictionProfileTable).Col(idColumn),
+	}
+
+	restrictionProfilesTagsTableMgr = &joinTable{
+		table: table{
+			table:    restrictionProfilesTagsJoinTable,
+			idColumn: restrictionProfilesTagsJoinTable.Col(restrictionProfileIDColumn),
+		},
+		fkColumn: restrictionProfilesTagsJoinTable.Col(tagIDColumn),
+	}
+
+	restrictionProfilesStudiosTableMgr = &joinTable{
+		table: table{
+			table:    restrictionProfilesStudiosJoinTable,
+			idColumn: restrictionProfilesStudiosJoinTable.Col(restrictionProfileIDColumn),
+		},
+		fkColumn: restrictionProfilesStudiosJoinTable.Col(studioIDColumn),
+	}
+
+	restrictionProfilesPerformersTableMgr = &joinTable{
+		table: table{
+			table:    restrictionProfilesPerformersJoinTable,
+			idColumn: restrictionProfilesPerformersJoinTable.Col(restrictionProfileIDColumn),
+		},
+		fkColumn: restrictionProfilesPerformersJoinTable.Col(performerIDColumn),
+	}
+
+	editTableMgr = &table{
+		table:    goqu.T(editTable),
+		idColumn: goqu.T(editTable).Col(idColumn),
+	}
 )
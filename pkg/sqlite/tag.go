@@ -23,6 +23,7 @@ const (
 	tagAliasColumn  = "alias"
 
 	tagImageBlobColumn = "image_blob"
+	tagImageBlobsTable = "tag_image_blobs"
 )
 
 type tagRow struct {
@@ -87,7 +88,7 @@ func (r *tagRowRecord) fromPartial(o models.TagPartial) {
 
 type TagStore struct {
 	repository
-	blobJoinQueryBuilder
+	imageCollectionQueryBuilder
 
 	tableMgr *table
 }
@@ -98,9 +99,14 @@ func NewTagStore(blobStore *BlobStore) *TagStore {
 			tableName: tagTable,
 			idColumn:  idColumn,
 		},
-		blobJoinQueryBuilder: blobJoinQueryBuilder{
-			blobStore: blobStore,
-			joinTable: tagTable,
+		imageCollectionQueryBuilder: imageCollectionQueryBuilder{
+			blobJoinQueryBuilder: blobJoinQueryBuilder{
+				blobStore: blobStore,
+				joinTable: tagTable,
+			},
+			blobCol:         tagImageBlobColumn,
+			collectionTable: tagImageBlobsTable,
+			fkColumn:        tagIDColumn,
 		},
 		tableMgr: tagTableMgr,
 	}
@@ -123,6 +129,8 @@ func (qb *TagStore) Create(ctx context.Context, newObject *models.Tag) error {
 		return err
 	}
 
+	tagEditTracker.trackCreate(ctx, id)
+
 	updated, err := qb.find(ctx, id)
 	if err != nil {
 		return fmt.Errorf("finding after create: %w", err)
@@ -134,6 +142,10 @@ func (qb *TagStore) Create(ctx context.Context, newObject *models.Tag) error {
 }
 
 func (qb *TagStore) UpdatePartial(ctx context.Context, id int, partial models.TagPartial) (*models.Tag, error) {
+	if err := tagEditTracker.track(ctx, id); err != nil {
+		return nil, err
+	}
+
 	r := tagRowRecord{
 		updateRecord{
 			Record: make(exp.Record),
@@ -152,6 +164,10 @@ func (qb *TagStore) UpdatePartial(ctx context.Context, id int, partial models.Ta
 }
 
 func (qb *TagStore) Update(ctx context.Context, updatedObject *models.Tag) error {
+	if err := tagEditTracker.track(ctx, updatedObject.ID); err != nil {
+		return err
+	}
+
 	var r tagRow
 	r.fromTag(*updatedObject)
 
@@ -502,7 +518,7 @@ func (qb *TagStore) makeFilter(ctx context.Context, tagFilter *models.TagFilterT
 	return query
 }
 
-func (qb *TagStore) Query(ctx context.Context, tagFilter *models.TagFilterType, findFilter *models.FindFilterType) ([]*models.Tag, int, error) {
+func (qb *TagStore) makeQuery(ctx context.Context, tagFilter *models.TagFilterType, findFilter *models.FindFilterType) (*queryBuilder, error) {
 	if tagFilter == nil {
 		tagFilter = &models.TagFilterType{}
 	}
@@ -513,22 +529,40 @@ func (qb *TagStore) Query(ctx context.Context, tagFilter *models.TagFilterType,
 	query := qb.newQuery()
 	distinctIDs(&query, tagTable)
 
-	if q := findFilter.Q; q != nil && *q != "" {
+	if q := findFilter.Q; q != nil && *q != "" && fullTextSearchEnabled {
+		query.parseFullTextQuery(*q)
+	} else if q != nil && *q != "" {
 		query.join(tagAliasesTable, "", "tag_aliases.tag_id = tags.id")
 		searchColumns := []string{"tags.name", "tag_aliases.alias"}
 		query.parseQueryString(searchColumns, *q)
 	}
 
 	if err := qb.validateFilter(tagFilter); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	filter := qb.makeFilter(ctx, tagFilter)
 
 	if err := query.addFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if findFilter.UsesCursor() {
+		if err := query.setKeysetPagination(ctx, tagKeysetSorts, findFilter); err != nil {
+			return nil, err
+		}
+	} else {
+		query.sortAndPagination = qb.getTagSort(&query, findFilter) + getPagination(findFilter)
+	}
+
+	return &query, nil
+}
+
+func (qb *TagStore) Query(ctx context.Context, tagFilter *models.TagFilterType, findFilter *models.FindFilterType) ([]*models.Tag, int, error) {
+	query, err := qb.makeQuery(ctx, tagFilter, findFilter)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	query.sortAndPagination = qb.getTagSort(&query, findFilter) + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind(ctx)
 	if err != nil {
 		return nil, 0, err
@@ -542,6 +576,19 @@ func (qb *TagStore) Query(ctx context.Context, tagFilter *models.TagFilterType,
 	return tags, countResult, nil
 }
 
+var tagFacets = facetDefinitions{
+	models.FacetTypeParent: relatedFacet("tags_relations", "child_id", tagTable, "parent_id", "name"),
+}
+
+func (qb *TagStore) Facets(ctx context.Context, tagFilter *models.TagFilterType, findFilter *models.FindFilterType, options models.FacetOptions) ([]*models.Facet, error) {
+	query, err := qb.makeQuery(ctx, tagFilter, findFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return qb.queryFacets(ctx, *query, tagFacets, options)
+}
+
 func tagAliasCriterionHandler(qb *TagStore, alias *models.StringCriterionInput) criterionHandlerFunc {
 	h := stringListCriterionHandlerBuilder{
 		joinTable:    tagAliasesTable,
@@ -837,6 +884,16 @@ func (qb *TagStore) getDefaultTagSort() string {
 	return getSort("name", "ASC", "tags")
 }
 
+var tagKeysetSorts = keysetSorts{
+	"name":             "COALESCE(tags.name, '') COLLATE NATURAL_CI",
+	"created_at":       "tags.created_at",
+	"updated_at":       "tags.updated_at",
+	"scenes_count":     getCountKeyset(tagTable, scenesTagsTable, tagIDColumn),
+	"images_count":     getCountKeyset(tagTable, imagesTagsTable, tagIDColumn),
+	"galleries_count":  getCountKeyset(tagTable, galleriesTagsTable, tagIDColumn),
+	"performers_count": getCountKeyset(tagTable, performersTagsTable, tagIDColumn),
+}
+
 func (qb *TagStore) getTagSort(query *queryBuilder, findFilter *models.FindFilterType) string {
 	var sort string
 	var direction string
@@ -848,6 +905,10 @@ func (qb *TagStore) getTagSort(query *queryBuilder, findFilter *models.FindFilte
 		direction = findFilter.GetDirection()
 	}
 
+	if sort == relevanceSort && query.fullTextSearch == "" {
+		sort = "name"
+	}
+
 	sortQuery := ""
 	switch sort {
 	case "scenes_count":
@@ -860,6 +921,8 @@ func (qb *TagStore) getTagSort(query *queryBuilder, findFilter *models.FindFilte
 		sortQuery += getCountSort(tagTable, galleriesTagsTable, tagIDColumn, direction)
 	case "performers_count":
 		sortQuery += getCountSort(tagTable, performersTagsTable, tagIDColumn, direction)
+	case relevanceSort:
+		sortQuery += query.getRelevanceSort(direction)
 	default:
 		sortQuery += getSort(sort, direction, "tags")
 	}
@@ -918,11 +981,31 @@ func (qb *TagStore) HasImage(ctx context.Context, tagID int) (bool, error) {
 }
 
 func (qb *TagStore) UpdateImage(ctx context.Context, tagID int, image []byte) error {
-	return qb.blobJoinQueryBuilder.UpdateImage(ctx, tagID, tagImageBlobColumn, image)
+	return qb.updatePrimaryImage(ctx, tagID, image)
 }
 
 func (qb *TagStore) destroyImage(ctx context.Context, tagID int) error {
-	return qb.blobJoinQueryBuilder.DestroyImage(ctx, tagID, tagImageBlobColumn)
+	return qb.destroyImages(ctx, tagID)
+}
+
+func (qb *TagStore) GetImages(ctx context.Context, tagID int) ([]models.EntityImage, error) {
+	return qb.getImages(ctx, tagID)
+}
+
+func (qb *TagStore) GetImageByID(ctx context.Context, tagID int, imageID int) ([]byte, error) {
+	return qb.getImageByID(ctx, tagID, imageID)
+}
+
+func (qb *TagStore) AddImage(ctx context.Context, tagID int, image []byte, primary bool) (int, error) {
+	return qb.addImage(ctx, tagID, image, primary)
+}
+
+func (qb *TagStore) SetPrimaryImage(ctx context.Context, tagID int, imageID int) error {
+	return qb.setPrimaryImage(ctx, tagID, imageID)
+}
+
+func (qb *TagStore) DestroyImageByID(ctx context.Context, tagID int, imageID int) error {
+	return qb.destroyImageByID(ctx, tagID, imageID)
 }
 
 func (qb *TagStore) aliasRepository() *stringRepository {
@@ -941,10 +1024,18 @@ func (qb *TagStore) GetAliases(ctx context.Context, tagID int) ([]string, error)
 }
 
 func (qb *TagStore) UpdateAliases(ctx context.Context, tagID int, aliases []string) error {
+	if err := tagEditTracker.track(ctx, tagID); err != nil {
+		return err
+	}
+
 	return qb.aliasRepository().replace(ctx, tagID, aliases)
 }
 
 func (qb *TagStore) Merge(ctx context.Context, source []int, destination int) error {
+	if err := tagEditTracker.track(ctx, destination); err != nil {
+		return err
+	}
+
 	if len(source) == 0 {
 		return nil
 	}
@@ -1003,6 +1094,10 @@ AND NOT EXISTS(SELECT 1 FROM `+table+` o WHERE o.`+idColumn+` = `+table+`.`+idCo
 		return err
 	}
 
+	if err := qb.mergeImages(ctx, source, destination); err != nil {
+		return err
+	}
+
 	for _, id := range source {
 		err = qb.Destroy(ctx, id)
 		if err != nil {
@@ -1014,6 +1109,10 @@ AND NOT EXISTS(SELECT 1 FROM `+table+` o WHERE o.`+idColumn+` = `+table+`.`+idCo
 }
 
 func (qb *TagStore) UpdateParentTags(ctx context.Context, tagID int, parentIDs []int) error {
+	if err := tagEditTracker.track(ctx, tagID); err != nil {
+		return err
+	}
+
 	tx := qb.tx
 	if _, err := tx.Exec(ctx, "DELETE FROM tags_relations WHERE child_id = ?", tagID); err != nil {
 		return err
@@ -1037,6 +1136,10 @@ func (qb *TagStore) UpdateParentTags(ctx context.Context, tagID int, parentIDs [
 }
 
 func (qb *TagStore) UpdateChildTags(ctx context.Context, tagID int, childIDs []int) error {
+	if err := tagEditTracker.track(ctx, tagID); err != nil {
+		return err
+	}
+
 	tx := qb.tx
 	if _, err := tx.Exec(ctx, "DELETE FROM tags_relations WHERE parent_id = ?", tagID); err != nil {
 		return err
@@ -0,0 +1,324 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+)
+
+const (
+	tagImplicationTable = "tag_implications"
+)
+
+type tagImplicationRow struct {
+	ID                int       `db:"id" goqu:"skipinsert"`
+	SourceTagID       null.Int  `db:"source_tag_id"`
+	SourcePerformerID null.Int  `db:"source_performer_id"`
+	ImpliedTagID      int       `db:"implied_tag_id"`
+	CreatedAt         Timestamp `db:"created_at"`
+}
+
+func (r *tagImplicationRow) fromTagImplication(o models.TagImplication) {
+	r.ID = o.ID
+	r.SourceTagID = intFromPtr(o.SourceTagID)
+	r.SourcePerformerID = intFromPtr(o.SourcePerformerID)
+	r.ImpliedTagID = o.ImpliedTagID
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+}
+
+func (r *tagImplicationRow) resolve() *models.TagImplication {
+	return &models.TagImplication{
+		ID:                r.ID,
+		SourceTagID:       nullIntPtr(r.SourceTagID),
+		SourcePerformerID: nullIntPtr(r.SourcePerformerID),
+		ImpliedTagID:      r.ImpliedTagID,
+		CreatedAt:         r.CreatedAt.Timestamp,
+	}
+}
+
+// tagImplicationTarget applies tag implications to objects of a type,
+// through the tags and performers join tables of the type.
+type tagImplicationTarget struct {
+	tagsTableMgr    *joinTable
+	tagsTable       string
+	performersTable string
+	idColumn        string
+	tracker         *editTracker
+}
+
+var (
+	sceneTagImplications = &tagImplicationTarget{
+		tagsTableMgr:    scenesTagsTableMgr,
+		tagsTable:       scenesTagsTable,
+		performersTable: performersScenesTable,
+		idColumn:        sceneIDColumn,
+		tracker:         sceneEditTracker,
+	}
+
+	imageTagImplications = &tagImplicationTarget{
+		tagsTableMgr:    imagesTagsTableMgr,
+		tagsTable:       imagesTagsTable,
+		performersTable: performersImagesTable,
+		idColumn:        imageIDColumn,
+		tracker:         imageEditTracker,
+	}
+
+	galleryTagImplications = &tagImplicationTarget{
+		tagsTableMgr:    galleriesTagsTableMgr,
+		tagsTable:       galleriesTagsTable,
+		performersTable: performersGalleriesTable,
+		idColumn:        galleryIDColumn,
+		tracker:         galleryEditTracker,
+	}
+)
+
+func getTagImplicationTarget(objectType models.TagImplicationObjectType) (*tagImplicationTarget, error) {
+	switch objectType {
+	case models.TagImplicationObjectTypeScene:
+		return sceneTagImplications, nil
+	case models.TagImplicationObjectTypeImage:
+		return imageTagImplications, nil
+	case models.TagImplicationObjectTypeGallery:
+		return galleryTagImplications, nil
+	}
+
+	return nil, fmt.Errorf("invalid tag implication object type %q", objectType)
+}
+
+// findMissing returns the implied tags which the objects do not have. The
+// recursive query follows the rules of implied tags, and the union discards
+// tags which have already been implied, so cyclic rules terminate.
+func (t *tagImplicationTarget) findMissing(ctx context.Context, ids []int) ([]*models.ImpliedTag, error) {
+	objectWhere := ""
+	var args []interface{}
+	if ids != nil {
+		if len(ids) == 0 {
+			return nil, nil
+		}
+
+		objectWhere = "WHERE j." + t.idColumn + " IN " + getInBinding(len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	// the object ids are bound once for each source
+	args = append(args, args...)
+
+	query := fmt.Sprintf(`WITH RECURSIVE implied(object_id, tag_id) AS (
+	SELECT j.%[1]s, ti.implied_tag_id FROM %[2]s j
+	INNER JOIN %[4]s ti ON ti.source_tag_id = j.tag_id
+	%[5]s
+	UNION
+	SELECT j.%[1]s, ti.implied_tag_id FROM %[3]s j
+	INNER JOIN %[4]s ti ON ti.source_performer_id = j.performer_id
+	%[5]s
+	UNION
+	SELECT implied.object_id, ti.implied_tag_id FROM implied
+	INNER JOIN %[4]s ti ON ti.source_tag_id = implied.tag_id
+)
+SELECT implied.object_id, implied.tag_id FROM implied
+WHERE NOT EXISTS (
+	SELECT 1 FROM %[2]s j WHERE j.%[1]s = implied.object_id AND j.tag_id = implied.tag_id
+)
+ORDER BY implied.object_id, implied.tag_id`, t.idColumn, t.tagsTable, t.performersTable, tagImplicationTable, objectWhere)
+
+	r := repository{}
+	var ret []*models.ImpliedTag
+	if err := r.queryFunc(ctx, query, args, false, func(rows *sqlx.Rows) error {
+		var i models.ImpliedTag
+		if err := rows.StructScan(&i); err != nil {
+			return err
+		}
+		ret = append(ret, &i)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("finding implied tags: %w", err)
+	}
+
+	return ret, nil
+}
+
+// apply adds the missing implied tags to the objects, returning the added
+// tags.
+func (t *tagImplicationTarget) apply(ctx context.Context, ids []int) ([]*models.ImpliedTag, error) {
+	missing, err := t.findMissing(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	objectIDs, tagIDs := models.GroupImpliedTags(missing)
+
+	for _, id := range objectIDs {
+		if err := t.tracker.track(ctx, id); err != nil {
+			return nil, err
+		}
+
+		if err := t.tagsTableMgr.insertJoins(ctx, id, tagIDs[id]); err != nil {
+			return nil, fmt.Errorf("adding implied tags: %w", err)
+		}
+	}
+
+	return missing, nil
+}
+
+// applyTagImplications adds the implied tags to the object with the given
+// id. It is called after the tags or performers of the object are written.
+func applyTagImplications(ctx context.Context, t *tagImplicationTarget, id int) error {
+	_, err := t.apply(ctx, []int{id})
+	return err
+}
+
+type TagImplicationStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewTagImplicationStore() *TagImplicationStore {
+	return &TagImplicationStore{
+		repository: repository{
+			tableName: tagImplicationTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: tagImplicationTableMgr,
+	}
+}
+
+func (qb *TagImplicationStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *TagImplicationStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *TagImplicationStore) Create(ctx context.Context, newObject *models.TagImplication) error {
+	if err := newObject.Validate(); err != nil {
+		return err
+	}
+
+	var r tagImplicationRow
+	r.fromTagImplication(*newObject)
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	updated, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding after create: %w", err)
+	}
+
+	*newObject = *updated
+
+	return nil
+}
+
+func (qb *TagImplicationStore) Destroy(ctx context.Context, id int) error {
+	return qb.destroyExisting(ctx, []int{id})
+}
+
+// returns nil, nil if not found
+func (qb *TagImplicationStore) Find(ctx context.Context, id int) (*models.TagImplication, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+func (qb *TagImplicationStore) FindMany(ctx context.Context, ids []int) ([]*models.TagImplication, error) {
+	ret := make([]*models.TagImplication, len(ids))
+
+	table := qb.table()
+	q := qb.selectDataset().Prepared(true).Where(table.Col(idColumn).In(ids))
+	unsorted, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range unsorted {
+		i := intslice.IntIndex(ids, s.ID)
+		ret[i] = s
+	}
+
+	for i := range ret {
+		if ret[i] == nil {
+			return nil, fmt.Errorf("tag implication with id %d not found", ids[i])
+		}
+	}
+
+	return ret, nil
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *TagImplicationStore) find(ctx context.Context, id int) (*models.TagImplication, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *TagImplicationStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.TagImplication, error) {
+	const single = false
+	var ret []*models.TagImplication
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f tagImplicationRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		ret = append(ret, f.resolve())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (qb *TagImplicationStore) All(ctx context.Context) ([]*models.TagImplication, error) {
+	return qb.getMany(ctx, qb.selectDataset().Order(qb.table().Col(idColumn).Asc()))
+}
+
+func (qb *TagImplicationStore) Count(ctx context.Context) (int, error) {
+	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	return count(ctx, q)
+}
+
+func (qb *TagImplicationStore) FindMissing(ctx context.Context, objectType models.TagImplicationObjectType, ids []int) ([]*models.ImpliedTag, error) {
+	t, err := getTagImplicationTarget(objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.findMissing(ctx, ids)
+}
+
+func (qb *TagImplicationStore) Apply(ctx context.Context, objectType models.TagImplicationObjectType, ids []int) ([]*models.ImpliedTag, error) {
+	t, err := getTagImplicationTarget(objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.apply(ctx, ids)
+}
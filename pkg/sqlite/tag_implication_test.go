@@ -0,0 +1,233 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestImplicationTags(ctx context.Context, t *testing.T, names ...string) []int {
+	t.Helper()
+
+	ret := make([]int, len(names))
+	for i, name := range names {
+		tag := &models.Tag{
+			Name: "implication " + name,
+		}
+
+		if err := db.Tag.Create(ctx, tag); err != nil {
+			t.Fatalf("TagStore.Create() error = %v", err)
+		}
+		ret[i] = tag.ID
+	}
+
+	return ret
+}
+
+func createTestTagImplication(ctx context.Context, t *testing.T, sourceTagID *int, sourcePerformerID *int, impliedTagID int) *models.TagImplication {
+	t.Helper()
+
+	i := &models.TagImplication{
+		SourceTagID:       sourceTagID,
+		SourcePerformerID: sourcePerformerID,
+		ImpliedTagID:      impliedTagID,
+		CreatedAt:         time.Now(),
+	}
+
+	if err := db.TagImplication.Create(ctx, i); err != nil {
+		t.Fatalf("TagImplicationStore.Create() error = %v", err)
+	}
+
+	return i
+}
+
+func TestTagImplicationCreateDestroy(t *testing.T) {
+	runWithRollbackTxn(t, "create and destroy", func(t *testing.T, ctx context.Context) {
+		ids := createTestImplicationTags(ctx, t, "a", "b")
+		performerID := performerIDs[performerIdxWithScene]
+
+		i := createTestTagImplication(ctx, t, &ids[0], nil, ids[1])
+		createTestTagImplication(ctx, t, nil, &performerID, ids[1])
+
+		found, err := db.TagImplication.Find(ctx, i.ID)
+		if err != nil {
+			t.Errorf("TagImplicationStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, &ids[0], found.SourceTagID)
+		assert.Nil(t, found.SourcePerformerID)
+		assert.Equal(t, ids[1], found.ImpliedTagID)
+
+		// rules must be unique
+		assert.Error(t, db.TagImplication.Create(ctx, &models.TagImplication{
+			SourceTagID:  &ids[0],
+			ImpliedTagID: ids[1],
+		}))
+
+		assert.ErrorIs(t, db.TagImplication.Create(ctx, &models.TagImplication{
+			SourceTagID:  &ids[0],
+			ImpliedTagID: ids[0],
+		}), models.ErrTagImplicationSelf)
+		assert.ErrorIs(t, db.TagImplication.Create(ctx, &models.TagImplication{
+			ImpliedTagID: ids[0],
+		}), models.ErrTagImplicationSource)
+		assert.ErrorIs(t, db.TagImplication.Create(ctx, &models.TagImplication{
+			SourceTagID:       &ids[0],
+			SourcePerformerID: &performerID,
+			ImpliedTagID:      ids[1],
+		}), models.ErrTagImplicationSource)
+
+		all, err := db.TagImplication.All(ctx)
+		if err != nil {
+			t.Errorf("TagImplicationStore.All() error = %v", err)
+			return
+		}
+		assert.Len(t, all, 2)
+
+		if err := db.TagImplication.Destroy(ctx, i.ID); err != nil {
+			t.Errorf("TagImplicationStore.Destroy() error = %v", err)
+			return
+		}
+
+		found, err = db.TagImplication.Find(ctx, i.ID)
+		if err != nil {
+			t.Errorf("TagImplicationStore.Find() error = %v", err)
+			return
+		}
+		assert.Nil(t, found)
+	})
+}
+
+func TestTagImplicationAppliedOnWrite(t *testing.T) {
+	runWithRollbackTxn(t, "applied on write", func(t *testing.T, ctx context.Context) {
+		ids := createTestImplicationTags(ctx, t, "a", "b", "c", "d")
+		a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+		performerID := performerIDs[performerIdxWithImage]
+
+		// cyclic rules must terminate
+		createTestTagImplication(ctx, t, &a, nil, b)
+		createTestTagImplication(ctx, t, &b, nil, c)
+		createTestTagImplication(ctx, t, &c, nil, a)
+		createTestTagImplication(ctx, t, nil, &performerID, d)
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		if _, err := db.Scene.UpdatePartial(ctx, sceneID, models.ScenePartial{
+			TagIDs: &models.UpdateIDs{
+				IDs:  []int{a},
+				Mode: models.RelationshipUpdateModeAdd,
+			},
+		}); err != nil {
+			t.Errorf("SceneStore.UpdatePartial() error = %v", err)
+			return
+		}
+
+		tagIDs, err := db.Scene.GetTagIDs(ctx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.GetTagIDs() error = %v", err)
+			return
+		}
+		assert.Subset(t, tagIDs, []int{a, b, c})
+		assert.NotContains(t, tagIDs, d)
+
+		image := &models.Image{}
+		if err := db.Image.Create(ctx, &models.ImageCreateInput{
+			Image: image,
+		}); err != nil {
+			t.Errorf("ImageStore.Create() error = %v", err)
+			return
+		}
+
+		image.PerformerIDs = models.NewRelatedIDs([]int{performerID})
+		image.TagIDs = models.NewRelatedIDs([]int{c})
+		if err := db.Image.Update(ctx, image); err != nil {
+			t.Errorf("ImageStore.Update() error = %v", err)
+			return
+		}
+
+		tagIDs, err = db.Image.GetTagIDs(ctx, image.ID)
+		if err != nil {
+			t.Errorf("ImageStore.GetTagIDs() error = %v", err)
+			return
+		}
+		assert.ElementsMatch(t, []int{a, b, c, d}, tagIDs)
+
+		gallery := &models.Gallery{
+			PerformerIDs: models.NewRelatedIDs([]int{performerID}),
+		}
+		if err := db.Gallery.Create(ctx, gallery, nil); err != nil {
+			t.Errorf("GalleryStore.Create() error = %v", err)
+			return
+		}
+
+		tagIDs, err = db.Gallery.GetTagIDs(ctx, gallery.ID)
+		if err != nil {
+			t.Errorf("GalleryStore.GetTagIDs() error = %v", err)
+			return
+		}
+		assert.ElementsMatch(t, []int{d}, tagIDs)
+	})
+}
+
+func TestTagImplicationApply(t *testing.T) {
+	runWithRollbackTxn(t, "apply", func(t *testing.T, ctx context.Context) {
+		ids := createTestImplicationTags(ctx, t, "source", "implied")
+		source, implied := ids[0], ids[1]
+
+		sceneID := sceneIDs[sceneIdxWithGallery]
+		if _, err := db.Scene.UpdatePartial(ctx, sceneID, models.ScenePartial{
+			TagIDs: &models.UpdateIDs{
+				IDs:  []int{source},
+				Mode: models.RelationshipUpdateModeAdd,
+			},
+		}); err != nil {
+			t.Errorf("SceneStore.UpdatePartial() error = %v", err)
+			return
+		}
+
+		// rules are not applied when they are created
+		createTestTagImplication(ctx, t, &source, nil, implied)
+
+		want := []*models.ImpliedTag{{ObjectID: sceneID, TagID: implied}}
+
+		missing, err := db.TagImplication.FindMissing(ctx, models.TagImplicationObjectTypeScene, nil)
+		if err != nil {
+			t.Errorf("TagImplicationStore.FindMissing() error = %v", err)
+			return
+		}
+		assert.Equal(t, want, missing)
+
+		missing, err = db.TagImplication.FindMissing(ctx, models.TagImplicationObjectTypeScene, []int{sceneIDs[sceneIdxWithMovie]})
+		if err != nil {
+			t.Errorf("TagImplicationStore.FindMissing() error = %v", err)
+			return
+		}
+		assert.Empty(t, missing)
+
+		added, err := db.TagImplication.Apply(ctx, models.TagImplicationObjectTypeScene, nil)
+		if err != nil {
+			t.Errorf("TagImplicationStore.Apply() error = %v", err)
+			return
+		}
+		assert.Equal(t, want, added)
+
+		tagIDs, err := db.Scene.GetTagIDs(ctx, sceneID)
+		if err != nil {
+			t.Errorf("SceneStore.GetTagIDs() error = %v", err)
+			return
+		}
+		assert.Contains(t, tagIDs, implied)
+
+		missing, err = db.TagImplication.FindMissing(ctx, models.TagImplicationObjectTypeScene, nil)
+		if err != nil {
+			t.Errorf("TagImplicationStore.FindMissing() error = %v", err)
+			return
+		}
+		assert.Empty(t, missing)
+	})
+}
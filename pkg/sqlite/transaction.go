@@ -18,6 +18,7 @@ const (
 	txnKey key = iota + 1
 	dbKey
 	exclusiveKey
+	editSessionKey
 )
 
 func (db *Database) WithDatabase(ctx context.Context) (context.Context, error) {
@@ -54,6 +55,11 @@ func (db *Database) Begin(ctx context.Context, exclusive bool) (context.Context,
 
 	ctx = context.WithValue(ctx, exclusiveKey, exclusive)
 
+	// record changes to entities if the transaction has an edit source
+	if source := models.EditSourceFromContext(ctx); source != "" {
+		ctx = context.WithValue(ctx, editSessionKey, &editSession{source: source})
+	}
+
 	return context.WithValue(ctx, txnKey, tx), nil
 }
 
@@ -125,19 +131,28 @@ func (db *Database) IsLocked(err error) bool {
 
 func (db *Database) TxnRepository() models.Repository {
 	return models.Repository{
-		TxnManager:     db,
-		File:           db.File,
-		Folder:         db.Folder,
-		Gallery:        db.Gallery,
-		GalleryChapter: db.GalleryChapter,
-		Image:          db.Image,
-		Movie:          db.Movie,
-		Performer:      db.Performer,
-		Scene:          db.Scene,
-		SceneMarker:    db.SceneMarker,
-		ScrapedItem:    ScrapedItemReaderWriter,
-		Studio:         db.Studio,
-		Tag:            db.Tag,
-		SavedFilter:    db.SavedFilter,
+		TxnManager:         db,
+		File:               db.File,
+		Folder:             db.Folder,
+		Gallery:            db.Gallery,
+		GalleryChapter:     db.GalleryChapter,
+		Image:              db.Image,
+		Movie:              db.Movie,
+		Performer:          db.Performer,
+		Scene:              db.Scene,
+		SceneMarker:        db.SceneMarker,
+		ScrapedItem:        ScrapedItemReaderWriter,
+		Studio:             db.Studio,
+		Tag:                db.Tag,
+		SavedFilter:        db.SavedFilter,
+		Playlist:           db.Playlist,
+		TagImplication:     db.TagImplication,
+		User:               db.User,
+		APIKey:             db.APIKey,
+		RestrictionProfile: db.RestrictionProfile,
+		AuditEvent:         db.AuditEvent,
+		ShareLink:          db.ShareLink,
+		Edit:               db.Edit,
+		Statistics:         db.Statistics,
 	}
 }
@@ -0,0 +1,232 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+	"gopkg.in/guregu/null.v4/zero"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	userTable = "users"
+)
+
+type userRow struct {
+	ID           int       `db:"id" goqu:"skipinsert"`
+	Username     string    `db:"username"`
+	PasswordHash string    `db:"password_hash"`
+	Role         string    `db:"role"`
+	CreatedAt    Timestamp `db:"created_at"`
+	UpdatedAt    Timestamp `db:"updated_at"`
+
+	RestrictionProfileID null.Int `db:"restriction_profile_id"`
+
+	TOTPSecret         string `db:"totp_secret"`
+	TOTPEnabled        bool   `db:"totp_enabled"`
+	TOTPLastStep       int64  `db:"totp_last_step"`
+	RecoveryCodeHashes string `db:"recovery_code_hashes"`
+
+	OIDCIssuer  zero.String `db:"oidc_issuer"`
+	OIDCSubject zero.String `db:"oidc_subject"`
+}
+
+func (r *userRow) fromUser(o models.User) {
+	r.ID = o.ID
+	r.Username = o.Username
+	r.PasswordHash = o.PasswordHash
+	r.Role = o.Role.String()
+	r.CreatedAt = Timestamp{Timestamp: o.CreatedAt}
+	r.UpdatedAt = Timestamp{Timestamp: o.UpdatedAt}
+	r.RestrictionProfileID = intFromPtr(o.RestrictionProfileID)
+	r.TOTPSecret = o.TOTPSecret
+	r.TOTPEnabled = o.TOTPEnabled
+	r.TOTPLastStep = o.TOTPLastStep
+	r.RecoveryCodeHashes = strings.Join(o.RecoveryCodeHashes, ",")
+	r.OIDCIssuer = zero.StringFrom(o.OIDCIssuer)
+	r.OIDCSubject = zero.StringFrom(o.OIDCSubject)
+}
+
+func (r *userRow) resolve() *models.User {
+	var recoveryCodeHashes []string
+	if r.RecoveryCodeHashes != "" {
+		recoveryCodeHashes = strings.Split(r.RecoveryCodeHashes, ",")
+	}
+
+	return &models.User{
+		ID:           r.ID,
+		Username:     r.Username,
+		PasswordHash: r.PasswordHash,
+		Role:         models.UserRole(r.Role),
+		CreatedAt:    r.CreatedAt.Timestamp,
+		UpdatedAt:    r.UpdatedAt.Timestamp,
+
+		RestrictionProfileID: nullIntPtr(r.RestrictionProfileID),
+
+		TOTPSecret:         r.TOTPSecret,
+		TOTPEnabled:        r.TOTPEnabled,
+		TOTPLastStep:       r.TOTPLastStep,
+		RecoveryCodeHashes: recoveryCodeHashes,
+
+		OIDCIssuer:  r.OIDCIssuer.String,
+		OIDCSubject: r.OIDCSubject.String,
+	}
+}
+
+type UserStore struct {
+	repository
+
+	tableMgr *table
+}
+
+func NewUserStore() *UserStore {
+	return &UserStore{
+		repository: repository{
+			tableName: userTable,
+			idColumn:  idColumn,
+		},
+		tableMgr: userTableMgr,
+	}
+}
+
+func (qb *UserStore) table() exp.IdentifierExpression {
+	return qb.tableMgr.table
+}
+
+func (qb *UserStore) selectDataset() *goqu.SelectDataset {
+	return dialect.From(qb.table()).Select(qb.table().All())
+}
+
+func (qb *UserStore) Create(ctx context.Context, newObject *models.User) error {
+	if err := newObject.Validate(); err != nil {
+		return err
+	}
+
+	var r userRow
+	r.fromUser(*newObject)
+
+	id, err := qb.tableMgr.insertID(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	updated, err := qb.find(ctx, id)
+	if err != nil {
+		return fmt.Errorf("finding after create: %w", err)
+	}
+
+	*newObject = *updated
+
+	return nil
+}
+
+func (qb *UserStore) Update(ctx context.Context, updatedObject *models.User) error {
+	if err := updatedObject.Validate(); err != nil {
+		return err
+	}
+
+	var r userRow
+	r.fromUser(*updatedObject)
+
+	return qb.tableMgr.updateByID(ctx, updatedObject.ID, r)
+}
+
+func (qb *UserStore) Destroy(ctx context.Context, id int) error {
+	return qb.destroyExisting(ctx, []int{id})
+}
+
+// returns nil, nil if not found
+func (qb *UserStore) Find(ctx context.Context, id int) (*models.User, error) {
+	ret, err := qb.find(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *UserStore) find(ctx context.Context, id int) (*models.User, error) {
+	q := qb.selectDataset().Where(qb.tableMgr.byID(id))
+
+	return qb.get(ctx, q)
+}
+
+// returns nil, nil if not found
+func (qb *UserStore) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	// username column is case insensitive
+	q := qb.selectDataset().Where(qb.table().Col("username").Eq(username))
+
+	ret, err := qb.get(ctx, q)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// returns nil, nil if not found
+func (qb *UserStore) FindByOIDCSubject(ctx context.Context, issuer string, subject string) (*models.User, error) {
+	table := qb.table()
+	q := qb.selectDataset().Where(
+		table.Col("oidc_issuer").Eq(issuer),
+		table.Col("oidc_subject").Eq(subject),
+	)
+
+	ret, err := qb.get(ctx, q)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+// returns nil, sql.ErrNoRows if not found
+func (qb *UserStore) get(ctx context.Context, q *goqu.SelectDataset) (*models.User, error) {
+	ret, err := qb.getMany(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return ret[0], nil
+}
+
+func (qb *UserStore) getMany(ctx context.Context, q *goqu.SelectDataset) ([]*models.User, error) {
+	const single = false
+	var ret []*models.User
+	if err := queryFunc(ctx, q, single, func(r *sqlx.Rows) error {
+		var f userRow
+		if err := r.StructScan(&f); err != nil {
+			return err
+		}
+
+		ret = append(ret, f.resolve())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (qb *UserStore) All(ctx context.Context) ([]*models.User, error) {
+	table := qb.table()
+	return qb.getMany(ctx, qb.selectDataset().Order(
+		table.Col("username").Asc(),
+		table.Col(idColumn).Asc(),
+	))
+}
+
+func (qb *UserStore) Count(ctx context.Context) (int, error) {
+	q := dialect.Select(goqu.COUNT("*")).From(qb.table())
+	return count(ctx, q)
+}
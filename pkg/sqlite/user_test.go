@@ -0,0 +1,135 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCreateUpdateDestroy(t *testing.T) {
+	runWithRollbackTxn(t, "create update destroy", func(t *testing.T, ctx context.Context) {
+		now := time.Now()
+		u := &models.User{
+			Username:     "Household",
+			PasswordHash: "hash",
+			Role:         models.UserRoleViewer,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+
+		if err := db.User.Create(ctx, u); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		// usernames are unique ignoring case
+		assert.Error(t, db.User.Create(ctx, &models.User{
+			Username: "HOUSEHOLD",
+			Role:     models.UserRoleViewer,
+		}))
+		assert.ErrorIs(t, db.User.Create(ctx, &models.User{
+			Username: "other",
+			Role:     "OWNER",
+		}), models.ErrInvalidUserRole)
+		assert.ErrorIs(t, db.User.Create(ctx, &models.User{
+			Role: models.UserRoleViewer,
+		}), models.ErrUsernameRequired)
+
+		found, err := db.User.FindByUsername(ctx, "household")
+		if err != nil {
+			t.Errorf("UserStore.FindByUsername() error = %v", err)
+			return
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, u.ID, found.ID)
+			assert.Equal(t, "Household", found.Username)
+			assert.Equal(t, "hash", found.PasswordHash)
+		}
+
+		u.Role = models.UserRoleEditor
+		u.TOTPSecret = "SECRET"
+		u.TOTPEnabled = true
+		u.TOTPLastStep = 100
+		u.RecoveryCodeHashes = []string{"a", "b"}
+		if err := db.User.Update(ctx, u); err != nil {
+			t.Errorf("UserStore.Update() error = %v", err)
+			return
+		}
+
+		found, err = db.User.Find(ctx, u.ID)
+		if err != nil {
+			t.Errorf("UserStore.Find() error = %v", err)
+			return
+		}
+		assert.Equal(t, models.UserRoleEditor, found.Role)
+		assert.Equal(t, "SECRET", found.TOTPSecret)
+		assert.True(t, found.TOTPEnabled)
+		assert.Equal(t, int64(100), found.TOTPLastStep)
+		assert.Equal(t, []string{"a", "b"}, found.RecoveryCodeHashes)
+
+		if err := db.User.Destroy(ctx, u.ID); err != nil {
+			t.Errorf("UserStore.Destroy() error = %v", err)
+			return
+		}
+
+		found, err = db.User.FindByUsername(ctx, "household")
+		if err != nil {
+			t.Errorf("UserStore.FindByUsername() error = %v", err)
+			return
+		}
+		assert.Nil(t, found)
+	})
+}
+
+func TestUserFindByOIDCSubject(t *testing.T) {
+	runWithRollbackTxn(t, "find by oidc subject", func(t *testing.T, ctx context.Context) {
+		linked := &models.User{
+			Username:    "linked",
+			Role:        models.UserRoleViewer,
+			OIDCIssuer:  "https://issuer",
+			OIDCSubject: "subject",
+		}
+		if err := db.User.Create(ctx, linked); err != nil {
+			t.Errorf("UserStore.Create() error = %v", err)
+			return
+		}
+
+		// users which are not linked do not conflict
+		for _, username := range []string{"unlinked", "unlinked2"} {
+			if err := db.User.Create(ctx, &models.User{Username: username, Role: models.UserRoleViewer}); err != nil {
+				t.Errorf("UserStore.Create() error = %v", err)
+				return
+			}
+		}
+
+		// a subject may only be linked to one user
+		assert.Error(t, db.User.Create(ctx, &models.User{
+			Username:    "other",
+			Role:        models.UserRoleViewer,
+			OIDCIssuer:  "https://issuer",
+			OIDCSubject: "subject",
+		}))
+
+		found, err := db.User.FindByOIDCSubject(ctx, "https://issuer", "subject")
+		if err != nil {
+			t.Errorf("UserStore.FindByOIDCSubject() error = %v", err)
+			return
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, linked.ID, found.ID)
+		}
+
+		found, err = db.User.FindByOIDCSubject(ctx, "https://other", "subject")
+		if err != nil {
+			t.Errorf("UserStore.FindByOIDCSubject() error = %v", err)
+			return
+		}
+		assert.Nil(t, found)
+	})
+}
@@ -15,6 +15,7 @@ type FinderImageStashIDGetter interface {
 	Finder
 	GetAliases(ctx context.Context, studioID int) ([]string, error)
 	GetImage(ctx context.Context, studioID int) ([]byte, error)
+	models.EntityImageGetter
 	models.StashIDLoader
 }
 
@@ -60,6 +61,15 @@ func ToJSON(ctx context.Context, reader FinderImageStashIDGetter, studio *models
 		newStudioJSON.Image = utils.GetBase64StringFromData(image)
 	}
 
+	images, err := models.GetSecondaryImages(ctx, reader, studio.ID)
+	if err != nil {
+		logger.Errorf("Error getting studio images: %v", err)
+	}
+
+	for _, image := range images {
+		newStudioJSON.Images = append(newStudioJSON.Images, utils.GetBase64StringFromData(image))
+	}
+
 	stashIDs, _ := reader.GetStashIDs(ctx, studio.ID)
 	var ret []models.StashID
 	for _, stashID := range stashIDs {
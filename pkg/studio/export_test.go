@@ -9,6 +9,7 @@ import (
 	"github.com/stashapp/stash/pkg/models/jsonschema"
 	"github.com/stashapp/stash/pkg/models/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"testing"
 	"time"
@@ -176,6 +177,8 @@ func TestToJSON(t *testing.T) {
 	mockStudioReader.On("GetImage", ctx, errStudioID).Return(imageBytes, nil).Maybe()
 	mockStudioReader.On("GetImage", ctx, errAliasID).Return(imageBytes, nil).Maybe()
 
+	mockStudioReader.On("GetImages", ctx, mock.Anything).Return(nil, nil)
+
 	parentStudioErr := errors.New("error getting parent studio")
 
 	mockStudioReader.On("Find", ctx, parentStudioID).Return(&parentStudio, nil)
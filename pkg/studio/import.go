@@ -15,6 +15,7 @@ type NameFinderCreatorUpdater interface {
 	NameFinderCreator
 	Update(ctx context.Context, updatedStudio *models.Studio) error
 	UpdateImage(ctx context.Context, studioID int, image []byte) error
+	AddImage(ctx context.Context, studioID int, image []byte, primary bool) (int, error)
 	UpdateAliases(ctx context.Context, studioID int, aliases []string) error
 	UpdateStashIDs(ctx context.Context, studioID int, stashIDs []models.StashID) error
 }
@@ -26,8 +27,9 @@ type Importer struct {
 	Input               jsonschema.Studio
 	MissingRefBehaviour models.ImportMissingRefEnum
 
-	studio    models.Studio
-	imageData []byte
+	studio     models.Studio
+	imageData  []byte
+	imagesData [][]byte
 }
 
 func (i *Importer) PreImport(ctx context.Context) error {
@@ -56,6 +58,15 @@ func (i *Importer) PreImport(ctx context.Context) error {
 		}
 	}
 
+	for _, image := range i.Input.Images {
+		data, err := utils.ProcessBase64Image(image)
+		if err != nil {
+			return fmt.Errorf("invalid image: %v", err)
+		}
+
+		i.imagesData = append(i.imagesData, data)
+	}
+
 	return nil
 }
 
@@ -108,6 +119,12 @@ func (i *Importer) PostImport(ctx context.Context, id int) error {
 		}
 	}
 
+	for _, data := range i.imagesData {
+		if _, err := i.ReaderWriter.AddImage(ctx, id, data, false); err != nil {
+			return fmt.Errorf("error adding studio image: %v", err)
+		}
+	}
+
 	if len(i.Input.StashIDs) > 0 {
 		if err := i.ReaderWriter.UpdateStashIDs(ctx, id, i.Input.StashIDs); err != nil {
 			return fmt.Errorf("error setting stash id: %v", err)
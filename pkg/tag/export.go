@@ -14,6 +14,7 @@ import (
 type FinderAliasImageGetter interface {
 	GetAliases(ctx context.Context, studioID int) ([]string, error)
 	GetImage(ctx context.Context, tagID int) ([]byte, error)
+	models.EntityImageGetter
 	FindByChildTagID(ctx context.Context, childID int) ([]*models.Tag, error)
 }
 
@@ -43,6 +44,15 @@ func ToJSON(ctx context.Context, reader FinderAliasImageGetter, tag *models.Tag)
 		newTagJSON.Image = utils.GetBase64StringFromData(image)
 	}
 
+	images, err := models.GetSecondaryImages(ctx, reader, tag.ID)
+	if err != nil {
+		logger.Errorf("Error getting tag images: %v", err)
+	}
+
+	for _, image := range images {
+		newTagJSON.Images = append(newTagJSON.Images, utils.GetBase64StringFromData(image))
+	}
+
 	parents, err := reader.FindByChildTagID(ctx, tag.ID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting parents: %v", err)
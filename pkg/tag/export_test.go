@@ -9,6 +9,7 @@ import (
 	"github.com/stashapp/stash/pkg/models/jsonschema"
 	"github.com/stashapp/stash/pkg/models/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"testing"
 	"time"
@@ -129,6 +130,8 @@ func TestToJSON(t *testing.T) {
 	mockTagReader.On("GetImage", ctx, withParentsID).Return(imageBytes, nil).Once()
 	mockTagReader.On("GetImage", ctx, errParentsID).Return(nil, nil).Once()
 
+	mockTagReader.On("GetImages", ctx, mock.Anything).Return(nil, nil)
+
 	mockTagReader.On("FindByChildTagID", ctx, tagID).Return(nil, nil).Once()
 	mockTagReader.On("FindByChildTagID", ctx, noImageID).Return(nil, nil).Once()
 	mockTagReader.On("FindByChildTagID", ctx, withParentsID).Return([]*models.Tag{{Name: "parent"}}, nil).Once()
@@ -14,6 +14,7 @@ type NameFinderCreatorUpdater interface {
 	Create(ctx context.Context, newTag *models.Tag) error
 	Update(ctx context.Context, updatedTag *models.Tag) error
 	UpdateImage(ctx context.Context, tagID int, image []byte) error
+	AddImage(ctx context.Context, tagID int, image []byte, primary bool) (int, error)
 	UpdateAliases(ctx context.Context, tagID int, aliases []string) error
 	UpdateParentTags(ctx context.Context, tagID int, parentIDs []int) error
 }
@@ -35,8 +36,9 @@ type Importer struct {
 	Input               jsonschema.Tag
 	MissingRefBehaviour models.ImportMissingRefEnum
 
-	tag       models.Tag
-	imageData []byte
+	tag        models.Tag
+	imageData  []byte
+	imagesData [][]byte
 }
 
 func (i *Importer) PreImport(ctx context.Context) error {
@@ -56,6 +58,15 @@ func (i *Importer) PreImport(ctx context.Context) error {
 		}
 	}
 
+	for _, image := range i.Input.Images {
+		data, err := utils.ProcessBase64Image(image)
+		if err != nil {
+			return fmt.Errorf("invalid image: %v", err)
+		}
+
+		i.imagesData = append(i.imagesData, data)
+	}
+
 	return nil
 }
 
@@ -66,6 +77,12 @@ func (i *Importer) PostImport(ctx context.Context, id int) error {
 		}
 	}
 
+	for _, data := range i.imagesData {
+		if _, err := i.ReaderWriter.AddImage(ctx, id, data, false); err != nil {
+			return fmt.Errorf("error adding tag image: %v", err)
+		}
+	}
+
 	if err := i.ReaderWriter.UpdateAliases(ctx, id, i.Input.Aliases); err != nil {
 		return fmt.Errorf("error setting tag aliases: %v", err)
 	}
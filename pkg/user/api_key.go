@@ -0,0 +1,209 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+const (
+	// apiKeyPrefix identifies keys issued by CreateAPIKey, as opposed to the
+	// configured API key.
+	apiKeyPrefix = "stash_"
+	apiKeyLength = 32
+
+	// lastUsedInterval is how often the last used time of a key is updated,
+	// so that every request does not write to the database.
+	lastUsedInterval = time.Minute
+
+	// StreamKeyName is the name of the keys issued by StreamAPIKey.
+	StreamKeyName = "external player"
+	// streamKeyLifetime is how long keys issued by StreamAPIKey are valid.
+	// They are replaced after half their lifetime, so that the URLs they
+	// are added to remain valid for at least the other half.
+	streamKeyLifetime = 24 * time.Hour
+)
+
+var ErrAPIKeyExpiry = errors.New("api key expiry must be in the future")
+
+// HashAPIKey returns the hash of the key stored for API keys.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, apiKeyLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateAPIKey creates an API key for the user. restrictionProfileID is the
+// restriction profile of the session the key is created from, if any, which
+// is applied to the key. It returns the key, which is not stored and cannot
+// be retrieved later. It must be called within a transaction.
+func (s *Service) CreateAPIKey(ctx context.Context, userID int, name string, scopes []models.APIKeyScope, expiresAt *time.Time, restrictionProfileID *int) (*models.APIKey, string, error) {
+	if !s.Config.HasCredentials() {
+		return nil, "", ErrCredentialsRequired
+	}
+
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", ErrAPIKeyExpiry
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating api key: %w", err)
+	}
+
+	ret := &models.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		KeyHash:   HashAPIKey(key),
+		Scopes:    uniqueScopes(scopes),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+
+		RestrictionProfileID: restrictionProfileID,
+	}
+
+	if err := s.APIKeys.Create(ctx, ret); err != nil {
+		return nil, "", err
+	}
+
+	return ret, key, nil
+}
+
+func uniqueScopes(scopes []models.APIKeyScope) []models.APIKeyScope {
+	var ret []models.APIKeyScope
+	seen := make(map[models.APIKeyScope]bool)
+	for _, s := range scopes {
+		if !seen[s] {
+			seen[s] = true
+			ret = append(ret, s)
+		}
+	}
+
+	return ret
+}
+
+// AuthenticateAPIKey returns the username of the user of the API key, and
+// the key. It returns an empty username if the key does not exist or has
+// expired. It opens its own transaction.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (string, *models.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) || s.Database.Ready() != nil {
+		return "", nil, nil
+	}
+
+	var (
+		apiKey *models.APIKey
+		u      *models.User
+	)
+	if err := txn.WithReadTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		var err error
+		apiKey, err = s.APIKeys.FindByKeyHash(ctx, HashAPIKey(key))
+		if err != nil || apiKey == nil {
+			return err
+		}
+
+		u, err = s.Repository.Find(ctx, apiKey.UserID)
+		return err
+	}); err != nil {
+		return "", nil, fmt.Errorf("finding api key: %w", err)
+	}
+
+	now := time.Now()
+	if apiKey == nil || u == nil || apiKey.Expired(now) {
+		return "", nil, nil
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		if err := txn.WithTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+			return s.APIKeys.UpdateLastUsed(ctx, apiKey.ID, now)
+		}); err != nil {
+			// not worth failing the request over
+			logger.Warnf("Error updating last used time of api key %q: %v", apiKey.Name, err)
+		}
+	}
+
+	return u.Username, apiKey, nil
+}
+
+// streamKeyOwner identifies the user and session restriction of keys
+// issued by StreamAPIKey.
+type streamKeyOwner struct {
+	userID               int
+	restrictionProfileID int
+}
+
+type streamKey struct {
+	key       string
+	expiresAt time.Time
+}
+
+// StreamAPIKey returns a short-lived API key of the user with only the
+// stream scope, which is added to the URLs of streams and playlists returned
+// to users logged in with a session, so that external players may request
+// them. restrictionProfileID is the restriction profile of the session, or
+// 0 if it is not restricted, which is applied to the key. The key is reused
+// until half of its lifetime has passed, after which a new key is created,
+// and the expired keys of the user are deleted. It opens its own
+// transaction.
+func (s *Service) StreamAPIKey(ctx context.Context, userID int, restrictionProfileID int) (string, error) {
+	s.streamKeysMutex.Lock()
+	defer s.streamKeysMutex.Unlock()
+
+	owner := streamKeyOwner{userID: userID, restrictionProfileID: restrictionProfileID}
+	now := time.Now()
+	if k, ok := s.streamKeys[owner]; ok && now.Add(streamKeyLifetime/2).Before(k.expiresAt) {
+		return k.key, nil
+	}
+
+	var profileID *int
+	if restrictionProfileID != 0 {
+		profileID = &restrictionProfileID
+	}
+
+	expiresAt := now.Add(streamKeyLifetime)
+	var key string
+	if err := txn.WithTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		existing, err := s.APIKeys.FindByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range existing {
+			if k.Name == StreamKeyName && k.Expired(now) {
+				if err := s.APIKeys.Destroy(ctx, k.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, key, err = s.CreateAPIKey(ctx, userID, StreamKeyName, []models.APIKeyScope{models.APIKeyScopeStream}, &expiresAt, profileID)
+		return err
+	}); err != nil {
+		return "", fmt.Errorf("creating stream api key: %w", err)
+	}
+
+	if s.streamKeys == nil {
+		s.streamKeys = make(map[streamKeyOwner]streamKey)
+	}
+	s.streamKeys[owner] = streamKey{key: key, expiresAt: expiresAt}
+
+	return key, nil
+}
@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_CreateAPIKey(t *testing.T) {
+	s, _ := newTestService(t)
+	apiKeyReaderWriter := s.APIKeys.(*mocks.APIKeyReaderWriter)
+	ctx := context.Background()
+
+	var created *models.APIKey
+	apiKeyReaderWriter.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.APIKey)
+	}).Return(nil).Once()
+
+	scopes := []models.APIKeyScope{models.APIKeyScopeStream, models.APIKeyScopeStream}
+	got, key, err := s.CreateAPIKey(ctx, editorID, " dlna ", scopes, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+	assert.Same(t, created, got)
+	assert.Equal(t, "dlna", got.Name)
+	assert.Equal(t, HashAPIKey(key), got.KeyHash)
+	assert.Equal(t, []models.APIKeyScope{models.APIKeyScopeStream}, got.Scopes)
+
+	past := time.Now().Add(-time.Hour)
+	_, _, err = s.CreateAPIKey(ctx, editorID, "expired", scopes, &past, nil)
+	assert.ErrorIs(t, err, ErrAPIKeyExpiry)
+
+	s.Config = config{}
+	_, _, err = s.CreateAPIKey(ctx, editorID, "other", scopes, nil, nil)
+	assert.ErrorIs(t, err, ErrCredentialsRequired)
+
+	apiKeyReaderWriter.AssertExpectations(t)
+}
+
+func TestService_AuthenticateAPIKey(t *testing.T) {
+	s, _ := newTestService(t)
+	apiKeyReaderWriter := s.APIKeys.(*mocks.APIKeyReaderWriter)
+	ctx := context.Background()
+
+	const (
+		validKey   = apiKeyPrefix + "valid"
+		expiredKey = apiKeyPrefix + "expired"
+		unusedKey  = apiKeyPrefix + "unused"
+	)
+
+	scopes := []models.APIKeyScope{models.APIKeyScopeRead}
+	recent := time.Now()
+	past := time.Now().Add(-time.Hour)
+
+	apiKeyReaderWriter.On("FindByKeyHash", mock.Anything, HashAPIKey(validKey)).Return(&models.APIKey{
+		ID: 1, UserID: editorID, Scopes: scopes, LastUsedAt: &recent,
+	}, nil)
+	apiKeyReaderWriter.On("FindByKeyHash", mock.Anything, HashAPIKey(expiredKey)).Return(&models.APIKey{
+		ID: 2, UserID: editorID, Scopes: scopes, ExpiresAt: &past,
+	}, nil)
+	apiKeyReaderWriter.On("FindByKeyHash", mock.Anything, HashAPIKey(unusedKey)).Return(&models.APIKey{
+		ID: 3, UserID: editorID, Scopes: scopes,
+	}, nil)
+	apiKeyReaderWriter.On("FindByKeyHash", mock.Anything, mock.Anything).Return(nil, nil)
+
+	// only keys which have not been used recently are updated
+	apiKeyReaderWriter.On("UpdateLastUsed", mock.Anything, 3, mock.Anything).Return(nil).Once()
+
+	tests := []struct {
+		name       string
+		key        string
+		wantUser   string
+		wantScopes []models.APIKeyScope
+	}{
+		{"valid", validKey, editorName, scopes},
+		{"unused", unusedKey, editorName, scopes},
+		{"expired", expiredKey, "", nil},
+		{"unknown", apiKeyPrefix + "unknown", "", nil},
+		{"configured key", "eyJhbGciOiJIUzI1NiJ9", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser, gotKey, err := s.AuthenticateAPIKey(ctx, tt.key)
+			if err != nil {
+				t.Errorf("Service.AuthenticateAPIKey() error = %v", err)
+				return
+			}
+
+			var gotScopes []models.APIKeyScope
+			if gotKey != nil {
+				gotScopes = gotKey.Scopes
+			}
+			assert.Equal(t, tt.wantUser, gotUser)
+			assert.Equal(t, tt.wantScopes, gotScopes)
+		})
+	}
+
+	apiKeyReaderWriter.AssertExpectations(t)
+	apiKeyReaderWriter.AssertNumberOfCalls(t, "UpdateLastUsed", 1)
+}
+
+func TestService_StreamAPIKey(t *testing.T) {
+	s, _ := newTestService(t)
+	apiKeyReaderWriter := s.APIKeys.(*mocks.APIKeyReaderWriter)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	expired := &models.APIKey{ID: 1, UserID: editorID, Name: StreamKeyName, ExpiresAt: &past}
+	other := &models.APIKey{ID: 2, UserID: editorID, Name: "dlna", ExpiresAt: &past}
+
+	var created *models.APIKey
+	apiKeyReaderWriter.On("FindByUserID", mock.Anything, editorID).Return([]*models.APIKey{expired, other}, nil).Once()
+	apiKeyReaderWriter.On("Destroy", mock.Anything, expired.ID).Return(nil).Once()
+	apiKeyReaderWriter.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.APIKey)
+	}).Return(nil).Once()
+
+	key, err := s.StreamAPIKey(ctx, editorID, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, HashAPIKey(key), created.KeyHash)
+	assert.Equal(t, []models.APIKeyScope{models.APIKeyScopeStream}, created.Scopes)
+	assert.Nil(t, created.RestrictionProfileID)
+	if assert.NotNil(t, created.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(streamKeyLifetime), *created.ExpiresAt, time.Minute)
+	}
+
+	// the key is reused
+	again, err := s.StreamAPIKey(ctx, editorID, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, key, again)
+	}
+
+	// restricted sessions get a separate key with their restriction
+	const profileID = 3
+	apiKeyReaderWriter.On("FindByUserID", mock.Anything, editorID).Return([]*models.APIKey{other}, nil).Once()
+	apiKeyReaderWriter.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.APIKey)
+	}).Return(nil).Once()
+
+	restricted, err := s.StreamAPIKey(ctx, editorID, profileID)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, key, restricted)
+		assert.Equal(t, HashAPIKey(restricted), created.KeyHash)
+		if assert.NotNil(t, created.RestrictionProfileID) {
+			assert.Equal(t, profileID, *created.RestrictionProfileID)
+		}
+	}
+
+	apiKeyReaderWriter.AssertExpectations(t)
+}
@@ -0,0 +1,491 @@
+// Package user provides functions for managing the user accounts which may
+// log in to the server.
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+var (
+	// ErrCredentialsRequired is returned when creating users without
+	// configured credentials. Authentication is not required in that case,
+	// so other users would be meaningless.
+	ErrCredentialsRequired = errors.New("username and password must be configured before creating users")
+	ErrUsernameTaken       = errors.New("username is already taken")
+	ErrPasswordRequired    = errors.New("password must not be empty")
+	ErrInvalidPassword     = errors.New("current password is invalid")
+	// ErrOwner is returned when modifying the owner, whose credentials are
+	// set in the configuration.
+	ErrOwner = errors.New("the owner is managed by the configured credentials")
+	// ErrOIDCSubjectTaken is returned when linking a user to an OpenID
+	// Connect user who is linked to another user.
+	ErrOIDCSubjectTaken  = errors.New("the OpenID Connect user is linked to another user")
+	ErrOIDCNotConfigured = errors.New("OpenID Connect is not configured")
+)
+
+type Config interface {
+	GetUsername() string
+	HasCredentials() bool
+	GetTOTPRequiredForAdmins() bool
+	GetOIDCIssuer() string
+}
+
+type Database interface {
+	Ready() error
+}
+
+// Service manages users. The owner is the user named by the configured
+// username. The owner logs in with the configured password, is always an
+// admin, and is created on first use.
+//
+// Users are not loaded until the database is ready, so that only the owner
+// may log in while the database needs to be migrated.
+type Service struct {
+	TxnManager txn.Manager
+	Repository models.UserReaderWriter
+	APIKeys    models.APIKeyReaderWriter
+	Config     Config
+	Database   Database
+
+	streamKeysMutex sync.Mutex
+	streamKeys      map[streamKeyOwner]streamKey
+}
+
+// HashPassword returns the hash of the password stored for users.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// IsOwner returns true if username is the configured username.
+func (s *Service) IsOwner(username string) bool {
+	return s.Config.HasCredentials() && strings.EqualFold(username, s.Config.GetUsername())
+}
+
+// Resolve returns the user with the username, creating the owner if
+// necessary. It returns nil if there is no such user. It opens its own
+// transaction.
+func (s *Service) Resolve(ctx context.Context, username string) (*models.User, error) {
+	if username == "" {
+		return nil, nil
+	}
+
+	if s.Database.Ready() != nil {
+		if !s.IsOwner(username) {
+			return nil, nil
+		}
+
+		// the owner is not stored until the database is ready
+		return &models.User{
+			Username: s.Config.GetUsername(),
+			Role:     models.UserRoleAdmin,
+		}, nil
+	}
+
+	var ret *models.User
+	if err := txn.WithReadTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		var err error
+		ret, err = s.Repository.FindByUsername(ctx, username)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("finding user %q: %w", username, err)
+	}
+
+	if !s.IsOwner(username) {
+		return ret, nil
+	}
+
+	if ret != nil {
+		ret.Role = models.UserRoleAdmin
+		return ret, nil
+	}
+
+	if err := txn.WithTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		now := time.Now()
+		ret = &models.User{
+			Username:  s.Config.GetUsername(),
+			Role:      models.UserRoleAdmin,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		return s.Repository.Create(ctx, ret)
+	}); err != nil {
+		return nil, fmt.Errorf("creating owner: %w", err)
+	}
+
+	return ret, nil
+}
+
+// LoginUserID returns the id of the user with the username, which is stored
+// in their session when they log in. The id of the owner is 0 while the
+// database is not ready. It opens its own transaction.
+func (s *Service) LoginUserID(ctx context.Context, username string) (int, error) {
+	u, err := s.Resolve(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	if u == nil {
+		return 0, fmt.Errorf("user %q not found", username)
+	}
+
+	return u.ID, nil
+}
+
+// SessionUsername returns the username of the user with the id stored in a
+// session, or an empty string if there is no such user. Sessions of the
+// owner created while the database was not ready are only valid until it
+// is. It opens its own transaction.
+func (s *Service) SessionUsername(ctx context.Context, id int) (string, error) {
+	if s.Database.Ready() != nil {
+		if id != 0 || !s.Config.HasCredentials() {
+			return "", nil
+		}
+		return s.Config.GetUsername(), nil
+	}
+
+	if id == 0 {
+		return "", nil
+	}
+
+	var u *models.User
+	if err := txn.WithReadTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		var err error
+		u, err = s.Repository.Find(ctx, id)
+		return err
+	}); err != nil {
+		return "", fmt.Errorf("finding user %d: %w", id, err)
+	}
+
+	if u == nil {
+		return "", nil
+	}
+
+	return u.Username, nil
+}
+
+// RestrictionProfileID returns the id of the restriction profile of the user
+// with the id, or 0 if they are not restricted or do not exist. It opens its
+// own transaction.
+func (s *Service) RestrictionProfileID(ctx context.Context, id int) (int, error) {
+	var u *models.User
+	if err := txn.WithReadTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		var err error
+		u, err = s.Repository.Find(ctx, id)
+		return err
+	}); err != nil {
+		return 0, fmt.Errorf("finding user %d: %w", id, err)
+	}
+
+	if u == nil || u.RestrictionProfileID == nil {
+		return 0, nil
+	}
+
+	return *u.RestrictionProfileID, nil
+}
+
+// ValidateCredentials returns true if the password is the password of the
+// user with the username. It always returns false for the owner, whose
+// credentials are validated against the configuration. It opens its own
+// transaction.
+func (s *Service) ValidateCredentials(ctx context.Context, username string, password string) (bool, error) {
+	if s.IsOwner(username) || s.Database.Ready() != nil {
+		return false, nil
+	}
+
+	var u *models.User
+	if err := txn.WithReadTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		var err error
+		u, err = s.Repository.FindByUsername(ctx, username)
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	if u == nil || u.PasswordHash == "" {
+		return false, nil
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil, nil
+}
+
+// LoginOIDCUser returns the username of the user linked to the OpenID
+// Connect user with the subject at the issuer, or an empty string if they
+// may not log in. Linked users are given role if it is not empty. If no user
+// is linked, a user without a password is created with the username and
+// linked to the subject, with role or else defaultRole, and may not log in
+// if both are empty. Existing users, including the owner, are never linked
+// implicitly, so a user of the provider cannot log in as them by choosing
+// their username. They must be linked by an admin. It opens its own
+// transaction.
+func (s *Service) LoginOIDCUser(ctx context.Context, issuer string, subject string, username string, role models.UserRole, defaultRole models.UserRole) (string, error) {
+	username = strings.TrimSpace(username)
+	if issuer == "" || subject == "" || !s.Config.HasCredentials() || s.Database.Ready() != nil {
+		return "", nil
+	}
+
+	ret := ""
+	if err := txn.WithTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		u, err := s.Repository.FindByOIDCSubject(ctx, issuer, subject)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		if u == nil {
+			if role == "" {
+				role = defaultRole
+			}
+			if role == "" || username == "" {
+				return nil
+			}
+
+			if err := s.validateUsername(ctx, username, 0); err != nil {
+				if errors.Is(err, ErrUsernameTaken) {
+					logger.Warnf("OpenID Connect user %q was not logged in, as the username is taken by an existing user who is not linked to them", username)
+					return nil
+				}
+				return err
+			}
+
+			ret = username
+			return s.Repository.Create(ctx, &models.User{
+				Username:    username,
+				Role:        role,
+				OIDCIssuer:  issuer,
+				OIDCSubject: subject,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+		}
+
+		// the owner logs in with the configured credentials
+		if s.IsOwner(u.Username) {
+			return nil
+		}
+
+		ret = u.Username
+		if role == "" || u.Role == role {
+			return nil
+		}
+
+		u.Role = role
+		u.UpdatedAt = now
+		return s.Repository.Update(ctx, u)
+	}); err != nil {
+		return "", fmt.Errorf("logging in OpenID Connect user %q: %w", subject, err)
+	}
+
+	return ret, nil
+}
+
+func (s *Service) validateUsername(ctx context.Context, username string, id int) error {
+	if s.IsOwner(username) {
+		return ErrUsernameTaken
+	}
+
+	existing, err := s.Repository.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != id {
+		return ErrUsernameTaken
+	}
+
+	return nil
+}
+
+func (s *Service) linkOIDCSubject(ctx context.Context, u *models.User, subject string) error {
+	if subject == "" {
+		u.OIDCIssuer = ""
+		u.OIDCSubject = ""
+		return nil
+	}
+
+	issuer := s.Config.GetOIDCIssuer()
+	if issuer == "" {
+		return ErrOIDCNotConfigured
+	}
+
+	existing, err := s.Repository.FindByOIDCSubject(ctx, issuer, subject)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != u.ID {
+		return ErrOIDCSubjectTaken
+	}
+
+	u.OIDCIssuer = issuer
+	u.OIDCSubject = subject
+	return nil
+}
+
+// Create creates a user with the password. It must be called within a
+// transaction.
+func (s *Service) Create(ctx context.Context, username string, password string, role models.UserRole) (*models.User, error) {
+	if !s.Config.HasCredentials() {
+		return nil, ErrCredentialsRequired
+	}
+
+	username = strings.TrimSpace(username)
+	if err := s.validateUsername(ctx, username, 0); err != nil {
+		return nil, err
+	}
+
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ret := &models.User{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.Repository.Create(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// UpdateInput is the changes to a user. Nil fields are not changed.
+type UpdateInput struct {
+	Username             *string
+	Password             *string
+	Role                 *models.UserRole
+	RestrictionProfileID models.OptionalInt
+	// OIDCSubject links the user to the OpenID Connect user with the
+	// subject at the configured issuer. An empty subject unlinks the user.
+	OIDCSubject *string
+}
+
+// Update updates the user with the id. The owner may not be updated. It must
+// be called within a transaction.
+func (s *Service) Update(ctx context.Context, id int, input UpdateInput) (*models.User, error) {
+	u, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u == nil {
+		return nil, fmt.Errorf("user with id %d not found", id)
+	}
+
+	if s.IsOwner(u.Username) {
+		return nil, ErrOwner
+	}
+
+	if input.Username != nil {
+		username := strings.TrimSpace(*input.Username)
+		if err := s.validateUsername(ctx, username, id); err != nil {
+			return nil, err
+		}
+		u.Username = username
+	}
+
+	if input.Password != nil {
+		if *input.Password == "" {
+			return nil, ErrPasswordRequired
+		}
+
+		u.PasswordHash, err = HashPassword(*input.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Role != nil {
+		u.Role = *input.Role
+	}
+
+	if input.RestrictionProfileID.Set {
+		u.RestrictionProfileID = input.RestrictionProfileID.Ptr()
+	}
+
+	if input.OIDCSubject != nil {
+		if err := s.linkOIDCSubject(ctx, u, strings.TrimSpace(*input.OIDCSubject)); err != nil {
+			return nil, err
+		}
+	}
+
+	u.UpdatedAt = time.Now()
+
+	if err := s.Repository.Update(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// ChangePassword sets the password of the user if currentPassword is their
+// current password. The password of the owner is changed in the
+// configuration. It must be called within a transaction.
+func (s *Service) ChangePassword(ctx context.Context, id int, currentPassword string, newPassword string) error {
+	u, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if u == nil {
+		return fmt.Errorf("user with id %d not found", id)
+	}
+
+	if s.IsOwner(u.Username) {
+		return ErrOwner
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(currentPassword)) != nil {
+		return ErrInvalidPassword
+	}
+
+	_, err = s.Update(ctx, id, UpdateInput{
+		Password: &newPassword,
+	})
+	return err
+}
+
+// Destroy destroys the user with the id. The owner may not be destroyed. It
+// must be called within a transaction.
+func (s *Service) Destroy(ctx context.Context, id int) error {
+	u, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if u == nil {
+		return fmt.Errorf("user with id %d not found", id)
+	}
+
+	if s.IsOwner(u.Username) {
+		return ErrOwner
+	}
+
+	return s.Repository.Destroy(ctx, id)
+}
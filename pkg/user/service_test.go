@@ -0,0 +1,380 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	ownerUsername = "owner"
+	ownerID       = 1
+	editorID      = 2
+	editorName    = "editor"
+	editorPass    = "password"
+)
+
+const testIssuer = "https://issuer"
+
+type config struct {
+	username     string
+	totpRequired bool
+	oidcIssuer   string
+}
+
+func (c config) GetUsername() string {
+	return c.username
+}
+
+func (c config) HasCredentials() bool {
+	return c.username != ""
+}
+
+func (c config) GetTOTPRequiredForAdmins() bool {
+	return c.totpRequired
+}
+
+func (c config) GetOIDCIssuer() string {
+	return c.oidcIssuer
+}
+
+type database struct {
+	err error
+}
+
+func (d database) Ready() error {
+	return d.err
+}
+
+func newTestService(t *testing.T) (*Service, *mocks.UserReaderWriter) {
+	t.Helper()
+
+	hash, err := HashPassword(editorPass)
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	db := mocks.NewTxnRepository()
+	userReaderWriter := db.User.(*mocks.UserReaderWriter)
+
+	editor := &models.User{ID: editorID, Username: editorName, PasswordHash: hash, Role: models.UserRoleEditor}
+	owner := &models.User{ID: ownerID, Username: ownerUsername, Role: models.UserRoleViewer}
+
+	userReaderWriter.On("FindByUsername", mock.Anything, editorName).Return(editor, nil).Maybe()
+	userReaderWriter.On("FindByUsername", mock.Anything, ownerUsername).Return(owner, nil).Maybe()
+	userReaderWriter.On("FindByUsername", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	userReaderWriter.On("Find", mock.Anything, editorID).Return(editor, nil).Maybe()
+	userReaderWriter.On("Find", mock.Anything, ownerID).Return(owner, nil).Maybe()
+	userReaderWriter.On("Find", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	return &Service{
+		TxnManager: db,
+		Repository: userReaderWriter,
+		APIKeys:    db.APIKey,
+		Config:     config{username: ownerUsername},
+		Database:   database{},
+	}, userReaderWriter
+}
+
+func TestService_Resolve(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	// the owner is created with the configured username if not found
+	userReaderWriter.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.Username == ownerUsername && u.Role == models.UserRoleAdmin
+	})).Return(nil).Once()
+
+	tests := []struct {
+		name     string
+		username string
+		wantRole models.UserRole
+		wantNil  bool
+	}{
+		{"editor", editorName, models.UserRoleEditor, false},
+		// the owner is always an admin
+		{"owner", ownerUsername, models.UserRoleAdmin, false},
+		{"missing owner", "OWNER", models.UserRoleAdmin, false},
+		{"unknown", "unknown", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Resolve(ctx, tt.username)
+			if err != nil {
+				t.Errorf("Service.Resolve() error = %v", err)
+				return
+			}
+
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.wantRole, got.Role)
+			}
+		})
+	}
+
+	userReaderWriter.AssertExpectations(t)
+}
+
+func TestService_ResolveNotReady(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	s.Database = database{err: errors.New("not ready")}
+	ctx := context.Background()
+
+	got, err := s.Resolve(ctx, ownerUsername)
+	if assert.NoError(t, err) && assert.NotNil(t, got) {
+		assert.Equal(t, models.UserRoleAdmin, got.Role)
+	}
+
+	got, err = s.Resolve(ctx, editorName)
+	if assert.NoError(t, err) {
+		assert.Nil(t, got)
+	}
+
+	userReaderWriter.AssertNotCalled(t, "FindByUsername", mock.Anything, mock.Anything)
+}
+
+func TestService_ValidateCredentials(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     bool
+	}{
+		{"valid", editorName, editorPass, true},
+		{"invalid password", editorName, "invalid", false},
+		{"unknown user", "unknown", editorPass, false},
+		// the owner is validated against the configuration
+		{"owner", ownerUsername, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ValidateCredentials(ctx, tt.username, tt.password)
+			if err != nil {
+				t.Errorf("Service.ValidateCredentials() error = %v", err)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_LoginOIDCUser(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	linked := &models.User{ID: editorID, Username: editorName, Role: models.UserRoleEditor, OIDCIssuer: testIssuer, OIDCSubject: "editor-subject"}
+	userReaderWriter.On("FindByOIDCSubject", mock.Anything, testIssuer, "editor-subject").Return(linked, nil)
+	userReaderWriter.On("FindByOIDCSubject", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	// missing users are created without a password, linked to the subject
+	userReaderWriter.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.Username == "viewer" && u.PasswordHash == "" && u.Role == models.UserRoleViewer &&
+			u.OIDCIssuer == testIssuer && u.OIDCSubject == "viewer-subject"
+	})).Return(nil).Once()
+	// linked users are given the mapped role
+	userReaderWriter.On("Update", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == editorID && u.Role == models.UserRoleAdmin
+	})).Return(nil).Once()
+
+	tests := []struct {
+		name        string
+		subject     string
+		username    string
+		role        models.UserRole
+		defaultRole models.UserRole
+		want        string
+	}{
+		// linked users are found by subject, whatever their username claim
+		{"linked", "editor-subject", "mallory", "", models.UserRoleViewer, editorName},
+		{"linked with role", "editor-subject", editorName, models.UserRoleAdmin, "", editorName},
+		{"new with default role", "viewer-subject", "viewer", "", models.UserRoleViewer, "viewer"},
+		{"new without role", "unknown-subject", "unknown", "", "", ""},
+		{"new without username", "unknown-subject", "", "", models.UserRoleViewer, ""},
+		// existing users must be linked by an admin
+		{"existing user", "other-subject", editorName, "", models.UserRoleViewer, ""},
+		{"owner", "owner-subject", ownerUsername, models.UserRoleAdmin, "", ""},
+		{"missing subject", "", "viewer", "", models.UserRoleViewer, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.LoginOIDCUser(ctx, testIssuer, tt.subject, tt.username, tt.role, tt.defaultRole)
+			if err != nil {
+				t.Errorf("Service.LoginOIDCUser() error = %v", err)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	userReaderWriter.AssertExpectations(t)
+}
+
+func TestService_UpdateOIDCSubject(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	userReaderWriter.On("FindByOIDCSubject", mock.Anything, testIssuer, "taken").Return(&models.User{ID: 3, Username: "other"}, nil)
+	userReaderWriter.On("FindByOIDCSubject", mock.Anything, testIssuer, "editor-subject").Return(nil, nil)
+	userReaderWriter.On("Update", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == editorID && u.OIDCIssuer == testIssuer && u.OIDCSubject == "editor-subject"
+	})).Return(nil).Once()
+
+	subject := "editor-subject"
+	_, err := s.Update(ctx, editorID, UpdateInput{OIDCSubject: &subject})
+	assert.ErrorIs(t, err, ErrOIDCNotConfigured)
+
+	s.Config = config{username: ownerUsername, oidcIssuer: testIssuer}
+
+	taken := "taken"
+	_, err = s.Update(ctx, editorID, UpdateInput{OIDCSubject: &taken})
+	assert.ErrorIs(t, err, ErrOIDCSubjectTaken)
+
+	_, err = s.Update(ctx, editorID, UpdateInput{OIDCSubject: &subject})
+	assert.NoError(t, err)
+
+	userReaderWriter.AssertExpectations(t)
+}
+
+func TestService_Create(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	userReaderWriter.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.Username == "viewer" && u.PasswordHash != "" && u.PasswordHash != "secret"
+	})).Return(nil).Once()
+
+	got, err := s.Create(ctx, " viewer ", "secret", models.UserRoleViewer)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "viewer", got.Username)
+	}
+
+	_, err = s.Create(ctx, editorName, "secret", models.UserRoleViewer)
+	assert.ErrorIs(t, err, ErrUsernameTaken)
+
+	_, err = s.Create(ctx, "Owner", "secret", models.UserRoleViewer)
+	assert.ErrorIs(t, err, ErrUsernameTaken)
+
+	_, err = s.Create(ctx, "other", "", models.UserRoleViewer)
+	assert.ErrorIs(t, err, ErrPasswordRequired)
+
+	s.Config = config{}
+	_, err = s.Create(ctx, "other", "secret", models.UserRoleViewer)
+	assert.ErrorIs(t, err, ErrCredentialsRequired)
+
+	userReaderWriter.AssertExpectations(t)
+}
+
+func TestService_Owner(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	role := models.UserRoleViewer
+	_, err := s.Update(ctx, ownerID, UpdateInput{Role: &role})
+	assert.ErrorIs(t, err, ErrOwner)
+
+	assert.ErrorIs(t, s.Destroy(ctx, ownerID), ErrOwner)
+	assert.ErrorIs(t, s.ChangePassword(ctx, ownerID, "", "new"), ErrOwner)
+
+	userReaderWriter.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	userReaderWriter.AssertNotCalled(t, "Destroy", mock.Anything, mock.Anything)
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	userReaderWriter.On("Update", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == editorID
+	})).Return(nil).Once()
+
+	assert.ErrorIs(t, s.ChangePassword(ctx, editorID, "invalid", "new"), ErrInvalidPassword)
+	assert.NoError(t, s.ChangePassword(ctx, editorID, editorPass, "new"))
+
+	userReaderWriter.AssertExpectations(t)
+}
+
+func TestService_RestrictionProfileID(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	got, err := s.RestrictionProfileID(ctx, editorID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, got)
+	}
+
+	// the users returned by the mock are shared
+	editor, _ := s.Repository.Find(ctx, editorID)
+	profileID := 3
+	editor.RestrictionProfileID = &profileID
+
+	got, err = s.RestrictionProfileID(ctx, editorID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, profileID, got)
+	}
+
+	got, err = s.RestrictionProfileID(ctx, 4)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, got)
+	}
+}
+
+func TestService_SessionUsername(t *testing.T) {
+	s, _ := newTestService(t)
+	ctx := context.Background()
+
+	id, err := s.LoginUserID(ctx, editorName)
+	if assert.NoError(t, err) {
+		assert.Equal(t, editorID, id)
+	}
+
+	tests := []struct {
+		name string
+		id   int
+		want string
+	}{
+		{"editor", editorID, editorName},
+		{"deleted", 3, ""},
+		{"owner before ready", 0, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := s.SessionUsername(ctx, tt.id)
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, tt.want, got, tt.name)
+		}
+	}
+
+	// only the owner may log in while the database is not ready
+	s.Database = database{err: errors.New("not ready")}
+
+	id, err = s.LoginUserID(ctx, ownerUsername)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, id)
+	}
+
+	got, err := s.SessionUsername(ctx, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, ownerUsername, got)
+	}
+
+	got, err = s.SessionUsername(ctx, editorID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, got)
+	}
+}
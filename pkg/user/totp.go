@@ -0,0 +1,431 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+const (
+	// codes of authenticator apps are generated as specified by RFC 6238,
+	// with the defaults supported by all apps
+	totpPeriod       = 30
+	totpDigits       = 6
+	totpModulus      = 1000000
+	totpSecretLength = 20
+	totpIssuer       = "Stash"
+
+	// totpSkew is the number of time steps before and after the current one
+	// whose codes are accepted, allowing for clock drift.
+	totpSkew = 1
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	// recoveryCodeAlphabet omits characters which are easily confused.
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var (
+	ErrTOTPEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTOTPDisabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPNotEnrolling = errors.New("two-factor authentication enrollment has not been started")
+	ErrTOTPRequired     = errors.New("two-factor authentication is required for admins")
+	ErrInvalidCode      = errors.New("code is invalid")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// totpURI returns the otpauth provisioning URI of the secret of the user.
+func totpURI(username string, secret string) string {
+	q := make(url.Values)
+	q.Set("secret", secret)
+	q.Set("issuer", totpIssuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", strconv.Itoa(totpDigits))
+	q.Set("period", strconv.Itoa(totpPeriod))
+
+	label := url.PathEscape(totpIssuer) + ":" + url.PathEscape(username)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// totpCode returns the code of the key at the time step.
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, v%totpModulus)
+}
+
+// validateTOTP returns the time step of the code if it is a code of the
+// secret at a time step near now. Codes of time steps up to lastStep have
+// already been used, and are not accepted again.
+func validateTOTP(secret string, code string, now time.Time, lastStep int64) (int64, bool) {
+	code = strings.ReplaceAll(code, " ", "")
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step > lastStep && subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// generateRecoveryCodes returns new recovery codes and their hashes.
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLength)
+		for j := range b {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+			b[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+
+		code := string(b[:recoveryCodeLength/2]) + "-" + string(b[recoveryCodeLength/2:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode returns the hash of the code stored for recovery codes.
+// Codes are compared ignoring case, spaces and dashes.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return HashAPIKey(code)
+}
+
+func clearTOTP(u *models.User) {
+	u.TOTPSecret = ""
+	u.TOTPEnabled = false
+	u.TOTPLastStep = 0
+	u.RecoveryCodeHashes = nil
+}
+
+// totpRequired returns true if the user must use two-factor
+// authentication.
+func (s *Service) totpRequired(u *models.User) bool {
+	return s.Config.GetTOTPRequiredForAdmins() && (u.HasRole(models.UserRoleAdmin) || s.IsOwner(u.Username))
+}
+
+// TOTPEnrollmentRequired returns true if the user must enroll in two-factor
+// authentication before they may use stash, however they authenticated.
+// The owner is exempt while the database is not ready.
+func (s *Service) TOTPEnrollmentRequired(u *models.User) bool {
+	return !u.TOTPEnabled && u.ID != 0 && s.totpRequired(u)
+}
+
+// TOTPRequired returns true if the user with the username must use
+// two-factor authentication. It opens its own transaction.
+func (s *Service) TOTPRequired(ctx context.Context, username string) (bool, error) {
+	u, err := s.Resolve(ctx, username)
+	if err != nil || u == nil {
+		return false, err
+	}
+
+	return s.totpRequired(u), nil
+}
+
+func (s *Service) findUser(ctx context.Context, id int) (*models.User, error) {
+	u, err := s.Repository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u == nil {
+		return nil, fmt.Errorf("user with id %d not found", id)
+	}
+
+	return u, nil
+}
+
+// EnrollTOTP starts enrolling the user in two-factor authentication,
+// returning the secret to add to their authenticator app. The secret of an
+// enrollment which has not been confirmed is returned again. It must be
+// called within a transaction.
+func (s *Service) EnrollTOTP(ctx context.Context, id int) (*models.TOTPEnrollment, error) {
+	if !s.Config.HasCredentials() {
+		return nil, ErrCredentialsRequired
+	}
+
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.TOTPEnabled {
+		return nil, ErrTOTPEnabled
+	}
+
+	if u.TOTPSecret == "" {
+		u.TOTPSecret, err = generateTOTPSecret()
+		if err != nil {
+			return nil, fmt.Errorf("generating secret: %w", err)
+		}
+
+		u.UpdatedAt = time.Now()
+		if err := s.Repository.Update(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: u.TOTPSecret,
+		URI:    totpURI(u.Username, u.TOTPSecret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication for the user if code is a
+// current code of the secret being enrolled. It returns the recovery codes
+// of the user, which are not stored and cannot be retrieved later. It must
+// be called within a transaction.
+func (s *Service) ConfirmTOTP(ctx context.Context, id int, code string) ([]string, error) {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.TOTPEnabled {
+		return nil, ErrTOTPEnabled
+	}
+
+	if u.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolling
+	}
+
+	step, ok := validateTOTP(u.TOTPSecret, code, time.Now(), u.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("generating recovery codes: %w", err)
+	}
+
+	u.TOTPEnabled = true
+	u.TOTPLastStep = step
+	u.RecoveryCodeHashes = hashes
+	u.UpdatedAt = time.Now()
+
+	if err := s.Repository.Update(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// verifySecondFactor returns true if code is a current code of the
+// authenticator app of the user, or one of their unused recovery codes. The
+// code may not be used again.
+func (s *Service) verifySecondFactor(ctx context.Context, u *models.User, code string) (bool, error) {
+	if !u.TOTPEnabled {
+		return false, nil
+	}
+
+	if step, ok := validateTOTP(u.TOTPSecret, code, time.Now(), u.TOTPLastStep); ok {
+		u.TOTPLastStep = step
+	} else if hash := hashRecoveryCode(code); stringslice.StrInclude(u.RecoveryCodeHashes, hash) {
+		u.RecoveryCodeHashes = stringslice.StrDelete(u.RecoveryCodeHashes, hash)
+	} else {
+		return false, nil
+	}
+
+	return true, s.Repository.Update(ctx, u)
+}
+
+// DisableTOTP disables two-factor authentication for the user if code is a
+// code of their authenticator app or a recovery code. Admins may not
+// disable it while it is required for them. It must be called within a
+// transaction.
+func (s *Service) DisableTOTP(ctx context.Context, id int, code string) error {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !u.TOTPEnabled {
+		return ErrTOTPDisabled
+	}
+
+	if s.totpRequired(u) {
+		return ErrTOTPRequired
+	}
+
+	ok, err := s.verifySecondFactor(ctx, u, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrInvalidCode
+	}
+
+	clearTOTP(u)
+	u.UpdatedAt = time.Now()
+
+	return s.Repository.Update(ctx, u)
+}
+
+// ResetTOTP disables two-factor authentication for the user without a code,
+// such as when they have lost their authenticator app and recovery codes.
+// It must be called within a transaction.
+func (s *Service) ResetTOTP(ctx context.Context, id int) error {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	clearTOTP(u)
+	u.UpdatedAt = time.Now()
+
+	return s.Repository.Update(ctx, u)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user if code is
+// a code of their authenticator app or a recovery code. It must be called
+// within a transaction.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, id int, code string) ([]string, error) {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.TOTPEnabled {
+		return nil, ErrTOTPDisabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, u, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("generating recovery codes: %w", err)
+	}
+
+	u.RecoveryCodeHashes = hashes
+	u.UpdatedAt = time.Now()
+
+	if err := s.Repository.Update(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// SecondFactorStatus returns true if the user with the username must enter
+// a code of their authenticator app after their password to log in, and
+// whether they must enroll first. The owner logs in with only their
+// password while the database is not ready. It opens its own transaction.
+func (s *Service) SecondFactorStatus(ctx context.Context, username string) (enabled bool, enrollmentRequired bool, err error) {
+	u, err := s.Resolve(ctx, username)
+	if err != nil || u == nil || s.Database.Ready() != nil {
+		return false, false, err
+	}
+
+	return u.TOTPEnabled, !u.TOTPEnabled && s.totpRequired(u), nil
+}
+
+// withLoginUser calls fn with the user with the username within a
+// transaction.
+func (s *Service) withLoginUser(ctx context.Context, username string, fn func(ctx context.Context, u *models.User) error) error {
+	return txn.WithTxn(ctx, s.TxnManager, func(ctx context.Context) error {
+		u, err := s.Repository.FindByUsername(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		if u == nil {
+			return fmt.Errorf("user %q not found", username)
+		}
+
+		return fn(ctx, u)
+	})
+}
+
+// EnrollLoginTOTP starts enrolling the user with the username while they
+// log in. It opens its own transaction.
+func (s *Service) EnrollLoginTOTP(ctx context.Context, username string) (ret *models.TOTPEnrollment, err error) {
+	err = s.withLoginUser(ctx, username, func(ctx context.Context, u *models.User) error {
+		var err error
+		ret, err = s.EnrollTOTP(ctx, u.ID)
+		return err
+	})
+	return
+}
+
+// ConfirmLoginTOTP confirms the enrollment of the user with the username
+// while they log in, returning their recovery codes. It returns nil if the
+// code is invalid. It opens its own transaction.
+func (s *Service) ConfirmLoginTOTP(ctx context.Context, username string, code string) (ret []string, err error) {
+	err = s.withLoginUser(ctx, username, func(ctx context.Context, u *models.User) error {
+		var err error
+		ret, err = s.ConfirmTOTP(ctx, u.ID, code)
+		return err
+	})
+	if errors.Is(err, ErrInvalidCode) {
+		return nil, nil
+	}
+	return
+}
+
+// VerifySecondFactor returns true if code is a current code of the
+// authenticator app of the user with the username, or one of their unused
+// recovery codes. It opens its own transaction.
+func (s *Service) VerifySecondFactor(ctx context.Context, username string, code string) (ret bool, err error) {
+	err = s.withLoginUser(ctx, username, func(ctx context.Context, u *models.User) error {
+		var err error
+		ret, err = s.verifySecondFactor(ctx, u, code)
+		return err
+	})
+	return
+}
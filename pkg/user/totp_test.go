@@ -0,0 +1,230 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// test vectors of RFC 6238, truncated to six digits
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, totpCode(key, tt.unix/totpPeriod), tt.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+	step := now.Unix() / totpPeriod
+
+	got, ok := validateTOTP(secret, "081804", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, got)
+
+	_, ok = validateTOTP(secret, "081 804", now, 0)
+	assert.True(t, ok)
+
+	// codes of adjacent steps are accepted
+	_, ok = validateTOTP(secret, "081804", now.Add(totpPeriod*time.Second), 0)
+	assert.True(t, ok)
+	_, ok = validateTOTP(secret, "081804", now.Add(2*totpPeriod*time.Second), 0)
+	assert.False(t, ok)
+
+	// used codes are not accepted again
+	_, ok = validateTOTP(secret, "081804", now, step)
+	assert.False(t, ok)
+
+	_, ok = validateTOTP(secret, "000000", now, 0)
+	assert.False(t, ok)
+	_, ok = validateTOTP("not base32!", "081804", now, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	assert.Equal(t,
+		"otpauth://totp/Stash:jane%20doe?algorithm=SHA1&digits=6&issuer=Stash&period=30&secret=SECRET",
+		totpURI("jane doe", "SECRET"),
+	)
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decoding secret: %v", err)
+	}
+
+	return totpCode(key, time.Now().Unix()/totpPeriod)
+}
+
+func TestService_TOTP(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+
+	userReaderWriter.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	enrollment, err := s.EnrollTOTP(ctx, editorID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	// the enrollment is resumed until it is confirmed
+	again, err := s.EnrollTOTP(ctx, editorID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, enrollment.Secret, again.Secret)
+	}
+
+	_, err = s.ConfirmTOTP(ctx, editorID, "")
+	assert.ErrorIs(t, err, ErrInvalidCode)
+
+	code := currentCode(t, enrollment.Secret)
+	recoveryCodes, err := s.ConfirmTOTP(ctx, editorID, code)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+
+	enabled, enrollmentRequired, err := s.SecondFactorStatus(ctx, editorName)
+	if assert.NoError(t, err) {
+		assert.True(t, enabled)
+		assert.False(t, enrollmentRequired)
+	}
+
+	_, err = s.EnrollTOTP(ctx, editorID)
+	assert.ErrorIs(t, err, ErrTOTPEnabled)
+
+	// the code used to confirm cannot be used again
+	ok, err := s.VerifySecondFactor(ctx, editorName, code)
+	if assert.NoError(t, err) {
+		assert.False(t, ok)
+	}
+
+	// recovery codes may be used once, ignoring case and dashes
+	recoveryCode := recoveryCodes[0]
+	ok, err = s.VerifySecondFactor(ctx, editorName, strings.ToUpper(recoveryCode[:5]+recoveryCode[6:]))
+	if assert.NoError(t, err) {
+		assert.True(t, ok)
+	}
+	ok, err = s.VerifySecondFactor(ctx, editorName, recoveryCode)
+	if assert.NoError(t, err) {
+		assert.False(t, ok)
+	}
+
+	newCodes, err := s.RegenerateRecoveryCodes(ctx, editorID, recoveryCodes[1])
+	if assert.NoError(t, err) {
+		assert.Len(t, newCodes, recoveryCodeCount)
+	}
+
+	// the old recovery codes are replaced
+	assert.ErrorIs(t, s.DisableTOTP(ctx, editorID, recoveryCodes[2]), ErrInvalidCode)
+	assert.NoError(t, s.DisableTOTP(ctx, editorID, newCodes[0]))
+
+	enabled, _, err = s.SecondFactorStatus(ctx, editorName)
+	if assert.NoError(t, err) {
+		assert.False(t, enabled)
+	}
+}
+
+func TestService_TOTPRequired(t *testing.T) {
+	s, userReaderWriter := newTestService(t)
+	ctx := context.Background()
+	s.Config = config{username: ownerUsername, totpRequired: true}
+
+	userReaderWriter.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	// the owner is an admin, so must enroll
+	enabled, enrollmentRequired, err := s.SecondFactorStatus(ctx, ownerUsername)
+	if assert.NoError(t, err) {
+		assert.False(t, enabled)
+		assert.True(t, enrollmentRequired)
+	}
+
+	_, enrollmentRequired, err = s.SecondFactorStatus(ctx, editorName)
+	if assert.NoError(t, err) {
+		assert.False(t, enrollmentRequired)
+	}
+
+	required, err := s.TOTPRequired(ctx, ownerUsername)
+	if assert.NoError(t, err) {
+		assert.True(t, required)
+	}
+	required, err = s.TOTPRequired(ctx, editorName)
+	if assert.NoError(t, err) {
+		assert.False(t, required)
+	}
+
+	enrollment, err := s.EnrollTOTP(ctx, ownerID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err := s.ConfirmTOTP(ctx, ownerID, currentCode(t, enrollment.Secret)); !assert.NoError(t, err) {
+		return
+	}
+
+	// admins may not disable two-factor authentication, but it may be reset
+	assert.ErrorIs(t, s.DisableTOTP(ctx, ownerID, "000000"), ErrTOTPRequired)
+	assert.NoError(t, s.ResetTOTP(ctx, ownerID))
+
+	_, enrollmentRequired, err = s.SecondFactorStatus(ctx, ownerUsername)
+	if assert.NoError(t, err) {
+		assert.True(t, enrollmentRequired)
+	}
+}
+
+func TestService_TOTPEnrollmentRequired(t *testing.T) {
+	s, _ := newTestService(t)
+	s.Config = config{username: ownerUsername, totpRequired: true}
+
+	tests := []struct {
+		name string
+		user *models.User
+		want bool
+	}{
+		{"owner", &models.User{ID: ownerID, Username: ownerUsername, Role: models.UserRoleViewer}, true},
+		{"admin", &models.User{ID: editorID, Username: editorName, Role: models.UserRoleAdmin}, true},
+		{"enrolled admin", &models.User{ID: editorID, Username: editorName, Role: models.UserRoleAdmin, TOTPEnabled: true}, false},
+		{"editor", &models.User{ID: editorID, Username: editorName, Role: models.UserRoleEditor}, false},
+		// the owner while the database is not ready
+		{"configured owner", &models.User{Username: ownerUsername, Role: models.UserRoleAdmin}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.TOTPEnrollmentRequired(tt.user))
+		})
+	}
+
+	s.Config = config{username: ownerUsername}
+	assert.False(t, s.TOTPEnrollmentRequired(&models.User{ID: editorID, Username: editorName, Role: models.UserRoleAdmin}))
+}
+
+func TestService_SecondFactorStatusNotReady(t *testing.T) {
+	s, _ := newTestService(t)
+	s.Config = config{username: ownerUsername, totpRequired: true}
+	s.Database = database{err: errors.New("not ready")}
+
+	enabled, enrollmentRequired, err := s.SecondFactorStatus(context.Background(), ownerUsername)
+	if assert.NoError(t, err) {
+		assert.False(t, enabled)
+		assert.False(t, enrollmentRequired)
+	}
+}